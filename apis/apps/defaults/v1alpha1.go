@@ -140,6 +140,10 @@ func SetDefaultsAdvancedCronJob(obj *v1alpha1.AdvancedCronJob, injectTemplateDef
 		SetDefaultPodSpec(&obj.Spec.Template.BroadcastJobTemplate.Spec.Template.Spec)
 	}
 
+	if obj.Spec.Template.ImagePullJobTemplate != nil {
+		setDefaultsImagePullJobTemplate(&obj.Spec.Template.ImagePullJobTemplate.Spec.ImagePullJobTemplate)
+	}
+
 	if obj.Spec.ConcurrencyPolicy == "" {
 		obj.Spec.ConcurrencyPolicy = v1alpha1.AllowConcurrent
 	}
@@ -206,6 +210,10 @@ func SetDefaultsUnitedDeployment(obj *v1alpha1.UnitedDeployment, injectTemplateD
 			}
 		}
 	}
+
+	if obj.Spec.Template.AdvancedDaemonSetTemplate != nil && injectTemplateDefaults {
+		SetDefaultPodSpec(&obj.Spec.Template.AdvancedDaemonSetTemplate.Spec.Template.Spec)
+	}
 }
 
 // SetDefaults_CloneSet set default values for CloneSet.
@@ -337,16 +345,25 @@ func SetDefaultsImageTagPullPolicy(obj *v1alpha1.ImageTagPullPolicy) {
 
 // SetDefaults_ImagePullJob set default values for ImagePullJob.
 func SetDefaultsImagePullJob(obj *v1alpha1.ImagePullJob) {
-	if obj.Spec.CompletionPolicy.Type == "" {
-		obj.Spec.CompletionPolicy.Type = v1alpha1.Always
+	setDefaultsImagePullJobTemplate(&obj.Spec.ImagePullJobTemplate)
+}
+
+// SetDefaults_ImageListPullJob set default values for ImageListPullJob.
+func SetDefaultsImageListPullJob(obj *v1alpha1.ImageListPullJob) {
+	setDefaultsImagePullJobTemplate(&obj.Spec.ImagePullJobTemplate)
+}
+
+func setDefaultsImagePullJobTemplate(template *v1alpha1.ImagePullJobTemplate) {
+	if template.CompletionPolicy.Type == "" {
+		template.CompletionPolicy.Type = v1alpha1.Always
 	}
-	if obj.Spec.PullPolicy == nil {
-		obj.Spec.PullPolicy = &v1alpha1.PullPolicy{}
+	if template.PullPolicy == nil {
+		template.PullPolicy = &v1alpha1.PullPolicy{}
 	}
-	if obj.Spec.PullPolicy.TimeoutSeconds == nil {
-		obj.Spec.PullPolicy.TimeoutSeconds = utilpointer.Int32Ptr(600)
+	if template.PullPolicy.TimeoutSeconds == nil {
+		template.PullPolicy.TimeoutSeconds = utilpointer.Int32Ptr(600)
 	}
-	if obj.Spec.PullPolicy.BackoffLimit == nil {
-		obj.Spec.PullPolicy.BackoffLimit = utilpointer.Int32Ptr(3)
+	if template.PullPolicy.BackoffLimit == nil {
+		template.PullPolicy.BackoffLimit = utilpointer.Int32Ptr(3)
 	}
 }
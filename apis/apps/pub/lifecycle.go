@@ -40,4 +40,23 @@ type Lifecycle struct {
 type LifecycleHook struct {
 	LabelsHandler     map[string]string `json:"labelsHandler,omitempty"`
 	FinalizersHandler []string          `json:"finalizersHandler,omitempty"`
+	// TimeoutSeconds is the max seconds that a Pod can be blocked by this hook since it entered the hooked state.
+	// Nil or 0 means the Pod can be blocked forever.
+	// Currently it only works for CloneSet, and is rejected by the other workloads.
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+	// FailurePolicy defines what to do when the hook has timed out.
+	// - Continue: ignore the hook and let the Pod continue to be deleted or updated.
+	// - Block: keep blocking the Pod, and report it in the workload conditions.
+	// Default is Block.
+	// Currently it only works for CloneSet, and is rejected by the other workloads.
+	FailurePolicy LifecycleHookFailurePolicyType `json:"failurePolicy,omitempty"`
 }
+
+// LifecycleHookFailurePolicyType defines what to do when a lifecycle hook has timed out.
+// +kubebuilder:validation:Enum=Continue;Block
+type LifecycleHookFailurePolicyType string
+
+const (
+	LifecycleHookFailurePolicyContinue LifecycleHookFailurePolicyType = "Continue"
+	LifecycleHookFailurePolicyBlock    LifecycleHookFailurePolicyType = "Block"
+)
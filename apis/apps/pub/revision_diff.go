@@ -0,0 +1,26 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pub
+
+// RevisionDiff is the semantic diff from the current revision to the update revision of a workload,
+// which shows what the rollout will change in Pods.
+type RevisionDiff struct {
+	// ChangedFields are the paths of fields added, removed or replaced in the update revision,
+	// such as `spec.template.spec.containers[main].image`, in which containers are indexed by their names
+	// and the keys of maps are quoted if they contain dots, e.g. `spec.template.metadata.labels["app.kubernetes.io/name"]`.
+	ChangedFields []string `json:"changedFields,omitempty"`
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pub
+
+import "fmt"
+
+// ScaleDownRankingPolicy defines how to rank the pods to be deleted when scaling down.
+// Pods with higher ranks are preferred to be deleted, which takes effect after pod-deletion-cost.
+type ScaleDownRankingPolicy struct {
+	// Type is the type of the ranking policy.
+	// - NodeUtilization: pods on the nodes with lower requested utilization are deleted first.
+	// - SpreadConstraints: pods in the topology domains that violate topologySpreadConstraints most are deleted first.
+	// - PodAge: older pods are deleted first.
+	// - Annotation: pods with higher number in the annotation of annotationKey are deleted first.
+	Type ScaleDownRankingType `json:"type"`
+	// AnnotationKey is the key of pod annotation whose number value is the rank for Annotation type.
+	// Pods without this annotation or with an invalid number have the lowest rank.
+	AnnotationKey string `json:"annotationKey,omitempty"`
+}
+
+// ScaleDownRankingType is the type of ScaleDownRankingPolicy.
+// +kubebuilder:validation:Enum=NodeUtilization;SpreadConstraints;PodAge;Annotation
+type ScaleDownRankingType string
+
+const (
+	ScaleDownRankingNodeUtilization   ScaleDownRankingType = "NodeUtilization"
+	ScaleDownRankingSpreadConstraints ScaleDownRankingType = "SpreadConstraints"
+	ScaleDownRankingPodAge            ScaleDownRankingType = "PodAge"
+	ScaleDownRankingAnnotation        ScaleDownRankingType = "Annotation"
+)
+
+// FieldsValidation checks invalid fields in ScaleDownRankingPolicy.
+func (policy *ScaleDownRankingPolicy) FieldsValidation() error {
+	if policy == nil {
+		return nil
+	}
+
+	switch policy.Type {
+	case ScaleDownRankingNodeUtilization, ScaleDownRankingSpreadConstraints, ScaleDownRankingPodAge:
+		if policy.AnnotationKey != "" {
+			return fmt.Errorf("annotationKey can only be set for %s type", ScaleDownRankingAnnotation)
+		}
+	case ScaleDownRankingAnnotation:
+		if policy.AnnotationKey == "" {
+			return fmt.Errorf("annotationKey is required for %s type", ScaleDownRankingAnnotation)
+		}
+	default:
+		return fmt.Errorf("unsupported type %q", policy.Type)
+	}
+	return nil
+}
@@ -185,6 +185,11 @@ func (in *LifecycleHook) DeepCopyInto(out *LifecycleHook) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LifecycleHook.
@@ -197,6 +202,26 @@ func (in *LifecycleHook) DeepCopy() *LifecycleHook {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RevisionDiff) DeepCopyInto(out *RevisionDiff) {
+	*out = *in
+	if in.ChangedFields != nil {
+		in, out := &in.ChangedFields, &out.ChangedFields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RevisionDiff.
+func (in *RevisionDiff) DeepCopy() *RevisionDiff {
+	if in == nil {
+		return nil
+	}
+	out := new(RevisionDiff)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RuntimeContainerHashes) DeepCopyInto(out *RuntimeContainerHashes) {
 	*out = *in
@@ -248,6 +273,21 @@ func (in *RuntimeContainerMetaSet) DeepCopy() *RuntimeContainerMetaSet {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScaleDownRankingPolicy) DeepCopyInto(out *ScaleDownRankingPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaleDownRankingPolicy.
+func (in *ScaleDownRankingPolicy) DeepCopy() *ScaleDownRankingPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ScaleDownRankingPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UpdatePriorityOrderTerm) DeepCopyInto(out *UpdatePriorityOrderTerm) {
 	*out = *in
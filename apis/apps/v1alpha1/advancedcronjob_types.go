@@ -31,6 +31,11 @@ type AdvancedCronJobSpec struct {
 	// The schedule in Cron format, see https://en.wikipedia.org/wiki/Cron.
 	Schedule string `json:"schedule" protobuf:"bytes,1,opt,name=schedule"`
 
+	// The time zone name for the given schedule, see https://en.wikipedia.org/wiki/List_of_tz_database_time_zones.
+	// If not specified, this will default to the time zone of the kruise-controller-manager process.
+	// +optional
+	TimeZone *string `json:"timeZone,omitempty" protobuf:"bytes,8,opt,name=timeZone"`
+
 	// +kubebuilder:validation:Minimum=0
 
 	// Optional deadline in seconds for starting the job if it misses scheduled
@@ -78,6 +83,18 @@ type CronJobTemplate struct {
 	// Specifies the broadcastjob that will be created when executing a BroadcastCronJob.
 	// +optional
 	BroadcastJobTemplate *BroadcastJobTemplateSpec `json:"broadcastJobTemplate,omitempty" protobuf:"bytes,2,opt,name=broadcastJobTemplate"`
+
+	// Specifies the imagepulljob that will be created when executing a CronJob, to pull the image periodically.
+	// +optional
+	ImagePullJobTemplate *ImagePullJobTemplateSpec `json:"imagePullJobTemplate,omitempty" protobuf:"bytes,3,opt,name=imagePullJobTemplate"`
+
+	// Specifies the ephemeraljob that will be created when executing a CronJob.
+	// The names of the ephemeral containers will be suffixed with the scheduled time of each run,
+	// for an ephemeral container name can not be reused in a pod.
+	// Ephemeral containers can never be removed from pods, so the runs are skipped once any selected pod
+	// has been injected by 100 runs.
+	// +optional
+	EphemeralJobTemplate *EphemeralJobTemplateSpec `json:"ephemeralJobTemplate,omitempty" protobuf:"bytes,4,opt,name=ephemeralJobTemplate"`
 }
 
 type TemplateKind string
@@ -86,6 +103,10 @@ const (
 	JobTemplate TemplateKind = "Job"
 
 	BroadcastJobTemplate TemplateKind = "BroadcastJob"
+
+	ImagePullJobTemplateKind TemplateKind = "ImagePullJob"
+
+	EphemeralJobTemplateKind TemplateKind = "EphemeralJob"
 )
 
 // JobTemplateSpec describes the data a Job should have when created from a template
@@ -99,6 +120,28 @@ type BroadcastJobTemplateSpec struct {
 	Spec BroadcastJobSpec `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
 }
 
+// ImagePullJobTemplateSpec describes the data an ImagePullJob should have when created from a template
+type ImagePullJobTemplateSpec struct {
+	// Standard object's metadata of the jobs created from this template.
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// Specification of the desired behavior of the imagepulljob.
+	// +optional
+	Spec ImagePullJobSpec `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
+}
+
+// EphemeralJobTemplateSpec describes the data an EphemeralJob should have when created from a template
+type EphemeralJobTemplateSpec struct {
+	// Standard object's metadata of the jobs created from this template.
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// Specification of the desired behavior of the ephemeraljob.
+	// +optional
+	Spec EphemeralJobSpec `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
+}
+
 // ConcurrencyPolicy describes how the job will be handled.
 // Only one of the following concurrent policies may be specified.
 // If none of the following policies is specified, the default one
@@ -89,6 +89,8 @@ const (
 
 	// Never means the job will be kept alive after all pods on the desired nodes are completed.
 	// This is useful when new nodes are added after the job completes, the pods will be triggered automatically on those new nodes.
+	// The job can be completed explicitly by changing the type to Always, then it will finish after the pods on
+	// the current desired nodes are completed.
 	Never CompletionPolicyType = "Never"
 )
 
@@ -19,6 +19,7 @@ package v1alpha1
 import (
 	appspub "github.com/openkruise/kruise/apis/apps/pub"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -30,6 +31,11 @@ const (
 
 	// DefaultCloneSetMaxUnavailable is the default value of maxUnavailable for CloneSet update strategy.
 	DefaultCloneSetMaxUnavailable = "20%"
+
+	// CloneSetCanaryResumeStepAnnotation resumes the canary step paused without duration,
+	// and its value should be `<revision>/<step>`, which are status.canaryStatus.revision and
+	// the index of the paused step in status.canaryStatus.currentStepIndex.
+	CloneSetCanaryResumeStepAnnotation = "apps.kruise.io/canary-resume-step"
 )
 
 // CloneSetSpec defines the desired state of CloneSet
@@ -56,6 +62,9 @@ type CloneSetSpec struct {
 	// +kubebuilder:validation:Schemaless
 	VolumeClaimTemplates []v1.PersistentVolumeClaim `json:"volumeClaimTemplates,omitempty"`
 
+	// VolumeClaimUpdateStrategy indicates how to update the existing PVCs when volumeClaimTemplates changed.
+	VolumeClaimUpdateStrategy CloneSetVolumeClaimUpdateStrategy `json:"volumeClaimUpdateStrategy,omitempty"`
+
 	// ScaleStrategy indicates the ScaleStrategy that will be employed to
 	// create and delete Pods in the CloneSet.
 	ScaleStrategy CloneSetScaleStrategy `json:"scaleStrategy,omitempty"`
@@ -77,6 +86,20 @@ type CloneSetSpec struct {
 
 	// Lifecycle defines the lifecycle hooks for Pods pre-delete, in-place update.
 	Lifecycle *appspub.Lifecycle `json:"lifecycle,omitempty"`
+
+	// PodNamingPolicy indicates how to generate the instance-id, which is the suffix of names of pods and PVCs.
+	// - Random: a random string of 5 characters.
+	// - Ordinal: the lowest non-negative integer that is not used by other pods, like StatefulSet.
+	//   The ordinals are only for deterministic identities, pods are still scaled and updated in the way of CloneSet.
+	// Default is Random. It can not be changed after creation.
+	PodNamingPolicy CloneSetPodNamingPolicyType `json:"podNamingPolicy,omitempty"`
+
+	// ProgressDeadlineSeconds is the maximum time in seconds for a CloneSet to make progress before it
+	// is considered to be failed. The controller will continue to process the CloneSet, and a Degraded
+	// condition will be surfaced in status with a ProgressDeadlineExceeded reason.
+	// Note that progress will not be estimated while the CloneSet or its canary step is paused.
+	// Not set by default, which means the progress is not checked.
+	ProgressDeadlineSeconds *int32 `json:"progressDeadlineSeconds,omitempty"`
 }
 
 // CloneSetScaleStrategy defines strategies for pods scale.
@@ -84,11 +107,18 @@ type CloneSetScaleStrategy struct {
 	// PodsToDelete is the names of Pod should be deleted.
 	// Note that this list will be truncated for non-existing pod names.
 	PodsToDelete []string `json:"podsToDelete,omitempty"`
+	// PodsToDeleteSelector is a label query over pods that are preferred to be deleted when scaling in.
+	// Unlike podsToDelete, the matched pods will only be deleted when replicas is decreased,
+	// and they take precedence over all the other ranks in choosing pods to delete.
+	PodsToDeleteSelector *metav1.LabelSelector `json:"podsToDeleteSelector,omitempty"`
 	// The maximum number of pods that can be unavailable for scaled pods.
 	// This field can control the changes rate of replicas for CloneSet so as to minimize the impact for users' service.
 	// The scale will fail if the number of unavailable pods were greater than this MaxUnavailable at scaling up.
 	// MaxUnavailable works only when scaling up.
 	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+	// RankingPolicy defines how to choose the pods to delete when scaling down,
+	// which takes effect after pod-deletion-cost and before the default ranks.
+	RankingPolicy *appspub.ScaleDownRankingPolicy `json:"rankingPolicy,omitempty"`
 }
 
 // CloneSetUpdateStrategy defines strategies for pods update.
@@ -98,10 +128,17 @@ type CloneSetUpdateStrategy struct {
 	Type CloneSetUpdateStrategyType `json:"type,omitempty"`
 	// Partition is the desired number of pods in old revisions.
 	// Value can be an absolute number (ex: 5) or a percentage of desired pods (ex: 10%).
-	// Absolute number is calculated from percentage by rounding up by default.
+	// Absolute number is calculated from percentage according to partitionRounding, which is rounding up by default.
 	// It means when partition is set during pods updating, (replicas - partition value) number of pods will be updated.
+	// Percentage is recalculated whenever replicas change, so the ratio of old pods keeps the same when scaled by HPA.
 	// Default value is 0.
 	Partition *intstr.IntOrString `json:"partition,omitempty"`
+	// PartitionRounding indicates how to calculate the absolute number from a percentage partition.
+	// - Up: round up, and at least 1 old pod is reserved if partition > "0%" and replicas > 0.
+	// - Down: round down, which may update all pods if replicas are too few for the percentage.
+	// Whatever the rounding is, at least 1 pod is updated if partition < "100%" and replicas > 1.
+	// Default is Up.
+	PartitionRounding CloneSetPartitionRoundingType `json:"partitionRounding,omitempty"`
 	// The maximum number of pods that can be unavailable during update or scale.
 	// Value can be an absolute number (ex: 5) or a percentage of desired pods (ex: 10%).
 	// Absolute number is calculated from percentage by rounding up by default.
@@ -126,8 +163,65 @@ type CloneSetUpdateStrategy struct {
 	ScatterStrategy UpdateScatterStrategy `json:"scatterStrategy,omitempty"`
 	// InPlaceUpdateStrategy contains strategies for in-place update.
 	InPlaceUpdateStrategy *appspub.InPlaceUpdateStrategy `json:"inPlaceUpdateStrategy,omitempty"`
+	// InPlaceMetadataKeys is the allowlist of label and annotation keys in template.
+	// If only these keys have been changed between revisions, pods will be updated in-place by patching metadata,
+	// even if the type is ReCreate.
+	InPlaceMetadataKeys []string `json:"inPlaceMetadataKeys,omitempty"`
+	// BatchPauseSeconds is the seconds to wait after the pods updated in a batch have become available,
+	// before continuing to update the next batch. Pods in the next batch won't be updated until all the updated pods
+	// are available, so a batch is up to maxUnavailable pods.
+	// Defaults to 0, which means no pause between batches.
+	BatchPauseSeconds int32 `json:"batchPauseSeconds,omitempty"`
+	// Steps defines the canary steps to update pods progressively for each new revision.
+	// The controller updates pods to the replicas of current step, and moves to the next step
+	// after the updated pods are ready and the pause of this step is over.
+	// Partition still works as the lower bound of pods in old revisions.
+	Steps []CloneSetUpdateStep `json:"steps,omitempty"`
+	// RollbackTo rolls back the template to a recorded ControllerRevision of this CloneSet.
+	// During rollback, the template is kept as the revision, and partition, steps and paused are ignored,
+	// so that all pods will be updated to the revision with the maxUnavailable of rollback.
+	// It will be cleared by the controller once all pods have been updated to the revision.
+	RollbackTo *CloneSetRollback `json:"rollbackTo,omitempty"`
+}
+
+// CloneSetRollback defines the revision and speed to roll back a CloneSet.
+type CloneSetRollback struct {
+	// Revision is the name of ControllerRevision to roll back to.
+	Revision string `json:"revision"`
+	// MaxUnavailable is the maximum number of pods that can be unavailable during rollback,
+	// which overrides the maxUnavailable in updateStrategy.
+	// Defaults to the maxUnavailable in updateStrategy.
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// CloneSetUpdateStep defines a canary step of CloneSet update.
+type CloneSetUpdateStep struct {
+	// Replicas is the number of pods that should be updated in this step.
+	// Value can be an absolute number (ex: 5) or a percentage of desired pods (ex: 10%).
+	// Absolute number is calculated from percentage by rounding up.
+	Replicas intstr.IntOrString `json:"replicas"`
+	// Pause defines how long to pause after the updated pods of this step are ready.
+	Pause CloneSetUpdateStepPause `json:"pause,omitempty"`
+}
+
+// CloneSetUpdateStepPause defines the pause of a canary step.
+type CloneSetUpdateStepPause struct {
+	// Duration is the seconds to pause before moving to the next step.
+	// If not set, the step pauses until it is resumed by annotation apps.kruise.io/canary-resume-step.
+	Duration *int32 `json:"duration,omitempty"`
 }
 
+// CloneSetPartitionRoundingType defines how to round a percentage partition.
+// +kubebuilder:validation:Enum=Up;Down
+type CloneSetPartitionRoundingType string
+
+const (
+	// CloneSetPartitionRoundUp rounds up the percentage partition, which is the default.
+	CloneSetPartitionRoundUp CloneSetPartitionRoundingType = "Up"
+	// CloneSetPartitionRoundDown rounds down the percentage partition.
+	CloneSetPartitionRoundDown CloneSetPartitionRoundingType = "Down"
+)
+
 // CloneSetUpdateStrategyType defines strategies for pods in-place update.
 type CloneSetUpdateStrategyType string
 
@@ -188,8 +282,110 @@ type CloneSetStatus struct {
 
 	// LabelSelector is label selectors for query over pods that should match the replica count used by HPA.
 	LabelSelector string `json:"labelSelector,omitempty"`
+
+	// CanaryStatus is the progress of canary steps for the update revision.
+	CanaryStatus *CloneSetCanaryStatus `json:"canaryStatus,omitempty"`
+
+	// VolumeClaimTemplates is the expansion progress of the existing PVCs for each volumeClaimTemplate,
+	// which only works when volumeClaimUpdateStrategy type is Expand.
+	VolumeClaimTemplates []CloneSetVolumeClaimTemplateStatus `json:"volumeClaimTemplates,omitempty"`
+
+	// RevisionDiff is the diff from currentRevision to updateRevision, which is empty if they are the same.
+	// +optional
+	RevisionDiff *appspub.RevisionDiff `json:"revisionDiff,omitempty"`
+}
+
+// CloneSetPodNamingPolicyType defines how to generate the instance-id of pods.
+type CloneSetPodNamingPolicyType string
+
+const (
+	// CloneSetRandomPodNaming generates a random string as instance-id.
+	CloneSetRandomPodNaming CloneSetPodNamingPolicyType = "Random"
+	// CloneSetOrdinalPodNaming generates the lowest available ordinal as instance-id.
+	CloneSetOrdinalPodNaming CloneSetPodNamingPolicyType = "Ordinal"
+)
+
+// CloneSetVolumeClaimUpdateStrategy defines strategies for updating the existing PVCs.
+type CloneSetVolumeClaimUpdateStrategy struct {
+	// Type indicates the type of the CloneSetVolumeClaimUpdateStrategy.
+	// Default is OnDelete.
+	Type CloneSetVolumeClaimUpdateStrategyType `json:"type,omitempty"`
+	// ReusePolicy indicates whether the PVCs of pods deleted for recreation should be reused by the new pods.
+	// Default is Never.
+	ReusePolicy CloneSetVolumeClaimReusePolicyType `json:"reusePolicy,omitempty"`
 }
 
+// CloneSetVolumeClaimReusePolicyType defines whether to reuse the PVCs of recreated pods.
+type CloneSetVolumeClaimReusePolicyType string
+
+const (
+	// NeverVolumeClaimReusePolicyType indicates that the PVCs are deleted along with the pods,
+	// and new PVCs are provisioned for the new pods.
+	NeverVolumeClaimReusePolicyType CloneSetVolumeClaimReusePolicyType = "Never"
+	// OnRecreateVolumeClaimReusePolicyType indicates that the PVCs of pods deleted for recreation, such as recreate update
+	// and specified delete, are kept and adopted by the new pods with the same instance-id.
+	// Since the volumes have been bound, the new pods are scheduled following the topology of the volumes,
+	// e.g., to the same node for local volumes.
+	// Note that the PVCs are not reused if the new pods are created before the old ones deleted, such as with maxSurge.
+	OnRecreateVolumeClaimReusePolicyType CloneSetVolumeClaimReusePolicyType = "OnRecreate"
+)
+
+// CloneSetVolumeClaimUpdateStrategyType defines strategies for updating the existing PVCs.
+type CloneSetVolumeClaimUpdateStrategyType string
+
+const (
+	// OnDeleteVolumeClaimUpdateStrategyType indicates that the existing PVCs will not be changed,
+	// and only the PVCs created for new Pods use the latest volumeClaimTemplates.
+	// Updates of volumeClaimTemplates are forbidden with this type.
+	OnDeleteVolumeClaimUpdateStrategyType CloneSetVolumeClaimUpdateStrategyType = "OnDelete"
+	// ExpandVolumeClaimUpdateStrategyType indicates that increasing the storage requests in volumeClaimTemplates is allowed,
+	// and the storage requests of the existing PVCs will be expanded if their StorageClasses allow volume expansion.
+	ExpandVolumeClaimUpdateStrategyType CloneSetVolumeClaimUpdateStrategyType = "Expand"
+)
+
+// CloneSetVolumeClaimTemplateStatus is the expansion progress of the existing PVCs for a volumeClaimTemplate.
+type CloneSetVolumeClaimTemplateStatus struct {
+	// Name is the name of the volumeClaimTemplate.
+	Name string `json:"name"`
+
+	// Storage is the storage requests in the volumeClaimTemplate.
+	Storage resource.Quantity `json:"storage"`
+
+	// ExpandedReplicas is the number of bound PVCs whose capacity has satisfied the storage requests.
+	ExpandedReplicas int32 `json:"expandedReplicas"`
+
+	// ExpandingReplicas is the number of bound PVCs whose capacity is less than the storage requests.
+	ExpandingReplicas int32 `json:"expandingReplicas"`
+
+	// UnsupportedReplicas is the number of bound PVCs that can not be expanded,
+	// because their StorageClasses do not allow volume expansion.
+	UnsupportedReplicas int32 `json:"unsupportedReplicas,omitempty"`
+}
+
+// CloneSetCanaryStatus is the progress of canary steps.
+type CloneSetCanaryStatus struct {
+	// Revision is the update revision that the canary steps are executed for.
+	Revision string `json:"revision"`
+	// CurrentStepIndex is the index of the step being executed, which equals to the length of steps when all steps completed.
+	CurrentStepIndex int32 `json:"currentStepIndex"`
+	// CurrentStepState is the state of the step being executed.
+	CurrentStepState CloneSetCanaryStepState `json:"currentStepState"`
+	// LastTransitionTime is the last time the step or its state changed.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// CloneSetCanaryStepState is the state of a canary step.
+type CloneSetCanaryStepState string
+
+const (
+	// CloneSetCanaryStepUpgrading indicates the pods of this step are being updated.
+	CloneSetCanaryStepUpgrading CloneSetCanaryStepState = "Upgrading"
+	// CloneSetCanaryStepPaused indicates the updated pods of this step are ready, and it is paused.
+	CloneSetCanaryStepPaused CloneSetCanaryStepState = "Paused"
+	// CloneSetCanaryStepCompleted indicates all steps have been completed.
+	CloneSetCanaryStepCompleted CloneSetCanaryStepState = "Completed"
+)
+
 // CloneSetConditionType is type for CloneSet conditions.
 type CloneSetConditionType string
 
@@ -198,6 +394,27 @@ const (
 	CloneSetConditionFailedScale CloneSetConditionType = "FailedScale"
 	// CloneSetConditionFailedUpdate indicates cloneset controller failed to update pods.
 	CloneSetConditionFailedUpdate CloneSetConditionType = "FailedUpdate"
+	// CloneSetConditionCanaryPaused indicates whether the canary step is paused.
+	CloneSetConditionCanaryPaused CloneSetConditionType = "CanaryPaused"
+	// CloneSetConditionLifecycleHookTimeout indicates some pods are still blocked by lifecycle hooks that have timed out.
+	CloneSetConditionLifecycleHookTimeout CloneSetConditionType = "LifecycleHookTimeout"
+	// CloneSetConditionProgressing indicates whether the CloneSet is progressing to the update revision,
+	// which only exists if progressDeadlineSeconds is set.
+	CloneSetConditionProgressing CloneSetConditionType = "Progressing"
+	// CloneSetConditionDegraded indicates the CloneSet has not made any progress for progressDeadlineSeconds.
+	CloneSetConditionDegraded CloneSetConditionType = "Degraded"
+)
+
+// Reasons for CloneSet Progressing conditions.
+const (
+	// CloneSetProgressingReason means the CloneSet is progressing to the update revision.
+	CloneSetProgressingReason = "CloneSetProgressing"
+	// CloneSetAvailableReason means all expected pods of the update revision are available.
+	CloneSetAvailableReason = "NewRevisionAvailable"
+	// CloneSetPausedReason means the CloneSet or its canary step is paused.
+	CloneSetPausedReason = "CloneSetPaused"
+	// CloneSetProgressDeadlineExceededReason means the CloneSet has not made any progress for progressDeadlineSeconds.
+	CloneSetProgressDeadlineExceededReason = "ProgressDeadlineExceeded"
 )
 
 // CloneSetCondition describes the state of a CloneSet at a certain point.
@@ -206,6 +423,8 @@ type CloneSetCondition struct {
 	Type CloneSetConditionType `json:"type"`
 	// Status of the condition, one of True, False, Unknown.
 	Status v1.ConditionStatus `json:"status"`
+	// The last time this condition was updated.
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
 	// Last time the condition transitioned from one status to another.
 	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
 	// The reason for the condition's last transition.
@@ -39,6 +39,9 @@ const (
 	// ContainerRecreateRequestUnreadyAcquiredKey indicates the Pod has been forced to not-ready.
 	// It is required if the unreadyGracePeriodSeconds is set in ContainerRecreateRequests.
 	ContainerRecreateRequestUnreadyAcquiredKey = "crr.apps.kruise.io/unready-acquired"
+	// ContainerRecreateRequestPubGrantedKey indicates the PodUnavailableBudget of the Pod has allowed to recreate the containers.
+	// It is labeled by kruise-manager, and kruise-daemon waits for it before recreating unless forceRecreate is set.
+	ContainerRecreateRequestPubGrantedKey = "crr.apps.kruise.io/pub-granted"
 )
 
 // ContainerRecreateRequestSpec defines the desired state of ContainerRecreateRequest
@@ -52,6 +55,7 @@ type ContainerRecreateRequestSpec struct {
 	// ActiveDeadlineSeconds is the deadline duration of this ContainerRecreateRequest.
 	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
 	// TTLSecondsAfterFinished is the TTL duration after this ContainerRecreateRequest has completed.
+	// If not set, the default TTL of kruise-manager is used, which is never deleted unless configured.
 	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
 }
 
@@ -60,6 +64,34 @@ type ContainerRecreateRequestContainer struct {
 	// Name of the container that need to recreate.
 	// It must be existing in the real pod.Spec.Containers.
 	Name string `json:"name"`
+	// MinStartedSeconds overrides strategy.minStartedSeconds for this container, which is the minimum number of
+	// seconds for which the newly created container should be started and ready to be considered Succeeded.
+	// +optional
+	MinStartedSeconds *int32 `json:"minStartedSeconds,omitempty"`
+	// ReadinessTimeoutSeconds is the maximum duration in seconds to wait for the newly created container to be ready
+	// after it started. Once exceeded, the recreation of this container is considered failed.
+	// Defaults to nil, which means waiting until activeDeadlineSeconds.
+	// +optional
+	ReadinessTimeoutSeconds *int32 `json:"readinessTimeoutSeconds,omitempty"`
+	// MaxRetries is the number of times to recreate this container again if the recreation fails, for the container
+	// can not be stopped or the new one is not ready within readinessTimeoutSeconds.
+	// Retries are delayed with exponential backoff defined by strategy.retryBackoffSeconds.
+	// Defaults to 0, which means no retry.
+	// +optional
+	MaxRetries int32 `json:"maxRetries,omitempty"`
+	// Signal is the signal sent to the main process of the container to stop it after the preStop hook,
+	// for applications that drain gracefully on a non-default signal. The container will still be stopped
+	// by its default stop signal if not exited within the termination grace period.
+	// It is sent by the `kill` command to PID 1 in the container, so the command must exist in the container image,
+	// otherwise a FailedSignal event is recorded and the container is stopped by its default stop signal.
+	// It is not allowed for Pods with shareProcessNamespace, whose PID 1 is the pause process.
+	// Defaults to empty, which means the default stop signal of the container.
+	// +optional
+	Signal ContainerRecreateRequestSignal `json:"signal,omitempty"`
+	// TerminationGracePeriodSeconds overrides strategy.terminationGracePeriodSeconds for this container.
+	// Value must be non-negative integer. The value zero indicates delete immediately.
+	// +optional
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
 	// PreStop is synced from the real container in Pod spec during this ContainerRecreateRequest creating.
 	// Populated by the system.
 	// Read-only.
@@ -74,6 +106,10 @@ type ContainerRecreateRequestContainer struct {
 	StatusContext *ContainerRecreateRequestContainerContext `json:"statusContext,omitempty"`
 }
 
+// ContainerRecreateRequestSignal is the signal sent to a container to stop it.
+// +kubebuilder:validation:Enum=SIGTERM;SIGINT;SIGQUIT;SIGHUP;SIGUSR1;SIGUSR2;SIGWINCH
+type ContainerRecreateRequestSignal string
+
 // ProbeHandler defines a specific action that should be taken
 // TODO(FillZpp): improve the definition when openkruise/kruise updates to k8s 1.23
 type ProbeHandler struct {
@@ -107,6 +143,7 @@ type ContainerRecreateRequestStrategy struct {
 	// FailurePolicy decides whether to continue if one container fails to recreate
 	FailurePolicy ContainerRecreateRequestFailurePolicyType `json:"failurePolicy,omitempty"`
 	// OrderedRecreate indicates whether to recreate the next container only if the previous one has recreated completely.
+	// The containers are recreated in the order of spec.containers.
 	OrderedRecreate bool `json:"orderedRecreate,omitempty"`
 	// TerminationGracePeriodSeconds is the optional duration in seconds to wait the container terminating gracefully.
 	// Value must be non-negative integer. The value zero indicates delete immediately.
@@ -119,6 +156,14 @@ type ContainerRecreateRequestStrategy struct {
 	// without any of its container crashing, for it to be considered Succeeded.
 	// Defaults to 0 (container will be considered Succeeded as soon as it is started and ready)
 	MinStartedSeconds int32 `json:"minStartedSeconds,omitempty"`
+	// ForceRecreate indicates to recreate the containers without waiting for the PodUnavailableBudget of the Pod.
+	// By default, the recreation stays Pending until the PodUnavailableBudget allows the Pod to be unavailable.
+	// It should only be used in emergencies.
+	ForceRecreate bool `json:"forceRecreate,omitempty"`
+	// RetryBackoffSeconds is the delay in seconds before the first retry of a failed container,
+	// which doubles on each subsequent retry and is capped at 5 minutes.
+	// Defaults to 10.
+	RetryBackoffSeconds *int32 `json:"retryBackoffSeconds,omitempty"`
 }
 
 type ContainerRecreateRequestFailurePolicyType string
@@ -160,6 +205,14 @@ type ContainerRecreateRequestContainerRecreateState struct {
 	Phase ContainerRecreateRequestPhase `json:"phase"`
 	// A human readable message indicating details about this state.
 	Message string `json:"message,omitempty"`
+	// Retries is the number of times the recreation of the container has failed and been retried.
+	Retries int32 `json:"retries,omitempty"`
+	// LastFailureTime is the time when the last recreation of the container failed,
+	// and the next retry is delayed since then.
+	LastFailureTime *metav1.Time `json:"lastFailureTime,omitempty"`
+	// ContainerID is the container to be recreated in the current retry, which was created by the last
+	// recreation but not ready in time. Empty means the one in statusContext.
+	ContainerID string `json:"containerID,omitempty"`
 }
 
 // ContainerRecreateRequestSyncContainerStatus only uses in the annotation `crr.apps.kruise.io/sync-container-statuses`.
@@ -0,0 +1,141 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// ContainerRecreateRequestSetLabelKey is the label of the ContainerRecreateRequests created by
+	// ContainerRecreateRequestSet, whose value is the name of the ContainerRecreateRequestSet.
+	ContainerRecreateRequestSetLabelKey = "crr.apps.kruise.io/crr-set-name"
+)
+
+// ContainerRecreateRequestSetSpec defines the desired state of ContainerRecreateRequestSet
+type ContainerRecreateRequestSetSpec struct {
+	// Selector is a label query over pods in the same namespace whose containers should be recreated.
+	// Pods created after the ContainerRecreateRequestSet started will be ignored.
+	Selector *metav1.LabelSelector `json:"selector"`
+
+	// Template describes the ContainerRecreateRequest that will be created for each pod.
+	Template ContainerRecreateRequestTemplate `json:"template"`
+
+	// MaxUnavailable is the maximum number of pods that can be recreating at the same time.
+	// Value can be an absolute number (ex: 5) or a percentage of the pods selected (ex: 5%).
+	// Absolute number is calculated from percentage by rounding up, and it is at least 1.
+	// Defaults to 1.
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+	// TopologyKey is the label key of nodes to recreate pods domain by domain, such as topology.kubernetes.io/zone.
+	// Pods in the next domain, sorted by the label values, will not be recreated until all pods in the previous
+	// domain have completed. If empty, pods are recreated regardless of their topology.
+	// +optional
+	TopologyKey string `json:"topologyKey,omitempty"`
+
+	// Paused indicates that no more ContainerRecreateRequests will be created.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+
+	// TTLSecondsAfterFinished is the TTL duration after this ContainerRecreateRequestSet has completed,
+	// and the ContainerRecreateRequests created by it will be deleted with it.
+	// +optional
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
+}
+
+// ContainerRecreateRequestTemplate describes the ContainerRecreateRequest created for each pod.
+type ContainerRecreateRequestTemplate struct {
+	// Containers contains the containers that need to recreate in the Pod.
+	Containers []ContainerRecreateRequestContainer `json:"containers"`
+	// Strategy defines strategies for containers recreation.
+	// +optional
+	Strategy *ContainerRecreateRequestStrategy `json:"strategy,omitempty"`
+	// ActiveDeadlineSeconds is the deadline duration of each ContainerRecreateRequest.
+	// +optional
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+}
+
+// ContainerRecreateRequestSetStatus defines the observed state of ContainerRecreateRequestSet
+type ContainerRecreateRequestSetStatus struct {
+	// Represents time when the ContainerRecreateRequestSet was acknowledged by the controller.
+	// It is represented in RFC3339 form and is in UTC.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// Represents time when the containers of all pods have been recreated.
+	// It is represented in RFC3339 form and is in UTC.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// The desired number of pods to recreate containers.
+	Desired int32 `json:"desired"`
+
+	// The number of pods whose ContainerRecreateRequests are running.
+	// +optional
+	Active int32 `json:"active"`
+
+	// The number of pods whose containers have been recreated successfully.
+	// +optional
+	Succeeded int32 `json:"succeeded"`
+
+	// The number of pods whose ContainerRecreateRequests have failed.
+	// +optional
+	Failed int32 `json:"failed"`
+
+	// The pods whose ContainerRecreateRequests have failed.
+	// +optional
+	FailedPods []string `json:"failedPods,omitempty"`
+
+	// A human readable message indicating details about this ContainerRecreateRequestSet.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// +genclient
+// +k8s:openapi-gen=true
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=crrs
+// +kubebuilder:printcolumn:name="TOTAL",type="integer",JSONPath=".status.desired",description="Number of pods to recreate containers"
+// +kubebuilder:printcolumn:name="ACTIVE",type="integer",JSONPath=".status.active",description="Number of pods recreating containers"
+// +kubebuilder:printcolumn:name="SUCCEEDED",type="integer",JSONPath=".status.succeeded",description="Number of pods recreated containers successfully"
+// +kubebuilder:printcolumn:name="FAILED",type="integer",JSONPath=".status.failed",description="Number of pods failed to recreate containers"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp",description="CreationTimestamp is a timestamp representing the server time when this object was created. It is not guaranteed to be set in happens-before order across separate operations. Clients may not set this value. It is represented in RFC3339 form and is in UTC."
+
+// ContainerRecreateRequestSet is the Schema for the containerrecreaterequestsets API
+type ContainerRecreateRequestSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ContainerRecreateRequestSetSpec   `json:"spec,omitempty"`
+	Status ContainerRecreateRequestSetStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ContainerRecreateRequestSetList contains a list of ContainerRecreateRequestSet
+type ContainerRecreateRequestSetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ContainerRecreateRequestSet `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ContainerRecreateRequestSet{}, &ContainerRecreateRequestSetList{})
+}
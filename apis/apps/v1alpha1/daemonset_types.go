@@ -111,10 +111,69 @@ type RollingUpdateDaemonSet struct {
 	// +optional
 	Partition *int32 `json:"partition,omitempty"`
 
+	// The percentage of DaemonSet pods remained to be old version, out of status.DesiredNumberScheduled,
+	// the number of nodes that should be running the daemon pod.
+	// Absolute number is calculated from percentage by rounding up.
+	// It can not be set together with Partition.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	PartitionPercent *int32 `json:"partitionPercent,omitempty"`
+
+	// ScatterTopologyKey is the label key of nodes that indicates the failure domain, such as
+	// topology.kubernetes.io/zone. If it is set, the nodes to update are interleaved across the domains,
+	// and the domains with fewer updated nodes come first, so that the update does not go through
+	// all the nodes of one domain before the others.
+	// +optional
+	ScatterTopologyKey string `json:"scatterTopologyKey,omitempty"`
+
 	// Indicates that the daemon set is paused and will not be processed by the
 	// daemon set controller.
 	// +optional
 	Paused *bool `json:"paused,omitempty"`
+
+	// NodePools sequences the rolling update through the ordered node pools.
+	// The nodes in a pool will not be updated until all the nodes in the previous pools are updated and available,
+	// and the pool is approved if it requires approval. The nodes not in any pool are updated after all the pools.
+	// +optional
+	NodePools []DaemonSetNodePool `json:"nodePools,omitempty"`
+
+	// ApprovedNodePools is the names of the node pools that are approved to update, which is required
+	// for the node pools with requireApproval.
+	// +optional
+	ApprovedNodePools []string `json:"approvedNodePools,omitempty"`
+
+	// CoLocatedPodUnavailableBudget is the PodUnavailableBudget protecting the pods that depend on the daemon pods
+	// on the same nodes. Before a daemon pod is restarted, the node will be deferred if the available pods protected
+	// by the budget on it are more than the unavailable pods allowed by the budget.
+	// It does not take effect when maxSurge is set, for the old daemon pods are deleted after the new ones are available.
+	// +optional
+	CoLocatedPodUnavailableBudget *CoLocatedPodUnavailableBudgetReference `json:"coLocatedPodUnavailableBudget,omitempty"`
+}
+
+// CoLocatedPodUnavailableBudgetReference is the reference to a PodUnavailableBudget.
+type CoLocatedPodUnavailableBudgetReference struct {
+	// Namespace of the PodUnavailableBudget, defaults to the namespace of DaemonSet.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the PodUnavailableBudget.
+	Name string `json:"name"`
+}
+
+// DaemonSetNodePool is a group of nodes to update together in the rolling update.
+type DaemonSetNodePool struct {
+	// Name is the unique name of the node pool.
+	Name string `json:"name"`
+
+	// Selector is a label query over the nodes in the node pool.
+	// A node belongs to the first node pool whose selector matches its labels.
+	Selector *metav1.LabelSelector `json:"selector"`
+
+	// RequireApproval indicates the rolling update should pause before this node pool,
+	// until its name is added into approvedNodePools.
+	// +optional
+	RequireApproval bool `json:"requireApproval,omitempty"`
 }
 
 // DaemonSetSpec defines the desired state of DaemonSet
@@ -162,6 +221,25 @@ type DaemonSetSpec struct {
 	// Currently, we only support pre-delete hook for Advanced DaemonSet.
 	// +optional
 	Lifecycle *appspub.Lifecycle `json:"lifecycle,omitempty"`
+
+	// NodeApproval holds the daemon pods on the nodes in standby until the nodes are approved.
+	// The daemon pod will not be created on a node, such as a newly added one, before the node is approved,
+	// and the daemon pods already running on the nodes are not affected, which are still recreated on the nodes
+	// once deleted or failed.
+	// +optional
+	NodeApproval *DaemonSetNodeApproval `json:"nodeApproval,omitempty"`
+}
+
+// DaemonSetNodeApproval defines how a node is approved to run the daemon pod.
+// A node is approved only if it matches all the specified requirements.
+type DaemonSetNodeApproval struct {
+	// Selector is a label query over the approved nodes.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// ConditionType is the type of node condition whose status must be True for the approved nodes.
+	// +optional
+	ConditionType corev1.NodeConditionType `json:"conditionType,omitempty"`
 }
 
 // DaemonSetStatus defines the observed state of DaemonSet
@@ -207,6 +285,11 @@ type DaemonSetStatus struct {
 	// +optional
 	NumberUnavailable int32 `json:"numberUnavailable,omitempty"`
 
+	// The number of nodes that should be running the daemon pod, but have
+	// none of the daemon pod created for waiting for the node approval.
+	// +optional
+	NumberStandby int32 `json:"numberStandby,omitempty"`
+
 	// Count of hash collisions for the DaemonSet. The DaemonSet controller
 	// uses this field as a collision avoidance mechanism when it needs to
 	// create the name for the newest ControllerRevision.
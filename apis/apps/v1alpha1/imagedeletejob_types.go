@@ -0,0 +1,186 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// ImageDeleteJobSpec defines the desired state of ImageDeleteJob
+type ImageDeleteJobSpec struct {
+	// Images is the list of images to be deleted from the nodes, such as nginx:1.21.
+	// If empty, all the images on the nodes are candidates, which should be limited by unusedDays.
+	// Images used by any container on the node, the sandbox image and the images pinned by runtime will never be deleted.
+	// +optional
+	Images []string `json:"images,omitempty"`
+
+	// UnusedDays limits the job to only delete the images which have not been used by any container
+	// on the node for at least these days.
+	// Note that the usage of images is recorded by kruise-daemon since it starts.
+	// +optional
+	UnusedDays *int32 `json:"unusedDays,omitempty"`
+
+	// Selector is a query over nodes that should delete the images.
+	// If nil, the images will be deleted on all the nodes.
+	// +optional
+	Selector *ImagePullJobNodeSelector `json:"selector,omitempty"`
+
+	// Parallelism is the max number of nodes deleting the images at the same time, it can be set to
+	// any non-negative value. If it is unspecified, it defaults to 1. If it is specified as 0,
+	// then the job is effectively paused until it is increased.
+	// +optional
+	Parallelism *intstr.IntOrString `json:"parallelism,omitempty"`
+
+	// ActiveDeadlineSeconds specifies the duration in seconds relative to the startTime that the job may be active
+	// before the system tries to terminate it; value must be positive integer.
+	// +optional
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+
+	// TTLSecondsAfterFinished limits the lifetime of a job that has finished execution.
+	// If this field is set, ttlSecondsAfterFinished after the job finishes, it is eligible to be automatically deleted.
+	// If this field is unset, the job won't be automatically deleted.
+	// +optional
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
+}
+
+// ImageDeleteJobStatus defines the observed state of ImageDeleteJob
+type ImageDeleteJobStatus struct {
+	// Represents time when the job was acknowledged by the job controller.
+	// It is not guaranteed to be set in happens-before order across separate operations.
+	// It is represented in RFC3339 form and is in UTC.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// Represents time when the job was completed. It is not guaranteed to
+	// be set in happens-before order across separate operations.
+	// It is represented in RFC3339 form and is in UTC.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// The desired number of nodes, this is typically equal to the number of nodes satisfied to the selector.
+	Desired int32 `json:"desired"`
+
+	// The number of nodes which are deleting the images.
+	// +optional
+	Active int32 `json:"active"`
+
+	// The number of nodes which have deleted the images successfully.
+	// +optional
+	Succeeded int32 `json:"succeeded"`
+
+	// The number of nodes which failed to delete the images.
+	// +optional
+	Failed int32 `json:"failed"`
+
+	// The status of deleting the images on each node started.
+	// +optional
+	NodeStatuses []ImageDeleteJobNodeStatus `json:"nodeStatuses,omitempty"`
+}
+
+// ImageDeleteJobNodeStatus is the status of deleting the images on a node.
+type ImageDeleteJobNodeStatus struct {
+	// NodeName is the name of the node.
+	NodeName string `json:"nodeName"`
+
+	// Phase is the phase of deleting the images on the node.
+	Phase ImageDeletePhase `json:"phase"`
+
+	// DeletedImages is the list of images deleted on the node.
+	// +optional
+	DeletedImages []string `json:"deletedImages,omitempty"`
+
+	// Represents time when the node started to delete the images.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// Represents time when the node finished deleting the images.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// The text prompt for deleting the images on the node.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ImageDeleteStatus is the status of deleting the images for an ImageDeleteJob reported by the node.
+type ImageDeleteStatus struct {
+	// JobUID is the uid of the ImageDeleteJob.
+	JobUID types.UID `json:"jobUID"`
+
+	// Phase is the phase of deleting the images on the node, which is Succeeded or Failed.
+	Phase ImageDeletePhase `json:"phase"`
+
+	// DeletedImages is the list of images deleted on the node.
+	// +optional
+	DeletedImages []string `json:"deletedImages,omitempty"`
+
+	// Represents time when the node finished deleting the images.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// The text prompt for deleting the images on the node.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ImageDeletePhase defines the phase of deleting the images on a node.
+type ImageDeletePhase string
+
+const (
+	// ImageDeletePhaseRunning means the node should be deleting the images.
+	ImageDeletePhaseRunning ImageDeletePhase = "Running"
+	// ImageDeletePhaseSucceeded means the images have been deleted on the node.
+	ImageDeletePhaseSucceeded ImageDeletePhase = "Succeeded"
+	// ImageDeletePhaseFailed means the node failed to delete the images.
+	ImageDeletePhaseFailed ImageDeletePhase = "Failed"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:openapi-gen=true
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="TOTAL",type="integer",JSONPath=".status.desired",description="Number of all nodes matched by this job"
+// +kubebuilder:printcolumn:name="ACTIVE",type="integer",JSONPath=".status.active",description="Number of nodes deleting the images"
+// +kubebuilder:printcolumn:name="SUCCEED",type="integer",JSONPath=".status.succeeded",description="Number of nodes deleted the images successfully"
+// +kubebuilder:printcolumn:name="FAILED",type="integer",JSONPath=".status.failed",description="Number of nodes failed to delete the images"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp",description="CreationTimestamp is a timestamp representing the server time when this object was created. It is not guaranteed to be set in happens-before order across separate operations. Clients may not set this value. It is represented in RFC3339 form and is in UTC."
+
+// ImageDeleteJob is the Schema for the imagedeletejobs API
+type ImageDeleteJob struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ImageDeleteJobSpec   `json:"spec,omitempty"`
+	Status ImageDeleteJobStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ImageDeleteJobList contains a list of ImageDeleteJob
+type ImageDeleteJobList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ImageDeleteJob `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ImageDeleteJob{}, &ImageDeleteJobList{})
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ImageListPullJobLabelKey is the label of the ImagePullJobs created by ImageListPullJob,
+	// whose value is the name of the ImageListPullJob.
+	ImageListPullJobLabelKey = "apps.kruise.io/image-list-pull-job"
+)
+
+// ImageListPullJobSpec defines the desired state of ImageListPullJob
+type ImageListPullJobSpec struct {
+	// Images is the image list to be pulled by the job.
+	// An ImagePullJob will be created for each image in the list.
+	Images []string `json:"images"`
+
+	ImagePullJobTemplate `json:",inline"`
+}
+
+// ImageListPullJobStatus defines the observed state of ImageListPullJob
+type ImageListPullJobStatus struct {
+	// Represents time when the job was acknowledged by the job controller.
+	// It is not guaranteed to be set in happens-before order across separate operations.
+	// It is represented in RFC3339 form and is in UTC.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// Represents time when the all the image pull job was completed. It is not guaranteed to
+	// be set in happens-before order across separate operations.
+	// It is represented in RFC3339 form and is in UTC.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// The desired number of ImagePullJobs, this is typically equal to the number of len(spec.images).
+	Desired int32 `json:"desired"`
+
+	// The number of running ImagePullJobs.
+	// +optional
+	Active int32 `json:"active"`
+
+	// The number of ImagePullJobs which are completed.
+	// +optional
+	Completed int32 `json:"completed"`
+
+	// The number of ImagePullJobs which are completed and have pulled the image on all the nodes.
+	// +optional
+	Succeeded int32 `json:"succeeded"`
+
+	// The status of pulling each image.
+	// +optional
+	ImageStatuses []ImageListPullJobImageStatus `json:"imageStatuses,omitempty"`
+}
+
+// ImageListPullJobImageStatus is the status of pulling an image in ImageListPullJob.
+type ImageListPullJobImageStatus struct {
+	// Image is the image to be pulled.
+	Image string `json:"image"`
+
+	// ImagePullJob is the name of the ImagePullJob which pulls the image.
+	ImagePullJob string `json:"imagePullJob"`
+
+	// The desired number of nodes to pull the image.
+	// +optional
+	Desired int32 `json:"desired"`
+
+	// The number of nodes which are pulling the image.
+	// +optional
+	Active int32 `json:"active"`
+
+	// The number of nodes which have pulled the image successfully.
+	// +optional
+	Succeeded int32 `json:"succeeded"`
+
+	// The number of nodes which failed to pull the image.
+	// +optional
+	Failed int32 `json:"failed"`
+
+	// Represents time when the ImagePullJob was completed.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// The text prompt for the ImagePullJob running status.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// +genclient
+// +k8s:openapi-gen=true
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="TOTAL",type="integer",JSONPath=".status.desired",description="Number of ImagePullJobs"
+// +kubebuilder:printcolumn:name="ACTIVE",type="integer",JSONPath=".status.active",description="Number of ImagePullJobs active"
+// +kubebuilder:printcolumn:name="COMPLETED",type="integer",JSONPath=".status.completed",description="Number of ImagePullJobs completed"
+// +kubebuilder:printcolumn:name="SUCCEEDED",type="integer",JSONPath=".status.succeeded",description="Number of ImagePullJobs succeeded on all the nodes"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp",description="CreationTimestamp is a timestamp representing the server time when this object was created. It is not guaranteed to be set in happens-before order across separate operations. Clients may not set this value. It is represented in RFC3339 form and is in UTC."
+
+// ImageListPullJob is the Schema for the imagelistpulljobs API
+type ImageListPullJob struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ImageListPullJobSpec   `json:"spec,omitempty"`
+	Status ImageListPullJobStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ImageListPullJobList contains a list of ImageListPullJob
+type ImageListPullJobList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ImageListPullJob `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ImageListPullJob{}, &ImageListPullJobList{})
+}
@@ -25,6 +25,21 @@ const (
 	ImagePreDownloadParallelismKey      = "apps.kruise.io/image-predownload-parallelism"
 	ImagePreDownloadTimeoutSecondsKey   = "apps.kruise.io/image-predownload-timeout-seconds"
 	ImagePreDownloadMinUpdatedReadyPods = "apps.kruise.io/image-predownload-min-updated-ready-pods"
+
+	// ImagePreDownloadScopeKey indicates the nodes to pre-download images, which can be:
+	// - Pods: the nodes that currently run the pods of workload, which is the default.
+	// - Affinity: the nodes matching the nodeSelector and required node affinity of the workload template.
+	//   It falls back to Pods if the scheduling hints can not be expressed by a label selector.
+	ImagePreDownloadScopeKey = "apps.kruise.io/image-predownload-scope"
+	// ImagePreDownloadCompletionThresholdKey is the number or percentage of nodes that should have pulled the images,
+	// before the first pods updated to the new revision. The update continues once the ImagePullJobs have completed,
+	// even if the threshold has not been reached.
+	ImagePreDownloadCompletionThresholdKey = "apps.kruise.io/image-predownload-completion-threshold"
+)
+
+const (
+	ImagePreDownloadScopePods     = "Pods"
+	ImagePreDownloadScopeAffinity = "Affinity"
 )
 
 // ImagePullJobSpec defines the desired state of ImagePullJob
@@ -32,6 +47,11 @@ type ImagePullJobSpec struct {
 	// Image is the image to be pulled by the job
 	Image string `json:"image"`
 
+	ImagePullJobTemplate `json:",inline"`
+}
+
+// ImagePullJobTemplate defines the pulling task of the images, which is shared by ImagePullJob and ImageListPullJob.
+type ImagePullJobTemplate struct {
 	// ImagePullSecrets is an optional list of references to secrets in the same namespace to use for pulling the image.
 	// If specified, these secrets will be passed to individual puller implementations for them to use.  For example,
 	// in the case of docker, only DockerConfig type secrets are honored.
@@ -58,6 +78,13 @@ type ImagePullJobSpec struct {
 	// +optional
 	PullPolicy *PullPolicy `json:"pullPolicy,omitempty"`
 
+	// Verification is an optional field to verify the signatures of the image before pulling it on any node.
+	// If specified, the job fails without pulling when no valid signature of the image is found,
+	// otherwise nodes pull the image by the verified digest.
+	// The image and verification of ImagePullJob can not be changed once verification is specified.
+	// +optional
+	Verification *ImageVerification `json:"verification,omitempty"`
+
 	// CompletionPolicy indicates the completion policy of the job.
 	// Default is Always CompletionPolicyType.
 	CompletionPolicy CompletionPolicy `json:"completionPolicy"`
@@ -92,8 +119,84 @@ type PullPolicy struct {
 	// Defaults to 3
 	// +optional
 	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+
+	// Distributor delegates the pulling task to a P2P distributor on the nodes instead of pulling the image
+	// from registry directly, which avoids making the registry a hotspot when pulling on thousands of nodes.
+	// +optional
+	Distributor *ImagePullDistributor `json:"distributor,omitempty"`
+}
+
+// ImagePullDistributor defines the P2P distributor that the pulling tasks are delegated to.
+type ImagePullDistributor struct {
+	// Type is the type of the distributor, which can be Dragonfly or Kraken.
+	Type ImagePullDistributorType `json:"type"`
+
+	// Endpoint is the address of the registry mirror served by the distributor agent on each node,
+	// such as 127.0.0.1:65001 for Dragonfly dfdaemon and 127.0.0.1:16000 for Kraken agent.
+	// The runtime on nodes should be allowed to pull from it by plain http if it is not a loopback address.
+	// The registry of the image is kept as the first path component of the repository in the mirror, such as
+	// 127.0.0.1:65001/ghcr.io/foo/bar for ghcr.io/foo/bar, so the distributor should route the repositories to
+	// their registries by it. The images in registries with ports can not be pulled through distributor.
+	Endpoint string `json:"endpoint"`
 }
 
+// ImagePullDistributorType defines the type of P2P distributor
+type ImagePullDistributorType string
+
+const (
+	// ImagePullDistributorDragonfly means pulling images through the registry mirror of Dragonfly dfdaemon
+	ImagePullDistributorDragonfly ImagePullDistributorType = "Dragonfly"
+	// ImagePullDistributorKraken means pulling images through the registry mirror of Kraken agent
+	ImagePullDistributorKraken ImagePullDistributorType = "Kraken"
+)
+
+// ImageVerification defines how to verify the signatures of the image.
+// Note that the image is resolved and verified once when the job starts, so the tag should not be moved while pulling.
+type ImageVerification struct {
+	// Type is the type of the signatures, which can be Cosign or Notation.
+	Type ImageSignatureType `json:"type"`
+
+	// PublicKey is the PEM encoded public key to verify the Cosign signatures signed by the key.
+	// The transparency log is not checked for signatures signed by keys.
+	// +optional
+	PublicKey string `json:"publicKey,omitempty"`
+
+	// Keyless verifies the Cosign signatures signed by ephemeral keys, whose certificates are issued by Fulcio
+	// and whose signing time is recorded in Rekor transparency log.
+	// +optional
+	Keyless *ImageVerificationKeyless `json:"keyless,omitempty"`
+
+	// TrustedCertificates is the PEM encoded root certificates trusted to issue the signing certificates
+	// of the Notation signatures.
+	// +optional
+	TrustedCertificates string `json:"trustedCertificates,omitempty"`
+}
+
+// ImageVerificationKeyless defines the identity and roots to verify the keyless Cosign signatures.
+type ImageVerificationKeyless struct {
+	// Roots is the PEM encoded root certificates of the certificate authority, such as Fulcio.
+	Roots string `json:"roots"`
+
+	// RekorPublicKey is the PEM encoded public key of Rekor to verify the signed entry timestamps.
+	RekorPublicKey string `json:"rekorPublicKey"`
+
+	// Issuer is the OIDC issuer expected in the signing certificates, such as https://accounts.google.com.
+	Issuer string `json:"issuer"`
+
+	// Subject is the identity expected in the signing certificates, which is an email or URI.
+	Subject string `json:"subject"`
+}
+
+// ImageSignatureType defines the type of image signatures
+type ImageSignatureType string
+
+const (
+	// ImageSignatureCosign means the signatures stored by cosign with the tag sha256-<digest>.sig
+	ImageSignatureCosign ImageSignatureType = "Cosign"
+	// ImageSignatureNotation means the JWS signatures stored by notation as referrers of the image
+	ImageSignatureNotation ImageSignatureType = "Notation"
+)
+
 // ImagePullJobStatus defines the observed state of ImagePullJob
 type ImagePullJobStatus struct {
 	// Represents time when the job was acknowledged by the job controller.
@@ -130,6 +233,10 @@ type ImagePullJobStatus struct {
 	// The nodes that failed to pull the image.
 	// +optional
 	FailedNodes []string `json:"failedNodes,omitempty"`
+
+	// VerifiedDigest is the digest of the image whose signatures have been verified, which is pulled on nodes.
+	// +optional
+	VerifiedDigest string `json:"verifiedDigest,omitempty"`
 }
 
 // +genclient
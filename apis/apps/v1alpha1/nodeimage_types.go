@@ -21,6 +21,21 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const (
+	// NodeCRISocketAnnotationKey can be annotated on the Node to specify the CRI socket used by kruise-daemon on it,
+	// which should be under /var/run or /run of the node, such as unix:///var/run/custom/containerd.sock.
+	// It is synced into the NodeImage of the Node, and read by kruise-daemon only at startup,
+	// so kruise-daemon on the node should be restarted after it is changed.
+	NodeCRISocketAnnotationKey = "apps.kruise.io/cri-socket"
+	// NodeContainerdNamespaceAnnotationKey can be annotated on the Node to specify the containerd namespace
+	// which kruise-daemon pulls images into on it. It is also synced into the NodeImage and read only at startup.
+	NodeContainerdNamespaceAnnotationKey = "apps.kruise.io/containerd-namespace"
+
+	// NodeImageShardOfLabelKey is labeled on the extra shards of NodeImage for a node, whose value is the node name.
+	// The first shard is always named as the node itself and has no such label.
+	NodeImageShardOfLabelKey = "apps.kruise.io/nodeimage-shard-of"
+)
+
 // NodeImageSpec defines the desired state of NodeImage
 type NodeImageSpec struct {
 	// Specifies images to be pulled on this node
@@ -102,6 +117,11 @@ type ImageTagPullPolicy struct {
 	// if not specified, the system will never terminate it.
 	// +optional
 	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+
+	// Distributor delegates the pulling task to a P2P distributor on the node instead of pulling the image
+	// from registry directly.
+	// +optional
+	Distributor *ImagePullDistributor `json:"distributor,omitempty"`
 }
 
 // NodeImageStatus defines the observed state of NodeImage
@@ -122,12 +142,19 @@ type NodeImageStatus struct {
 	Pulling int32 `json:"pulling"`
 
 	// all statuses of active image pulling tasks
+	// The statuses of tasks completed for a long time are compacted by kruise-daemon, which only keep
+	// the tag, phase, version, completionTime and the message of failure.
 	ImageStatuses map[string]ImageStatus `json:"imageStatuses,omitempty"`
 
 	// The first of all job has finished on this node. When a node is added to the cluster, we want to know
 	// the time when the node's image pulling is completed, and use it to trigger the operation of the upper system.
 	// +optional
 	FirstSyncStatus *SyncStatus `json:"firstSyncStatus,omitempty"`
+
+	// The statuses of deleting images on this node for ImageDeleteJobs, keyed by the job name.
+	// They are reported by kruise-daemon and aggregated into the ImageDeleteJob status by kruise-manager.
+	// +optional
+	ImageDeleteStatuses map[string]ImageDeleteStatus `json:"imageDeleteStatuses,omitempty"`
 }
 
 // ImageStatus defines the pulling status of an image
@@ -148,6 +175,10 @@ type ImageTagStatus struct {
 	// of monotonic consistency, and it may be a rollback due to retry during pulling.
 	Progress int32 `json:"progress,omitempty"`
 
+	// Represents the detail of the pulling progress, which is reported by kruise-daemon at a throttled interval.
+	// +optional
+	ProgressDetail *ImagePullProgressDetail `json:"progressDetail,omitempty"`
+
 	// Represents time when the pulling task was acknowledged by the image puller.
 	// It is not guaranteed to be set in happens-before order across separate operations.
 	// It is represented in RFC3339 form and is in UTC.
@@ -173,6 +204,26 @@ type ImageTagStatus struct {
 	Message string `json:"message,omitempty"`
 }
 
+// ImagePullProgressDetail defines the detail of the pulling progress of an image tag
+type ImagePullProgressDetail struct {
+	// Represents the bytes of the layers that have been downloaded.
+	// +optional
+	DownloadedBytes int64 `json:"downloadedBytes,omitempty"`
+
+	// Represents the total bytes of the layers known so far, which may increase during pulling.
+	// +optional
+	TotalBytes int64 `json:"totalBytes,omitempty"`
+
+	// Represents the layer being downloaded currently.
+	// +optional
+	CurrentLayer string `json:"currentLayer,omitempty"`
+
+	// Represents the last time the downloaded bytes increased, which tells a stuck pulling
+	// from a slow one if it is far from now.
+	// +optional
+	LastProgressTime *metav1.Time `json:"lastProgressTime,omitempty"`
+}
+
 // ImagePullPhase defines the tasks status
 type ImagePullPhase string
 
@@ -31,6 +31,10 @@ type SidecarSetSpec struct {
 	// otherwise, match pods in all namespaces(in cluster)
 	Namespace string `json:"namespace,omitempty"`
 
+	// NamespaceSelector is a label query over namespaces, sidecarSet will only match the pods in the namespaces
+	// whose labels match the selector. It can not be set together with Namespace.
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
 	// InitContainers is the list of init containers to be injected into the selected pod
 	// We will inject those containers by their name in ascending order
 	// We only inject init containers when a new pod is created, it does not apply to any existing pod
@@ -50,6 +54,11 @@ type SidecarSetSpec struct {
 	// InjectionStrategy describe the strategy when sidecarset is injected into pods
 	InjectionStrategy SidecarSetInjectionStrategy `json:"injectionStrategy,omitempty"`
 
+	// UninjectionStrategy describe the strategy to uninject the sidecars from the injected pods,
+	// when sidecarset is deleted or the pods no longer match it.
+	// If it is nil, the sidecars will be left in the pods until the pods are recreated.
+	UninjectionStrategy *SidecarSetUninjectionStrategy `json:"uninjectionStrategy,omitempty"`
+
 	// List of the names of secrets required by pulling sidecar container images
 	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
 
@@ -71,6 +80,27 @@ type SidecarContainer struct {
 	// default BeforeAppContainerType
 	PodInjectPolicy PodInjectPolicyType `json:"podInjectPolicy,omitempty"`
 
+	// InitContainerInjectPolicy is the rule that injected SidecarContainer into Pod.spec.initContainers,
+	// only takes effect in initContainers.
+	// If BeforeAppContainer, the SidecarContainer will be injected in front of the pod.spec.initContainers
+	// otherwise it will be injected into the back.
+	// default AfterAppContainerType
+	// +optional
+	InitContainerInjectPolicy PodInjectPolicyType `json:"initContainerInjectPolicy,omitempty"`
+
+	// InjectWeight orders the sidecar containers injected into the same side of the app containers or initContainers,
+	// the ones with higher weight are injected in front. The sidecar containers with the same weight
+	// keep the order in SidecarSets, and the initContainers with the same weight are ordered by name.
+	// default is 0
+	// +optional
+	InjectWeight int32 `json:"injectWeight,omitempty"`
+
+	// LaunchPriority is injected as the KRUISE_CONTAINER_PRIORITY env of the sidecar container,
+	// so that the containers in pod will be started in order of the priority, the higher is started earlier.
+	// It only takes effect in containers.
+	// +optional
+	LaunchPriority *int32 `json:"launchPriority,omitempty"`
+
 	// sidecarContainer upgrade strategy, include: ColdUpgrade, HotUpgrade
 	UpgradeStrategy SidecarContainerUpgradeStrategy `json:"upgradeStrategy,omitempty"`
 
@@ -85,6 +115,35 @@ type SidecarContainer struct {
 
 type ShareVolumePolicy struct {
 	Type ShareVolumePolicyType `json:"type,omitempty"`
+
+	// IncludeVolumes is the names of the volumes that will be shared with the sidecar container.
+	// If it is empty, all the volumes mounted by the other containers will be shared.
+	// +optional
+	IncludeVolumes []string `json:"includeVolumes,omitempty"`
+
+	// ExcludeVolumes is the names of the volumes that will not be shared with the sidecar container,
+	// and it takes precedence over IncludeVolumes.
+	// +optional
+	ExcludeVolumes []string `json:"excludeVolumes,omitempty"`
+
+	// MountOverrides transforms the shared volumeMounts before they are injected into the sidecar container.
+	// +optional
+	MountOverrides []ShareVolumeMountOverride `json:"mountOverrides,omitempty"`
+}
+
+// ShareVolumeMountOverride overrides the volumeMounts of the volume shared with the sidecar container.
+type ShareVolumeMountOverride struct {
+	// VolumeName is the name of the shared volume.
+	VolumeName string `json:"volumeName"`
+
+	// SubPath overrides the subPath of the volumeMounts, and the subPathExpr of them will be cleared.
+	// An empty string means the root of the volume.
+	// +optional
+	SubPath *string `json:"subPath,omitempty"`
+
+	// ReadOnly overrides the readOnly of the volumeMounts.
+	// +optional
+	ReadOnly *bool `json:"readOnly,omitempty"`
 }
 
 type PodInjectPolicyType string
@@ -133,6 +192,19 @@ type SidecarContainerUpgradeStrategy struct {
 	// HotUpgradeEmptyImage is consistent of sidecar container in Command, Args, Liveness probe, etc.
 	// but it does no actual work.
 	HotUpgradeEmptyImage string `json:"hotUpgradeEmptyImage,omitempty"`
+
+	// HotUpgradeMigrationHook is executed in the old sidecar container after the new one is ready,
+	// and before the old one is reset to HotUpgradeEmptyImage, to trigger connection draining or state handoff.
+	// The old sidecar container will not be reset until the hook succeeds, so the hook should be idempotent.
+	// Only exec and httpGet are supported, and httpGet is always sent to the pod ip so host is forbidden.
+	// Exec requires kruise-manager to be granted pods/exec in the namespace of the pods,
+	// e.g. by binding ClusterRole kruise-sidecarset-migration-hook-role with a RoleBinding.
+	HotUpgradeMigrationHook *ProbeHandler `json:"hotUpgradeMigrationHook,omitempty"`
+
+	// HotUpgradeMinReadySeconds is the minimum number of seconds for which the new sidecar container
+	// should be running and ready, before the migration hook is executed and the old one is reset to HotUpgradeEmptyImage.
+	// Defaults to 0 (the old one will be reset as soon as the new one is ready)
+	HotUpgradeMinReadySeconds int32 `json:"hotUpgradeMinReadySeconds,omitempty"`
 }
 
 // SidecarSetInjectionStrategy indicates the injection strategy of SidecarSet.
@@ -142,6 +214,33 @@ type SidecarSetInjectionStrategy struct {
 	// but the injected sidecar container remains updating and running.
 	// default is false
 	Paused bool `json:"paused,omitempty"`
+
+	// Percent indicates the percentage of the newly created matching pods that SidecarSet will be injected into,
+	// which makes it possible to canary a new sidecar before injecting it into all the matching pods.
+	// The pods are chosen deterministically by the hash of pod name, and a pod chosen by a smaller percent
+	// will still be chosen when the percent increases.
+	// default is 100, which means all the newly created matching pods will be injected
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	Percent *int32 `json:"percent,omitempty"`
+
+	// Ephemeral indicates that the SidecarSet is a debug profile, whose containers will not be injected
+	// into the newly created Pods, but will be injected into the matched running Pods as ephemeral containers
+	// on demand, when the Pods are annotated with kruise.io/sidecarset-ephemeral containing the SidecarSet name.
+	// The ephemeral containers can not be updated or removed once injected, and they can not add volumes into the Pods.
+	// default is false
+	// +optional
+	Ephemeral bool `json:"ephemeral,omitempty"`
+}
+
+// SidecarSetUninjectionStrategy indicates the uninjection strategy of SidecarSet.
+// Containers and volumes can not be removed from running pods, so the sidecar containers will be replaced
+// with the empty image in place, and the sidecar init containers and volumes will be left in pods.
+type SidecarSetUninjectionStrategy struct {
+	// EmptyImage is the image to replace the images of the sidecar containers in place,
+	// which should do nothing but keep running, such as the hotUpgradeEmptyImage.
+	EmptyImage string `json:"emptyImage"`
 }
 
 // SidecarSetUpdateStrategy indicates the strategy that the SidecarSet
@@ -162,6 +261,11 @@ type SidecarSetUpdateStrategy struct {
 	// If selector is not nil, this upgrade will only update the selected pods.
 	Selector *metav1.LabelSelector `json:"selector,omitempty"`
 
+	// PausedSelector is a label query over the injected pods, the sidecar containers in the selected pods
+	// will not be updated while the others continue updating. It works like Paused for a subset of pods.
+	// +optional
+	PausedSelector *metav1.LabelSelector `json:"pausedSelector,omitempty"`
+
 	// Partition is the desired number of pods in old revisions. It means when partition
 	// is set during pods updating, (replicas - partition) number of pods will be updated.
 	// Default value is 0.
@@ -180,6 +284,34 @@ type SidecarSetUpdateStrategy struct {
 	// - Note that pods will be scattered after priority sort. So, although priority strategy and scatter strategy can be applied together, we suggest to use either one of them.
 	// - If scatterStrategy is used, we suggest to just use one term. Otherwise, the update order can be hard to understand.
 	ScatterStrategy UpdateScatterStrategy `json:"scatterStrategy,omitempty"`
+
+	// TopologyScatterKeys are the node label keys, such as topology.kubernetes.io/zone and kubernetes.io/hostname,
+	// to scatter the pods to update across the topology domains of their nodes.
+	// Pods will be picked from the domains in turn, so that one batch will not update the pods in the same domain
+	// as far as possible.
+	// +optional
+	TopologyScatterKeys []string `json:"topologyScatterKeys,omitempty"`
+
+	// PodUnavailableBudgetGated indicates whether to consult the PodUnavailableBudget of each pod before updating it,
+	// and the pods whose updates are not allowed by PodUnavailableBudget will be skipped and retried later.
+	// default is false
+	// +optional
+	PodUnavailableBudgetGated bool `json:"podUnavailableBudgetGated,omitempty"`
+
+	// RollbackTo indicates the revision that the containers, initContainers, volumes and imagePullSecrets
+	// of SidecarSet will be rolled back to. The controller will restore these fields from the revision,
+	// and then clear rollbackTo, so the injected pods will be updated to the revision as a normal update.
+	// +optional
+	RollbackTo *SidecarSetRollbackConfig `json:"rollbackTo,omitempty"`
+}
+
+// SidecarSetRollbackConfig describes the revision that SidecarSet will be rolled back to.
+type SidecarSetRollbackConfig struct {
+	// Revision is the revision number of the controllerRevision to roll back to.
+	// If set to 0, SidecarSet will be rolled back to the last revision before the latest one.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Revision int64 `json:"revision,omitempty"`
 }
 
 type SidecarSetUpdateStrategyType string
@@ -214,6 +346,19 @@ type SidecarSetStatus struct {
 	// uses this field as a collision avoidance mechanism when it needs to create the name for the
 	// newest ControllerRevision.
 	CollisionCount *int32 `json:"collisionCount,omitempty"`
+
+	// RevisionPods is the number of matched pods in each revision of SidecarSet, sorted by revision name.
+	// The pods injected before the revisions are recorded are not counted.
+	// +optional
+	RevisionPods []SidecarSetRevisionPods `json:"revisionPods,omitempty"`
+}
+
+// SidecarSetRevisionPods is the number of matched pods in a revision of SidecarSet.
+type SidecarSetRevisionPods struct {
+	// Revision is the controllerRevision name of SidecarSet.
+	Revision string `json:"revision"`
+	// Pods is the number of matched pods in this revision.
+	Pods int32 `json:"pods"`
 }
 
 // +genclient
@@ -20,6 +20,7 @@ import (
 	"github.com/openkruise/kruise/apis/apps/v1beta1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -33,6 +34,12 @@ const (
 	// The update progress is able to be controlled by updating the partitions
 	// of each subset.
 	ManualUpdateStrategyType UpdateStrategyType = "Manual"
+
+	// OrderedUpdateStrategyType updates the subsets one by one in the order of steps.
+	// A subset starts to update only after the subsets before it have been updated to their partitions
+	// and all the updated pods are ready, and the rollout stops at the steps to pause.
+	// It is not supported by the subsets of Deployment, which have no partition.
+	OrderedUpdateStrategyType UpdateStrategyType = "Ordered"
 )
 
 // UnitedDeploymentConditionType indicates valid conditions type of a UnitedDeployment.
@@ -47,9 +54,33 @@ const (
 	SubsetFailure UnitedDeploymentConditionType = "SubsetFailure"
 )
 
+// UnitedDeploymentSubsetConditionType indicates valid conditions type of a subset of UnitedDeployment.
+type UnitedDeploymentSubsetConditionType string
+
+const (
+	// UnitedDeploymentSubsetSchedulable means the pods of the subset can be scheduled. When it is false,
+	// the pending replicas of the subset are moved to the other schedulable subsets.
+	UnitedDeploymentSubsetSchedulable UnitedDeploymentSubsetConditionType = "Schedulable"
+)
+
+// UnitedDeploymentScheduleStrategyType is a string enumeration type that enumerates
+// all possible schedule strategies for the UnitedDeployment controller.
+// +kubebuilder:validation:Enum=Adaptive;Fixed;""
+type UnitedDeploymentScheduleStrategyType string
+
+const (
+	// FixedUnitedDeploymentScheduleStrategyType keeps the replicas allocated to each subset,
+	// even if the pods of the subset can not be scheduled.
+	FixedUnitedDeploymentScheduleStrategyType UnitedDeploymentScheduleStrategyType = "Fixed"
+	// AdaptiveUnitedDeploymentScheduleStrategyType moves the replicas of the pods pending too long
+	// to the other subsets, and moves them back after the subset is considered schedulable again.
+	AdaptiveUnitedDeploymentScheduleStrategyType UnitedDeploymentScheduleStrategyType = "Adaptive"
+)
+
 // UnitedDeploymentSpec defines the desired state of UnitedDeployment.
 type UnitedDeploymentSpec struct {
 	// Replicas is the total desired replicas of all the subsets.
+	// The subsets configured in SubsetAutoscaling are excluded, whose replicas are decided by their own autoscalers.
 	// If unspecified, defaults to 1.
 	// +optional
 	Replicas *int32 `json:"replicas,omitempty"`
@@ -75,6 +106,47 @@ type UnitedDeploymentSpec struct {
 	// If unspecified, defaults to 10.
 	// +optional
 	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+
+	// SubsetAutoscaling configures a HorizontalPodAutoscaler for each of the listed subsets.
+	// The replicas of these subsets are scaled by their own autoscalers,
+	// instead of being allocated from the replicas of UnitedDeployment.
+	// +optional
+	SubsetAutoscaling []SubsetAutoscaling `json:"subsetAutoscaling,omitempty"`
+}
+
+// SubsetAutoscaling defines the scaling bounds and metrics of a subset.
+type SubsetAutoscaling struct {
+	// Name is the name of the subset in topology.
+	Name string `json:"name"`
+
+	// MinReplicas is the lower limit for the number of replicas of the subset.
+	// If unspecified, defaults to 1.
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas is the upper limit for the number of replicas of the subset.
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// Metrics contains the specifications used to calculate the desired replica count of the subset.
+	// If unspecified, defaults to 80% average CPU utilization.
+	// +optional
+	Metrics []SubsetAutoscalingMetric `json:"metrics,omitempty"`
+}
+
+// SubsetAutoscalingMetric defines the target of a resource metric of the pods in a subset.
+// Exactly one of TargetAverageUtilization and TargetAverageValue should be set.
+type SubsetAutoscalingMetric struct {
+	// Resource is the name of the resource in question, cpu or memory.
+	Resource corev1.ResourceName `json:"resource"`
+
+	// TargetAverageUtilization is the target value of the average of the resource metric
+	// across all pods, represented as a percentage of the requested value of the resource.
+	// +optional
+	TargetAverageUtilization *int32 `json:"targetAverageUtilization,omitempty"`
+
+	// TargetAverageValue is the target value of the average of the resource metric across all pods.
+	// +optional
+	TargetAverageValue *resource.Quantity `json:"targetAverageValue,omitempty"`
 }
 
 // SubsetTemplate defines the subset template under the UnitedDeployment.
@@ -95,6 +167,11 @@ type SubsetTemplate struct {
 	// Deployment template
 	// +optional
 	DeploymentTemplate *DeploymentTemplateSpec `json:"deploymentTemplate,omitempty"`
+
+	// AdvancedDaemonSet template. The replicas of its subsets are decided by the nodes
+	// selected by the subsets, instead of being allocated from the replicas of UnitedDeployment.
+	// +optional
+	AdvancedDaemonSetTemplate *AdvancedDaemonSetTemplateSpec `json:"advancedDaemonSetTemplate,omitempty"`
 }
 
 // StatefulSetTemplateSpec defines the subset template of StatefulSet.
@@ -133,6 +210,16 @@ type DeploymentTemplateSpec struct {
 	Spec appsv1.DeploymentSpec `json:"spec"`
 }
 
+// AdvancedDaemonSetTemplateSpec defines the subset template of AdvancedDaemonSet.
+type AdvancedDaemonSetTemplateSpec struct {
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	Spec DaemonSetSpec `json:"spec"`
+}
+
 // UnitedDeploymentUpdateStrategy defines the update performance
 // when template of UnitedDeployment is changed.
 type UnitedDeploymentUpdateStrategy struct {
@@ -143,6 +230,9 @@ type UnitedDeploymentUpdateStrategy struct {
 	// Includes all of the parameters a Manual update strategy needs.
 	// +optional
 	ManualUpdate *ManualUpdate `json:"manualUpdate,omitempty"`
+	// Includes all of the parameters an Ordered update strategy needs.
+	// +optional
+	OrderedUpdate *OrderedUpdate `json:"orderedUpdate,omitempty"`
 }
 
 // ManualUpdate is a update strategy which allows users to control the update progress
@@ -153,6 +243,28 @@ type ManualUpdate struct {
 	Partitions map[string]int32 `json:"partitions,omitempty"`
 }
 
+// OrderedUpdate is an update strategy which updates the subsets in the order of steps.
+type OrderedUpdate struct {
+	// Steps are the subsets to update in order. The subsets not listed are updated
+	// after all the steps in the order of topology, with partition 0.
+	// +optional
+	Steps []OrderedUpdateStep `json:"steps,omitempty"`
+}
+
+// OrderedUpdateStep defines how a subset is updated in an ordered update.
+type OrderedUpdateStep struct {
+	// Subset is the name of the subset to update.
+	Subset string `json:"subset"`
+	// Partition indicates the number of pods in the subset kept in the old revision.
+	// Defaults to 0, which means all the pods of the subset are updated.
+	// +optional
+	Partition *int32 `json:"partition,omitempty"`
+	// Pause indicates the rollout stops after this subset has been updated,
+	// until it is set to false.
+	// +optional
+	Pause bool `json:"pause,omitempty"`
+}
+
 // Topology defines the spread detail of each subset under UnitedDeployment.
 // A UnitedDeployment manages multiple homogeneous workloads which are called subset.
 // Each of subsets under the UnitedDeployment is described in Topology.
@@ -161,6 +273,39 @@ type Topology struct {
 	// which will be provisioned and managed by UnitedDeployment.
 	// +optional
 	Subsets []Subset `json:"subsets,omitempty"`
+
+	// ScheduleStrategy indicates the strategy the UnitedDeployment uses to deal with the pods
+	// that can not be scheduled in their subsets.
+	// +optional
+	ScheduleStrategy UnitedDeploymentScheduleStrategy `json:"scheduleStrategy,omitempty"`
+}
+
+// UnitedDeploymentScheduleStrategy defines how the replicas are moved between subsets
+// when the pods of a subset can not be scheduled.
+type UnitedDeploymentScheduleStrategy struct {
+	// Type indicates the type of the UnitedDeploymentScheduleStrategy.
+	// Default is Fixed.
+	// +optional
+	Type UnitedDeploymentScheduleStrategyType `json:"type,omitempty"`
+
+	// Adaptive is used to communicate parameters when Type is AdaptiveUnitedDeploymentScheduleStrategyType.
+	// +optional
+	Adaptive *AdaptiveUnitedDeploymentStrategy `json:"adaptive,omitempty"`
+}
+
+// AdaptiveUnitedDeploymentStrategy is used to communicate parameters when Type is AdaptiveUnitedDeploymentScheduleStrategyType.
+type AdaptiveUnitedDeploymentStrategy struct {
+	// RescheduleCriticalSeconds indicates how long the pods of a subset can stay unschedulable
+	// before the subset is marked unschedulable and their replicas are moved to the other subsets.
+	// Defaults to 30.
+	// +optional
+	RescheduleCriticalSeconds *int32 `json:"rescheduleCriticalSeconds,omitempty"`
+
+	// UnschedulableLastSeconds indicates how long a subset keeps unschedulable,
+	// after which the moved replicas are allowed to come back to it.
+	// Defaults to 300.
+	// +optional
+	UnschedulableLastSeconds *int32 `json:"unschedulableLastSeconds,omitempty"`
 }
 
 // Subset defines the detail of a subset.
@@ -187,6 +332,19 @@ type Subset struct {
 	// Controller will try to keep all the subsets with nil replicas have average pods.
 	// +optional
 	Replicas *intstr.IntOrString `json:"replicas,omitempty"`
+
+	// Indicates the lower bound of the number of the pod under this subset when its replicas is nil.
+	// Controller will allocate no less than MinReplicas pods to this subset, even if the others have
+	// fewer pods than the average.
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// Indicates the priority of this subset to receive the remainder, when the replicas can not be
+	// allocated averagely among the subsets with nil replicas. The subsets with higher priority
+	// receive one more pod first. The subsets with the same priority are ordered by their current
+	// replicas and names, the larger ones first.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
 }
 
 // UnitedDeploymentStatus defines the observed state of UnitedDeployment.
@@ -230,6 +388,38 @@ type UnitedDeploymentStatus struct {
 	// Records the information of update progress.
 	// +optional
 	UpdateStatus *UpdateStatus `json:"updateStatus,omitempty"`
+
+	// SubsetStatuses records the conditions of each subset when the Adaptive schedule strategy is used.
+	// +optional
+	SubsetStatuses []UnitedDeploymentSubsetStatus `json:"subsetStatuses,omitempty"`
+}
+
+// UnitedDeploymentSubsetStatus describes the observed state of a subset of UnitedDeployment.
+type UnitedDeploymentSubsetStatus struct {
+	// Name is the name of the subset.
+	Name string `json:"name"`
+
+	// Conditions is an array of current observed subset conditions.
+	// +optional
+	Conditions []UnitedDeploymentSubsetCondition `json:"conditions,omitempty"`
+}
+
+// UnitedDeploymentSubsetCondition describes current state of a subset of UnitedDeployment.
+type UnitedDeploymentSubsetCondition struct {
+	// Type of the subset condition.
+	Type UnitedDeploymentSubsetConditionType `json:"type"`
+
+	// Status of the condition, one of True, False, Unknown.
+	Status corev1.ConditionStatus `json:"status"`
+
+	// Last time the condition transitioned from one status to another.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// The reason for the condition's last transition.
+	Reason string `json:"reason,omitempty"`
+
+	// A human readable message indicating details about the transition.
+	Message string `json:"message,omitempty"`
 }
 
 // UnitedDeploymentCondition describes current state of a UnitedDeployment.
@@ -26,6 +26,8 @@ import (
 // WorkloadSpreadSpec defines the desired state of WorkloadSpread.
 type WorkloadSpreadSpec struct {
 	// TargetReference is the target workload that WorkloadSpread want to control.
+	// Besides CloneSet, Deployment, ReplicaSet and Job, it can also be a custom workload exposing the scale subresource,
+	// such as Argo Rollout, whose Pods are found by the ownerReference chain.
 	TargetReference *TargetReference `json:"targetRef"`
 
 	// Subsets describes the pods distribution details between each of subsets.
@@ -34,8 +36,29 @@ type WorkloadSpreadSpec struct {
 	// ScheduleStrategy indicates the strategy the WorkloadSpread used to preform the schedule between each of subsets.
 	// +optional
 	ScheduleStrategy WorkloadSpreadScheduleStrategy `json:"scheduleStrategy,omitempty"`
+
+	// DeletionCostPolicy indicates how the WorkloadSpread controller sets the pod-deletion-cost of Pods,
+	// which decides the order of Pods to delete when the workload scales in.
+	// Default is Subset
+	// +optional
+	DeletionCostPolicy WorkloadSpreadDeletionCostPolicyType `json:"deletionCostPolicy,omitempty"`
 }
 
+// WorkloadSpreadDeletionCostPolicyType is a string enumeration type that enumerates
+// all possible deletion-cost policies for the WorkloadSpread controller.
+// +kubebuilder:validation:Enum=Subset;Tiered;""
+type WorkloadSpreadDeletionCostPolicyType string
+
+const (
+	// SubsetWorkloadSpreadDeletionCostPolicyType represents that the Pods in the same subset have the same deletion-cost,
+	// except the ones beyond maxReplicas of the subset, which have a negative deletion-cost.
+	SubsetWorkloadSpreadDeletionCostPolicyType WorkloadSpreadDeletionCostPolicyType = "Subset"
+	// TieredWorkloadSpreadDeletionCostPolicyType represents that the Pods in the same subset are further ranked
+	// by their conditions and ages within the deletion-cost tier of the subset, so that the unhealthy and newer Pods
+	// in a lower-priority subset are deleted first.
+	TieredWorkloadSpreadDeletionCostPolicyType WorkloadSpreadDeletionCostPolicyType = "Tiered"
+)
+
 // TargetReference contains enough information to let you identify an workload
 type TargetReference struct {
 	// API version of the referent.
@@ -85,6 +108,48 @@ type AdaptiveWorkloadSpreadStrategy struct {
 	// over RescheduleCriticalSeconds duration, the controller will reschedule it to a suitable subset.
 	// +optional
 	RescheduleCriticalSeconds *int32 `json:"rescheduleCriticalSeconds,omitempty"`
+
+	// Rebalance indicates the controller to gradually delete the Pods in lower-priority subsets when the
+	// higher-priority subsets regain capacity, so that the recreated Pods are injected into the higher-priority
+	// subsets and the distribution converges back to the order of subsets.
+	// The deletion is still validated by PodUnavailableBudget if there is any, and it backs off exponentially
+	// after Pods of the workload fail to be scheduled, so that the Pods are not moved back and forth.
+	// +optional
+	Rebalance *WorkloadSpreadRebalanceStrategy `json:"rebalance,omitempty"`
+
+	// Failover indicates the controller to detect the health of subsets, and mark the unhealthy subset
+	// temporarily unschedulable, so that webhook will inject the new Pods into the next subsets.
+	// +optional
+	Failover *WorkloadSpreadFailoverStrategy `json:"failover,omitempty"`
+}
+
+// WorkloadSpreadFailoverStrategy defines how the controller detects the health of subsets.
+// The last subset is always schedulable.
+type WorkloadSpreadFailoverStrategy struct {
+	// MaxPendingPodPercent is the maximum percentage of unschedulable Pods in the active Pods of a subset.
+	// The subset is considered unhealthy if the percentage exceeds it.
+	// +optional
+	MaxPendingPodPercent *int32 `json:"maxPendingPodPercent,omitempty"`
+
+	// MaxNotReadyNodePercent is the maximum percentage of NotReady nodes in the nodes matching a subset.
+	// The subset is considered unhealthy if the percentage exceeds it.
+	// +optional
+	MaxNotReadyNodePercent *int32 `json:"maxNotReadyNodePercent,omitempty"`
+
+	// RecoverySeconds indicates how long the subset keeps unschedulable after it becomes healthy again.
+	// Defaults to 300.
+	// +optional
+	RecoverySeconds *int32 `json:"recoverySeconds,omitempty"`
+}
+
+// WorkloadSpreadRebalanceStrategy defines how the controller rebalances Pods between subsets.
+type WorkloadSpreadRebalanceStrategy struct {
+	// MaxUnavailable is the maximum number of Pods of the target workload that can be unavailable
+	// when the controller deletes Pods for rebalancing. Value can be an absolute number (ex: 5) or
+	// a percentage of the workload replicas (ex: 10%). Absolute number is calculated from percentage by rounding up.
+	// Defaults to 1.
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
 }
 
 // WorkloadSpreadSubset defines the details of a subset.
@@ -104,6 +169,12 @@ type WorkloadSpreadSubset struct {
 	// +optional
 	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
 
+	// PriorityClassName indicates the priorityClassName injected into the pods under this subset,
+	// which overrides the priorityClassName of the workload template.
+	// The PriorityClass must exist when the pods are created.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
 	// MaxReplicas indicates the desired max replicas of this subset.
 	// +optional
 	MaxReplicas *intstr.IntOrString `json:"maxReplicas,omitempty"`
@@ -139,6 +210,10 @@ const (
 	// even if only single Pod scheduled fails.
 	// After a period of time(e.g. 5m), the controller will recover the subset to be schedulable.
 	SubsetSchedulable WorkloadSpreadSubsetConditionType = "Schedulable"
+	// SubsetSaturationReached means the active and creating Pods of this subset have reached its maxReplicas,
+	// so that the newly created Pods will not be injected into this subset.
+	// It is only set for the subsets with maxReplicas.
+	SubsetSaturationReached WorkloadSpreadSubsetConditionType = "SaturationReached"
 )
 
 type WorkloadSpreadSubsetCondition struct {
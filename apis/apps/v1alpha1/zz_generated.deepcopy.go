@@ -31,6 +31,31 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdaptiveUnitedDeploymentStrategy) DeepCopyInto(out *AdaptiveUnitedDeploymentStrategy) {
+	*out = *in
+	if in.RescheduleCriticalSeconds != nil {
+		in, out := &in.RescheduleCriticalSeconds, &out.RescheduleCriticalSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.UnschedulableLastSeconds != nil {
+		in, out := &in.UnschedulableLastSeconds, &out.UnschedulableLastSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdaptiveUnitedDeploymentStrategy.
+func (in *AdaptiveUnitedDeploymentStrategy) DeepCopy() *AdaptiveUnitedDeploymentStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(AdaptiveUnitedDeploymentStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AdaptiveWorkloadSpreadStrategy) DeepCopyInto(out *AdaptiveWorkloadSpreadStrategy) {
 	*out = *in
@@ -39,6 +64,16 @@ func (in *AdaptiveWorkloadSpreadStrategy) DeepCopyInto(out *AdaptiveWorkloadSpre
 		*out = new(int32)
 		**out = **in
 	}
+	if in.Rebalance != nil {
+		in, out := &in.Rebalance, &out.Rebalance
+		*out = new(WorkloadSpreadRebalanceStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Failover != nil {
+		in, out := &in.Failover, &out.Failover
+		*out = new(WorkloadSpreadFailoverStrategy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdaptiveWorkloadSpreadStrategy.
@@ -113,6 +148,11 @@ func (in *AdvancedCronJobList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AdvancedCronJobSpec) DeepCopyInto(out *AdvancedCronJobSpec) {
 	*out = *in
+	if in.TimeZone != nil {
+		in, out := &in.TimeZone, &out.TimeZone
+		*out = new(string)
+		**out = **in
+	}
 	if in.StartingDeadlineSeconds != nil {
 		in, out := &in.StartingDeadlineSeconds, &out.StartingDeadlineSeconds
 		*out = new(int64)
@@ -170,6 +210,23 @@ func (in *AdvancedCronJobStatus) DeepCopy() *AdvancedCronJobStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdvancedDaemonSetTemplateSpec) DeepCopyInto(out *AdvancedDaemonSetTemplateSpec) {
+	*out = *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdvancedDaemonSetTemplateSpec.
+func (in *AdvancedDaemonSetTemplateSpec) DeepCopy() *AdvancedDaemonSetTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AdvancedDaemonSetTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AdvancedStatefulSetTemplateSpec) DeepCopyInto(out *AdvancedStatefulSetTemplateSpec) {
 	*out = *in
@@ -343,9 +400,26 @@ func (in *CloneSet) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloneSetCanaryStatus) DeepCopyInto(out *CloneSetCanaryStatus) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloneSetCanaryStatus.
+func (in *CloneSetCanaryStatus) DeepCopy() *CloneSetCanaryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CloneSetCanaryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CloneSetCondition) DeepCopyInto(out *CloneSetCondition) {
 	*out = *in
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
 	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
 }
 
@@ -391,6 +465,26 @@ func (in *CloneSetList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloneSetRollback) DeepCopyInto(out *CloneSetRollback) {
+	*out = *in
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloneSetRollback.
+func (in *CloneSetRollback) DeepCopy() *CloneSetRollback {
+	if in == nil {
+		return nil
+	}
+	out := new(CloneSetRollback)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CloneSetScaleStrategy) DeepCopyInto(out *CloneSetScaleStrategy) {
 	*out = *in
@@ -399,11 +493,21 @@ func (in *CloneSetScaleStrategy) DeepCopyInto(out *CloneSetScaleStrategy) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.PodsToDeleteSelector != nil {
+		in, out := &in.PodsToDeleteSelector, &out.PodsToDeleteSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.MaxUnavailable != nil {
 		in, out := &in.MaxUnavailable, &out.MaxUnavailable
 		*out = new(intstr.IntOrString)
 		**out = **in
 	}
+	if in.RankingPolicy != nil {
+		in, out := &in.RankingPolicy, &out.RankingPolicy
+		*out = new(pub.ScaleDownRankingPolicy)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloneSetScaleStrategy.
@@ -437,6 +541,7 @@ func (in *CloneSetSpec) DeepCopyInto(out *CloneSetSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	out.VolumeClaimUpdateStrategy = in.VolumeClaimUpdateStrategy
 	in.ScaleStrategy.DeepCopyInto(&out.ScaleStrategy)
 	in.UpdateStrategy.DeepCopyInto(&out.UpdateStrategy)
 	if in.RevisionHistoryLimit != nil {
@@ -449,6 +554,11 @@ func (in *CloneSetSpec) DeepCopyInto(out *CloneSetSpec) {
 		*out = new(pub.Lifecycle)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ProgressDeadlineSeconds != nil {
+		in, out := &in.ProgressDeadlineSeconds, &out.ProgressDeadlineSeconds
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloneSetSpec.
@@ -476,6 +586,23 @@ func (in *CloneSetStatus) DeepCopyInto(out *CloneSetStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.CanaryStatus != nil {
+		in, out := &in.CanaryStatus, &out.CanaryStatus
+		*out = new(CloneSetCanaryStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VolumeClaimTemplates != nil {
+		in, out := &in.VolumeClaimTemplates, &out.VolumeClaimTemplates
+		*out = make([]CloneSetVolumeClaimTemplateStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RevisionDiff != nil {
+		in, out := &in.RevisionDiff, &out.RevisionDiff
+		*out = new(pub.RevisionDiff)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloneSetStatus.
@@ -505,6 +632,43 @@ func (in *CloneSetTemplateSpec) DeepCopy() *CloneSetTemplateSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloneSetUpdateStep) DeepCopyInto(out *CloneSetUpdateStep) {
+	*out = *in
+	out.Replicas = in.Replicas
+	in.Pause.DeepCopyInto(&out.Pause)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloneSetUpdateStep.
+func (in *CloneSetUpdateStep) DeepCopy() *CloneSetUpdateStep {
+	if in == nil {
+		return nil
+	}
+	out := new(CloneSetUpdateStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloneSetUpdateStepPause) DeepCopyInto(out *CloneSetUpdateStepPause) {
+	*out = *in
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloneSetUpdateStepPause.
+func (in *CloneSetUpdateStepPause) DeepCopy() *CloneSetUpdateStepPause {
+	if in == nil {
+		return nil
+	}
+	out := new(CloneSetUpdateStepPause)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CloneSetUpdateStrategy) DeepCopyInto(out *CloneSetUpdateStrategy) {
 	*out = *in
@@ -538,6 +702,23 @@ func (in *CloneSetUpdateStrategy) DeepCopyInto(out *CloneSetUpdateStrategy) {
 		*out = new(pub.InPlaceUpdateStrategy)
 		**out = **in
 	}
+	if in.InPlaceMetadataKeys != nil {
+		in, out := &in.InPlaceMetadataKeys, &out.InPlaceMetadataKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]CloneSetUpdateStep, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RollbackTo != nil {
+		in, out := &in.RollbackTo, &out.RollbackTo
+		*out = new(CloneSetRollback)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloneSetUpdateStrategy.
@@ -550,6 +731,52 @@ func (in *CloneSetUpdateStrategy) DeepCopy() *CloneSetUpdateStrategy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloneSetVolumeClaimTemplateStatus) DeepCopyInto(out *CloneSetVolumeClaimTemplateStatus) {
+	*out = *in
+	out.Storage = in.Storage.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloneSetVolumeClaimTemplateStatus.
+func (in *CloneSetVolumeClaimTemplateStatus) DeepCopy() *CloneSetVolumeClaimTemplateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CloneSetVolumeClaimTemplateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloneSetVolumeClaimUpdateStrategy) DeepCopyInto(out *CloneSetVolumeClaimUpdateStrategy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloneSetVolumeClaimUpdateStrategy.
+func (in *CloneSetVolumeClaimUpdateStrategy) DeepCopy() *CloneSetVolumeClaimUpdateStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(CloneSetVolumeClaimUpdateStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CoLocatedPodUnavailableBudgetReference) DeepCopyInto(out *CoLocatedPodUnavailableBudgetReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoLocatedPodUnavailableBudgetReference.
+func (in *CoLocatedPodUnavailableBudgetReference) DeepCopy() *CoLocatedPodUnavailableBudgetReference {
+	if in == nil {
+		return nil
+	}
+	out := new(CoLocatedPodUnavailableBudgetReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CompletionPolicy) DeepCopyInto(out *CompletionPolicy) {
 	*out = *in
@@ -605,6 +832,21 @@ func (in *ContainerRecreateRequest) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ContainerRecreateRequestContainer) DeepCopyInto(out *ContainerRecreateRequestContainer) {
 	*out = *in
+	if in.MinStartedSeconds != nil {
+		in, out := &in.MinStartedSeconds, &out.MinStartedSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ReadinessTimeoutSeconds != nil {
+		in, out := &in.ReadinessTimeoutSeconds, &out.ReadinessTimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TerminationGracePeriodSeconds != nil {
+		in, out := &in.TerminationGracePeriodSeconds, &out.TerminationGracePeriodSeconds
+		*out = new(int64)
+		**out = **in
+	}
 	if in.PreStop != nil {
 		in, out := &in.PreStop, &out.PreStop
 		*out = new(ProbeHandler)
@@ -650,6 +892,10 @@ func (in *ContainerRecreateRequestContainerContext) DeepCopy() *ContainerRecreat
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ContainerRecreateRequestContainerRecreateState) DeepCopyInto(out *ContainerRecreateRequestContainerRecreateState) {
 	*out = *in
+	if in.LastFailureTime != nil {
+		in, out := &in.LastFailureTime, &out.LastFailureTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerRecreateRequestContainerRecreateState.
@@ -695,23 +941,76 @@ func (in *ContainerRecreateRequestList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ContainerRecreateRequestSpec) DeepCopyInto(out *ContainerRecreateRequestSpec) {
+func (in *ContainerRecreateRequestSet) DeepCopyInto(out *ContainerRecreateRequestSet) {
 	*out = *in
-	if in.Containers != nil {
-		in, out := &in.Containers, &out.Containers
-		*out = make([]ContainerRecreateRequestContainer, len(*in))
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerRecreateRequestSet.
+func (in *ContainerRecreateRequestSet) DeepCopy() *ContainerRecreateRequestSet {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerRecreateRequestSet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ContainerRecreateRequestSet) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerRecreateRequestSetList) DeepCopyInto(out *ContainerRecreateRequestSetList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ContainerRecreateRequestSet, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.Strategy != nil {
-		in, out := &in.Strategy, &out.Strategy
-		*out = new(ContainerRecreateRequestStrategy)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerRecreateRequestSetList.
+func (in *ContainerRecreateRequestSetList) DeepCopy() *ContainerRecreateRequestSetList {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerRecreateRequestSetList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ContainerRecreateRequestSetList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerRecreateRequestSetSpec) DeepCopyInto(out *ContainerRecreateRequestSetSpec) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.ActiveDeadlineSeconds != nil {
-		in, out := &in.ActiveDeadlineSeconds, &out.ActiveDeadlineSeconds
-		*out = new(int64)
+	in.Template.DeepCopyInto(&out.Template)
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
 		**out = **in
 	}
 	if in.TTLSecondsAfterFinished != nil {
@@ -721,52 +1020,124 @@ func (in *ContainerRecreateRequestSpec) DeepCopyInto(out *ContainerRecreateReque
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerRecreateRequestSpec.
-func (in *ContainerRecreateRequestSpec) DeepCopy() *ContainerRecreateRequestSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerRecreateRequestSetSpec.
+func (in *ContainerRecreateRequestSetSpec) DeepCopy() *ContainerRecreateRequestSetSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ContainerRecreateRequestSpec)
+	out := new(ContainerRecreateRequestSetSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ContainerRecreateRequestStatus) DeepCopyInto(out *ContainerRecreateRequestStatus) {
+func (in *ContainerRecreateRequestSetStatus) DeepCopyInto(out *ContainerRecreateRequestSetStatus) {
 	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
 	if in.CompletionTime != nil {
 		in, out := &in.CompletionTime, &out.CompletionTime
 		*out = (*in).DeepCopy()
 	}
-	if in.ContainerRecreateStates != nil {
-		in, out := &in.ContainerRecreateStates, &out.ContainerRecreateStates
-		*out = make([]ContainerRecreateRequestContainerRecreateState, len(*in))
+	if in.FailedPods != nil {
+		in, out := &in.FailedPods, &out.FailedPods
+		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerRecreateRequestStatus.
-func (in *ContainerRecreateRequestStatus) DeepCopy() *ContainerRecreateRequestStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerRecreateRequestSetStatus.
+func (in *ContainerRecreateRequestSetStatus) DeepCopy() *ContainerRecreateRequestSetStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ContainerRecreateRequestStatus)
+	out := new(ContainerRecreateRequestSetStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ContainerRecreateRequestStrategy) DeepCopyInto(out *ContainerRecreateRequestStrategy) {
+func (in *ContainerRecreateRequestSpec) DeepCopyInto(out *ContainerRecreateRequestSpec) {
 	*out = *in
-	if in.TerminationGracePeriodSeconds != nil {
-		in, out := &in.TerminationGracePeriodSeconds, &out.TerminationGracePeriodSeconds
-		*out = new(int64)
-		**out = **in
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = make([]ContainerRecreateRequestContainer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	if in.UnreadyGracePeriodSeconds != nil {
-		in, out := &in.UnreadyGracePeriodSeconds, &out.UnreadyGracePeriodSeconds
-		*out = new(int64)
-		**out = **in
+	if in.Strategy != nil {
+		in, out := &in.Strategy, &out.Strategy
+		*out = new(ContainerRecreateRequestStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ActiveDeadlineSeconds != nil {
+		in, out := &in.ActiveDeadlineSeconds, &out.ActiveDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.TTLSecondsAfterFinished != nil {
+		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerRecreateRequestSpec.
+func (in *ContainerRecreateRequestSpec) DeepCopy() *ContainerRecreateRequestSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerRecreateRequestSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerRecreateRequestStatus) DeepCopyInto(out *ContainerRecreateRequestStatus) {
+	*out = *in
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ContainerRecreateStates != nil {
+		in, out := &in.ContainerRecreateStates, &out.ContainerRecreateStates
+		*out = make([]ContainerRecreateRequestContainerRecreateState, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerRecreateRequestStatus.
+func (in *ContainerRecreateRequestStatus) DeepCopy() *ContainerRecreateRequestStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerRecreateRequestStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerRecreateRequestStrategy) DeepCopyInto(out *ContainerRecreateRequestStrategy) {
+	*out = *in
+	if in.TerminationGracePeriodSeconds != nil {
+		in, out := &in.TerminationGracePeriodSeconds, &out.TerminationGracePeriodSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.UnreadyGracePeriodSeconds != nil {
+		in, out := &in.UnreadyGracePeriodSeconds, &out.UnreadyGracePeriodSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.RetryBackoffSeconds != nil {
+		in, out := &in.RetryBackoffSeconds, &out.RetryBackoffSeconds
+		*out = new(int32)
+		**out = **in
 	}
 }
 
@@ -795,6 +1166,38 @@ func (in *ContainerRecreateRequestSyncContainerStatus) DeepCopy() *ContainerRecr
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerRecreateRequestTemplate) DeepCopyInto(out *ContainerRecreateRequestTemplate) {
+	*out = *in
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = make([]ContainerRecreateRequestContainer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Strategy != nil {
+		in, out := &in.Strategy, &out.Strategy
+		*out = new(ContainerRecreateRequestStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ActiveDeadlineSeconds != nil {
+		in, out := &in.ActiveDeadlineSeconds, &out.ActiveDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerRecreateRequestTemplate.
+func (in *ContainerRecreateRequestTemplate) DeepCopy() *ContainerRecreateRequestTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerRecreateRequestTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CronJobTemplate) DeepCopyInto(out *CronJobTemplate) {
 	*out = *in
@@ -808,6 +1211,16 @@ func (in *CronJobTemplate) DeepCopyInto(out *CronJobTemplate) {
 		*out = new(BroadcastJobTemplateSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ImagePullJobTemplate != nil {
+		in, out := &in.ImagePullJobTemplate, &out.ImagePullJobTemplate
+		*out = new(ImagePullJobTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EphemeralJobTemplate != nil {
+		in, out := &in.EphemeralJobTemplate, &out.EphemeralJobTemplate
+		*out = new(EphemeralJobTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CronJobTemplate.
@@ -879,6 +1292,46 @@ func (in *DaemonSetList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DaemonSetNodeApproval) DeepCopyInto(out *DaemonSetNodeApproval) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DaemonSetNodeApproval.
+func (in *DaemonSetNodeApproval) DeepCopy() *DaemonSetNodeApproval {
+	if in == nil {
+		return nil
+	}
+	out := new(DaemonSetNodeApproval)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DaemonSetNodePool) DeepCopyInto(out *DaemonSetNodePool) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DaemonSetNodePool.
+func (in *DaemonSetNodePool) DeepCopy() *DaemonSetNodePool {
+	if in == nil {
+		return nil
+	}
+	out := new(DaemonSetNodePool)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DaemonSetSpec) DeepCopyInto(out *DaemonSetSpec) {
 	*out = *in
@@ -904,6 +1357,11 @@ func (in *DaemonSetSpec) DeepCopyInto(out *DaemonSetSpec) {
 		*out = new(pub.Lifecycle)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.NodeApproval != nil {
+		in, out := &in.NodeApproval, &out.NodeApproval
+		*out = new(DaemonSetNodeApproval)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DaemonSetSpec.
@@ -953,57 +1411,422 @@ func (in *DaemonSetUpdateStrategy) DeepCopyInto(out *DaemonSetUpdateStrategy) {
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DaemonSetUpdateStrategy.
-func (in *DaemonSetUpdateStrategy) DeepCopy() *DaemonSetUpdateStrategy {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DaemonSetUpdateStrategy.
+func (in *DaemonSetUpdateStrategy) DeepCopy() *DaemonSetUpdateStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(DaemonSetUpdateStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentTemplateSpec) DeepCopyInto(out *DeploymentTemplateSpec) {
+	*out = *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentTemplateSpec.
+func (in *DeploymentTemplateSpec) DeepCopy() *DeploymentTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EphemeralContainerTemplateSpec) DeepCopyInto(out *EphemeralContainerTemplateSpec) {
+	*out = *in
+	if in.EphemeralContainers != nil {
+		in, out := &in.EphemeralContainers, &out.EphemeralContainers
+		*out = make([]v1.EphemeralContainer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EphemeralContainerTemplateSpec.
+func (in *EphemeralContainerTemplateSpec) DeepCopy() *EphemeralContainerTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EphemeralContainerTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EphemeralJob) DeepCopyInto(out *EphemeralJob) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EphemeralJob.
+func (in *EphemeralJob) DeepCopy() *EphemeralJob {
+	if in == nil {
+		return nil
+	}
+	out := new(EphemeralJob)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EphemeralJob) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EphemeralJobCondition) DeepCopyInto(out *EphemeralJobCondition) {
+	*out = *in
+	in.LastProbeTime.DeepCopyInto(&out.LastProbeTime)
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EphemeralJobCondition.
+func (in *EphemeralJobCondition) DeepCopy() *EphemeralJobCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(EphemeralJobCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EphemeralJobList) DeepCopyInto(out *EphemeralJobList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]EphemeralJob, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EphemeralJobList.
+func (in *EphemeralJobList) DeepCopy() *EphemeralJobList {
+	if in == nil {
+		return nil
+	}
+	out := new(EphemeralJobList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EphemeralJobList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EphemeralJobSpec) DeepCopyInto(out *EphemeralJobSpec) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Parallelism != nil {
+		in, out := &in.Parallelism, &out.Parallelism
+		*out = new(int32)
+		**out = **in
+	}
+	in.Template.DeepCopyInto(&out.Template)
+	if in.ActiveDeadlineSeconds != nil {
+		in, out := &in.ActiveDeadlineSeconds, &out.ActiveDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.TTLSecondsAfterFinished != nil {
+		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EphemeralJobSpec.
+func (in *EphemeralJobSpec) DeepCopy() *EphemeralJobSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EphemeralJobSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EphemeralJobStatus) DeepCopyInto(out *EphemeralJobStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]EphemeralJobCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EphemeralJobStatus.
+func (in *EphemeralJobStatus) DeepCopy() *EphemeralJobStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EphemeralJobStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EphemeralJobTemplateSpec) DeepCopyInto(out *EphemeralJobTemplateSpec) {
+	*out = *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EphemeralJobTemplateSpec.
+func (in *EphemeralJobTemplateSpec) DeepCopy() *EphemeralJobTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EphemeralJobTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FailurePolicy) DeepCopyInto(out *FailurePolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailurePolicy.
+func (in *FailurePolicy) DeepCopy() *FailurePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(FailurePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageDeleteJob) DeepCopyInto(out *ImageDeleteJob) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageDeleteJob.
+func (in *ImageDeleteJob) DeepCopy() *ImageDeleteJob {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageDeleteJob)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ImageDeleteJob) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageDeleteJobList) DeepCopyInto(out *ImageDeleteJobList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ImageDeleteJob, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageDeleteJobList.
+func (in *ImageDeleteJobList) DeepCopy() *ImageDeleteJobList {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageDeleteJobList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ImageDeleteJobList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageDeleteJobNodeStatus) DeepCopyInto(out *ImageDeleteJobNodeStatus) {
+	*out = *in
+	if in.DeletedImages != nil {
+		in, out := &in.DeletedImages, &out.DeletedImages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageDeleteJobNodeStatus.
+func (in *ImageDeleteJobNodeStatus) DeepCopy() *ImageDeleteJobNodeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageDeleteJobNodeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageDeleteJobSpec) DeepCopyInto(out *ImageDeleteJobSpec) {
+	*out = *in
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.UnusedDays != nil {
+		in, out := &in.UnusedDays, &out.UnusedDays
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(ImagePullJobNodeSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Parallelism != nil {
+		in, out := &in.Parallelism, &out.Parallelism
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.ActiveDeadlineSeconds != nil {
+		in, out := &in.ActiveDeadlineSeconds, &out.ActiveDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.TTLSecondsAfterFinished != nil {
+		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageDeleteJobSpec.
+func (in *ImageDeleteJobSpec) DeepCopy() *ImageDeleteJobSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(DaemonSetUpdateStrategy)
+	out := new(ImageDeleteJobSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DeploymentTemplateSpec) DeepCopyInto(out *DeploymentTemplateSpec) {
+func (in *ImageDeleteJobStatus) DeepCopyInto(out *ImageDeleteJobStatus) {
 	*out = *in
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NodeStatuses != nil {
+		in, out := &in.NodeStatuses, &out.NodeStatuses
+		*out = make([]ImageDeleteJobNodeStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentTemplateSpec.
-func (in *DeploymentTemplateSpec) DeepCopy() *DeploymentTemplateSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageDeleteJobStatus.
+func (in *ImageDeleteJobStatus) DeepCopy() *ImageDeleteJobStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(DeploymentTemplateSpec)
+	out := new(ImageDeleteJobStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EphemeralContainerTemplateSpec) DeepCopyInto(out *EphemeralContainerTemplateSpec) {
+func (in *ImageDeleteStatus) DeepCopyInto(out *ImageDeleteStatus) {
 	*out = *in
-	if in.EphemeralContainers != nil {
-		in, out := &in.EphemeralContainers, &out.EphemeralContainers
-		*out = make([]v1.EphemeralContainer, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.DeletedImages != nil {
+		in, out := &in.DeletedImages, &out.DeletedImages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EphemeralContainerTemplateSpec.
-func (in *EphemeralContainerTemplateSpec) DeepCopy() *EphemeralContainerTemplateSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageDeleteStatus.
+func (in *ImageDeleteStatus) DeepCopy() *ImageDeleteStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(EphemeralContainerTemplateSpec)
+	out := new(ImageDeleteStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EphemeralJob) DeepCopyInto(out *EphemeralJob) {
+func (in *ImageListPullJob) DeepCopyInto(out *ImageListPullJob) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -1011,18 +1834,18 @@ func (in *EphemeralJob) DeepCopyInto(out *EphemeralJob) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EphemeralJob.
-func (in *EphemeralJob) DeepCopy() *EphemeralJob {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageListPullJob.
+func (in *ImageListPullJob) DeepCopy() *ImageListPullJob {
 	if in == nil {
 		return nil
 	}
-	out := new(EphemeralJob)
+	out := new(ImageListPullJob)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *EphemeralJob) DeepCopyObject() runtime.Object {
+func (in *ImageListPullJob) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1030,48 +1853,50 @@ func (in *EphemeralJob) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EphemeralJobCondition) DeepCopyInto(out *EphemeralJobCondition) {
+func (in *ImageListPullJobImageStatus) DeepCopyInto(out *ImageListPullJobImageStatus) {
 	*out = *in
-	in.LastProbeTime.DeepCopyInto(&out.LastProbeTime)
-	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EphemeralJobCondition.
-func (in *EphemeralJobCondition) DeepCopy() *EphemeralJobCondition {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageListPullJobImageStatus.
+func (in *ImageListPullJobImageStatus) DeepCopy() *ImageListPullJobImageStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(EphemeralJobCondition)
+	out := new(ImageListPullJobImageStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EphemeralJobList) DeepCopyInto(out *EphemeralJobList) {
+func (in *ImageListPullJobList) DeepCopyInto(out *ImageListPullJobList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]EphemeralJob, len(*in))
+		*out = make([]ImageListPullJob, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EphemeralJobList.
-func (in *EphemeralJobList) DeepCopy() *EphemeralJobList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageListPullJobList.
+func (in *ImageListPullJobList) DeepCopy() *ImageListPullJobList {
 	if in == nil {
 		return nil
 	}
-	out := new(EphemeralJobList)
+	out := new(ImageListPullJobList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *EphemeralJobList) DeepCopyObject() runtime.Object {
+func (in *ImageListPullJobList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1079,56 +1904,29 @@ func (in *EphemeralJobList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EphemeralJobSpec) DeepCopyInto(out *EphemeralJobSpec) {
+func (in *ImageListPullJobSpec) DeepCopyInto(out *ImageListPullJobSpec) {
 	*out = *in
-	if in.Selector != nil {
-		in, out := &in.Selector, &out.Selector
-		*out = new(metav1.LabelSelector)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Replicas != nil {
-		in, out := &in.Replicas, &out.Replicas
-		*out = new(int32)
-		**out = **in
-	}
-	if in.Parallelism != nil {
-		in, out := &in.Parallelism, &out.Parallelism
-		*out = new(int32)
-		**out = **in
-	}
-	in.Template.DeepCopyInto(&out.Template)
-	if in.ActiveDeadlineSeconds != nil {
-		in, out := &in.ActiveDeadlineSeconds, &out.ActiveDeadlineSeconds
-		*out = new(int64)
-		**out = **in
-	}
-	if in.TTLSecondsAfterFinished != nil {
-		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
-		*out = new(int32)
-		**out = **in
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
+	in.ImagePullJobTemplate.DeepCopyInto(&out.ImagePullJobTemplate)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EphemeralJobSpec.
-func (in *EphemeralJobSpec) DeepCopy() *EphemeralJobSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageListPullJobSpec.
+func (in *ImageListPullJobSpec) DeepCopy() *ImageListPullJobSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(EphemeralJobSpec)
+	out := new(ImageListPullJobSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EphemeralJobStatus) DeepCopyInto(out *EphemeralJobStatus) {
+func (in *ImageListPullJobStatus) DeepCopyInto(out *ImageListPullJobStatus) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]EphemeralJobCondition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 	if in.StartTime != nil {
 		in, out := &in.StartTime, &out.StartTime
 		*out = (*in).DeepCopy()
@@ -1137,29 +1935,36 @@ func (in *EphemeralJobStatus) DeepCopyInto(out *EphemeralJobStatus) {
 		in, out := &in.CompletionTime, &out.CompletionTime
 		*out = (*in).DeepCopy()
 	}
+	if in.ImageStatuses != nil {
+		in, out := &in.ImageStatuses, &out.ImageStatuses
+		*out = make([]ImageListPullJobImageStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EphemeralJobStatus.
-func (in *EphemeralJobStatus) DeepCopy() *EphemeralJobStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageListPullJobStatus.
+func (in *ImageListPullJobStatus) DeepCopy() *ImageListPullJobStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(EphemeralJobStatus)
+	out := new(ImageListPullJobStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FailurePolicy) DeepCopyInto(out *FailurePolicy) {
+func (in *ImagePullDistributor) DeepCopyInto(out *ImagePullDistributor) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailurePolicy.
-func (in *FailurePolicy) DeepCopy() *FailurePolicy {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImagePullDistributor.
+func (in *ImagePullDistributor) DeepCopy() *ImagePullDistributor {
 	if in == nil {
 		return nil
 	}
-	out := new(FailurePolicy)
+	out := new(ImagePullDistributor)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -1262,6 +2067,50 @@ func (in *ImagePullJobPodSelector) DeepCopy() *ImagePullJobPodSelector {
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ImagePullJobSpec) DeepCopyInto(out *ImagePullJobSpec) {
+	*out = *in
+	in.ImagePullJobTemplate.DeepCopyInto(&out.ImagePullJobTemplate)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImagePullJobSpec.
+func (in *ImagePullJobSpec) DeepCopy() *ImagePullJobSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ImagePullJobSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImagePullJobStatus) DeepCopyInto(out *ImagePullJobStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.FailedNodes != nil {
+		in, out := &in.FailedNodes, &out.FailedNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImagePullJobStatus.
+func (in *ImagePullJobStatus) DeepCopy() *ImagePullJobStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ImagePullJobStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImagePullJobTemplate) DeepCopyInto(out *ImagePullJobTemplate) {
 	*out = *in
 	if in.PullSecrets != nil {
 		in, out := &in.PullSecrets, &out.PullSecrets
@@ -1288,43 +2137,56 @@ func (in *ImagePullJobSpec) DeepCopyInto(out *ImagePullJobSpec) {
 		*out = new(PullPolicy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Verification != nil {
+		in, out := &in.Verification, &out.Verification
+		*out = new(ImageVerification)
+		(*in).DeepCopyInto(*out)
+	}
 	in.CompletionPolicy.DeepCopyInto(&out.CompletionPolicy)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImagePullJobSpec.
-func (in *ImagePullJobSpec) DeepCopy() *ImagePullJobSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImagePullJobTemplate.
+func (in *ImagePullJobTemplate) DeepCopy() *ImagePullJobTemplate {
 	if in == nil {
 		return nil
 	}
-	out := new(ImagePullJobSpec)
+	out := new(ImagePullJobTemplate)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ImagePullJobStatus) DeepCopyInto(out *ImagePullJobStatus) {
+func (in *ImagePullJobTemplateSpec) DeepCopyInto(out *ImagePullJobTemplateSpec) {
 	*out = *in
-	if in.StartTime != nil {
-		in, out := &in.StartTime, &out.StartTime
-		*out = (*in).DeepCopy()
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImagePullJobTemplateSpec.
+func (in *ImagePullJobTemplateSpec) DeepCopy() *ImagePullJobTemplateSpec {
+	if in == nil {
+		return nil
 	}
-	if in.CompletionTime != nil {
-		in, out := &in.CompletionTime, &out.CompletionTime
+	out := new(ImagePullJobTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImagePullProgressDetail) DeepCopyInto(out *ImagePullProgressDetail) {
+	*out = *in
+	if in.LastProgressTime != nil {
+		in, out := &in.LastProgressTime, &out.LastProgressTime
 		*out = (*in).DeepCopy()
 	}
-	if in.FailedNodes != nil {
-		in, out := &in.FailedNodes, &out.FailedNodes
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImagePullJobStatus.
-func (in *ImagePullJobStatus) DeepCopy() *ImagePullJobStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImagePullProgressDetail.
+func (in *ImagePullProgressDetail) DeepCopy() *ImagePullProgressDetail {
 	if in == nil {
 		return nil
 	}
-	out := new(ImagePullJobStatus)
+	out := new(ImagePullProgressDetail)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -1401,6 +2263,11 @@ func (in *ImageTagPullPolicy) DeepCopyInto(out *ImageTagPullPolicy) {
 		*out = new(int64)
 		**out = **in
 	}
+	if in.Distributor != nil {
+		in, out := &in.Distributor, &out.Distributor
+		*out = new(ImagePullDistributor)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageTagPullPolicy.
@@ -1445,6 +2312,11 @@ func (in *ImageTagSpec) DeepCopy() *ImageTagSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ImageTagStatus) DeepCopyInto(out *ImageTagStatus) {
 	*out = *in
+	if in.ProgressDetail != nil {
+		in, out := &in.ProgressDetail, &out.ProgressDetail
+		*out = new(ImagePullProgressDetail)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.StartTime != nil {
 		in, out := &in.StartTime, &out.StartTime
 		*out = (*in).DeepCopy()
@@ -1465,6 +2337,41 @@ func (in *ImageTagStatus) DeepCopy() *ImageTagStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageVerification) DeepCopyInto(out *ImageVerification) {
+	*out = *in
+	if in.Keyless != nil {
+		in, out := &in.Keyless, &out.Keyless
+		*out = new(ImageVerificationKeyless)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageVerification.
+func (in *ImageVerification) DeepCopy() *ImageVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageVerificationKeyless) DeepCopyInto(out *ImageVerificationKeyless) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageVerificationKeyless.
+func (in *ImageVerificationKeyless) DeepCopy() *ImageVerificationKeyless {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageVerificationKeyless)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *JobCondition) DeepCopyInto(out *JobCondition) {
 	*out = *in
@@ -1575,39 +2482,88 @@ func (in *NodeImageSpec) DeepCopyInto(out *NodeImageSpec) {
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeImageSpec.
-func (in *NodeImageSpec) DeepCopy() *NodeImageSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeImageSpec.
+func (in *NodeImageSpec) DeepCopy() *NodeImageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeImageSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeImageStatus) DeepCopyInto(out *NodeImageStatus) {
+	*out = *in
+	if in.ImageStatuses != nil {
+		in, out := &in.ImageStatuses, &out.ImageStatuses
+		*out = make(map[string]ImageStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.FirstSyncStatus != nil {
+		in, out := &in.FirstSyncStatus, &out.FirstSyncStatus
+		*out = new(SyncStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImageDeleteStatuses != nil {
+		in, out := &in.ImageDeleteStatuses, &out.ImageDeleteStatuses
+		*out = make(map[string]ImageDeleteStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeImageStatus.
+func (in *NodeImageStatus) DeepCopy() *NodeImageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeImageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OrderedUpdate) DeepCopyInto(out *OrderedUpdate) {
+	*out = *in
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]OrderedUpdateStep, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrderedUpdate.
+func (in *OrderedUpdate) DeepCopy() *OrderedUpdate {
 	if in == nil {
 		return nil
 	}
-	out := new(NodeImageSpec)
+	out := new(OrderedUpdate)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NodeImageStatus) DeepCopyInto(out *NodeImageStatus) {
+func (in *OrderedUpdateStep) DeepCopyInto(out *OrderedUpdateStep) {
 	*out = *in
-	if in.ImageStatuses != nil {
-		in, out := &in.ImageStatuses, &out.ImageStatuses
-		*out = make(map[string]ImageStatus, len(*in))
-		for key, val := range *in {
-			(*out)[key] = *val.DeepCopy()
-		}
-	}
-	if in.FirstSyncStatus != nil {
-		in, out := &in.FirstSyncStatus, &out.FirstSyncStatus
-		*out = new(SyncStatus)
-		(*in).DeepCopyInto(*out)
+	if in.Partition != nil {
+		in, out := &in.Partition, &out.Partition
+		*out = new(int32)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeImageStatus.
-func (in *NodeImageStatus) DeepCopy() *NodeImageStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrderedUpdateStep.
+func (in *OrderedUpdateStep) DeepCopy() *OrderedUpdateStep {
 	if in == nil {
 		return nil
 	}
-	out := new(NodeImageStatus)
+	out := new(OrderedUpdateStep)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -1655,6 +2611,11 @@ func (in *PullPolicy) DeepCopyInto(out *PullPolicy) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.Distributor != nil {
+		in, out := &in.Distributor, &out.Distributor
+		*out = new(ImagePullDistributor)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullPolicy.
@@ -1877,11 +2838,33 @@ func (in *RollingUpdateDaemonSet) DeepCopyInto(out *RollingUpdateDaemonSet) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.PartitionPercent != nil {
+		in, out := &in.PartitionPercent, &out.PartitionPercent
+		*out = new(int32)
+		**out = **in
+	}
 	if in.Paused != nil {
 		in, out := &in.Paused, &out.Paused
 		*out = new(bool)
 		**out = **in
 	}
+	if in.NodePools != nil {
+		in, out := &in.NodePools, &out.NodePools
+		*out = make([]DaemonSetNodePool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ApprovedNodePools != nil {
+		in, out := &in.ApprovedNodePools, &out.ApprovedNodePools
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CoLocatedPodUnavailableBudget != nil {
+		in, out := &in.CoLocatedPodUnavailableBudget, &out.CoLocatedPodUnavailableBudget
+		*out = new(CoLocatedPodUnavailableBudgetReference)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RollingUpdateDaemonSet.
@@ -1934,9 +2917,51 @@ func (in *RollingUpdateStatefulSetStrategy) DeepCopy() *RollingUpdateStatefulSet
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ShareVolumeMountOverride) DeepCopyInto(out *ShareVolumeMountOverride) {
+	*out = *in
+	if in.SubPath != nil {
+		in, out := &in.SubPath, &out.SubPath
+		*out = new(string)
+		**out = **in
+	}
+	if in.ReadOnly != nil {
+		in, out := &in.ReadOnly, &out.ReadOnly
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ShareVolumeMountOverride.
+func (in *ShareVolumeMountOverride) DeepCopy() *ShareVolumeMountOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(ShareVolumeMountOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ShareVolumePolicy) DeepCopyInto(out *ShareVolumePolicy) {
 	*out = *in
+	if in.IncludeVolumes != nil {
+		in, out := &in.IncludeVolumes, &out.IncludeVolumes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludeVolumes != nil {
+		in, out := &in.ExcludeVolumes, &out.ExcludeVolumes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MountOverrides != nil {
+		in, out := &in.MountOverrides, &out.MountOverrides
+		*out = make([]ShareVolumeMountOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ShareVolumePolicy.
@@ -1953,8 +2978,13 @@ func (in *ShareVolumePolicy) DeepCopy() *ShareVolumePolicy {
 func (in *SidecarContainer) DeepCopyInto(out *SidecarContainer) {
 	*out = *in
 	in.Container.DeepCopyInto(&out.Container)
-	out.UpgradeStrategy = in.UpgradeStrategy
-	out.ShareVolumePolicy = in.ShareVolumePolicy
+	if in.LaunchPriority != nil {
+		in, out := &in.LaunchPriority, &out.LaunchPriority
+		*out = new(int32)
+		**out = **in
+	}
+	in.UpgradeStrategy.DeepCopyInto(&out.UpgradeStrategy)
+	in.ShareVolumePolicy.DeepCopyInto(&out.ShareVolumePolicy)
 	if in.TransferEnv != nil {
 		in, out := &in.TransferEnv, &out.TransferEnv
 		*out = make([]TransferEnvVar, len(*in))
@@ -1977,6 +3007,11 @@ func (in *SidecarContainer) DeepCopy() *SidecarContainer {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SidecarContainerUpgradeStrategy) DeepCopyInto(out *SidecarContainerUpgradeStrategy) {
 	*out = *in
+	if in.HotUpgradeMigrationHook != nil {
+		in, out := &in.HotUpgradeMigrationHook, &out.HotUpgradeMigrationHook
+		*out = new(ProbeHandler)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SidecarContainerUpgradeStrategy.
@@ -2019,6 +3054,11 @@ func (in *SidecarSet) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SidecarSetInjectionStrategy) DeepCopyInto(out *SidecarSetInjectionStrategy) {
 	*out = *in
+	if in.Percent != nil {
+		in, out := &in.Percent, &out.Percent
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SidecarSetInjectionStrategy.
@@ -2063,6 +3103,36 @@ func (in *SidecarSetList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SidecarSetRevisionPods) DeepCopyInto(out *SidecarSetRevisionPods) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SidecarSetRevisionPods.
+func (in *SidecarSetRevisionPods) DeepCopy() *SidecarSetRevisionPods {
+	if in == nil {
+		return nil
+	}
+	out := new(SidecarSetRevisionPods)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SidecarSetRollbackConfig) DeepCopyInto(out *SidecarSetRollbackConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SidecarSetRollbackConfig.
+func (in *SidecarSetRollbackConfig) DeepCopy() *SidecarSetRollbackConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SidecarSetRollbackConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SidecarSetSpec) DeepCopyInto(out *SidecarSetSpec) {
 	*out = *in
@@ -2071,6 +3141,11 @@ func (in *SidecarSetSpec) DeepCopyInto(out *SidecarSetSpec) {
 		*out = new(metav1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.InitContainers != nil {
 		in, out := &in.InitContainers, &out.InitContainers
 		*out = make([]SidecarContainer, len(*in))
@@ -2093,7 +3168,12 @@ func (in *SidecarSetSpec) DeepCopyInto(out *SidecarSetSpec) {
 		}
 	}
 	in.UpdateStrategy.DeepCopyInto(&out.UpdateStrategy)
-	out.InjectionStrategy = in.InjectionStrategy
+	in.InjectionStrategy.DeepCopyInto(&out.InjectionStrategy)
+	if in.UninjectionStrategy != nil {
+		in, out := &in.UninjectionStrategy, &out.UninjectionStrategy
+		*out = new(SidecarSetUninjectionStrategy)
+		**out = **in
+	}
 	if in.ImagePullSecrets != nil {
 		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
 		*out = make([]v1.LocalObjectReference, len(*in))
@@ -2124,6 +3204,11 @@ func (in *SidecarSetStatus) DeepCopyInto(out *SidecarSetStatus) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.RevisionPods != nil {
+		in, out := &in.RevisionPods, &out.RevisionPods
+		*out = make([]SidecarSetRevisionPods, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SidecarSetStatus.
@@ -2136,6 +3221,21 @@ func (in *SidecarSetStatus) DeepCopy() *SidecarSetStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SidecarSetUninjectionStrategy) DeepCopyInto(out *SidecarSetUninjectionStrategy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SidecarSetUninjectionStrategy.
+func (in *SidecarSetUninjectionStrategy) DeepCopy() *SidecarSetUninjectionStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(SidecarSetUninjectionStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SidecarSetUpdateStrategy) DeepCopyInto(out *SidecarSetUpdateStrategy) {
 	*out = *in
@@ -2144,6 +3244,11 @@ func (in *SidecarSetUpdateStrategy) DeepCopyInto(out *SidecarSetUpdateStrategy)
 		*out = new(metav1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PausedSelector != nil {
+		in, out := &in.PausedSelector, &out.PausedSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Partition != nil {
 		in, out := &in.Partition, &out.Partition
 		*out = new(intstr.IntOrString)
@@ -2159,6 +3264,16 @@ func (in *SidecarSetUpdateStrategy) DeepCopyInto(out *SidecarSetUpdateStrategy)
 		*out = make(UpdateScatterStrategy, len(*in))
 		copy(*out, *in)
 	}
+	if in.TopologyScatterKeys != nil {
+		in, out := &in.TopologyScatterKeys, &out.TopologyScatterKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RollbackTo != nil {
+		in, out := &in.RollbackTo, &out.RollbackTo
+		*out = new(SidecarSetRollbackConfig)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SidecarSetUpdateStrategy.
@@ -2369,6 +3484,11 @@ func (in *Subset) DeepCopyInto(out *Subset) {
 		*out = new(intstr.IntOrString)
 		**out = **in
 	}
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Subset.
@@ -2381,6 +3501,58 @@ func (in *Subset) DeepCopy() *Subset {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubsetAutoscaling) DeepCopyInto(out *SubsetAutoscaling) {
+	*out = *in
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]SubsetAutoscalingMetric, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubsetAutoscaling.
+func (in *SubsetAutoscaling) DeepCopy() *SubsetAutoscaling {
+	if in == nil {
+		return nil
+	}
+	out := new(SubsetAutoscaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubsetAutoscalingMetric) DeepCopyInto(out *SubsetAutoscalingMetric) {
+	*out = *in
+	if in.TargetAverageUtilization != nil {
+		in, out := &in.TargetAverageUtilization, &out.TargetAverageUtilization
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetAverageValue != nil {
+		in, out := &in.TargetAverageValue, &out.TargetAverageValue
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubsetAutoscalingMetric.
+func (in *SubsetAutoscalingMetric) DeepCopy() *SubsetAutoscalingMetric {
+	if in == nil {
+		return nil
+	}
+	out := new(SubsetAutoscalingMetric)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SubsetTemplate) DeepCopyInto(out *SubsetTemplate) {
 	*out = *in
@@ -2404,6 +3576,11 @@ func (in *SubsetTemplate) DeepCopyInto(out *SubsetTemplate) {
 		*out = new(DeploymentTemplateSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.AdvancedDaemonSetTemplate != nil {
+		in, out := &in.AdvancedDaemonSetTemplate, &out.AdvancedDaemonSetTemplate
+		*out = new(AdvancedDaemonSetTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubsetTemplate.
@@ -2457,6 +3634,7 @@ func (in *Topology) DeepCopyInto(out *Topology) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	in.ScheduleStrategy.DeepCopyInto(&out.ScheduleStrategy)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Topology.
@@ -2569,6 +3747,26 @@ func (in *UnitedDeploymentList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UnitedDeploymentScheduleStrategy) DeepCopyInto(out *UnitedDeploymentScheduleStrategy) {
+	*out = *in
+	if in.Adaptive != nil {
+		in, out := &in.Adaptive, &out.Adaptive
+		*out = new(AdaptiveUnitedDeploymentStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnitedDeploymentScheduleStrategy.
+func (in *UnitedDeploymentScheduleStrategy) DeepCopy() *UnitedDeploymentScheduleStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(UnitedDeploymentScheduleStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UnitedDeploymentSpec) DeepCopyInto(out *UnitedDeploymentSpec) {
 	*out = *in
@@ -2590,6 +3788,13 @@ func (in *UnitedDeploymentSpec) DeepCopyInto(out *UnitedDeploymentSpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.SubsetAutoscaling != nil {
+		in, out := &in.SubsetAutoscaling, &out.SubsetAutoscaling
+		*out = make([]SubsetAutoscaling, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnitedDeploymentSpec.
@@ -2629,6 +3834,13 @@ func (in *UnitedDeploymentStatus) DeepCopyInto(out *UnitedDeploymentStatus) {
 		*out = new(UpdateStatus)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.SubsetStatuses != nil {
+		in, out := &in.SubsetStatuses, &out.SubsetStatuses
+		*out = make([]UnitedDeploymentSubsetStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnitedDeploymentStatus.
@@ -2641,6 +3853,44 @@ func (in *UnitedDeploymentStatus) DeepCopy() *UnitedDeploymentStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UnitedDeploymentSubsetCondition) DeepCopyInto(out *UnitedDeploymentSubsetCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnitedDeploymentSubsetCondition.
+func (in *UnitedDeploymentSubsetCondition) DeepCopy() *UnitedDeploymentSubsetCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(UnitedDeploymentSubsetCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UnitedDeploymentSubsetStatus) DeepCopyInto(out *UnitedDeploymentSubsetStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]UnitedDeploymentSubsetCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnitedDeploymentSubsetStatus.
+func (in *UnitedDeploymentSubsetStatus) DeepCopy() *UnitedDeploymentSubsetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(UnitedDeploymentSubsetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UnitedDeploymentUpdateStrategy) DeepCopyInto(out *UnitedDeploymentUpdateStrategy) {
 	*out = *in
@@ -2649,6 +3899,11 @@ func (in *UnitedDeploymentUpdateStrategy) DeepCopyInto(out *UnitedDeploymentUpda
 		*out = new(ManualUpdate)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.OrderedUpdate != nil {
+		in, out := &in.OrderedUpdate, &out.OrderedUpdate
+		*out = new(OrderedUpdate)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnitedDeploymentUpdateStrategy.
@@ -2764,6 +4019,36 @@ func (in *WorkloadSpread) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadSpreadFailoverStrategy) DeepCopyInto(out *WorkloadSpreadFailoverStrategy) {
+	*out = *in
+	if in.MaxPendingPodPercent != nil {
+		in, out := &in.MaxPendingPodPercent, &out.MaxPendingPodPercent
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxNotReadyNodePercent != nil {
+		in, out := &in.MaxNotReadyNodePercent, &out.MaxNotReadyNodePercent
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RecoverySeconds != nil {
+		in, out := &in.RecoverySeconds, &out.RecoverySeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadSpreadFailoverStrategy.
+func (in *WorkloadSpreadFailoverStrategy) DeepCopy() *WorkloadSpreadFailoverStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadSpreadFailoverStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkloadSpreadList) DeepCopyInto(out *WorkloadSpreadList) {
 	*out = *in
@@ -2796,6 +4081,26 @@ func (in *WorkloadSpreadList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadSpreadRebalanceStrategy) DeepCopyInto(out *WorkloadSpreadRebalanceStrategy) {
+	*out = *in
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadSpreadRebalanceStrategy.
+func (in *WorkloadSpreadRebalanceStrategy) DeepCopy() *WorkloadSpreadRebalanceStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadSpreadRebalanceStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkloadSpreadScheduleStrategy) DeepCopyInto(out *WorkloadSpreadScheduleStrategy) {
 	*out = *in
@@ -20,13 +20,19 @@ import (
 	appspub "github.com/openkruise/kruise/apis/apps/pub"
 	apps "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 const (
 	// MaxMinReadySeconds is the max value of MinReadySeconds
 	MaxMinReadySeconds = 300
+
+	// CordonOrdinalAnnotationKey is the annotation key on Pod. If its value is "true", the ordinal of the Pod
+	// will be appended into spec.reserveOrdinals of the StatefulSet, so the Pod will be deleted and never recreated.
+	CordonOrdinalAnnotationKey = "apps.kruise.io/cordon-ordinal"
 )
 
 // StatefulSetUpdateStrategy indicates the strategy that the StatefulSet
@@ -68,7 +74,9 @@ type RollingUpdateStatefulSetStrategy struct {
 	Paused bool `json:"paused,omitempty"`
 	// UnorderedUpdate contains strategies for non-ordered update.
 	// If it is not nil, pods will be updated with non-ordered sequence.
-	// Noted that UnorderedUpdate can only be allowed to work with Parallel podManagementPolicy
+	// Noted that UnorderedUpdate can only be allowed to work with Parallel podManagementPolicy.
+	// In unordered update, maxUnavailable limits the unavailable Pods among all replicas, and
+	// Pods will not be updated if it is rejected by PodUnavailableBudget.
 	// +optional
 	UnorderedUpdate *UnorderedUpdateStrategy `json:"unorderedUpdate,omitempty"`
 	// InPlaceUpdateStrategy contains strategies for in-place update.
@@ -214,8 +222,28 @@ type StatefulSetSpec struct {
 	//   Then controller will delete Pod-1 and create Pod-3 (existing Pods will be [0, 2, 3])
 	// - If you just want to delete Pod-1, you should set spec.reserveOrdinal to [1] and spec.replicas to 2.
 	//   Then controller will delete Pod-1 (existing Pods will be [0, 2])
+	// Ordinals of Pods annotated with apps.kruise.io/cordon-ordinal=true will be appended into it by controller.
 	ReserveOrdinals []int `json:"reserveOrdinals,omitempty"`
 
+	// reserveOrdinalRanges controls the ranges of ordinal numbers that should be reserved, the same as reserveOrdinals.
+	// Each item is a range with both ends included (ex: "100-199") or a single ordinal (ex: "5").
+	// +optional
+	ReserveOrdinalRanges []string `json:"reserveOrdinalRanges,omitempty"`
+
+	// ordinals controls the numbering of replica indices in a StatefulSet.
+	// The default ordinals behavior assigns a "0" index to the first replica and increments the index by one
+	// for each additional replica requested.
+	// +optional
+	Ordinals *StatefulSetOrdinals `json:"ordinals,omitempty"`
+
+	// ordinalOverrides patches the Pods with specific ordinals, so that some members of the StatefulSet
+	// can have a different spec, such as env, resources and nodeSelector.
+	// If multiple overrides match an ordinal, they will be applied in order.
+	// Changes of ordinalOverrides will be rolled out as a new revision, and Pods whose overrides
+	// have been changed will be recreated instead of in-place updated.
+	// +optional
+	OrdinalOverrides []StatefulSetOrdinalOverride `json:"ordinalOverrides,omitempty"`
+
 	// Lifecycle defines the lifecycle hooks for Pods pre-delete, in-place update.
 	Lifecycle *appspub.Lifecycle `json:"lifecycle,omitempty"`
 
@@ -226,8 +254,40 @@ type StatefulSetSpec struct {
 	// PersistentVolumeClaimRetentionPolicy describes the policy used for PVCs created from
 	// the StatefulSet VolumeClaimTemplates. This requires the
 	// StatefulSetAutoDeletePVC feature gate to be enabled, which is alpha.
+	// Pods with reserved ordinals are regarded as scaled down, so their PVCs will be deleted if whenScaled is Delete.
 	// +optional
 	PersistentVolumeClaimRetentionPolicy *StatefulSetPersistentVolumeClaimRetentionPolicy `json:"persistentVolumeClaimRetentionPolicy,omitempty"`
+
+	// VolumeClaimUpdateStrategy indicates how to update the existing PVCs when volumeClaimTemplates changed.
+	// +optional
+	VolumeClaimUpdateStrategy *StatefulSetVolumeClaimUpdateStrategy `json:"volumeClaimUpdateStrategy,omitempty"`
+}
+
+// StatefulSetOrdinals describes the policy used for replica ordinal assignment in this StatefulSet.
+type StatefulSetOrdinals struct {
+	// start is the number representing the first replica's index. It may be used to number replicas
+	// from an alternate index (eg: 1-indexed) over the default 0-indexed names, or to orchestrate
+	// progressive movement of replicas from one StatefulSet to another.
+	// If set, replica indices will be in the range:
+	//   [.spec.ordinals.start, .spec.ordinals.start + .spec.replicas) excluding reserveOrdinals.
+	// If unset, defaults to 0. Replica indices will be in the range:
+	//   [0, .spec.replicas) excluding reserveOrdinals.
+	// Partition of rolling update is counted from start, i.e., pods with ordinals less than start + partition
+	// are kept in the current revision.
+	// +optional
+	Start int32 `json:"start"`
+}
+
+// StatefulSetOrdinalOverride defines the patch for Pods with specific ordinals.
+type StatefulSetOrdinalOverride struct {
+	// ordinals are the ordinals of Pods to patch. Each item can be an integer ordinal (ex: 0)
+	// or a string range with both ends included (ex: "100-199").
+	Ordinals []intstr.IntOrString `json:"ordinals"`
+
+	// patch is the strategic merge patch to apply to the Pods created from the template.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	Patch runtime.RawExtension `json:"patch"`
 }
 
 // StatefulSetScaleStrategy defines strategies for pods scale.
@@ -237,6 +297,64 @@ type StatefulSetScaleStrategy struct {
 	// Absolute number is calculated from percentage by rounding down.
 	// It can just be allowed to work with Parallel podManagementPolicy.
 	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+	// PodOperationRateLimit limits the rate of Pod creations and deletions of the StatefulSet,
+	// which overrides the default rate limit configured by flags of kruise-manager.
+	// +optional
+	PodOperationRateLimit *StatefulSetPodOperationRateLimit `json:"podOperationRateLimit,omitempty"`
+}
+
+// StatefulSetPodOperationRateLimit defines the token bucket to limit Pod creations and deletions.
+type StatefulSetPodOperationRateLimit struct {
+	// QPS is the number of Pod creations and deletions allowed per second.
+	// 0 means no limit.
+	QPS int32 `json:"qps"`
+
+	// Burst is the max number of Pod creations and deletions allowed at once. Defaults to QPS.
+	// +optional
+	Burst int32 `json:"burst,omitempty"`
+}
+
+// StatefulSetVolumeClaimUpdateStrategy defines strategies for updating the existing PVCs.
+type StatefulSetVolumeClaimUpdateStrategy struct {
+	// Type indicates the type of the StatefulSetVolumeClaimUpdateStrategy.
+	// Default is OnDelete.
+	Type StatefulSetVolumeClaimUpdateStrategyType `json:"type,omitempty"`
+}
+
+// StatefulSetVolumeClaimUpdateStrategyType defines strategies for updating the existing PVCs.
+type StatefulSetVolumeClaimUpdateStrategyType string
+
+const (
+	// OnDeleteVolumeClaimUpdateStrategyType indicates that the existing PVCs will not be changed,
+	// and only the PVCs created for new Pods use the latest volumeClaimTemplates.
+	// Updates of volumeClaimTemplates are forbidden with this type.
+	OnDeleteVolumeClaimUpdateStrategyType StatefulSetVolumeClaimUpdateStrategyType = "OnDelete"
+	// ExpandVolumeClaimUpdateStrategyType indicates that increasing the storage requests in volumeClaimTemplates is allowed,
+	// and the existing PVCs will be expanded replica by replica in ordinal order if their StorageClasses allow volume expansion.
+	// The Pod will be restarted if its file system resize keeps pending, which means the volume only supports offline expansion.
+	ExpandVolumeClaimUpdateStrategyType StatefulSetVolumeClaimUpdateStrategyType = "Expand"
+)
+
+// StatefulSetVolumeClaimTemplateStatus is the expansion progress of the existing PVCs for a volumeClaimTemplate.
+type StatefulSetVolumeClaimTemplateStatus struct {
+	// Name is the name of the volumeClaimTemplate.
+	Name string `json:"name"`
+
+	// Storage is the storage requests in the volumeClaimTemplate.
+	Storage resource.Quantity `json:"storage"`
+
+	// ExpandedReplicas is the number of bound PVCs whose capacity has satisfied the storage requests.
+	ExpandedReplicas int32 `json:"expandedReplicas"`
+
+	// ExpandingReplicas is the number of bound PVCs whose storage requests have been expanded,
+	// but the capacity is still less than the storage requests.
+	// The other bound PVCs are waiting for the expansion of replicas with smaller ordinals.
+	ExpandingReplicas int32 `json:"expandingReplicas"`
+
+	// UnsupportedReplicas is the number of bound PVCs that can not be expanded,
+	// because their StorageClasses do not allow volume expansion.
+	UnsupportedReplicas int32 `json:"unsupportedReplicas,omitempty"`
 }
 
 // StatefulSetStatus defines the observed state of StatefulSet
@@ -289,6 +407,15 @@ type StatefulSetStatus struct {
 
 	// LabelSelector is label selectors for query over pods that should match the replica count used by HPA.
 	LabelSelector string `json:"labelSelector,omitempty"`
+
+	// VolumeClaimTemplates is the expansion progress of the existing PVCs for each volumeClaimTemplate,
+	// which only works when volumeClaimUpdateStrategy type is Expand.
+	// +optional
+	VolumeClaimTemplates []StatefulSetVolumeClaimTemplateStatus `json:"volumeClaimTemplates,omitempty"`
+
+	// RevisionDiff is the diff from currentRevision to updateRevision, which is empty if they are the same.
+	// +optional
+	RevisionDiff *appspub.RevisionDiff `json:"revisionDiff,omitempty"`
 }
 
 // These are valid conditions of a statefulset.
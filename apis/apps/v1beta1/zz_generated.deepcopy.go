@@ -26,7 +26,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
@@ -129,6 +129,42 @@ func (in *StatefulSetList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatefulSetOrdinalOverride) DeepCopyInto(out *StatefulSetOrdinalOverride) {
+	*out = *in
+	if in.Ordinals != nil {
+		in, out := &in.Ordinals, &out.Ordinals
+		*out = make([]intstr.IntOrString, len(*in))
+		copy(*out, *in)
+	}
+	in.Patch.DeepCopyInto(&out.Patch)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatefulSetOrdinalOverride.
+func (in *StatefulSetOrdinalOverride) DeepCopy() *StatefulSetOrdinalOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(StatefulSetOrdinalOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatefulSetOrdinals) DeepCopyInto(out *StatefulSetOrdinals) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatefulSetOrdinals.
+func (in *StatefulSetOrdinals) DeepCopy() *StatefulSetOrdinals {
+	if in == nil {
+		return nil
+	}
+	out := new(StatefulSetOrdinals)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StatefulSetPersistentVolumeClaimRetentionPolicy) DeepCopyInto(out *StatefulSetPersistentVolumeClaimRetentionPolicy) {
 	*out = *in
@@ -144,6 +180,21 @@ func (in *StatefulSetPersistentVolumeClaimRetentionPolicy) DeepCopy() *StatefulS
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatefulSetPodOperationRateLimit) DeepCopyInto(out *StatefulSetPodOperationRateLimit) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatefulSetPodOperationRateLimit.
+func (in *StatefulSetPodOperationRateLimit) DeepCopy() *StatefulSetPodOperationRateLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(StatefulSetPodOperationRateLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StatefulSetScaleStrategy) DeepCopyInto(out *StatefulSetScaleStrategy) {
 	*out = *in
@@ -152,6 +203,11 @@ func (in *StatefulSetScaleStrategy) DeepCopyInto(out *StatefulSetScaleStrategy)
 		*out = new(intstr.IntOrString)
 		**out = **in
 	}
+	if in.PodOperationRateLimit != nil {
+		in, out := &in.PodOperationRateLimit, &out.PodOperationRateLimit
+		*out = new(StatefulSetPodOperationRateLimit)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatefulSetScaleStrategy.
@@ -196,6 +252,23 @@ func (in *StatefulSetSpec) DeepCopyInto(out *StatefulSetSpec) {
 		*out = make([]int, len(*in))
 		copy(*out, *in)
 	}
+	if in.ReserveOrdinalRanges != nil {
+		in, out := &in.ReserveOrdinalRanges, &out.ReserveOrdinalRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Ordinals != nil {
+		in, out := &in.Ordinals, &out.Ordinals
+		*out = new(StatefulSetOrdinals)
+		**out = **in
+	}
+	if in.OrdinalOverrides != nil {
+		in, out := &in.OrdinalOverrides, &out.OrdinalOverrides
+		*out = make([]StatefulSetOrdinalOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Lifecycle != nil {
 		in, out := &in.Lifecycle, &out.Lifecycle
 		*out = new(pub.Lifecycle)
@@ -211,6 +284,11 @@ func (in *StatefulSetSpec) DeepCopyInto(out *StatefulSetSpec) {
 		*out = new(StatefulSetPersistentVolumeClaimRetentionPolicy)
 		**out = **in
 	}
+	if in.VolumeClaimUpdateStrategy != nil {
+		in, out := &in.VolumeClaimUpdateStrategy, &out.VolumeClaimUpdateStrategy
+		*out = new(StatefulSetVolumeClaimUpdateStrategy)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatefulSetSpec.
@@ -238,6 +316,18 @@ func (in *StatefulSetStatus) DeepCopyInto(out *StatefulSetStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.VolumeClaimTemplates != nil {
+		in, out := &in.VolumeClaimTemplates, &out.VolumeClaimTemplates
+		*out = make([]StatefulSetVolumeClaimTemplateStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RevisionDiff != nil {
+		in, out := &in.RevisionDiff, &out.RevisionDiff
+		*out = new(pub.RevisionDiff)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatefulSetStatus.
@@ -270,6 +360,37 @@ func (in *StatefulSetUpdateStrategy) DeepCopy() *StatefulSetUpdateStrategy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatefulSetVolumeClaimTemplateStatus) DeepCopyInto(out *StatefulSetVolumeClaimTemplateStatus) {
+	*out = *in
+	out.Storage = in.Storage.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatefulSetVolumeClaimTemplateStatus.
+func (in *StatefulSetVolumeClaimTemplateStatus) DeepCopy() *StatefulSetVolumeClaimTemplateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StatefulSetVolumeClaimTemplateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatefulSetVolumeClaimUpdateStrategy) DeepCopyInto(out *StatefulSetVolumeClaimUpdateStrategy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatefulSetVolumeClaimUpdateStrategy.
+func (in *StatefulSetVolumeClaimUpdateStrategy) DeepCopy() *StatefulSetVolumeClaimUpdateStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(StatefulSetVolumeClaimUpdateStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UnorderedUpdateStrategy) DeepCopyInto(out *UnorderedUpdateStrategy) {
 	*out = *in
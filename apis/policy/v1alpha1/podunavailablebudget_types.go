@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -41,6 +42,49 @@ type PodUnavailableBudgetSpec struct {
 	// Delete pod, evict pod or update pod specification is allowed if at least "minAvailable" pods selected by
 	// "selector" or "targetRef" will still be available after the above operation for pod.
 	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+
+	// ReadinessConditions are the types of additional pod conditions that must be True for a pod to be counted as available,
+	// e.g. the custom conditions published by PodProbeMarker. By default, only the PodReady condition is checked.
+	// +optional
+	ReadinessConditions []corev1.PodConditionType `json:"readinessConditions,omitempty"`
+
+	// StaleTimeoutSeconds is how long a pod recorded in DisruptedPods or UnavailablePods stays unavailable
+	// before it is removed and the budget is refunded, in case the operation was not observed by the controller.
+	// Slow-starting applications may need a longer window, pods evicted by the Eviction API are removed after at most 10s.
+	// Defaults to the controller's global setting.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	StaleTimeoutSeconds *int32 `json:"staleTimeoutSeconds,omitempty"`
+
+	// FailurePolicy defines how to handle the pod operation if the webhook cannot complete the validation,
+	// e.g. the status update keeps conflicting or the latency budget is exceeded.
+	// It doesn't affect the operations rejected because the budget is exhausted or the PodUnavailableBudget is not found.
+	// +optional
+	FailurePolicy *PubFailurePolicy `json:"failurePolicy,omitempty"`
+}
+
+// PubFailurePolicyType is the type of failure policy
+type PubFailurePolicyType string
+
+const (
+	// PubFailurePolicyFail rejects the pod operation if the validation cannot be completed
+	PubFailurePolicyFail PubFailurePolicyType = "Fail"
+	// PubFailurePolicyIgnore allows the pod operation if the validation cannot be completed
+	PubFailurePolicyIgnore PubFailurePolicyType = "Ignore"
+)
+
+// PubFailurePolicy defines how to handle the pod operation if the validation cannot be completed
+type PubFailurePolicy struct {
+	// Type is Fail or Ignore, defaults to Fail.
+	// +kubebuilder:validation:Enum=Fail;Ignore
+	// +optional
+	Type PubFailurePolicyType `json:"type,omitempty"`
+
+	// TimeoutMilliseconds is the latency budget of the validation, the validation is regarded as failed
+	// if it cannot be completed within it. Defaults to no timeout.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TimeoutMilliseconds *int32 `json:"timeoutMilliseconds,omitempty"`
 }
 
 // TargetReference contains enough information to let you identify an workload for PodUnavailableBudget
@@ -65,6 +109,11 @@ type PodUnavailableBudgetStatus struct {
 	// +optional
 	DisruptedPods map[string]metav1.Time `json:"disruptedPods,omitempty"`
 
+	// EvictedPods contains the names of pods in DisruptedPods whose disruption was requested by the Eviction API,
+	// they will be removed from DisruptedPods after a separate eviction timeout.
+	// +optional
+	EvictedPods []string `json:"evictedPods,omitempty"`
+
 	// UnavailablePods contains information about pods whose specification changed(inplace-update pod),
 	// once pod is available(consistent and ready) again, it will be removed from the list.
 	// +optional
@@ -81,6 +130,47 @@ type PodUnavailableBudgetStatus struct {
 
 	// TotalReplicas total number of pods counted by this unavailable budget
 	TotalReplicas int32 `json:"totalReplicas"`
+
+	// Conditions represents the latest available observations of a PodUnavailableBudget's current state.
+	// +optional
+	Conditions []PodUnavailableBudgetCondition `json:"conditions,omitempty"`
+
+	// LastRejectedTime is the last time an operation for pod was rejected by this budget.
+	// +optional
+	LastRejectedTime *metav1.Time `json:"lastRejectedTime,omitempty"`
+
+	// RejectedCount is the number of operations rejected since this budget started blocking,
+	// it is reset if no operation has been rejected in the last 10 minutes.
+	// +optional
+	RejectedCount int32 `json:"rejectedCount,omitempty"`
+}
+
+// PodUnavailableBudgetConditionType is type for PodUnavailableBudget conditions.
+type PodUnavailableBudgetConditionType string
+
+const (
+	// PubConditionSufficientPods indicates whether the available pods are no less than the desired available pods.
+	PubConditionSufficientPods PodUnavailableBudgetConditionType = "SufficientPods"
+	// PubConditionBudgetExhausted indicates whether no more pods are allowed to be unavailable,
+	// and the pod operations protected by the budget will be rejected.
+	PubConditionBudgetExhausted PodUnavailableBudgetConditionType = "BudgetExhausted"
+	// PubConditionTooManyUnconfirmedPods indicates whether DisruptedPods and UnavailablePods exceed the max size,
+	// and the pod operations will be rejected until they are confirmed by the controller.
+	PubConditionTooManyUnconfirmedPods PodUnavailableBudgetConditionType = "TooManyUnconfirmedPods"
+)
+
+// PodUnavailableBudgetCondition describes the state of a PodUnavailableBudget at a certain point.
+type PodUnavailableBudgetCondition struct {
+	// Type of PodUnavailableBudget condition.
+	Type PodUnavailableBudgetConditionType `json:"type"`
+	// Status of the condition, one of True, False, Unknown.
+	Status corev1.ConditionStatus `json:"status"`
+	// Last time the condition transitioned from one status to another.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// The reason for the condition's last transition.
+	Reason string `json:"reason,omitempty"`
+	// A human readable message indicating details about the transition.
+	Message string `json:"message,omitempty"`
 }
 
 // +genclient
@@ -22,6 +22,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -54,6 +55,22 @@ func (in *PodUnavailableBudget) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodUnavailableBudgetCondition) DeepCopyInto(out *PodUnavailableBudgetCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodUnavailableBudgetCondition.
+func (in *PodUnavailableBudgetCondition) DeepCopy() *PodUnavailableBudgetCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(PodUnavailableBudgetCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PodUnavailableBudgetList) DeepCopyInto(out *PodUnavailableBudgetList) {
 	*out = *in
@@ -109,6 +126,21 @@ func (in *PodUnavailableBudgetSpec) DeepCopyInto(out *PodUnavailableBudgetSpec)
 		*out = new(intstr.IntOrString)
 		**out = **in
 	}
+	if in.ReadinessConditions != nil {
+		in, out := &in.ReadinessConditions, &out.ReadinessConditions
+		*out = make([]corev1.PodConditionType, len(*in))
+		copy(*out, *in)
+	}
+	if in.StaleTimeoutSeconds != nil {
+		in, out := &in.StaleTimeoutSeconds, &out.StaleTimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailurePolicy != nil {
+		in, out := &in.FailurePolicy, &out.FailurePolicy
+		*out = new(PubFailurePolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodUnavailableBudgetSpec.
@@ -131,6 +163,11 @@ func (in *PodUnavailableBudgetStatus) DeepCopyInto(out *PodUnavailableBudgetStat
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.EvictedPods != nil {
+		in, out := &in.EvictedPods, &out.EvictedPods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.UnavailablePods != nil {
 		in, out := &in.UnavailablePods, &out.UnavailablePods
 		*out = make(map[string]v1.Time, len(*in))
@@ -138,6 +175,17 @@ func (in *PodUnavailableBudgetStatus) DeepCopyInto(out *PodUnavailableBudgetStat
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]PodUnavailableBudgetCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastRejectedTime != nil {
+		in, out := &in.LastRejectedTime, &out.LastRejectedTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodUnavailableBudgetStatus.
@@ -150,6 +198,26 @@ func (in *PodUnavailableBudgetStatus) DeepCopy() *PodUnavailableBudgetStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PubFailurePolicy) DeepCopyInto(out *PubFailurePolicy) {
+	*out = *in
+	if in.TimeoutMilliseconds != nil {
+		in, out := &in.TimeoutMilliseconds, &out.TimeoutMilliseconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PubFailurePolicy.
+func (in *PubFailurePolicy) DeepCopy() *PubFailurePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(PubFailurePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TargetReference) DeepCopyInto(out *TargetReference) {
 	*out = *in
@@ -23,6 +23,8 @@ import (
 	_ "net/http/pprof"
 	"os"
 	"time"
+	// embed the time zone database for the time zones of AdvancedCronJob
+	_ "time/tzdata"
 
 	"github.com/spf13/pflag"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -37,11 +39,13 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	extclient "github.com/openkruise/kruise/pkg/client"
+	"github.com/openkruise/kruise/pkg/control/pubcontrol"
 	"github.com/openkruise/kruise/pkg/features"
 	utilclient "github.com/openkruise/kruise/pkg/util/client"
 	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
 	"github.com/openkruise/kruise/pkg/util/fieldindex"
 	"github.com/openkruise/kruise/pkg/webhook"
+	podvalidating "github.com/openkruise/kruise/pkg/webhook/pod/validating"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
 	appsv1beta1 "github.com/openkruise/kruise/apis/apps/v1beta1"
@@ -98,6 +102,14 @@ func main() {
 	rand.Seed(time.Now().UnixNano())
 	ctrl.SetLogger(klogr.New())
 	features.SetDefaultFeatureGates()
+	if err := pubcontrol.ValidateConflictRetry(); err != nil {
+		setupLog.Error(err, "invalid pub conflict retry flags")
+		os.Exit(1)
+	}
+	if err := podvalidating.ValidateTaintEvictionPolicy(); err != nil {
+		setupLog.Error(err, "invalid pub taint eviction policy flag")
+		os.Exit(1)
+	}
 
 	if enablePprof {
 		go func() {
@@ -29,8 +29,11 @@ type AppsV1alpha1Interface interface {
 	BroadcastJobsGetter
 	CloneSetsGetter
 	ContainerRecreateRequestsGetter
+	ContainerRecreateRequestSetsGetter
 	DaemonSetsGetter
 	EphemeralJobsGetter
+	ImageDeleteJobsGetter
+	ImageListPullJobsGetter
 	ImagePullJobsGetter
 	NodeImagesGetter
 	ResourceDistributionsGetter
@@ -61,6 +64,10 @@ func (c *AppsV1alpha1Client) ContainerRecreateRequests(namespace string) Contain
 	return newContainerRecreateRequests(c, namespace)
 }
 
+func (c *AppsV1alpha1Client) ContainerRecreateRequestSets(namespace string) ContainerRecreateRequestSetInterface {
+	return newContainerRecreateRequestSets(c, namespace)
+}
+
 func (c *AppsV1alpha1Client) DaemonSets(namespace string) DaemonSetInterface {
 	return newDaemonSets(c, namespace)
 }
@@ -69,6 +76,14 @@ func (c *AppsV1alpha1Client) EphemeralJobs(namespace string) EphemeralJobInterfa
 	return newEphemeralJobs(c, namespace)
 }
 
+func (c *AppsV1alpha1Client) ImageDeleteJobs() ImageDeleteJobInterface {
+	return newImageDeleteJobs(c)
+}
+
+func (c *AppsV1alpha1Client) ImageListPullJobs(namespace string) ImageListPullJobInterface {
+	return newImageListPullJobs(c, namespace)
+}
+
 func (c *AppsV1alpha1Client) ImagePullJobs(namespace string) ImagePullJobInterface {
 	return newImagePullJobs(c, namespace)
 }
@@ -0,0 +1,194 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"context"
+	"time"
+
+	v1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	scheme "github.com/openkruise/kruise/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// ContainerRecreateRequestSetsGetter has a method to return a ContainerRecreateRequestSetInterface.
+// A group's client should implement this interface.
+type ContainerRecreateRequestSetsGetter interface {
+	ContainerRecreateRequestSets(namespace string) ContainerRecreateRequestSetInterface
+}
+
+// ContainerRecreateRequestSetInterface has methods to work with ContainerRecreateRequestSet resources.
+type ContainerRecreateRequestSetInterface interface {
+	Create(ctx context.Context, containerRecreateRequestSet *v1alpha1.ContainerRecreateRequestSet, opts v1.CreateOptions) (*v1alpha1.ContainerRecreateRequestSet, error)
+	Update(ctx context.Context, containerRecreateRequestSet *v1alpha1.ContainerRecreateRequestSet, opts v1.UpdateOptions) (*v1alpha1.ContainerRecreateRequestSet, error)
+	UpdateStatus(ctx context.Context, containerRecreateRequestSet *v1alpha1.ContainerRecreateRequestSet, opts v1.UpdateOptions) (*v1alpha1.ContainerRecreateRequestSet, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.ContainerRecreateRequestSet, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.ContainerRecreateRequestSetList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.ContainerRecreateRequestSet, err error)
+	ContainerRecreateRequestSetExpansion
+}
+
+// containerRecreateRequestSets implements ContainerRecreateRequestSetInterface
+type containerRecreateRequestSets struct {
+	client rest.Interface
+	ns     string
+}
+
+// newContainerRecreateRequestSets returns a ContainerRecreateRequestSets
+func newContainerRecreateRequestSets(c *AppsV1alpha1Client, namespace string) *containerRecreateRequestSets {
+	return &containerRecreateRequestSets{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the containerRecreateRequestSet, and returns the corresponding containerRecreateRequestSet object, and an error if there is any.
+func (c *containerRecreateRequestSets) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.ContainerRecreateRequestSet, err error) {
+	result = &v1alpha1.ContainerRecreateRequestSet{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("containerrecreaterequestsets").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of ContainerRecreateRequestSets that match those selectors.
+func (c *containerRecreateRequestSets) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.ContainerRecreateRequestSetList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha1.ContainerRecreateRequestSetList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("containerrecreaterequestsets").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested containerRecreateRequestSets.
+func (c *containerRecreateRequestSets) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("containerrecreaterequestsets").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a containerRecreateRequestSet and creates it.  Returns the server's representation of the containerRecreateRequestSet, and an error, if there is any.
+func (c *containerRecreateRequestSets) Create(ctx context.Context, containerRecreateRequestSet *v1alpha1.ContainerRecreateRequestSet, opts v1.CreateOptions) (result *v1alpha1.ContainerRecreateRequestSet, err error) {
+	result = &v1alpha1.ContainerRecreateRequestSet{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("containerrecreaterequestsets").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(containerRecreateRequestSet).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a containerRecreateRequestSet and updates it. Returns the server's representation of the containerRecreateRequestSet, and an error, if there is any.
+func (c *containerRecreateRequestSets) Update(ctx context.Context, containerRecreateRequestSet *v1alpha1.ContainerRecreateRequestSet, opts v1.UpdateOptions) (result *v1alpha1.ContainerRecreateRequestSet, err error) {
+	result = &v1alpha1.ContainerRecreateRequestSet{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("containerrecreaterequestsets").
+		Name(containerRecreateRequestSet.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(containerRecreateRequestSet).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *containerRecreateRequestSets) UpdateStatus(ctx context.Context, containerRecreateRequestSet *v1alpha1.ContainerRecreateRequestSet, opts v1.UpdateOptions) (result *v1alpha1.ContainerRecreateRequestSet, err error) {
+	result = &v1alpha1.ContainerRecreateRequestSet{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("containerrecreaterequestsets").
+		Name(containerRecreateRequestSet.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(containerRecreateRequestSet).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the containerRecreateRequestSet and deletes it. Returns an error if one occurs.
+func (c *containerRecreateRequestSets) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("containerrecreaterequestsets").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *containerRecreateRequestSets) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("containerrecreaterequestsets").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched containerRecreateRequestSet.
+func (c *containerRecreateRequestSets) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.ContainerRecreateRequestSet, err error) {
+	result = &v1alpha1.ContainerRecreateRequestSet{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("containerrecreaterequestsets").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
@@ -43,6 +43,10 @@ func (c *FakeAppsV1alpha1) ContainerRecreateRequests(namespace string) v1alpha1.
 	return &FakeContainerRecreateRequests{c, namespace}
 }
 
+func (c *FakeAppsV1alpha1) ContainerRecreateRequestSets(namespace string) v1alpha1.ContainerRecreateRequestSetInterface {
+	return &FakeContainerRecreateRequestSets{c, namespace}
+}
+
 func (c *FakeAppsV1alpha1) DaemonSets(namespace string) v1alpha1.DaemonSetInterface {
 	return &FakeDaemonSets{c, namespace}
 }
@@ -51,6 +55,14 @@ func (c *FakeAppsV1alpha1) EphemeralJobs(namespace string) v1alpha1.EphemeralJob
 	return &FakeEphemeralJobs{c, namespace}
 }
 
+func (c *FakeAppsV1alpha1) ImageDeleteJobs() v1alpha1.ImageDeleteJobInterface {
+	return &FakeImageDeleteJobs{c}
+}
+
+func (c *FakeAppsV1alpha1) ImageListPullJobs(namespace string) v1alpha1.ImageListPullJobInterface {
+	return &FakeImageListPullJobs{c, namespace}
+}
+
 func (c *FakeAppsV1alpha1) ImagePullJobs(namespace string) v1alpha1.ImagePullJobInterface {
 	return &FakeImagePullJobs{c, namespace}
 }
@@ -0,0 +1,141 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeContainerRecreateRequestSets implements ContainerRecreateRequestSetInterface
+type FakeContainerRecreateRequestSets struct {
+	Fake *FakeAppsV1alpha1
+	ns   string
+}
+
+var containerrecreaterequestsetsResource = schema.GroupVersionResource{Group: "apps.kruise.io", Version: "v1alpha1", Resource: "containerrecreaterequestsets"}
+
+var containerrecreaterequestsetsKind = schema.GroupVersionKind{Group: "apps.kruise.io", Version: "v1alpha1", Kind: "ContainerRecreateRequestSet"}
+
+// Get takes name of the containerRecreateRequestSet, and returns the corresponding containerRecreateRequestSet object, and an error if there is any.
+func (c *FakeContainerRecreateRequestSets) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.ContainerRecreateRequestSet, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(containerrecreaterequestsetsResource, c.ns, name), &v1alpha1.ContainerRecreateRequestSet{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ContainerRecreateRequestSet), err
+}
+
+// List takes label and field selectors, and returns the list of ContainerRecreateRequestSets that match those selectors.
+func (c *FakeContainerRecreateRequestSets) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.ContainerRecreateRequestSetList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(containerrecreaterequestsetsResource, containerrecreaterequestsetsKind, c.ns, opts), &v1alpha1.ContainerRecreateRequestSetList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.ContainerRecreateRequestSetList{ListMeta: obj.(*v1alpha1.ContainerRecreateRequestSetList).ListMeta}
+	for _, item := range obj.(*v1alpha1.ContainerRecreateRequestSetList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested containerRecreateRequestSets.
+func (c *FakeContainerRecreateRequestSets) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(containerrecreaterequestsetsResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a containerRecreateRequestSet and creates it.  Returns the server's representation of the containerRecreateRequestSet, and an error, if there is any.
+func (c *FakeContainerRecreateRequestSets) Create(ctx context.Context, containerRecreateRequestSet *v1alpha1.ContainerRecreateRequestSet, opts v1.CreateOptions) (result *v1alpha1.ContainerRecreateRequestSet, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(containerrecreaterequestsetsResource, c.ns, containerRecreateRequestSet), &v1alpha1.ContainerRecreateRequestSet{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ContainerRecreateRequestSet), err
+}
+
+// Update takes the representation of a containerRecreateRequestSet and updates it. Returns the server's representation of the containerRecreateRequestSet, and an error, if there is any.
+func (c *FakeContainerRecreateRequestSets) Update(ctx context.Context, containerRecreateRequestSet *v1alpha1.ContainerRecreateRequestSet, opts v1.UpdateOptions) (result *v1alpha1.ContainerRecreateRequestSet, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(containerrecreaterequestsetsResource, c.ns, containerRecreateRequestSet), &v1alpha1.ContainerRecreateRequestSet{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ContainerRecreateRequestSet), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeContainerRecreateRequestSets) UpdateStatus(ctx context.Context, containerRecreateRequestSet *v1alpha1.ContainerRecreateRequestSet, opts v1.UpdateOptions) (*v1alpha1.ContainerRecreateRequestSet, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(containerrecreaterequestsetsResource, "status", c.ns, containerRecreateRequestSet), &v1alpha1.ContainerRecreateRequestSet{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ContainerRecreateRequestSet), err
+}
+
+// Delete takes name of the containerRecreateRequestSet and deletes it. Returns an error if one occurs.
+func (c *FakeContainerRecreateRequestSets) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(containerrecreaterequestsetsResource, c.ns, name), &v1alpha1.ContainerRecreateRequestSet{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeContainerRecreateRequestSets) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(containerrecreaterequestsetsResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.ContainerRecreateRequestSetList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched containerRecreateRequestSet.
+func (c *FakeContainerRecreateRequestSets) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.ContainerRecreateRequestSet, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(containerrecreaterequestsetsResource, c.ns, name, pt, data, subresources...), &v1alpha1.ContainerRecreateRequestSet{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ContainerRecreateRequestSet), err
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeImageDeleteJobs implements ImageDeleteJobInterface
+type FakeImageDeleteJobs struct {
+	Fake *FakeAppsV1alpha1
+}
+
+var imagedeletejobsResource = schema.GroupVersionResource{Group: "apps.kruise.io", Version: "v1alpha1", Resource: "imagedeletejobs"}
+
+var imagedeletejobsKind = schema.GroupVersionKind{Group: "apps.kruise.io", Version: "v1alpha1", Kind: "ImageDeleteJob"}
+
+// Get takes name of the imageDeleteJob, and returns the corresponding imageDeleteJob object, and an error if there is any.
+func (c *FakeImageDeleteJobs) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.ImageDeleteJob, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootGetAction(imagedeletejobsResource, name), &v1alpha1.ImageDeleteJob{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ImageDeleteJob), err
+}
+
+// List takes label and field selectors, and returns the list of ImageDeleteJobs that match those selectors.
+func (c *FakeImageDeleteJobs) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.ImageDeleteJobList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootListAction(imagedeletejobsResource, imagedeletejobsKind, opts), &v1alpha1.ImageDeleteJobList{})
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.ImageDeleteJobList{ListMeta: obj.(*v1alpha1.ImageDeleteJobList).ListMeta}
+	for _, item := range obj.(*v1alpha1.ImageDeleteJobList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested imageDeleteJobs.
+func (c *FakeImageDeleteJobs) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewRootWatchAction(imagedeletejobsResource, opts))
+}
+
+// Create takes the representation of a imageDeleteJob and creates it.  Returns the server's representation of the imageDeleteJob, and an error, if there is any.
+func (c *FakeImageDeleteJobs) Create(ctx context.Context, imageDeleteJob *v1alpha1.ImageDeleteJob, opts v1.CreateOptions) (result *v1alpha1.ImageDeleteJob, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootCreateAction(imagedeletejobsResource, imageDeleteJob), &v1alpha1.ImageDeleteJob{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ImageDeleteJob), err
+}
+
+// Update takes the representation of a imageDeleteJob and updates it. Returns the server's representation of the imageDeleteJob, and an error, if there is any.
+func (c *FakeImageDeleteJobs) Update(ctx context.Context, imageDeleteJob *v1alpha1.ImageDeleteJob, opts v1.UpdateOptions) (result *v1alpha1.ImageDeleteJob, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateAction(imagedeletejobsResource, imageDeleteJob), &v1alpha1.ImageDeleteJob{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ImageDeleteJob), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeImageDeleteJobs) UpdateStatus(ctx context.Context, imageDeleteJob *v1alpha1.ImageDeleteJob, opts v1.UpdateOptions) (*v1alpha1.ImageDeleteJob, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateSubresourceAction(imagedeletejobsResource, "status", imageDeleteJob), &v1alpha1.ImageDeleteJob{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ImageDeleteJob), err
+}
+
+// Delete takes name of the imageDeleteJob and deletes it. Returns an error if one occurs.
+func (c *FakeImageDeleteJobs) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewRootDeleteAction(imagedeletejobsResource, name), &v1alpha1.ImageDeleteJob{})
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeImageDeleteJobs) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewRootDeleteCollectionAction(imagedeletejobsResource, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.ImageDeleteJobList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched imageDeleteJob.
+func (c *FakeImageDeleteJobs) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.ImageDeleteJob, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(imagedeletejobsResource, name, pt, data, subresources...), &v1alpha1.ImageDeleteJob{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ImageDeleteJob), err
+}
@@ -25,10 +25,16 @@ type CloneSetExpansion interface{}
 
 type ContainerRecreateRequestExpansion interface{}
 
+type ContainerRecreateRequestSetExpansion interface{}
+
 type DaemonSetExpansion interface{}
 
 type EphemeralJobExpansion interface{}
 
+type ImageDeleteJobExpansion interface{}
+
+type ImageListPullJobExpansion interface{}
+
 type ImagePullJobExpansion interface{}
 
 type NodeImageExpansion interface{}
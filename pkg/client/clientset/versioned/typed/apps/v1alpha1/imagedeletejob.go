@@ -0,0 +1,183 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"context"
+	"time"
+
+	v1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	scheme "github.com/openkruise/kruise/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// ImageDeleteJobsGetter has a method to return a ImageDeleteJobInterface.
+// A group's client should implement this interface.
+type ImageDeleteJobsGetter interface {
+	ImageDeleteJobs() ImageDeleteJobInterface
+}
+
+// ImageDeleteJobInterface has methods to work with ImageDeleteJob resources.
+type ImageDeleteJobInterface interface {
+	Create(ctx context.Context, imageDeleteJob *v1alpha1.ImageDeleteJob, opts v1.CreateOptions) (*v1alpha1.ImageDeleteJob, error)
+	Update(ctx context.Context, imageDeleteJob *v1alpha1.ImageDeleteJob, opts v1.UpdateOptions) (*v1alpha1.ImageDeleteJob, error)
+	UpdateStatus(ctx context.Context, imageDeleteJob *v1alpha1.ImageDeleteJob, opts v1.UpdateOptions) (*v1alpha1.ImageDeleteJob, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.ImageDeleteJob, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.ImageDeleteJobList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.ImageDeleteJob, err error)
+	ImageDeleteJobExpansion
+}
+
+// imageDeleteJobs implements ImageDeleteJobInterface
+type imageDeleteJobs struct {
+	client rest.Interface
+}
+
+// newImageDeleteJobs returns a ImageDeleteJobs
+func newImageDeleteJobs(c *AppsV1alpha1Client) *imageDeleteJobs {
+	return &imageDeleteJobs{
+		client: c.RESTClient(),
+	}
+}
+
+// Get takes name of the imageDeleteJob, and returns the corresponding imageDeleteJob object, and an error if there is any.
+func (c *imageDeleteJobs) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.ImageDeleteJob, err error) {
+	result = &v1alpha1.ImageDeleteJob{}
+	err = c.client.Get().
+		Resource("imagedeletejobs").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of ImageDeleteJobs that match those selectors.
+func (c *imageDeleteJobs) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.ImageDeleteJobList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha1.ImageDeleteJobList{}
+	err = c.client.Get().
+		Resource("imagedeletejobs").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested imageDeleteJobs.
+func (c *imageDeleteJobs) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Resource("imagedeletejobs").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a imageDeleteJob and creates it.  Returns the server's representation of the imageDeleteJob, and an error, if there is any.
+func (c *imageDeleteJobs) Create(ctx context.Context, imageDeleteJob *v1alpha1.ImageDeleteJob, opts v1.CreateOptions) (result *v1alpha1.ImageDeleteJob, err error) {
+	result = &v1alpha1.ImageDeleteJob{}
+	err = c.client.Post().
+		Resource("imagedeletejobs").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(imageDeleteJob).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a imageDeleteJob and updates it. Returns the server's representation of the imageDeleteJob, and an error, if there is any.
+func (c *imageDeleteJobs) Update(ctx context.Context, imageDeleteJob *v1alpha1.ImageDeleteJob, opts v1.UpdateOptions) (result *v1alpha1.ImageDeleteJob, err error) {
+	result = &v1alpha1.ImageDeleteJob{}
+	err = c.client.Put().
+		Resource("imagedeletejobs").
+		Name(imageDeleteJob.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(imageDeleteJob).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *imageDeleteJobs) UpdateStatus(ctx context.Context, imageDeleteJob *v1alpha1.ImageDeleteJob, opts v1.UpdateOptions) (result *v1alpha1.ImageDeleteJob, err error) {
+	result = &v1alpha1.ImageDeleteJob{}
+	err = c.client.Put().
+		Resource("imagedeletejobs").
+		Name(imageDeleteJob.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(imageDeleteJob).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the imageDeleteJob and deletes it. Returns an error if one occurs.
+func (c *imageDeleteJobs) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Resource("imagedeletejobs").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *imageDeleteJobs) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Resource("imagedeletejobs").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched imageDeleteJob.
+func (c *imageDeleteJobs) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.ImageDeleteJob, err error) {
+	result = &v1alpha1.ImageDeleteJob{}
+	err = c.client.Patch(pt).
+		Resource("imagedeletejobs").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
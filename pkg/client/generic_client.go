@@ -2,10 +2,16 @@ package client
 
 import (
 	kruiseclientset "github.com/openkruise/kruise/pkg/client/clientset/versioned"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
 	kubeclientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/scale"
 )
 
 // GenericClientset defines a generic client
@@ -13,6 +19,7 @@ type GenericClientset struct {
 	DiscoveryClient discovery.DiscoveryInterface
 	KubeClient      kubeclientset.Interface
 	KruiseClient    kruiseclientset.Interface
+	ScaleClient     scale.ScalesGetter
 }
 
 // newForConfig creates a new Clientset for the given config.
@@ -31,10 +38,17 @@ func newForConfig(c *rest.Config) (*GenericClientset, error) {
 	if err != nil {
 		return nil, err
 	}
+	scaleMapper := scaleRESTMapper{restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient))}
+	scaleClient, err := scale.NewForConfig(rest.CopyConfig(c), scaleMapper,
+		dynamic.LegacyAPIPathResolverFunc, scale.NewDiscoveryScaleKindResolver(discoveryClient))
+	if err != nil {
+		return nil, err
+	}
 	return &GenericClientset{
 		DiscoveryClient: discoveryClient,
 		KubeClient:      kubeClient,
 		KruiseClient:    kruiseClient,
+		ScaleClient:     scaleClient,
 	}, nil
 }
 
@@ -46,3 +60,18 @@ func newForConfigOrDie(c *rest.Config) *GenericClientset {
 	}
 	return gc
 }
+
+// scaleRESTMapper resets the cached discovery and retries once if the resource is not found,
+// so that the CRDs installed after the client created can be resolved by scale client.
+type scaleRESTMapper struct {
+	*restmapper.DeferredDiscoveryRESTMapper
+}
+
+func (m scaleRESTMapper) ResourceFor(input schema.GroupVersionResource) (schema.GroupVersionResource, error) {
+	gvr, err := m.DeferredDiscoveryRESTMapper.ResourceFor(input)
+	if meta.IsNoMatchError(err) {
+		m.Reset()
+		return m.DeferredDiscoveryRESTMapper.ResourceFor(input)
+	}
+	return gvr, err
+}
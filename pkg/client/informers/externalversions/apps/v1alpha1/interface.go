@@ -31,10 +31,16 @@ type Interface interface {
 	CloneSets() CloneSetInformer
 	// ContainerRecreateRequests returns a ContainerRecreateRequestInformer.
 	ContainerRecreateRequests() ContainerRecreateRequestInformer
+	// ContainerRecreateRequestSets returns a ContainerRecreateRequestSetInformer.
+	ContainerRecreateRequestSets() ContainerRecreateRequestSetInformer
 	// DaemonSets returns a DaemonSetInformer.
 	DaemonSets() DaemonSetInformer
 	// EphemeralJobs returns a EphemeralJobInformer.
 	EphemeralJobs() EphemeralJobInformer
+	// ImageDeleteJobs returns a ImageDeleteJobInformer.
+	ImageDeleteJobs() ImageDeleteJobInformer
+	// ImageListPullJobs returns a ImageListPullJobInformer.
+	ImageListPullJobs() ImageListPullJobInformer
 	// ImagePullJobs returns a ImagePullJobInformer.
 	ImagePullJobs() ImagePullJobInformer
 	// NodeImages returns a NodeImageInformer.
@@ -82,6 +88,11 @@ func (v *version) ContainerRecreateRequests() ContainerRecreateRequestInformer {
 	return &containerRecreateRequestInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// ContainerRecreateRequestSets returns a ContainerRecreateRequestSetInformer.
+func (v *version) ContainerRecreateRequestSets() ContainerRecreateRequestSetInformer {
+	return &containerRecreateRequestSetInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // DaemonSets returns a DaemonSetInformer.
 func (v *version) DaemonSets() DaemonSetInformer {
 	return &daemonSetInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -92,6 +103,16 @@ func (v *version) EphemeralJobs() EphemeralJobInformer {
 	return &ephemeralJobInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// ImageDeleteJobs returns a ImageDeleteJobInformer.
+func (v *version) ImageDeleteJobs() ImageDeleteJobInformer {
+	return &imageDeleteJobInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
+// ImageListPullJobs returns a ImageListPullJobInformer.
+func (v *version) ImageListPullJobs() ImageListPullJobInformer {
+	return &imageListPullJobInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // ImagePullJobs returns a ImagePullJobInformer.
 func (v *version) ImagePullJobs() ImagePullJobInformer {
 	return &imagePullJobInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
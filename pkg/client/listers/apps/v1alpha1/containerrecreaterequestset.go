@@ -0,0 +1,98 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ContainerRecreateRequestSetLister helps list ContainerRecreateRequestSets.
+// All objects returned here must be treated as read-only.
+type ContainerRecreateRequestSetLister interface {
+	// List lists all ContainerRecreateRequestSets in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha1.ContainerRecreateRequestSet, err error)
+	// ContainerRecreateRequestSets returns an object that can list and get ContainerRecreateRequestSets.
+	ContainerRecreateRequestSets(namespace string) ContainerRecreateRequestSetNamespaceLister
+	ContainerRecreateRequestSetListerExpansion
+}
+
+// containerRecreateRequestSetLister implements the ContainerRecreateRequestSetLister interface.
+type containerRecreateRequestSetLister struct {
+	indexer cache.Indexer
+}
+
+// NewContainerRecreateRequestSetLister returns a new ContainerRecreateRequestSetLister.
+func NewContainerRecreateRequestSetLister(indexer cache.Indexer) ContainerRecreateRequestSetLister {
+	return &containerRecreateRequestSetLister{indexer: indexer}
+}
+
+// List lists all ContainerRecreateRequestSets in the indexer.
+func (s *containerRecreateRequestSetLister) List(selector labels.Selector) (ret []*v1alpha1.ContainerRecreateRequestSet, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.ContainerRecreateRequestSet))
+	})
+	return ret, err
+}
+
+// ContainerRecreateRequestSets returns an object that can list and get ContainerRecreateRequestSets.
+func (s *containerRecreateRequestSetLister) ContainerRecreateRequestSets(namespace string) ContainerRecreateRequestSetNamespaceLister {
+	return containerRecreateRequestSetNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// ContainerRecreateRequestSetNamespaceLister helps list and get ContainerRecreateRequestSets.
+// All objects returned here must be treated as read-only.
+type ContainerRecreateRequestSetNamespaceLister interface {
+	// List lists all ContainerRecreateRequestSets in the indexer for a given namespace.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha1.ContainerRecreateRequestSet, err error)
+	// Get retrieves the ContainerRecreateRequestSet from the indexer for a given namespace and name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1alpha1.ContainerRecreateRequestSet, error)
+	ContainerRecreateRequestSetNamespaceListerExpansion
+}
+
+// containerRecreateRequestSetNamespaceLister implements the ContainerRecreateRequestSetNamespaceLister
+// interface.
+type containerRecreateRequestSetNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all ContainerRecreateRequestSets in the indexer for a given namespace.
+func (s containerRecreateRequestSetNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.ContainerRecreateRequestSet, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.ContainerRecreateRequestSet))
+	})
+	return ret, err
+}
+
+// Get retrieves the ContainerRecreateRequestSet from the indexer for a given namespace and name.
+func (s containerRecreateRequestSetNamespaceLister) Get(name string) (*v1alpha1.ContainerRecreateRequestSet, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("containerrecreaterequestset"), name)
+	}
+	return obj.(*v1alpha1.ContainerRecreateRequestSet), nil
+}
@@ -49,6 +49,14 @@ type ContainerRecreateRequestListerExpansion interface{}
 // ContainerRecreateRequestNamespaceLister.
 type ContainerRecreateRequestNamespaceListerExpansion interface{}
 
+// ContainerRecreateRequestSetListerExpansion allows custom methods to be added to
+// ContainerRecreateRequestSetLister.
+type ContainerRecreateRequestSetListerExpansion interface{}
+
+// ContainerRecreateRequestSetNamespaceListerExpansion allows custom methods to be added to
+// ContainerRecreateRequestSetNamespaceLister.
+type ContainerRecreateRequestSetNamespaceListerExpansion interface{}
+
 // DaemonSetListerExpansion allows custom methods to be added to
 // DaemonSetLister.
 type DaemonSetListerExpansion interface{}
@@ -65,6 +73,18 @@ type EphemeralJobListerExpansion interface{}
 // EphemeralJobNamespaceLister.
 type EphemeralJobNamespaceListerExpansion interface{}
 
+// ImageDeleteJobListerExpansion allows custom methods to be added to
+// ImageDeleteJobLister.
+type ImageDeleteJobListerExpansion interface{}
+
+// ImageListPullJobListerExpansion allows custom methods to be added to
+// ImageListPullJobLister.
+type ImageListPullJobListerExpansion interface{}
+
+// ImageListPullJobNamespaceListerExpansion allows custom methods to be added to
+// ImageListPullJobNamespaceLister.
+type ImageListPullJobNamespaceListerExpansion interface{}
+
 // ImagePullJobListerExpansion allows custom methods to be added to
 // ImagePullJobLister.
 type ImagePullJobListerExpansion interface{}
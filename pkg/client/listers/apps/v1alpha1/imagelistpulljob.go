@@ -0,0 +1,98 @@
+/*
+Copyright 2021 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ImageListPullJobLister helps list ImageListPullJobs.
+// All objects returned here must be treated as read-only.
+type ImageListPullJobLister interface {
+	// List lists all ImageListPullJobs in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha1.ImageListPullJob, err error)
+	// ImageListPullJobs returns an object that can list and get ImageListPullJobs.
+	ImageListPullJobs(namespace string) ImageListPullJobNamespaceLister
+	ImageListPullJobListerExpansion
+}
+
+// imageListPullJobLister implements the ImageListPullJobLister interface.
+type imageListPullJobLister struct {
+	indexer cache.Indexer
+}
+
+// NewImageListPullJobLister returns a new ImageListPullJobLister.
+func NewImageListPullJobLister(indexer cache.Indexer) ImageListPullJobLister {
+	return &imageListPullJobLister{indexer: indexer}
+}
+
+// List lists all ImageListPullJobs in the indexer.
+func (s *imageListPullJobLister) List(selector labels.Selector) (ret []*v1alpha1.ImageListPullJob, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.ImageListPullJob))
+	})
+	return ret, err
+}
+
+// ImageListPullJobs returns an object that can list and get ImageListPullJobs.
+func (s *imageListPullJobLister) ImageListPullJobs(namespace string) ImageListPullJobNamespaceLister {
+	return imageListPullJobNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// ImageListPullJobNamespaceLister helps list and get ImageListPullJobs.
+// All objects returned here must be treated as read-only.
+type ImageListPullJobNamespaceLister interface {
+	// List lists all ImageListPullJobs in the indexer for a given namespace.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha1.ImageListPullJob, err error)
+	// Get retrieves the ImageListPullJob from the indexer for a given namespace and name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1alpha1.ImageListPullJob, error)
+	ImageListPullJobNamespaceListerExpansion
+}
+
+// imageListPullJobNamespaceLister implements the ImageListPullJobNamespaceLister
+// interface.
+type imageListPullJobNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all ImageListPullJobs in the indexer for a given namespace.
+func (s imageListPullJobNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.ImageListPullJob, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.ImageListPullJob))
+	})
+	return ret, err
+}
+
+// Get retrieves the ImageListPullJob from the indexer for a given namespace and name.
+func (s imageListPullJobNamespaceLister) Get(name string) (*v1alpha1.ImageListPullJob, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("imagelistpulljob"), name)
+	}
+	return obj.(*v1alpha1.ImageListPullJob), nil
+}
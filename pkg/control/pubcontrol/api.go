@@ -27,7 +27,8 @@ type PubControl interface {
 	// IsPodReady indicates whether pod is fully ready
 	// 1. pod.Status.Phase == v1.PodRunning
 	// 2. pod.condition PodReady == true
-	IsPodReady(pod *corev1.Pod) bool
+	// 3. pod.conditions in pub.spec.readinessConditions == true
+	IsPodReady(pod *corev1.Pod, pub *policyv1alpha1.PodUnavailableBudget) bool
 	// IsPodStateConsistent indicates whether pod.spec and pod.status are consistent after updating containers
 	IsPodStateConsistent(pod *corev1.Pod) bool
 	// GetPodsForPub returns Pods protected by the pub object.
@@ -35,6 +36,8 @@ type PubControl interface {
 	// 1. podList
 	// 2. expectedCount, the default is workload.Replicas
 	GetPodsForPub(pub *policyv1alpha1.PodUnavailableBudget) ([]*corev1.Pod, int32, error)
+	// GetPodsWithRelatedPub returns Pods whose related-pub annotation is the pub name, even if the pub has been deleted.
+	GetPodsWithRelatedPub(namespace, pubName string) ([]*corev1.Pod, error)
 
 	// webhook
 	// determine if this change to pod might cause unavailability
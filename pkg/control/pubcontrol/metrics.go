@@ -0,0 +1,90 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pubcontrol
+
+import (
+	"time"
+
+	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	admissionResultAllowed = "allowed"
+	admissionResultDenied  = "denied"
+)
+
+var (
+	// pubAdmissionCounter counts the pod operations validated by pub, labeled with the result
+	pubAdmissionCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kruise_pub_admission_total",
+			Help: "Number of pod operations validated by PodUnavailableBudget",
+		},
+		[]string{"namespace", "pub", "operation", "result"},
+	)
+
+	// pubConflictRetryCounter counts the conflicts when updating pub status in webhook
+	pubConflictRetryCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kruise_pub_conflict_retry_total",
+			Help: "Number of conflict retries when updating PodUnavailableBudget status in webhook",
+		},
+		[]string{"namespace", "pub"},
+	)
+
+	pubGetDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "kruise_pub_webhook_get_duration_seconds",
+			Help:    "Latency of getting PodUnavailableBudget in webhook",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"namespace", "pub"},
+	)
+
+	pubUpdateDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "kruise_pub_webhook_update_duration_seconds",
+			Help:    "Latency of updating PodUnavailableBudget status in webhook",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"namespace", "pub"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(pubAdmissionCounter, pubConflictRetryCounter, pubGetDuration, pubUpdateDuration)
+}
+
+func recordPubAdmission(pub *policyv1alpha1.PodUnavailableBudget, operation Operation, allowed bool) {
+	result := admissionResultAllowed
+	if !allowed {
+		result = admissionResultDenied
+	}
+	pubAdmissionCounter.WithLabelValues(pub.Namespace, pub.Name, string(operation), result).Inc()
+}
+
+func recordPubWebhookCost(pub *policyv1alpha1.PodUnavailableBudget, conflictTimes int, costOfGet, costOfUpdate time.Duration) {
+	if conflictTimes > 0 {
+		pubConflictRetryCounter.WithLabelValues(pub.Namespace, pub.Name).Add(float64(conflictTimes))
+	}
+	pubGetDuration.WithLabelValues(pub.Namespace, pub.Name).Observe(costOfGet.Seconds())
+	if costOfUpdate > 0 {
+		pubUpdateDuration.WithLabelValues(pub.Namespace, pub.Name).Observe(costOfUpdate.Seconds())
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pubcontrol
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPubAdmissionMetrics(t *testing.T) {
+	pub := pubDemo.DeepCopy()
+	pub.Name = "pub-metrics"
+	pub.Status.UnavailableAllowed = 0
+	// metrics are global, so only the deltas are checked
+	allowedBefore := getCounterValue(t, pub.Namespace, pub.Name, UpdateOperation, admissionResultAllowed)
+	deniedBefore := getCounterValue(t, pub.Namespace, pub.Name, UpdateOperation, admissionResultDenied)
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pub).Build()
+	control := NewPubControl(fakeClient)
+
+	// not ready pod is allowed without consuming budget
+	notReady := podDemo.DeepCopy()
+	notReady.Status.Phase = corev1.PodPending
+	allowed, _, _ := PodUnavailableBudgetValidatePod(fakeClient, control, pub, notReady, UpdateOperation, false)
+	if !allowed {
+		t.Fatalf("expect not ready pod allowed")
+	}
+	// ready pod is denied because unavailableAllowed is 0
+	allowed, _, _ = PodUnavailableBudgetValidatePod(fakeClient, control, pub, podDemo.DeepCopy(), UpdateOperation, false)
+	if allowed {
+		t.Fatalf("expect ready pod denied")
+	}
+	// dry run is not recorded
+	allowed, _, _ = PodUnavailableBudgetValidatePod(fakeClient, control, pub, podDemo.DeepCopy(), UpdateOperation, true)
+	if allowed {
+		t.Fatalf("expect ready pod denied in dry run")
+	}
+
+	if value := getCounterValue(t, pub.Namespace, pub.Name, UpdateOperation, admissionResultAllowed) - allowedBefore; value != 1 {
+		t.Fatalf("expect allowed count 1, but get %v", value)
+	}
+	if value := getCounterValue(t, pub.Namespace, pub.Name, UpdateOperation, admissionResultDenied) - deniedBefore; value != 1 {
+		t.Fatalf("expect denied count 1, but get %v", value)
+	}
+}
+
+func TestPubWebhookCostMetrics(t *testing.T) {
+	pub := pubDemo.DeepCopy()
+	pub.Name = "pub-cost-metrics"
+	getBefore := getHistogramCount(t, pubGetDuration, pub.Namespace, pub.Name)
+	updateBefore := getHistogramCount(t, pubUpdateDuration, pub.Namespace, pub.Name)
+
+	recordPubWebhookCost(pub, 0, time.Millisecond, 0)
+	recordPubWebhookCost(pub, 1, time.Millisecond, time.Millisecond)
+	if count := getHistogramCount(t, pubGetDuration, pub.Namespace, pub.Name) - getBefore; count != 2 {
+		t.Fatalf("expect get duration observed 2 times, but get %d", count)
+	}
+	if count := getHistogramCount(t, pubUpdateDuration, pub.Namespace, pub.Name) - updateBefore; count != 1 {
+		t.Fatalf("expect update duration observed 1 time, but get %d", count)
+	}
+	// other pubs are not affected
+	if count := getHistogramCount(t, pubGetDuration, pub.Namespace, "other-pub"); count != 0 {
+		t.Fatalf("expect get duration of other pub not observed, but get %d", count)
+	}
+}
+
+func getCounterValue(t *testing.T, labels ...string) float64 {
+	metric := &dto.Metric{}
+	if err := pubAdmissionCounter.WithLabelValues(labels...).Write(metric); err != nil {
+		t.Fatalf("write metric failed: %s", err.Error())
+	}
+	return metric.GetCounter().GetValue()
+}
+
+func getHistogramCount(t *testing.T, histogram *prometheus.HistogramVec, labels ...string) uint64 {
+	metric := &dto.Metric{}
+	if err := histogram.WithLabelValues(labels...).(prometheus.Metric).Write(metric); err != nil {
+		t.Fatalf("write metric failed: %s", err.Error())
+	}
+	return metric.GetHistogram().GetSampleCount()
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pubcontrol
+
+import (
+	"fmt"
+	"time"
+
+	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// DisruptionBatch is a group of pods that can be disrupted at the same time.
+type DisruptionBatch struct {
+	// Pods is the names of the pods in this batch
+	Pods []string
+	// Offset is the earliest time, relative to the start of the plan, at which this batch should be disrupted
+	Offset time.Duration
+}
+
+// DisruptionPlan is the recommended schedule for disrupting a group of pods protected by one pub.
+type DisruptionPlan struct {
+	// MaxConcurrent is the maximum number of available pods that can be disrupted at the same time
+	MaxConcurrent int32
+	// Batches is the ordered batch schedule
+	Batches []DisruptionBatch
+}
+
+// PlanDisruptions computes, read-only, a batch schedule for disrupting the given pods without violating the pub.
+// 1. pods that are not ready or already recorded in pub don't consume the budget, they are all put in the first batch
+// 2. every batch contains at most min(UnavailableAllowed, MaxUnavailablePodSize - recorded pods) available pods
+// 3. consecutive batches are separated by at least minInterval
+func PlanDisruptions(control PubControl, pub *policyv1alpha1.PodUnavailableBudget, pods []*corev1.Pod, minInterval time.Duration) (*DisruptionPlan, error) {
+	plan := &DisruptionPlan{MaxConcurrent: GetDisruptionHeadroom(pub)}
+
+	var free, counted []string
+	for _, pod := range pods {
+		if !control.IsPodReady(pod, pub) || isPodRecordedInPub(pod.Name, pub) {
+			free = append(free, pod.Name)
+		} else {
+			counted = append(counted, pod.Name)
+		}
+	}
+	if len(counted) > 0 && plan.MaxConcurrent <= 0 {
+		return nil, fmt.Errorf("pub(%s/%s) doesn't allow any disruption, unavailableAllowed(%d) recorded pods(%d)",
+			pub.Namespace, pub.Name, pub.Status.UnavailableAllowed, len(pub.Status.DisruptedPods)+len(pub.Status.UnavailablePods))
+	}
+
+	var current []string
+	current = append(current, free...)
+	for len(counted) > 0 {
+		size := int(plan.MaxConcurrent)
+		if size > len(counted) {
+			size = len(counted)
+		}
+		current = append(current, counted[:size]...)
+		counted = counted[size:]
+		plan.Batches = append(plan.Batches, DisruptionBatch{Pods: current, Offset: time.Duration(len(plan.Batches)) * minInterval})
+		current = nil
+	}
+	if len(current) > 0 {
+		plan.Batches = append(plan.Batches, DisruptionBatch{Pods: current})
+	}
+	return plan, nil
+}
+
+// GetDisruptionHeadroom returns the number of available pods that can be disrupted at the same time right now.
+func GetDisruptionHeadroom(pub *policyv1alpha1.PodUnavailableBudget) int32 {
+	headroom := pub.Status.UnavailableAllowed
+	if left := int32(MaxUnavailablePodSize - len(pub.Status.DisruptedPods) - len(pub.Status.UnavailablePods)); left < headroom {
+		headroom = left
+	}
+	if headroom < 0 {
+		headroom = 0
+	}
+	return headroom
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pubcontrol
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPlanDisruptions(t *testing.T) {
+	cases := []struct {
+		name          string
+		getPub        func() *policyv1alpha1.PodUnavailableBudget
+		getPods       func() []*corev1.Pod
+		minInterval   time.Duration
+		expectErr     bool
+		expectMax     int32
+		expectBatches []int
+	}{
+		{
+			name: "unavailableAllowed 3, 10 ready pods",
+			getPub: func() *policyv1alpha1.PodUnavailableBudget {
+				pub := pubDemo.DeepCopy()
+				pub.Status.UnavailableAllowed = 3
+				return pub
+			},
+			getPods: func() []*corev1.Pod {
+				return newPlanPods(10, 0)
+			},
+			minInterval:   time.Minute,
+			expectMax:     3,
+			expectBatches: []int{3, 3, 3, 1},
+		},
+		{
+			name: "not ready pods don't consume budget",
+			getPub: func() *policyv1alpha1.PodUnavailableBudget {
+				pub := pubDemo.DeepCopy()
+				pub.Status.UnavailableAllowed = 2
+				return pub
+			},
+			getPods: func() []*corev1.Pod {
+				return newPlanPods(4, 3)
+			},
+			minInterval:   time.Minute,
+			expectMax:     2,
+			expectBatches: []int{5, 2},
+		},
+		{
+			name: "recorded pods don't consume budget",
+			getPub: func() *policyv1alpha1.PodUnavailableBudget {
+				pub := pubDemo.DeepCopy()
+				pub.Status.UnavailableAllowed = 2
+				pub.Status.DisruptedPods = map[string]metav1.Time{"pod-0": metav1.Now()}
+				return pub
+			},
+			getPods: func() []*corev1.Pod {
+				return newPlanPods(5, 0)
+			},
+			expectMax:     2,
+			expectBatches: []int{3, 2},
+		},
+		{
+			name: "limited by MaxUnavailablePodSize",
+			getPub: func() *policyv1alpha1.PodUnavailableBudget {
+				pub := pubDemo.DeepCopy()
+				pub.Status.UnavailableAllowed = 100
+				for i := 0; i < MaxUnavailablePodSize-2; i++ {
+					pub.Status.UnavailablePods[fmt.Sprintf("other-%d", i)] = metav1.Now()
+				}
+				return pub
+			},
+			getPods: func() []*corev1.Pod {
+				return newPlanPods(5, 0)
+			},
+			expectMax:     2,
+			expectBatches: []int{2, 2, 1},
+		},
+		{
+			name: "only not ready pods, no budget",
+			getPub: func() *policyv1alpha1.PodUnavailableBudget {
+				pub := pubDemo.DeepCopy()
+				pub.Status.UnavailableAllowed = 0
+				return pub
+			},
+			getPods: func() []*corev1.Pod {
+				return newPlanPods(0, 3)
+			},
+			expectMax:     0,
+			expectBatches: []int{3},
+		},
+		{
+			name: "no budget",
+			getPub: func() *policyv1alpha1.PodUnavailableBudget {
+				pub := pubDemo.DeepCopy()
+				pub.Status.UnavailableAllowed = 0
+				return pub
+			},
+			getPods: func() []*corev1.Pod {
+				return newPlanPods(3, 0)
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			control := NewPubControl(fake.NewClientBuilder().WithScheme(scheme).Build())
+			pub := cs.getPub()
+			plan, err := PlanDisruptions(control, pub, cs.getPods(), cs.minInterval)
+			if cs.expectErr {
+				if err == nil {
+					t.Fatalf("expect error, but get nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PlanDisruptions failed: %s", err.Error())
+			}
+			if plan.MaxConcurrent != cs.expectMax {
+				t.Fatalf("expect MaxConcurrent(%d), but get(%d)", cs.expectMax, plan.MaxConcurrent)
+			}
+			if len(plan.Batches) != len(cs.expectBatches) {
+				t.Fatalf("expect batches(%v), but get(%d)", cs.expectBatches, len(plan.Batches))
+			}
+			seen := map[string]bool{}
+			for i, batch := range plan.Batches {
+				if len(batch.Pods) != cs.expectBatches[i] {
+					t.Fatalf("expect batch[%d] size(%d), but get(%d)", i, cs.expectBatches[i], len(batch.Pods))
+				}
+				if batch.Offset != time.Duration(i)*cs.minInterval {
+					t.Fatalf("expect batch[%d] offset(%v), but get(%v)", i, time.Duration(i)*cs.minInterval, batch.Offset)
+				}
+				var counted int32
+				for _, name := range batch.Pods {
+					if seen[name] {
+						t.Fatalf("pod(%s) is planned twice", name)
+					}
+					seen[name] = true
+					if _, ok := pub.Status.DisruptedPods[name]; !ok && !isPlanPodNotReady(name) {
+						counted++
+					}
+				}
+				if counted > plan.MaxConcurrent {
+					t.Fatalf("batch[%d] disrupts %d available pods, more than %d", i, counted, plan.MaxConcurrent)
+				}
+			}
+		})
+	}
+}
+
+// newPlanPods returns ready pods named pod-x and not ready pods named notready-x
+func newPlanPods(ready, notReady int) []*corev1.Pod {
+	var pods []*corev1.Pod
+	for i := 0; i < ready; i++ {
+		pod := podDemo.DeepCopy()
+		pod.Name = fmt.Sprintf("pod-%d", i)
+		pods = append(pods, pod)
+	}
+	for i := 0; i < notReady; i++ {
+		pod := podDemo.DeepCopy()
+		pod.Name = fmt.Sprintf("notready-%d", i)
+		pod.Status.Phase = corev1.PodPending
+		pods = append(pods, pod)
+	}
+	return pods
+}
+
+func isPlanPodNotReady(name string) bool {
+	return strings.HasPrefix(name, "notready-")
+}
@@ -26,11 +26,14 @@ import (
 	"github.com/openkruise/kruise/pkg/util"
 	utilclient "github.com/openkruise/kruise/pkg/util/client"
 	"github.com/openkruise/kruise/pkg/util/controllerfinder"
+	"github.com/openkruise/kruise/pkg/util/fieldindex"
 	"github.com/openkruise/kruise/pkg/util/inplaceupdate"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
 	kubecontroller "k8s.io/kubernetes/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -40,10 +43,23 @@ type commonControl struct {
 	controllerFinder *controllerfinder.ControllerFinder
 }
 
-func (c *commonControl) IsPodReady(pod *corev1.Pod) bool {
+func (c *commonControl) IsPodReady(pod *corev1.Pod, pub *policyv1alpha1.PodUnavailableBudget) bool {
 	// 1. pod.Status.Phase == v1.PodRunning
 	// 2. pod.condition PodReady == true
-	return util.IsRunningAndReady(pod)
+	if !util.IsRunningAndReady(pod) {
+		return false
+	}
+	// 3. custom conditions, e.g. published by PodProbeMarker
+	if pub == nil {
+		return true
+	}
+	for _, conditionType := range pub.Spec.ReadinessConditions {
+		_, condition := podutil.GetPodCondition(&pod.Status, conditionType)
+		if condition == nil || condition.Status != corev1.ConditionTrue {
+			return false
+		}
+	}
+	return true
 }
 
 func (c *commonControl) IsPodUnavailableChanged(oldPod, newPod *corev1.Pod) bool {
@@ -66,7 +82,6 @@ func (c *commonControl) IsPodUnavailableChanged(oldPod, newPod *corev1.Pod) bool
 // 2. expectedCount, the default is workload.Replicas
 func (c *commonControl) GetPodsForPub(pub *policyv1alpha1.PodUnavailableBudget) ([]*corev1.Pod, int32, error) {
 	// if targetReference isn't nil, priority to take effect
-	var listOptions *client.ListOptions
 	if pub.Spec.TargetReference != nil {
 		ref := pub.Spec.TargetReference
 		matchedPods, expectedCount, err := c.controllerFinder.GetPodsForRef(ref.APIVersion, ref.Kind, ref.Name, pub.Namespace, true)
@@ -81,7 +96,9 @@ func (c *commonControl) GetPodsForPub(pub *policyv1alpha1.PodUnavailableBudget)
 		klog.Warningf("pub(%s/%s) GetFastLabelSelector failed: %s", pub.Namespace, pub.Name, err.Error())
 		return nil, 0, nil
 	}
-	listOptions = &client.ListOptions{Namespace: pub.Namespace, LabelSelector: labelSelector}
+	// the related-pub annotation is only stamped on pods created after the pub, so pods are listed by
+	// the selector which is the source of truth of the protected pods
+	listOptions := &client.ListOptions{Namespace: pub.Namespace, LabelSelector: labelSelector}
 	podList := &corev1.PodList{}
 	if err = c.List(context.TODO(), podList, listOptions, utilclient.DisableDeepCopy); err != nil {
 		return nil, 0, err
@@ -101,6 +118,25 @@ func (c *commonControl) GetPodsForPub(pub *policyv1alpha1.PodUnavailableBudget)
 	return matchedPods, expectedCount, nil
 }
 
+func (c *commonControl) GetPodsWithRelatedPub(namespace, pubName string) ([]*corev1.Pod, error) {
+	podList := &corev1.PodList{}
+	listOptions := &client.ListOptions{
+		Namespace:     namespace,
+		FieldSelector: fields.SelectorFromSet(fields.Set{fieldindex.IndexNameForRelatedPub: pubName}),
+	}
+	if err := c.List(context.TODO(), podList, listOptions, utilclient.DisableDeepCopy); err != nil {
+		return nil, err
+	}
+	pods := make([]*corev1.Pod, 0, len(podList.Items))
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if pod.Annotations[PodRelatedPubAnnotation] == pubName {
+			pods = append(pods, pod)
+		}
+	}
+	return pods, nil
+}
+
 func (c *commonControl) IsPodStateConsistent(pod *corev1.Pod) bool {
 	// if all container image is digest format
 	// by comparing status.containers[x].ImageID with spec.container[x].Image can determine whether pod is consistent
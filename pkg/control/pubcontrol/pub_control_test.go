@@ -0,0 +1,160 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pubcontrol
+
+import (
+	"fmt"
+	"testing"
+
+	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestIsPodReady(t *testing.T) {
+	customCondition := corev1.PodConditionType("game.kruise.io/healthy")
+	cases := []struct {
+		name        string
+		getPod      func() *corev1.Pod
+		getPub      func() *policyv1alpha1.PodUnavailableBudget
+		expectReady bool
+	}{
+		{
+			name: "pod ready, no readiness conditions",
+			getPod: func() *corev1.Pod {
+				return podDemo.DeepCopy()
+			},
+			getPub: func() *policyv1alpha1.PodUnavailableBudget {
+				return pubDemo.DeepCopy()
+			},
+			expectReady: true,
+		},
+		{
+			name: "pod not ready, no readiness conditions",
+			getPod: func() *corev1.Pod {
+				pod := podDemo.DeepCopy()
+				pod.Status.Conditions[0].Status = corev1.ConditionFalse
+				return pod
+			},
+			getPub: func() *policyv1alpha1.PodUnavailableBudget {
+				return pubDemo.DeepCopy()
+			},
+			expectReady: false,
+		},
+		{
+			name: "pod ready, custom condition not found",
+			getPod: func() *corev1.Pod {
+				return podDemo.DeepCopy()
+			},
+			getPub: func() *policyv1alpha1.PodUnavailableBudget {
+				pub := pubDemo.DeepCopy()
+				pub.Spec.ReadinessConditions = []corev1.PodConditionType{customCondition}
+				return pub
+			},
+			expectReady: false,
+		},
+		{
+			name: "pod ready, custom condition false",
+			getPod: func() *corev1.Pod {
+				pod := podDemo.DeepCopy()
+				pod.Status.Conditions = append(pod.Status.Conditions, corev1.PodCondition{Type: customCondition, Status: corev1.ConditionFalse})
+				return pod
+			},
+			getPub: func() *policyv1alpha1.PodUnavailableBudget {
+				pub := pubDemo.DeepCopy()
+				pub.Spec.ReadinessConditions = []corev1.PodConditionType{customCondition}
+				return pub
+			},
+			expectReady: false,
+		},
+		{
+			name: "pod ready, custom condition true",
+			getPod: func() *corev1.Pod {
+				pod := podDemo.DeepCopy()
+				pod.Status.Conditions = append(pod.Status.Conditions, corev1.PodCondition{Type: customCondition, Status: corev1.ConditionTrue})
+				return pod
+			},
+			getPub: func() *policyv1alpha1.PodUnavailableBudget {
+				pub := pubDemo.DeepCopy()
+				pub.Spec.ReadinessConditions = []corev1.PodConditionType{customCondition}
+				return pub
+			},
+			expectReady: true,
+		},
+		{
+			name: "pod not ready, custom condition true",
+			getPod: func() *corev1.Pod {
+				pod := podDemo.DeepCopy()
+				pod.Status.Conditions[0].Status = corev1.ConditionFalse
+				pod.Status.Conditions = append(pod.Status.Conditions, corev1.PodCondition{Type: customCondition, Status: corev1.ConditionTrue})
+				return pod
+			},
+			getPub: func() *policyv1alpha1.PodUnavailableBudget {
+				pub := pubDemo.DeepCopy()
+				pub.Spec.ReadinessConditions = []corev1.PodConditionType{customCondition}
+				return pub
+			},
+			expectReady: false,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			control := NewPubControl(fake.NewClientBuilder().WithScheme(scheme).Build())
+			if ready := control.IsPodReady(cs.getPod(), cs.getPub()); ready != cs.expectReady {
+				t.Fatalf("expect IsPodReady(%v), but get(%v)", cs.expectReady, ready)
+			}
+		})
+	}
+}
+
+func TestGetPodsForPubWithSelector(t *testing.T) {
+	pub := pubDemo.DeepCopy()
+	var objs []client.Object
+	// the pods created before pub, bare pods or pods matched by the edited selector have no related-pub annotation
+	for i, relatedPub := range []string{pub.Name, pub.Name, "other-pub", ""} {
+		pod := podDemo.DeepCopy()
+		pod.Name = fmt.Sprintf("%s-%d", pod.Name, i)
+		pod.OwnerReferences = nil
+		if relatedPub != "" {
+			pod.Annotations[PodRelatedPubAnnotation] = relatedPub
+		}
+		objs = append(objs, pod)
+	}
+	// related to pub, but not matched by selector
+	unmatched := podDemo.DeepCopy()
+	unmatched.Name = "unmatched-pod"
+	unmatched.OwnerReferences = nil
+	unmatched.Labels = map[string]string{"app": "nginx"}
+	unmatched.Annotations[PodRelatedPubAnnotation] = pub.Name
+	objs = append(objs, unmatched)
+
+	control := NewPubControl(fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build())
+	pods, _, err := control.GetPodsForPub(pub)
+	if err != nil {
+		t.Fatalf("GetPodsForPub failed: %s", err.Error())
+	}
+	names := sets.NewString()
+	for _, pod := range pods {
+		names.Insert(pod.Name)
+	}
+	if expect := sets.NewString("test-pod-0", "test-pod-1", "test-pod-2", "test-pod-3"); !names.Equal(expect) {
+		t.Fatalf("expect pods(%v), but get(%v)", expect.List(), names.List())
+	}
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pubcontrol
+
+import (
+	"context"
+	"flag"
+	"sync"
+	"time"
+
+	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
+	"github.com/openkruise/kruise/pkg/util"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func init() {
+	flag.DurationVar(&rejectionFlushInterval, "pub-rejection-flush-interval", rejectionFlushInterval,
+		"Interval of flushing the rejection statistics to PUB status, which limits the rate of status updates. Defaults 10s")
+}
+
+var (
+	rejectionFlushInterval = 10 * time.Second
+	// rejectionResetWindow is the quiet window after which pub.status.rejectedCount is reset
+	rejectionResetWindow = 10 * time.Minute
+
+	globalRejections     *rejectionRecorder
+	globalRejectionsOnce sync.Once
+)
+
+// rejectionRecorder counts the operations rejected by pubs locally,
+// and flushes them to pub status periodically.
+type rejectionRecorder struct {
+	sync.Mutex
+	client client.Client
+	// key is pub namespace/name
+	rejections map[types.NamespacedName]*pubRejection
+}
+
+type pubRejection struct {
+	uid          types.UID
+	count        int32
+	lastRejected time.Time
+}
+
+func getRejectionRecorder(c client.Client) *rejectionRecorder {
+	globalRejectionsOnce.Do(func() {
+		globalRejections = newRejectionRecorder(c)
+		// the first flush is after an interval rather than immediately, so that rejections are batched
+		go func() {
+			ticker := time.NewTicker(rejectionFlushInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				globalRejections.flushAll()
+			}
+		}()
+	})
+	return globalRejections
+}
+
+func newRejectionRecorder(c client.Client) *rejectionRecorder {
+	return &rejectionRecorder{
+		client:     c,
+		rejections: map[types.NamespacedName]*pubRejection{},
+	}
+}
+
+func (r *rejectionRecorder) record(pub *policyv1alpha1.PodUnavailableBudget) {
+	r.Lock()
+	defer r.Unlock()
+
+	key := types.NamespacedName{Namespace: pub.Namespace, Name: pub.Name}
+	rejection := r.rejections[key]
+	if rejection == nil || rejection.uid != pub.UID {
+		rejection = &pubRejection{uid: pub.UID}
+		r.rejections[key] = rejection
+	}
+	rejection.count++
+	rejection.lastRejected = time.Now()
+}
+
+func (r *rejectionRecorder) flushAll() {
+	r.Lock()
+	rejections := r.rejections
+	r.rejections = map[types.NamespacedName]*pubRejection{}
+	r.Unlock()
+
+	for key, rejection := range rejections {
+		if err := r.flush(key, rejection); err != nil {
+			klog.Errorf("Flush rejections of PodUnavailableBudget(%s) failed: %s, will retry in next flush", key.String(), err.Error())
+			r.restore(key, rejection)
+		}
+	}
+}
+
+// restore puts back the rejection failed to flush, and merges it with the rejections recorded during the flush.
+func (r *rejectionRecorder) restore(key types.NamespacedName, rejection *pubRejection) {
+	r.Lock()
+	defer r.Unlock()
+
+	current := r.rejections[key]
+	if current == nil {
+		r.rejections[key] = rejection
+		return
+	}
+	// the pub has been recreated, the rejections of the old one are discarded
+	if current.uid != rejection.uid {
+		return
+	}
+	current.count += rejection.count
+	if current.lastRejected.Before(rejection.lastRejected) {
+		current.lastRejected = rejection.lastRejected
+	}
+}
+
+// flush adds the local rejections to pub status, the count recorded by others is kept
+// unless no operation has been rejected in the last rejectionResetWindow.
+func (r *rejectionRecorder) flush(key types.NamespacedName, rejection *pubRejection) error {
+	var flushed *policyv1alpha1.PodUnavailableBudget
+	err := retry.RetryOnConflict(ConflictRetry, func() error {
+		pub := &policyv1alpha1.PodUnavailableBudget{}
+		if err := r.client.Get(context.TODO(), key, pub); err != nil {
+			return err
+		}
+		if pub.UID != rejection.uid {
+			return nil
+		}
+		lastRejected := pub.Status.LastRejectedTime
+		if lastRejected == nil || rejection.lastRejected.Sub(lastRejected.Time) > rejectionResetWindow {
+			pub.Status.RejectedCount = 0
+		}
+		pub.Status.RejectedCount += rejection.count
+		if lastRejected == nil || lastRejected.Time.Before(rejection.lastRejected) {
+			pub.Status.LastRejectedTime = &metav1.Time{Time: rejection.lastRejected}
+		}
+		if err := r.client.Status().Update(context.TODO(), pub); err != nil {
+			return err
+		}
+		flushed = pub
+		return nil
+	})
+	if err != nil || flushed == nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if err = util.GlobalCache.Add(flushed); err != nil {
+		klog.Errorf("Add cache failed for PodUnavailableBudget(%s/%s): %s", flushed.Namespace, flushed.Name, err.Error())
+	}
+	klog.V(3).Infof("flush pub(%s/%s) rejections(%d), rejectedCount(%d)", flushed.Namespace, flushed.Name, rejection.count, flushed.Status.RejectedCount)
+	return nil
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pubcontrol
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
+	"github.com/openkruise/kruise/pkg/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRejectionRecorder(t *testing.T) {
+	cases := []struct {
+		name             string
+		lastRejectedTime *metav1.Time
+		rejectedCount    int32
+		expectCount      int32
+	}{
+		{
+			name:        "first rejections",
+			expectCount: 3,
+		},
+		{
+			name:             "rejections within reset window",
+			lastRejectedTime: &metav1.Time{Time: time.Now().Add(-time.Minute)},
+			rejectedCount:    5,
+			expectCount:      8,
+		},
+		{
+			name:             "rejections after reset window",
+			lastRejectedTime: &metav1.Time{Time: time.Now().Add(-time.Hour)},
+			rejectedCount:    5,
+			expectCount:      3,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			pub := pubDemo.DeepCopy()
+			pub.UID = "pub-rejection-uid"
+			pub.Status.LastRejectedTime = cs.lastRejectedTime
+			pub.Status.RejectedCount = cs.rejectedCount
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pub).Build()
+			recorder := newRejectionRecorder(fakeClient)
+			defer func() {
+				_ = util.GlobalCache.Delete(pub)
+			}()
+
+			for i := 0; i < 3; i++ {
+				recorder.record(pub)
+			}
+			recorder.flushAll()
+			if len(recorder.rejections) != 0 {
+				t.Fatalf("expect rejections flushed, but get %d", len(recorder.rejections))
+			}
+
+			newPub := &policyv1alpha1.PodUnavailableBudget{}
+			if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: pub.Namespace, Name: pub.Name}, newPub); err != nil {
+				t.Fatalf("get pub failed: %s", err.Error())
+			}
+			if newPub.Status.RejectedCount != cs.expectCount {
+				t.Fatalf("expect rejectedCount(%d), but get(%d)", cs.expectCount, newPub.Status.RejectedCount)
+			}
+			if newPub.Status.LastRejectedTime == nil || time.Since(newPub.Status.LastRejectedTime.Time) > time.Minute {
+				t.Fatalf("expect lastRejectedTime updated, but get(%v)", newPub.Status.LastRejectedTime)
+			}
+		})
+	}
+}
+
+type failedStatusClient struct {
+	client.Client
+}
+
+func (c *failedStatusClient) Status() client.StatusWriter {
+	return &failedStatusWriter{c.Client.Status()}
+}
+
+type failedStatusWriter struct {
+	client.StatusWriter
+}
+
+func (w *failedStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	return fmt.Errorf("injected error")
+}
+
+func TestRejectionRecorderFlushFailed(t *testing.T) {
+	pub := pubDemo.DeepCopy()
+	pub.UID = "pub-rejection-uid"
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pub).Build()
+	recorder := newRejectionRecorder(&failedStatusClient{fakeClient})
+
+	recorder.record(pub)
+	recorder.record(pub)
+	recorder.flushAll()
+	key := types.NamespacedName{Namespace: pub.Namespace, Name: pub.Name}
+	if rejection := recorder.rejections[key]; rejection == nil || rejection.count != 2 {
+		t.Fatalf("expect rejections kept after flush failed, but get %v", rejection)
+	}
+
+	// the rejections kept are merged with the new ones, and flushed in next time
+	recorder.record(pub)
+	recorder.client = fakeClient
+	recorder.flushAll()
+	if len(recorder.rejections) != 0 {
+		t.Fatalf("expect rejections flushed, but get %d", len(recorder.rejections))
+	}
+	defer func() {
+		_ = util.GlobalCache.Delete(pub)
+	}()
+	newPub := &policyv1alpha1.PodUnavailableBudget{}
+	if err := fakeClient.Get(context.TODO(), key, newPub); err != nil {
+		t.Fatalf("get pub failed: %s", err.Error())
+	}
+	if newPub.Status.RejectedCount != 3 {
+		t.Fatalf("expect rejectedCount(3), but get(%d)", newPub.Status.RejectedCount)
+	}
+}
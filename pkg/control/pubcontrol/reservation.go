@@ -0,0 +1,186 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pubcontrol
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sync"
+	"time"
+
+	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
+	kubeClient "github.com/openkruise/kruise/pkg/client"
+	"github.com/openkruise/kruise/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func init() {
+	flag.DurationVar(&reservationFlushInterval, "pub-reservation-flush-interval", reservationFlushInterval,
+		"Interval of batching the operations admitted by PUB reservation ledger before persisting them to PUB status.")
+}
+
+var (
+	reservationFlushInterval = 100 * time.Millisecond
+
+	globalLedger     *reservationLedger
+	globalLedgerOnce sync.Once
+)
+
+// reservationLedger batches the concurrent operations of one pub in this webhook replica,
+// and persists them to pub status with a single update before any of them is admitted.
+// The reservations are recorded in pub.status.disruptedPods and pub.status.unavailablePods, and the update is
+// guarded by resourceVersion, so all the webhook replicas share them and never admit more than unavailableAllowed.
+type reservationLedger struct {
+	sync.Mutex
+	client client.Client
+	// getLivePub gets pub from apiserver rather than cache, which is used after conflict
+	getLivePub func(namespace, name string) (*policyv1alpha1.PodUnavailableBudget, error)
+	// pending batches, key is pub namespace/name
+	batches map[types.NamespacedName]*reservationBatch
+}
+
+type reservationBatch struct {
+	pub      *policyv1alpha1.PodUnavailableBudget
+	requests []*reservationRequest
+}
+
+type reservationRequest struct {
+	podName   string
+	operation Operation
+	// done receives the result once the batch has been persisted, nil means admitted
+	done chan error
+}
+
+func getReservationLedger(c client.Client) *reservationLedger {
+	globalLedgerOnce.Do(func() {
+		globalLedger = newReservationLedger(c)
+	})
+	return globalLedger
+}
+
+func newReservationLedger(c client.Client) *reservationLedger {
+	return &reservationLedger{
+		client:     c,
+		getLivePub: getLivePub,
+		batches:    map[types.NamespacedName]*reservationBatch{},
+	}
+}
+
+func getLivePub(namespace, name string) (*policyv1alpha1.PodUnavailableBudget, error) {
+	return kubeClient.GetGenericClient().KruiseClient.PolicyV1alpha1().PodUnavailableBudgets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+// admit adds the operation of pod to the pending batch of pub, and waits until the batch is persisted to pub status.
+// It returns a Forbidden error if the budget is exhausted.
+func (l *reservationLedger) admit(ctx context.Context, pub *policyv1alpha1.PodUnavailableBudget, pod *corev1.Pod, operation Operation, dryRun bool) error {
+	if dryRun {
+		klog.V(5).Infof("pod(%s) operation for pub(%s/%s) is a dry run", pod.Name, pub.Namespace, pub.Name)
+		return checkAndDecrement(pod.Name, getNewerCachedPub(l.client, pub), operation)
+	}
+
+	request := &reservationRequest{podName: pod.Name, operation: operation, done: make(chan error, 1)}
+	key := types.NamespacedName{Namespace: pub.Namespace, Name: pub.Name}
+	l.Lock()
+	batch := l.batches[key]
+	if batch == nil || batch.pub.UID != pub.UID {
+		batch = &reservationBatch{pub: pub}
+		l.batches[key] = batch
+		time.AfterFunc(reservationFlushInterval, func() { l.flush(key, batch) })
+	}
+	batch.requests = append(batch.requests, request)
+	l.Unlock()
+
+	select {
+	case err := <-request.done:
+		return err
+	case <-ctx.Done():
+		return errors.NewTimeoutError(fmt.Sprintf("couldn't persist the reservation in PodUnavailableBudget %s: %s", pub.Name, ctx.Err()), 0)
+	}
+}
+
+// flush records the pods of batch in pub status and decreases unavailableAllowed with one update,
+// then replies the result to each request. The pods beyond unavailableAllowed are rejected.
+func (l *reservationLedger) flush(key types.NamespacedName, batch *reservationBatch) {
+	l.Lock()
+	if l.batches[key] == batch {
+		delete(l.batches, key)
+	}
+	l.Unlock()
+
+	results := make([]error, len(batch.requests))
+	var persisted *policyv1alpha1.PodUnavailableBudget
+	refresh := false
+	err := retry.RetryOnConflict(ConflictRetry, func() error {
+		persisted = nil
+		var pub *policyv1alpha1.PodUnavailableBudget
+		if refresh {
+			// the caches are not guaranteed to be newer after conflict, so get the latest pub from apiserver
+			var err error
+			if pub, err = l.getLivePub(key.Namespace, key.Name); err != nil {
+				klog.Errorf("Get PodUnavailableBudget(%s) failed form etcd: %s", key.String(), err.Error())
+				return err
+			}
+		} else {
+			pub = getNewerCachedPub(l.client, batch.pub)
+		}
+		if pub.UID != batch.pub.UID {
+			return errors.NewNotFound(policyv1alpha1.Resource("podunavailablebudget"), key.Name)
+		}
+		var count int
+		for i, request := range batch.requests {
+			results[i] = nil
+			if isPodRecordedInPub(request.podName, pub) {
+				continue
+			}
+			if results[i] = checkAndDecrement(request.podName, pub, request.operation); results[i] == nil {
+				count++
+			}
+		}
+		if count == 0 {
+			return nil
+		}
+		if err := l.client.Status().Update(context.TODO(), pub); err != nil {
+			// if conflict, then retry with the latest pub
+			refresh = true
+			return err
+		}
+		persisted = pub
+		return nil
+	})
+	if err != nil {
+		klog.Errorf("Persist reservations(%d) in PodUnavailableBudget(%s) failed: %s", len(batch.requests), key.String(), err.Error())
+		for i := range results {
+			results[i] = err
+		}
+	} else if persisted != nil {
+		if err = util.GlobalCache.Add(persisted); err != nil {
+			klog.Errorf("Add cache failed for PodUnavailableBudget(%s/%s): %s", persisted.Namespace, persisted.Name, err.Error())
+		}
+		klog.V(3).Infof("persist pub(%s/%s) reservations(%d), unavailableAllowed(%d)",
+			persisted.Namespace, persisted.Name, len(batch.requests), persisted.Status.UnavailableAllowed)
+	}
+	for i, request := range batch.requests {
+		request.done <- results[i]
+	}
+}
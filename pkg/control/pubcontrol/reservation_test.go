@@ -0,0 +1,243 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pubcontrol
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
+	"github.com/openkruise/kruise/pkg/util"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type countingStatusClient struct {
+	client.Client
+	sync.Mutex
+	updates int
+}
+
+func (c *countingStatusClient) Status() client.StatusWriter {
+	return &countingStatusWriter{StatusWriter: c.Client.Status(), c: c}
+}
+
+type countingStatusWriter struct {
+	client.StatusWriter
+	c *countingStatusClient
+}
+
+func (w *countingStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	w.c.Lock()
+	w.c.updates++
+	w.c.Unlock()
+	return w.StatusWriter.Update(ctx, obj, opts...)
+}
+
+func TestReservationLedger(t *testing.T) {
+	pub := pubDemo.DeepCopy()
+	pub.UID = "pub-ledger-uid"
+	pub.Status.UnavailableAllowed = 3
+	fakeClient := &countingStatusClient{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(pub).Build()}
+	ledger := newReservationLedger(fakeClient)
+	defer func(interval time.Duration) {
+		reservationFlushInterval = interval
+		_ = util.GlobalCache.Delete(pub)
+	}(reservationFlushInterval)
+	reservationFlushInterval = 500 * time.Millisecond
+
+	// 5 concurrent operations are persisted with one update, and only 3 of them are admitted
+	results := make([]error, 5)
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			pod := podDemo.DeepCopy()
+			pod.Name = fmt.Sprintf("test-pod-%d", i)
+			results[i] = ledger.admit(context.TODO(), pub, pod, UpdateOperation, false)
+		}(i)
+	}
+	wg.Wait()
+	var admitted []string
+	for i, err := range results {
+		if err == nil {
+			admitted = append(admitted, fmt.Sprintf("test-pod-%d", i))
+		} else if !errors.IsForbidden(err) {
+			t.Fatalf("expect forbidden error, but get %s", err.Error())
+		}
+	}
+	if len(admitted) != 3 {
+		t.Fatalf("expect 3 pods admitted, but get %v", admitted)
+	}
+	if fakeClient.updates != 1 {
+		t.Fatalf("expect 1 status update, but get %d", fakeClient.updates)
+	}
+	key := types.NamespacedName{Namespace: pub.Namespace, Name: pub.Name}
+	newPub := &policyv1alpha1.PodUnavailableBudget{}
+	if err := fakeClient.Get(context.TODO(), key, newPub); err != nil {
+		t.Fatalf("get pub failed: %s", err.Error())
+	}
+	if newPub.Status.UnavailableAllowed != 0 {
+		t.Fatalf("expect unavailableAllowed 0, but get %d", newPub.Status.UnavailableAllowed)
+	}
+	for _, name := range admitted {
+		if _, ok := newPub.Status.UnavailablePods[name]; !ok {
+			t.Fatalf("expect pod(%s) recorded in UnavailablePods", name)
+		}
+	}
+	if len(ledger.batches) != 0 {
+		t.Fatalf("expect no pending batch, but get %d", len(ledger.batches))
+	}
+
+	// the reserved pod is admitted again without decrement
+	pod := podDemo.DeepCopy()
+	pod.Name = admitted[0]
+	if err := ledger.admit(context.TODO(), pub, pod, UpdateOperation, false); err != nil {
+		t.Fatalf("expect reserved pod admitted again, but get %s", err.Error())
+	}
+	// the request gives up when the context is done before the batch is persisted
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+	pod.Name = "test-pod-10"
+	if err := ledger.admit(ctx, pub, pod, DeleteOperation, false); !errors.IsTimeout(err) {
+		t.Fatalf("expect timeout error, but get %v", err)
+	}
+	// the batch of the timed out request is still flushed later
+	ledger.Lock()
+	batch := ledger.batches[key]
+	ledger.Unlock()
+	if err := <-batch.requests[0].done; !errors.IsForbidden(err) {
+		t.Fatalf("expect forbidden error, but get %v", err)
+	}
+}
+
+func TestReservationLedgerReplicas(t *testing.T) {
+	pub := pubDemo.DeepCopy()
+	pub.UID = "pub-ledger-uid"
+	pub.Status.UnavailableAllowed = 3
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pub).Build()
+	defer func(interval time.Duration, backoffDuration time.Duration) {
+		reservationFlushInterval = interval
+		ConflictRetry.Duration = backoffDuration
+		_ = util.GlobalCache.Delete(pub)
+	}(reservationFlushInterval, ConflictRetry.Duration)
+	reservationFlushInterval = 10 * time.Millisecond
+	ConflictRetry.Duration = time.Millisecond
+
+	// each webhook replica has its own ledger, and they share the reservations persisted in pub status
+	ledgers := []*reservationLedger{newReservationLedger(fakeClient), newReservationLedger(fakeClient)}
+	for _, ledger := range ledgers {
+		ledger.getLivePub = func(namespace, name string) (*policyv1alpha1.PodUnavailableBudget, error) {
+			livePub := &policyv1alpha1.PodUnavailableBudget{}
+			return livePub, fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: name}, livePub)
+		}
+	}
+	results := make([]error, 4)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			pod := podDemo.DeepCopy()
+			pod.Name = fmt.Sprintf("test-pod-%d", i)
+			results[i] = ledgers[i%2].admit(context.TODO(), pub, pod, DeleteOperation, false)
+		}(i)
+	}
+	wg.Wait()
+	var admitted int
+	for _, err := range results {
+		if err == nil {
+			admitted++
+		} else if !errors.IsForbidden(err) {
+			t.Fatalf("expect forbidden error, but get %s", err.Error())
+		}
+	}
+	newPub := &policyv1alpha1.PodUnavailableBudget{}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: pub.Namespace, Name: pub.Name}, newPub); err != nil {
+		t.Fatalf("get pub failed: %s", err.Error())
+	}
+	if admitted != 3 || newPub.Status.UnavailableAllowed != 0 || len(newPub.Status.DisruptedPods) != 3 {
+		t.Fatalf("expect 3 pods admitted, but get admitted(%d) unavailableAllowed(%d) disruptedPods(%d)",
+			admitted, newPub.Status.UnavailableAllowed, len(newPub.Status.DisruptedPods))
+	}
+}
+
+// staleCacheClient serves Get from the stale pub, as the informer cache which has not observed the latest pub.
+type staleCacheClient struct {
+	client.Client
+	stale *policyv1alpha1.PodUnavailableBudget
+}
+
+func (c *staleCacheClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	c.stale.DeepCopyInto(obj.(*policyv1alpha1.PodUnavailableBudget))
+	return nil
+}
+
+func TestReservationLedgerRefreshAfterConflict(t *testing.T) {
+	pub := pubDemo.DeepCopy()
+	pub.UID = "pub-ledger-uid"
+	pub.Status.UnavailableAllowed = 3
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pub).Build()
+	key := types.NamespacedName{Namespace: pub.Namespace, Name: pub.Name}
+	stale := &policyv1alpha1.PodUnavailableBudget{}
+	if err := fakeClient.Get(context.TODO(), key, stale); err != nil {
+		t.Fatalf("get pub failed: %s", err.Error())
+	}
+	// the pub is updated by another replica, which has not been observed by caches
+	latest := stale.DeepCopy()
+	latest.Status.UnavailableAllowed = 2
+	if err := fakeClient.Status().Update(context.TODO(), latest); err != nil {
+		t.Fatalf("update pub failed: %s", err.Error())
+	}
+
+	ledger := newReservationLedger(&staleCacheClient{Client: fakeClient, stale: stale})
+	var gets int
+	ledger.getLivePub = func(namespace, name string) (*policyv1alpha1.PodUnavailableBudget, error) {
+		gets++
+		livePub := &policyv1alpha1.PodUnavailableBudget{}
+		return livePub, fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: name}, livePub)
+	}
+	defer func(interval time.Duration, backoffDuration time.Duration) {
+		reservationFlushInterval = interval
+		ConflictRetry.Duration = backoffDuration
+		_ = util.GlobalCache.Delete(pub)
+	}(reservationFlushInterval, ConflictRetry.Duration)
+	reservationFlushInterval = 10 * time.Millisecond
+	ConflictRetry.Duration = time.Millisecond
+	_ = util.GlobalCache.Delete(pub)
+
+	pod := podDemo.DeepCopy()
+	if err := ledger.admit(context.TODO(), pub, pod, DeleteOperation, false); err != nil {
+		t.Fatalf("expect pod admitted after conflict, but get %s", err.Error())
+	}
+	if gets != 1 {
+		t.Fatalf("expect pub got from apiserver once, but get %d", gets)
+	}
+	newPub := &policyv1alpha1.PodUnavailableBudget{}
+	if err := fakeClient.Get(context.TODO(), key, newPub); err != nil {
+		t.Fatalf("get pub failed: %s", err.Error())
+	}
+	if _, ok := newPub.Status.DisruptedPods[pod.Name]; !ok || newPub.Status.UnavailableAllowed != 1 {
+		t.Fatalf("expect pod recorded and unavailableAllowed 1, but get %v %d", newPub.Status.DisruptedPods, newPub.Status.UnavailableAllowed)
+	}
+}
@@ -18,18 +18,27 @@ package pubcontrol
 
 import (
 	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/appscode/jsonpatch"
 	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
 	kubeClient "github.com/openkruise/kruise/pkg/client"
+	"github.com/openkruise/kruise/pkg/features"
 	"github.com/openkruise/kruise/pkg/util"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
+	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -40,6 +49,17 @@ const (
 	MaxUnavailablePodSize = 2000
 )
 
+func init() {
+	flag.IntVar(&ConflictRetry.Steps, "pub-conflict-retry-steps", ConflictRetry.Steps,
+		"The max times of retrying on conflict when PUB webhook updates PodUnavailableBudget status, should be at least 1. Defaults 4")
+	flag.DurationVar(&ConflictRetry.Duration, "pub-conflict-retry-duration", ConflictRetry.Duration,
+		"The initial interval of retrying on conflict when PUB webhook updates PodUnavailableBudget status. Defaults 500ms")
+	flag.Float64Var(&ConflictRetry.Factor, "pub-conflict-retry-factor", ConflictRetry.Factor,
+		"The factor multiplied to the retry interval after each conflict in PUB webhook. Defaults 1.0")
+}
+
+// ConflictRetry is the backoff of retrying on conflict when PUB webhook updates PodUnavailableBudget status,
+// it can be tuned by the pub-conflict-retry-* flags.
 var ConflictRetry = wait.Backoff{
 	Steps:    4,
 	Duration: 500 * time.Millisecond,
@@ -47,25 +67,160 @@ var ConflictRetry = wait.Backoff{
 	Jitter:   0.1,
 }
 
+// Clock is used to check the latency budget of validation, it can be replaced in tests.
+var Clock clock.Clock = clock.RealClock{}
+
+// ValidateConflictRetry checks the ConflictRetry backoff tuned by the pub-conflict-retry-* flags,
+// it should be called once the flags are parsed.
+func ValidateConflictRetry() error {
+	if ConflictRetry.Steps < 1 {
+		return fmt.Errorf("pub-conflict-retry-steps must be at least 1, got %d", ConflictRetry.Steps)
+	}
+	if ConflictRetry.Duration <= 0 {
+		return fmt.Errorf("pub-conflict-retry-duration must be positive, got %v", ConflictRetry.Duration)
+	}
+	if ConflictRetry.Factor < 1 {
+		return fmt.Errorf("pub-conflict-retry-factor must be at least 1.0, got %v", ConflictRetry.Factor)
+	}
+	return nil
+}
+
+// The keys of audit annotations attached to the AdmissionResponse when PUB denies an operation,
+// apiserver will prefix them with the webhook name.
+const (
+	AuditAnnotationPubName            = "pub-name"
+	AuditAnnotationCurrentAvailable   = "pub-current-available"
+	AuditAnnotationDesiredAvailable   = "pub-desired-available"
+	AuditAnnotationUnavailableAllowed = "pub-unavailable-allowed"
+)
+
+// PubRejectedEventReason is the reason of events emitted when an operation is rejected by PodUnavailableBudget
+const PubRejectedEventReason = "PodUnavailableBudgetRejected"
+
 type Operation string
 
 const (
 	UpdateOperation = "UPDATE"
-	//DeleteOperation = "DELETE"
+	DeleteOperation = "DELETE"
+	// EvictOperation is the create operation of pods/eviction subresource
+	EvictOperation = "EVICT"
+	// RecreateOperation is the recreation of containers in the pod by ContainerRecreateRequest
+	RecreateOperation = "RECREATE"
+	// ResizeOperation is the update operation of pods/resize subresource, which resizes container resources in-place
+	ResizeOperation = "RESIZE"
 
-	// Marked pods will not be pub-protected, solving the scenario of force pod deletion
+	// Marked pods will not be pub-protected, solving the scenario of force pod deletion.
+	// The value "true" exempts all operations, and a comma-separated list of "delete", "update", "evict"
+	// only exempts the specified operations, e.g. "delete,evict".
 	PodPubNoProtectionAnnotation = "pub.kruise.io/no-protect"
 
 	// related-pub annotation in pod
 	PodRelatedPubAnnotation = "kruise.io/related-pub"
+
+	// PodPubBudgetLeaseAnnotation is stamped in pod by workload controller(e.g. CloneSet) once the in-place update
+	// to a revision has been budgeted by pub, the value is "{pub uid}/{pod-template-hash of the revision}".
+	// PUB webhook won't count the update again, so that the workload and pub share a single budget.
+	PodPubBudgetLeaseAnnotation = "pub.kruise.io/budget-lease"
+
+	// NamespaceDefaultMinAvailableAnnotation and NamespaceDefaultMaxUnavailableAnnotation in namespace define the default budget
+	// for the workloads without explicit pub, MaxUnavailable is priority to take effect.
+	NamespaceDefaultMinAvailableAnnotation   = "pub.kruise.io/default-min-available"
+	NamespaceDefaultMaxUnavailableAnnotation = "pub.kruise.io/default-max-unavailable"
+	// PubDefaultPolicyLabel marks the pubs created by the namespace default budget policy
+	PubDefaultPolicyLabel = "pub.kruise.io/default-policy"
+
+	// PubReplicasFromHPAAnnotation in pub, if "true", resolves percentage minAvailable/maxUnavailable against
+	// status.desiredReplicas of the HorizontalPodAutoscalers targeting the protected workloads instead of workload replicas,
+	// so that the budget doesn't lag behind scaling.
+	PubReplicasFromHPAAnnotation = "pub.kruise.io/replicas-from-hpa"
 )
 
+// GetPubBudgetLease returns the budget lease of pub for the update to revision
+func GetPubBudgetLease(pub *policyv1alpha1.PodUnavailableBudget, revisionHash string) string {
+	return fmt.Sprintf("%s/%s", pub.UID, revisionHash)
+}
+
+// IsPodUpdateBudgeted returns whether the update of pod to a new revision has been budgeted by workload controller
+// according to annotations[pub.kruise.io/budget-lease].
+func IsPodUpdateBudgeted(oldPod, newPod *corev1.Pod, pub *policyv1alpha1.PodUnavailableBudget) bool {
+	if oldPod == nil || newPod == nil {
+		return false
+	}
+	newHash := newPod.Labels[apps.DefaultDeploymentUniqueLabelKey]
+	if newHash == "" || newHash == oldPod.Labels[apps.DefaultDeploymentUniqueLabelKey] {
+		return false
+	}
+	return newPod.Annotations[PodPubBudgetLeaseAnnotation] == GetPubBudgetLease(pub, newHash)
+}
+
+// IsPodResizeBudgeted returns whether the resize of pod is a part of the in-place update to a new revision,
+// which has been budgeted by workload controller according to annotations[pub.kruise.io/budget-lease].
+// The resize is requested before the revision of pod updated, so the lease is for a revision other than the current one.
+func IsPodResizeBudgeted(pod *corev1.Pod, pub *policyv1alpha1.PodUnavailableBudget) bool {
+	if pod == nil {
+		return false
+	}
+	prefix := fmt.Sprintf("%s/", pub.UID)
+	lease := pod.Annotations[PodPubBudgetLeaseAnnotation]
+	if !strings.HasPrefix(lease, prefix) {
+		return false
+	}
+	leaseHash := strings.TrimPrefix(lease, prefix)
+	return leaseHash != "" && leaseHash != pod.Labels[apps.DefaultDeploymentUniqueLabelKey]
+}
+
+// IsPodNoProtected returns whether the operation for pod is exempted from pub protection
+// by annotations[pub.kruise.io/no-protect].
+func IsPodNoProtected(pod *corev1.Pod, operation Operation) bool {
+	value, ok := pod.Annotations[PodPubNoProtectionAnnotation]
+	if !ok {
+		return false
+	}
+	if value == "true" {
+		return true
+	}
+	for _, scope := range strings.Split(value, ",") {
+		if strings.EqualFold(strings.TrimSpace(scope), string(operation)) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPodDisruptionAllowed returns whether the operation for pod would be allowed by its pub right now,
+// it is a dry run and never mutates pub status.
+func IsPodDisruptionAllowed(client client.Client, control PubControl, pod *corev1.Pod, operation Operation) (allowed bool, reason string, err error) {
+	// the same as pod webhook, the operation is allowed for pod that is not running
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed ||
+		pod.Status.Phase == corev1.PodPending || pod.Status.Phase == "" || !pod.DeletionTimestamp.IsZero() {
+		return true, "", nil
+	}
+	pub, err := control.GetPubForPod(pod)
+	if err != nil || pub == nil {
+		return true, "", err
+	}
+	if IsPodNoProtected(pod, operation) {
+		return true, "", nil
+	}
+	return PodUnavailableBudgetValidatePod(client, control, pub, pod, operation, true)
+}
+
 // parameters:
 // 1. allowed(bool) indicates whether to allow this update operation
 // 2. err(error)
 func PodUnavailableBudgetValidatePod(client client.Client, control PubControl, pub *policyv1alpha1.PodUnavailableBudget, pod *corev1.Pod, operation Operation, dryRun bool) (allowed bool, reason string, err error) {
+	defer func() {
+		// dry run, e.g. the disruption query, is not a real admission
+		if dryRun {
+			return
+		}
+		recordPubAdmission(pub, operation, allowed)
+		if !allowed && err == nil {
+			getRejectionRecorder(client).record(pub)
+		}
+	}()
 	// If the pod is not ready, it doesn't count towards healthy and we should not decrement
-	if !control.IsPodReady(pod) {
+	if !control.IsPodReady(pod, pub) {
 		klog.V(3).Infof("pod(%s/%s) is not ready, then don't need check pub", pod.Namespace, pod.Name)
 		return true, "", nil
 	}
@@ -75,6 +230,24 @@ func PodUnavailableBudgetValidatePod(client client.Client, control PubControl, p
 		return true, "", nil
 	}
 
+	// the latency budget of validation defined in pub failurePolicy, it is checked before each retry
+	// and passed to the requests to apiserver as the context deadline
+	ctx := context.TODO()
+	var deadline time.Time
+	if pub.Spec.FailurePolicy != nil && pub.Spec.FailurePolicy.TimeoutMilliseconds != nil {
+		timeout := time.Duration(*pub.Spec.FailurePolicy.TimeoutMilliseconds) * time.Millisecond
+		deadline = Clock.Now().Add(timeout)
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	// the concurrent operations are batched and persisted to pub status together before being admitted
+	if utilfeature.DefaultFeatureGate.Enabled(features.PodUnavailableBudgetReservation) {
+		err = getReservationLedger(client).admit(ctx, pub, pod, operation, dryRun)
+		return getAdmissionResult(pub, pod, operation, err)
+	}
+
 	// for debug
 	var conflictTimes int
 	var costOfGet, costOfUpdate time.Duration
@@ -84,42 +257,26 @@ func PodUnavailableBudgetValidatePod(client client.Client, control PubControl, p
 	err = retry.RetryOnConflict(ConflictRetry, func() error {
 		unlock := util.GlobalKeyedMutex.Lock(string(pub.UID))
 		defer unlock()
+		if !deadline.IsZero() && Clock.Now().After(deadline) {
+			return errors.NewTimeoutError(fmt.Sprintf("couldn't validate PodUnavailableBudget %s within %dms", pub.Name, *pub.Spec.FailurePolicy.TimeoutMilliseconds), 0)
+		}
 
 		start := time.Now()
 		if refresh {
 			pubClone, err = kubeClient.GetGenericClient().KruiseClient.PolicyV1alpha1().
-				PodUnavailableBudgets(pub.Namespace).Get(context.TODO(), pub.Name, metav1.GetOptions{})
+				PodUnavailableBudgets(pub.Namespace).Get(ctx, pub.Name, metav1.GetOptions{})
 			if err != nil {
 				klog.Errorf("Get PodUnavailableBudget(%s/%s) failed form etcd: %s", pub.Namespace, pub.Name, err.Error())
 				return err
 			}
 		} else {
-			// compare local cache and informer cache, then get the newer one
-			item, _, err := util.GlobalCache.Get(pub)
-			if err != nil {
-				klog.Errorf("Get cache failed for PodUnavailableBudget(%s/%s): %s", pub.Namespace, pub.Name, err.Error())
-			}
-			if localCached, ok := item.(*policyv1alpha1.PodUnavailableBudget); ok {
-				pubClone = localCached.DeepCopy()
-			} else {
-				pubClone = pub.DeepCopy()
-			}
-
-			informerCached := &policyv1alpha1.PodUnavailableBudget{}
-			if err := client.Get(context.TODO(), types.NamespacedName{Namespace: pub.Namespace,
-				Name: pub.Name}, informerCached); err == nil {
-				var localRV, informerRV int64
-				_ = runtime.Convert_string_To_int64(&pubClone.ResourceVersion, &localRV, nil)
-				_ = runtime.Convert_string_To_int64(&informerCached.ResourceVersion, &informerRV, nil)
-				if informerRV > localRV {
-					pubClone = informerCached
-				}
-			}
+			pubClone = getNewerCachedPub(client, pub)
 		}
 		costOfGet += time.Since(start)
 
 		// Try to verify-and-decrement
 		// If it was false already, or if it becomes false during the course of our retries,
+		oldPub := pubClone.DeepCopy()
 		err := checkAndDecrement(pod.Name, pubClone, operation)
 		if err != nil {
 			return err
@@ -134,8 +291,18 @@ func PodUnavailableBudgetValidatePod(client client.Client, control PubControl, p
 			pubClone.Namespace, pubClone.Name, len(pubClone.Status.DisruptedPods), len(pubClone.Status.UnavailablePods),
 			pubClone.Status.TotalReplicas, pubClone.Status.DesiredAvailable, pubClone.Status.CurrentAvailable, pubClone.Status.UnavailableAllowed)
 		start = time.Now()
-		err = client.Status().Update(context.TODO(), pubClone)
+		// only patch unavailableAllowed and the pod entry rather than update the whole status,
+		// and the test operation of json patch guarantees that unavailableAllowed hasn't been changed by others.
+		patch, err := buildDecrementPatch(oldPub, pubClone, pod.Name)
+		if err != nil {
+			return err
+		}
+		err = client.Status().Patch(ctx, pubClone, patch)
 		costOfUpdate += time.Since(start)
+		if errors.IsInvalid(err) {
+			// json patch test failed, the status has been changed by others
+			err = errors.NewConflict(policyv1alpha1.Resource("podunavailablebudget"), pub.Name, err)
+		}
 		if err == nil {
 			if err = util.GlobalCache.Add(pubClone); err != nil {
 				klog.Errorf("Add cache failed for PodUnavailableBudget(%s/%s): %s", pub.Namespace, pub.Name, err.Error())
@@ -149,12 +316,24 @@ func PodUnavailableBudgetValidatePod(client client.Client, control PubControl, p
 	})
 	klog.V(3).Infof("Webhook cost of pub(%s/%s): conflict times %v, cost of Get %v, cost of Update %v",
 		pub.Namespace, pub.Name, conflictTimes, costOfGet, costOfUpdate)
-	if err != nil && err != wait.ErrWaitTimeout {
-		klog.V(3).Infof("pod(%s/%s) operation(%s) for pub(%s/%s) failed: %s", pod.Namespace, pod.Name, operation, pub.Namespace, pub.Name, err.Error())
-		return false, err.Error(), nil
-	} else if err == wait.ErrWaitTimeout {
+	recordPubWebhookCost(pub, conflictTimes, costOfGet, costOfUpdate)
+	return getAdmissionResult(pub, pod, operation, err)
+}
+
+// getAdmissionResult converts the error of verify-and-decrement to the admission result according to pub failurePolicy.
+func getAdmissionResult(pub *policyv1alpha1.PodUnavailableBudget, pod *corev1.Pod, operation Operation, err error) (bool, string, error) {
+	if err == wait.ErrWaitTimeout {
 		err = errors.NewTimeoutError(fmt.Sprintf("couldn't update PodUnavailableBudget %s due to conflicts", pub.Name), 10)
 		klog.Errorf("pod(%s/%s) operation(%s) failed: %s", pod.Namespace, pod.Name, operation, err.Error())
+	}
+	if err != nil {
+		// the validation cannot be completed rather than the budget is exhausted or the pub has been deleted
+		if !errors.IsForbidden(err) && !errors.IsNotFound(err) && isPubFailurePolicyIgnore(pub) {
+			klog.Warningf("pod(%s/%s) operation(%s) for pub(%s/%s) failed: %s, then admit according to failurePolicy(Ignore)",
+				pod.Namespace, pod.Name, operation, pub.Namespace, pub.Name, err.Error())
+			return true, "", nil
+		}
+		klog.V(3).Infof("pod(%s/%s) operation(%s) for pub(%s/%s) failed: %s", pod.Namespace, pod.Name, operation, pub.Namespace, pub.Name, err.Error())
 		return false, err.Error(), nil
 	}
 
@@ -162,6 +341,10 @@ func PodUnavailableBudgetValidatePod(client client.Client, control PubControl, p
 	return true, "", nil
 }
 
+func isPubFailurePolicyIgnore(pub *policyv1alpha1.PodUnavailableBudget) bool {
+	return pub.Spec.FailurePolicy != nil && pub.Spec.FailurePolicy.Type == policyv1alpha1.PubFailurePolicyIgnore
+}
+
 func checkAndDecrement(podName string, pub *policyv1alpha1.PodUnavailableBudget, operation Operation) error {
 	if pub.Status.UnavailableAllowed <= 0 {
 		return errors.NewForbidden(policyv1alpha1.Resource("podunavailablebudget"), pub.Name, fmt.Errorf("pub unavailable allowed is negative"))
@@ -171,7 +354,12 @@ func checkAndDecrement(podName string, pub *policyv1alpha1.PodUnavailableBudget,
 	}
 
 	pub.Status.UnavailableAllowed--
+	recordPodInPub(podName, pub, operation)
+	return nil
+}
 
+// recordPodInPub records pod in pub.Status.UnavailablePods or pub.Status.DisruptedPods according to the operation
+func recordPodInPub(podName string, pub *policyv1alpha1.PodUnavailableBudget, operation Operation) {
 	if pub.Status.DisruptedPods == nil {
 		pub.Status.DisruptedPods = make(map[string]metav1.Time)
 	}
@@ -179,14 +367,124 @@ func checkAndDecrement(podName string, pub *policyv1alpha1.PodUnavailableBudget,
 		pub.Status.UnavailablePods = make(map[string]metav1.Time)
 	}
 
-	if operation == UpdateOperation {
+	switch operation {
+	// containers recreation and resize make pod unavailable like in-place update
+	case UpdateOperation, RecreateOperation, ResizeOperation:
 		pub.Status.UnavailablePods[podName] = metav1.Time{Time: time.Now()}
 		klog.V(3).Infof("pod(%s) is recorded in pub(%s/%s) UnavailablePods", podName, pub.Namespace, pub.Name)
-	} else {
+	case EvictOperation:
+		pub.Status.DisruptedPods[podName] = metav1.Time{Time: time.Now()}
+		pub.Status.EvictedPods = append(pub.Status.EvictedPods, podName)
+		klog.V(3).Infof("pod(%s) is recorded in pub(%s/%s) DisruptedPods by eviction", podName, pub.Namespace, pub.Name)
+	default:
 		pub.Status.DisruptedPods[podName] = metav1.Time{Time: time.Now()}
 		klog.V(3).Infof("pod(%s) is recorded in pub(%s/%s) DisruptedPods", podName, pub.Namespace, pub.Name)
 	}
-	return nil
+}
+
+// RecordPubRejection emits Warning events on both pub and pod when an operation is rejected by pub,
+// so that application owners can find out why their pods were not deleted, evicted or updated.
+func RecordPubRejection(recorder record.EventRecorder, pub *policyv1alpha1.PodUnavailableBudget, pod *corev1.Pod, operation Operation, requester, reason string) {
+	if recorder == nil {
+		return
+	}
+	var requestedBy string
+	if requester != "" {
+		requestedBy = fmt.Sprintf(" requested by %s", requester)
+	}
+	recorder.Eventf(pub, corev1.EventTypeWarning, PubRejectedEventReason, "Operation(%s) of pod %s%s was rejected: %s",
+		operation, pod.Name, requestedBy, reason)
+	recorder.Eventf(pod, corev1.EventTypeWarning, PubRejectedEventReason, "Operation(%s)%s was rejected by PodUnavailableBudget %s: %s",
+		operation, requestedBy, pub.Name, reason)
+}
+
+// GetPubAuditAnnotations returns the audit annotations that record the latest observed status of pub,
+// they help to find out why an operation was denied in cluster audit logs.
+func GetPubAuditAnnotations(c client.Client, pub *policyv1alpha1.PodUnavailableBudget) map[string]string {
+	pubClone := getNewerCachedPub(c, pub)
+	return map[string]string{
+		AuditAnnotationPubName:            fmt.Sprintf("%s/%s", pubClone.Namespace, pubClone.Name),
+		AuditAnnotationCurrentAvailable:   fmt.Sprintf("%d", pubClone.Status.CurrentAvailable),
+		AuditAnnotationDesiredAvailable:   fmt.Sprintf("%d", pubClone.Status.DesiredAvailable),
+		AuditAnnotationUnavailableAllowed: fmt.Sprintf("%d", pubClone.Status.UnavailableAllowed),
+	}
+}
+
+// getNewerCachedPub compares local cache and informer cache, then returns the newer one
+func getNewerCachedPub(c client.Client, pub *policyv1alpha1.PodUnavailableBudget) *policyv1alpha1.PodUnavailableBudget {
+	var pubClone *policyv1alpha1.PodUnavailableBudget
+	item, _, err := util.GlobalCache.Get(pub)
+	if err != nil {
+		klog.Errorf("Get cache failed for PodUnavailableBudget(%s/%s): %s", pub.Namespace, pub.Name, err.Error())
+	}
+	if localCached, ok := item.(*policyv1alpha1.PodUnavailableBudget); ok {
+		pubClone = localCached.DeepCopy()
+	} else {
+		pubClone = pub.DeepCopy()
+	}
+
+	informerCached := &policyv1alpha1.PodUnavailableBudget{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Namespace: pub.Namespace,
+		Name: pub.Name}, informerCached); err == nil {
+		var localRV, informerRV int64
+		_ = runtime.Convert_string_To_int64(&pubClone.ResourceVersion, &localRV, nil)
+		_ = runtime.Convert_string_To_int64(&informerCached.ResourceVersion, &informerRV, nil)
+		if informerRV > localRV {
+			pubClone = informerCached
+		}
+	}
+	return pubClone
+}
+
+// jsonNull is used as the value of json patch test operation to assert that a path is absent.
+var jsonNull = json.RawMessage("null")
+
+// buildDecrementPatch returns the json patch from oldPub to newPub, which only contains status.unavailableAllowed
+// and the entry of podName in status.disruptedPods, status.evictedPods or status.unavailablePods.
+// Every add operation is preceded by a test operation, so that the patch never overwrites the entries
+// written by others even if oldPub is stale.
+func buildDecrementPatch(oldPub, newPub *policyv1alpha1.PodUnavailableBudget, podName string) (client.Patch, error) {
+	patches := []jsonpatch.Operation{
+		jsonpatch.NewPatch("test", "/status/unavailableAllowed", oldPub.Status.UnavailableAllowed),
+		jsonpatch.NewPatch("replace", "/status/unavailableAllowed", newPub.Status.UnavailableAllowed),
+	}
+	if t, ok := newPub.Status.UnavailablePods[podName]; ok {
+		patches = append(patches, buildMapEntryPatch("/status/unavailablePods", oldPub.Status.UnavailablePods == nil, podName, t)...)
+	}
+	if t, ok := newPub.Status.DisruptedPods[podName]; ok {
+		patches = append(patches, buildMapEntryPatch("/status/disruptedPods", oldPub.Status.DisruptedPods == nil, podName, t)...)
+	}
+	if len(newPub.Status.EvictedPods) > len(oldPub.Status.EvictedPods) {
+		if oldPub.Status.EvictedPods == nil {
+			patches = append(patches,
+				jsonpatch.NewPatch("test", "/status/evictedPods", jsonNull),
+				jsonpatch.NewPatch("add", "/status/evictedPods", []string{podName}))
+		} else {
+			patches = append(patches, jsonpatch.NewPatch("add", "/status/evictedPods/-", podName))
+		}
+	}
+	body, err := json.Marshal(patches)
+	if err != nil {
+		return nil, err
+	}
+	return client.RawPatch(types.JSONPatchType, body), nil
+}
+
+// buildMapEntryPatch returns the operations which add the key to the map of path.
+// If the map is nil locally, the whole map is added only when it is also absent in apiserver,
+// otherwise the key is added only when it is absent in the map.
+func buildMapEntryPatch(path string, isNil bool, key string, value metav1.Time) []jsonpatch.Operation {
+	if isNil {
+		return []jsonpatch.Operation{
+			jsonpatch.NewPatch("test", path, jsonNull),
+			jsonpatch.NewPatch("add", path, map[string]metav1.Time{key: value}),
+		}
+	}
+	keyPath := fmt.Sprintf("%s/%s", path, key)
+	return []jsonpatch.Operation{
+		jsonpatch.NewPatch("test", keyPath, jsonNull),
+		jsonpatch.NewPatch("add", keyPath, value),
+	}
 }
 
 func isPodRecordedInPub(podName string, pub *policyv1alpha1.PodUnavailableBudget) bool {
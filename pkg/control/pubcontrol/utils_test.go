@@ -17,16 +17,25 @@ limitations under the License.
 package pubcontrol
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"testing"
+	"time"
 
+	jsonpatch "github.com/evanphx/json-patch"
 	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
+	"github.com/openkruise/kruise/pkg/util"
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	utilpointer "k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -272,3 +281,390 @@ func TestGetPodUnavailableBudgetForPod(t *testing.T) {
 		})
 	}
 }
+
+func TestIsPodNoProtected(t *testing.T) {
+	cases := []struct {
+		name       string
+		annotation *string
+		operation  Operation
+		expect     bool
+	}{
+		{
+			name:      "no annotation",
+			operation: DeleteOperation,
+			expect:    false,
+		},
+		{
+			name:       "true, all operations",
+			annotation: utilpointer.StringPtr("true"),
+			operation:  UpdateOperation,
+			expect:     true,
+		},
+		{
+			name:       "false",
+			annotation: utilpointer.StringPtr("false"),
+			operation:  DeleteOperation,
+			expect:     false,
+		},
+		{
+			name:       "delete scope, delete operation",
+			annotation: utilpointer.StringPtr("delete"),
+			operation:  DeleteOperation,
+			expect:     true,
+		},
+		{
+			name:       "delete scope, update operation",
+			annotation: utilpointer.StringPtr("delete"),
+			operation:  UpdateOperation,
+			expect:     false,
+		},
+		{
+			name:       "delete and evict scope, evict operation",
+			annotation: utilpointer.StringPtr("delete, Evict"),
+			operation:  EvictOperation,
+			expect:     true,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			pod := podDemo.DeepCopy()
+			if cs.annotation != nil {
+				pod.Annotations[PodPubNoProtectionAnnotation] = *cs.annotation
+			}
+			if get := IsPodNoProtected(pod, cs.operation); get != cs.expect {
+				t.Fatalf("expect IsPodNoProtected(%v), but get(%v)", cs.expect, get)
+			}
+		})
+	}
+}
+
+func TestIsPodUpdateBudgeted(t *testing.T) {
+	pub := pubDemo.DeepCopy()
+	pub.UID = "pub-lease-uid"
+	cases := []struct {
+		name    string
+		oldHash string
+		newHash string
+		lease   string
+		expect  bool
+	}{
+		{
+			name:    "no lease",
+			oldHash: "v1",
+			newHash: "v2",
+			expect:  false,
+		},
+		{
+			name:    "lease of the new revision",
+			oldHash: "v1",
+			newHash: "v2",
+			lease:   "pub-lease-uid/v2",
+			expect:  true,
+		},
+		{
+			name:    "lease of another pub",
+			oldHash: "v1",
+			newHash: "v2",
+			lease:   "other-uid/v2",
+			expect:  false,
+		},
+		{
+			name:    "revision not changed",
+			oldHash: "v2",
+			newHash: "v2",
+			lease:   "pub-lease-uid/v2",
+			expect:  false,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			oldPod := podDemo.DeepCopy()
+			oldPod.Labels[apps.DefaultDeploymentUniqueLabelKey] = cs.oldHash
+			newPod := podDemo.DeepCopy()
+			newPod.Labels[apps.DefaultDeploymentUniqueLabelKey] = cs.newHash
+			if cs.lease != "" {
+				newPod.Annotations[PodPubBudgetLeaseAnnotation] = cs.lease
+			}
+			if get := IsPodUpdateBudgeted(oldPod, newPod, pub); get != cs.expect {
+				t.Fatalf("expect IsPodUpdateBudgeted(%v), but get(%v)", cs.expect, get)
+			}
+		})
+	}
+}
+
+func TestIsPodResizeBudgeted(t *testing.T) {
+	pub := pubDemo.DeepCopy()
+	pub.UID = "pub-lease-uid"
+	cases := []struct {
+		name   string
+		hash   string
+		lease  string
+		expect bool
+	}{
+		{
+			name:   "no lease",
+			hash:   "v1",
+			expect: false,
+		},
+		{
+			name:   "lease of the revision to update",
+			hash:   "v1",
+			lease:  "pub-lease-uid/v2",
+			expect: true,
+		},
+		{
+			name:   "lease of another pub",
+			hash:   "v1",
+			lease:  "other-uid/v2",
+			expect: false,
+		},
+		{
+			name:   "lease of the current revision",
+			hash:   "v2",
+			lease:  "pub-lease-uid/v2",
+			expect: false,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			pod := podDemo.DeepCopy()
+			pod.Labels[apps.DefaultDeploymentUniqueLabelKey] = cs.hash
+			if cs.lease != "" {
+				pod.Annotations[PodPubBudgetLeaseAnnotation] = cs.lease
+			}
+			if get := IsPodResizeBudgeted(pod, pub); get != cs.expect {
+				t.Fatalf("expect IsPodResizeBudgeted(%v), but get(%v)", cs.expect, get)
+			}
+		})
+	}
+}
+
+// slowConflictClient steps the fake clock on every status patch and returns conflict,
+// it simulates the slow and conflicting updates of pub status.
+type slowConflictClient struct {
+	client.Client
+	clock *clock.FakeClock
+	step  time.Duration
+}
+
+func (c *slowConflictClient) Status() client.StatusWriter {
+	return &slowConflictStatusWriter{StatusWriter: c.Client.Status(), c: c}
+}
+
+type slowConflictStatusWriter struct {
+	client.StatusWriter
+	c *slowConflictClient
+}
+
+func (w *slowConflictStatusWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if _, ok := ctx.Deadline(); !ok {
+		return fmt.Errorf("expect context with deadline")
+	}
+	w.c.clock.Step(w.c.step)
+	return errors.NewConflict(policyv1alpha1.Resource("podunavailablebudget"), obj.GetName(), fmt.Errorf("conflict"))
+}
+
+func TestPubFailurePolicy(t *testing.T) {
+	origin := ConflictRetry
+	ConflictRetry.Duration = time.Millisecond
+	defer func() {
+		Clock = clock.RealClock{}
+		ConflictRetry = origin
+	}()
+	cases := []struct {
+		name               string
+		failurePolicy      *policyv1alpha1.PubFailurePolicy
+		unavailableAllowed int32
+		pubNotFound        bool
+		slowConflict       bool
+		expectAllowed      bool
+	}{
+		{
+			name:               "pub not found, default fail",
+			unavailableAllowed: 1,
+			pubNotFound:        true,
+			expectAllowed:      false,
+		},
+		{
+			name:               "pub not found, ignore doesn't take effect",
+			failurePolicy:      &policyv1alpha1.PubFailurePolicy{Type: policyv1alpha1.PubFailurePolicyIgnore},
+			unavailableAllowed: 1,
+			pubNotFound:        true,
+			expectAllowed:      false,
+		},
+		{
+			name:               "latency budget exceeded, default fail",
+			failurePolicy:      &policyv1alpha1.PubFailurePolicy{TimeoutMilliseconds: utilpointer.Int32Ptr(100)},
+			unavailableAllowed: 1,
+			slowConflict:       true,
+			expectAllowed:      false,
+		},
+		{
+			name:               "latency budget exceeded, ignore",
+			failurePolicy:      &policyv1alpha1.PubFailurePolicy{Type: policyv1alpha1.PubFailurePolicyIgnore, TimeoutMilliseconds: utilpointer.Int32Ptr(100)},
+			unavailableAllowed: 1,
+			slowConflict:       true,
+			expectAllowed:      true,
+		},
+		{
+			name:               "budget exhausted, ignore doesn't take effect",
+			failurePolicy:      &policyv1alpha1.PubFailurePolicy{Type: policyv1alpha1.PubFailurePolicyIgnore},
+			unavailableAllowed: 0,
+			expectAllowed:      false,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			fakeClock := clock.NewFakeClock(time.Now())
+			Clock = fakeClock
+			pub := pubDemo.DeepCopy()
+			pub.Spec.FailurePolicy = cs.failurePolicy
+			pub.Status.UnavailableAllowed = cs.unavailableAllowed
+			builder := fake.NewClientBuilder().WithScheme(scheme)
+			if !cs.pubNotFound {
+				builder = builder.WithObjects(pub)
+			}
+			var fakeClient client.Client = builder.Build()
+			if cs.slowConflict {
+				// the first patch exceeds the latency budget, so the validation fails before the second one
+				fakeClient = &slowConflictClient{Client: fakeClient, clock: fakeClock, step: 200 * time.Millisecond}
+			}
+			allowed, _, err := PodUnavailableBudgetValidatePod(fakeClient, NewPubControl(fakeClient), pub, podDemo.DeepCopy(), DeleteOperation, false)
+			if err != nil {
+				t.Fatalf("PodUnavailableBudgetValidatePod failed: %s", err.Error())
+			}
+			if allowed != cs.expectAllowed {
+				t.Fatalf("expect allowed(%v), but get(%v)", cs.expectAllowed, allowed)
+			}
+		})
+	}
+}
+
+func TestValidateConflictRetry(t *testing.T) {
+	origin := ConflictRetry
+	defer func() { ConflictRetry = origin }()
+
+	cases := []struct {
+		name      string
+		steps     int
+		duration  time.Duration
+		factor    float64
+		expectErr bool
+	}{
+		{name: "default", steps: 4, duration: 500 * time.Millisecond, factor: 1.0},
+		{name: "zero steps", steps: 0, duration: 500 * time.Millisecond, factor: 1.0, expectErr: true},
+		{name: "zero duration", steps: 4, duration: 0, factor: 1.0, expectErr: true},
+		{name: "negative duration", steps: 4, duration: -time.Second, factor: 1.0, expectErr: true},
+		{name: "factor less than 1", steps: 4, duration: 500 * time.Millisecond, factor: 0.5, expectErr: true},
+	}
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			ConflictRetry.Steps = cs.steps
+			ConflictRetry.Duration = cs.duration
+			ConflictRetry.Factor = cs.factor
+			if err := ValidateConflictRetry(); (err != nil) != cs.expectErr {
+				t.Fatalf("expect error(%v), but get(%v)", cs.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestBuildDecrementPatch(t *testing.T) {
+	now := metav1.Now()
+	cases := []struct {
+		name      string
+		oldPub    *policyv1alpha1.PodUnavailableBudget
+		operation Operation
+		// status of pub in apiserver
+		serverStatus policyv1alpha1.PodUnavailableBudgetStatus
+		expectErr    bool
+		expectStatus policyv1alpha1.PodUnavailableBudgetStatus
+	}{
+		{
+			name:         "local map is nil, map is absent in apiserver",
+			oldPub:       &policyv1alpha1.PodUnavailableBudget{Status: policyv1alpha1.PodUnavailableBudgetStatus{UnavailableAllowed: 2}},
+			operation:    DeleteOperation,
+			serverStatus: policyv1alpha1.PodUnavailableBudgetStatus{UnavailableAllowed: 2},
+			expectStatus: policyv1alpha1.PodUnavailableBudgetStatus{UnavailableAllowed: 1, DisruptedPods: map[string]metav1.Time{"pod-0": now}},
+		},
+		{
+			name:      "local map is nil, map has been written by others in apiserver",
+			oldPub:    &policyv1alpha1.PodUnavailableBudget{Status: policyv1alpha1.PodUnavailableBudgetStatus{UnavailableAllowed: 2}},
+			operation: DeleteOperation,
+			serverStatus: policyv1alpha1.PodUnavailableBudgetStatus{UnavailableAllowed: 2,
+				DisruptedPods: map[string]metav1.Time{"pod-1": now}},
+			expectErr: true,
+		},
+		{
+			name: "local map is not nil, add key",
+			oldPub: &policyv1alpha1.PodUnavailableBudget{Status: policyv1alpha1.PodUnavailableBudgetStatus{UnavailableAllowed: 2,
+				UnavailablePods: map[string]metav1.Time{"pod-1": now}}},
+			operation: UpdateOperation,
+			serverStatus: policyv1alpha1.PodUnavailableBudgetStatus{UnavailableAllowed: 2,
+				UnavailablePods: map[string]metav1.Time{"pod-1": now, "pod-2": now}},
+			expectStatus: policyv1alpha1.PodUnavailableBudgetStatus{UnavailableAllowed: 1,
+				UnavailablePods: map[string]metav1.Time{"pod-0": now, "pod-1": now, "pod-2": now}},
+		},
+		{
+			name: "local map is not nil, key has been written by others in apiserver",
+			oldPub: &policyv1alpha1.PodUnavailableBudget{Status: policyv1alpha1.PodUnavailableBudgetStatus{UnavailableAllowed: 2,
+				UnavailablePods: map[string]metav1.Time{"pod-1": now}}},
+			operation: UpdateOperation,
+			serverStatus: policyv1alpha1.PodUnavailableBudgetStatus{UnavailableAllowed: 2,
+				UnavailablePods: map[string]metav1.Time{"pod-0": now}},
+			expectErr: true,
+		},
+		{
+			name:      "evicted pods is nil locally, but has been written by others in apiserver",
+			oldPub:    &policyv1alpha1.PodUnavailableBudget{Status: policyv1alpha1.PodUnavailableBudgetStatus{UnavailableAllowed: 2, DisruptedPods: map[string]metav1.Time{}}},
+			operation: EvictOperation,
+			serverStatus: policyv1alpha1.PodUnavailableBudgetStatus{UnavailableAllowed: 2,
+				DisruptedPods: map[string]metav1.Time{"pod-1": now}, EvictedPods: []string{"pod-1"}},
+			expectErr: true,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			newPub := cs.oldPub.DeepCopy()
+			if err := checkAndDecrement("pod-0", newPub, cs.operation); err != nil {
+				t.Fatalf("checkAndDecrement failed: %s", err.Error())
+			}
+			// align the timestamps with the expected status
+			for name := range newPub.Status.DisruptedPods {
+				newPub.Status.DisruptedPods[name] = now
+			}
+			for name := range newPub.Status.UnavailablePods {
+				newPub.Status.UnavailablePods[name] = now
+			}
+			patch, err := buildDecrementPatch(cs.oldPub, newPub, "pod-0")
+			if err != nil {
+				t.Fatalf("buildDecrementPatch failed: %s", err.Error())
+			}
+			body, _ := patch.Data(newPub)
+			decoded, err := jsonpatch.DecodePatch(body)
+			if err != nil {
+				t.Fatalf("DecodePatch failed: %s", err.Error())
+			}
+			server, _ := json.Marshal(&policyv1alpha1.PodUnavailableBudget{Status: cs.serverStatus})
+			patched, err := decoded.Apply(server)
+			if (err != nil) != cs.expectErr {
+				t.Fatalf("expect error(%v), but get(%v)", cs.expectErr, err)
+			}
+			if cs.expectErr {
+				return
+			}
+			result := &policyv1alpha1.PodUnavailableBudget{}
+			if err = json.Unmarshal(patched, result); err != nil {
+				t.Fatalf("Unmarshal failed: %s", err.Error())
+			}
+			if util.DumpJSON(result.Status) != util.DumpJSON(cs.expectStatus) {
+				t.Fatalf("expect status(%s), but get(%s)", util.DumpJSON(cs.expectStatus), util.DumpJSON(result.Status))
+			}
+		})
+	}
+}
@@ -17,8 +17,10 @@ limitations under the License.
 package sidecarcontrol
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
 	"regexp"
 	"strings"
 
@@ -35,6 +37,7 @@ import (
 	"k8s.io/klog/v2"
 	kubecontroller "k8s.io/kubernetes/pkg/controller"
 	"k8s.io/kubernetes/pkg/fieldpath"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
@@ -48,6 +51,10 @@ const (
 	// SidecarSetListAnnotation represent sidecarset list that injected pods
 	SidecarSetListAnnotation = "kruise.io/sidecarset-injected-list"
 
+	// SidecarSetEphemeralAnnotation represents the sidecarSet list whose containers will be injected into pod
+	// as ephemeral containers, e.g. "debug-tools,network-tools"
+	SidecarSetEphemeralAnnotation = "kruise.io/sidecarset-ephemeral"
+
 	// SidecarEnvKey specifies the environment variable which record a container as injected
 	SidecarEnvKey = "IS_INJECTED"
 
@@ -72,11 +79,17 @@ type SidecarSetUpgradeSpec struct {
 }
 
 // PodMatchSidecarSet determines if pod match Selector of sidecar.
-func PodMatchedSidecarSet(pod *corev1.Pod, sidecarSet appsv1alpha1.SidecarSet) (bool, error) {
+func PodMatchedSidecarSet(reader client.Reader, pod *corev1.Pod, sidecarSet appsv1alpha1.SidecarSet) (bool, error) {
 	//If matchedNamespace is not empty, sidecarSet will only match the pods in the namespace
 	if sidecarSet.Spec.Namespace != "" && sidecarSet.Spec.Namespace != pod.Namespace {
 		return false, nil
 	}
+	// If namespaceSelector is not nil, sidecarSet will only match the pods in the selected namespaces
+	if sidecarSet.Spec.NamespaceSelector != nil {
+		if matched, err := IsNamespaceSelected(reader, pod.Namespace, sidecarSet.Spec.NamespaceSelector); err != nil || !matched {
+			return false, err
+		}
+	}
 	// if selector not matched, then continue
 	selector, err := metav1.LabelSelectorAsSelector(sidecarSet.Spec.Selector)
 	if err != nil {
@@ -89,6 +102,33 @@ func PodMatchedSidecarSet(pod *corev1.Pod, sidecarSet appsv1alpha1.SidecarSet) (
 	return false, nil
 }
 
+// IsPodSelectedByInjectionPercent determines if the pod is chosen by the injection percent of sidecarSet.
+// The choice only depends on the names of sidecarSet and pod, so that it is stable for the same pod.
+func IsPodSelectedByInjectionPercent(pod *corev1.Pod, sidecarSet *appsv1alpha1.SidecarSet) bool {
+	percent := sidecarSet.Spec.InjectionStrategy.Percent
+	if percent == nil || *percent >= 100 {
+		return true
+	} else if *percent <= 0 {
+		return false
+	}
+	hasher := fnv.New32a()
+	_, _ = hasher.Write([]byte(fmt.Sprintf("%s/%s/%s", sidecarSet.Name, pod.Namespace, pod.Name)))
+	return int32(hasher.Sum32()%100) < *percent
+}
+
+// IsNamespaceSelected determines if the labels of namespace match the namespaceSelector.
+func IsNamespaceSelected(reader client.Reader, namespace string, namespaceSelector *metav1.LabelSelector) (bool, error) {
+	selector, err := metav1.LabelSelectorAsSelector(namespaceSelector)
+	if err != nil {
+		return false, err
+	}
+	ns := &corev1.Namespace{}
+	if err = reader.Get(context.TODO(), client.ObjectKey{Name: namespace}, ns); err != nil {
+		return false, err
+	}
+	return selector.Matches(labels.Set(ns.Labels)), nil
+}
+
 // IsActivePod determines the pod whether need be injected and updated
 func IsActivePod(pod *corev1.Pod) bool {
 	for _, namespace := range SidecarIgnoredNamespaces {
@@ -275,9 +315,10 @@ func GetInjectedVolumeMountsAndEnvs(control SidecarControl, sidecarContainer *ap
 		}
 
 		for _, volumeMount := range appContainer.VolumeMounts {
-			if !control.NeedToInjectVolumeMount(volumeMount) {
+			if !control.NeedToInjectVolumeMount(volumeMount) || !isSharedVolume(&sidecarContainer.ShareVolumePolicy, volumeMount.Name) {
 				continue
 			}
+			volumeMount = transformSharedVolumeMount(&sidecarContainer.ShareVolumePolicy, volumeMount)
 			injectedMounts = append(injectedMounts, volumeMount)
 			//If volumeMounts.SubPathExpr contains expansions, copy environment
 			//for example: SubPathExpr=foo/$(ODD_NAME)/$(POD_NAME), we need copy environment ODD_NAME、POD_NAME
@@ -299,6 +340,42 @@ func GetInjectedVolumeMountsAndEnvs(control SidecarControl, sidecarContainer *ap
 	return injectedMounts, injectedEnvs
 }
 
+// isSharedVolume returns whether the volume is shared with the sidecar container by the include and exclude filters.
+func isSharedVolume(policy *appsv1alpha1.ShareVolumePolicy, volumeName string) bool {
+	for _, name := range policy.ExcludeVolumes {
+		if name == volumeName {
+			return false
+		}
+	}
+	if len(policy.IncludeVolumes) == 0 {
+		return true
+	}
+	for _, name := range policy.IncludeVolumes {
+		if name == volumeName {
+			return true
+		}
+	}
+	return false
+}
+
+// transformSharedVolumeMount applies the mount override of the volume to the shared volumeMount.
+func transformSharedVolumeMount(policy *appsv1alpha1.ShareVolumePolicy, volumeMount corev1.VolumeMount) corev1.VolumeMount {
+	for _, override := range policy.MountOverrides {
+		if override.VolumeName != volumeMount.Name {
+			continue
+		}
+		if override.SubPath != nil {
+			volumeMount.SubPath = *override.SubPath
+			volumeMount.SubPathExpr = ""
+		}
+		if override.ReadOnly != nil {
+			volumeMount.ReadOnly = *override.ReadOnly
+		}
+		break
+	}
+	return volumeMount
+}
+
 func GetSidecarTransferEnvs(sidecarContainer *appsv1alpha1.SidecarContainer, pod *corev1.Pod) (injectedEnvs []corev1.EnvVar) {
 	// pre-process envs in pod, format: container.name/env.name -> container.env
 	// if SourceContainerName is set, use it as source container name
@@ -0,0 +1,159 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecarcontrol
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/util"
+
+	corev1 "k8s.io/api/core/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// SidecarSetOverrideLabel is the label of the ConfigMaps that override the sidecar containers
+	// injected into the pods in their namespaces, and the value is the name of the overridden SidecarSet.
+	// The data keys of the ConfigMaps are the names of sidecar containers, and the values are
+	// SidecarContainerOverride in YAML or JSON.
+	SidecarSetOverrideLabel = "kruise.io/sidecarset-override"
+)
+
+// SidecarContainerOverride describes the values to override a sidecar container in a namespace.
+type SidecarContainerOverride struct {
+	// ImageTag replaces the tag of the sidecar container image.
+	ImageTag string `json:"imageTag,omitempty"`
+	// Resources replaces the requests and limits of the same resource names.
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+	// Env replaces the env of the same names, and the others are appended.
+	Env []corev1.EnvVar `json:"env,omitempty"`
+}
+
+// ApplySidecarSetOverrides merges the overrides in the namespace into the containers and initContainers of sidecarSet,
+// which must be a deep copy. The ConfigMaps are applied in the order of their names, and it returns an error if
+// any of the overrides is invalid, so that the sidecars are never injected or upgraded without their overrides.
+func ApplySidecarSetOverrides(reader client.Reader, namespace string, sidecarSet *appsv1alpha1.SidecarSet) error {
+	configMaps := &corev1.ConfigMapList{}
+	if err := reader.List(context.TODO(), configMaps, client.InNamespace(namespace),
+		client.MatchingLabels{SidecarSetOverrideLabel: sidecarSet.Name}); err != nil {
+		return fmt.Errorf("failed to list overrides of sidecarSet %s in namespace %s: %v", sidecarSet.Name, namespace, err)
+	}
+	sort.Slice(configMaps.Items, func(i, j int) bool {
+		return configMaps.Items[i].Name < configMaps.Items[j].Name
+	})
+
+	var errs []error
+	for i := range configMaps.Items {
+		cm := &configMaps.Items[i]
+		for j := range sidecarSet.Spec.InitContainers {
+			if err := applySidecarContainerOverride(cm, &sidecarSet.Spec.InitContainers[j].Container); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		for j := range sidecarSet.Spec.Containers {
+			if err := applySidecarContainerOverride(cm, &sidecarSet.Spec.Containers[j].Container); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// ValidateSidecarSetOverrides checks all the overrides of sidecarSet against its containers and initContainers.
+func ValidateSidecarSetOverrides(reader client.Reader, sidecarSet *appsv1alpha1.SidecarSet) error {
+	configMaps := &corev1.ConfigMapList{}
+	if err := reader.List(context.TODO(), configMaps, client.MatchingLabels{SidecarSetOverrideLabel: sidecarSet.Name}); err != nil {
+		return fmt.Errorf("failed to list overrides of sidecarSet %s: %v", sidecarSet.Name, err)
+	}
+
+	var errs []error
+	for i := range configMaps.Items {
+		cm := &configMaps.Items[i]
+		for j := range sidecarSet.Spec.InitContainers {
+			if err := applySidecarContainerOverride(cm, sidecarSet.Spec.InitContainers[j].Container.DeepCopy()); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		for j := range sidecarSet.Spec.Containers {
+			if err := applySidecarContainerOverride(cm, sidecarSet.Spec.Containers[j].Container.DeepCopy()); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func applySidecarContainerOverride(cm *corev1.ConfigMap, container *corev1.Container) error {
+	value, ok := cm.Data[container.Name]
+	if !ok {
+		return nil
+	}
+	override := &SidecarContainerOverride{}
+	if err := yaml.UnmarshalStrict([]byte(value), override); err != nil {
+		return fmt.Errorf("invalid sidecar container(%s) override in configmap(%s/%s): %s", container.Name, cm.Namespace, cm.Name, err.Error())
+	}
+
+	if override.ImageTag != "" {
+		repo, _, _, err := util.ParseImage(container.Image)
+		if err != nil {
+			return fmt.Errorf("invalid imageTag override of sidecar container(%s) in configmap(%s/%s): %s", container.Name, cm.Namespace, cm.Name, err.Error())
+		}
+		image := fmt.Sprintf("%s:%s", repo, override.ImageTag)
+		if _, tag, _, err := util.ParseImage(image); err != nil || tag != override.ImageTag {
+			return fmt.Errorf("invalid imageTag override of sidecar container(%s) in configmap(%s/%s): %q is not a valid tag", container.Name, cm.Namespace, cm.Name, override.ImageTag)
+		}
+		container.Image = image
+	}
+	if override.Resources != nil {
+		if len(override.Resources.Requests) > 0 && container.Resources.Requests == nil {
+			container.Resources.Requests = corev1.ResourceList{}
+		}
+		for name, quantity := range override.Resources.Requests {
+			container.Resources.Requests[name] = quantity
+		}
+		if len(override.Resources.Limits) > 0 && container.Resources.Limits == nil {
+			container.Resources.Limits = corev1.ResourceList{}
+		}
+		for name, quantity := range override.Resources.Limits {
+			container.Resources.Limits[name] = quantity
+		}
+	}
+	for _, env := range override.Env {
+		if msgs := validation.IsEnvVarName(env.Name); len(msgs) > 0 {
+			return fmt.Errorf("invalid env override of sidecar container(%s) in configmap(%s/%s): %s", container.Name, cm.Namespace, cm.Name, strings.Join(msgs, ", "))
+		}
+		replaced := false
+		for k := range container.Env {
+			if container.Env[k].Name == env.Name {
+				container.Env[k] = env
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			container.Env = append(container.Env, env)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecarcontrol
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestApplySidecarSetOverrides(t *testing.T) {
+	newSidecarSet := func() *appsv1alpha1.SidecarSet {
+		return &appsv1alpha1.SidecarSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-sidecarset"},
+			Spec: appsv1alpha1.SidecarSetSpec{
+				Containers: []appsv1alpha1.SidecarContainer{
+					{
+						Container: corev1.Container{
+							Name:  "sidecar",
+							Image: "registry.io/sidecar:v1",
+							Env:   []corev1.EnvVar{{Name: "LOG_LEVEL", Value: "info"}},
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("100m"),
+									corev1.ResourceMemory: resource.MustParse("100Mi"),
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	newConfigMap := func(namespace, name, sidecarSetName, value string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      name,
+				Labels:    map[string]string{SidecarSetOverrideLabel: sidecarSetName},
+			},
+			Data: map[string]string{"sidecar": value},
+		}
+	}
+
+	cases := []struct {
+		name       string
+		configMaps []*corev1.ConfigMap
+		expected   func() *appsv1alpha1.SidecarSet
+		expectErr  bool
+	}{
+		{
+			name: "no overrides in namespace",
+			configMaps: []*corev1.ConfigMap{
+				newConfigMap("other-ns", "override", "test-sidecarset", `imageTag: v2`),
+				newConfigMap("test-ns", "override", "other-sidecarset", `imageTag: v2`),
+			},
+			expected: newSidecarSet,
+		},
+		{
+			name: "override image tag, resources and env",
+			configMaps: []*corev1.ConfigMap{
+				newConfigMap("test-ns", "override", "test-sidecarset", `
+imageTag: v2
+resources:
+  requests:
+    cpu: 200m
+  limits:
+    cpu: "1"
+env:
+- name: LOG_LEVEL
+  value: debug
+- name: TENANT
+  value: test
+`),
+			},
+			expected: func() *appsv1alpha1.SidecarSet {
+				sidecarSet := newSidecarSet()
+				container := &sidecarSet.Spec.Containers[0].Container
+				container.Image = "registry.io/sidecar:v2"
+				container.Resources.Requests[corev1.ResourceCPU] = resource.MustParse("200m")
+				container.Resources.Limits = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}
+				container.Env = []corev1.EnvVar{{Name: "LOG_LEVEL", Value: "debug"}, {Name: "TENANT", Value: "test"}}
+				return sidecarSet
+			},
+		},
+		{
+			name: "overrides applied in order",
+			configMaps: []*corev1.ConfigMap{
+				newConfigMap("test-ns", "override-b", "test-sidecarset", `{"imageTag": "v3"}`),
+				newConfigMap("test-ns", "override-a", "test-sidecarset", `{"imageTag": "v2"}`),
+			},
+			expected: func() *appsv1alpha1.SidecarSet {
+				sidecarSet := newSidecarSet()
+				sidecarSet.Spec.Containers[0].Image = "registry.io/sidecar:v3"
+				return sidecarSet
+			},
+		},
+		{
+			name: "invalid override",
+			configMaps: []*corev1.ConfigMap{
+				newConfigMap("test-ns", "override-a", "test-sidecarset", `{"imageTag": "v2"}`),
+				newConfigMap("test-ns", "override-b", "test-sidecarset", `imageTag: [`),
+			},
+			expectErr: true,
+		},
+		{
+			name: "unknown field in override",
+			configMaps: []*corev1.ConfigMap{
+				newConfigMap("test-ns", "override", "test-sidecarset", `image: registry.io/sidecar:v2`),
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid image tag in override",
+			configMaps: []*corev1.ConfigMap{
+				newConfigMap("test-ns", "override", "test-sidecarset", `imageTag: "v2@latest"`),
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid env name in override",
+			configMaps: []*corev1.ConfigMap{
+				newConfigMap("test-ns", "override", "test-sidecarset", `{"env": [{"name": "1=2", "value": "x"}]}`),
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder()
+			for _, cm := range cs.configMaps {
+				builder.WithObjects(cm)
+			}
+			sidecarSet := newSidecarSet()
+			err := ApplySidecarSetOverrides(builder.Build(), "test-ns", sidecarSet)
+			if cs.expectErr {
+				if err == nil {
+					t.Fatalf("expect error for invalid overrides, but got nil")
+				}
+				if err := ValidateSidecarSetOverrides(builder.Build(), newSidecarSet()); err == nil {
+					t.Fatalf("expect validation error for invalid overrides, but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("apply overrides failed: %s", err.Error())
+			}
+			if err := ValidateSidecarSetOverrides(builder.Build(), newSidecarSet()); err != nil {
+				t.Fatalf("validate overrides failed: %s", err.Error())
+			}
+			if expected := cs.expected(); !reflect.DeepEqual(sidecarSet.Spec, expected.Spec) {
+				t.Fatalf("expect sidecarSet spec %v, but got %v", expected.Spec, sidecarSet.Spec)
+			}
+		})
+	}
+}
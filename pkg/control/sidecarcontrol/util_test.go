@@ -18,6 +18,8 @@ package sidecarcontrol
 
 import (
 	"encoding/json"
+	"fmt"
+	"reflect"
 	"testing"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
@@ -25,6 +27,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
+	utilpointer "k8s.io/utils/pointer"
 )
 
 var (
@@ -720,3 +723,113 @@ func TestGetSidecarTransferEnvs(t *testing.T) {
 		}
 	}
 }
+
+func TestIsPodSelectedByInjectionPercent(t *testing.T) {
+	sidecarSet := &appsv1alpha1.SidecarSet{ObjectMeta: metav1.ObjectMeta{Name: "test-sidecarset"}}
+	selected := map[string]bool{}
+	for _, percent := range []int32{0, 10, 50, 100} {
+		sidecarSet.Spec.InjectionStrategy.Percent = utilpointer.Int32Ptr(percent)
+		var count int32
+		for i := 0; i < 1000; i++ {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: fmt.Sprintf("pod-%d", i)}}
+			if !IsPodSelectedByInjectionPercent(pod, sidecarSet) {
+				continue
+			}
+			count++
+			selected[pod.Name] = true
+		}
+		if count != int32(len(selected)) {
+			t.Fatalf("percent %d: expect the pods chosen by smaller percent still chosen", percent)
+		}
+		if count < percent*10-50 || count > percent*10+50 {
+			t.Fatalf("percent %d: expect about %d pods chosen, but got %d", percent, percent*10, count)
+		}
+	}
+}
+
+func TestGetInjectedVolumeMountsAndEnvs(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-pod"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "main",
+					Env:  []corev1.EnvVar{{Name: "POD_NAME", Value: "test-pod"}},
+					VolumeMounts: []corev1.VolumeMount{
+						{Name: "logs", MountPath: "/var/log", SubPathExpr: "$(POD_NAME)"},
+						{Name: "data", MountPath: "/data"},
+						{Name: "config", MountPath: "/etc/config", ReadOnly: true},
+					},
+				},
+			},
+		},
+	}
+
+	cases := []struct {
+		name           string
+		policy         appsv1alpha1.ShareVolumePolicy
+		expectedMounts []corev1.VolumeMount
+		expectedEnvs   []corev1.EnvVar
+	}{
+		{
+			name:   "share all volumes",
+			policy: appsv1alpha1.ShareVolumePolicy{Type: appsv1alpha1.ShareVolumePolicyEnabled},
+			expectedMounts: []corev1.VolumeMount{
+				{Name: "logs", MountPath: "/var/log", SubPathExpr: "$(POD_NAME)"},
+				{Name: "data", MountPath: "/data"},
+				{Name: "config", MountPath: "/etc/config", ReadOnly: true},
+			},
+			expectedEnvs: []corev1.EnvVar{{Name: "POD_NAME", Value: "test-pod"}},
+		},
+		{
+			name: "include and exclude volumes",
+			policy: appsv1alpha1.ShareVolumePolicy{
+				Type:           appsv1alpha1.ShareVolumePolicyEnabled,
+				IncludeVolumes: []string{"data", "config"},
+				ExcludeVolumes: []string{"config"},
+			},
+			expectedMounts: []corev1.VolumeMount{
+				{Name: "data", MountPath: "/data"},
+			},
+		},
+		{
+			name: "override subPath and readOnly",
+			policy: appsv1alpha1.ShareVolumePolicy{
+				Type: appsv1alpha1.ShareVolumePolicyEnabled,
+				MountOverrides: []appsv1alpha1.ShareVolumeMountOverride{
+					{VolumeName: "logs", SubPath: utilpointer.StringPtr("")},
+					{VolumeName: "data", SubPath: utilpointer.StringPtr("sidecar"), ReadOnly: utilpointer.BoolPtr(true)},
+					{VolumeName: "config", ReadOnly: utilpointer.BoolPtr(false)},
+				},
+			},
+			expectedMounts: []corev1.VolumeMount{
+				{Name: "logs", MountPath: "/var/log"},
+				{Name: "data", MountPath: "/data", SubPath: "sidecar", ReadOnly: true},
+				{Name: "config", MountPath: "/etc/config"},
+			},
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			sidecarSet := &appsv1alpha1.SidecarSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-sidecarset"},
+				Spec: appsv1alpha1.SidecarSetSpec{
+					Containers: []appsv1alpha1.SidecarContainer{
+						{
+							Container:         corev1.Container{Name: "sidecar"},
+							ShareVolumePolicy: cs.policy,
+						},
+					},
+				},
+			}
+			mounts, envs := GetInjectedVolumeMountsAndEnvs(New(sidecarSet), &sidecarSet.Spec.Containers[0], pod)
+			if !reflect.DeepEqual(mounts, cs.expectedMounts) {
+				t.Fatalf("expect volumeMounts %v, but got %v", cs.expectedMounts, mounts)
+			}
+			if !reflect.DeepEqual(envs, cs.expectedEnvs) {
+				t.Fatalf("expect envs %v, but got %v", cs.expectedEnvs, envs)
+			}
+		})
+	}
+}
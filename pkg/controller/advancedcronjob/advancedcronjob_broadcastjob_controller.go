@@ -216,6 +216,12 @@ func (r *ReconcileAdvancedCronJob) reconcileBroadcastJob(ctx context.Context, re
 		if err != nil {
 			return time.Time{}, time.Time{}, fmt.Errorf("Unparseable schedule %q: %v", cronJob.Spec.Schedule, err)
 		}
+		// the schedule is calculated in the time zone
+		loc, err := getTimeZoneLocation(cronJob)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("Unknown time zone %q: %v", *cronJob.Spec.TimeZone, err)
+		}
+		now = now.In(loc)
 
 		// for optimization purposes, cheat a bit and start from our last observed run time
 		// we could reconstitute this here, but there's not much point, since we've
@@ -234,6 +240,7 @@ func (r *ReconcileAdvancedCronJob) reconcileBroadcastJob(ctx context.Context, re
 				earliestTime = schedulingDeadline
 			}
 		}
+		earliestTime = earliestTime.In(loc)
 		if earliestTime.After(now) {
 			return time.Time{}, sched.Next(now), nil
 		}
@@ -96,6 +96,20 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		klog.Error(err)
 		return err
 	}
+
+	if utildiscovery.DiscoverObject(&appsv1alpha1.ImagePullJob{}) {
+		if err = watchImagePullJob(c); err != nil {
+			klog.Error(err)
+			return err
+		}
+	}
+
+	if utildiscovery.DiscoverObject(&appsv1alpha1.EphemeralJob{}) {
+		if err = watchEphemeralJob(c); err != nil {
+			klog.Error(err)
+			return err
+		}
+	}
 	return nil
 }
 
@@ -150,6 +164,10 @@ func (r *ReconcileAdvancedCronJob) Reconcile(_ context.Context, req ctrl.Request
 		return r.reconcileJob(ctx, req, advancedCronJob)
 	case appsv1alpha1.BroadcastJobTemplate:
 		return r.reconcileBroadcastJob(ctx, req, advancedCronJob)
+	case appsv1alpha1.ImagePullJobTemplateKind:
+		return r.reconcileImagePullJob(ctx, req, advancedCronJob)
+	case appsv1alpha1.EphemeralJobTemplateKind:
+		return r.reconcileEphemeralJob(ctx, req, advancedCronJob)
 	default:
 		klog.Info("No template found", req.NamespacedName)
 	}
@@ -18,13 +18,16 @@ package advancedcronjob
 
 import (
 	"flag"
+	"fmt"
 	"testing"
+	"time"
 
 	batchv1 "k8s.io/api/batch/v1"
 
 	batchv1beta1 "k8s.io/api/batch/v1beta1"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/robfig/cron"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/net/context"
 	v1 "k8s.io/api/core/v1"
@@ -122,6 +125,111 @@ func TestReconcileAdvancedJobCreateJob(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestReconcileAdvancedJobCreateImagePullJob(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = appsv1alpha1.AddToScheme(scheme)
+
+	job1 := createJob("job1", imagePullJobTemplate())
+	job1.CreationTimestamp = metav1.NewTime(time.Now().Add(-90 * time.Second))
+	reconcileJob := createReconcileJob(scheme, job1)
+
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "job1",
+			Namespace: "default",
+		},
+	}
+
+	_, err := reconcileJob.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	retrievedJob := &appsv1alpha1.AdvancedCronJob{}
+	err = reconcileJob.Get(context.TODO(), request.NamespacedName, retrievedJob)
+	assert.NoError(t, err)
+	assert.Equal(t, retrievedJob.Status.Type, appsv1alpha1.ImagePullJobTemplateKind)
+
+	imagePullJobList := &appsv1alpha1.ImagePullJobList{}
+	err = reconcileJob.List(context.TODO(), imagePullJobList, client.InNamespace(request.Namespace))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(imagePullJobList.Items))
+	assert.Equal(t, "nginx:latest", imagePullJobList.Items[0].Spec.Image)
+	assert.Equal(t, "job1", metav1.GetControllerOf(&imagePullJobList.Items[0]).Name)
+}
+
+func TestReconcileAdvancedJobCreateEphemeralJob(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = appsv1alpha1.AddToScheme(scheme)
+	_ = v1.AddToScheme(scheme)
+
+	job1 := createJob("job1", ephemeralJobTemplate())
+	job1.CreationTimestamp = metav1.NewTime(time.Now().Add(-90 * time.Second))
+	reconcileJob := createReconcileJob(scheme, job1)
+
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "job1",
+			Namespace: "default",
+		},
+	}
+
+	_, err := reconcileJob.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	retrievedJob := &appsv1alpha1.AdvancedCronJob{}
+	err = reconcileJob.Get(context.TODO(), request.NamespacedName, retrievedJob)
+	assert.NoError(t, err)
+	assert.Equal(t, retrievedJob.Status.Type, appsv1alpha1.EphemeralJobTemplateKind)
+
+	ephemeralJobList := &appsv1alpha1.EphemeralJobList{}
+	err = reconcileJob.List(context.TODO(), ephemeralJobList, client.InNamespace(request.Namespace))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(ephemeralJobList.Items))
+	// the ephemeral container name is suffixed with the scheduled time
+	ejob := &ephemeralJobList.Items[0]
+	scheduledTime, err := time.Parse(time.RFC3339, ejob.Annotations[scheduledTimeAnnotation])
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("debug-%d", scheduledTime.Unix()), ejob.Spec.Template.EphemeralContainers[0].Name)
+}
+
+func TestReconcileAdvancedJobEphemeralJobRunsExceeded(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = appsv1alpha1.AddToScheme(scheme)
+	_ = v1.AddToScheme(scheme)
+
+	job1 := createJob("job1", ephemeralJobTemplate())
+	job1.CreationTimestamp = metav1.NewTime(time.Now().Add(-90 * time.Second))
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-0", Namespace: "default", Labels: map[string]string{"app": "demo"}},
+	}
+	for i := 0; i < maxEphemeralJobRunsPerPod; i++ {
+		pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, v1.EphemeralContainer{
+			EphemeralContainerCommon: v1.EphemeralContainerCommon{Name: fmt.Sprintf("debug-%d", 1600000000+i*60), Image: "busybox"},
+		})
+	}
+	reconcileJob := createReconcileJob(scheme, job1, pod)
+
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "job1",
+			Namespace: "default",
+		},
+	}
+
+	_, err := reconcileJob.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	ephemeralJobList := &appsv1alpha1.EphemeralJobList{}
+	err = reconcileJob.List(context.TODO(), ephemeralJobList, client.InNamespace(request.Namespace))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(ephemeralJobList.Items))
+
+	// the containers not injected by the runs are not counted
+	pod.Spec.EphemeralContainers[0].Name = "other"
+	reconcileJob = createReconcileJob(scheme, job1, pod)
+	_, err = reconcileJob.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	err = reconcileJob.List(context.TODO(), ephemeralJobList, client.InNamespace(request.Namespace))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(ephemeralJobList.Items))
+}
+
 func createReconcileJob(scheme *runtime.Scheme, initObjs ...client.Object) ReconcileAdvancedCronJob {
 	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjs...).Build()
 	eventBroadcaster := record.NewBroadcaster()
@@ -194,3 +302,49 @@ func jobTemplate() appsv1alpha1.CronJobTemplate {
 		},
 	}
 }
+
+func imagePullJobTemplate() appsv1alpha1.CronJobTemplate {
+	return appsv1alpha1.CronJobTemplate{
+		ImagePullJobTemplate: &appsv1alpha1.ImagePullJobTemplateSpec{
+			Spec: appsv1alpha1.ImagePullJobSpec{
+				Image: "nginx:latest",
+			},
+		},
+	}
+}
+
+func ephemeralJobTemplate() appsv1alpha1.CronJobTemplate {
+	return appsv1alpha1.CronJobTemplate{
+		EphemeralJobTemplate: &appsv1alpha1.EphemeralJobTemplateSpec{
+			Spec: appsv1alpha1.EphemeralJobSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "demo"}},
+				Template: appsv1alpha1.EphemeralContainerTemplateSpec{
+					EphemeralContainers: []v1.EphemeralContainer{{
+						EphemeralContainerCommon: v1.EphemeralContainerCommon{Name: "debug", Image: "busybox"},
+					}},
+				},
+			},
+		},
+	}
+}
+
+func TestGetTimeZoneLocation(t *testing.T) {
+	cronJob := &appsv1alpha1.AdvancedCronJob{}
+	loc, err := getTimeZoneLocation(cronJob)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Local, loc)
+
+	timeZone := "Asia/Shanghai"
+	cronJob.Spec.TimeZone = &timeZone
+	loc, err = getTimeZoneLocation(cronJob)
+	assert.NoError(t, err)
+	// 09:00 in Asia/Shanghai is 01:00 in UTC
+	sched, err := cron.ParseStandard("0 9 * * *")
+	assert.NoError(t, err)
+	now := time.Date(2022, 1, 1, 0, 30, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), sched.Next(now.In(loc)).UTC())
+
+	timeZone = "Unknown/Zone"
+	_, err = getTimeZoneLocation(cronJob)
+	assert.Error(t, err)
+}
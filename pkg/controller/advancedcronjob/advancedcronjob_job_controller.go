@@ -217,6 +217,12 @@ func (r *ReconcileAdvancedCronJob) reconcileJob(ctx context.Context, req ctrl.Re
 		if err != nil {
 			return time.Time{}, time.Time{}, fmt.Errorf("Unparseable schedule %q: %v", cronJob.Spec.Schedule, err)
 		}
+		// the schedule is calculated in the time zone
+		loc, err := getTimeZoneLocation(cronJob)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("Unknown time zone %q: %v", *cronJob.Spec.TimeZone, err)
+		}
+		now = now.In(loc)
 
 		// for optimization purposes, cheat a bit and start from our last observed run time
 		// we could reconstitute this here, but there's not much point, since we've
@@ -235,6 +241,7 @@ func (r *ReconcileAdvancedCronJob) reconcileJob(ctx context.Context, req ctrl.Re
 				earliestTime = schedulingDeadline
 			}
 		}
+		earliestTime = earliestTime.In(loc)
 		if earliestTime.After(now) {
 			return time.Time{}, sched.Next(now), nil
 		}
@@ -1,11 +1,30 @@
 package advancedcronjob
 
-import appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+import (
+	"time"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+)
 
 func FindTemplateKind(spec appsv1alpha1.AdvancedCronJobSpec) appsv1alpha1.TemplateKind {
 	if spec.Template.JobTemplate != nil {
 		return appsv1alpha1.JobTemplate
 	}
+	if spec.Template.ImagePullJobTemplate != nil {
+		return appsv1alpha1.ImagePullJobTemplateKind
+	}
+	if spec.Template.EphemeralJobTemplate != nil {
+		return appsv1alpha1.EphemeralJobTemplateKind
+	}
 
 	return appsv1alpha1.BroadcastJobTemplate
 }
+
+// getTimeZoneLocation returns the location of the time zone for the schedule,
+// which is the local time zone of the controller if spec.timeZone is not specified.
+func getTimeZoneLocation(cronJob *appsv1alpha1.AdvancedCronJob) (*time.Location, error) {
+	if cronJob.Spec.TimeZone == nil {
+		return time.Local, nil
+	}
+	return time.LoadLocation(*cronJob.Spec.TimeZone)
+}
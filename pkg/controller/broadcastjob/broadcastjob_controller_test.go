@@ -23,6 +23,7 @@ import (
 	"testing"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/util/expectations"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/net/context"
 	v1 "k8s.io/api/core/v1"
@@ -665,3 +666,69 @@ func patchPodName(pod *v1.Pod) {
 		pod.Name = pod.GenerateName + string(uuid.NewUUID())
 	}
 }
+
+// Test scenario:
+// job with Never completionPolicy has completed on node1
+// node2 is added after the job started
+// the job runs a pod on node2 and keeps running, until it is changed to Always
+func TestJobNeverRunOnNewNodeUntilCompleted(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = appsv1alpha1.AddToScheme(scheme)
+	_ = v1.AddToScheme(scheme)
+
+	job := createJob("job11", intstr.FromInt(10))
+	job.Spec.CompletionPolicy.Type = appsv1alpha1.Never
+	job1Pod1onNode1 := createPod(job, "job1pod1node1", "node1", v1.PodSucceeded)
+	node1 := createNode("node1")
+	node2 := createNode("node2")
+
+	reconcileJob := createReconcileJob(scheme, job, job1Pod1onNode1, node1, node2)
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "job11",
+			Namespace: "default",
+		},
+	}
+
+	_, err := reconcileJob.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	retrievedJob := &appsv1alpha1.BroadcastJob{}
+	err = reconcileJob.Get(context.TODO(), request.NamespacedName, retrievedJob)
+	assert.NoError(t, err)
+
+	// a new pod is created on node2, and the job is still running
+	podList := &v1.PodList{}
+	err = reconcileJob.List(context.TODO(), podList, client.InNamespace(request.Namespace))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(podList.Items))
+	assert.Equal(t, int32(2), retrievedJob.Status.Desired)
+	assert.Equal(t, int32(1), retrievedJob.Status.Active)
+	assert.Equal(t, appsv1alpha1.PhaseRunning, retrievedJob.Status.Phase)
+
+	// observe the pod created, as the pod event handler does
+	scaleExpectations.ObserveScale(request.String(), expectations.Create, "node2")
+
+	// the job is still running after all pods succeeded
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		pod.Status.Phase = v1.PodSucceeded
+		assert.NoError(t, reconcileJob.Status().Update(context.TODO(), pod))
+	}
+	_, err = reconcileJob.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	err = reconcileJob.Get(context.TODO(), request.NamespacedName, retrievedJob)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), retrievedJob.Status.Succeeded)
+	assert.Equal(t, appsv1alpha1.PhaseRunning, retrievedJob.Status.Phase)
+	assert.Equal(t, 0, len(retrievedJob.Status.Conditions))
+
+	// the job is completed after changed to Always
+	retrievedJob.Spec.CompletionPolicy.Type = appsv1alpha1.Always
+	assert.NoError(t, reconcileJob.Update(context.TODO(), retrievedJob))
+	_, err = reconcileJob.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	err = reconcileJob.Get(context.TODO(), request.NamespacedName, retrievedJob)
+	assert.NoError(t, err)
+	assert.Equal(t, appsv1alpha1.PhaseCompleted, retrievedJob.Status.Phase)
+	assert.Equal(t, appsv1alpha1.JobComplete, retrievedJob.Status.Conditions[len(retrievedJob.Status.Conditions)-1].Type)
+}
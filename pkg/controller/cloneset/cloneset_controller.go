@@ -39,6 +39,7 @@ import (
 	imagejobutilfunc "github.com/openkruise/kruise/pkg/util/imagejob/utilfunction"
 	"github.com/openkruise/kruise/pkg/util/ratelimiter"
 	"github.com/openkruise/kruise/pkg/util/refmanager"
+	"github.com/openkruise/kruise/pkg/util/revisiondiff"
 	apps "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -70,6 +71,7 @@ var (
 
 	isPreDownloadDisabled             bool
 	minimumReplicasToPreDownloadImage int32 = 3
+	imagePreDownloadCheckInterval           = 5 * time.Second
 )
 
 // Add creates a new CloneSet Controller and adds it to the Manager with default RBAC. The Manager will set fields on the Controller
@@ -100,7 +102,7 @@ func newReconciler(mgr manager.Manager) reconcile.Reconciler {
 		Client:            cli,
 		scheme:            mgr.GetScheme(),
 		recorder:          recorder,
-		statusUpdater:     newStatusUpdater(cli),
+		statusUpdater:     newStatusUpdater(cli, recorder),
 		controllerHistory: historyutil.NewHistory(cli),
 		revisionControl:   revisioncontrol.NewRevisionControl(),
 	}
@@ -169,6 +171,7 @@ type ReconcileCloneSet struct {
 // +kubebuilder:rbac:groups=core,resources=pods/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
 // +kubebuilder:rbac:groups=apps,resources=controllerrevisions,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps.kruise.io,resources=clonesets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps.kruise.io,resources=clonesets/status,verbs=get;update;patch
@@ -204,6 +207,7 @@ func (r *ReconcileCloneSet) doReconcile(request reconcile.Request) (res reconcil
 			// For additional cleanup logic use finalizers.
 			klog.V(3).Infof("CloneSet %s has been deleted.", request)
 			clonesetutils.ScaleExpectations.DeleteExpectations(request.String())
+			clonesetutils.DeleteMetrics(request.Namespace, request.Name)
 			return reconcile.Result{}, nil
 		}
 		return reconcile.Result{}, err
@@ -251,6 +255,11 @@ func (r *ReconcileCloneSet) doReconcile(request reconcile.Request) (res reconcil
 	}
 	history.SortControllerRevisions(revisions)
 
+	// flip the template back to the revision to roll back
+	if rolled, err := r.syncRollbackTemplate(instance, revisions); err != nil || rolled {
+		return reconcile.Result{}, err
+	}
+
 	// get the current, and update revisions
 	currentRevision, updateRevision, collisionCount, err := r.getActiveRevisions(instance, revisions)
 	if err != nil {
@@ -288,6 +297,13 @@ func (r *ReconcileCloneSet) doReconcile(request reconcile.Request) (res reconcil
 	}
 	*newStatus.CollisionCount = collisionCount
 
+	// reuse the diff in status if the revisions have not changed
+	if instance.Status.CurrentRevision == currentRevision.Name && instance.Status.UpdateRevision == updateRevision.Name && instance.Status.RevisionDiff != nil {
+		newStatus.RevisionDiff = instance.Status.RevisionDiff.DeepCopy()
+	} else if newStatus.RevisionDiff, err = revisiondiff.Calculate(currentRevision, updateRevision); err != nil {
+		klog.Warningf("Failed to calculate revision diff for %s: %v", request, err)
+	}
+
 	if !isPreDownloadDisabled {
 		if currentRevision.Name != updateRevision.Name {
 			// get clone pre-download annotation
@@ -320,10 +336,23 @@ func (r *ReconcileCloneSet) doReconcile(request reconcile.Request) (res reconcil
 	// scale and update pods
 	syncErr := r.syncCloneSet(instance, &newStatus, currentRevision, updateRevision, revisions, filteredPods, filteredPVCs)
 
+	// expand the existing pvcs to volumeClaimTemplates
+	if err = r.syncVolumeClaimExpansion(instance, &newStatus, filteredPVCs); err != nil {
+		klog.Errorf("Failed to expand pvcs for %s: %v", request, err)
+		if syncErr == nil {
+			syncErr = err
+		}
+	}
+
 	// update new status
 	if err = r.statusUpdater.UpdateCloneSetStatus(instance, &newStatus, filteredPods); err != nil {
 		return reconcile.Result{}, err
 	}
+	clonesetutils.RecordRolloutMetrics(instance, &newStatus, filteredPods, updateRevision)
+
+	if err = r.completeRollback(instance, &newStatus); err != nil {
+		klog.Errorf("Failed to complete rollback for %s: %v", request, err)
+	}
 
 	if err = r.truncatePodsToDelete(instance, filteredPods); err != nil {
 		klog.Warningf("Failed to truncate podsToDelete for %s: %v", request, err)
@@ -376,6 +405,14 @@ func (r *ReconcileCloneSet) syncCloneSet(
 		return podsScaleErr
 	}
 
+	// wait for the images pre-downloaded before updating the first pods
+	if gated, err := r.isUpdateGatedByImagePreDownload(instance, updateRevision, filteredPods); err != nil {
+		klog.Errorf("Failed to check image pre-download for CloneSet %s/%s: %v", instance.Namespace, instance.Name, err)
+	} else if gated {
+		clonesetutils.DurationStore.Push(clonesetutils.GetControllerKey(instance), imagePreDownloadCheckInterval)
+		return podsScaleErr
+	}
+
 	podsUpdateErr = r.syncControl.Update(updateSet, currentRevision, updateRevision, revisions, filteredPods, filteredPVCs)
 	if podsUpdateErr != nil {
 		newStatus.Conditions = append(newStatus.Conditions, appsv1alpha1.CloneSetCondition{
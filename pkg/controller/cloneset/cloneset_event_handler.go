@@ -251,6 +251,17 @@ func (e *pvcEventHandler) Update(evt event.UpdateEvent, q workqueue.RateLimiting
 	pvc := evt.ObjectNew.(*v1.PersistentVolumeClaim)
 	if pvc.DeletionTimestamp != nil {
 		e.Delete(event.DeleteEvent{Object: evt.ObjectNew}, q)
+		return
+	}
+
+	// enqueue the owner to refresh the expansion progress in status
+	oldPVC := evt.ObjectOld.(*v1.PersistentVolumeClaim)
+	if !reflect.DeepEqual(oldPVC.Status.Capacity, pvc.Status.Capacity) || oldPVC.Status.Phase != pvc.Status.Phase {
+		if controllerRef := metav1.GetControllerOf(pvc); controllerRef != nil {
+			if req := resolveControllerRef(pvc.Namespace, controllerRef); req != nil {
+				q.Add(*req)
+			}
+		}
 	}
 }
 
@@ -23,7 +23,6 @@ import (
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
 	clonesetcore "github.com/openkruise/kruise/pkg/controller/cloneset/core"
 	clonesetutils "github.com/openkruise/kruise/pkg/controller/cloneset/utils"
-	"github.com/openkruise/kruise/pkg/util"
 	imagejobutilfunc "github.com/openkruise/kruise/pkg/util/imagejob/utilfunction"
 	"github.com/openkruise/kruise/pkg/util/inplaceupdate"
 	apps "k8s.io/api/apps/v1"
@@ -61,7 +60,7 @@ func (r *ReconcileCloneSet) createImagePullJobsForInPlaceUpdate(cs *appsv1alpha1
 	// ignore if all Pods update in one batch
 	var partition, maxUnavailable int
 	if cs.Spec.UpdateStrategy.Partition != nil {
-		if pValue, err := util.CalculatePartitionReplicas(cs.Spec.UpdateStrategy.Partition, cs.Spec.Replicas); err != nil {
+		if pValue, err := clonesetutils.CalculatePartitionReplicas(cs); err != nil {
 			klog.Errorf("CloneSet %s/%s partition value is illegal", cs.Namespace, cs.Name)
 			return err
 		} else {
@@ -70,7 +69,7 @@ func (r *ReconcileCloneSet) createImagePullJobsForInPlaceUpdate(cs *appsv1alpha1
 	}
 	maxUnavailable, _ = intstrutil.GetValueFromIntOrPercent(
 		intstrutil.ValueOrDefault(cs.Spec.UpdateStrategy.MaxUnavailable, intstrutil.FromString(appsv1alpha1.DefaultCloneSetMaxUnavailable)), int(*cs.Spec.Replicas), false)
-	if partition == 0 && maxUnavailable >= int(*cs.Spec.Replicas) {
+	if partition == 0 && maxUnavailable >= int(*cs.Spec.Replicas) && len(cs.Spec.UpdateStrategy.Steps) == 0 {
 		klog.V(4).Infof("CloneSet %s/%s skipped to create ImagePullJob for all Pods update in one batch, replicas=%d, partition=%d, maxUnavailable=%d",
 			cs.Namespace, cs.Name, *cs.Spec.Replicas, partition, maxUnavailable)
 		return r.patchControllerRevisionLabels(updateRevision, appsv1alpha1.ImagePreDownloadIgnoredKey, "true")
@@ -92,6 +91,15 @@ func (r *ReconcileCloneSet) createImagePullJobsForInPlaceUpdate(cs *appsv1alpha1
 		pullSecrets = append(pullSecrets, s.Name)
 	}
 
+	// pull images on the nodes matching the scheduling hints of template, if the scope is Affinity
+	var nodeSelector *metav1.LabelSelector
+	if cs.Annotations[appsv1alpha1.ImagePreDownloadScopeKey] == appsv1alpha1.ImagePreDownloadScopeAffinity {
+		if nodeSelector = imagejobutilfunc.GetNodeSelectorFromPodSpec(&cs.Spec.Template.Spec); nodeSelector == nil {
+			klog.Warningf("CloneSet %s/%s can not convert scheduling hints into node selector, fall back to pre-download on nodes of pods",
+				cs.Namespace, cs.Name)
+		}
+	}
+
 	selector := cs.Spec.Selector.DeepCopy()
 	selector.MatchExpressions = append(selector.MatchExpressions, metav1.LabelSelectorRequirement{
 		Key:      apps.ControllerRevisionHashLabelKey,
@@ -114,7 +122,12 @@ func (r *ReconcileCloneSet) createImagePullJobsForInPlaceUpdate(cs *appsv1alpha1
 	for name, image := range containerImages {
 		// job name is revision name + container name, it can not be more than 255 characters
 		jobName := fmt.Sprintf("%s-%s", updateRevision.Name, name)
-		err := imagejobutilfunc.CreateJobForWorkload(r.Client, cs, clonesetutils.ControllerKind, jobName, image, labelMap, *selector, pullSecrets)
+		var err error
+		if nodeSelector != nil {
+			err = imagejobutilfunc.CreateJobForWorkloadOnNodes(r.Client, cs, clonesetutils.ControllerKind, jobName, image, labelMap, *nodeSelector, pullSecrets)
+		} else {
+			err = imagejobutilfunc.CreateJobForWorkload(r.Client, cs, clonesetutils.ControllerKind, jobName, image, labelMap, *selector, pullSecrets)
+		}
 		if err != nil {
 			if !errors.IsAlreadyExists(err) {
 				klog.Errorf("CloneSet %s/%s failed to create ImagePullJob %s: %v", cs.Namespace, cs.Name, jobName, err)
@@ -129,6 +142,57 @@ func (r *ReconcileCloneSet) createImagePullJobsForInPlaceUpdate(cs *appsv1alpha1
 	return r.patchControllerRevisionLabels(updateRevision, appsv1alpha1.ImagePreDownloadCreatedKey, "true")
 }
 
+// isUpdateGatedByImagePreDownload returns true if the first pods should not be updated to the new revision,
+// for the ImagePullJobs of it have not reached the completion threshold yet.
+func (r *ReconcileCloneSet) isUpdateGatedByImagePreDownload(cs *appsv1alpha1.CloneSet, updateRevision *apps.ControllerRevision, pods []*v1.Pod) (bool, error) {
+	thresholdStr, ok := cs.Annotations[appsv1alpha1.ImagePreDownloadCompletionThresholdKey]
+	if isPreDownloadDisabled || !ok {
+		return false, nil
+	}
+	if _, ok := updateRevision.Labels[appsv1alpha1.ImagePreDownloadCreatedKey]; !ok {
+		return false, nil
+	}
+	// only gate the first batch
+	for _, pod := range pods {
+		if clonesetutils.RevisionAdapterImpl.EqualToRevisionHash("", pod, updateRevision.Name) {
+			return false, nil
+		}
+	}
+
+	jobList := &appsv1alpha1.ImagePullJobList{}
+	if err := r.List(context.TODO(), jobList, client.InNamespace(cs.Namespace),
+		client.MatchingLabels{history.ControllerRevisionHashLabel: updateRevision.Labels[history.ControllerRevisionHashLabel]}); err != nil {
+		return false, err
+	}
+
+	threshold := intstrutil.Parse(thresholdStr)
+	for i := range jobList.Items {
+		job := &jobList.Items[i]
+		if owner := metav1.GetControllerOf(job); owner == nil || owner.UID != cs.UID {
+			continue
+		}
+		if job.Status.CompletionTime != nil {
+			continue
+		}
+		if job.Status.StartTime == nil {
+			return true, nil
+		}
+		expected, err := intstrutil.GetScaledValueFromIntOrPercent(&threshold, int(job.Status.Desired), true)
+		if err != nil {
+			return false, fmt.Errorf("invalid %s %s: %v", appsv1alpha1.ImagePreDownloadCompletionThresholdKey, thresholdStr, err)
+		}
+		if expected > int(job.Status.Desired) {
+			expected = int(job.Status.Desired)
+		}
+		if int(job.Status.Succeeded) < expected {
+			klog.V(4).Infof("CloneSet %s/%s update gated by ImagePullJob %s, succeeded %d < %d",
+				cs.Namespace, cs.Name, job.Name, job.Status.Succeeded, expected)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func (r *ReconcileCloneSet) patchControllerRevisionLabels(revision *apps.ControllerRevision, key, value string) error {
 	oldRevision := revision.ResourceVersion
 	body := fmt.Sprintf(`{"metadata":{"labels":{"%s":"%s"}}}`, key, value)
@@ -0,0 +1,119 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloneset
+
+import (
+	"testing"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/kubernetes/pkg/controller/history"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestIsUpdateGatedByImagePreDownload(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = appsv1alpha1.AddToScheme(scheme)
+	_ = v1.AddToScheme(scheme)
+
+	cs := &appsv1alpha1.CloneSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "foo",
+			UID:         "uid",
+			Annotations: map[string]string{appsv1alpha1.ImagePreDownloadCompletionThresholdKey: "50%"},
+		},
+	}
+	cs.SetGroupVersionKind(appsv1alpha1.SchemeGroupVersion.WithKind("CloneSet"))
+	updateRevision := &apps.ControllerRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "foo-rev2",
+			Labels: map[string]string{
+				history.ControllerRevisionHashLabel:     "rev2",
+				appsv1alpha1.ImagePreDownloadCreatedKey: "true",
+			},
+		},
+	}
+	newJob := func(status appsv1alpha1.ImagePullJobStatus) *appsv1alpha1.ImagePullJob {
+		return &appsv1alpha1.ImagePullJob{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       "default",
+				Name:            "foo-rev2-main",
+				Labels:          map[string]string{history.ControllerRevisionHashLabel: "rev2"},
+				OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(cs, cs.GroupVersionKind())},
+			},
+			Status: status,
+		}
+	}
+	now := metav1.Now()
+	oldPod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo-a", Labels: map[string]string{apps.ControllerRevisionHashLabelKey: "foo-rev1"}}}
+	newPod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo-b", Labels: map[string]string{apps.ControllerRevisionHashLabelKey: "foo-rev2"}}}
+
+	cases := []struct {
+		name     string
+		job      *appsv1alpha1.ImagePullJob
+		pods     []*v1.Pod
+		expected bool
+	}{
+		{
+			name:     "job not started",
+			job:      newJob(appsv1alpha1.ImagePullJobStatus{}),
+			pods:     []*v1.Pod{oldPod},
+			expected: true,
+		},
+		{
+			name:     "job below threshold",
+			job:      newJob(appsv1alpha1.ImagePullJobStatus{StartTime: &now, Desired: 4, Succeeded: 1}),
+			pods:     []*v1.Pod{oldPod},
+			expected: true,
+		},
+		{
+			name:     "job reached threshold",
+			job:      newJob(appsv1alpha1.ImagePullJobStatus{StartTime: &now, Desired: 4, Succeeded: 2}),
+			pods:     []*v1.Pod{oldPod},
+			expected: false,
+		},
+		{
+			name:     "job completed",
+			job:      newJob(appsv1alpha1.ImagePullJobStatus{StartTime: &now, CompletionTime: &now, Desired: 4, Succeeded: 1}),
+			pods:     []*v1.Pod{oldPod},
+			expected: false,
+		},
+		{
+			name:     "first batch updated",
+			job:      newJob(appsv1alpha1.ImagePullJobStatus{}),
+			pods:     []*v1.Pod{oldPod, newPod},
+			expected: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &ReconcileCloneSet{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.job).Build()}
+			gated, err := r.isUpdateGatedByImagePreDownload(cs, updateRevision, tc.pods)
+			if err != nil {
+				t.Fatalf("failed to check gate: %v", err)
+			}
+			if gated != tc.expected {
+				t.Fatalf("expected gated %v, got %v", tc.expected, gated)
+			}
+		})
+	}
+}
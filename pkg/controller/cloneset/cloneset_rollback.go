@@ -0,0 +1,90 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloneset
+
+import (
+	"context"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// syncRollbackTemplate flips the template back to the revision specified in rollbackTo.
+// It returns true if the template has been updated, then the CloneSet will be reconciled again for the update event.
+func (r *ReconcileCloneSet) syncRollbackTemplate(cs *appsv1alpha1.CloneSet, revisions []*apps.ControllerRevision) (bool, error) {
+	rollback := cs.Spec.UpdateStrategy.RollbackTo
+	if rollback == nil || cs.DeletionTimestamp != nil {
+		return false, nil
+	}
+
+	var target *apps.ControllerRevision
+	for i := range revisions {
+		if revisions[i].Name == rollback.Revision {
+			target = revisions[i]
+			break
+		}
+	}
+	if target == nil {
+		r.recorder.Eventf(cs, v1.EventTypeWarning, "RollbackRevisionNotFound", "revision %s to roll back is not found", rollback.Revision)
+		return false, nil
+	}
+
+	restored, err := r.revisionControl.ApplyRevision(cs, target)
+	if err != nil {
+		return false, err
+	}
+	if apiequality.Semantic.DeepEqual(restored.Spec.Template, cs.Spec.Template) {
+		return false, nil
+	}
+
+	klog.Infof("CloneSet %s/%s is rolling back template to revision %s", cs.Namespace, cs.Name, rollback.Revision)
+	err = retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		clone := &appsv1alpha1.CloneSet{}
+		if err := r.Get(context.TODO(), types.NamespacedName{Namespace: cs.Namespace, Name: cs.Name}, clone); err != nil {
+			return err
+		}
+		clone.Spec.Template = restored.Spec.Template
+		return r.Update(context.TODO(), clone)
+	})
+	if err != nil {
+		return false, err
+	}
+	r.recorder.Eventf(cs, v1.EventTypeNormal, "RollingBack", "rolling back template to revision %s", rollback.Revision)
+	return true, nil
+}
+
+// completeRollback clears rollbackTo once all pods have been updated to the revision.
+func (r *ReconcileCloneSet) completeRollback(cs *appsv1alpha1.CloneSet, newStatus *appsv1alpha1.CloneSetStatus) error {
+	rollback := cs.Spec.UpdateStrategy.RollbackTo
+	if rollback == nil || newStatus.UpdateRevision != rollback.Revision || newStatus.CurrentRevision != newStatus.UpdateRevision {
+		return nil
+	}
+
+	body := `{"spec":{"updateStrategy":{"rollbackTo":null}}}`
+	if err := r.Patch(context.TODO(), cs.DeepCopy(), client.RawPatch(types.MergePatchType, []byte(body))); err != nil {
+		return err
+	}
+	klog.Infof("CloneSet %s/%s has completed rolling back to revision %s", cs.Namespace, cs.Name, rollback.Revision)
+	r.recorder.Eventf(cs, v1.EventTypeNormal, "RollbackCompleted", "all pods have been rolled back to revision %s", rollback.Revision)
+	return nil
+}
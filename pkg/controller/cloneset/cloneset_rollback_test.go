@@ -0,0 +1,105 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloneset
+
+import (
+	"context"
+	"testing"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	revisioncontrol "github.com/openkruise/kruise/pkg/controller/cloneset/revision"
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRollback(t *testing.T) {
+	// revision patches are encoded with the global scheme
+	scheme := clientgoscheme.Scheme
+	_ = appsv1alpha1.AddToScheme(scheme)
+
+	newCloneSet := func(image string) *appsv1alpha1.CloneSet {
+		return &appsv1alpha1.CloneSet{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo", UID: "uid"},
+			Spec: appsv1alpha1.CloneSetSpec{
+				Template: v1.PodTemplateSpec{
+					Spec: v1.PodSpec{Containers: []v1.Container{{Name: "main", Image: image}}},
+				},
+			},
+		}
+	}
+
+	revisionControl := revisioncontrol.NewRevisionControl()
+	var collisionCount int32
+	oldRevision, err := revisionControl.NewRevision(newCloneSet("nginx:1"), 1, &collisionCount)
+	if err != nil {
+		t.Fatalf("failed to create revision: %v", err)
+	}
+
+	cs := newCloneSet("nginx:2")
+	cs.Spec.UpdateStrategy.RollbackTo = &appsv1alpha1.CloneSetRollback{Revision: oldRevision.Name}
+	r := &ReconcileCloneSet{
+		Client:          fake.NewClientBuilder().WithScheme(scheme).WithObjects(cs.DeepCopy()).Build(),
+		recorder:        record.NewFakeRecorder(10),
+		revisionControl: revisionControl,
+	}
+
+	// flip the template back to the old revision
+	rolled, err := r.syncRollbackTemplate(cs, []*apps.ControllerRevision{oldRevision})
+	if err != nil || !rolled {
+		t.Fatalf("expected template rolled back, got %v, %v", rolled, err)
+	}
+	updated := &appsv1alpha1.CloneSet{}
+	if err := r.Get(context.TODO(), client.ObjectKeyFromObject(cs), updated); err != nil {
+		t.Fatalf("failed to get cloneset: %v", err)
+	}
+	if image := updated.Spec.Template.Spec.Containers[0].Image; image != "nginx:1" {
+		t.Fatalf("expected image nginx:1, got %s", image)
+	}
+
+	// no more flip for the template has been rolled back
+	if rolled, err = r.syncRollbackTemplate(updated, []*apps.ControllerRevision{oldRevision}); err != nil || rolled {
+		t.Fatalf("expected template not rolled back again, got %v, %v", rolled, err)
+	}
+
+	// keep rollbackTo until all pods updated
+	newStatus := &appsv1alpha1.CloneSetStatus{CurrentRevision: "nginx-2", UpdateRevision: oldRevision.Name}
+	if err := r.completeRollback(updated, newStatus); err != nil {
+		t.Fatalf("failed to complete rollback: %v", err)
+	}
+	if err := r.Get(context.TODO(), client.ObjectKeyFromObject(cs), updated); err != nil {
+		t.Fatalf("failed to get cloneset: %v", err)
+	}
+	if updated.Spec.UpdateStrategy.RollbackTo == nil {
+		t.Fatalf("expected rollbackTo kept before all pods updated")
+	}
+
+	newStatus.CurrentRevision = oldRevision.Name
+	if err := r.completeRollback(updated, newStatus); err != nil {
+		t.Fatalf("failed to complete rollback: %v", err)
+	}
+	if err := r.Get(context.TODO(), client.ObjectKeyFromObject(cs), updated); err != nil {
+		t.Fatalf("failed to get cloneset: %v", err)
+	}
+	if updated.Spec.UpdateStrategy.RollbackTo != nil {
+		t.Fatalf("expected rollbackTo cleared after all pods updated, got %+v", updated.Spec.UpdateStrategy.RollbackTo)
+	}
+}
@@ -18,13 +18,20 @@ package cloneset
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"time"
 
+	appspub "github.com/openkruise/kruise/apis/apps/pub"
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
 	clonesetcore "github.com/openkruise/kruise/pkg/controller/cloneset/core"
 	clonesetutils "github.com/openkruise/kruise/pkg/controller/cloneset/utils"
-	"github.com/openkruise/kruise/pkg/util"
+	"github.com/openkruise/kruise/pkg/util/lifecycle"
 	v1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -35,12 +42,13 @@ type StatusUpdater interface {
 	UpdateCloneSetStatus(cs *appsv1alpha1.CloneSet, newStatus *appsv1alpha1.CloneSetStatus, pods []*v1.Pod) error
 }
 
-func newStatusUpdater(c client.Client) StatusUpdater {
-	return &realStatusUpdater{Client: c}
+func newStatusUpdater(c client.Client, recorder record.EventRecorder) StatusUpdater {
+	return &realStatusUpdater{Client: c, recorder: recorder}
 }
 
 type realStatusUpdater struct {
 	client.Client
+	recorder record.EventRecorder
 }
 
 func (r *realStatusUpdater) UpdateCloneSetStatus(cs *appsv1alpha1.CloneSet, newStatus *appsv1alpha1.CloneSetStatus, pods []*v1.Pod) error {
@@ -78,7 +86,31 @@ func (r *realStatusUpdater) inconsistentStatus(cs *appsv1alpha1.CloneSet, newSta
 		newStatus.UpdatedReplicas != oldStatus.UpdatedReplicas ||
 		newStatus.UpdateRevision != oldStatus.UpdateRevision ||
 		newStatus.CurrentRevision != oldStatus.CurrentRevision ||
-		newStatus.LabelSelector != oldStatus.LabelSelector
+		newStatus.LabelSelector != oldStatus.LabelSelector ||
+		!apiequality.Semantic.DeepEqual(newStatus.CanaryStatus, oldStatus.CanaryStatus) ||
+		!apiequality.Semantic.DeepEqual(newStatus.VolumeClaimTemplates, oldStatus.VolumeClaimTemplates) ||
+		!apiequality.Semantic.DeepEqual(newStatus.RevisionDiff, oldStatus.RevisionDiff) ||
+		inconsistentCondition(newStatus, &oldStatus, appsv1alpha1.CloneSetConditionLifecycleHookTimeout) ||
+		inconsistentCondition(newStatus, &oldStatus, appsv1alpha1.CloneSetConditionProgressing) ||
+		inconsistentCondition(newStatus, &oldStatus, appsv1alpha1.CloneSetConditionDegraded)
+}
+
+func inconsistentCondition(newStatus, oldStatus *appsv1alpha1.CloneSetStatus, condType appsv1alpha1.CloneSetConditionType) bool {
+	newCondition := getCloneSetCondition(newStatus, condType)
+	oldCondition := getCloneSetCondition(oldStatus, condType)
+	if newCondition == nil || oldCondition == nil {
+		return newCondition != oldCondition
+	}
+	return newCondition.Status != oldCondition.Status || newCondition.Message != oldCondition.Message
+}
+
+func getCloneSetCondition(status *appsv1alpha1.CloneSetStatus, condType appsv1alpha1.CloneSetConditionType) *appsv1alpha1.CloneSetCondition {
+	for i := range status.Conditions {
+		if status.Conditions[i].Type == condType {
+			return &status.Conditions[i]
+		}
+	}
+	return nil
 }
 
 func (r *realStatusUpdater) calculateStatus(cs *appsv1alpha1.CloneSet, newStatus *appsv1alpha1.CloneSetStatus, pods []*v1.Pod) {
@@ -101,13 +133,219 @@ func (r *realStatusUpdater) calculateStatus(cs *appsv1alpha1.CloneSet, newStatus
 	// Consider the update revision as stable if revisions of all pods are consistent to it, no need to wait all of them ready
 	if newStatus.UpdatedReplicas == newStatus.Replicas {
 		newStatus.CurrentRevision = newStatus.UpdateRevision
+		newStatus.RevisionDiff = nil
 	}
 
 	if newStatus.UpdateRevision == newStatus.CurrentRevision {
 		newStatus.ExpectedUpdatedReplicas = *cs.Spec.Replicas
 	} else {
-		if partition, err := util.CalculatePartitionReplicas(cs.Spec.UpdateStrategy.Partition, cs.Spec.Replicas); err == nil {
+		if partition, err := clonesetutils.CalculateUpdatePartitionReplicas(cs, newStatus.UpdateRevision); err == nil {
 			newStatus.ExpectedUpdatedReplicas = *cs.Spec.Replicas - int32(partition)
 		}
 	}
+
+	r.calculateCanaryStatus(cs, newStatus)
+	r.calculateLifecycleHookTimeout(cs, newStatus, pods)
+	r.calculateProgress(cs, newStatus)
+}
+
+// calculateProgress sets the Progressing and Degraded conditions, if the CloneSet has progressDeadlineSeconds.
+func (r *realStatusUpdater) calculateProgress(cs *appsv1alpha1.CloneSet, newStatus *appsv1alpha1.CloneSetStatus) {
+	if cs.Spec.ProgressDeadlineSeconds == nil {
+		return
+	}
+
+	now := metav1.Now()
+	oldCondition := getCloneSetCondition(&cs.Status, appsv1alpha1.CloneSetConditionProgressing)
+	condition := appsv1alpha1.CloneSetCondition{
+		Type:               appsv1alpha1.CloneSetConditionProgressing,
+		Status:             v1.ConditionTrue,
+		LastUpdateTime:     now,
+		LastTransitionTime: now,
+		Reason:             appsv1alpha1.CloneSetProgressingReason,
+		Message:            fmt.Sprintf("CloneSet is progressing to revision %s", newStatus.UpdateRevision),
+	}
+
+	switch {
+	case cs.Spec.UpdateStrategy.Paused ||
+		(newStatus.CanaryStatus != nil && newStatus.CanaryStatus.CurrentStepState == appsv1alpha1.CloneSetCanaryStepPaused):
+		condition.Status = v1.ConditionUnknown
+		condition.Reason = appsv1alpha1.CloneSetPausedReason
+		condition.Message = "CloneSet is paused"
+	case newStatus.Replicas == *cs.Spec.Replicas && newStatus.AvailableReplicas >= *cs.Spec.Replicas &&
+		newStatus.UpdatedReadyReplicas >= newStatus.ExpectedUpdatedReplicas:
+		condition.Reason = appsv1alpha1.CloneSetAvailableReason
+		condition.Message = fmt.Sprintf("CloneSet has successfully progressed to revision %s", newStatus.UpdateRevision)
+	case oldCondition == nil || oldCondition.Reason == appsv1alpha1.CloneSetPausedReason ||
+		oldCondition.Reason == appsv1alpha1.CloneSetAvailableReason || hasProgressed(&cs.Status, newStatus):
+		// a new rollout starts or progress has been made
+	default:
+		// no progress since last update, check if the deadline has been exceeded
+		condition = *oldCondition
+		deadline := oldCondition.LastUpdateTime.Add(time.Duration(*cs.Spec.ProgressDeadlineSeconds) * time.Second)
+		if left := deadline.Sub(now.Time); left > 0 {
+			clonesetutils.DurationStore.Push(clonesetutils.GetControllerKey(cs), left)
+		} else {
+			condition.Status = v1.ConditionFalse
+			condition.Reason = appsv1alpha1.CloneSetProgressDeadlineExceededReason
+			condition.Message = fmt.Sprintf("CloneSet has not progressed to revision %s for %d seconds", newStatus.UpdateRevision, *cs.Spec.ProgressDeadlineSeconds)
+		}
+	}
+	if oldCondition != nil && oldCondition.Status == condition.Status {
+		condition.LastTransitionTime = oldCondition.LastTransitionTime
+	}
+	newStatus.Conditions = append(newStatus.Conditions, condition)
+
+	if condition.Reason != appsv1alpha1.CloneSetProgressDeadlineExceededReason {
+		return
+	}
+	degraded := appsv1alpha1.CloneSetCondition{
+		Type:               appsv1alpha1.CloneSetConditionDegraded,
+		Status:             v1.ConditionTrue,
+		LastUpdateTime:     now,
+		LastTransitionTime: now,
+		Reason:             condition.Reason,
+		Message:            condition.Message,
+	}
+	if oldDegraded := getCloneSetCondition(&cs.Status, degraded.Type); oldDegraded != nil && oldDegraded.Status == degraded.Status {
+		degraded.LastUpdateTime = oldDegraded.LastUpdateTime
+		degraded.LastTransitionTime = oldDegraded.LastTransitionTime
+	} else {
+		klog.Warningf("CloneSet %s/%s progress deadline exceeded: %s", cs.Namespace, cs.Name, condition.Message)
+		if r.recorder != nil {
+			r.recorder.Event(cs, v1.EventTypeWarning, appsv1alpha1.CloneSetProgressDeadlineExceededReason, condition.Message)
+		}
+	}
+	newStatus.Conditions = append(newStatus.Conditions, degraded)
+}
+
+// hasProgressed returns whether there are more pods updated or available, or the update revision has changed.
+func hasProgressed(oldStatus, newStatus *appsv1alpha1.CloneSetStatus) bool {
+	return newStatus.UpdateRevision != oldStatus.UpdateRevision ||
+		newStatus.UpdatedReplicas > oldStatus.UpdatedReplicas ||
+		newStatus.UpdatedReadyReplicas > oldStatus.UpdatedReadyReplicas ||
+		newStatus.AvailableReplicas > oldStatus.AvailableReplicas
+}
+
+// calculateLifecycleHookTimeout reports the pods blocked by lifecycle hooks that have timed out with Block policy.
+func (r *realStatusUpdater) calculateLifecycleHookTimeout(cs *appsv1alpha1.CloneSet, newStatus *appsv1alpha1.CloneSetStatus, pods []*v1.Pod) {
+	if cs.Spec.Lifecycle == nil {
+		return
+	}
+
+	var blockedPods []string
+	for _, pod := range pods {
+		var hook *appspub.LifecycleHook
+		switch lifecycle.GetPodLifecycleState(pod) {
+		case appspub.LifecycleStatePreparingDelete:
+			if lifecycle.IsPodHooked(cs.Spec.Lifecycle.PreDelete, pod) {
+				hook = cs.Spec.Lifecycle.PreDelete
+			}
+		case appspub.LifecycleStatePreparingUpdate:
+			if lifecycle.IsPodHooked(cs.Spec.Lifecycle.InPlaceUpdate, pod) {
+				hook = cs.Spec.Lifecycle.InPlaceUpdate
+			}
+		case appspub.LifecycleStateUpdated:
+			if cs.Spec.Lifecycle.InPlaceUpdate != nil && !lifecycle.IsPodAllHooked(cs.Spec.Lifecycle.InPlaceUpdate, pod) {
+				hook = cs.Spec.Lifecycle.InPlaceUpdate
+			}
+		}
+		if hook == nil || hook.FailurePolicy == appspub.LifecycleHookFailurePolicyContinue {
+			continue
+		}
+		if timeout, _ := lifecycle.IsHookTimeout(hook, pod); timeout {
+			blockedPods = append(blockedPods, pod.Name)
+		}
+	}
+	if len(blockedPods) == 0 {
+		return
+	}
+
+	sort.Strings(blockedPods)
+	condition := appsv1alpha1.CloneSetCondition{
+		Type:               appsv1alpha1.CloneSetConditionLifecycleHookTimeout,
+		Status:             v1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "HookTimeout",
+		Message:            fmt.Sprintf("pods %v are blocked by lifecycle hooks that have timed out", blockedPods),
+	}
+	if oldCondition := getCloneSetCondition(&cs.Status, condition.Type); oldCondition != nil && oldCondition.Status == condition.Status {
+		condition.LastTransitionTime = oldCondition.LastTransitionTime
+	}
+	newStatus.Conditions = append(newStatus.Conditions, condition)
+}
+
+// calculateCanaryStatus moves the canary step forward when the updated pods of current step are ready and its pause is over.
+func (r *realStatusUpdater) calculateCanaryStatus(cs *appsv1alpha1.CloneSet, newStatus *appsv1alpha1.CloneSetStatus) {
+	steps := cs.Spec.UpdateStrategy.Steps
+	if len(steps) == 0 || newStatus.UpdateRevision == newStatus.CurrentRevision || clonesetutils.IsRollingBack(cs, newStatus.UpdateRevision) {
+		return
+	}
+
+	now := metav1.Now()
+	var canaryStatus *appsv1alpha1.CloneSetCanaryStatus
+	if cs.Status.CanaryStatus != nil && cs.Status.CanaryStatus.Revision == newStatus.UpdateRevision {
+		canaryStatus = cs.Status.CanaryStatus.DeepCopy()
+	} else {
+		canaryStatus = &appsv1alpha1.CloneSetCanaryStatus{
+			Revision:           newStatus.UpdateRevision,
+			CurrentStepState:   appsv1alpha1.CloneSetCanaryStepUpgrading,
+			LastTransitionTime: now,
+		}
+	}
+	newStatus.CanaryStatus = canaryStatus
+
+	stepIndex := int(canaryStatus.CurrentStepIndex)
+	if stepIndex >= len(steps) {
+		canaryStatus.CurrentStepState = appsv1alpha1.CloneSetCanaryStepCompleted
+	} else {
+		switch canaryStatus.CurrentStepState {
+		case appsv1alpha1.CloneSetCanaryStepUpgrading:
+			if newStatus.UpdatedReplicas >= newStatus.ExpectedUpdatedReplicas && newStatus.UpdatedReadyReplicas >= newStatus.ExpectedUpdatedReplicas {
+				klog.Infof("CloneSet %s/%s canary step %d has been updated ready, pause it", cs.Namespace, cs.Name, stepIndex)
+				canaryStatus.CurrentStepState = appsv1alpha1.CloneSetCanaryStepPaused
+				canaryStatus.LastTransitionTime = now
+			}
+		case appsv1alpha1.CloneSetCanaryStepPaused:
+			var resumed bool
+			if duration := steps[stepIndex].Pause.Duration; duration != nil {
+				if left := time.Duration(*duration)*time.Second - now.Sub(canaryStatus.LastTransitionTime.Time); left > 0 {
+					clonesetutils.DurationStore.Push(clonesetutils.GetControllerKey(cs), left)
+				} else {
+					resumed = true
+				}
+			}
+			if cs.Annotations[appsv1alpha1.CloneSetCanaryResumeStepAnnotation] == getCanaryResumeStepValue(canaryStatus) {
+				resumed = true
+			}
+			if resumed {
+				klog.Infof("CloneSet %s/%s canary step %d has been resumed", cs.Namespace, cs.Name, stepIndex)
+				canaryStatus.CurrentStepIndex++
+				canaryStatus.CurrentStepState = appsv1alpha1.CloneSetCanaryStepUpgrading
+				if int(canaryStatus.CurrentStepIndex) >= len(steps) {
+					canaryStatus.CurrentStepState = appsv1alpha1.CloneSetCanaryStepCompleted
+				}
+				canaryStatus.LastTransitionTime = now
+			}
+		}
+	}
+
+	condition := appsv1alpha1.CloneSetCondition{
+		Type:               appsv1alpha1.CloneSetConditionCanaryPaused,
+		Status:             v1.ConditionFalse,
+		LastTransitionTime: canaryStatus.LastTransitionTime,
+		Reason:             string(canaryStatus.CurrentStepState),
+	}
+	if canaryStatus.CurrentStepState == appsv1alpha1.CloneSetCanaryStepPaused {
+		condition.Status = v1.ConditionTrue
+		condition.Message = fmt.Sprintf("canary step %d paused, it can be resumed by annotation %s=%s",
+			canaryStatus.CurrentStepIndex, appsv1alpha1.CloneSetCanaryResumeStepAnnotation, getCanaryResumeStepValue(canaryStatus))
+	}
+	newStatus.Conditions = append(newStatus.Conditions, condition)
+}
+
+// getCanaryResumeStepValue returns the value of resume-step annotation that resumes the current step,
+// which is scoped by the revision so that the annotation left by previous rollouts can not resume it.
+func getCanaryResumeStepValue(canaryStatus *appsv1alpha1.CloneSetCanaryStatus) string {
+	return fmt.Sprintf("%s/%d", canaryStatus.Revision, canaryStatus.CurrentStepIndex)
 }
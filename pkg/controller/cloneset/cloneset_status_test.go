@@ -0,0 +1,296 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloneset
+
+import (
+	"testing"
+	"time"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+)
+
+func TestCalculateCanaryStatus(t *testing.T) {
+	steps := []appsv1alpha1.CloneSetUpdateStep{
+		{Replicas: intstr.FromInt(1), Pause: appsv1alpha1.CloneSetUpdateStepPause{Duration: pointer.Int32(60)}},
+		{Replicas: intstr.FromString("50%")},
+	}
+	cases := []struct {
+		name              string
+		annotations       map[string]string
+		canaryStatus      *appsv1alpha1.CloneSetCanaryStatus
+		updatedReady      int32
+		expectedIndex     int32
+		expectedState     appsv1alpha1.CloneSetCanaryStepState
+		expectedCondition v1.ConditionStatus
+	}{
+		{
+			name:              "new revision starts from the first step",
+			canaryStatus:      &appsv1alpha1.CloneSetCanaryStatus{Revision: "old", CurrentStepIndex: 2, CurrentStepState: appsv1alpha1.CloneSetCanaryStepCompleted},
+			updatedReady:      0,
+			expectedIndex:     0,
+			expectedState:     appsv1alpha1.CloneSetCanaryStepUpgrading,
+			expectedCondition: v1.ConditionFalse,
+		},
+		{
+			name:              "pause after pods of step updated ready",
+			canaryStatus:      &appsv1alpha1.CloneSetCanaryStatus{Revision: "new", CurrentStepState: appsv1alpha1.CloneSetCanaryStepUpgrading},
+			updatedReady:      1,
+			expectedIndex:     0,
+			expectedState:     appsv1alpha1.CloneSetCanaryStepPaused,
+			expectedCondition: v1.ConditionTrue,
+		},
+		{
+			name: "keep paused during duration",
+			canaryStatus: &appsv1alpha1.CloneSetCanaryStatus{Revision: "new", CurrentStepState: appsv1alpha1.CloneSetCanaryStepPaused,
+				LastTransitionTime: metav1.NewTime(time.Now().Add(-time.Second * 10))},
+			updatedReady:      1,
+			expectedIndex:     0,
+			expectedState:     appsv1alpha1.CloneSetCanaryStepPaused,
+			expectedCondition: v1.ConditionTrue,
+		},
+		{
+			name: "move to next step after duration",
+			canaryStatus: &appsv1alpha1.CloneSetCanaryStatus{Revision: "new", CurrentStepState: appsv1alpha1.CloneSetCanaryStepPaused,
+				LastTransitionTime: metav1.NewTime(time.Now().Add(-time.Minute * 2))},
+			updatedReady:      1,
+			expectedIndex:     1,
+			expectedState:     appsv1alpha1.CloneSetCanaryStepUpgrading,
+			expectedCondition: v1.ConditionFalse,
+		},
+		{
+			name:              "keep paused without duration",
+			canaryStatus:      &appsv1alpha1.CloneSetCanaryStatus{Revision: "new", CurrentStepIndex: 1, CurrentStepState: appsv1alpha1.CloneSetCanaryStepPaused},
+			updatedReady:      2,
+			expectedIndex:     1,
+			expectedState:     appsv1alpha1.CloneSetCanaryStepPaused,
+			expectedCondition: v1.ConditionTrue,
+		},
+		{
+			name:              "resumed by annotation",
+			annotations:       map[string]string{appsv1alpha1.CloneSetCanaryResumeStepAnnotation: "new/1"},
+			canaryStatus:      &appsv1alpha1.CloneSetCanaryStatus{Revision: "new", CurrentStepIndex: 1, CurrentStepState: appsv1alpha1.CloneSetCanaryStepPaused},
+			updatedReady:      2,
+			expectedIndex:     2,
+			expectedState:     appsv1alpha1.CloneSetCanaryStepCompleted,
+			expectedCondition: v1.ConditionFalse,
+		},
+		{
+			name:              "not resumed by annotation of previous revision",
+			annotations:       map[string]string{appsv1alpha1.CloneSetCanaryResumeStepAnnotation: "old/1"},
+			canaryStatus:      &appsv1alpha1.CloneSetCanaryStatus{Revision: "new", CurrentStepIndex: 1, CurrentStepState: appsv1alpha1.CloneSetCanaryStepPaused},
+			updatedReady:      2,
+			expectedIndex:     1,
+			expectedState:     appsv1alpha1.CloneSetCanaryStepPaused,
+			expectedCondition: v1.ConditionTrue,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cs := &appsv1alpha1.CloneSet{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo", Annotations: tc.annotations},
+				Spec: appsv1alpha1.CloneSetSpec{
+					Replicas:       pointer.Int32(4),
+					UpdateStrategy: appsv1alpha1.CloneSetUpdateStrategy{Steps: steps},
+				},
+				Status: appsv1alpha1.CloneSetStatus{CanaryStatus: tc.canaryStatus},
+			}
+			newStatus := &appsv1alpha1.CloneSetStatus{UpdateRevision: "new", CurrentRevision: "old"}
+			for i := int32(0); i < 4; i++ {
+				newStatus.Replicas++
+				newStatus.ReadyReplicas++
+				newStatus.AvailableReplicas++
+				if i < tc.updatedReady {
+					newStatus.UpdatedReplicas++
+					newStatus.UpdatedReadyReplicas++
+				}
+			}
+
+			r := &realStatusUpdater{}
+			r.calculateStatus(cs, newStatus, nil)
+			if newStatus.CanaryStatus == nil {
+				t.Fatalf("expected canary status, got nil")
+			}
+			if newStatus.CanaryStatus.CurrentStepIndex != tc.expectedIndex || newStatus.CanaryStatus.CurrentStepState != tc.expectedState {
+				t.Fatalf("expected step %d %s, got %d %s", tc.expectedIndex, tc.expectedState,
+					newStatus.CanaryStatus.CurrentStepIndex, newStatus.CanaryStatus.CurrentStepState)
+			}
+			if len(newStatus.Conditions) != 1 || newStatus.Conditions[0].Type != appsv1alpha1.CloneSetConditionCanaryPaused ||
+				newStatus.Conditions[0].Status != tc.expectedCondition {
+				t.Fatalf("expected condition %s, got %v", tc.expectedCondition, newStatus.Conditions)
+			}
+		})
+	}
+}
+
+func TestCalculateCanaryStatusForSuccessiveRollouts(t *testing.T) {
+	cs := &appsv1alpha1.CloneSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo"},
+		Spec: appsv1alpha1.CloneSetSpec{
+			Replicas:       pointer.Int32(2),
+			UpdateStrategy: appsv1alpha1.CloneSetUpdateStrategy{Steps: []appsv1alpha1.CloneSetUpdateStep{{Replicas: intstr.FromInt(1)}}},
+		},
+	}
+	r := &realStatusUpdater{}
+	reconcile := func(updateRevision string) *appsv1alpha1.CloneSetCanaryStatus {
+		newStatus := &appsv1alpha1.CloneSetStatus{UpdateRevision: updateRevision, CurrentRevision: "rev0",
+			Replicas: 2, ReadyReplicas: 2, AvailableReplicas: 2, UpdatedReplicas: 1, UpdatedReadyReplicas: 1}
+		r.calculateStatus(cs, newStatus, nil)
+		cs.Status.CanaryStatus = newStatus.CanaryStatus
+		return newStatus.CanaryStatus
+	}
+	expectStep := func(canaryStatus *appsv1alpha1.CloneSetCanaryStatus, index int32, state appsv1alpha1.CloneSetCanaryStepState) {
+		if canaryStatus.CurrentStepIndex != index || canaryStatus.CurrentStepState != state {
+			t.Fatalf("expected step %d %s of %s, got %d %s", index, state, canaryStatus.Revision,
+				canaryStatus.CurrentStepIndex, canaryStatus.CurrentStepState)
+		}
+	}
+
+	// the first rollout is paused at step 0 and resumed by annotation
+	reconcile("rev1")
+	expectStep(reconcile("rev1"), 0, appsv1alpha1.CloneSetCanaryStepPaused)
+	cs.Annotations = map[string]string{appsv1alpha1.CloneSetCanaryResumeStepAnnotation: "rev1/0"}
+	expectStep(reconcile("rev1"), 1, appsv1alpha1.CloneSetCanaryStepCompleted)
+
+	// the second rollout should still be paused at step 0 with the annotation left by the first one
+	reconcile("rev2")
+	expectStep(reconcile("rev2"), 0, appsv1alpha1.CloneSetCanaryStepPaused)
+	expectStep(reconcile("rev2"), 0, appsv1alpha1.CloneSetCanaryStepPaused)
+	cs.Annotations[appsv1alpha1.CloneSetCanaryResumeStepAnnotation] = "rev2/0"
+	expectStep(reconcile("rev2"), 1, appsv1alpha1.CloneSetCanaryStepCompleted)
+}
+
+func TestCalculateProgress(t *testing.T) {
+	progressingSince := func(d time.Duration) *appsv1alpha1.CloneSetCondition {
+		return &appsv1alpha1.CloneSetCondition{
+			Type:           appsv1alpha1.CloneSetConditionProgressing,
+			Status:         v1.ConditionTrue,
+			LastUpdateTime: metav1.NewTime(time.Now().Add(-d)),
+			Reason:         appsv1alpha1.CloneSetProgressingReason,
+		}
+	}
+	cases := []struct {
+		name             string
+		paused           bool
+		oldCondition     *appsv1alpha1.CloneSetCondition
+		oldUpdated       int32
+		updated          int32
+		expectedStatus   v1.ConditionStatus
+		expectedReason   string
+		expectedDegraded bool
+	}{
+		{
+			name:           "new rollout starts",
+			updated:        1,
+			expectedStatus: v1.ConditionTrue,
+			expectedReason: appsv1alpha1.CloneSetProgressingReason,
+		},
+		{
+			name:           "no progress within deadline",
+			oldCondition:   progressingSince(10 * time.Second),
+			oldUpdated:     1,
+			updated:        1,
+			expectedStatus: v1.ConditionTrue,
+			expectedReason: appsv1alpha1.CloneSetProgressingReason,
+		},
+		{
+			name:             "no progress exceeding deadline",
+			oldCondition:     progressingSince(10 * time.Minute),
+			oldUpdated:       1,
+			updated:          1,
+			expectedStatus:   v1.ConditionFalse,
+			expectedReason:   appsv1alpha1.CloneSetProgressDeadlineExceededReason,
+			expectedDegraded: true,
+		},
+		{
+			name:           "progress made after deadline",
+			oldCondition:   progressingSince(10 * time.Minute),
+			oldUpdated:     1,
+			updated:        2,
+			expectedStatus: v1.ConditionTrue,
+			expectedReason: appsv1alpha1.CloneSetProgressingReason,
+		},
+		{
+			name:           "paused",
+			paused:         true,
+			oldCondition:   progressingSince(10 * time.Minute),
+			oldUpdated:     1,
+			updated:        1,
+			expectedStatus: v1.ConditionUnknown,
+			expectedReason: appsv1alpha1.CloneSetPausedReason,
+		},
+		{
+			name:           "all updated and available",
+			oldCondition:   progressingSince(10 * time.Minute),
+			oldUpdated:     3,
+			updated:        4,
+			expectedStatus: v1.ConditionTrue,
+			expectedReason: appsv1alpha1.CloneSetAvailableReason,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cs := &appsv1alpha1.CloneSet{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo"},
+				Spec: appsv1alpha1.CloneSetSpec{
+					Replicas:                pointer.Int32(4),
+					ProgressDeadlineSeconds: pointer.Int32(60),
+					UpdateStrategy:          appsv1alpha1.CloneSetUpdateStrategy{Paused: tc.paused},
+				},
+				Status: appsv1alpha1.CloneSetStatus{
+					UpdateRevision:       "new",
+					UpdatedReplicas:      tc.oldUpdated,
+					UpdatedReadyReplicas: tc.oldUpdated,
+					AvailableReplicas:    4,
+				},
+			}
+			if tc.oldCondition != nil {
+				cs.Status.Conditions = []appsv1alpha1.CloneSetCondition{*tc.oldCondition}
+			}
+			newStatus := &appsv1alpha1.CloneSetStatus{
+				UpdateRevision:          "new",
+				CurrentRevision:         "old",
+				Replicas:                4,
+				AvailableReplicas:       4,
+				UpdatedReplicas:         tc.updated,
+				UpdatedReadyReplicas:    tc.updated,
+				ExpectedUpdatedReplicas: 4,
+			}
+
+			recorder := record.NewFakeRecorder(10)
+			r := &realStatusUpdater{recorder: recorder}
+			r.calculateProgress(cs, newStatus)
+			condition := getCloneSetCondition(newStatus, appsv1alpha1.CloneSetConditionProgressing)
+			if condition == nil || condition.Status != tc.expectedStatus || condition.Reason != tc.expectedReason {
+				t.Fatalf("expected progressing %s %s, got %+v", tc.expectedStatus, tc.expectedReason, condition)
+			}
+			degraded := getCloneSetCondition(newStatus, appsv1alpha1.CloneSetConditionDegraded)
+			if (degraded != nil) != tc.expectedDegraded {
+				t.Fatalf("expected degraded %v, got %+v", tc.expectedDegraded, degraded)
+			}
+			if tc.expectedDegraded && len(recorder.Events) != 1 {
+				t.Fatalf("expected an event for degraded, got %d", len(recorder.Events))
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloneset
+
+import (
+	"context"
+	"fmt"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	clonesetutils "github.com/openkruise/kruise/pkg/controller/cloneset/utils"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// syncVolumeClaimExpansion expands the storage requests of existing PVCs to the volumeClaimTemplates
+// and records the expansion progress into newStatus, only if volumeClaimUpdateStrategy type is Expand.
+func (r *ReconcileCloneSet) syncVolumeClaimExpansion(cs *appsv1alpha1.CloneSet, newStatus *appsv1alpha1.CloneSetStatus, pvcs []*v1.PersistentVolumeClaim) error {
+	if cs.DeletionTimestamp != nil || cs.Spec.VolumeClaimUpdateStrategy.Type != appsv1alpha1.ExpandVolumeClaimUpdateStrategyType {
+		return nil
+	}
+
+	var errs []error
+	expandable := make(map[string]bool)
+	for i := range cs.Spec.VolumeClaimTemplates {
+		template := &cs.Spec.VolumeClaimTemplates[i]
+		storage, ok := template.Spec.Resources.Requests[v1.ResourceStorage]
+		if !ok {
+			continue
+		}
+
+		templateStatus := appsv1alpha1.CloneSetVolumeClaimTemplateStatus{Name: template.Name, Storage: storage}
+		for _, pvc := range pvcs {
+			// only the requests of bound claims can be modified
+			if pvc.Status.Phase != v1.ClaimBound || clonesetutils.GetVolumeClaimTemplateName(cs, pvc) != template.Name {
+				continue
+			}
+
+			capacity := pvc.Status.Capacity[v1.ResourceStorage]
+			if capacity.Cmp(storage) >= 0 {
+				templateStatus.ExpandedReplicas++
+				continue
+			}
+
+			requests := pvc.Spec.Resources.Requests[v1.ResourceStorage]
+			if requests.Cmp(storage) < 0 {
+				allowed, err := r.isVolumeExpansionAllowed(pvc, expandable)
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				if !allowed {
+					templateStatus.UnsupportedReplicas++
+					continue
+				}
+				if err := r.patchVolumeClaimStorage(pvc, storage); err != nil {
+					errs = append(errs, fmt.Errorf("failed to expand pvc %s: %v", pvc.Name, err))
+					continue
+				}
+				klog.V(3).Infof("CloneSet %s/%s expanded pvc %s from %s to %s", cs.Namespace, cs.Name, pvc.Name, requests.String(), storage.String())
+			}
+			templateStatus.ExpandingReplicas++
+		}
+		newStatus.VolumeClaimTemplates = append(newStatus.VolumeClaimTemplates, templateStatus)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// isVolumeExpansionAllowed returns whether the StorageClass of pvc allows volume expansion.
+// The results are cached in expandable by StorageClass names.
+func (r *ReconcileCloneSet) isVolumeExpansionAllowed(pvc *v1.PersistentVolumeClaim, expandable map[string]bool) (bool, error) {
+	if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName == "" {
+		return false, nil
+	}
+	name := *pvc.Spec.StorageClassName
+	if allowed, ok := expandable[name]; ok {
+		return allowed, nil
+	}
+
+	sc := &storagev1.StorageClass{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: name}, sc); err != nil {
+		return false, fmt.Errorf("failed to get storageclass %s: %v", name, err)
+	}
+	expandable[name] = sc.AllowVolumeExpansion != nil && *sc.AllowVolumeExpansion
+	return expandable[name], nil
+}
+
+func (r *ReconcileCloneSet) patchVolumeClaimStorage(pvc *v1.PersistentVolumeClaim, storage resource.Quantity) error {
+	body := fmt.Sprintf(`{"spec":{"resources":{"requests":{"%s":"%s"}}}}`, v1.ResourceStorage, storage.String())
+	return r.Patch(context.TODO(), pvc.DeepCopy(), client.RawPatch(types.StrategicMergePatchType, []byte(body)))
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloneset
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSyncVolumeClaimExpansion(t *testing.T) {
+	cs := &appsv1alpha1.CloneSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo"},
+		Spec: appsv1alpha1.CloneSetSpec{
+			VolumeClaimTemplates: []v1.PersistentVolumeClaim{{
+				ObjectMeta: metav1.ObjectMeta{Name: "data"},
+				Spec: v1.PersistentVolumeClaimSpec{
+					Resources: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse("20Gi")}},
+				},
+			}},
+			VolumeClaimUpdateStrategy: appsv1alpha1.CloneSetVolumeClaimUpdateStrategy{Type: appsv1alpha1.ExpandVolumeClaimUpdateStrategyType},
+		},
+	}
+	newPVC := func(id, storageClass, requests, capacity string) *v1.PersistentVolumeClaim {
+		return &v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      "data-foo-" + id,
+				Labels:    map[string]string{appsv1alpha1.CloneSetInstanceID: id},
+			},
+			Spec: v1.PersistentVolumeClaimSpec{
+				StorageClassName: pointer.String(storageClass),
+				Resources:        v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse(requests)}},
+			},
+			Status: v1.PersistentVolumeClaimStatus{
+				Phase:    v1.ClaimBound,
+				Capacity: v1.ResourceList{v1.ResourceStorage: resource.MustParse(capacity)},
+			},
+		}
+	}
+	pvcs := []*v1.PersistentVolumeClaim{
+		newPVC("expanded", "expandable", "20Gi", "20Gi"),
+		newPVC("expanding", "expandable", "20Gi", "10Gi"),
+		newPVC("to-expand", "expandable", "10Gi", "10Gi"),
+		newPVC("unsupported", "fixed", "10Gi", "10Gi"),
+	}
+
+	builder := fake.NewClientBuilder().WithObjects(
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "expandable"}, AllowVolumeExpansion: pointer.Bool(true)},
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "fixed"}},
+	)
+	for _, pvc := range pvcs {
+		builder.WithObjects(pvc.DeepCopy())
+	}
+	r := &ReconcileCloneSet{Client: builder.Build()}
+
+	newStatus := &appsv1alpha1.CloneSetStatus{}
+	if err := r.syncVolumeClaimExpansion(cs, newStatus, pvcs); err != nil {
+		t.Fatalf("failed to sync volume claim expansion: %v", err)
+	}
+
+	expectedStatus := []appsv1alpha1.CloneSetVolumeClaimTemplateStatus{{
+		Name:                "data",
+		Storage:             resource.MustParse("20Gi"),
+		ExpandedReplicas:    1,
+		ExpandingReplicas:   2,
+		UnsupportedReplicas: 1,
+	}}
+	if !reflect.DeepEqual(newStatus.VolumeClaimTemplates, expectedStatus) {
+		t.Fatalf("expected status %+v, got %+v", expectedStatus, newStatus.VolumeClaimTemplates)
+	}
+
+	expectedRequests := map[string]string{
+		"data-foo-expanded":    "20Gi",
+		"data-foo-expanding":   "20Gi",
+		"data-foo-to-expand":   "20Gi",
+		"data-foo-unsupported": "10Gi",
+	}
+	for name, requests := range expectedRequests {
+		pvc := &v1.PersistentVolumeClaim{}
+		if err := r.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: name}, pvc); err != nil {
+			t.Fatalf("failed to get pvc %s: %v", name, err)
+		}
+		if got := pvc.Spec.Resources.Requests[v1.ResourceStorage]; got.Cmp(resource.MustParse(requests)) != 0 {
+			t.Fatalf("expected pvc %s requests %s, got %s", name, requests, got.String())
+		}
+	}
+}
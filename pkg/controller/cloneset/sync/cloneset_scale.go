@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strconv"
 	"sync"
 	"sync/atomic"
 
@@ -30,7 +31,12 @@ import (
 	"github.com/openkruise/kruise/pkg/util"
 	"github.com/openkruise/kruise/pkg/util/expectations"
 	"github.com/openkruise/kruise/pkg/util/lifecycle"
+	"github.com/openkruise/kruise/pkg/util/podranker"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
@@ -87,8 +93,16 @@ func (r *realControl) Scale(
 		klog.V(3).Infof("CloneSet %s begin to scale out %d pods including %d (current rev)",
 			controllerKey, expectedCreations, expectedCurrentCreations)
 
-		// available instance-id come from free pvc
-		availableIDs := getOrGenAvailableIDs(expectedCreations, pods, pvcs)
+		// available instance-id come from free pvc, or the lowest ordinals
+		var availableIDs []string
+		if updateCS.Spec.PodNamingPolicy == appsv1alpha1.CloneSetOrdinalPodNaming {
+			var err error
+			if availableIDs, err = r.genAvailableOrdinalIDs(updateCS, expectedCreations, pods); err != nil {
+				return false, err
+			}
+		} else {
+			availableIDs = getOrGenAvailableIDs(expectedCreations, pods, pvcs).List()
+		}
 		// existing pvc names
 		existingPVCNames := sets.NewString()
 		for _, pvc := range pvcs {
@@ -96,13 +110,14 @@ func (r *realControl) Scale(
 		}
 
 		return r.createPods(expectedCreations, expectedCurrentCreations,
-			currentCS, updateCS, currentRevision, updateRevision, availableIDs.List(), existingPVCNames)
+			currentCS, updateCS, currentRevision, updateRevision, availableIDs, existingPVCNames)
 	}
 
 	// 4. try to delete pods already in pre-delete
 	if len(podsInPreDelete) > 0 {
 		klog.V(3).Infof("CloneSet %s try to delete pods in preDelete: %v", controllerKey, util.GetPodNames(podsInPreDelete).List())
-		if modified, err := r.deletePods(updateCS, podsInPreDelete, pvcs); err != nil || modified {
+		pvcsToDelete := getPVCsToDeleteForRecreation(updateCS, pods, podsInPreDelete, numToDelete, pvcs)
+		if modified, err := r.deletePods(updateCS, podsInPreDelete, pvcsToDelete); err != nil || modified {
 			return modified, err
 		}
 	}
@@ -131,7 +146,8 @@ func (r *realControl) Scale(
 			}
 		}
 
-		if modified, err := r.deletePods(updateCS, podsToDelete, pvcs); err != nil || modified {
+		pvcsToDelete := getPVCsToDeleteForRecreation(updateCS, pods, podsToDelete, numToDelete, pvcs)
+		if modified, err := r.deletePods(updateCS, podsToDelete, pvcsToDelete); err != nil || modified {
 			return modified, err
 		}
 	}
@@ -269,7 +285,12 @@ func (r *realControl) createOnePod(cs *appsv1alpha1.CloneSet, pod *v1.Pod, exist
 func (r *realControl) deletePods(cs *appsv1alpha1.CloneSet, podsToDelete []*v1.Pod, pvcs []*v1.PersistentVolumeClaim) (bool, error) {
 	var modified bool
 	for _, pod := range podsToDelete {
-		if cs.Spec.Lifecycle != nil && lifecycle.IsPodHooked(cs.Spec.Lifecycle.PreDelete, pod) {
+		hooked := cs.Spec.Lifecycle != nil && lifecycle.IsPodHooked(cs.Spec.Lifecycle.PreDelete, pod)
+		if hooked && lifecycle.GetPodLifecycleState(pod) == appspub.LifecycleStatePreparingDelete &&
+			r.isHookTimeoutToContinue(cs, cs.Spec.Lifecycle.PreDelete, pod) {
+			hooked = false
+		}
+		if hooked {
 			if updated, gotPod, err := r.lifecycleControl.UpdatePodLifecycle(pod, appspub.LifecycleStatePreparingDelete); err != nil {
 				return false, err
 			} else if updated {
@@ -308,6 +329,37 @@ func (r *realControl) deletePods(cs *appsv1alpha1.CloneSet, podsToDelete []*v1.P
 	return modified, nil
 }
 
+// getPVCsToDeleteForRecreation returns the pvcs to be deleted along with the pods to delete.
+// If the reuse policy is OnRecreate, the pvcs of pods that will be replaced by new pods are kept for the new pods to reuse.
+func getPVCsToDeleteForRecreation(cs *appsv1alpha1.CloneSet, pods, podsToDelete []*v1.Pod, numToDelete int, pvcs []*v1.PersistentVolumeClaim) []*v1.PersistentVolumeClaim {
+	if cs.Spec.VolumeClaimUpdateStrategy.ReusePolicy != appsv1alpha1.OnRecreateVolumeClaimReusePolicyType {
+		return pvcs
+	}
+
+	// only the pods deleted under replicas will be replaced
+	replacements := int(*cs.Spec.Replicas) - (len(pods) - numToDelete)
+	reusedIDs := sets.NewString()
+	for _, pod := range podsToDelete {
+		if reusedIDs.Len() >= replacements {
+			break
+		}
+		if id := pod.Labels[appsv1alpha1.CloneSetInstanceID]; id != "" {
+			reusedIDs.Insert(id)
+		}
+	}
+	if reusedIDs.Len() == 0 {
+		return pvcs
+	}
+
+	pvcsToDelete := make([]*v1.PersistentVolumeClaim, 0, len(pvcs))
+	for _, pvc := range pvcs {
+		if !reusedIDs.Has(pvc.Labels[appsv1alpha1.CloneSetInstanceID]) {
+			pvcsToDelete = append(pvcsToDelete, pvc)
+		}
+	}
+	return pvcsToDelete
+}
+
 func getPlannedDeletedPods(cs *appsv1alpha1.CloneSet, pods []*v1.Pod) ([]*v1.Pod, []*v1.Pod, int) {
 	var podsSpecifiedToDelete []*v1.Pod
 	var podsInPreDelete []*v1.Pod
@@ -368,8 +420,42 @@ func getOrGenInstanceID(existingIDs, availableIDs sets.String) string {
 	return id
 }
 
+// genAvailableOrdinalIDs returns the lowest ordinals that are neither used by active pods nor terminating pods.
+func (r *realControl) genAvailableOrdinalIDs(cs *appsv1alpha1.CloneSet, num int, pods []*v1.Pod) ([]string, error) {
+	existingIDs := sets.NewString()
+	for _, pod := range pods {
+		existingIDs.Insert(pod.Labels[appsv1alpha1.CloneSetInstanceID])
+	}
+
+	var retIDs []string
+	for i := 0; len(retIDs) < num; i++ {
+		id := strconv.Itoa(i)
+		if existingIDs.Has(id) {
+			continue
+		}
+		// the name may still be occupied by a terminating pod
+		pod := &v1.Pod{}
+		err := r.Get(context.TODO(), types.NamespacedName{Namespace: cs.Namespace, Name: fmt.Sprintf("%s-%s", cs.Name, id)}, pod)
+		if err == nil {
+			continue
+		} else if !errors.IsNotFound(err) {
+			return nil, err
+		}
+		retIDs = append(retIDs, id)
+	}
+	return retIDs, nil
+}
+
 func (r *realControl) choosePodsToDelete(cs *appsv1alpha1.CloneSet, totalDiff int, currentRevDiff int, notUpdatedPods, updatedPods []*v1.Pod) []*v1.Pod {
 	coreControl := clonesetcore.New(cs)
+	var preferredSelector labels.Selector
+	if cs.Spec.ScaleStrategy.PodsToDeleteSelector != nil {
+		var err error
+		if preferredSelector, err = metav1.LabelSelectorAsSelector(cs.Spec.ScaleStrategy.PodsToDeleteSelector); err != nil {
+			klog.Warningf("CloneSet %s/%s has invalid podsToDeleteSelector: %v", cs.Namespace, cs.Name, err)
+			preferredSelector = nil
+		}
+	}
 	choose := func(pods []*v1.Pod, diff int) []*v1.Pod {
 		// No need to sort pods if we are about to delete all of them.
 		if diff < len(pods) {
@@ -380,11 +466,13 @@ func (r *realControl) choosePodsToDelete(cs *appsv1alpha1.CloneSet, totalDiff in
 				ranker = clonesetutils.NewSameNodeRanker(pods)
 			}
 			sort.Sort(clonesetutils.ActivePodsWithRanks{
-				Pods:   pods,
-				Ranker: ranker,
+				Pods:         pods,
+				Ranker:       ranker,
+				PolicyRanker: podranker.New(cs.Spec.ScaleStrategy.RankingPolicy, pods, r.Client),
 				AvailableFunc: func(pod *v1.Pod) bool {
 					return isPodAvailable(coreControl, pod, cs.Spec.MinReadySeconds)
 				},
+				PreferredSelector: preferredSelector,
 			})
 		} else if diff > len(pods) {
 			klog.Warningf("Diff > len(pods) in choosePodsToDelete func which is not expected.")
@@ -21,6 +21,7 @@ import (
 	"reflect"
 	"sort"
 	"testing"
+	"time"
 
 	appspub "github.com/openkruise/kruise/apis/apps/pub"
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
@@ -28,12 +29,15 @@ import (
 	clonesetutils "github.com/openkruise/kruise/pkg/controller/cloneset/utils"
 	"github.com/openkruise/kruise/pkg/util"
 	"github.com/openkruise/kruise/pkg/util/expectations"
+	"github.com/openkruise/kruise/pkg/util/lifecycle"
 	apps "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
@@ -486,6 +490,72 @@ func TestDeletePods(t *testing.T) {
 	}
 }
 
+func TestDeletePodsWithPreDeleteHookTimeout(t *testing.T) {
+	newPod := func(name, timestamp string) *v1.Pod {
+		return &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      name,
+			Labels: map[string]string{
+				appspub.LifecycleStateKey: string(appspub.LifecycleStatePreparingDelete),
+				"hook":                    "true",
+			},
+			Annotations: map[string]string{appspub.LifecycleTimestampKey: timestamp},
+		}}
+	}
+	expired := time.Now().Add(-time.Minute).Format(time.RFC3339)
+	recent := time.Now().Format(time.RFC3339)
+
+	cases := []struct {
+		name          string
+		failurePolicy appspub.LifecycleHookFailurePolicyType
+		pod           *v1.Pod
+		expectDeleted bool
+	}{
+		{
+			name:          "hook not timeout",
+			failurePolicy: appspub.LifecycleHookFailurePolicyContinue,
+			pod:           newPod("foo-0", recent),
+			expectDeleted: false,
+		},
+		{
+			name:          "hook timeout with Block policy",
+			failurePolicy: appspub.LifecycleHookFailurePolicyBlock,
+			pod:           newPod("foo-1", expired),
+			expectDeleted: false,
+		},
+		{
+			name:          "hook timeout with Continue policy",
+			failurePolicy: appspub.LifecycleHookFailurePolicyContinue,
+			pod:           newPod("foo-2", expired),
+			expectDeleted: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cs := &appsv1alpha1.CloneSet{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo"},
+				Spec: appsv1alpha1.CloneSetSpec{Lifecycle: &appspub.Lifecycle{PreDelete: &appspub.LifecycleHook{
+					LabelsHandler:  map[string]string{"hook": "true"},
+					TimeoutSeconds: pointer.Int32(30),
+					FailurePolicy:  tc.failurePolicy,
+				}}},
+			}
+			ctrl := newFakeControl()
+			ctrl.lifecycleControl = lifecycle.New(ctrl.Client)
+			_ = ctrl.Create(context.TODO(), tc.pod)
+
+			if _, err := ctrl.deletePods(cs, []*v1.Pod{tc.pod}, nil); err != nil {
+				t.Fatalf("failed to delete pods: %v", err)
+			}
+			err := ctrl.Get(context.TODO(), client.ObjectKeyFromObject(tc.pod), &v1.Pod{})
+			if deleted := errors.IsNotFound(err); deleted != tc.expectDeleted {
+				t.Fatalf("expected deleted %v, got %v", tc.expectDeleted, deleted)
+			}
+		})
+	}
+}
+
 func TestGetOrGenAvailableIDs(t *testing.T) {
 	pods := []*v1.Pod{
 		{
@@ -527,3 +597,83 @@ func TestGetOrGenAvailableIDs(t *testing.T) {
 		t.Fatalf("expected got random id, but actually %v", id)
 	}
 }
+
+func TestGenAvailableOrdinalIDs(t *testing.T) {
+	cs := &appsv1alpha1.CloneSet{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo"}}
+	pods := []*v1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{appsv1alpha1.CloneSetInstanceID: "0"}}},
+		{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{appsv1alpha1.CloneSetInstanceID: "2"}}},
+	}
+	terminatingPod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Namespace:         "default",
+		Name:              "foo-3",
+		DeletionTimestamp: &metav1.Time{Time: time.Now()},
+		Finalizers:        []string{"test"},
+	}}
+
+	ctrl := newFakeControl()
+	ctrl.Client = fake.NewClientBuilder().WithObjects(terminatingPod).Build()
+	gotIDs, err := ctrl.genAvailableOrdinalIDs(cs, 3, pods)
+	if err != nil {
+		t.Fatalf("failed to gen ordinal ids: %v", err)
+	}
+	if expected := []string{"1", "4", "5"}; !reflect.DeepEqual(gotIDs, expected) {
+		t.Fatalf("expected ids %v, got %v", expected, gotIDs)
+	}
+}
+
+func TestGetPVCsToDeleteForRecreation(t *testing.T) {
+	newPod := func(id string) *v1.Pod {
+		return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo-" + id, Labels: map[string]string{appsv1alpha1.CloneSetInstanceID: id}}}
+	}
+	newPVC := func(id string) *v1.PersistentVolumeClaim {
+		return &v1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data-foo-" + id, Labels: map[string]string{appsv1alpha1.CloneSetInstanceID: id}}}
+	}
+	pods := []*v1.Pod{newPod("a"), newPod("b"), newPod("c")}
+	pvcs := []*v1.PersistentVolumeClaim{newPVC("a"), newPVC("b"), newPVC("c")}
+
+	cases := []struct {
+		name         string
+		replicas     int32
+		reusePolicy  appsv1alpha1.CloneSetVolumeClaimReusePolicyType
+		podsToDelete []*v1.Pod
+		expected     []string
+	}{
+		{
+			name:         "never reuse",
+			replicas:     3,
+			podsToDelete: []*v1.Pod{pods[0]},
+			expected:     []string{"data-foo-a", "data-foo-b", "data-foo-c"},
+		},
+		{
+			name:         "reuse for recreation",
+			replicas:     3,
+			reusePolicy:  appsv1alpha1.OnRecreateVolumeClaimReusePolicyType,
+			podsToDelete: []*v1.Pod{pods[0], pods[1]},
+			expected:     []string{"data-foo-c"},
+		},
+		{
+			name:         "not reuse for scale in",
+			replicas:     2,
+			reusePolicy:  appsv1alpha1.OnRecreateVolumeClaimReusePolicyType,
+			podsToDelete: []*v1.Pod{pods[0], pods[1]},
+			expected:     []string{"data-foo-b", "data-foo-c"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cs := &appsv1alpha1.CloneSet{Spec: appsv1alpha1.CloneSetSpec{
+				Replicas:                  pointer.Int32(tc.replicas),
+				VolumeClaimUpdateStrategy: appsv1alpha1.CloneSetVolumeClaimUpdateStrategy{ReusePolicy: tc.reusePolicy},
+			}}
+			var got []string
+			for _, pvc := range getPVCsToDeleteForRecreation(cs, pods, tc.podsToDelete, len(tc.podsToDelete), pvcs) {
+				got = append(got, pvc.Name)
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Fatalf("expected pvcs to delete %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
@@ -19,6 +19,7 @@ package sync
 import (
 	"math"
 	"reflect"
+	"time"
 
 	appspub "github.com/openkruise/kruise/apis/apps/pub"
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
@@ -75,8 +76,8 @@ func calculateDiffsWithExpectation(cs *appsv1alpha1.CloneSet, pods []*v1.Pod, cu
 	coreControl := clonesetcore.New(cs)
 	replicas := int(*cs.Spec.Replicas)
 	var partition, maxSurge, maxUnavailable, scaleMaxUnavailable int
-	if cs.Spec.UpdateStrategy.Partition != nil {
-		if pValue, err := util.CalculatePartitionReplicas(cs.Spec.UpdateStrategy.Partition, cs.Spec.Replicas); err != nil {
+	if cs.Spec.UpdateStrategy.Partition != nil || len(cs.Spec.UpdateStrategy.Steps) > 0 {
+		if pValue, err := clonesetutils.CalculateUpdatePartitionReplicas(cs, updateRevision); err != nil {
 			// TODO: maybe, we should block pod update if partition settings is wrong
 			klog.Errorf("CloneSet %s/%s partition value is illegal", cs.Namespace, cs.Name)
 		} else {
@@ -86,8 +87,12 @@ func calculateDiffsWithExpectation(cs *appsv1alpha1.CloneSet, pods []*v1.Pod, cu
 	if cs.Spec.UpdateStrategy.MaxSurge != nil {
 		maxSurge, _ = intstrutil.GetValueFromIntOrPercent(cs.Spec.UpdateStrategy.MaxSurge, replicas, true)
 	}
+	maxUnavailableValue := cs.Spec.UpdateStrategy.MaxUnavailable
+	if clonesetutils.IsRollingBack(cs, updateRevision) && cs.Spec.UpdateStrategy.RollbackTo.MaxUnavailable != nil {
+		maxUnavailableValue = cs.Spec.UpdateStrategy.RollbackTo.MaxUnavailable
+	}
 	maxUnavailable, _ = intstrutil.GetValueFromIntOrPercent(
-		intstrutil.ValueOrDefault(cs.Spec.UpdateStrategy.MaxUnavailable, intstrutil.FromString(appsv1alpha1.DefaultCloneSetMaxUnavailable)), replicas, maxSurge == 0)
+		intstrutil.ValueOrDefault(maxUnavailableValue, intstrutil.FromString(appsv1alpha1.DefaultCloneSetMaxUnavailable)), replicas, maxSurge == 0)
 	scaleMaxUnavailable, _ = intstrutil.GetValueFromIntOrPercent(
 		intstrutil.ValueOrDefault(cs.Spec.ScaleStrategy.MaxUnavailable, intstrutil.FromInt(math.MaxInt32)), replicas, true)
 
@@ -222,6 +227,39 @@ func calculateDiffsWithExpectation(cs *appsv1alpha1.CloneSet, pods []*v1.Pod, cu
 	return
 }
 
+// waitForBatchPause returns true if the next batch should not be updated yet, for the pods in target revision
+// are not all available, or they have been available for less than batchPauseSeconds.
+// It also returns the duration left to pause if all of them are available.
+func waitForBatchPause(cs *appsv1alpha1.CloneSet, coreControl clonesetcore.Control, pods []*v1.Pod, targetRevision string) (bool, time.Duration) {
+	if cs.Spec.UpdateStrategy.BatchPauseSeconds <= 0 {
+		return false, 0
+	}
+
+	var lastReadyTime time.Time
+	for _, pod := range pods {
+		if !clonesetutils.EqualToRevisionHash("", pod, targetRevision) {
+			continue
+		}
+		if !coreControl.IsPodUpdateReady(pod, cs.Spec.MinReadySeconds) {
+			return true, 0
+		}
+		for _, c := range pod.Status.Conditions {
+			if (c.Type == v1.PodReady || c.Type == appspub.InPlaceUpdateReady) && c.LastTransitionTime.After(lastReadyTime) {
+				lastReadyTime = c.LastTransitionTime.Time
+			}
+		}
+	}
+	if lastReadyTime.IsZero() {
+		return false, 0
+	}
+
+	pause := time.Duration(cs.Spec.MinReadySeconds+cs.Spec.UpdateStrategy.BatchPauseSeconds) * time.Second
+	if left := time.Until(lastReadyTime.Add(pause)); left > 0 {
+		return true, left
+	}
+	return false, 0
+}
+
 func isSpecifiedDelete(cs *appsv1alpha1.CloneSet, pod *v1.Pod) bool {
 	if specifieddelete.IsSpecifiedDelete(pod) {
 		return true
@@ -234,6 +272,24 @@ func isSpecifiedDelete(cs *appsv1alpha1.CloneSet, pod *v1.Pod) bool {
 	return false
 }
 
+// isHookTimeoutToContinue returns whether the pod can ignore the hook, for the hook has timed out with Continue policy.
+// If the hook has not timed out yet, it requeues the CloneSet when the hook will time out.
+func (c *realControl) isHookTimeoutToContinue(cs *appsv1alpha1.CloneSet, hook *appspub.LifecycleHook, pod *v1.Pod) bool {
+	timeout, left := lifecycle.IsHookTimeout(hook, pod)
+	if left > 0 {
+		clonesetutils.DurationStore.Push(clonesetutils.GetControllerKey(cs), left)
+	}
+	if !timeout || hook.FailurePolicy != appspub.LifecycleHookFailurePolicyContinue {
+		return false
+	}
+	klog.Warningf("CloneSet %s/%s find Pod %s lifecycle hook timeout in state %s, ignore it",
+		cs.Namespace, cs.Name, pod.Name, lifecycle.GetPodLifecycleState(pod))
+	c.recorder.Eventf(cs, v1.EventTypeWarning, "LifecycleHookTimeout",
+		"lifecycle hook of pod %s in state %s has timed out, continue for failurePolicy is %s",
+		pod.Name, lifecycle.GetPodLifecycleState(pod), appspub.LifecycleHookFailurePolicyContinue)
+	return true
+}
+
 func isPodReady(coreControl clonesetcore.Control, pod *v1.Pod) bool {
 	return isPodAvailable(coreControl, pod, 0)
 }
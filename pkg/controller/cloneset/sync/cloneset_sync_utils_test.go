@@ -20,9 +20,11 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	appspub "github.com/openkruise/kruise/apis/apps/pub"
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	clonesetcore "github.com/openkruise/kruise/pkg/controller/cloneset/core"
 	"github.com/openkruise/kruise/pkg/features"
 	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
 	apps "k8s.io/api/apps/v1"
@@ -241,6 +243,70 @@ func TestCalculateDiffsWithExpectation(t *testing.T) {
 			},
 			expectResult: expectationDiffs{},
 		},
+		{
+			name: "update with canary steps 2,4 (step 1/4)",
+			set:  setCanarySteps(createTestCloneSet(5, intstr.FromInt(0), intstr.FromInt(1), intstr.FromInt(0)), nil),
+			pods: []*v1.Pod{
+				createTestPod(oldRevision, appspub.LifecycleStateNormal, true, false),
+				createTestPod(oldRevision, appspub.LifecycleStateNormal, true, false),
+				createTestPod(oldRevision, appspub.LifecycleStateNormal, true, false),
+				createTestPod(oldRevision, appspub.LifecycleStateNormal, true, false),
+				createTestPod(oldRevision, appspub.LifecycleStateNormal, true, false),
+			},
+			expectResult: expectationDiffs{updateNum: 2, updateMaxUnavailable: 1},
+		},
+		{
+			name: "update with canary steps 2,4 (step 2/4)",
+			set: setCanarySteps(createTestCloneSet(5, intstr.FromInt(0), intstr.FromInt(1), intstr.FromInt(0)),
+				&appsv1alpha1.CloneSetCanaryStatus{Revision: oldRevision, CurrentStepIndex: 1}),
+			pods: []*v1.Pod{
+				createTestPod(newRevision, appspub.LifecycleStateNormal, true, false),
+				createTestPod(newRevision, appspub.LifecycleStateNormal, true, false),
+				createTestPod(oldRevision, appspub.LifecycleStateNormal, true, false),
+				createTestPod(oldRevision, appspub.LifecycleStateNormal, true, false),
+				createTestPod(oldRevision, appspub.LifecycleStateNormal, true, false),
+			},
+			expectResult: expectationDiffs{},
+		},
+		{
+			name: "update with canary steps 2,4 (step 3/4)",
+			set: setCanarySteps(createTestCloneSet(5, intstr.FromInt(0), intstr.FromInt(1), intstr.FromInt(0)),
+				&appsv1alpha1.CloneSetCanaryStatus{Revision: newRevision, CurrentStepIndex: 1}),
+			pods: []*v1.Pod{
+				createTestPod(newRevision, appspub.LifecycleStateNormal, true, false),
+				createTestPod(newRevision, appspub.LifecycleStateNormal, true, false),
+				createTestPod(oldRevision, appspub.LifecycleStateNormal, true, false),
+				createTestPod(oldRevision, appspub.LifecycleStateNormal, true, false),
+				createTestPod(oldRevision, appspub.LifecycleStateNormal, true, false),
+			},
+			expectResult: expectationDiffs{updateNum: 2, updateMaxUnavailable: 1},
+		},
+		{
+			name: "update with canary steps 2,4 (step 4/4)",
+			set: setCanarySteps(createTestCloneSet(5, intstr.FromInt(0), intstr.FromInt(1), intstr.FromInt(0)),
+				&appsv1alpha1.CloneSetCanaryStatus{Revision: newRevision, CurrentStepIndex: 2}),
+			pods: []*v1.Pod{
+				createTestPod(newRevision, appspub.LifecycleStateNormal, true, false),
+				createTestPod(newRevision, appspub.LifecycleStateNormal, true, false),
+				createTestPod(oldRevision, appspub.LifecycleStateNormal, true, false),
+				createTestPod(oldRevision, appspub.LifecycleStateNormal, true, false),
+				createTestPod(oldRevision, appspub.LifecycleStateNormal, true, false),
+			},
+			expectResult: expectationDiffs{updateNum: 3, updateMaxUnavailable: 1},
+		},
+		{
+			name: "rollbackTo with maxUnavailable=3 ignores partition and steps",
+			set: setRollbackTo(setCanarySteps(createTestCloneSet(5, intstr.FromInt(4), intstr.FromInt(1), intstr.FromInt(0)), nil),
+				newRevision, intstr.FromInt(3)),
+			pods: []*v1.Pod{
+				createTestPod(oldRevision, appspub.LifecycleStateNormal, true, false),
+				createTestPod(oldRevision, appspub.LifecycleStateNormal, true, false),
+				createTestPod(oldRevision, appspub.LifecycleStateNormal, true, false),
+				createTestPod(oldRevision, appspub.LifecycleStateNormal, true, false),
+				createTestPod(oldRevision, appspub.LifecycleStateNormal, true, false),
+			},
+			expectResult: expectationDiffs{updateNum: 5, updateMaxUnavailable: 3},
+		},
 		{
 			name: "rollback partition=4 (step 1/3)",
 			set:  createTestCloneSet(5, intstr.FromInt(4), intstr.FromInt(2), intstr.FromInt(0)),
@@ -715,6 +781,65 @@ func TestCalculateDiffsWithExpectation(t *testing.T) {
 	}
 }
 
+func TestWaitForBatchPause(t *testing.T) {
+	newReadyPod := func(revision string, readyTime time.Time) *v1.Pod {
+		pod := createTestPod(revision, appspub.LifecycleStateNormal, true, false)
+		pod.Status.Conditions[0].LastTransitionTime = metav1.NewTime(readyTime)
+		return pod
+	}
+	now := time.Now()
+
+	cases := []struct {
+		name         string
+		pauseSeconds int32
+		pods         []*v1.Pod
+		expectWait   bool
+		expectLeft   bool
+	}{
+		{
+			name:       "no batch pause",
+			pods:       []*v1.Pod{createTestPod("new_rev", appspub.LifecycleStateNormal, false, false)},
+			expectWait: false,
+		},
+		{
+			name:         "first batch",
+			pauseSeconds: 60,
+			pods:         []*v1.Pod{newReadyPod("old_rev", now)},
+			expectWait:   false,
+		},
+		{
+			name:         "updated pod not ready",
+			pauseSeconds: 60,
+			pods:         []*v1.Pod{newReadyPod("new_rev", now.Add(-time.Hour)), createTestPod("new_rev", appspub.LifecycleStateNormal, false, false)},
+			expectWait:   true,
+		},
+		{
+			name:         "pausing after batch ready",
+			pauseSeconds: 60,
+			pods:         []*v1.Pod{newReadyPod("new_rev", now.Add(-time.Hour)), newReadyPod("new_rev", now.Add(-time.Second)), newReadyPod("old_rev", now)},
+			expectWait:   true,
+			expectLeft:   true,
+		},
+		{
+			name:         "pause finished",
+			pauseSeconds: 60,
+			pods:         []*v1.Pod{newReadyPod("new_rev", now.Add(-time.Minute*2)), newReadyPod("old_rev", now)},
+			expectWait:   false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cs := createTestCloneSet(3, intstr.FromInt(0), intstr.FromInt(1), intstr.FromInt(0))
+			cs.Spec.UpdateStrategy.BatchPauseSeconds = tc.pauseSeconds
+			wait, left := waitForBatchPause(cs, clonesetcore.New(cs), tc.pods, "new_rev")
+			if wait != tc.expectWait || (left > 0) != tc.expectLeft {
+				t.Fatalf("expected wait %v with left %v, got %v, %v", tc.expectWait, tc.expectLeft, wait, left)
+			}
+		})
+	}
+}
+
 func createTestCloneSet(replicas int32, partition, maxUnavailable, maxSurge intstr.IntOrString) *appsv1alpha1.CloneSet {
 	return &appsv1alpha1.CloneSet{
 		Spec: appsv1alpha1.CloneSetSpec{
@@ -728,6 +853,23 @@ func createTestCloneSet(replicas int32, partition, maxUnavailable, maxSurge ints
 	}
 }
 
+func setCanarySteps(cs *appsv1alpha1.CloneSet, canaryStatus *appsv1alpha1.CloneSetCanaryStatus) *appsv1alpha1.CloneSet {
+	cs.Spec.UpdateStrategy.Steps = []appsv1alpha1.CloneSetUpdateStep{
+		{Replicas: intstr.FromInt(2)},
+		{Replicas: intstr.FromString("80%")},
+	}
+	cs.Status.CanaryStatus = canaryStatus
+	return cs
+}
+
+func setRollbackTo(cs *appsv1alpha1.CloneSet, revision string, maxUnavailable intstr.IntOrString) *appsv1alpha1.CloneSet {
+	cs.Spec.UpdateStrategy.RollbackTo = &appsv1alpha1.CloneSetRollback{
+		Revision:       revision,
+		MaxUnavailable: &maxUnavailable,
+	}
+	return cs
+}
+
 func setScaleStrategy(cs *appsv1alpha1.CloneSet, maxUnavailable intstr.IntOrString) *appsv1alpha1.CloneSet {
 	cs.Spec.ScaleStrategy = appsv1alpha1.CloneSetScaleStrategy{
 		MaxUnavailable: &maxUnavailable,
@@ -73,7 +73,7 @@ func (c *realControl) Update(cs *appsv1alpha1.CloneSet,
 		return nil
 	}
 
-	if cs.Spec.UpdateStrategy.Paused {
+	if cs.Spec.UpdateStrategy.Paused && !clonesetutils.IsRollingBack(cs, updateRevision.Name) {
 		return nil
 	}
 
@@ -88,6 +88,13 @@ func (c *realControl) Update(cs *appsv1alpha1.CloneSet,
 	if diffRes.updateNum < 0 {
 		targetRevision = currentRevision
 	}
+	if wait, left := waitForBatchPause(cs, coreControl, pods, targetRevision.Name); wait {
+		if left > 0 {
+			klog.V(4).Infof("CloneSet %s/%s pauses for %v before updating the next batch", cs.Namespace, cs.Name, left)
+			clonesetutils.DurationStore.Push(key, left)
+		}
+		return nil
+	}
 	var waitUpdateIndexes []int
 	for i, pod := range pods {
 		if coreControl.IsPodUpdatePaused(pod) {
@@ -141,15 +148,25 @@ func (c *realControl) Update(cs *appsv1alpha1.CloneSet,
 	// 6. update pods
 	for _, idx := range waitUpdateIndexes {
 		pod := pods[idx]
+		var leased bool
 		// Determine the pub before updating the pod
 		if pub != nil {
-			allowed, _, err := pubcontrol.PodUnavailableBudgetValidatePod(c.Client, c.pubControl, pub, pod, pubcontrol.UpdateOperation, false)
-			if err != nil {
-				return err
-				// pub check does not pass, try again in seconds
-			} else if !allowed {
-				clonesetutils.DurationStore.Push(key, time.Second)
-				return nil
+			lease := pubcontrol.GetPubBudgetLease(pub, clonesetutils.GetShortHash(targetRevision.Name))
+			// the update has not been budgeted by pub, e.g. the pod waits in PreparingUpdate state
+			if pod.Annotations[pubcontrol.PodPubBudgetLeaseAnnotation] != lease {
+				allowed, reason, err := pubcontrol.PodUnavailableBudgetValidatePod(c.Client, c.pubControl, pub, pod, pubcontrol.UpdateOperation, false)
+				if err != nil {
+					return err
+					// pub check does not pass, try again in seconds
+				} else if !allowed {
+					pubcontrol.RecordPubRejection(c.recorder, pub, pod, pubcontrol.UpdateOperation, fmt.Sprintf("CloneSet %s", cs.Name), reason)
+					clonesetutils.DurationStore.Push(key, time.Second)
+					return nil
+				}
+				if pod, err = c.patchPubBudgetLease(pod, lease); err != nil {
+					return err
+				}
+				leased = true
 			}
 		}
 		duration, err := c.updatePod(cs, coreControl, targetRevision, revisions, pod, pvcs)
@@ -157,6 +174,12 @@ func (c *realControl) Update(cs *appsv1alpha1.CloneSet,
 			clonesetutils.DurationStore.Push(key, duration)
 		}
 		if err != nil {
+			// the lease must not outlive the failed update, the next attempt will be validated by pub again
+			if leased {
+				if clearErr := c.clearPubBudgetLease(pod); clearErr != nil {
+					klog.Errorf("CloneSet %s failed to clear pub budget lease of pod %s: %v", key, pod.Name, clearErr)
+				}
+			}
 			return err
 		}
 	}
@@ -164,6 +187,28 @@ func (c *realControl) Update(cs *appsv1alpha1.CloneSet,
 	return nil
 }
 
+// patchPubBudgetLease records the budget lease in pod, so that the update won't be counted by pub again
+func (c *realControl) patchPubBudgetLease(pod *v1.Pod, lease string) (*v1.Pod, error) {
+	clone := pod.DeepCopy()
+	body := fmt.Sprintf(`{"metadata":{"annotations":{"%s":"%s"}}}`, pubcontrol.PodPubBudgetLeaseAnnotation, lease)
+	if err := c.Patch(context.TODO(), clone, client.RawPatch(types.StrategicMergePatchType, []byte(body))); err != nil {
+		return nil, err
+	}
+	clonesetutils.ResourceVersionExpectations.Expect(clone)
+	return clone, nil
+}
+
+// clearPubBudgetLease removes the budget lease from pod
+func (c *realControl) clearPubBudgetLease(pod *v1.Pod) error {
+	clone := pod.DeepCopy()
+	body := fmt.Sprintf(`{"metadata":{"annotations":{"%s":null}}}`, pubcontrol.PodPubBudgetLeaseAnnotation)
+	if err := c.Patch(context.TODO(), clone, client.RawPatch(types.StrategicMergePatchType, []byte(body))); err != nil {
+		return err
+	}
+	clonesetutils.ResourceVersionExpectations.Expect(clone)
+	return nil
+}
+
 func (c *realControl) refreshPodState(cs *appsv1alpha1.CloneSet, coreControl clonesetcore.Control, pod *v1.Pod) (bool, time.Duration, error) {
 	opts := coreControl.GetUpdateOptions()
 	opts = inplaceupdate.SetOptionsDefaults(opts)
@@ -175,6 +220,22 @@ func (c *realControl) refreshPodState(cs *appsv1alpha1.CloneSet, coreControl clo
 		return false, 0, res.RefreshErr
 	}
 
+	// back off to recreate the pod if the node can not satisfy its in-place resize
+	if lifecycle.GetPodLifecycleState(pod) == appspub.LifecycleStateUpdating && inplaceupdate.IsPodResizeInfeasible(pod) &&
+		cs.Spec.UpdateStrategy.Type != appsv1alpha1.InPlaceOnlyCloneSetUpdateStrategyType && !specifieddelete.IsSpecifiedDelete(pod) {
+		klog.Warningf("CloneSet %s/%s find Pod %s resize infeasible, so it will back off to ReCreate", cs.Namespace, cs.Name, pod.Name)
+		if patched, err := specifieddelete.PatchPodSpecifiedDelete(c.Client, pod, "true"); err != nil {
+			c.recorder.Eventf(cs, v1.EventTypeWarning, "FailedUpdatePodReCreate",
+				"failed to patch pod specified-delete %s for resize infeasible: %v", pod.Name, err)
+			return false, 0, err
+		} else if patched {
+			clonesetutils.ResourceVersionExpectations.Expect(pod)
+			c.recorder.Eventf(cs, v1.EventTypeWarning, "ResizeInfeasible",
+				"pod %s can not be resized in-place on its node, patch specified-delete to recreate it", pod.Name)
+			return true, res.DelayDuration, nil
+		}
+	}
+
 	var state appspub.LifecycleStateType
 	switch lifecycle.GetPodLifecycleState(pod) {
 	case appspub.LifecycleStateUpdating:
@@ -188,7 +249,8 @@ func (c *realControl) refreshPodState(cs *appsv1alpha1.CloneSet, coreControl clo
 	case appspub.LifecycleStateUpdated:
 		if cs.Spec.Lifecycle == nil ||
 			cs.Spec.Lifecycle.InPlaceUpdate == nil ||
-			lifecycle.IsPodAllHooked(cs.Spec.Lifecycle.InPlaceUpdate, pod) {
+			lifecycle.IsPodAllHooked(cs.Spec.Lifecycle.InPlaceUpdate, pod) ||
+			c.isHookTimeoutToContinue(cs, cs.Spec.Lifecycle.InPlaceUpdate, pod) {
 			state = appspub.LifecycleStateNormal
 		}
 	}
@@ -228,16 +290,17 @@ func (c *realControl) updatePod(cs *appsv1alpha1.CloneSet, coreControl clonesetc
 	pod *v1.Pod, pvcs []*v1.PersistentVolumeClaim,
 ) (time.Duration, error) {
 
-	if cs.Spec.UpdateStrategy.Type == appsv1alpha1.InPlaceIfPossibleCloneSetUpdateStrategyType ||
-		cs.Spec.UpdateStrategy.Type == appsv1alpha1.InPlaceOnlyCloneSetUpdateStrategyType {
-		var oldRevision *apps.ControllerRevision
-		for _, r := range revisions {
-			if clonesetutils.EqualToRevisionHash("", pod, r.Name) {
-				oldRevision = r
-				break
-			}
+	var oldRevision *apps.ControllerRevision
+	for _, r := range revisions {
+		if clonesetutils.EqualToRevisionHash("", pod, r.Name) {
+			oldRevision = r
+			break
 		}
+	}
 
+	if cs.Spec.UpdateStrategy.Type == appsv1alpha1.InPlaceIfPossibleCloneSetUpdateStrategyType ||
+		cs.Spec.UpdateStrategy.Type == appsv1alpha1.InPlaceOnlyCloneSetUpdateStrategyType ||
+		inplaceupdate.IsOnlyMetadataKeysChanged(oldRevision, updateRevision, cs.Spec.UpdateStrategy.InPlaceMetadataKeys) {
 		if c.inplaceControl.CanUpdateInPlace(oldRevision, updateRevision, coreControl.GetUpdateOptions()) {
 			switch state := lifecycle.GetPodLifecycleState(pod); state {
 			case "", appspub.LifecycleStateNormal:
@@ -267,7 +330,8 @@ func (c *realControl) updatePod(cs *appsv1alpha1.CloneSet, coreControl clonesetc
 				}
 				return 0, err
 			case appspub.LifecycleStatePreparingUpdate:
-				if cs.Spec.Lifecycle != nil && lifecycle.IsPodHooked(cs.Spec.Lifecycle.InPlaceUpdate, pod) {
+				if cs.Spec.Lifecycle != nil && lifecycle.IsPodHooked(cs.Spec.Lifecycle.InPlaceUpdate, pod) &&
+					!c.isHookTimeoutToContinue(cs, cs.Spec.Lifecycle.InPlaceUpdate, pod) {
 					return 0, nil
 				}
 			case appspub.LifecycleStateUpdating:
@@ -285,6 +349,7 @@ func (c *realControl) updatePod(cs *appsv1alpha1.CloneSet, coreControl clonesetc
 					return res.DelayDuration, nil
 				}
 
+				clonesetutils.RecordInPlaceUpdateFailure(cs)
 				c.recorder.Eventf(cs, v1.EventTypeWarning, "FailedUpdatePodInPlace", "failed to update pod %s in-place(revision %v): %v", pod.Name, updateRevision.Name, res.UpdateErr)
 				return res.DelayDuration, res.UpdateErr
 			}
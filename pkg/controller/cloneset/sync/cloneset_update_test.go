@@ -866,3 +866,25 @@ func TestCalculateUpdateCount(t *testing.T) {
 		}
 	}
 }
+
+func TestClearPubBudgetLease(t *testing.T) {
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod-0", Annotations: map[string]string{
+		pubcontrol.PodPubBudgetLeaseAnnotation: "pub-uid/hash-new",
+		"foo":                                  "bar",
+	}}}
+	fakeClient := fake.NewClientBuilder().WithObjects(pod).Build()
+	ctrl := &realControl{Client: fakeClient}
+	if err := ctrl.clearPubBudgetLease(pod); err != nil {
+		t.Fatalf("Failed to clear lease: %v", err)
+	}
+	gotPod := &v1.Pod{}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}, gotPod); err != nil {
+		t.Fatalf("Failed to get pod: %v", err)
+	}
+	if _, ok := gotPod.Annotations[pubcontrol.PodPubBudgetLeaseAnnotation]; ok {
+		t.Fatalf("Expect lease cleared, but got %v", gotPod.Annotations)
+	}
+	if gotPod.Annotations["foo"] != "bar" {
+		t.Fatalf("Expect other annotations kept, but got %v", gotPod.Annotations)
+	}
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"sync"
+	"time"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// revisionPodsGauge is the number of pods in each revision of CloneSet
+	revisionPodsGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kruise_cloneset_revision_pods",
+			Help: "Number of pods in each revision of CloneSet",
+		},
+		[]string{"namespace", "name", "revision"},
+	)
+
+	updatedReadyReplicasGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kruise_cloneset_updated_ready_replicas",
+			Help: "Number of ready pods in the update revision of CloneSet",
+		},
+		[]string{"namespace", "name"},
+	)
+
+	// partitionGauge is the effective partition, which has been calculated from percentage, steps and rollback
+	partitionGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kruise_cloneset_partition",
+			Help: "Number of pods reserved in old revisions of CloneSet",
+		},
+		[]string{"namespace", "name"},
+	)
+
+	// rolloutDurationGauge is the seconds since the update revision created, or 0 if the rollout has completed
+	rolloutDurationGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kruise_cloneset_rollout_duration_seconds",
+			Help: "Seconds since the current rollout of CloneSet started",
+		},
+		[]string{"namespace", "name"},
+	)
+
+	inPlaceUpdateFailureCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kruise_cloneset_inplace_update_failures_total",
+			Help: "Number of failures when updating pods of CloneSet in-place",
+		},
+		[]string{"namespace", "name"},
+	)
+
+	// recordedRevisions records the revisions in revisionPodsGauge for each CloneSet, so that the outdated ones can be deleted
+	recordedRevisions     = make(map[string]sets.String)
+	recordedRevisionsLock sync.Mutex
+)
+
+func init() {
+	metrics.Registry.MustRegister(revisionPodsGauge, updatedReadyReplicasGauge, partitionGauge, rolloutDurationGauge, inPlaceUpdateFailureCounter)
+}
+
+// RecordRolloutMetrics records the rollout progress of CloneSet from the calculated status and pods.
+func RecordRolloutMetrics(cs *appsv1alpha1.CloneSet, newStatus *appsv1alpha1.CloneSetStatus, pods []*v1.Pod, updateRevision *apps.ControllerRevision) {
+	revisionPods := make(map[string]int)
+	for _, pod := range pods {
+		revisionPods[pod.Labels[apps.ControllerRevisionHashLabelKey]]++
+	}
+
+	key := GetControllerKey(cs)
+	recordedRevisionsLock.Lock()
+	revisions := sets.NewString()
+	for revision, count := range revisionPods {
+		revisionPodsGauge.WithLabelValues(cs.Namespace, cs.Name, revision).Set(float64(count))
+		revisions.Insert(revision)
+	}
+	for revision := range recordedRevisions[key].Difference(revisions) {
+		revisionPodsGauge.DeleteLabelValues(cs.Namespace, cs.Name, revision)
+	}
+	recordedRevisions[key] = revisions
+	recordedRevisionsLock.Unlock()
+
+	updatedReadyReplicasGauge.WithLabelValues(cs.Namespace, cs.Name).Set(float64(newStatus.UpdatedReadyReplicas))
+	if partition, err := CalculateUpdatePartitionReplicas(cs, updateRevision.Name); err == nil {
+		partitionGauge.WithLabelValues(cs.Namespace, cs.Name).Set(float64(partition))
+	}
+
+	var rolloutDuration time.Duration
+	if newStatus.CurrentRevision != newStatus.UpdateRevision {
+		rolloutDuration = time.Since(updateRevision.CreationTimestamp.Time)
+	}
+	rolloutDurationGauge.WithLabelValues(cs.Namespace, cs.Name).Set(rolloutDuration.Seconds())
+}
+
+// RecordInPlaceUpdateFailure increases the in-place update failures of CloneSet.
+func RecordInPlaceUpdateFailure(cs *appsv1alpha1.CloneSet) {
+	inPlaceUpdateFailureCounter.WithLabelValues(cs.Namespace, cs.Name).Inc()
+}
+
+// DeleteMetrics deletes all metrics of the CloneSet, which has been deleted.
+func DeleteMetrics(namespace, name string) {
+	key := types.NamespacedName{Namespace: namespace, Name: name}.String()
+	recordedRevisionsLock.Lock()
+	for revision := range recordedRevisions[key] {
+		revisionPodsGauge.DeleteLabelValues(namespace, name, revision)
+	}
+	delete(recordedRevisions, key)
+	recordedRevisionsLock.Unlock()
+
+	updatedReadyReplicasGauge.DeleteLabelValues(namespace, name)
+	partitionGauge.DeleteLabelValues(namespace, name)
+	rolloutDurationGauge.DeleteLabelValues(namespace, name)
+	inPlaceUpdateFailureCounter.DeleteLabelValues(namespace, name)
+}
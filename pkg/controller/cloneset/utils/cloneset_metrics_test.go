@@ -0,0 +1,83 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+	"time"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	utilmetrics "github.com/openkruise/kruise/pkg/util/metrics"
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+)
+
+func TestRecordRolloutMetrics(t *testing.T) {
+	partition := intstr.FromInt(1)
+	cs := &appsv1alpha1.CloneSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "metrics"},
+		Spec: appsv1alpha1.CloneSetSpec{
+			Replicas:       pointer.Int32(3),
+			UpdateStrategy: appsv1alpha1.CloneSetUpdateStrategy{Partition: &partition},
+		},
+	}
+	updateRevision := &apps.ControllerRevision{ObjectMeta: metav1.ObjectMeta{
+		Name:              "metrics-v2",
+		CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Minute)),
+	}}
+	newPod := func(revision string) *v1.Pod {
+		return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{apps.ControllerRevisionHashLabelKey: revision}}}
+	}
+
+	newStatus := &appsv1alpha1.CloneSetStatus{CurrentRevision: "metrics-v1", UpdateRevision: "metrics-v2", UpdatedReadyReplicas: 1}
+	RecordRolloutMetrics(cs, newStatus, []*v1.Pod{newPod("metrics-v1"), newPod("metrics-v1"), newPod("metrics-v2")}, updateRevision)
+	if value := utilmetrics.GetMetricValueDuringTest(t, revisionPodsGauge.WithLabelValues("default", "metrics", "metrics-v1")); value != 2 {
+		t.Fatalf("expected 2 pods in metrics-v1, got %v", value)
+	}
+	if value := utilmetrics.GetMetricValueDuringTest(t, updatedReadyReplicasGauge.WithLabelValues("default", "metrics")); value != 1 {
+		t.Fatalf("expected updated ready 1, got %v", value)
+	}
+	if value := utilmetrics.GetMetricValueDuringTest(t, partitionGauge.WithLabelValues("default", "metrics")); value != 1 {
+		t.Fatalf("expected partition 1, got %v", value)
+	}
+	if value := utilmetrics.GetMetricValueDuringTest(t, rolloutDurationGauge.WithLabelValues("default", "metrics")); value < 60 {
+		t.Fatalf("expected rollout duration >= 60s, got %v", value)
+	}
+
+	// the outdated revision should be deleted after all pods updated
+	newStatus = &appsv1alpha1.CloneSetStatus{CurrentRevision: "metrics-v2", UpdateRevision: "metrics-v2", UpdatedReadyReplicas: 3}
+	RecordRolloutMetrics(cs, newStatus, []*v1.Pod{newPod("metrics-v2"), newPod("metrics-v2"), newPod("metrics-v2")}, updateRevision)
+	if revisionPodsGauge.DeleteLabelValues("default", "metrics", "metrics-v1") {
+		t.Fatalf("expected metrics-v1 deleted")
+	}
+	if value := utilmetrics.GetMetricValueDuringTest(t, rolloutDurationGauge.WithLabelValues("default", "metrics")); value != 0 {
+		t.Fatalf("expected rollout duration 0 after completed, got %v", value)
+	}
+
+	RecordInPlaceUpdateFailure(cs)
+	if value := utilmetrics.GetMetricValueDuringTest(t, inPlaceUpdateFailureCounter.WithLabelValues("default", "metrics")); value != 1 {
+		t.Fatalf("expected 1 in-place update failure, got %v", value)
+	}
+
+	DeleteMetrics(cs.Namespace, cs.Name)
+	if partitionGauge.DeleteLabelValues("default", "metrics") || revisionPodsGauge.DeleteLabelValues("default", "metrics", "metrics-v2") {
+		t.Fatalf("expected metrics deleted")
+	}
+}
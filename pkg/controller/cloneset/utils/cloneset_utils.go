@@ -24,6 +24,7 @@ import (
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
 	"github.com/openkruise/kruise/pkg/features"
+	"github.com/openkruise/kruise/pkg/util"
 	utilclient "github.com/openkruise/kruise/pkg/util/client"
 	"github.com/openkruise/kruise/pkg/util/expectations"
 	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
@@ -32,6 +33,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	intstrutil "k8s.io/apimachinery/pkg/util/intstr"
 	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
 	kubecontroller "k8s.io/kubernetes/pkg/controller"
 	"k8s.io/utils/integer"
@@ -191,6 +193,18 @@ func GetPersistentVolumeClaims(cs *appsv1alpha1.CloneSet, pod *v1.Pod) map[strin
 	return claims
 }
 
+// GetVolumeClaimTemplateName returns the name of volumeClaimTemplate that the PersistentVolumeClaim is created from,
+// or empty if it does not match any template in set.
+func GetVolumeClaimTemplateName(cs *appsv1alpha1.CloneSet, pvc *v1.PersistentVolumeClaim) string {
+	id := pvc.Labels[appsv1alpha1.CloneSetInstanceID]
+	for i := range cs.Spec.VolumeClaimTemplates {
+		if getPersistentVolumeClaimName(cs, &cs.Spec.VolumeClaimTemplates[i], id) == pvc.Name {
+			return cs.Spec.VolumeClaimTemplates[i].Name
+		}
+	}
+	return ""
+}
+
 // getPersistentVolumeClaimName gets the name of PersistentVolumeClaim for a Pod with an instance id. claim
 // must be a PersistentVolumeClaim from set's VolumeClaims template.
 func getPersistentVolumeClaimName(cs *appsv1alpha1.CloneSet, claim *v1.PersistentVolumeClaim, id string) string {
@@ -233,3 +247,51 @@ func DoItSlowly(count int, initialBatchSize int, fn func() error) (int, error) {
 	}
 	return successes, nil
 }
+
+// CalculatePartitionReplicas returns absolute value of partition for CloneSet, according to its partitionRounding.
+func CalculatePartitionReplicas(cs *appsv1alpha1.CloneSet) (int, error) {
+	roundUp := cs.Spec.UpdateStrategy.PartitionRounding != appsv1alpha1.CloneSetPartitionRoundDown
+	return util.CalculatePartitionReplicasWithRounding(cs.Spec.UpdateStrategy.Partition, cs.Spec.Replicas, roundUp)
+}
+
+// GetCanaryStepIndex returns the index of canary step being executed for the update revision.
+func GetCanaryStepIndex(cs *appsv1alpha1.CloneSet, updateRevision string) int {
+	if canaryStatus := cs.Status.CanaryStatus; canaryStatus != nil && canaryStatus.Revision == updateRevision {
+		return int(canaryStatus.CurrentStepIndex)
+	}
+	return 0
+}
+
+// IsRollingBack returns whether the CloneSet is rolling back to the update revision specified in rollbackTo.
+func IsRollingBack(cs *appsv1alpha1.CloneSet, updateRevision string) bool {
+	rollback := cs.Spec.UpdateStrategy.RollbackTo
+	return rollback != nil && rollback.Revision == updateRevision
+}
+
+// CalculateUpdatePartitionReplicas returns absolute value of partition for updating pods to the update revision,
+// which keeps the pods in old revisions no less than both partition and the canary step being executed.
+func CalculateUpdatePartitionReplicas(cs *appsv1alpha1.CloneSet, updateRevision string) (int, error) {
+	if IsRollingBack(cs, updateRevision) {
+		return 0, nil
+	}
+	partition, err := CalculatePartitionReplicas(cs)
+	if err != nil {
+		return partition, err
+	}
+
+	steps := cs.Spec.UpdateStrategy.Steps
+	stepIndex := GetCanaryStepIndex(cs, updateRevision)
+	if stepIndex >= len(steps) {
+		return partition, nil
+	}
+	replicas := 1
+	if cs.Spec.Replicas != nil {
+		replicas = int(*cs.Spec.Replicas)
+	}
+	stepReplicas, err := intstrutil.GetScaledValueFromIntOrPercent(&steps[stepIndex].Replicas, replicas, true)
+	if err != nil {
+		return partition, err
+	}
+	stepReplicas = integer.IntMax(integer.IntMin(stepReplicas, replicas), 0)
+	return integer.IntMax(partition, replicas-stepReplicas), nil
+}
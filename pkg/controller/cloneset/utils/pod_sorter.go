@@ -23,8 +23,10 @@ import (
 	"sort"
 	"strconv"
 
+	"github.com/openkruise/kruise/pkg/util/podranker"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
@@ -32,9 +34,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-type Ranker interface {
-	GetRank(*v1.Pod) float64
-}
+type Ranker = podranker.Ranker
 
 const (
 	// PodDeletionCost can be used to set to an int32 that represent the cost of deleting
@@ -47,24 +47,33 @@ const (
 type ActivePodsWithRanks struct {
 	Pods          []*v1.Pod
 	Ranker        Ranker
+	PolicyRanker  Ranker
 	AvailableFunc func(*v1.Pod) bool
+	// PreferredSelector selects the pods that are preferred to be deleted.
+	PreferredSelector labels.Selector
 }
 
 func (s ActivePodsWithRanks) Len() int      { return len(s.Pods) }
 func (s ActivePodsWithRanks) Swap(i, j int) { s.Pods[i], s.Pods[j] = s.Pods[j], s.Pods[i] }
 
 func (s ActivePodsWithRanks) Less(i, j int) bool {
-	// 1. Unassigned < assigned
+	// 1. Pods matching preferred selector < others
+	if s.PreferredSelector != nil {
+		if preferredI, preferredJ := s.PreferredSelector.Matches(labels.Set(s.Pods[i].Labels)), s.PreferredSelector.Matches(labels.Set(s.Pods[j].Labels)); preferredI != preferredJ {
+			return preferredI
+		}
+	}
+	// 2. Unassigned < assigned
 	// If only one of the pods is unassigned, the unassigned one is smaller
 	if s.Pods[i].Spec.NodeName != s.Pods[j].Spec.NodeName && (len(s.Pods[i].Spec.NodeName) == 0 || len(s.Pods[j].Spec.NodeName) == 0) {
 		return len(s.Pods[i].Spec.NodeName) == 0
 	}
-	// 2. PodPending < PodUnknown < PodRunning
+	// 3. PodPending < PodUnknown < PodRunning
 	podPhaseToOrdinal := map[v1.PodPhase]int{v1.PodPending: 0, v1.PodUnknown: 1, v1.PodRunning: 2}
 	if podPhaseToOrdinal[s.Pods[i].Status.Phase] != podPhaseToOrdinal[s.Pods[j].Status.Phase] {
 		return podPhaseToOrdinal[s.Pods[i].Status.Phase] < podPhaseToOrdinal[s.Pods[j].Status.Phase]
 	}
-	// 3. Not available < available; Not ready < ready
+	// 4. Not available < available; Not ready < ready
 	// If only one of the pods is not ready, the not ready one is smaller
 	if s.AvailableFunc != nil {
 		if s.AvailableFunc(s.Pods[i]) != s.AvailableFunc(s.Pods[j]) {
@@ -75,14 +84,21 @@ func (s ActivePodsWithRanks) Less(i, j int) bool {
 		return !podutil.IsPodReady(s.Pods[i])
 	}
 
-	// 4. Lower pod-deletion cost < higher pod-deletion-cost
+	// 5. Lower pod-deletion cost < higher pod-deletion-cost
 	pi, _ := getDeletionCostFromPodAnnotations(s.Pods[i].Annotations)
 	pj, _ := getDeletionCostFromPodAnnotations(s.Pods[j].Annotations)
 	if pi != pj {
 		return pi < pj
 	}
 
-	// 5. Higher ranks < lower ranks
+	// 6. Higher policy ranks < lower policy ranks
+	if s.PolicyRanker != nil {
+		if rankI, rankJ := s.PolicyRanker.GetRank(s.Pods[i]), s.PolicyRanker.GetRank(s.Pods[j]); rankI != rankJ {
+			return rankI > rankJ
+		}
+	}
+
+	// 7. Higher ranks < lower ranks
 	var rankI, rankJ float64
 	if s.Ranker != nil {
 		rankI = s.Ranker.GetRank(s.Pods[i])
@@ -94,7 +110,7 @@ func (s ActivePodsWithRanks) Less(i, j int) bool {
 
 	// TODO: take availability into account when we push minReadySeconds information from deployment into pods,
 	//       see https://github.com/kubernetes/kubernetes/issues/22065
-	// 6. Been ready for empty time < less time < more time
+	// 8. Been ready for empty time < less time < more time
 	// If both pods are ready, the latest ready one is smaller
 	if podutil.IsPodReady(s.Pods[i]) && podutil.IsPodReady(s.Pods[j]) {
 		readyTime1 := podReadyTime(s.Pods[i])
@@ -103,11 +119,11 @@ func (s ActivePodsWithRanks) Less(i, j int) bool {
 			return afterOrZero(readyTime1, readyTime2)
 		}
 	}
-	// 7. Pods with containers with higher restart counts < lower restart counts
+	// 9. Pods with containers with higher restart counts < lower restart counts
 	if maxContainerRestarts(s.Pods[i]) != maxContainerRestarts(s.Pods[j]) {
 		return maxContainerRestarts(s.Pods[i]) > maxContainerRestarts(s.Pods[j])
 	}
-	// 8. Empty creation time pods < newer pods < older pods
+	// 10. Empty creation time pods < newer pods < older pods
 	if !s.Pods[i].CreationTimestamp.Equal(&s.Pods[j].CreationTimestamp) {
 		return afterOrZero(&s.Pods[i].CreationTimestamp, &s.Pods[j].CreationTimestamp)
 	}
@@ -114,6 +114,27 @@ func TestSortingActivePods(t *testing.T) {
 	}
 }
 
+func TestSortingActivePodsWithPreferredSelector(t *testing.T) {
+	pods := []*v1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "unscheduled"}, Status: v1.PodStatus{Phase: v1.PodPending}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "degraded", Labels: map[string]string{"health": "degraded"}}, Spec: v1.PodSpec{NodeName: "node"}, Status: v1.PodStatus{Phase: v1.PodRunning}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "healthy", Labels: map[string]string{"health": "ok"}}, Spec: v1.PodSpec{NodeName: "node"}, Status: v1.PodStatus{Phase: v1.PodRunning}},
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{MatchLabels: map[string]string{"health": "degraded"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Sort(ActivePodsWithRanks{Pods: pods, PreferredSelector: selector})
+	var names []string
+	for _, pod := range pods {
+		names = append(names, pod.Name)
+	}
+	if expected := []string{"degraded", "unscheduled", "healthy"}; !reflect.DeepEqual(names, expected) {
+		t.Fatalf("expected sorted %v, got %v", expected, names)
+	}
+}
+
 func TestSameNodeRanker(t *testing.T) {
 	// node a: 0, 4, 10
 	// node b: 3, 6
@@ -24,6 +24,7 @@ import (
 
 	appspub "github.com/openkruise/kruise/apis/apps/pub"
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/control/pubcontrol"
 	"github.com/openkruise/kruise/pkg/features"
 	"github.com/openkruise/kruise/pkg/util"
 	utildiscovery "github.com/openkruise/kruise/pkg/util/discovery"
@@ -48,15 +49,27 @@ import (
 
 const (
 	responseTimeout = time.Minute
+
+	// podUnavailableBudgetRetryInterval is the interval to check PodUnavailableBudget again for the CRR that has been denied
+	podUnavailableBudgetRetryInterval = 10 * time.Second
 )
 
 func init() {
 	flag.IntVar(&concurrentReconciles, "crr-workers", concurrentReconciles, "Max concurrent workers for ContainerRecreateRequest controller.")
+	flag.IntVar(&defaultTTLSecondsAfterFinished, "crr-default-ttl-seconds-after-finished", defaultTTLSecondsAfterFinished,
+		"Default TTL seconds to delete completed ContainerRecreateRequests that have no ttlSecondsAfterFinished, 0 means never.")
+	flag.IntVar(&historyLimit, "crr-history-limit", historyLimit,
+		"Max number of completed ContainerRecreateRequests retained for each Pod, the oldest ones will be deleted. 0 means no limit.")
+	flag.DurationVar(&historyCollectInterval, "crr-history-collect-interval", historyCollectInterval,
+		"Interval to delete the completed ContainerRecreateRequests exceeding crr-history-limit.")
 }
 
 var (
-	concurrentReconciles = 3
-	controllerKind       = appsv1alpha1.SchemeGroupVersion.WithKind("ContainerRecreateRequest")
+	concurrentReconciles           = 3
+	defaultTTLSecondsAfterFinished = 0
+	historyLimit                   = 0
+	historyCollectInterval         = 10 * time.Minute
+	controllerKind                 = appsv1alpha1.SchemeGroupVersion.WithKind("ContainerRecreateRequest")
 )
 
 // Add creates a new ContainerRecreateRequest Controller and adds it to the Manager with default RBAC. The Manager will set fields on the Controller
@@ -70,9 +83,11 @@ func Add(mgr manager.Manager) error {
 
 // newReconciler returns a new reconcile.Reconciler
 func newReconciler(mgr manager.Manager) *ReconcileContainerRecreateRequest {
+	cli := util.NewClientFromManager(mgr, "containerrecreaterequest-controller")
 	return &ReconcileContainerRecreateRequest{
-		Client: util.NewClientFromManager(mgr, "containerrecreaterequest-controller"),
-		clock:  clock.RealClock{},
+		Client:     cli,
+		clock:      clock.RealClock{},
+		pubControl: pubcontrol.NewPubControl(cli),
 	}
 }
 
@@ -96,6 +111,9 @@ func add(mgr manager.Manager, r *ReconcileContainerRecreateRequest) error {
 		return err
 	}
 
+	if historyLimit > 0 {
+		return mgr.Add(&historyCollector{Client: r.Client, historyLimit: historyLimit, interval: historyCollectInterval})
+	}
 	return nil
 }
 
@@ -104,7 +122,8 @@ var _ reconcile.Reconciler = &ReconcileContainerRecreateRequest{}
 // ReconcileContainerRecreateRequest reconciles a ContainerRecreateRequest object
 type ReconcileContainerRecreateRequest struct {
 	client.Client
-	clock clock.Clock
+	clock      clock.Clock
+	pubControl pubcontrol.PubControl
 }
 
 // +kubebuilder:rbac:groups=apps.kruise.io,resources=containerrecreaterequests,verbs=get;list;watch;create;update;patch;delete
@@ -160,8 +179,8 @@ func (r *ReconcileContainerRecreateRequest) Reconcile(_ context.Context, request
 		}
 
 		var leftTime time.Duration
-		if crr.Spec.TTLSecondsAfterFinished != nil {
-			leftTime = time.Duration(*crr.Spec.TTLSecondsAfterFinished)*time.Second - time.Since(crr.Status.CompletionTime.Time)
+		if ttl := getTTLSecondsAfterFinished(crr); ttl != nil {
+			leftTime = time.Duration(*ttl)*time.Second - time.Since(crr.Status.CompletionTime.Time)
 			if leftTime <= 0 {
 				klog.Infof("Deleting CRR %s/%s for ttlSecondsAfterFinished", crr.Namespace, crr.Name)
 				if err = r.Delete(context.TODO(), crr); err != nil {
@@ -202,6 +221,21 @@ func (r *ReconcileContainerRecreateRequest) Reconcile(_ context.Context, request
 	}
 
 	if crr.Status.Phase != appsv1alpha1.ContainerRecreateRequestRecreating {
+		// recreating containers makes pod unavailable, so kruise-daemon waits until PodUnavailableBudget grants it
+		if needPodUnavailableBudgetGrant(crr) {
+			allowed, reason, err := r.podUnavailableBudgetValidatingCRR(pod)
+			if err != nil {
+				return reconcile.Result{}, err
+			} else if !allowed {
+				klog.Infof("CRR %s/%s is denied by PodUnavailableBudget: %s", crr.Namespace, crr.Name, reason)
+				duration.Update(podUnavailableBudgetRetryInterval)
+			} else {
+				body := fmt.Sprintf(`{"metadata":{"labels":{"%s":"true"}}}`, appsv1alpha1.ContainerRecreateRequestPubGrantedKey)
+				if err = r.Patch(context.TODO(), crr, client.RawPatch(types.MergePatchType, []byte(body))); err != nil {
+					return reconcile.Result{}, err
+				}
+			}
+		}
 		return reconcile.Result{RequeueAfter: duration.Get()}, nil
 	}
 
@@ -220,6 +254,43 @@ func (r *ReconcileContainerRecreateRequest) Reconcile(_ context.Context, request
 	return reconcile.Result{RequeueAfter: duration.Get()}, nil
 }
 
+func needPodUnavailableBudgetGrant(crr *appsv1alpha1.ContainerRecreateRequest) bool {
+	if crr.Spec.Strategy != nil && crr.Spec.Strategy.ForceRecreate {
+		return false
+	}
+	return crr.Labels[appsv1alpha1.ContainerRecreateRequestPubGrantedKey] != "true"
+}
+
+// podUnavailableBudgetValidatingCRR returns whether the PodUnavailableBudget of the Pod allows to recreate its containers,
+// and the budget will be consumed if allowed.
+func (r *ReconcileContainerRecreateRequest) podUnavailableBudgetValidatingCRR(pod *v1.Pod) (bool, string, error) {
+	if !utilfeature.DefaultFeatureGate.Enabled(features.PodUnavailableBudgetUpdateGate) {
+		return true, "", nil
+	}
+	pub, err := r.pubControl.GetPubForPod(pod)
+	if err != nil || pub == nil {
+		return true, "", err
+	}
+	if pubcontrol.IsPodNoProtected(pod, pubcontrol.RecreateOperation) {
+		klog.V(3).Infof("pod(%s/%s) contains annotations[%s] for operation(%s), then don't need check pub", pod.Namespace, pod.Name, pubcontrol.PodPubNoProtectionAnnotation, pubcontrol.RecreateOperation)
+		return true, "", nil
+	}
+	return pubcontrol.PodUnavailableBudgetValidatePod(r.Client, r.pubControl, pub, pod, pubcontrol.RecreateOperation, false)
+}
+
+// getTTLSecondsAfterFinished returns the TTL of the completed CRR, which defaults to crr-default-ttl-seconds-after-finished.
+// Note that the CRRs created by ContainerRecreateRequestSet have no default TTL, for they are deleted with the set.
+func getTTLSecondsAfterFinished(crr *appsv1alpha1.ContainerRecreateRequest) *int32 {
+	if crr.Spec.TTLSecondsAfterFinished != nil {
+		return crr.Spec.TTLSecondsAfterFinished
+	}
+	if defaultTTLSecondsAfterFinished > 0 && crr.Labels[appsv1alpha1.ContainerRecreateRequestSetLabelKey] == "" {
+		ttl := int32(defaultTTLSecondsAfterFinished)
+		return &ttl
+	}
+	return nil
+}
+
 func (r *ReconcileContainerRecreateRequest) syncContainerStatuses(crr *appsv1alpha1.ContainerRecreateRequest, pod *v1.Pod) error {
 	syncContainerStatuses := make([]appsv1alpha1.ContainerRecreateRequestSyncContainerStatus, 0, len(crr.Spec.Containers))
 	for i := range crr.Spec.Containers {
@@ -0,0 +1,131 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package containerrecreaterequest
+
+import (
+	"context"
+	"testing"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
+	"github.com/openkruise/kruise/pkg/control/pubcontrol"
+	"github.com/openkruise/kruise/pkg/features"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestReconcileWithPodUnavailableBudget(t *testing.T) {
+	defer utilfeature.SetFeatureGateDuringTest(t, utilfeature.DefaultFeatureGate, features.PodUnavailableBudgetUpdateGate, true)()
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = appsv1alpha1.AddToScheme(scheme)
+	_ = policyv1alpha1.AddToScheme(scheme)
+
+	newPod := func(name string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "default",
+				Name:        name,
+				UID:         types.UID(name + "-uid"),
+				Annotations: map[string]string{pubcontrol.PodRelatedPubAnnotation: "pub-test"},
+			},
+			Status: v1.PodStatus{
+				Phase:      v1.PodRunning,
+				Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}},
+			},
+		}
+	}
+	newCRR := func(pod *v1.Pod, force bool) *appsv1alpha1.ContainerRecreateRequest {
+		return &appsv1alpha1.ContainerRecreateRequest{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:         pod.Namespace,
+				Name:              pod.Name + "-crr",
+				CreationTimestamp: metav1.Now(),
+				Labels:            map[string]string{appsv1alpha1.ContainerRecreateRequestPodUIDKey: string(pod.UID)},
+			},
+			Spec: appsv1alpha1.ContainerRecreateRequestSpec{
+				PodName:  pod.Name,
+				Strategy: &appsv1alpha1.ContainerRecreateRequestStrategy{ForceRecreate: force},
+			},
+			Status: appsv1alpha1.ContainerRecreateRequestStatus{Phase: appsv1alpha1.ContainerRecreateRequestPending},
+		}
+	}
+	maxUnavailable := intstr.FromInt(1)
+	pub := &policyv1alpha1.PodUnavailableBudget{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pub-test", UID: "pub-uid"},
+		Spec:       policyv1alpha1.PodUnavailableBudgetSpec{MaxUnavailable: &maxUnavailable},
+		Status: policyv1alpha1.PodUnavailableBudgetStatus{
+			UnavailableAllowed: 1,
+			UnavailablePods:    map[string]metav1.Time{},
+			DisruptedPods:      map[string]metav1.Time{},
+		},
+	}
+	pod0, pod1, pod2 := newPod("pod-0"), newPod("pod-1"), newPod("pod-2")
+	crr0, crr1, crr2 := newCRR(pod0, false), newCRR(pod1, false), newCRR(pod2, true)
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pub, pod0, pod1, pod2, crr0, crr1, crr2).Build()
+	r := &ReconcileContainerRecreateRequest{Client: fakeClient, clock: clock.RealClock{}, pubControl: pubcontrol.NewPubControl(fakeClient)}
+
+	reconcileAndGet := func(crr *appsv1alpha1.ContainerRecreateRequest) (reconcile.Result, *appsv1alpha1.ContainerRecreateRequest) {
+		key := types.NamespacedName{Namespace: crr.Namespace, Name: crr.Name}
+		res, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+		if err != nil {
+			t.Fatalf("failed to reconcile %s: %v", crr.Name, err)
+		}
+		got := &appsv1alpha1.ContainerRecreateRequest{}
+		if err := fakeClient.Get(context.TODO(), key, got); err != nil {
+			t.Fatalf("failed to get %s: %v", crr.Name, err)
+		}
+		return res, got
+	}
+
+	if _, got := reconcileAndGet(crr0); got.Labels[appsv1alpha1.ContainerRecreateRequestPubGrantedKey] != "true" {
+		t.Fatalf("expected %s granted, got labels %v", crr0.Name, got.Labels)
+	}
+	newPub := &policyv1alpha1.PodUnavailableBudget{}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: pub.Namespace, Name: pub.Name}, newPub); err != nil {
+		t.Fatalf("failed to get pub: %v", err)
+	}
+	if _, ok := newPub.Status.UnavailablePods[pod0.Name]; !ok || newPub.Status.UnavailableAllowed != 0 {
+		t.Fatalf("expected pod-0 recorded in pub, got %#v", newPub.Status)
+	}
+
+	// the budget has been consumed by pod-0
+	res, got := reconcileAndGet(crr1)
+	if got.Labels[appsv1alpha1.ContainerRecreateRequestPubGrantedKey] != "" {
+		t.Fatalf("expected %s not granted, got labels %v", crr1.Name, got.Labels)
+	}
+	if res.RequeueAfter <= 0 || res.RequeueAfter > podUnavailableBudgetRetryInterval {
+		t.Fatalf("expected %s requeued after %v, got %v", crr1.Name, podUnavailableBudgetRetryInterval, res.RequeueAfter)
+	}
+	// the CRR with forceRecreate needs no grant
+	if _, got = reconcileAndGet(crr2); got.Labels[appsv1alpha1.ContainerRecreateRequestPubGrantedKey] != "" {
+		t.Fatalf("expected %s not labeled, got labels %v", crr2.Name, got.Labels)
+	}
+	// reconcile the granted CRR again does not consume budget
+	if _, got = reconcileAndGet(crr0); got.Labels[appsv1alpha1.ContainerRecreateRequestPubGrantedKey] != "true" {
+		t.Fatalf("expected %s granted, got labels %v", crr0.Name, got.Labels)
+	}
+}
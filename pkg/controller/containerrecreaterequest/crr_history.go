@@ -0,0 +1,90 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package containerrecreaterequest
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// historyCollector deletes the oldest completed ContainerRecreateRequests of each Pod periodically,
+// so that only the latest historyLimit ones are retained.
+type historyCollector struct {
+	client.Client
+	historyLimit int
+	interval     time.Duration
+}
+
+// Start runs the collector until the context is done.
+func (g *historyCollector) Start(ctx context.Context) error {
+	klog.Infof("Starting CRR history collector with limit %d every %v", g.historyLimit, g.interval)
+	wait.UntilWithContext(ctx, g.collect, g.interval)
+	return nil
+}
+
+func (g *historyCollector) collect(ctx context.Context) {
+	crrList := &appsv1alpha1.ContainerRecreateRequestList{}
+	if err := g.List(ctx, crrList); err != nil {
+		klog.Errorf("Failed to list CRRs for history collection: %v", err)
+		return
+	}
+
+	for _, crr := range getExceededHistory(crrList.Items, g.historyLimit) {
+		klog.V(3).Infof("Deleting CRR %s/%s of Pod %s for history limit %d", crr.Namespace, crr.Name, crr.Spec.PodName, g.historyLimit)
+		if err := g.Delete(ctx, crr); err != nil && !errors.IsNotFound(err) {
+			klog.Errorf("Failed to delete CRR %s/%s for history limit: %v", crr.Namespace, crr.Name, err)
+		}
+	}
+}
+
+// getExceededHistory returns the completed CRRs of each Pod except the latest historyLimit ones.
+func getExceededHistory(crrs []appsv1alpha1.ContainerRecreateRequest, historyLimit int) []*appsv1alpha1.ContainerRecreateRequest {
+	history := map[types.NamespacedName][]*appsv1alpha1.ContainerRecreateRequest{}
+	for i := range crrs {
+		crr := &crrs[i]
+		// the CRRs created by ContainerRecreateRequestSet are deleted with the set
+		if crr.DeletionTimestamp != nil || crr.Status.CompletionTime == nil || crr.Labels[appsv1alpha1.ContainerRecreateRequestSetLabelKey] != "" {
+			continue
+		}
+		key := types.NamespacedName{Namespace: crr.Namespace, Name: crr.Spec.PodName}
+		history[key] = append(history[key], crr)
+	}
+
+	var exceeded []*appsv1alpha1.ContainerRecreateRequest
+	for _, podHistory := range history {
+		if len(podHistory) <= historyLimit {
+			continue
+		}
+		// the latest completed ones first
+		sort.SliceStable(podHistory, func(i, j int) bool {
+			if !podHistory[i].Status.CompletionTime.Equal(podHistory[j].Status.CompletionTime) {
+				return podHistory[j].Status.CompletionTime.Before(podHistory[i].Status.CompletionTime)
+			}
+			return podHistory[j].CreationTimestamp.Before(&podHistory[i].CreationTimestamp)
+		})
+		exceeded = append(exceeded, podHistory[historyLimit:]...)
+	}
+	return exceeded
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package containerrecreaterequest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestHistoryCollector(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = appsv1alpha1.AddToScheme(scheme)
+
+	now := time.Now()
+	newCRR := func(name, podName string, completedBefore time.Duration) *appsv1alpha1.ContainerRecreateRequest {
+		crr := &appsv1alpha1.ContainerRecreateRequest{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec:       appsv1alpha1.ContainerRecreateRequestSpec{PodName: podName},
+		}
+		if completedBefore > 0 {
+			crr.Status.CompletionTime = &metav1.Time{Time: now.Add(-completedBefore)}
+		}
+		return crr
+	}
+	var objects []client.Object
+	for i := 1; i <= 4; i++ {
+		objects = append(objects, newCRR(fmt.Sprintf("pod-0-%d", i), "pod-0", time.Duration(i)*time.Minute))
+	}
+	objects = append(objects,
+		newCRR("pod-0-active", "pod-0", 0),
+		newCRR("pod-1-1", "pod-1", time.Minute),
+		newCRR("pod-1-2", "pod-1", 2*time.Minute),
+	)
+	fromSet := newCRR("pod-1-set", "pod-1", time.Hour)
+	fromSet.Labels = map[string]string{appsv1alpha1.ContainerRecreateRequestSetLabelKey: "restart"}
+	objects = append(objects, fromSet)
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+	g := &historyCollector{Client: fakeClient, historyLimit: 2, interval: time.Minute}
+	g.collect(context.TODO())
+
+	crrList := &appsv1alpha1.ContainerRecreateRequestList{}
+	if err := fakeClient.List(context.TODO(), crrList); err != nil {
+		t.Fatalf("failed to list CRRs: %v", err)
+	}
+	var names []string
+	for i := range crrList.Items {
+		names = append(names, crrList.Items[i].Name)
+	}
+	sort.Strings(names)
+	expected := []string{"pod-0-1", "pod-0-2", "pod-0-active", "pod-1-1", "pod-1-2", "pod-1-set"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("expected %v retained, got %v", expected, names)
+	}
+}
@@ -0,0 +1,371 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package containerrecreaterequestset
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+	"time"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/features"
+	"github.com/openkruise/kruise/pkg/util"
+	utildiscovery "github.com/openkruise/kruise/pkg/util/discovery"
+	"github.com/openkruise/kruise/pkg/util/expectations"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/klog/v2"
+	kubecontroller "k8s.io/kubernetes/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+func init() {
+	flag.IntVar(&concurrentReconciles, "crrset-workers", concurrentReconciles, "Max concurrent workers for ContainerRecreateRequestSet controller.")
+}
+
+var (
+	concurrentReconciles = 3
+	controllerKind       = appsv1alpha1.SchemeGroupVersion.WithKind("ContainerRecreateRequestSet")
+	scaleExpectations    = expectations.NewScaleExpectations()
+)
+
+const (
+	// deniedRequeueTime is the interval to create the ContainerRecreateRequests again, which have been denied by webhook
+	deniedRequeueTime = 10 * time.Second
+)
+
+// Add creates a new ContainerRecreateRequestSet Controller and adds it to the Manager with default RBAC. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	if !utildiscovery.DiscoverGVK(controllerKind) || !utilfeature.DefaultFeatureGate.Enabled(features.KruiseDaemon) {
+		return nil
+	}
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) *ReconcileContainerRecreateRequestSet {
+	return &ReconcileContainerRecreateRequestSet{
+		Client: util.NewClientFromManager(mgr, "containerrecreaterequestset-controller"),
+		clock:  clock.RealClock{},
+	}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r *ReconcileContainerRecreateRequestSet) error {
+	// Create a new controller
+	c, err := controller.New("containerrecreaterequestset-controller", mgr, controller.Options{Reconciler: r, MaxConcurrentReconciles: concurrentReconciles})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to ContainerRecreateRequestSet
+	err = c.Watch(&source.Kind{Type: &appsv1alpha1.ContainerRecreateRequestSet{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to ContainerRecreateRequest created by ContainerRecreateRequestSet
+	err = c.Watch(&source.Kind{Type: &appsv1alpha1.ContainerRecreateRequest{}}, &crrEventHandler{
+		enqueueHandler: handler.EnqueueRequestForOwner{IsController: true, OwnerType: &appsv1alpha1.ContainerRecreateRequestSet{}},
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+var _ reconcile.Reconciler = &ReconcileContainerRecreateRequestSet{}
+
+// ReconcileContainerRecreateRequestSet reconciles a ContainerRecreateRequestSet object
+type ReconcileContainerRecreateRequestSet struct {
+	client.Client
+	clock clock.Clock
+}
+
+// +kubebuilder:rbac:groups=apps.kruise.io,resources=containerrecreaterequestsets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps.kruise.io,resources=containerrecreaterequestsets/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=apps.kruise.io,resources=containerrecreaterequests,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
+
+// Reconcile reads that state of the cluster for a ContainerRecreateRequestSet object and makes changes based on the state read
+// and what is in the ContainerRecreateRequestSet.Spec
+func (r *ReconcileContainerRecreateRequestSet) Reconcile(_ context.Context, request reconcile.Request) (res reconcile.Result, err error) {
+	start := time.Now()
+	klog.V(5).Infof("Starting to process CRRSet %v", request.NamespacedName)
+	defer func() {
+		if err != nil {
+			klog.Warningf("Failed to process CRRSet %v, elapsedTime %v, error: %v", request.NamespacedName, time.Since(start), err)
+		} else if res.RequeueAfter > 0 {
+			klog.Infof("Finish to process CRRSet %v, elapsedTime %v, RetryAfter %v", request.NamespacedName, time.Since(start), res.RequeueAfter)
+		} else {
+			klog.Infof("Finish to process CRRSet %v, elapsedTime %v", request.NamespacedName, time.Since(start))
+		}
+	}()
+
+	crrSet := &appsv1alpha1.ContainerRecreateRequestSet{}
+	err = r.Get(context.TODO(), request.NamespacedName, crrSet)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// Object not found, return.  Created objects are automatically garbage collected.
+			// For additional cleanup logic use finalizers.
+			scaleExpectations.DeleteExpectations(request.String())
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, err
+	}
+
+	if crrSet.DeletionTimestamp != nil {
+		return reconcile.Result{}, nil
+	}
+
+	// The CRRSet has been finished, the CRRs will be deleted with it by garbage collector
+	if crrSet.Status.CompletionTime != nil {
+		var leftTime time.Duration
+		if crrSet.Spec.TTLSecondsAfterFinished != nil {
+			leftTime = time.Duration(*crrSet.Spec.TTLSecondsAfterFinished)*time.Second - time.Since(crrSet.Status.CompletionTime.Time)
+			if leftTime <= 0 {
+				klog.Infof("Deleting CRRSet %s/%s for ttlSecondsAfterFinished", crrSet.Namespace, crrSet.Name)
+				if err = r.Delete(context.TODO(), crrSet); err != nil {
+					return reconcile.Result{}, fmt.Errorf("delete CRRSet error: %v", err)
+				}
+				return reconcile.Result{}, nil
+			}
+		}
+		return reconcile.Result{RequeueAfter: leftTime}, nil
+	}
+
+	// If scale expectations have not satisfied yet, just skip this reconcile
+	if scaleSatisfied, unsatisfiedDuration, dirtyCRRs := scaleExpectations.SatisfiedExpectations(request.String()); !scaleSatisfied {
+		if unsatisfiedDuration >= expectations.ExpectationTimeout {
+			klog.Warningf("Expectation unsatisfied overtime for CRRSet %v, dirtyCRRs=%v, timeout=%v", request.String(), dirtyCRRs, unsatisfiedDuration)
+			return reconcile.Result{}, nil
+		}
+		klog.V(4).Infof("Not satisfied scale for CRRSet %v, dirtyCRRs=%v", request.String(), dirtyCRRs)
+		return reconcile.Result{RequeueAfter: expectations.ExpectationTimeout - unsatisfiedDuration}, nil
+	}
+
+	newStatus := crrSet.Status.DeepCopy()
+	now := metav1.NewTime(r.clock.Now())
+	if newStatus.StartTime == nil {
+		newStatus.StartTime = &now
+	}
+
+	crrs, err := r.getOwnedCRRs(crrSet)
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to get CRRs: %v", err)
+	}
+	pendingPods, err := r.getPendingPods(crrSet, crrs, newStatus.StartTime)
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to get pods: %v", err)
+	}
+
+	var active []*appsv1alpha1.ContainerRecreateRequest
+	newStatus.Succeeded, newStatus.Failed, newStatus.FailedPods = 0, 0, nil
+	for _, crr := range crrs {
+		if crr.Status.CompletionTime == nil {
+			active = append(active, crr)
+		} else if isCRRSucceeded(crr) {
+			newStatus.Succeeded++
+		} else {
+			newStatus.Failed++
+			newStatus.FailedPods = append(newStatus.FailedPods, crr.Spec.PodName)
+		}
+	}
+	sort.Strings(newStatus.FailedPods)
+	pending := len(pendingPods)
+	newStatus.Desired = int32(len(crrs) + pending)
+	newStatus.Message = ""
+
+	var requeueAfter time.Duration
+	if !crrSet.Spec.Paused && len(pendingPods) > 0 {
+		created, denied, err := r.createCRRs(crrSet, pendingPods, active, int(newStatus.Desired))
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if denied != nil {
+			newStatus.Message = fmt.Sprintf("waiting for recreating denied: %v", denied)
+			requeueAfter = deniedRequeueTime
+		}
+		active = append(active, created...)
+		pending -= len(created)
+	}
+	newStatus.Active = int32(len(active))
+
+	if len(active) == 0 && pending == 0 {
+		newStatus.CompletionTime = &now
+	}
+
+	if !util.IsJSONObjectEqual(&crrSet.Status, newStatus) {
+		crrSet.Status = *newStatus
+		if err = r.Status().Update(context.TODO(), crrSet); err != nil {
+			return reconcile.Result{}, fmt.Errorf("update CRRSet status error: %v", err)
+		}
+	}
+	return reconcile.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// getOwnedCRRs returns the CRRs controlled by the CRRSet.
+func (r *ReconcileContainerRecreateRequestSet) getOwnedCRRs(crrSet *appsv1alpha1.ContainerRecreateRequestSet) ([]*appsv1alpha1.ContainerRecreateRequest, error) {
+	crrList := &appsv1alpha1.ContainerRecreateRequestList{}
+	if err := r.List(context.TODO(), crrList, client.InNamespace(crrSet.Namespace), client.MatchingLabels{appsv1alpha1.ContainerRecreateRequestSetLabelKey: crrSet.Name}); err != nil {
+		return nil, err
+	}
+	var crrs []*appsv1alpha1.ContainerRecreateRequest
+	for i := range crrList.Items {
+		crr := &crrList.Items[i]
+		if owner := metav1.GetControllerOf(crr); owner == nil || owner.UID != crrSet.UID {
+			continue
+		}
+		crrs = append(crrs, crr)
+	}
+	return crrs, nil
+}
+
+// getPendingPods returns the active pods selected that have no CRR created yet, and the pods created after
+// the CRRSet started are ignored for their containers are new.
+func (r *ReconcileContainerRecreateRequestSet) getPendingPods(crrSet *appsv1alpha1.ContainerRecreateRequestSet,
+	crrs []*appsv1alpha1.ContainerRecreateRequest, startTime *metav1.Time) ([]*v1.Pod, error) {
+
+	selector, err := metav1.LabelSelectorAsSelector(crrSet.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+	podList := &v1.PodList{}
+	if err := r.List(context.TODO(), podList, client.InNamespace(crrSet.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+
+	podsWithCRR := make(map[string]struct{}, len(crrs))
+	for _, crr := range crrs {
+		podsWithCRR[crr.Spec.PodName] = struct{}{}
+	}
+	var pods []*v1.Pod
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if _, ok := podsWithCRR[pod.Name]; ok {
+			continue
+		}
+		if !kubecontroller.IsPodActive(pod) || pod.Spec.NodeName == "" || pod.CreationTimestamp.After(startTime.Time) {
+			continue
+		}
+		pods = append(pods, pod)
+	}
+	return pods, nil
+}
+
+// createCRRs creates CRRs for the pending pods in order under the limit of maxUnavailable, and returns the CRRs
+// created and the error if denied by webhook.
+func (r *ReconcileContainerRecreateRequestSet) createCRRs(crrSet *appsv1alpha1.ContainerRecreateRequestSet, pendingPods []*v1.Pod,
+	active []*appsv1alpha1.ContainerRecreateRequest, desired int) ([]*appsv1alpha1.ContainerRecreateRequest, error, error) {
+
+	maxUnavailable := getMaxUnavailable(crrSet, desired)
+	if len(active) >= maxUnavailable {
+		klog.V(3).Infof("CRRSet %s/%s has %d active CRRs >= maxUnavailable %d, so skip to create for the left %d pods",
+			crrSet.Namespace, crrSet.Name, len(active), maxUnavailable, len(pendingPods))
+		return nil, nil, nil
+	}
+
+	domains, err := r.getTopologyDomains(crrSet, pendingPods, active)
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.SliceStable(pendingPods, func(i, j int) bool {
+		if domains[pendingPods[i].Spec.NodeName] != domains[pendingPods[j].Spec.NodeName] {
+			return domains[pendingPods[i].Spec.NodeName] < domains[pendingPods[j].Spec.NodeName]
+		}
+		return pendingPods[i].Name < pendingPods[j].Name
+	})
+	// only the pods in the first domain not completed can be recreated
+	currentDomain := domains[pendingPods[0].Spec.NodeName]
+	for _, crr := range active {
+		nodeName := crr.Labels[appsv1alpha1.ContainerRecreateRequestNodeNameKey]
+		if domain, ok := domains[nodeName]; ok && nodeName != "" && domain < currentDomain {
+			currentDomain = domain
+		}
+	}
+
+	key := types.NamespacedName{Namespace: crrSet.Namespace, Name: crrSet.Name}.String()
+	var created []*appsv1alpha1.ContainerRecreateRequest
+	var denied error
+	for _, pod := range pendingPods {
+		if len(active)+len(created) >= maxUnavailable || domains[pod.Spec.NodeName] != currentDomain {
+			break
+		}
+		crr := newCRR(crrSet, pod)
+		scaleExpectations.ExpectScale(key, expectations.Create, crr.Name)
+		if err := r.Create(context.TODO(), crr); err != nil {
+			scaleExpectations.ObserveScale(key, expectations.Create, crr.Name)
+			if errors.IsForbidden(err) || errors.IsBadRequest(err) {
+				klog.Warningf("CRRSet %s/%s failed to create CRR for Pod %s: %v", crrSet.Namespace, crrSet.Name, pod.Name, err)
+				denied = err
+				break
+			}
+			return nil, nil, fmt.Errorf("create CRR for Pod %s error: %v", pod.Name, err)
+		}
+		klog.V(3).Infof("CRRSet %s/%s has created CRR %s for Pod %s", crrSet.Namespace, crrSet.Name, crr.Name, pod.Name)
+		created = append(created, crr)
+	}
+
+	return created, denied, nil
+}
+
+// getTopologyDomains returns a mapping from the node names of pods and CRRs to the values of topologyKey in node labels.
+func (r *ReconcileContainerRecreateRequestSet) getTopologyDomains(crrSet *appsv1alpha1.ContainerRecreateRequestSet,
+	pods []*v1.Pod, crrs []*appsv1alpha1.ContainerRecreateRequest) (map[string]string, error) {
+
+	domains := map[string]string{}
+	if crrSet.Spec.TopologyKey == "" {
+		return domains, nil
+	}
+	nodeNames := make([]string, 0, len(pods)+len(crrs))
+	for _, pod := range pods {
+		nodeNames = append(nodeNames, pod.Spec.NodeName)
+	}
+	for _, crr := range crrs {
+		nodeNames = append(nodeNames, crr.Labels[appsv1alpha1.ContainerRecreateRequestNodeNameKey])
+	}
+	for _, nodeName := range nodeNames {
+		if _, ok := domains[nodeName]; ok || nodeName == "" {
+			continue
+		}
+		node := &v1.Node{}
+		if err := r.Get(context.TODO(), types.NamespacedName{Name: nodeName}, node); err != nil {
+			if errors.IsNotFound(err) {
+				domains[nodeName] = ""
+				continue
+			}
+			return nil, fmt.Errorf("get Node %s error: %v", nodeName, err)
+		}
+		domains[nodeName] = node.Labels[crrSet.Spec.TopologyKey]
+	}
+	return domains, nil
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package containerrecreaterequestset
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestReconcileContainerRecreateRequestSet(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = appsv1alpha1.AddToScheme(scheme)
+
+	maxUnavailable := intstr.FromInt(2)
+	crrSet := &appsv1alpha1.ContainerRecreateRequestSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "restart", UID: "set-uid"},
+		Spec: appsv1alpha1.ContainerRecreateRequestSetSpec{
+			Selector:       &metav1.LabelSelector{MatchLabels: map[string]string{"app": "demo"}},
+			Template:       appsv1alpha1.ContainerRecreateRequestTemplate{Containers: []appsv1alpha1.ContainerRecreateRequestContainer{{Name: "main"}}},
+			MaxUnavailable: &maxUnavailable,
+			TopologyKey:    v1.LabelTopologyZone,
+		},
+	}
+	objects := []client.Object{crrSet}
+	for _, zone := range []string{"zone-b", "zone-a"} {
+		objects = append(objects, &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-" + zone, Labels: map[string]string{v1.LabelTopologyZone: zone}}})
+	}
+	created := metav1.NewTime(time.Now().Add(-time.Hour))
+	for i, nodeName := range []string{"node-zone-b", "node-zone-a", "node-zone-b", "node-zone-a", "node-zone-a", ""} {
+		objects = append(objects, &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: fmt.Sprintf("pod-%d", i), Labels: map[string]string{"app": "demo"}, CreationTimestamp: created},
+			Spec:       v1.PodSpec{NodeName: nodeName},
+			Status:     v1.PodStatus{Phase: v1.PodRunning},
+		})
+	}
+	objects = append(objects, &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod-other", Labels: map[string]string{"app": "other"}, CreationTimestamp: created},
+		Spec:       v1.PodSpec{NodeName: "node-zone-a"},
+		Status:     v1.PodStatus{Phase: v1.PodRunning},
+	})
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+	r := &ReconcileContainerRecreateRequestSet{Client: fakeClient, clock: clock.RealClock{}}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: crrSet.Namespace, Name: crrSet.Name}}
+	reconcileOnce := func() *appsv1alpha1.ContainerRecreateRequestSet {
+		if _, err := r.Reconcile(context.TODO(), request); err != nil {
+			t.Fatalf("failed to reconcile: %v", err)
+		}
+		// no informer in test, so the CRRs created are observed here
+		scaleExpectations.DeleteExpectations(request.String())
+		newSet := &appsv1alpha1.ContainerRecreateRequestSet{}
+		if err := fakeClient.Get(context.TODO(), request.NamespacedName, newSet); err != nil {
+			t.Fatalf("failed to get CRRSet: %v", err)
+		}
+		return newSet
+	}
+	listCRRs := func() map[string]*appsv1alpha1.ContainerRecreateRequest {
+		crrList := &appsv1alpha1.ContainerRecreateRequestList{}
+		if err := fakeClient.List(context.TODO(), crrList, client.InNamespace(crrSet.Namespace)); err != nil {
+			t.Fatalf("failed to list CRRs: %v", err)
+		}
+		crrs := map[string]*appsv1alpha1.ContainerRecreateRequest{}
+		for i := range crrList.Items {
+			crrs[crrList.Items[i].Spec.PodName] = &crrList.Items[i]
+		}
+		return crrs
+	}
+	podNames := func(crrs map[string]*appsv1alpha1.ContainerRecreateRequest) []string {
+		var names []string
+		for name := range crrs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return names
+	}
+	complete := func(podName string, succeeded bool) {
+		crr := listCRRs()[podName]
+		now := metav1.Now()
+		crr.Status = appsv1alpha1.ContainerRecreateRequestStatus{
+			Phase:                   appsv1alpha1.ContainerRecreateRequestCompleted,
+			CompletionTime:          &now,
+			ContainerRecreateStates: []appsv1alpha1.ContainerRecreateRequestContainerRecreateState{{Name: "main", Phase: appsv1alpha1.ContainerRecreateRequestSucceeded}},
+		}
+		if !succeeded {
+			crr.Status.Message = "failed to kill"
+			crr.Status.ContainerRecreateStates[0].Phase = appsv1alpha1.ContainerRecreateRequestFailed
+		}
+		if err := fakeClient.Status().Update(context.TODO(), crr); err != nil {
+			t.Fatalf("failed to update CRR: %v", err)
+		}
+	}
+
+	// zone-a first, limited by maxUnavailable
+	newSet := reconcileOnce()
+	crrs := listCRRs()
+	if names := podNames(crrs); !reflect.DeepEqual(names, []string{"pod-1", "pod-3"}) {
+		t.Fatalf("expected CRRs for pod-1 and pod-3, got %v", names)
+	}
+	if crr := crrs["pod-1"]; crr.Name != "restart-pod-1" || crr.Labels[appsv1alpha1.ContainerRecreateRequestSetLabelKey] != "restart" ||
+		metav1.GetControllerOf(crr) == nil || metav1.GetControllerOf(crr).UID != crrSet.UID || crr.Spec.Containers[0].Name != "main" {
+		t.Fatalf("unexpected CRR %#v", crr)
+	}
+	if s := newSet.Status; s.Desired != 5 || s.Active != 2 || s.StartTime == nil || s.CompletionTime != nil {
+		t.Fatalf("unexpected status %#v", s)
+	}
+
+	// pods in zone-b are not recreated until all pods in zone-a have completed
+	complete("pod-1", true)
+	newSet = reconcileOnce()
+	if names := podNames(listCRRs()); !reflect.DeepEqual(names, []string{"pod-1", "pod-3", "pod-4"}) {
+		t.Fatalf("expected CRRs for pod-1, pod-3 and pod-4, got %v", names)
+	}
+	complete("pod-3", false)
+	newSet = reconcileOnce()
+	if names := podNames(listCRRs()); len(names) != 3 {
+		t.Fatalf("expected no CRRs in zone-b, got %v", names)
+	}
+	complete("pod-4", true)
+	newSet = reconcileOnce()
+	if names := podNames(listCRRs()); !reflect.DeepEqual(names, []string{"pod-0", "pod-1", "pod-2", "pod-3", "pod-4"}) {
+		t.Fatalf("expected CRRs for all pods, got %v", names)
+	}
+	if s := newSet.Status; s.Desired != 5 || s.Active != 2 || s.Succeeded != 2 || s.Failed != 1 || !reflect.DeepEqual(s.FailedPods, []string{"pod-3"}) {
+		t.Fatalf("unexpected status %#v", s)
+	}
+
+	complete("pod-0", true)
+	complete("pod-2", true)
+	newSet = reconcileOnce()
+	if s := newSet.Status; s.Desired != 5 || s.Active != 0 || s.Succeeded != 4 || s.Failed != 1 || s.CompletionTime == nil {
+		t.Fatalf("unexpected status %#v", s)
+	}
+}
+
+func TestGetCRRName(t *testing.T) {
+	crrSet := &appsv1alpha1.ContainerRecreateRequestSet{ObjectMeta: metav1.ObjectMeta{Name: "restart"}}
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod-0"}}
+	if name := getCRRName(crrSet, pod); name != "restart-pod-0" {
+		t.Fatalf("unexpected name %s", name)
+	}
+
+	longName := make([]byte, 200)
+	for i := range longName {
+		longName[i] = 'a'
+	}
+	crrSet.Name = string(longName)
+	pod.Name = string(longName)
+	name := getCRRName(crrSet, pod)
+	if len(name) > 253 || name == getCRRName(crrSet, &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: string(longName) + "b"}}) {
+		t.Fatalf("unexpected name %s", name)
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package containerrecreaterequestset
+
+import (
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/util/expectations"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+)
+
+type crrEventHandler struct {
+	enqueueHandler handler.EnqueueRequestForOwner
+}
+
+var _ handler.EventHandler = &crrEventHandler{}
+
+func isCRRSetController(controllerRef *metav1.OwnerReference) bool {
+	refGV, err := schema.ParseGroupVersion(controllerRef.APIVersion)
+	if err != nil {
+		klog.Errorf("Could not parse OwnerReference %v APIVersion: %v", controllerRef, err)
+		return false
+	}
+	return controllerRef.Kind == controllerKind.Kind && refGV.Group == controllerKind.Group
+}
+
+func (e *crrEventHandler) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	crr := evt.Object.(*appsv1alpha1.ContainerRecreateRequest)
+	if controllerRef := metav1.GetControllerOf(crr); controllerRef != nil && isCRRSetController(controllerRef) {
+		key := types.NamespacedName{Namespace: crr.Namespace, Name: controllerRef.Name}.String()
+		scaleExpectations.ObserveScale(key, expectations.Create, crr.Name)
+		e.enqueueHandler.Create(evt, q)
+	}
+}
+
+func (e *crrEventHandler) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	crr := evt.ObjectNew.(*appsv1alpha1.ContainerRecreateRequest)
+	oldCRR := evt.ObjectOld.(*appsv1alpha1.ContainerRecreateRequest)
+	// only the completion of CRRs makes sense to the CRRSet
+	if crr.Status.CompletionTime != nil && oldCRR.Status.CompletionTime == nil {
+		e.enqueueHandler.Update(evt, q)
+	}
+}
+
+func (e *crrEventHandler) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	e.enqueueHandler.Delete(evt, q)
+}
+
+func (e *crrEventHandler) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+}
+
+var _ inject.Scheme = &crrEventHandler{}
+
+func (e *crrEventHandler) InjectScheme(s *runtime.Scheme) error {
+	return e.enqueueHandler.InjectScheme(s)
+}
+
+var _ inject.Mapper = &crrEventHandler{}
+
+func (e *crrEventHandler) InjectMapper(m meta.RESTMapper) error {
+	return e.enqueueHandler.InjectMapper(m)
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package containerrecreaterequestset
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const defaultMaxUnavailable = 1
+
+// getCRRName returns the name of the ContainerRecreateRequest for the pod, which is hashed if too long.
+func getCRRName(crrSet *appsv1alpha1.ContainerRecreateRequestSet, pod *v1.Pod) string {
+	name := fmt.Sprintf("%s-%s", crrSet.Name, pod.Name)
+	if len(name) <= validation.DNS1123SubdomainMaxLength {
+		return name
+	}
+	hasher := fnv.New32a()
+	_, _ = hasher.Write([]byte(name))
+	return fmt.Sprintf("%s-%08x", strings.TrimRight(name[:validation.DNS1123SubdomainMaxLength-9], "-."), hasher.Sum32())
+}
+
+func newCRR(crrSet *appsv1alpha1.ContainerRecreateRequestSet, pod *v1.Pod) *appsv1alpha1.ContainerRecreateRequest {
+	template := crrSet.Spec.Template.DeepCopy()
+	return &appsv1alpha1.ContainerRecreateRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: crrSet.Namespace,
+			Name:      getCRRName(crrSet, pod),
+			Labels: map[string]string{
+				appsv1alpha1.ContainerRecreateRequestSetLabelKey: crrSet.Name,
+				appsv1alpha1.ContainerRecreateRequestNodeNameKey: pod.Spec.NodeName,
+			},
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(crrSet, controllerKind)},
+		},
+		Spec: appsv1alpha1.ContainerRecreateRequestSpec{
+			PodName:               pod.Name,
+			Containers:            template.Containers,
+			Strategy:              template.Strategy,
+			ActiveDeadlineSeconds: template.ActiveDeadlineSeconds,
+		},
+	}
+}
+
+// isCRRSucceeded returns whether the completed ContainerRecreateRequest has recreated all the containers.
+func isCRRSucceeded(crr *appsv1alpha1.ContainerRecreateRequest) bool {
+	if crr.Status.Message != "" {
+		return false
+	}
+	for _, state := range crr.Status.ContainerRecreateStates {
+		if state.Phase != appsv1alpha1.ContainerRecreateRequestSucceeded {
+			return false
+		}
+	}
+	return true
+}
+
+func getMaxUnavailable(crrSet *appsv1alpha1.ContainerRecreateRequestSet, desired int) int {
+	if crrSet.Spec.MaxUnavailable == nil {
+		return defaultMaxUnavailable
+	}
+	maxUnavailable, err := intstr.GetScaledValueFromIntOrPercent(crrSet.Spec.MaxUnavailable, desired, true)
+	if err != nil || maxUnavailable < 1 {
+		return defaultMaxUnavailable
+	}
+	return maxUnavailable
+}
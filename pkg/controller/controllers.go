@@ -22,8 +22,11 @@ import (
 	"github.com/openkruise/kruise/pkg/controller/cloneset"
 	containerlauchpriority "github.com/openkruise/kruise/pkg/controller/containerlaunchpriority"
 	"github.com/openkruise/kruise/pkg/controller/containerrecreaterequest"
+	"github.com/openkruise/kruise/pkg/controller/containerrecreaterequestset"
 	"github.com/openkruise/kruise/pkg/controller/daemonset"
 	"github.com/openkruise/kruise/pkg/controller/ephemeraljob"
+	"github.com/openkruise/kruise/pkg/controller/imagedeletejob"
+	"github.com/openkruise/kruise/pkg/controller/imagelistpulljob"
 	"github.com/openkruise/kruise/pkg/controller/imagepulljob"
 	"github.com/openkruise/kruise/pkg/controller/nodeimage"
 	"github.com/openkruise/kruise/pkg/controller/podreadiness"
@@ -45,9 +48,12 @@ func init() {
 	controllerAddFuncs = append(controllerAddFuncs, broadcastjob.Add)
 	controllerAddFuncs = append(controllerAddFuncs, cloneset.Add)
 	controllerAddFuncs = append(controllerAddFuncs, containerrecreaterequest.Add)
+	controllerAddFuncs = append(controllerAddFuncs, containerrecreaterequestset.Add)
 	controllerAddFuncs = append(controllerAddFuncs, daemonset.Add)
 	controllerAddFuncs = append(controllerAddFuncs, nodeimage.Add)
 	controllerAddFuncs = append(controllerAddFuncs, imagepulljob.Add)
+	controllerAddFuncs = append(controllerAddFuncs, imagelistpulljob.Add)
+	controllerAddFuncs = append(controllerAddFuncs, imagedeletejob.Add)
 	controllerAddFuncs = append(controllerAddFuncs, podreadiness.Add)
 	controllerAddFuncs = append(controllerAddFuncs, sidecarset.Add)
 	controllerAddFuncs = append(controllerAddFuncs, statefulset.Add)
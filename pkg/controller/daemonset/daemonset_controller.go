@@ -51,6 +51,7 @@ import (
 	"k8s.io/kubernetes/pkg/controller/daemon/util"
 	daemonsetutil "k8s.io/kubernetes/pkg/controller/daemon/util"
 	"k8s.io/utils/integer"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -65,9 +66,12 @@ import (
 	kruiseclientset "github.com/openkruise/kruise/pkg/client/clientset/versioned"
 	"github.com/openkruise/kruise/pkg/client/clientset/versioned/scheme"
 	kruiseappslisters "github.com/openkruise/kruise/pkg/client/listers/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/control/pubcontrol"
+	"github.com/openkruise/kruise/pkg/features"
 	kruiseutil "github.com/openkruise/kruise/pkg/util"
 	utildiscovery "github.com/openkruise/kruise/pkg/util/discovery"
 	kruiseExpectations "github.com/openkruise/kruise/pkg/util/expectations"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
 	"github.com/openkruise/kruise/pkg/util/inplaceupdate"
 	"github.com/openkruise/kruise/pkg/util/lifecycle"
 	"github.com/openkruise/kruise/pkg/util/ratelimiter"
@@ -90,6 +94,11 @@ var (
 	onceBackoffGC sync.Once
 	// this is a short cut for any sub-functions to notify the reconcile how long to wait to requeue
 	durationStore = requeueduration.DurationStore{}
+
+	// predownload image field
+	minimumReplicasToPreDownloadImage int32 = 3
+	// determined during controller initializing
+	isPreDownloadDisabled bool
 )
 
 const (
@@ -126,6 +135,11 @@ func Add(mgr manager.Manager) error {
 	if !utildiscovery.DiscoverGVK(controllerKind) {
 		return nil
 	}
+	if !utildiscovery.DiscoverGVK(appsv1alpha1.SchemeGroupVersion.WithKind("ImagePullJob")) ||
+		!utilfeature.DefaultFeatureGate.Enabled(features.KruiseDaemon) ||
+		!utilfeature.DefaultFeatureGate.Enabled(features.PreDownloadImageForInPlaceUpdate) {
+		isPreDownloadDisabled = true
+	}
 
 	r, err := newReconciler(mgr)
 	if err != nil {
@@ -185,8 +199,11 @@ func newReconciler(mgr manager.Manager) (reconcile.Reconciler, error) {
 		podLister:                   podLister,
 		nodeLister:                  nodeLister,
 		failedPodsBackoff:           failedPodsBackoff,
+		runNodes:                    newRunNodesRecorder(),
 		inplaceControl:              inplaceupdate.New(cli, revisionAdapter),
 		revisionAdapter:             revisionAdapter,
+		runtimeClient:               cli,
+		pubControl:                  pubcontrol.NewPubControl(cli),
 	}
 	return dsc, err
 }
@@ -274,9 +291,15 @@ type ReconcileDaemonSet struct {
 	nodeLister corelisters.NodeLister
 
 	failedPodsBackoff *flowcontrol.Backoff
+	// runNodes records the nodes that have run the daemon pods for node approval
+	runNodes *runNodesRecorder
 
 	inplaceControl  inplaceupdate.Interface
 	revisionAdapter revisionadapter.Interface
+
+	// runtimeClient and pubControl read the co-located PodUnavailableBudget and the pods protected by it
+	runtimeClient runtimeclient.Client
+	pubControl    pubcontrol.PubControl
 }
 
 // +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;create;update;patch;delete
@@ -286,6 +309,8 @@ type ReconcileDaemonSet struct {
 // +kubebuilder:rbac:groups=apps,resources=controllerrevisions,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps.kruise.io,resources=daemonsets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps.kruise.io,resources=daemonsets/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=policy.kruise.io,resources=podunavailablebudgets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps.kruise.io,resources=imagepulljobs,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile reads that state of the cluster for a DaemonSet object and makes changes based on the state read
 // and what is in the DaemonSet.Spec
@@ -344,6 +369,7 @@ func (dsc *ReconcileDaemonSet) syncDaemonSet(request reconcile.Request) error {
 		if errors.IsNotFound(err) {
 			klog.V(4).Infof("DaemonSet has been deleted %s", dsKey)
 			dsc.expectations.DeleteExpectations(dsKey)
+			dsc.runNodes.forget(dsKey)
 			return nil
 		}
 		return fmt.Errorf("unable to retrieve DaemonSet %s from store: %v", dsKey, err)
@@ -392,6 +418,13 @@ func (dsc *ReconcileDaemonSet) syncDaemonSet(request reconcile.Request) error {
 		return err
 	}
 
+	if !isPreDownloadDisabled && dsc.runtimeClient != nil && ds.Spec.UpdateStrategy.Type == appsv1alpha1.RollingUpdateDaemonSetStrategyType {
+		// pre-download images for new revision before the pods updated in-place
+		if err := dsc.syncImagePreDownload(ds, cur, old); err != nil {
+			klog.Errorf("Failed to sync ImagePullJobs for DaemonSet %s: %v", dsKey, err)
+		}
+	}
+
 	// Process rolling updates if we're ready. For all kinds of update should not be executed if the update
 	// expectation is not satisfied.
 	if !isDaemonSetPaused(ds) {
@@ -465,7 +498,7 @@ func (dsc *ReconcileDaemonSet) updateDaemonSetStatus(ds *appsv1alpha1.DaemonSet,
 		return fmt.Errorf("couldn't get node to daemon pod mapping for DaemonSet %q: %v", ds.Name, err)
 	}
 
-	var desiredNumberScheduled, currentNumberScheduled, numberMisscheduled, numberReady, updatedNumberScheduled, numberAvailable int
+	var desiredNumberScheduled, currentNumberScheduled, numberMisscheduled, numberReady, updatedNumberScheduled, numberAvailable, numberStandby int
 	now := dsc.failedPodsBackoff.Clock.Now()
 	for _, node := range nodeList {
 		shouldRun, _ := nodeShouldRunDaemonPod(node, ds)
@@ -494,6 +527,8 @@ func (dsc *ReconcileDaemonSet) updateDaemonSetStatus(ds *appsv1alpha1.DaemonSet,
 				if util.IsPodUpdated(pod, hash, generation) {
 					updatedNumberScheduled++
 				}
+			} else if dsc.isNodeInStandby(node, ds) {
+				numberStandby++
 			}
 		} else {
 			if scheduled {
@@ -503,7 +538,7 @@ func (dsc *ReconcileDaemonSet) updateDaemonSetStatus(ds *appsv1alpha1.DaemonSet,
 	}
 	numberUnavailable := desiredNumberScheduled - numberAvailable
 
-	err = dsc.storeDaemonSetStatus(ds, desiredNumberScheduled, currentNumberScheduled, numberMisscheduled, numberReady, updatedNumberScheduled, numberAvailable, numberUnavailable, updateObservedGen, hash)
+	err = dsc.storeDaemonSetStatus(ds, desiredNumberScheduled, currentNumberScheduled, numberMisscheduled, numberReady, updatedNumberScheduled, numberAvailable, numberUnavailable, numberStandby, updateObservedGen, hash)
 	if err != nil {
 		return fmt.Errorf("error storing status for DaemonSet %v: %v", ds.Name, err)
 	}
@@ -515,7 +550,7 @@ func (dsc *ReconcileDaemonSet) updateDaemonSetStatus(ds *appsv1alpha1.DaemonSet,
 	return nil
 }
 
-func (dsc *ReconcileDaemonSet) storeDaemonSetStatus(ds *appsv1alpha1.DaemonSet, desiredNumberScheduled, currentNumberScheduled, numberMisscheduled, numberReady, updatedNumberScheduled, numberAvailable, numberUnavailable int, updateObservedGen bool, hash string) error {
+func (dsc *ReconcileDaemonSet) storeDaemonSetStatus(ds *appsv1alpha1.DaemonSet, desiredNumberScheduled, currentNumberScheduled, numberMisscheduled, numberReady, updatedNumberScheduled, numberAvailable, numberUnavailable, numberStandby int, updateObservedGen bool, hash string) error {
 	if int(ds.Status.DesiredNumberScheduled) == desiredNumberScheduled &&
 		int(ds.Status.CurrentNumberScheduled) == currentNumberScheduled &&
 		int(ds.Status.NumberMisscheduled) == numberMisscheduled &&
@@ -523,6 +558,7 @@ func (dsc *ReconcileDaemonSet) storeDaemonSetStatus(ds *appsv1alpha1.DaemonSet,
 		int(ds.Status.UpdatedNumberScheduled) == updatedNumberScheduled &&
 		int(ds.Status.NumberAvailable) == numberAvailable &&
 		int(ds.Status.NumberUnavailable) == numberUnavailable &&
+		int(ds.Status.NumberStandby) == numberStandby &&
 		ds.Status.ObservedGeneration >= ds.Generation &&
 		ds.Status.DaemonSetHash == hash {
 		return nil
@@ -542,6 +578,7 @@ func (dsc *ReconcileDaemonSet) storeDaemonSetStatus(ds *appsv1alpha1.DaemonSet,
 		toUpdate.Status.UpdatedNumberScheduled = int32(updatedNumberScheduled)
 		toUpdate.Status.NumberAvailable = int32(numberAvailable)
 		toUpdate.Status.NumberUnavailable = int32(numberUnavailable)
+		toUpdate.Status.NumberStandby = int32(numberStandby)
 		toUpdate.Status.DaemonSetHash = hash
 
 		if _, updateErr = dsClient.UpdateStatus(context.TODO(), toUpdate, metav1.UpdateOptions{}); updateErr == nil {
@@ -595,13 +632,15 @@ func (dsc *ReconcileDaemonSet) manage(ds *appsv1alpha1.DaemonSet, nodeList []*co
 
 	// This is the first deploy process.
 	if ds.Spec.UpdateStrategy.Type == appsv1alpha1.RollingUpdateDaemonSetStrategyType && ds.Spec.UpdateStrategy.RollingUpdate != nil {
-		if ds.Spec.UpdateStrategy.RollingUpdate.Partition != nil && *ds.Spec.UpdateStrategy.RollingUpdate.Partition != 0 {
-			partition := *ds.Spec.UpdateStrategy.RollingUpdate.Partition
-
+		partition, err := getPartition(ds, nodesDesireScheduled)
+		if err != nil {
+			return err
+		}
+		if partition != 0 {
 			// Creates pods on nodes that needing daemon pod. If progressive annotation is true, the creation will controlled
 			// by partition and only some of daemon pods will be created. Otherwise daemon pods will be created on every
 			// node that need to start a daemon pod.
-			nodesNeedingDaemonPods = GetNodesNeedingPods(newPodCount, nodesDesireScheduled, int(partition), isDaemonSetCreationProgressively(ds), nodesNeedingDaemonPods)
+			nodesNeedingDaemonPods = GetNodesNeedingPods(newPodCount, nodesDesireScheduled, partition, isDaemonSetCreationProgressively(ds), nodesNeedingDaemonPods)
 		}
 	}
 
@@ -778,10 +817,18 @@ func (dsc *ReconcileDaemonSet) podsShouldBeOnNode(
 
 	shouldRun, shouldContinueRunning := nodeShouldRunDaemonPod(node, ds)
 	daemonPods, exists := nodeToDaemonPods[node.Name]
+	if exists && ds.Spec.NodeApproval != nil {
+		dsc.runNodes.record(ds, node.Name)
+	}
 
 	switch {
 	case shouldRun && !exists:
-		// If daemon pod is supposed to be running on node, but isn't, create daemon pod.
+		// If daemon pod is supposed to be running on node, but isn't, create daemon pod
+		// unless the node is not approved yet and has never run the daemon pod.
+		if dsc.isNodeInStandby(node, ds) {
+			klog.V(4).Infof("Node %s is waiting for approval to run daemon pod of DaemonSet %s/%s", node.Name, ds.Namespace, ds.Name)
+			break
+		}
 		nodesNeedingDaemonPods = append(nodesNeedingDaemonPods, node.Name)
 	case shouldContinueRunning:
 		// If a daemon pod failed, delete it
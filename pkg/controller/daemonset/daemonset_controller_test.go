@@ -263,6 +263,7 @@ func NewDaemonSetController(
 		podLister:                   podInformer.Lister(),
 		nodeLister:                  nodeInformer.Lister(),
 		failedPodsBackoff:           failedPodsBackoff,
+		runNodes:                    newRunNodesRecorder(),
 	}
 }
 
@@ -408,3 +409,43 @@ func Test_isControlledByDaemonSet(t *testing.T) {
 		})
 	}
 }
+
+func TestDaemonSetStandbyOnUnapprovedNodes(t *testing.T) {
+	ds := newDaemonSet("foo")
+	ds.Spec.NodeApproval = &appsv1alpha1.DaemonSetNodeApproval{
+		Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"approved": "true"}},
+	}
+	manager, podControl, _, err := newTestController(ds)
+	if err != nil {
+		t.Fatalf("error creating DaemonSets controller: %v", err)
+	}
+	addNodes(manager.nodeStore, 0, 3, nil)
+	addNodes(manager.nodeStore, 3, 2, map[string]string{"approved": "true"})
+	manager.dsStore.Add(ds)
+	expectSyncDaemonSets(t, manager, ds, podControl, 2, 0, 0)
+}
+
+func TestDaemonSetRecreateOnUnapprovedNodes(t *testing.T) {
+	ds := newDaemonSet("foo")
+	ds.Spec.NodeApproval = &appsv1alpha1.DaemonSetNodeApproval{
+		Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"approved": "true"}},
+	}
+	manager, podControl, _, err := newTestController(ds)
+	if err != nil {
+		t.Fatalf("error creating DaemonSets controller: %v", err)
+	}
+	addNodes(manager.nodeStore, 0, 3, nil)
+	manager.dsStore.Add(ds)
+	// the daemon pod has been running on node-0 before it is unapproved
+	pod := newPod("foo-0", "node-0", simpleDaemonSetLabel, ds)
+	manager.podStore.Add(pod)
+	expectSyncDaemonSets(t, manager, ds, podControl, 0, 0, 0)
+
+	// the daemon pod deleted on node-0 should be recreated, but not on the nodes that have never run it
+	manager.podStore.Delete(pod)
+	expectSyncDaemonSets(t, manager, ds, podControl, 1, 0, 0)
+	recreated := &corev1.Pod{Spec: podControl.Templates[0].Spec}
+	if nodeName, err := util.GetTargetNodeName(recreated); err != nil || nodeName != "node-0" {
+		t.Fatalf("expected daemon pod recreated on node-0, got %s, error: %v", nodeName, err)
+	}
+}
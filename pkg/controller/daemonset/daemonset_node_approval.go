@@ -0,0 +1,77 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package daemonset
+
+import (
+	"sync"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// runNodesRecorder records the nodes that have run the daemon pods of each DaemonSet with node approval.
+// The daemon pods deleted by rolling update, evicted or failed on these nodes are replacements, which should be
+// recreated even if the nodes are not approved.
+// The records are kept in memory, and they are rebuilt from the daemon pods in informer cache after restart,
+// including the terminating ones.
+type runNodesRecorder struct {
+	sync.Mutex
+	// key is namespace/name of DaemonSet
+	records map[string]*runNodes
+}
+
+type runNodes struct {
+	uid   types.UID
+	nodes sets.String
+}
+
+func newRunNodesRecorder() *runNodesRecorder {
+	return &runNodesRecorder{records: map[string]*runNodes{}}
+}
+
+func (r *runNodesRecorder) record(ds *appsv1alpha1.DaemonSet, nodeName string) {
+	r.Lock()
+	defer r.Unlock()
+	key := keyFunc(ds)
+	record := r.records[key]
+	if record == nil || record.uid != ds.UID {
+		record = &runNodes{uid: ds.UID, nodes: sets.NewString()}
+		r.records[key] = record
+	}
+	record.nodes.Insert(nodeName)
+}
+
+func (r *runNodesRecorder) hasRun(ds *appsv1alpha1.DaemonSet, nodeName string) bool {
+	r.Lock()
+	defer r.Unlock()
+	record := r.records[keyFunc(ds)]
+	return record != nil && record.uid == ds.UID && record.nodes.Has(nodeName)
+}
+
+func (r *runNodesRecorder) forget(dsKey string) {
+	r.Lock()
+	defer r.Unlock()
+	delete(r.records, dsKey)
+}
+
+// isNodeInStandby returns true if the daemon pod should not be created on the node, which is not approved and
+// has never run the daemon pod of ds.
+func (dsc *ReconcileDaemonSet) isNodeInStandby(node *corev1.Node, ds *appsv1alpha1.DaemonSet) bool {
+	return !IsNodeApproved(node, ds) && !dsc.runNodes.hasRun(ds, node.Name)
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package daemonset
+
+import (
+	"context"
+	"fmt"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	imagejobutilfunc "github.com/openkruise/kruise/pkg/util/imagejob/utilfunction"
+	"github.com/openkruise/kruise/pkg/util/inplaceupdate"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/controller/history"
+)
+
+// syncImagePreDownload creates ImagePullJobs for the nodes of old pods before they are updated in-place,
+// and deletes the jobs once all the pods have been updated.
+func (dsc *ReconcileDaemonSet) syncImagePreDownload(ds *appsv1alpha1.DaemonSet, curRevision *apps.ControllerRevision, oldRevisions []*apps.ControllerRevision) error {
+	nodeToDaemonPods, err := dsc.getNodesToDaemonPods(ds)
+	if err != nil {
+		return fmt.Errorf("couldn't get node to daemon pod mapping for daemon set %q: %v", ds.Name, err)
+	}
+
+	oldRevision := getLatestOldRevisionOfPods(nodeToDaemonPods, oldRevisions)
+	if oldRevision == nil {
+		// delete ImagePullJobs if all pods have been updated
+		return imagejobutilfunc.DeleteJobsForWorkload(dsc.runtimeClient, ds)
+	}
+	return dsc.createImagePullJobsForInPlaceUpdate(ds, oldRevision, curRevision)
+}
+
+// getLatestOldRevisionOfPods returns the latest one of the old revisions that the pods are still in, or nil if there is no old pods.
+func getLatestOldRevisionOfPods(nodeToDaemonPods map[string][]*corev1.Pod, oldRevisions []*apps.ControllerRevision) *apps.ControllerRevision {
+	var latest *apps.ControllerRevision
+	for _, revision := range oldRevisions {
+		if latest != nil && latest.Revision >= revision.Revision {
+			continue
+		}
+		hash := revision.Labels[apps.DefaultDaemonSetUniqueLabelKey]
+		var found bool
+		for _, pods := range nodeToDaemonPods {
+			for _, pod := range pods {
+				if pod.Labels[apps.DefaultDaemonSetUniqueLabelKey] == hash {
+					found = true
+					break
+				}
+			}
+			if found {
+				break
+			}
+		}
+		if found {
+			latest = revision
+		}
+	}
+	return latest
+}
+
+func (dsc *ReconcileDaemonSet) createImagePullJobsForInPlaceUpdate(ds *appsv1alpha1.DaemonSet, currentRevision, updateRevision *apps.ControllerRevision) error {
+	if _, ok := updateRevision.Labels[appsv1alpha1.ImagePreDownloadCreatedKey]; ok {
+		return nil
+	} else if _, ok := updateRevision.Labels[appsv1alpha1.ImagePreDownloadIgnoredKey]; ok {
+		return nil
+	}
+
+	// ignore if update type is not InPlaceIfPossible
+	if ds.Spec.UpdateStrategy.RollingUpdate == nil || ds.Spec.UpdateStrategy.RollingUpdate.Type != appsv1alpha1.InplaceRollingUpdateType {
+		klog.V(4).Infof("DaemonSet %s/%s skipped to create ImagePullJob for rolling update type is not %s",
+			ds.Namespace, ds.Name, appsv1alpha1.InplaceRollingUpdateType)
+		return dsc.patchControllerRevisionLabels(updateRevision, appsv1alpha1.ImagePreDownloadIgnoredKey, "true")
+	}
+
+	// ignore if desired <= minimumReplicasToPreDownloadImage
+	desired := ds.Status.DesiredNumberScheduled
+	if desired <= minimumReplicasToPreDownloadImage {
+		klog.V(4).Infof("DaemonSet %s/%s skipped to create ImagePullJob for desired number %d <= %d",
+			ds.Namespace, ds.Name, desired, minimumReplicasToPreDownloadImage)
+		return dsc.patchControllerRevisionLabels(updateRevision, appsv1alpha1.ImagePreDownloadIgnoredKey, "true")
+	}
+
+	// ignore if all Pods update in one batch
+	partition, err := getPartition(ds, int(desired))
+	if err != nil {
+		klog.Errorf("DaemonSet %s/%s partition value is illegal", ds.Namespace, ds.Name)
+		return err
+	}
+	maxUnavailable, err := unavailableCount(ds, int(desired))
+	if err != nil {
+		return err
+	}
+	if partition == 0 && maxUnavailable >= int(desired) {
+		klog.V(4).Infof("DaemonSet %s/%s skipped to create ImagePullJob for all Pods update in one batch, desired=%d, partition=%d, maxUnavailable=%d",
+			ds.Namespace, ds.Name, desired, partition, maxUnavailable)
+		return dsc.patchControllerRevisionLabels(updateRevision, appsv1alpha1.ImagePreDownloadIgnoredKey, "true")
+	}
+
+	// ignore if this revision can not update in-place
+	if !dsc.inplaceControl.CanUpdateInPlace(currentRevision, updateRevision, getInPlaceUpdateOptions()) {
+		klog.V(4).Infof("DaemonSet %s/%s skipped to create ImagePullJob for %s -> %s can not update in-place",
+			ds.Namespace, ds.Name, currentRevision.Name, updateRevision.Name)
+		return dsc.patchControllerRevisionLabels(updateRevision, appsv1alpha1.ImagePreDownloadIgnoredKey, "true")
+	}
+
+	// start to create jobs
+
+	var pullSecrets []string
+	for _, s := range ds.Spec.Template.Spec.ImagePullSecrets {
+		pullSecrets = append(pullSecrets, s.Name)
+	}
+
+	// pull images on the nodes of the pods not updated yet
+	hash := updateRevision.Labels[apps.DefaultDaemonSetUniqueLabelKey]
+	selector := ds.Spec.Selector.DeepCopy()
+	selector.MatchExpressions = append(selector.MatchExpressions, metav1.LabelSelectorRequirement{
+		Key:      apps.DefaultDaemonSetUniqueLabelKey,
+		Operator: metav1.LabelSelectorOpNotIn,
+		Values:   []string{hash},
+	})
+
+	// As daemonset is the job's owner, we have the convention that all resources owned by daemonset
+	// have to match the selector of daemonset, such as pod and controllerrevision.
+	// So we had better put the labels into jobs.
+	labelMap := make(map[string]string)
+	for k, v := range ds.Spec.Template.Labels {
+		labelMap[k] = v
+	}
+	labelMap[history.ControllerRevisionHashLabel] = hash
+
+	containerImages := diffImagesBetweenRevisions(currentRevision, updateRevision)
+	klog.V(3).Infof("DaemonSet %s/%s begin to create ImagePullJobs for revision %s -> %s: %v",
+		ds.Namespace, ds.Name, currentRevision.Name, updateRevision.Name, containerImages)
+	for name, image := range containerImages {
+		// job name is revision name + container name, it can not be more than 255 characters
+		jobName := fmt.Sprintf("%s-%s", updateRevision.Name, name)
+		err := imagejobutilfunc.CreateJobForWorkload(dsc.runtimeClient, ds, controllerKind, jobName, image, labelMap, *selector, pullSecrets)
+		if err != nil {
+			if !errors.IsAlreadyExists(err) {
+				klog.Errorf("DaemonSet %s/%s failed to create ImagePullJob %s: %v", ds.Namespace, ds.Name, jobName, err)
+				dsc.eventRecorder.Eventf(ds, corev1.EventTypeNormal, "FailedCreateImagePullJob", "failed to create ImagePullJob %s: %v", jobName, err)
+			}
+			continue
+		}
+		klog.V(3).Infof("DaemonSet %s/%s created ImagePullJob %s for image: %s", ds.Namespace, ds.Name, jobName, image)
+		dsc.eventRecorder.Eventf(ds, corev1.EventTypeNormal, "CreatedImagePullJob", "created ImagePullJob %s for image: %s", jobName, image)
+	}
+
+	return dsc.patchControllerRevisionLabels(updateRevision, appsv1alpha1.ImagePreDownloadCreatedKey, "true")
+}
+
+func (dsc *ReconcileDaemonSet) patchControllerRevisionLabels(revision *apps.ControllerRevision, key, value string) error {
+	body := fmt.Sprintf(`{"metadata":{"labels":{"%s":"%s"}}}`, key, value)
+	_, err := dsc.kubeClient.AppsV1().ControllerRevisions(revision.Namespace).Patch(context.TODO(), revision.Name, types.StrategicMergePatchType, []byte(body), metav1.PatchOptions{})
+	return err
+}
+
+func diffImagesBetweenRevisions(oldRevision, newRevision *apps.ControllerRevision) map[string]string {
+	oldTemp, err := inplaceupdate.GetTemplateFromRevision(oldRevision)
+	if err != nil {
+		return nil
+	}
+	newTemp, err := inplaceupdate.GetTemplateFromRevision(newRevision)
+	if err != nil {
+		return nil
+	}
+
+	containerImages := make(map[string]string)
+	for i := range newTemp.Spec.Containers {
+		name := newTemp.Spec.Containers[i].Name
+		newImage := newTemp.Spec.Containers[i].Image
+
+		var found bool
+		for j := range oldTemp.Spec.Containers {
+			if oldTemp.Spec.Containers[j].Name != name {
+				continue
+			}
+			if oldTemp.Spec.Containers[j].Image != newImage {
+				containerImages[name] = newImage
+			}
+			found = true
+			break
+		}
+		if !found {
+			containerImages[name] = newImage
+		}
+	}
+	return containerImages
+}
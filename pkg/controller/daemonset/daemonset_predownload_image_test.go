@@ -0,0 +1,86 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package daemonset
+
+import (
+	"testing"
+
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestGetLatestOldRevisionOfPods(t *testing.T) {
+	newRevision := func(hash string, revision int64) *apps.ControllerRevision {
+		return &apps.ControllerRevision{
+			ObjectMeta: metav1.ObjectMeta{Name: "ds-" + hash, Labels: map[string]string{apps.DefaultDaemonSetUniqueLabelKey: hash}},
+			Revision:   revision,
+		}
+	}
+	newPod := func(hash string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{apps.DefaultDaemonSetUniqueLabelKey: hash}}}
+	}
+	oldRevisions := []*apps.ControllerRevision{newRevision("v1", 1), newRevision("v3", 3), newRevision("v2", 2)}
+
+	cases := []struct {
+		name             string
+		nodeToDaemonPods map[string][]*corev1.Pod
+		expected         string
+	}{
+		{
+			name:             "all pods updated",
+			nodeToDaemonPods: map[string][]*corev1.Pod{"node-1": {newPod("v4")}, "node-2": {newPod("v4")}},
+		},
+		{
+			name:             "pods in old revisions",
+			nodeToDaemonPods: map[string][]*corev1.Pod{"node-1": {newPod("v1")}, "node-2": {newPod("v2")}, "node-3": {newPod("v4")}},
+			expected:         "ds-v2",
+		},
+		{
+			name:             "pods in the latest old revision",
+			nodeToDaemonPods: map[string][]*corev1.Pod{"node-1": {newPod("v3")}, "node-2": {newPod("v1")}},
+			expected:         "ds-v3",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			revision := getLatestOldRevisionOfPods(tc.nodeToDaemonPods, oldRevisions)
+			var name string
+			if revision != nil {
+				name = revision.Name
+			}
+			if name != tc.expected {
+				t.Fatalf("expected revision %q, got %q", tc.expected, name)
+			}
+		})
+	}
+}
+
+func TestDiffImagesBetweenRevisions(t *testing.T) {
+	oldRevision := &apps.ControllerRevision{Data: runtime.RawExtension{
+		Raw: []byte(`{"spec":{"template":{"$patch":"replace","spec":{"containers":[{"name":"main","image":"main:v1"},{"name":"log","image":"log:v1"}]}}}}`),
+	}}
+	newRevision := &apps.ControllerRevision{Data: runtime.RawExtension{
+		Raw: []byte(`{"spec":{"template":{"$patch":"replace","spec":{"containers":[{"name":"main","image":"main:v2"},{"name":"log","image":"log:v1"}]}}}}`),
+	}}
+	images := diffImagesBetweenRevisions(oldRevision, newRevision)
+	if len(images) != 1 || images["main"] != "main:v2" {
+		t.Fatalf("unexpected images %v", images)
+	}
+}
@@ -17,18 +17,23 @@ limitations under the License.
 package daemonset
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"strconv"
 	"sync"
+	"time"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
 	clonesetutils "github.com/openkruise/kruise/pkg/controller/cloneset/utils"
 	"github.com/openkruise/kruise/pkg/util/inplaceupdate"
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
@@ -72,6 +77,7 @@ func (dsc *ReconcileDaemonSet) rollingUpdate(ds *appsv1alpha1.DaemonSet, nodeLis
 		var numUnavailable int
 		var allowedReplacementPods []string
 		var candidatePodsToDelete []string
+		var inflightNodes []string
 		for nodeName, pods := range nodeToDaemonPods {
 			newPod, oldPod, ok := findUpdatedPodsOnNode(ds, pods, hash)
 			if !ok {
@@ -90,6 +96,7 @@ func (dsc *ReconcileDaemonSet) rollingUpdate(ds *appsv1alpha1.DaemonSet, nodeLis
 				if !podutil.IsPodAvailable(newPod, ds.Spec.MinReadySeconds, metav1.Time{Time: now}) {
 					// an unavailable new pod is counted against maxUnavailable
 					numUnavailable++
+					inflightNodes = append(inflightNodes, nodeName)
 					klog.V(5).Infof("DaemonSet %s/%s pod %s on node %s is new and unavailable", ds.Namespace, ds.Name, newPod.Name, nodeName)
 				}
 				if isPodPreDeleting(newPod) {
@@ -128,10 +135,12 @@ func (dsc *ReconcileDaemonSet) rollingUpdate(ds *appsv1alpha1.DaemonSet, nodeLis
 		if remainingUnavailable < 0 {
 			remainingUnavailable = 0
 		}
-		if max := len(candidatePodsToDelete); remainingUnavailable > max {
-			remainingUnavailable = max
+		// Advanced: defer the nodes whose co-located pods can not be disrupted according to the PodUnavailableBudget
+		candidatePodsToDelete, err = dsc.filterPodsByCoLocatedBudget(ds, candidatePodsToDelete, inflightNodes, remainingUnavailable)
+		if err != nil {
+			return err
 		}
-		oldPodsToDelete := append(allowedReplacementPods, candidatePodsToDelete[:remainingUnavailable]...)
+		oldPodsToDelete := append(allowedReplacementPods, candidatePodsToDelete...)
 
 		// Advanced: update pods in-place first and still delete the others
 		if ds.Spec.UpdateStrategy.RollingUpdate.Type == appsv1alpha1.InplaceRollingUpdateType {
@@ -286,7 +295,22 @@ func (dsc *ReconcileDaemonSet) filterDaemonPodsToUpdate(ds *appsv1alpha1.DaemonS
 		}
 	}
 
-	nodeNames, err := dsc.filterDaemonPodsNodeToUpdate(ds, hash, nodeToDaemonPods)
+	if err := dsc.filterDaemonPodsByNodePools(ds, hash, nodeToDaemonPods, dsc.failedPodsBackoff.Clock.Now()); err != nil {
+		return nil, err
+	}
+
+	var nodesDesireScheduled int
+	for _, node := range nodeList {
+		if shouldRun, _ := nodeShouldRunDaemonPod(node, ds); shouldRun {
+			nodesDesireScheduled++
+		}
+	}
+	partition, err := getPartition(ds, nodesDesireScheduled)
+	if err != nil {
+		return nil, err
+	}
+
+	nodeNames, err := dsc.filterDaemonPodsNodeToUpdate(ds, hash, partition, nodeToDaemonPods)
 	if err != nil {
 		return nil, err
 	}
@@ -298,18 +322,10 @@ func (dsc *ReconcileDaemonSet) filterDaemonPodsToUpdate(ds *appsv1alpha1.DaemonS
 	return ret, nil
 }
 
-func (dsc *ReconcileDaemonSet) filterDaemonPodsNodeToUpdate(ds *appsv1alpha1.DaemonSet, hash string, nodeToDaemonPods map[string][]*corev1.Pod) ([]string, error) {
+func (dsc *ReconcileDaemonSet) filterDaemonPodsNodeToUpdate(ds *appsv1alpha1.DaemonSet, hash string, partition int, nodeToDaemonPods map[string][]*corev1.Pod) ([]string, error) {
 	var err error
-	var partition int32
 	var selector labels.Selector
-	if ds.Spec.UpdateStrategy.RollingUpdate != nil && ds.Spec.UpdateStrategy.RollingUpdate.Partition != nil {
-		partition = *ds.Spec.UpdateStrategy.RollingUpdate.Partition
-	}
-	if ds.Spec.UpdateStrategy.RollingUpdate != nil && ds.Spec.UpdateStrategy.RollingUpdate.Selector != nil {
-		if selector, err = metav1.LabelSelectorAsSelector(ds.Spec.UpdateStrategy.RollingUpdate.Selector); err != nil {
-			return nil, err
-		}
-	}
+	var scatterKey string
 
 	var allNodeNames []string
 	for nodeName := range nodeToDaemonPods {
@@ -317,6 +333,15 @@ func (dsc *ReconcileDaemonSet) filterDaemonPodsNodeToUpdate(ds *appsv1alpha1.Dae
 	}
 	sort.Strings(allNodeNames)
 
+	if ds.Spec.UpdateStrategy.RollingUpdate != nil {
+		scatterKey = ds.Spec.UpdateStrategy.RollingUpdate.ScatterTopologyKey
+	}
+	if ds.Spec.UpdateStrategy.RollingUpdate != nil && ds.Spec.UpdateStrategy.RollingUpdate.Selector != nil {
+		if selector, err = metav1.LabelSelectorAsSelector(ds.Spec.UpdateStrategy.RollingUpdate.Selector); err != nil {
+			return nil, err
+		}
+	}
+
 	var updated []string
 	var updating []string
 	var selected []string
@@ -348,13 +373,19 @@ func (dsc *ReconcileDaemonSet) filterDaemonPodsNodeToUpdate(ds *appsv1alpha1.Dae
 		rest = append(rest, nodeName)
 	}
 
-	sorted := append(updated, updating...)
+	candidates := rest
 	if selector != nil {
-		sorted = append(sorted, selected...)
-	} else {
-		sorted = append(sorted, rest...)
+		candidates = selected
+	}
+	if scatterKey != "" {
+		if candidates, err = dsc.scatterNodesByTopology(scatterKey, candidates, append(updated, updating...)); err != nil {
+			return nil, err
+		}
 	}
-	if maxUpdate := len(allNodeNames) - int(partition); maxUpdate <= 0 {
+
+	sorted := append(updated, updating...)
+	sorted = append(sorted, candidates...)
+	if maxUpdate := len(allNodeNames) - partition; maxUpdate <= 0 {
 		return nil, nil
 	} else if maxUpdate < len(sorted) {
 		sorted = sorted[:maxUpdate]
@@ -362,6 +393,216 @@ func (dsc *ReconcileDaemonSet) filterDaemonPodsNodeToUpdate(ds *appsv1alpha1.Dae
 	return sorted, nil
 }
 
+// scatterNodesByTopology interleaves the candidate nodes across the topology domains indicated by the label key.
+// The domains with fewer nodes updated or updating come first, and the nodes without the label are in an empty domain.
+func (dsc *ReconcileDaemonSet) scatterNodesByTopology(key string, candidates, updatedNodes []string) ([]string, error) {
+	getDomain := func(nodeName string) (string, error) {
+		node, err := dsc.nodeLister.Get(nodeName)
+		if err != nil {
+			return "", fmt.Errorf("failed to get node %v: %v", nodeName, err)
+		}
+		return node.Labels[key], nil
+	}
+
+	updatedCount := make(map[string]int)
+	for _, nodeName := range updatedNodes {
+		domain, err := getDomain(nodeName)
+		if err != nil {
+			return nil, err
+		}
+		updatedCount[domain]++
+	}
+
+	var domains []string
+	domainToNodes := make(map[string][]string)
+	for _, nodeName := range candidates {
+		domain, err := getDomain(nodeName)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := domainToNodes[domain]; !ok {
+			domains = append(domains, domain)
+		}
+		domainToNodes[domain] = append(domainToNodes[domain], nodeName)
+	}
+	sort.SliceStable(domains, func(i, j int) bool {
+		if updatedCount[domains[i]] != updatedCount[domains[j]] {
+			return updatedCount[domains[i]] < updatedCount[domains[j]]
+		}
+		return domains[i] < domains[j]
+	})
+
+	// the domains with fewer updated nodes take turns first until they catch up with the others
+	scattered := make([]string, 0, len(candidates))
+	for len(scattered) < len(candidates) {
+		minCount := -1
+		for _, domain := range domains {
+			if len(domainToNodes[domain]) > 0 && (minCount < 0 || updatedCount[domain] < minCount) {
+				minCount = updatedCount[domain]
+			}
+		}
+		for _, domain := range domains {
+			if len(domainToNodes[domain]) == 0 || updatedCount[domain] != minCount {
+				continue
+			}
+			scattered = append(scattered, domainToNodes[domain][0])
+			domainToNodes[domain] = domainToNodes[domain][1:]
+			updatedCount[domain]++
+		}
+	}
+	return scattered, nil
+}
+
+// filterDaemonPodsByNodePools removes the nodes in the node pools not allowed to update yet from nodeToDaemonPods.
+// The nodes in a pool are allowed to update only if all the nodes in the previous pools are updated and available,
+// and the pool is approved if it requires approval. The nodes not in any pool belong to an implicit last pool.
+func (dsc *ReconcileDaemonSet) filterDaemonPodsByNodePools(ds *appsv1alpha1.DaemonSet, hash string, nodeToDaemonPods map[string][]*corev1.Pod, now time.Time) error {
+	rollingUpdate := ds.Spec.UpdateStrategy.RollingUpdate
+	if rollingUpdate == nil || len(rollingUpdate.NodePools) == 0 {
+		return nil
+	}
+	pools := rollingUpdate.NodePools
+	selectors := make([]labels.Selector, len(pools))
+	for i := range pools {
+		selector, err := metav1.LabelSelectorAsSelector(pools[i].Selector)
+		if err != nil {
+			return err
+		}
+		selectors[i] = selector
+	}
+
+	nodeToPool := make(map[string]int, len(nodeToDaemonPods))
+	poolUpdated := make([]bool, len(pools))
+	for i := range poolUpdated {
+		poolUpdated[i] = true
+	}
+	for nodeName, pods := range nodeToDaemonPods {
+		node, err := dsc.nodeLister.Get(nodeName)
+		if err != nil {
+			return fmt.Errorf("failed to get node %v: %v", nodeName, err)
+		}
+		pool := len(pools)
+		for i, selector := range selectors {
+			if selector.Matches(labels.Set(node.Labels)) {
+				pool = i
+				break
+			}
+		}
+		nodeToPool[nodeName] = pool
+		if pool == len(pools) {
+			continue
+		}
+		newPod, oldPod, ok := findUpdatedPodsOnNode(ds, pods, hash)
+		if !ok || oldPod != nil || newPod == nil || !podutil.IsPodAvailable(newPod, ds.Spec.MinReadySeconds, metav1.Time{Time: now}) {
+			poolUpdated[pool] = false
+		}
+	}
+
+	// find the current pool to update, and the pools after it are not allowed
+	approved := sets.NewString(rollingUpdate.ApprovedNodePools...)
+	allowedPool := len(pools)
+	var waitingPool string
+	for i := range pools {
+		if pools[i].RequireApproval && !approved.Has(pools[i].Name) {
+			waitingPool = pools[i].Name
+			allowedPool = i - 1
+			break
+		}
+		if !poolUpdated[i] {
+			allowedPool = i
+			break
+		}
+	}
+
+	var numDeferred int
+	for nodeName, pool := range nodeToPool {
+		if pool <= allowedPool {
+			continue
+		}
+		// keep the nodes already in updating
+		if newPod, oldPod, ok := findUpdatedPodsOnNode(ds, nodeToDaemonPods[nodeName], hash); ok && newPod == nil && !isPodNilOrPreDeleting(oldPod) {
+			delete(nodeToDaemonPods, nodeName)
+			numDeferred++
+		}
+	}
+	if numDeferred > 0 && waitingPool != "" {
+		klog.V(3).Infof("DaemonSet %s/%s node pool %s is waiting for approval, %d nodes deferred", ds.Namespace, ds.Name, waitingPool, numDeferred)
+		dsc.eventRecorder.Eventf(ds, corev1.EventTypeNormal, "WaitingNodePoolApproval", "node pool %s is waiting for approval", waitingPool)
+	}
+	return nil
+}
+
+// filterPodsByCoLocatedBudget returns at most limit pods to restart from podNames, and defers the others whose nodes have
+// more available pods protected by the co-located PodUnavailableBudget than the budget allows to be unavailable.
+// The protected pods on the nodes in updating have been counted against the budget.
+func (dsc *ReconcileDaemonSet) filterPodsByCoLocatedBudget(ds *appsv1alpha1.DaemonSet, podNames, inflightNodes []string, limit int) ([]string, error) {
+	ref := ds.Spec.UpdateStrategy.RollingUpdate.CoLocatedPodUnavailableBudget
+	if ref == nil {
+		if len(podNames) > limit {
+			podNames = podNames[:limit]
+		}
+		return podNames, nil
+	}
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = ds.Namespace
+	}
+	pub := &policyv1alpha1.PodUnavailableBudget{}
+	if err := dsc.runtimeClient.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: ref.Name}, pub); err != nil {
+		if errors.IsNotFound(err) {
+			// defer all the nodes until the budget is created
+			dsc.eventRecorder.Eventf(ds, corev1.EventTypeWarning, "CoLocatedBudgetNotFound", "PodUnavailableBudget %s/%s not found", namespace, ref.Name)
+			return nil, nil
+		}
+		return nil, err
+	}
+	protectedPods, _, err := dsc.pubControl.GetPodsForPub(pub)
+	if err != nil {
+		return nil, err
+	}
+	nodeToProtected := make(map[string]int)
+	for _, pod := range protectedPods {
+		if pod.Spec.NodeName != "" && dsc.pubControl.IsPodReady(pod, pub) {
+			nodeToProtected[pod.Spec.NodeName]++
+		}
+	}
+
+	allowed := int(pub.Status.UnavailableAllowed)
+	for _, nodeName := range inflightNodes {
+		allowed -= nodeToProtected[nodeName]
+	}
+	// the in-flight nodes may have used more than the budget, which should not defer the nodes without protected pods
+	if allowed < 0 {
+		allowed = 0
+	}
+	var podsToRestart []string
+	var numDeferred int
+	for _, podName := range podNames {
+		if len(podsToRestart) >= limit {
+			break
+		}
+		pod, err := dsc.podLister.Pods(ds.Namespace).Get(podName)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		if protected := nodeToProtected[pod.Spec.NodeName]; protected > allowed {
+			numDeferred++
+		} else {
+			allowed -= protected
+			podsToRestart = append(podsToRestart, podName)
+		}
+	}
+	if numDeferred > 0 {
+		klog.V(3).Infof("DaemonSet %s/%s deferred %d nodes by PodUnavailableBudget %s/%s", ds.Namespace, ds.Name, numDeferred, namespace, ref.Name)
+		dsc.eventRecorder.Eventf(ds, corev1.EventTypeNormal, "DeferredByCoLocatedBudget",
+			"deferred %d nodes whose co-located pods can not be disrupted by PodUnavailableBudget %s/%s", numDeferred, namespace, ref.Name)
+	}
+	return podsToRestart, nil
+}
+
 func getInPlaceUpdateOptions() *inplaceupdate.UpdateOptions {
 	return &inplaceupdate.UpdateOptions{GetRevision: func(rev *apps.ControllerRevision) string {
 		return rev.Labels[apps.DefaultDaemonSetUniqueLabelKey]
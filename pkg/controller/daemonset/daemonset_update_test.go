@@ -18,22 +18,28 @@ package daemonset
 
 import (
 	"reflect"
+	"sort"
 	"testing"
 	"time"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
+	"github.com/openkruise/kruise/pkg/control/pubcontrol"
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
 	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
 	"k8s.io/kubernetes/pkg/controller/daemon/util"
 	utilpointer "k8s.io/utils/pointer"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestDaemonSetUpdatesPods(t *testing.T) {
@@ -855,6 +861,65 @@ func TestFilterDaemonPodsNodeToUpdate(t *testing.T) {
 			},
 			expectNodes: []string{"n2", "n3", "n1"},
 		},
+		{
+			name: "Standard,scatter=zone",
+			rolling: &appsv1alpha1.RollingUpdateDaemonSet{
+				Type:               appsv1alpha1.StandardRollingUpdateType,
+				ScatterTopologyKey: "zone",
+			},
+			hash: "v2",
+			nodeToDaemonPods: map[string][]*corev1.Pod{
+				"n1": {
+					{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{apps.DefaultDaemonSetUniqueLabelKey: "v1"}}},
+				},
+				"n2": {
+					{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{apps.DefaultDaemonSetUniqueLabelKey: "v1"}}},
+				},
+				"n3": {
+					{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{apps.DefaultDaemonSetUniqueLabelKey: "v1"}}},
+				},
+				"n4": {
+					{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{apps.DefaultDaemonSetUniqueLabelKey: "v1"}}},
+				},
+			},
+			nodes: []*corev1.Node{
+				{ObjectMeta: metav1.ObjectMeta{Name: "n1", Labels: map[string]string{"zone": "z1"}}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "n2", Labels: map[string]string{"zone": "z1"}}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "n3", Labels: map[string]string{"zone": "z2"}}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "n4", Labels: map[string]string{"zone": "z2"}}},
+			},
+			expectNodes: []string{"n2", "n4", "n1", "n3"},
+		},
+		{
+			name: "Standard,partition=50%,scatter=zone",
+			rolling: &appsv1alpha1.RollingUpdateDaemonSet{
+				Type:               appsv1alpha1.StandardRollingUpdateType,
+				PartitionPercent:   utilpointer.Int32Ptr(50),
+				ScatterTopologyKey: "zone",
+			},
+			hash: "v2",
+			nodeToDaemonPods: map[string][]*corev1.Pod{
+				"n1": {
+					{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{apps.DefaultDaemonSetUniqueLabelKey: "v2"}}},
+				},
+				"n2": {
+					{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{apps.DefaultDaemonSetUniqueLabelKey: "v1"}}},
+				},
+				"n3": {
+					{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{apps.DefaultDaemonSetUniqueLabelKey: "v1"}}},
+				},
+				"n4": {
+					{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{apps.DefaultDaemonSetUniqueLabelKey: "v1"}}},
+				},
+			},
+			nodes: []*corev1.Node{
+				{ObjectMeta: metav1.ObjectMeta{Name: "n1", Labels: map[string]string{"zone": "z1"}}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "n2", Labels: map[string]string{"zone": "z1"}}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "n3", Labels: map[string]string{"zone": "z2"}}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "n4", Labels: map[string]string{"zone": "z2"}}},
+			},
+			expectNodes: []string{"n1", "n4"},
+		},
 	}
 
 	testFn := func(test *testcase, t *testing.T) {
@@ -871,7 +936,11 @@ func TestFilterDaemonPodsNodeToUpdate(t *testing.T) {
 			Type:          appsv1alpha1.RollingUpdateDaemonSetStrategyType,
 			RollingUpdate: test.rolling,
 		}}}
-		got, err := dsc.filterDaemonPodsNodeToUpdate(ds, test.hash, test.nodeToDaemonPods)
+		partition, err := getPartition(ds, len(test.nodeToDaemonPods))
+		if err != nil {
+			t.Fatalf("failed to get partition: %v", err)
+		}
+		got, err := dsc.filterDaemonPodsNodeToUpdate(ds, test.hash, partition, test.nodeToDaemonPods)
 		if err != nil {
 			t.Fatalf("failed to call filterDaemonPodsNodeToUpdate: %v", err)
 		}
@@ -886,3 +955,207 @@ func TestFilterDaemonPodsNodeToUpdate(t *testing.T) {
 		})
 	}
 }
+
+func TestFilterDaemonPodsByNodePools(t *testing.T) {
+	now := time.Now()
+	newPod := func(hash string, ready bool) *corev1.Pod {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{apps.DefaultDaemonSetUniqueLabelKey: hash}}}
+		if ready {
+			pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue, LastTransitionTime: metav1.NewTime(now.Add(-time.Minute))}}
+		}
+		return pod
+	}
+	nodes := []*corev1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "n1", Labels: map[string]string{"pool": "canary"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "n2", Labels: map[string]string{"pool": "general"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "n3", Labels: map[string]string{"pool": "critical"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "n4"}},
+	}
+	nodePools := []appsv1alpha1.DaemonSetNodePool{
+		{Name: "canary", Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"pool": "canary"}}},
+		{Name: "general", Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"pool": "general"}}},
+		{Name: "critical", Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"pool": "critical"}}, RequireApproval: true},
+	}
+
+	tests := []struct {
+		name             string
+		approved         []string
+		nodeToDaemonPods map[string][]*corev1.Pod
+		expectNodes      []string
+	}{
+		{
+			name: "only the first pool",
+			nodeToDaemonPods: map[string][]*corev1.Pod{
+				"n1": {newPod("v1", true)},
+				"n2": {newPod("v1", true)},
+				"n3": {newPod("v1", true)},
+				"n4": {newPod("v1", true)},
+			},
+			expectNodes: []string{"n1"},
+		},
+		{
+			name: "first pool updated but not available",
+			nodeToDaemonPods: map[string][]*corev1.Pod{
+				"n1": {newPod("v2", false)},
+				"n2": {newPod("v1", true)},
+				"n3": {newPod("v1", true)},
+				"n4": {newPod("v1", true)},
+			},
+			expectNodes: []string{"n1"},
+		},
+		{
+			name: "pool waiting for approval",
+			nodeToDaemonPods: map[string][]*corev1.Pod{
+				"n1": {newPod("v2", true)},
+				"n2": {newPod("v2", true)},
+				"n3": {newPod("v1", true)},
+				"n4": {newPod("v1", true)},
+			},
+			expectNodes: []string{"n1", "n2"},
+		},
+		{
+			name:     "pool approved",
+			approved: []string{"critical"},
+			nodeToDaemonPods: map[string][]*corev1.Pod{
+				"n1": {newPod("v2", true)},
+				"n2": {newPod("v2", true)},
+				"n3": {newPod("v1", true)},
+				"n4": {newPod("v1", true)},
+			},
+			expectNodes: []string{"n1", "n2", "n3"},
+		},
+		{
+			name: "nodes not in any pool at last",
+			nodeToDaemonPods: map[string][]*corev1.Pod{
+				"n1": {newPod("v2", true)},
+				"n2": {newPod("v2", true)},
+				"n3": {newPod("v2", true)},
+				"n4": {newPod("v1", true)},
+			},
+			approved:    []string{"critical"},
+			expectNodes: []string{"n1", "n2", "n3", "n4"},
+		},
+	}
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, node := range nodes {
+		if err := indexer.Add(node); err != nil {
+			t.Fatalf("failed to add node into indexer: %v", err)
+		}
+	}
+	dsc := &ReconcileDaemonSet{nodeLister: corelisters.NewNodeLister(indexer), eventRecorder: record.NewFakeRecorder(10)}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ds := &appsv1alpha1.DaemonSet{Spec: appsv1alpha1.DaemonSetSpec{UpdateStrategy: appsv1alpha1.DaemonSetUpdateStrategy{
+				Type: appsv1alpha1.RollingUpdateDaemonSetStrategyType,
+				RollingUpdate: &appsv1alpha1.RollingUpdateDaemonSet{
+					NodePools:         nodePools,
+					ApprovedNodePools: test.approved,
+				},
+			}}}
+			if err := dsc.filterDaemonPodsByNodePools(ds, "v2", test.nodeToDaemonPods, now); err != nil {
+				t.Fatalf("failed to call filterDaemonPodsByNodePools: %v", err)
+			}
+			var got []string
+			for nodeName := range test.nodeToDaemonPods {
+				got = append(got, nodeName)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, test.expectNodes) {
+				t.Fatalf("expected %v, got %v", test.expectNodes, got)
+			}
+		})
+	}
+}
+
+func TestFilterPodsByCoLocatedBudget(t *testing.T) {
+	newAppPod := func(name, nodeName string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Labels: map[string]string{"app": "web"}},
+			Spec:       corev1.PodSpec{NodeName: nodeName},
+			Status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			},
+		}
+	}
+	pub := &policyv1alpha1.PodUnavailableBudget{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web-pub"},
+		Spec:       policyv1alpha1.PodUnavailableBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+		Status:     policyv1alpha1.PodUnavailableBudgetStatus{UnavailableAllowed: 1},
+	}
+	fakeClient := fakeclient.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(pub,
+		newAppPod("web-1", "n1"), newAppPod("web-2", "n1"), newAppPod("web-3", "n2")).Build()
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, nodeName := range []string{"n1", "n2", "n3"} {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "daemon-" + nodeName}, Spec: corev1.PodSpec{NodeName: nodeName}}
+		if err := indexer.Add(pod); err != nil {
+			t.Fatalf("failed to add pod into indexer: %v", err)
+		}
+	}
+	dsc := &ReconcileDaemonSet{
+		podLister:     corelisters.NewPodLister(indexer),
+		eventRecorder: record.NewFakeRecorder(10),
+		runtimeClient: fakeClient,
+		pubControl:    pubcontrol.NewPubControl(fakeClient),
+	}
+	podNames := []string{"daemon-n1", "daemon-n2", "daemon-n3"}
+
+	tests := []struct {
+		name          string
+		ref           *appsv1alpha1.CoLocatedPodUnavailableBudgetReference
+		inflightNodes []string
+		limit         int
+		expectPods    []string
+	}{
+		{
+			name:       "no budget",
+			limit:      2,
+			expectPods: []string{"daemon-n1", "daemon-n2"},
+		},
+		{
+			name:       "defer node with more protected pods than allowed",
+			ref:        &appsv1alpha1.CoLocatedPodUnavailableBudgetReference{Namespace: "default", Name: "web-pub"},
+			limit:      3,
+			expectPods: []string{"daemon-n2", "daemon-n3"},
+		},
+		{
+			name:          "budget consumed by inflight nodes",
+			ref:           &appsv1alpha1.CoLocatedPodUnavailableBudgetReference{Namespace: "default", Name: "web-pub"},
+			inflightNodes: []string{"n2"},
+			limit:         3,
+			expectPods:    []string{"daemon-n3"},
+		},
+		{
+			name:          "inflight nodes use more than budget",
+			ref:           &appsv1alpha1.CoLocatedPodUnavailableBudgetReference{Namespace: "default", Name: "web-pub"},
+			inflightNodes: []string{"n1"},
+			limit:         3,
+			expectPods:    []string{"daemon-n3"},
+		},
+		{
+			name:  "budget not found",
+			ref:   &appsv1alpha1.CoLocatedPodUnavailableBudgetReference{Name: "web-pub"},
+			limit: 3,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ds := &appsv1alpha1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "daemon"},
+				Spec: appsv1alpha1.DaemonSetSpec{UpdateStrategy: appsv1alpha1.DaemonSetUpdateStrategy{
+					Type:          appsv1alpha1.RollingUpdateDaemonSetStrategyType,
+					RollingUpdate: &appsv1alpha1.RollingUpdateDaemonSet{CoLocatedPodUnavailableBudget: test.ref},
+				}},
+			}
+			got, err := dsc.filterPodsByCoLocatedBudget(ds, podNames, test.inflightNodes, test.limit)
+			if err != nil {
+				t.Fatalf("failed to call filterPodsByCoLocatedBudget: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.expectPods) {
+				t.Fatalf("expected %v, got %v", test.expectPods, got)
+			}
+		})
+	}
+}
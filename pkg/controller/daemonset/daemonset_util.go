@@ -22,6 +22,7 @@ import (
 
 	appspub "github.com/openkruise/kruise/apis/apps/pub"
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	kruiseutil "github.com/openkruise/kruise/pkg/util"
 	"github.com/openkruise/kruise/pkg/util/inplaceupdate"
 	"github.com/openkruise/kruise/pkg/util/lifecycle"
 
@@ -117,6 +118,24 @@ func getBurstReplicas(ds *appsv1alpha1.DaemonSet) int {
 	return burstReplicas
 }
 
+// getPartition returns the number of DaemonSet pods remained to be old version.
+// PartitionPercent is calculated from the number of nodes that should be running the daemon pod.
+func getPartition(ds *appsv1alpha1.DaemonSet, nodesDesireScheduled int) (int, error) {
+	rollingUpdate := ds.Spec.UpdateStrategy.RollingUpdate
+	if rollingUpdate == nil {
+		return 0, nil
+	}
+	if rollingUpdate.Partition != nil {
+		return int(*rollingUpdate.Partition), nil
+	}
+	if rollingUpdate.PartitionPercent != nil {
+		percent := intstrutil.FromString(fmt.Sprintf("%d%%", *rollingUpdate.PartitionPercent))
+		desired := int32(nodesDesireScheduled)
+		return kruiseutil.CalculatePartitionReplicas(&percent, &desired)
+	}
+	return 0, nil
+}
+
 // GetPodDaemonSets returns a list of DaemonSets that potentially match a pod.
 // Only the one specified in the Pod's ControllerRef will actually manage it.
 // Returns an error only if no matching DaemonSets are found.
@@ -328,6 +347,34 @@ func NodeShouldUpdateBySelector(node *corev1.Node, ds *appsv1alpha1.DaemonSet) b
 	}
 }
 
+// IsNodeApproved checks if the node is approved to run the daemon pod by ds's node approval.
+// This function does not check NodeShouldRunDaemonPod
+func IsNodeApproved(node *corev1.Node, ds *appsv1alpha1.DaemonSet) bool {
+	approval := ds.Spec.NodeApproval
+	if approval == nil {
+		return true
+	}
+	if approval.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(approval.Selector)
+		if err != nil {
+			// this should not happen if the DaemonSet passed validation
+			return false
+		}
+		if !selector.Matches(labels.Set(node.Labels)) {
+			return false
+		}
+	}
+	if approval.ConditionType != "" {
+		for _, condition := range node.Status.Conditions {
+			if condition.Type == approval.ConditionType {
+				return condition.Status == corev1.ConditionTrue
+			}
+		}
+		return false
+	}
+	return true
+}
+
 func isPodPreDeleting(pod *corev1.Pod) bool {
 	return pod != nil && lifecycle.GetPodLifecycleState(pod) == appspub.LifecycleStatePreparingDelete
 }
@@ -236,6 +236,71 @@ func Test_getBurstReplicas(t *testing.T) {
 	}
 }
 
+func Test_getPartition(t *testing.T) {
+	newRollingUpdate := func(partition, partitionPercent *int32) *appsv1alpha1.DaemonSet {
+		return &appsv1alpha1.DaemonSet{
+			Spec: appsv1alpha1.DaemonSetSpec{
+				UpdateStrategy: appsv1alpha1.DaemonSetUpdateStrategy{
+					Type: appsv1alpha1.RollingUpdateDaemonSetStrategyType,
+					RollingUpdate: &appsv1alpha1.RollingUpdateDaemonSet{
+						Partition:        partition,
+						PartitionPercent: partitionPercent,
+					},
+				},
+			},
+		}
+	}
+	int32Ptr := func(i int32) *int32 { return &i }
+	tests := []struct {
+		name                 string
+		ds                   *appsv1alpha1.DaemonSet
+		nodesDesireScheduled int
+		want                 int
+	}{
+		{
+			name:                 "no rolling update",
+			ds:                   &appsv1alpha1.DaemonSet{},
+			nodesDesireScheduled: 10,
+			want:                 0,
+		},
+		{
+			name:                 "partition",
+			ds:                   newRollingUpdate(int32Ptr(3), nil),
+			nodesDesireScheduled: 10,
+			want:                 3,
+		},
+		{
+			name:                 "partition percent rounded up",
+			ds:                   newRollingUpdate(nil, int32Ptr(25)),
+			nodesDesireScheduled: 10,
+			want:                 3,
+		},
+		{
+			name:                 "partition percent less than 100 updates at least one node",
+			ds:                   newRollingUpdate(nil, int32Ptr(99)),
+			nodesDesireScheduled: 10,
+			want:                 9,
+		},
+		{
+			name:                 "partition percent 100",
+			ds:                   newRollingUpdate(nil, int32Ptr(100)),
+			nodesDesireScheduled: 10,
+			want:                 10,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getPartition(tt.ds, tt.nodesDesireScheduled)
+			if err != nil {
+				t.Fatalf("getPartition() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("getPartition() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestGetPodRevision(t *testing.T) {
 	type args struct {
 		pod metav1.Object
@@ -716,3 +781,62 @@ func newStandardRollingUpdateStrategy(matchLabels map[string]string) appsv1alpha
 	}
 	return strategy
 }
+
+func TestIsNodeApproved(t *testing.T) {
+	for _, tt := range []struct {
+		Title    string
+		Node     *corev1.Node
+		Approval *appsv1alpha1.DaemonSetNodeApproval
+		Expected bool
+	}{
+		{
+			"no node approval",
+			newNode("node1", nil),
+			nil,
+			true,
+		},
+		{
+			"node without approval label",
+			newNode("node1", map[string]string{"key1": "value1"}),
+			&appsv1alpha1.DaemonSetNodeApproval{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"approved": "true"}},
+			},
+			false,
+		},
+		{
+			"node with approval label",
+			newNode("node1", map[string]string{"approved": "true"}),
+			&appsv1alpha1.DaemonSetNodeApproval{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"approved": "true"}},
+			},
+			true,
+		},
+		{
+			"node without approval condition",
+			newNode("node1", map[string]string{"approved": "true"}),
+			&appsv1alpha1.DaemonSetNodeApproval{
+				Selector:      &metav1.LabelSelector{MatchLabels: map[string]string{"approved": "true"}},
+				ConditionType: "Validated",
+			},
+			false,
+		},
+		{
+			"node with approval condition",
+			func() *corev1.Node {
+				node := newNode("node1", nil)
+				node.Status.Conditions = append(node.Status.Conditions, corev1.NodeCondition{Type: "Validated", Status: corev1.ConditionTrue})
+				return node
+			}(),
+			&appsv1alpha1.DaemonSetNodeApproval{ConditionType: "Validated"},
+			true,
+		},
+	} {
+		t.Logf("\t%s", tt.Title)
+		ds := newDaemonSet("ds1")
+		ds.Spec.NodeApproval = tt.Approval
+		approved := IsNodeApproved(tt.Node, ds)
+		if approved != tt.Expected {
+			t.Errorf("IsNodeApproved() = %v, want %v", approved, tt.Expected)
+		}
+	}
+}
@@ -0,0 +1,332 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagedeletejob
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+	"time"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/features"
+	"github.com/openkruise/kruise/pkg/util"
+	utilclient "github.com/openkruise/kruise/pkg/util/client"
+	utildiscovery "github.com/openkruise/kruise/pkg/util/discovery"
+	"github.com/openkruise/kruise/pkg/util/expectations"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
+	utilimagejob "github.com/openkruise/kruise/pkg/util/imagejob"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+func init() {
+	flag.IntVar(&concurrentReconciles, "imagedeletejob-workers", concurrentReconciles, "Max concurrent workers for ImageDeleteJob controller.")
+}
+
+var (
+	concurrentReconciles        = 3
+	controllerKind              = appsv1alpha1.SchemeGroupVersion.WithKind("ImageDeleteJob")
+	resourceVersionExpectations = expectations.NewResourceVersionExpectation()
+)
+
+const (
+	defaultParallelism = 1
+)
+
+// Add creates a new ImageDeleteJob Controller and adds it to the Manager with default RBAC. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	if !utildiscovery.DiscoverGVK(controllerKind) || !utilfeature.DefaultFeatureGate.Enabled(features.KruiseDaemon) {
+		return nil
+	}
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) *ReconcileImageDeleteJob {
+	return &ReconcileImageDeleteJob{
+		Client: util.NewClientFromManager(mgr, "imagedeletejob-controller"),
+		scheme: mgr.GetScheme(),
+		clock:  clock.RealClock{},
+	}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r *ReconcileImageDeleteJob) error {
+	// Create a new controller
+	c, err := controller.New("imagedeletejob-controller", mgr, controller.Options{Reconciler: r, MaxConcurrentReconciles: concurrentReconciles})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to ImageDeleteJob
+	err = c.Watch(&source.Kind{Type: &appsv1alpha1.ImageDeleteJob{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to NodeImage, including the image deleting statuses reported by kruise-daemon on each node
+	err = c.Watch(&source.Kind{Type: &appsv1alpha1.NodeImage{}}, handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+		nodeImage, ok := obj.(*appsv1alpha1.NodeImage)
+		if !ok {
+			return nil
+		}
+		var requests []reconcile.Request
+		for jobName := range nodeImage.Status.ImageDeleteStatuses {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: jobName}})
+		}
+		return requests
+	}))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+var _ reconcile.Reconciler = &ReconcileImageDeleteJob{}
+
+// ReconcileImageDeleteJob reconciles a ImageDeleteJob object
+type ReconcileImageDeleteJob struct {
+	client.Client
+	scheme *runtime.Scheme
+	clock  clock.Clock
+}
+
+// +kubebuilder:rbac:groups=apps.kruise.io,resources=imagedeletejobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps.kruise.io,resources=imagedeletejobs/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=apps.kruise.io,resources=nodeimages,verbs=get;list;watch
+
+// Reconcile reads that state of the cluster for a ImageDeleteJob object and makes changes based on the state read
+// and what is in the ImageDeleteJob.Spec
+func (r *ReconcileImageDeleteJob) Reconcile(_ context.Context, request reconcile.Request) (res reconcile.Result, err error) {
+	start := time.Now()
+	klog.V(5).Infof("Starting to process ImageDeleteJob %v", request.Name)
+	defer func() {
+		if err != nil {
+			klog.Warningf("Failed to process ImageDeleteJob %v, elapsedTime %v, error: %v", request.Name, time.Since(start), err)
+		} else if res.RequeueAfter > 0 {
+			klog.Infof("Finish to process ImageDeleteJob %v, elapsedTime %v, RetryAfter %v", request.Name, time.Since(start), res.RequeueAfter)
+		} else {
+			klog.Infof("Finish to process ImageDeleteJob %v, elapsedTime %v", request.Name, time.Since(start))
+		}
+	}()
+
+	// Fetch the ImageDeleteJob instance
+	job := &appsv1alpha1.ImageDeleteJob{}
+	err = r.Get(context.TODO(), request.NamespacedName, job)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// Object not found, return.  Created objects are automatically garbage collected.
+			// For additional cleanup logic use finalizers.
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, err
+	}
+
+	// If resourceVersion expectations have not satisfied yet, just skip this reconcile
+	resourceVersionExpectations.Observe(job)
+	if isSatisfied, unsatisfiedDuration := resourceVersionExpectations.IsSatisfied(job); !isSatisfied {
+		if unsatisfiedDuration >= expectations.ExpectationTimeout {
+			klog.Warningf("Expectation unsatisfied overtime for %v, timeout=%v", request.String(), unsatisfiedDuration)
+			return reconcile.Result{}, nil
+		}
+		klog.V(4).Infof("Not satisfied resourceVersion for %v", request.String())
+		return reconcile.Result{RequeueAfter: expectations.ExpectationTimeout - unsatisfiedDuration}, nil
+	}
+
+	if job.DeletionTimestamp != nil {
+		return reconcile.Result{}, nil
+	}
+
+	// The Job has been finished
+	if job.Status.CompletionTime != nil {
+		var leftTime time.Duration
+		if job.Spec.TTLSecondsAfterFinished != nil {
+			leftTime = time.Duration(*job.Spec.TTLSecondsAfterFinished)*time.Second - time.Since(job.Status.CompletionTime.Time)
+			if leftTime <= 0 {
+				klog.Infof("Deleting ImageDeleteJob %s for ttlSecondsAfterFinished", job.Name)
+				if err = r.Delete(context.TODO(), job); err != nil {
+					return reconcile.Result{}, fmt.Errorf("delete job error: %v", err)
+				}
+				return reconcile.Result{}, nil
+			}
+		}
+		return reconcile.Result{RequeueAfter: leftTime}, nil
+	}
+
+	nodeNames, reports, err := r.getNodeNamesForJob(job)
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to get nodes: %v", err)
+	}
+
+	newStatus := r.calculateStatus(job, nodeNames, reports)
+	if !util.IsJSONObjectEqual(&job.Status, newStatus) {
+		job.Status = *newStatus
+		if err = r.Status().Update(context.TODO(), job); err != nil {
+			return reconcile.Result{}, fmt.Errorf("update ImageDeleteJob status error: %v", err)
+		}
+		resourceVersionExpectations.Expect(job)
+	}
+
+	if newStatus.CompletionTime == nil && job.Spec.ActiveDeadlineSeconds != nil {
+		leftTime := time.Duration(*job.Spec.ActiveDeadlineSeconds)*time.Second - r.clock.Since(newStatus.StartTime.Time)
+		return reconcile.Result{RequeueAfter: leftTime}, nil
+	}
+	return reconcile.Result{}, nil
+}
+
+// getNodeNamesForJob returns the sorted names of the nodes selected by the job, which have kruise-daemon running on,
+// and the statuses of the job reported by kruise-daemon in NodeImages, keyed by the node name.
+func (r *ReconcileImageDeleteJob) getNodeNamesForJob(job *appsv1alpha1.ImageDeleteJob) ([]string, map[string]appsv1alpha1.ImageDeleteStatus, error) {
+	nodeImageList := &appsv1alpha1.NodeImageList{}
+	if job.Spec.Selector != nil && job.Spec.Selector.Names == nil {
+		selector, err := util.GetFastLabelSelector(&job.Spec.Selector.LabelSelector)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parse selector error: %v", err)
+		}
+		if err := r.List(context.TODO(), nodeImageList, client.MatchingLabelsSelector{Selector: selector}, utilclient.DisableDeepCopy); err != nil {
+			return nil, nil, err
+		}
+	} else if err := r.List(context.TODO(), nodeImageList, utilclient.DisableDeepCopy); err != nil {
+		return nil, nil, err
+	}
+
+	var names sets.String
+	if job.Spec.Selector != nil && job.Spec.Selector.Names != nil {
+		names = sets.NewString(job.Spec.Selector.Names...)
+	}
+	nodeNames := make([]string, 0, len(nodeImageList.Items))
+	reports := make(map[string]appsv1alpha1.ImageDeleteStatus)
+	for i := range nodeImageList.Items {
+		nodeImage := &nodeImageList.Items[i]
+		// the extra shards of NodeImage are not related to nodes directly
+		if _, isShard := utilimagejob.GetNodeNameOfNodeImage(nodeImage); isShard {
+			continue
+		}
+		if names != nil && !names.Has(nodeImage.Name) {
+			continue
+		}
+		nodeNames = append(nodeNames, nodeImage.Name)
+		if report, ok := nodeImage.Status.ImageDeleteStatuses[job.Name]; ok && report.JobUID == job.UID {
+			reports[nodeImage.Name] = report
+		}
+	}
+	sort.Strings(nodeNames)
+	return nodeNames, reports, nil
+}
+
+// calculateStatus aggregates the statuses reported by the nodes, starts the job on more nodes under the parallelism,
+// and completes the job if all the nodes finished.
+func (r *ReconcileImageDeleteJob) calculateStatus(job *appsv1alpha1.ImageDeleteJob, nodeNames []string,
+	reports map[string]appsv1alpha1.ImageDeleteStatus) *appsv1alpha1.ImageDeleteJobStatus {
+	newStatus := job.Status.DeepCopy()
+	now := metav1.NewTime(r.clock.Now())
+	if newStatus.StartTime == nil {
+		newStatus.StartTime = &now
+	}
+
+	existingNodes := sets.NewString(nodeNames...)
+	startedNodes := sets.NewString()
+	for i := range newStatus.NodeStatuses {
+		nodeStatus := &newStatus.NodeStatuses[i]
+		startedNodes.Insert(nodeStatus.NodeName)
+		if nodeStatus.Phase != appsv1alpha1.ImageDeletePhaseRunning {
+			continue
+		}
+		if report, ok := reports[nodeStatus.NodeName]; ok {
+			nodeStatus.Phase = report.Phase
+			nodeStatus.DeletedImages = report.DeletedImages
+			nodeStatus.CompletionTime = report.CompletionTime
+			nodeStatus.Message = report.Message
+		} else if !existingNodes.Has(nodeStatus.NodeName) {
+			nodeStatus.Phase = appsv1alpha1.ImageDeletePhaseFailed
+			nodeStatus.CompletionTime = &now
+			nodeStatus.Message = "node not found"
+		}
+	}
+
+	pastDeadline := job.Spec.ActiveDeadlineSeconds != nil &&
+		now.Sub(newStatus.StartTime.Time) >= time.Duration(*job.Spec.ActiveDeadlineSeconds)*time.Second
+	if pastDeadline {
+		for i := range newStatus.NodeStatuses {
+			nodeStatus := &newStatus.NodeStatuses[i]
+			if nodeStatus.Phase == appsv1alpha1.ImageDeletePhaseRunning {
+				nodeStatus.Phase = appsv1alpha1.ImageDeletePhaseFailed
+				nodeStatus.CompletionTime = &now
+				nodeStatus.Message = "job exceeded activeDeadlineSeconds"
+			}
+		}
+	} else {
+		desired := existingNodes.Union(startedNodes).Len()
+		parallelism := defaultParallelism
+		if job.Spec.Parallelism != nil {
+			parallelism, _ = intstr.GetScaledValueFromIntOrPercent(job.Spec.Parallelism, desired, true)
+		}
+		active := countNodes(newStatus, appsv1alpha1.ImageDeletePhaseRunning)
+		for _, nodeName := range nodeNames {
+			if active >= parallelism {
+				break
+			}
+			if startedNodes.Has(nodeName) {
+				continue
+			}
+			newStatus.NodeStatuses = append(newStatus.NodeStatuses, appsv1alpha1.ImageDeleteJobNodeStatus{
+				NodeName:  nodeName,
+				Phase:     appsv1alpha1.ImageDeletePhaseRunning,
+				StartTime: &now,
+			})
+			startedNodes.Insert(nodeName)
+			active++
+			klog.V(3).Infof("ImageDeleteJob %s starts to delete images on node %s", job.Name, nodeName)
+		}
+	}
+
+	newStatus.Desired = int32(existingNodes.Union(startedNodes).Len())
+	newStatus.Active = int32(countNodes(newStatus, appsv1alpha1.ImageDeletePhaseRunning))
+	newStatus.Succeeded = int32(countNodes(newStatus, appsv1alpha1.ImageDeletePhaseSucceeded))
+	newStatus.Failed = int32(countNodes(newStatus, appsv1alpha1.ImageDeletePhaseFailed))
+	if pastDeadline || newStatus.Succeeded+newStatus.Failed == newStatus.Desired {
+		newStatus.CompletionTime = &now
+	}
+	return newStatus
+}
+
+func countNodes(status *appsv1alpha1.ImageDeleteJobStatus, phase appsv1alpha1.ImageDeletePhase) int {
+	var count int
+	for i := range status.NodeStatuses {
+		if status.NodeStatuses[i].Phase == phase {
+			count++
+		}
+	}
+	return count
+}
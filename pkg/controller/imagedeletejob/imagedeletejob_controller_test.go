@@ -0,0 +1,118 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagedeletejob
+
+import (
+	"context"
+	"testing"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func newNodeImage(name string, labels map[string]string) *appsv1alpha1.NodeImage {
+	return &appsv1alpha1.NodeImage{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+func TestReconcileImageDeleteJob(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = appsv1alpha1.AddToScheme(scheme)
+
+	parallelism := intstr.FromInt(2)
+	job := &appsv1alpha1.ImageDeleteJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "gc", UID: "uid-gc"},
+		Spec: appsv1alpha1.ImageDeleteJobSpec{
+			Images:      []string{"nginx:1.21"},
+			Selector:    &appsv1alpha1.ImagePullJobNodeSelector{LabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"pool": "a"}}},
+			Parallelism: &parallelism,
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		job,
+		newNodeImage("node-1", map[string]string{"pool": "a"}),
+		newNodeImage("node-2", map[string]string{"pool": "a"}),
+		newNodeImage("node-3", map[string]string{"pool": "a"}),
+		newNodeImage("node-4", map[string]string{"pool": "b"}),
+	).Build()
+	r := &ReconcileImageDeleteJob{Client: fakeClient, scheme: scheme, clock: clock.RealClock{}}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Name: job.Name}}
+
+	getJob := func() *appsv1alpha1.ImageDeleteJob {
+		newJob := &appsv1alpha1.ImageDeleteJob{}
+		if err := fakeClient.Get(context.TODO(), request.NamespacedName, newJob); err != nil {
+			t.Fatalf("failed to get job: %v", err)
+		}
+		return newJob
+	}
+
+	if _, err := r.Reconcile(context.TODO(), request); err != nil {
+		t.Fatalf("failed to reconcile: %v", err)
+	}
+	status := getJob().Status
+	if status.Desired != 3 || status.Active != 2 || len(status.NodeStatuses) != 2 || status.CompletionTime != nil {
+		t.Fatalf("expected to start on 2 of 3 nodes, but got %+v", status)
+	}
+	if status.NodeStatuses[0].NodeName != "node-1" || status.NodeStatuses[1].NodeName != "node-2" {
+		t.Fatalf("unexpected nodes started: %+v", status.NodeStatuses)
+	}
+
+	// report sets the status reported by kruise-daemon into the NodeImage
+	report := func(nodeName string, status appsv1alpha1.ImageDeleteStatus) {
+		nodeImage := &appsv1alpha1.NodeImage{}
+		if err := fakeClient.Get(context.TODO(), types.NamespacedName{Name: nodeName}, nodeImage); err != nil {
+			t.Fatalf("failed to get NodeImage: %v", err)
+		}
+		nodeImage.Status.ImageDeleteStatuses = map[string]appsv1alpha1.ImageDeleteStatus{job.Name: status}
+		if err := fakeClient.Status().Update(context.TODO(), nodeImage); err != nil {
+			t.Fatalf("failed to update NodeImage status: %v", err)
+		}
+	}
+
+	// kruise-daemon on node-1 reports succeeded and node-2 reports failed, and the stale report of node-3 is ignored
+	report("node-1", appsv1alpha1.ImageDeleteStatus{JobUID: job.UID, Phase: appsv1alpha1.ImageDeletePhaseSucceeded, DeletedImages: []string{"nginx:1.21"}})
+	report("node-2", appsv1alpha1.ImageDeleteStatus{JobUID: job.UID, Phase: appsv1alpha1.ImageDeletePhaseFailed, Message: "failed"})
+	report("node-3", appsv1alpha1.ImageDeleteStatus{JobUID: "uid-old", Phase: appsv1alpha1.ImageDeletePhaseSucceeded})
+	resourceVersionExpectations.Delete(getJob())
+	if _, err := r.Reconcile(context.TODO(), request); err != nil {
+		t.Fatalf("failed to reconcile: %v", err)
+	}
+	status = getJob().Status
+	if status.Active != 1 || status.Succeeded != 1 || status.Failed != 1 || status.NodeStatuses[2].NodeName != "node-3" || status.CompletionTime != nil {
+		t.Fatalf("expected to start on node-3, but got %+v", status)
+	}
+	if status.NodeStatuses[0].DeletedImages[0] != "nginx:1.21" || status.NodeStatuses[1].Message != "failed" {
+		t.Fatalf("expected reports aggregated, but got %+v", status.NodeStatuses)
+	}
+
+	report("node-3", appsv1alpha1.ImageDeleteStatus{JobUID: job.UID, Phase: appsv1alpha1.ImageDeletePhaseSucceeded})
+	resourceVersionExpectations.Delete(getJob())
+	if _, err := r.Reconcile(context.TODO(), request); err != nil {
+		t.Fatalf("failed to reconcile: %v", err)
+	}
+	status = getJob().Status
+	if status.Active != 0 || status.Succeeded != 2 || status.Failed != 1 || status.CompletionTime == nil {
+		t.Fatalf("expected job completed, but got %+v", status)
+	}
+}
@@ -0,0 +1,300 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagelistpulljob
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/features"
+	"github.com/openkruise/kruise/pkg/util"
+	utildiscovery "github.com/openkruise/kruise/pkg/util/discovery"
+	"github.com/openkruise/kruise/pkg/util/expectations"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+func init() {
+	flag.IntVar(&concurrentReconciles, "imagelistpulljob-workers", concurrentReconciles, "Max concurrent workers for ImageListPullJob controller.")
+}
+
+var (
+	concurrentReconciles        = 3
+	controllerKind              = appsv1alpha1.SchemeGroupVersion.WithKind("ImageListPullJob")
+	resourceVersionExpectations = expectations.NewResourceVersionExpectation()
+)
+
+// Add creates a new ImageListPullJob Controller and adds it to the Manager with default RBAC. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	if !utildiscovery.DiscoverGVK(controllerKind) || !utilfeature.DefaultFeatureGate.Enabled(features.KruiseDaemon) {
+		return nil
+	}
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) *ReconcileImageListPullJob {
+	return &ReconcileImageListPullJob{
+		Client: util.NewClientFromManager(mgr, "imagelistpulljob-controller"),
+		scheme: mgr.GetScheme(),
+		clock:  clock.RealClock{},
+	}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r *ReconcileImageListPullJob) error {
+	// Create a new controller
+	c, err := controller.New("imagelistpulljob-controller", mgr, controller.Options{Reconciler: r, MaxConcurrentReconciles: concurrentReconciles})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to ImageListPullJob
+	err = c.Watch(&source.Kind{Type: &appsv1alpha1.ImageListPullJob{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to ImagePullJob created by ImageListPullJob
+	err = c.Watch(&source.Kind{Type: &appsv1alpha1.ImagePullJob{}}, &handler.EnqueueRequestForOwner{
+		IsController: true, OwnerType: &appsv1alpha1.ImageListPullJob{},
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+var _ reconcile.Reconciler = &ReconcileImageListPullJob{}
+
+// ReconcileImageListPullJob reconciles a ImageListPullJob object
+type ReconcileImageListPullJob struct {
+	client.Client
+	scheme *runtime.Scheme
+	clock  clock.Clock
+}
+
+// +kubebuilder:rbac:groups=apps.kruise.io,resources=imagelistpulljobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps.kruise.io,resources=imagelistpulljobs/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=apps.kruise.io,resources=imagepulljobs,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile reads that state of the cluster for a ImageListPullJob object and makes changes based on the state read
+// and what is in the ImageListPullJob.Spec
+func (r *ReconcileImageListPullJob) Reconcile(_ context.Context, request reconcile.Request) (res reconcile.Result, err error) {
+	start := time.Now()
+	klog.V(5).Infof("Starting to process ImageListPullJob %v", request.NamespacedName)
+	defer func() {
+		if err != nil {
+			klog.Warningf("Failed to process ImageListPullJob %v, elapsedTime %v, error: %v", request.NamespacedName, time.Since(start), err)
+		} else if res.RequeueAfter > 0 {
+			klog.Infof("Finish to process ImageListPullJob %v, elapsedTime %v, RetryAfter %v", request.NamespacedName, time.Since(start), res.RequeueAfter)
+		} else {
+			klog.Infof("Finish to process ImageListPullJob %v, elapsedTime %v", request.NamespacedName, time.Since(start))
+		}
+	}()
+
+	// Fetch the ImageListPullJob instance
+	job := &appsv1alpha1.ImageListPullJob{}
+	err = r.Get(context.TODO(), request.NamespacedName, job)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// Object not found, return.  Created objects are automatically garbage collected.
+			// For additional cleanup logic use finalizers.
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, err
+	}
+
+	// If resourceVersion expectations have not satisfied yet, just skip this reconcile
+	resourceVersionExpectations.Observe(job)
+	if isSatisfied, unsatisfiedDuration := resourceVersionExpectations.IsSatisfied(job); !isSatisfied {
+		if unsatisfiedDuration >= expectations.ExpectationTimeout {
+			klog.Warningf("Expectation unsatisfied overtime for %v, timeout=%v", request.String(), unsatisfiedDuration)
+			return reconcile.Result{}, nil
+		}
+		klog.V(4).Infof("Not satisfied resourceVersion for %v", request.String())
+		return reconcile.Result{RequeueAfter: expectations.ExpectationTimeout - unsatisfiedDuration}, nil
+	}
+
+	if job.DeletionTimestamp != nil {
+		return reconcile.Result{}, nil
+	}
+
+	// The Job has been finished, the ImagePullJobs will be deleted with it by garbage collector
+	if job.Status.CompletionTime != nil {
+		var leftTime time.Duration
+		if job.Spec.CompletionPolicy.TTLSecondsAfterFinished != nil {
+			leftTime = time.Duration(*job.Spec.CompletionPolicy.TTLSecondsAfterFinished)*time.Second - time.Since(job.Status.CompletionTime.Time)
+			if leftTime <= 0 {
+				klog.Infof("Deleting ImageListPullJob %s/%s for ttlSecondsAfterFinished", job.Namespace, job.Name)
+				if err = r.Delete(context.TODO(), job); err != nil {
+					return reconcile.Result{}, fmt.Errorf("delete job error: %v", err)
+				}
+				return reconcile.Result{}, nil
+			}
+		}
+		return reconcile.Result{RequeueAfter: leftTime}, nil
+	}
+
+	// Get all ImagePullJobs created by this ImageListPullJob
+	imagePullJobs, err := r.getOwnedImagePullJobs(job)
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to get ImagePullJobs: %v", err)
+	}
+
+	// Sync ImagePullJobs to the images in spec
+	if err = r.syncImagePullJobs(job, imagePullJobs); err != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to sync ImagePullJobs: %v", err)
+	}
+
+	newStatus := r.calculateStatus(job, imagePullJobs)
+	if !util.IsJSONObjectEqual(&job.Status, newStatus) {
+		job.Status = *newStatus
+		if err = r.Status().Update(context.TODO(), job); err != nil {
+			return reconcile.Result{}, fmt.Errorf("update ImageListPullJob status error: %v", err)
+		}
+		resourceVersionExpectations.Expect(job)
+	}
+	return reconcile.Result{}, nil
+}
+
+// getOwnedImagePullJobs returns a mapping from image to the ImagePullJob controlled by the job.
+func (r *ReconcileImageListPullJob) getOwnedImagePullJobs(job *appsv1alpha1.ImageListPullJob) (map[string]*appsv1alpha1.ImagePullJob, error) {
+	jobList := &appsv1alpha1.ImagePullJobList{}
+	if err := r.List(context.TODO(), jobList, client.InNamespace(job.Namespace), client.MatchingLabels{appsv1alpha1.ImageListPullJobLabelKey: job.Name}); err != nil {
+		return nil, err
+	}
+
+	imageToJob := make(map[string]*appsv1alpha1.ImagePullJob, len(jobList.Items))
+	for i := range jobList.Items {
+		imagePullJob := &jobList.Items[i]
+		if owner := metav1.GetControllerOf(imagePullJob); owner == nil || owner.UID != job.UID {
+			continue
+		}
+		imageToJob[imagePullJob.Spec.Image] = imagePullJob
+	}
+	return imageToJob, nil
+}
+
+// syncImagePullJobs creates the ImagePullJobs for the images not pulled yet, updates the ones with the old template
+// and deletes the ones whose images have been removed from spec.
+func (r *ReconcileImageListPullJob) syncImagePullJobs(job *appsv1alpha1.ImageListPullJob, imagePullJobs map[string]*appsv1alpha1.ImagePullJob) error {
+	images := make(map[string]struct{}, len(job.Spec.Images))
+	for _, image := range job.Spec.Images {
+		images[image] = struct{}{}
+		expected := newImagePullJob(job, image)
+		imagePullJob, exists := imagePullJobs[image]
+		if !exists {
+			if err := r.Create(context.TODO(), expected); err != nil && !errors.IsAlreadyExists(err) {
+				return fmt.Errorf("create ImagePullJob for image %s error: %v", image, err)
+			}
+			klog.V(3).Infof("ImageListPullJob %s/%s has created ImagePullJob %s for image %s", job.Namespace, job.Name, expected.Name, image)
+			continue
+		}
+		if imagePullJob.Status.CompletionTime != nil || apiequality.Semantic.DeepEqual(imagePullJob.Spec, expected.Spec) {
+			continue
+		}
+		// the verification of ImagePullJob is immutable, so recreate it to verify the image again
+		if !apiequality.Semantic.DeepEqual(imagePullJob.Spec.Verification, expected.Spec.Verification) {
+			if err := r.Delete(context.TODO(), imagePullJob); err != nil && !errors.IsNotFound(err) {
+				return fmt.Errorf("delete ImagePullJob %s error: %v", imagePullJob.Name, err)
+			}
+			klog.V(3).Infof("ImageListPullJob %s/%s has deleted ImagePullJob %s for verification changed", job.Namespace, job.Name, imagePullJob.Name)
+			continue
+		}
+		newImagePullJob := imagePullJob.DeepCopy()
+		newImagePullJob.Spec = expected.Spec
+		if err := r.Update(context.TODO(), newImagePullJob); err != nil {
+			return fmt.Errorf("update ImagePullJob %s error: %v", imagePullJob.Name, err)
+		}
+		klog.V(3).Infof("ImageListPullJob %s/%s has updated ImagePullJob %s for image %s", job.Namespace, job.Name, imagePullJob.Name, image)
+	}
+
+	for image, imagePullJob := range imagePullJobs {
+		if _, exists := images[image]; exists {
+			continue
+		}
+		if err := r.Delete(context.TODO(), imagePullJob); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("delete ImagePullJob %s error: %v", imagePullJob.Name, err)
+		}
+		klog.V(3).Infof("ImageListPullJob %s/%s has deleted ImagePullJob %s for image %s removed", job.Namespace, job.Name, imagePullJob.Name, image)
+	}
+	return nil
+}
+
+func (r *ReconcileImageListPullJob) calculateStatus(job *appsv1alpha1.ImageListPullJob, imagePullJobs map[string]*appsv1alpha1.ImagePullJob) *appsv1alpha1.ImageListPullJobStatus {
+	newStatus := appsv1alpha1.ImageListPullJobStatus{
+		StartTime: job.Status.StartTime,
+		Desired:   int32(len(job.Spec.Images)),
+	}
+	now := metav1.NewTime(r.clock.Now())
+	if newStatus.StartTime == nil {
+		newStatus.StartTime = &now
+	}
+
+	for _, image := range job.Spec.Images {
+		imagePullJob, exists := imagePullJobs[image]
+		if !exists {
+			newStatus.ImageStatuses = append(newStatus.ImageStatuses, appsv1alpha1.ImageListPullJobImageStatus{
+				Image:        image,
+				ImagePullJob: getImagePullJobName(job, image),
+			})
+			continue
+		}
+
+		newStatus.ImageStatuses = append(newStatus.ImageStatuses, appsv1alpha1.ImageListPullJobImageStatus{
+			Image:          image,
+			ImagePullJob:   imagePullJob.Name,
+			Desired:        imagePullJob.Status.Desired,
+			Active:         imagePullJob.Status.Active,
+			Succeeded:      imagePullJob.Status.Succeeded,
+			Failed:         imagePullJob.Status.Failed,
+			CompletionTime: imagePullJob.Status.CompletionTime,
+			Message:        imagePullJob.Status.Message,
+		})
+		if imagePullJob.Status.CompletionTime == nil {
+			newStatus.Active++
+			continue
+		}
+		newStatus.Completed++
+		if imagePullJob.Status.Succeeded == imagePullJob.Status.Desired {
+			newStatus.Succeeded++
+		}
+	}
+
+	if job.Spec.CompletionPolicy.Type != appsv1alpha1.Never && newStatus.Completed == newStatus.Desired {
+		newStatus.CompletionTime = &now
+	}
+	return &newStatus
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagelistpulljob
+
+import (
+	"context"
+	"testing"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	utilpointer "k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestReconcileImageListPullJob(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = appsv1alpha1.AddToScheme(scheme)
+
+	job := &appsv1alpha1.ImageListPullJob{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "preheat", UID: "list-uid"},
+		Spec: appsv1alpha1.ImageListPullJobSpec{
+			Images: []string{"nginx:1.21", "redis:6", "busybox:1.35"},
+			ImagePullJobTemplate: appsv1alpha1.ImagePullJobTemplate{
+				CompletionPolicy: appsv1alpha1.CompletionPolicy{
+					Type:                    appsv1alpha1.Always,
+					TTLSecondsAfterFinished: utilpointer.Int32(300),
+				},
+			},
+		},
+	}
+	now := metav1.Now()
+	succeededJob := newImagePullJob(job, "nginx:1.21")
+	succeededJob.Status = appsv1alpha1.ImagePullJobStatus{Desired: 3, Succeeded: 3, CompletionTime: &now}
+	failedJob := newImagePullJob(job, "redis:6")
+	failedJob.Status = appsv1alpha1.ImagePullJobStatus{Desired: 3, Succeeded: 2, Failed: 1, CompletionTime: &now, FailedNodes: []string{"node-1"}}
+	removedJob := newImagePullJob(job, "alpine:3.15")
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(job, succeededJob, failedJob, removedJob).Build()
+	r := &ReconcileImageListPullJob{Client: fakeClient, scheme: scheme, clock: clock.RealClock{}}
+	// the ImagePullJobs created are observed in the second round
+	for i := 0; i < 2; i++ {
+		if _, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Namespace: job.Namespace, Name: job.Name}}); err != nil {
+			t.Fatalf("failed to reconcile: %v", err)
+		}
+	}
+
+	jobList := &appsv1alpha1.ImagePullJobList{}
+	if err := fakeClient.List(context.TODO(), jobList, client.InNamespace(job.Namespace)); err != nil {
+		t.Fatalf("failed to list ImagePullJobs: %v", err)
+	}
+	images := map[string]*appsv1alpha1.ImagePullJob{}
+	for i := range jobList.Items {
+		images[jobList.Items[i].Spec.Image] = &jobList.Items[i]
+	}
+	if len(images) != 3 || images["busybox:1.35"] == nil || images["alpine:3.15"] != nil {
+		t.Fatalf("expected ImagePullJobs of the images in spec, but got %v", images)
+	}
+	created := images["busybox:1.35"]
+	if created.Spec.CompletionPolicy.TTLSecondsAfterFinished != nil {
+		t.Fatalf("expected no ttlSecondsAfterFinished for ImagePullJob, but got %v", *created.Spec.CompletionPolicy.TTLSecondsAfterFinished)
+	}
+	if owner := metav1.GetControllerOf(created); owner == nil || owner.UID != job.UID {
+		t.Fatalf("expected ImagePullJob controlled by ImageListPullJob, but got %v", owner)
+	}
+
+	newJob := &appsv1alpha1.ImageListPullJob{}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: job.Namespace, Name: job.Name}, newJob); err != nil {
+		t.Fatalf("failed to get ImageListPullJob: %v", err)
+	}
+	status := newJob.Status
+	if status.Desired != 3 || status.Active != 1 || status.Completed != 2 || status.Succeeded != 1 || status.CompletionTime != nil {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+	if len(status.ImageStatuses) != 3 || status.ImageStatuses[1].Image != "redis:6" || status.ImageStatuses[1].Failed != 1 || status.ImageStatuses[1].Succeeded != 2 {
+		t.Fatalf("unexpected image statuses: %+v", status.ImageStatuses)
+	}
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagelistpulljob
+
+import (
+	"fmt"
+	"hash/fnv"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+)
+
+// getImagePullJobName returns the name of the ImagePullJob for the image, which is stable
+// so that the job will not be created twice with a stale cache.
+func getImagePullJobName(job *appsv1alpha1.ImageListPullJob, image string) string {
+	hasher := fnv.New32a()
+	hasher.Write([]byte(image))
+	return fmt.Sprintf("%s-%s", job.Name, rand.SafeEncodeString(fmt.Sprint(hasher.Sum32())))
+}
+
+func newImagePullJob(job *appsv1alpha1.ImageListPullJob, image string) *appsv1alpha1.ImagePullJob {
+	imagePullJob := &appsv1alpha1.ImagePullJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       job.Namespace,
+			Name:            getImagePullJobName(job, image),
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(job, controllerKind)},
+			Labels:          map[string]string{appsv1alpha1.ImageListPullJobLabelKey: job.Name},
+		},
+		Spec: appsv1alpha1.ImagePullJobSpec{
+			Image:                image,
+			ImagePullJobTemplate: *job.Spec.ImagePullJobTemplate.DeepCopy(),
+		},
+	}
+	// the ImagePullJobs are deleted together with the ImageListPullJob
+	imagePullJob.Spec.CompletionPolicy.TTLSecondsAfterFinished = nil
+	return imagePullJob
+}
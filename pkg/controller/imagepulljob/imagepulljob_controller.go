@@ -24,13 +24,13 @@ import (
 	"time"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
-	daemonutil "github.com/openkruise/kruise/pkg/daemon/util"
 	"github.com/openkruise/kruise/pkg/features"
 	"github.com/openkruise/kruise/pkg/util"
 	utildiscovery "github.com/openkruise/kruise/pkg/util/discovery"
 	"github.com/openkruise/kruise/pkg/util/expectations"
 	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
 	utilimagejob "github.com/openkruise/kruise/pkg/util/imagejob"
+	"github.com/openkruise/kruise/pkg/util/imageverify"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -49,10 +49,12 @@ import (
 
 func init() {
 	flag.IntVar(&concurrentReconciles, "imagepulljob-workers", concurrentReconciles, "Max concurrent workers for ImagePullJob controller.")
+	flag.IntVar(&clusterParallelism, "imagepulljob-cluster-parallelism", clusterParallelism, "Max pulling tasks of all ImagePullJobs active in the cluster, 0 means no limit.")
 }
 
 var (
 	concurrentReconciles        = 3
+	clusterParallelism          = 0
 	controllerKind              = appsv1alpha1.SchemeGroupVersion.WithKind("ImagePullJob")
 	resourceVersionExpectations = expectations.NewResourceVersionExpectation()
 )
@@ -60,6 +62,10 @@ var (
 const (
 	defaultParallelism = 1
 	minRequeueTime     = time.Second
+	// throttledRequeueTime is the interval to check again for the job throttled by the cluster parallelism
+	throttledRequeueTime = 5 * time.Second
+	// verifyTimeout is the timeout to verify the signatures of the image from registry
+	verifyTimeout = time.Minute
 )
 
 // Add creates a new ImagePullJob Controller and adds it to the Manager with default RBAC. The Manager will set fields on the Controller
@@ -120,6 +126,7 @@ type ReconcileImagePullJob struct {
 
 // +kubebuilder:rbac:groups=apps.kruise.io,resources=imagepulljobs,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps.kruise.io,resources=imagepulljobs/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
 
 // Reconcile reads that state of the cluster for a ImagePullJob object and makes changes based on the state read
 // and what is in the ImagePullJob.Spec
@@ -181,6 +188,11 @@ func (r *ReconcileImagePullJob) Reconcile(_ context.Context, request reconcile.R
 		return reconcile.Result{RequeueAfter: leftTime}, nil
 	}
 
+	// Verify the signatures of the image before pulling it on any node
+	if job.Spec.Verification != nil && len(job.Status.VerifiedDigest) == 0 {
+		return reconcile.Result{}, r.verifyImage(job)
+	}
+
 	// Get all NodeImage related to this ImagePullJob
 	nodeImages, err := utilimagejob.GetNodeImagesForJob(r.Client, job)
 	if err != nil {
@@ -207,7 +219,8 @@ func (r *ReconcileImagePullJob) Reconcile(_ context.Context, request reconcile.R
 	}
 
 	// Sync image to more NodeImages
-	if err = r.syncNodeImages(job, newStatus, notSyncedNodeImages); err != nil {
+	throttled, err := r.syncNodeImages(job, newStatus, notSyncedNodeImages)
+	if err != nil {
 		return reconcile.Result{}, fmt.Errorf("failed to sync NodeImages: %v", err)
 	}
 
@@ -225,14 +238,65 @@ func (r *ReconcileImagePullJob) Reconcile(_ context.Context, request reconcile.R
 		if leftTime < minRequeueTime {
 			leftTime = minRequeueTime
 		}
+		if throttled && leftTime > throttledRequeueTime {
+			leftTime = throttledRequeueTime
+		}
 		return reconcile.Result{RequeueAfter: leftTime}, nil
 	}
+	if throttled {
+		return reconcile.Result{RequeueAfter: throttledRequeueTime}, nil
+	}
 	return reconcile.Result{}, nil
 }
 
-func (r *ReconcileImagePullJob) syncNodeImages(job *appsv1alpha1.ImagePullJob, newStatus *appsv1alpha1.ImagePullJobStatus, notSyncedNodeImages []string) error {
+// verifyImage verifies the signatures of the image, and marks the job failed if no valid signature found.
+func (r *ReconcileImagePullJob) verifyImage(job *appsv1alpha1.ImagePullJob) error {
+	var pullSecrets []v1.Secret
+	for _, name := range job.Spec.PullSecrets {
+		secret := v1.Secret{}
+		if err := r.Get(context.TODO(), types.NamespacedName{Namespace: job.Namespace, Name: name}, &secret); err != nil {
+			if errors.IsNotFound(err) {
+				klog.Warningf("Pull secret %s/%s of ImagePullJob %s not found", job.Namespace, name, job.Name)
+				continue
+			}
+			return fmt.Errorf("get pull secret %s error: %v", name, err)
+		}
+		pullSecrets = append(pullSecrets, secret)
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), verifyTimeout)
+	defer cancel()
+	dgst, err := imageverify.Verify(ctx, job.Spec.Image, job.Spec.Verification, pullSecrets)
+	if err != nil && !imageverify.IsVerificationError(err) {
+		return fmt.Errorf("failed to verify image %s: %v", job.Spec.Image, err)
+	}
+
+	newStatus := job.Status.DeepCopy()
+	now := metav1.NewTime(r.clock.Now())
+	if newStatus.StartTime == nil {
+		newStatus.StartTime = &now
+	}
+	if err != nil {
+		klog.Warningf("ImagePullJob %s/%s failed to verify image %s: %v", job.Namespace, job.Name, job.Spec.Image, err)
+		newStatus.CompletionTime = &now
+		newStatus.Message = fmt.Sprintf("image signature verification failed: %v", err)
+	} else {
+		klog.Infof("ImagePullJob %s/%s verified image %s with digest %s", job.Namespace, job.Name, job.Spec.Image, dgst)
+		newStatus.VerifiedDigest = dgst.String()
+	}
+
+	job.Status = *newStatus
+	if err = r.Status().Update(context.TODO(), job); err != nil {
+		return fmt.Errorf("update ImagePullJob status error: %v", err)
+	}
+	resourceVersionExpectations.Expect(job)
+	return nil
+}
+
+// syncNodeImages syncs the image into the NodeImages not synced yet, and returns whether it is throttled by the cluster parallelism.
+func (r *ReconcileImagePullJob) syncNodeImages(job *appsv1alpha1.ImagePullJob, newStatus *appsv1alpha1.ImagePullJobStatus, notSyncedNodeImages []string) (bool, error) {
 	if len(notSyncedNodeImages) == 0 {
-		return nil
+		return false, nil
 	}
 
 	parallelismLimit := defaultParallelism
@@ -243,18 +307,34 @@ func (r *ReconcileImagePullJob) syncNodeImages(job *appsv1alpha1.ImagePullJob, n
 	if parallelism <= 0 {
 		klog.V(3).Infof("Find ImagePullJob %s/%s have active pulling %d >= parallelism %d, so skip to sync the left %d NodeImages",
 			job.Namespace, job.Name, newStatus.Active, parallelismLimit, len(notSyncedNodeImages))
-		return nil
+		return false, nil
 	}
 	if len(notSyncedNodeImages) < parallelism {
 		parallelism = len(notSyncedNodeImages)
 	}
 
+	if clusterParallelism > 0 {
+		clusterActive, err := r.getClusterActivePulling(job)
+		if err != nil {
+			return false, err
+		}
+		clusterActive += int(newStatus.Active)
+		if clusterLeft := clusterParallelism - clusterActive; clusterLeft < parallelism {
+			parallelism = clusterLeft
+		}
+		if parallelism <= 0 {
+			klog.V(3).Infof("Find ImagePullJob %s/%s throttled for active pulling %d >= cluster parallelism %d, so skip to sync the left %d NodeImages",
+				job.Namespace, job.Name, clusterActive, clusterParallelism, len(notSyncedNodeImages))
+			return true, nil
+		}
+	}
+
 	ownerRef := getOwnerRef(job)
 	secrets := getSecrets(job)
 	pullPolicy := getImagePullPolicy(job)
 
 	now := metav1.NewTime(r.clock.Now())
-	imageName, imageTag, _ := daemonutil.NormalizeImageRefToNameTag(job.Spec.Image)
+	imageName, imageTag, _ := getImageNameAndPullTag(job)
 	for i := 0; i < parallelism; i++ {
 		var skip bool
 		updateErr := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
@@ -324,33 +404,53 @@ func (r *ReconcileImagePullJob) syncNodeImages(job *appsv1alpha1.ImagePullJob, n
 			return nil
 		})
 		if updateErr != nil {
-			return fmt.Errorf("update NodeImage %s error: %v", notSyncedNodeImages[i], updateErr)
+			return false, fmt.Errorf("update NodeImage %s error: %v", notSyncedNodeImages[i], updateErr)
 		} else if skip {
 			klog.V(4).Infof("ImagePullJob %s/%s find %s already synced in NodeImage %s", job.Namespace, job.Name, job.Spec.Image, notSyncedNodeImages[i])
 			continue
 		}
 		klog.V(3).Infof("ImagePullJob %s/%s has synced %s into NodeImage %s", job.Namespace, job.Name, job.Spec.Image, notSyncedNodeImages[i])
 	}
-	return nil
+	return false, nil
+}
+
+// getClusterActivePulling returns the number of active pulling tasks of the other ImagePullJobs in the cluster.
+func (r *ReconcileImagePullJob) getClusterActivePulling(job *appsv1alpha1.ImagePullJob) (int, error) {
+	jobList := &appsv1alpha1.ImagePullJobList{}
+	if err := r.List(context.TODO(), jobList); err != nil {
+		return 0, fmt.Errorf("failed to list ImagePullJobs: %v", err)
+	}
+	var active int
+	for i := range jobList.Items {
+		other := &jobList.Items[i]
+		if other.UID == job.UID || other.Status.CompletionTime != nil {
+			continue
+		}
+		active += int(other.Status.Active)
+	}
+	return active, nil
 }
 
 func (r *ReconcileImagePullJob) calculateStatus(job *appsv1alpha1.ImagePullJob, nodeImages []*appsv1alpha1.NodeImage) (*appsv1alpha1.ImagePullJobStatus, []string, error) {
 	newStatus := appsv1alpha1.ImagePullJobStatus{
-		StartTime: job.Status.StartTime,
-		Desired:   int32(len(nodeImages)),
+		StartTime:      job.Status.StartTime,
+		Desired:        int32(len(nodeImages)),
+		VerifiedDigest: job.Status.VerifiedDigest,
 	}
 	now := metav1.NewTime(r.clock.Now())
 	if newStatus.StartTime == nil {
 		newStatus.StartTime = &now
 	}
 
-	imageName, imageTag, err := daemonutil.NormalizeImageRefToNameTag(job.Spec.Image)
+	imageName, imageTag, err := getImageNameAndPullTag(job)
 	if err != nil {
 		return nil, nil, fmt.Errorf("invalid image %s: %v", job.Spec.Image, err)
 	}
 
-	var notSynced, pulling, succeeded, failed []string
+	// notSynced contains the names of NodeImages to sync, and the others contain the names of nodes
+	var notSynced, notSyncedNodes, pulling, succeeded, failed []string
 	for _, nodeImage := range nodeImages {
+		nodeName, _ := utilimagejob.GetNodeNameOfNodeImage(nodeImage)
 		var tagVersion int64 = -1
 		if imageSpec, ok := nodeImage.Spec.Images[imageName]; ok {
 			for _, tagSpec := range imageSpec.Tags {
@@ -372,12 +472,13 @@ func (r *ReconcileImagePullJob) calculateStatus(job *appsv1alpha1.ImagePullJob,
 		}
 		if tagVersion < 0 {
 			notSynced = append(notSynced, nodeImage.Name)
+			notSyncedNodes = append(notSyncedNodes, nodeName)
 			continue
 		}
 
 		imageStatus, ok := nodeImage.Status.ImageStatuses[imageName]
 		if !ok {
-			pulling = append(pulling, nodeImage.Name)
+			pulling = append(pulling, nodeName)
 		}
 
 		for _, tagStatus := range imageStatus.Tags {
@@ -385,16 +486,16 @@ func (r *ReconcileImagePullJob) calculateStatus(job *appsv1alpha1.ImagePullJob,
 				continue
 			}
 			if tagStatus.Version != tagVersion {
-				pulling = append(pulling, nodeImage.Name)
+				pulling = append(pulling, nodeName)
 				break
 			}
 			switch tagStatus.Phase {
 			case appsv1alpha1.ImagePhaseSucceeded:
-				succeeded = append(succeeded, nodeImage.Name)
+				succeeded = append(succeeded, nodeName)
 			case appsv1alpha1.ImagePhaseFailed:
-				failed = append(failed, nodeImage.Name)
+				failed = append(failed, nodeName)
 			default:
-				pulling = append(pulling, nodeImage.Name)
+				pulling = append(pulling, nodeName)
 			}
 			break
 		}
@@ -405,7 +506,7 @@ func (r *ReconcileImagePullJob) calculateStatus(job *appsv1alpha1.ImagePullJob,
 			newStatus.CompletionTime = &now
 			newStatus.Succeeded = int32(len(succeeded))
 			failed = append(failed, pulling...)
-			failed = append(failed, notSynced...)
+			failed = append(failed, notSyncedNodes...)
 			newStatus.Failed = int32(len(failed))
 			newStatus.FailedNodes = failed
 			newStatus.Message = "job exceeds activeDeadlineSeconds"
@@ -21,6 +21,7 @@ import (
 	"math/rand"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	daemonutil "github.com/openkruise/kruise/pkg/daemon/util"
 	v1 "k8s.io/api/core/v1"
 )
 
@@ -51,6 +52,19 @@ func getTTLSecondsForAlways(job *appsv1alpha1.ImagePullJob) *int32 {
 	return &ret
 }
 
+// getImageNameAndPullTag returns the image name and the tag to pull on nodes. The verified image is pinned to its digest,
+// so that nodes never pull an unverified image even if the tag has been moved after verification.
+func getImageNameAndPullTag(job *appsv1alpha1.ImagePullJob) (string, string, error) {
+	imageName, imageTag, err := daemonutil.NormalizeImageRefToNameTag(job.Spec.Image)
+	if err != nil {
+		return "", "", err
+	}
+	if job.Spec.Verification != nil && job.Status.VerifiedDigest != "" {
+		imageTag = job.Status.VerifiedDigest
+	}
+	return imageName, imageTag, nil
+}
+
 func getOwnerRef(job *appsv1alpha1.ImagePullJob) *v1.ObjectReference {
 	return &v1.ObjectReference{
 		APIVersion: controllerKind.GroupVersion().String(),
@@ -78,6 +92,7 @@ func getImagePullPolicy(job *appsv1alpha1.ImagePullJob) *appsv1alpha1.ImageTagPu
 	if job.Spec.PullPolicy != nil {
 		pullPolicy.BackoffLimit = job.Spec.PullPolicy.BackoffLimit
 		pullPolicy.TimeoutSeconds = job.Spec.PullPolicy.TimeoutSeconds
+		pullPolicy.Distributor = job.Spec.PullPolicy.Distributor
 	}
 	if job.Spec.CompletionPolicy.Type == appsv1alpha1.Never {
 		pullPolicy.TTLSecondsAfterFinished = getTTLSecondsForNever()
@@ -0,0 +1,64 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagepulljob
+
+import (
+	"testing"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+)
+
+func TestGetImageNameAndPullTag(t *testing.T) {
+	const dgst = "sha256:bc8813ea7b3603864987522f02a76101c17ad122e1c46d790efc0fca78ca7bfb"
+	cases := []struct {
+		name           string
+		verification   *appsv1alpha1.ImageVerification
+		verifiedDigest string
+		expectedTag    string
+	}{
+		{
+			name:        "no verification",
+			expectedTag: "1.21",
+		},
+		{
+			name:         "not verified yet",
+			verification: &appsv1alpha1.ImageVerification{},
+			expectedTag:  "1.21",
+		},
+		{
+			name:           "verified",
+			verification:   &appsv1alpha1.ImageVerification{},
+			verifiedDigest: dgst,
+			expectedTag:    dgst,
+		},
+	}
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			job := &appsv1alpha1.ImagePullJob{}
+			job.Spec.Image = "nginx:1.21"
+			job.Spec.Verification = cs.verification
+			job.Status.VerifiedDigest = cs.verifiedDigest
+			name, tag, err := getImageNameAndPullTag(job)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if name != "nginx" || tag != cs.expectedTag {
+				t.Fatalf("expected nginx %s, got %s %s", cs.expectedTag, name, tag)
+			}
+		})
+	}
+}
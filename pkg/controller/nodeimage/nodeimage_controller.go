@@ -56,6 +56,7 @@ import (
 func init() {
 	flag.IntVar(&concurrentReconciles, "nodeimage-workers", concurrentReconciles, "Max concurrent workers for NodeImage controller.")
 	flag.DurationVar(&nodeImageCreationDelayAfterNodeReady, "nodeimage-creation-delay", nodeImageCreationDelayAfterNodeReady, "Delay duration for NodeImage creation after Node ready.")
+	flag.IntVar(&utilimagejob.NodeImageShards, "nodeimage-shards", utilimagejob.NodeImageShards, "The number of NodeImage objects for each node to hold its images, which helps nodes with too many images.")
 }
 
 var (
@@ -63,6 +64,14 @@ var (
 	controllerKind       = appsv1alpha1.SchemeGroupVersion.WithKind("NodeImage")
 
 	nodeImageCreationDelayAfterNodeReady = time.Second * 30
+
+	// runtimeAnnotationKeys are the annotations of Node used by kruise-daemon to find the runtime,
+	// including the CRI socket annotated by kubeadm.
+	runtimeAnnotationKeys = []string{
+		appsv1alpha1.NodeCRISocketAnnotationKey,
+		appsv1alpha1.NodeContainerdNamespaceAnnotationKey,
+		"kubeadm.alpha.kubernetes.io/cri-socket",
+	}
 )
 
 const (
@@ -159,16 +168,6 @@ func (r *ReconcileNodeImage) Reconcile(_ context.Context, request reconcile.Requ
 		}
 	}()
 
-	// Fetch the Node
-	node := &v1.Node{}
-	err = r.Get(context.TODO(), request.NamespacedName, node)
-	if err != nil {
-		if !errors.IsNotFound(err) {
-			return reconcile.Result{}, fmt.Errorf("failed to get node %s: %v", request.NamespacedName.Name, err)
-		}
-		node = nil
-	}
-
 	// Fetch the NodeImage
 	nodeImage := &appsv1alpha1.NodeImage{}
 	err = r.Get(context.TODO(), request.NamespacedName, nodeImage)
@@ -179,8 +178,29 @@ func (r *ReconcileNodeImage) Reconcile(_ context.Context, request reconcile.Requ
 		nodeImage = nil
 	}
 
+	nodeName, isShard := request.Name, false
+	if nodeImage != nil {
+		nodeName, isShard = utilimagejob.GetNodeNameOfNodeImage(nodeImage)
+	}
+
+	// Fetch the Node
+	node := &v1.Node{}
+	err = r.Get(context.TODO(), types.NamespacedName{Name: nodeName}, node)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return reconcile.Result{}, fmt.Errorf("failed to get node %s: %v", nodeName, err)
+		}
+		node = nil
+	}
+
 	// If Node not exists or has been deleted
 	if node == nil || node.DeletionTimestamp != nil {
+		if !isShard {
+			if err = r.deleteNodeImageShards(nodeName); err != nil {
+				return reconcile.Result{}, err
+			}
+		}
+
 		// All been deleted
 		if nodeImage == nil || nodeImage.DeletionTimestamp != nil {
 			return reconcile.Result{}, nil
@@ -211,6 +231,9 @@ func (r *ReconcileNodeImage) Reconcile(_ context.Context, request reconcile.Requ
 			},
 			Spec: appsv1alpha1.NodeImageSpec{},
 		}
+		if !isShard {
+			syncRuntimeAnnotations(nodeImage, node)
+		}
 		if err = r.Create(context.TODO(), nodeImage); err != nil {
 			return reconcile.Result{}, fmt.Errorf("failed to create nodeimage %v, err: %v", nodeImage.Name, err)
 		}
@@ -222,6 +245,28 @@ func (r *ReconcileNodeImage) Reconcile(_ context.Context, request reconcile.Requ
 		return reconcile.Result{}, nil
 	}
 
+	if isShard {
+		// Delete the shard no longer expected, the images in it will be synced into the expected shards by jobs.
+		// The shard is also deleted if a node named as it joins, so that the NodeImage will be recreated for that node.
+		nameTaken, err := r.isNodeNameTaken(nodeImage.Name)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if nameTaken || !utilimagejob.IsNodeImageShardExpected(nodeName, nodeImage.Name) {
+			if err = r.Delete(context.TODO(), nodeImage); err != nil {
+				return reconcile.Result{}, fmt.Errorf("failed to delete nodeimage shard %v, err: %v", nodeImage.Name, err)
+			}
+			klog.Infof("Successfully delete nodeimage shard %s for node %s", nodeImage.Name, nodeName)
+			return reconcile.Result{}, nil
+		}
+		// The labels of Node are only synced into its first NodeImage
+		node = nil
+	} else if node != nil {
+		if err = r.createNodeImageShards(nodeName); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
 	duration := &requeueduration.Duration{}
 	if modified, err := r.updateNodeImage(nodeImage.Name, node, duration); err != nil {
 		return reconcile.Result{}, err
@@ -241,6 +286,74 @@ func (r *ReconcileNodeImage) Reconcile(_ context.Context, request reconcile.Requ
 	return res, nil
 }
 
+// createNodeImageShards creates the extra shards of NodeImage for the node if they do not exist.
+func (r *ReconcileNodeImage) createNodeImageShards(nodeName string) error {
+	for i := 1; i < utilimagejob.NodeImageShards; i++ {
+		name := utilimagejob.GetNodeImageShardName(nodeName, i)
+		existing := &appsv1alpha1.NodeImage{}
+		err := r.Get(context.TODO(), types.NamespacedName{Name: name}, existing)
+		if err == nil {
+			if existing.Labels[appsv1alpha1.NodeImageShardOfLabelKey] != nodeName {
+				klog.Warningf("NodeImage shard %s for node %s collides with the NodeImage of another node", name, nodeName)
+			}
+			continue
+		} else if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get nodeimage shard %s: %v", name, err)
+		}
+		if nameTaken, err := r.isNodeNameTaken(name); err != nil {
+			return err
+		} else if nameTaken {
+			klog.Warningf("NodeImage shard %s for node %s collides with the name of another node", name, nodeName)
+			continue
+		}
+
+		shard := &appsv1alpha1.NodeImage{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{appsv1alpha1.NodeImageShardOfLabelKey: nodeName},
+			},
+		}
+		if err = r.Create(context.TODO(), shard); err != nil {
+			if errors.IsAlreadyExists(err) {
+				continue
+			}
+			return fmt.Errorf("failed to create nodeimage shard %v, err: %v", name, err)
+		}
+		klog.Infof("Successfully create nodeimage shard %s for node %s", name, nodeName)
+	}
+	return nil
+}
+
+// isNodeNameTaken returns whether there is a node with the name, which can not be used by NodeImage shards.
+func (r *ReconcileNodeImage) isNodeNameTaken(name string) (bool, error) {
+	err := r.Get(context.TODO(), types.NamespacedName{Name: name}, &v1.Node{})
+	if err == nil {
+		return true, nil
+	} else if errors.IsNotFound(err) {
+		return false, nil
+	}
+	return false, fmt.Errorf("failed to get node %s: %v", name, err)
+}
+
+// deleteNodeImageShards deletes all the extra shards of NodeImage for the node.
+func (r *ReconcileNodeImage) deleteNodeImageShards(nodeName string) error {
+	shardList := &appsv1alpha1.NodeImageList{}
+	if err := r.List(context.TODO(), shardList, client.MatchingLabels{appsv1alpha1.NodeImageShardOfLabelKey: nodeName}); err != nil {
+		return fmt.Errorf("failed to list nodeimage shards for node %s: %v", nodeName, err)
+	}
+	for i := range shardList.Items {
+		shard := &shardList.Items[i]
+		if shard.DeletionTimestamp != nil {
+			continue
+		}
+		if err := r.Delete(context.TODO(), shard); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete nodeimage shard %v, err: %v", shard.Name, err)
+		}
+		klog.Infof("Successfully delete nodeimage shard %s for node %s", shard.Name, nodeName)
+	}
+	return nil
+}
+
 func (r *ReconcileNodeImage) updateNodeImage(name string, node *v1.Node, duration *requeueduration.Duration) (bool, error) {
 	var modified bool
 	var messages []string
@@ -267,6 +380,27 @@ func (r *ReconcileNodeImage) updateNodeImage(name string, node *v1.Node, duratio
 	return modified, err
 }
 
+// syncRuntimeAnnotations copies the annotations of the node to find the runtime into its NodeImage,
+// which are read by kruise-daemon without the permission to get nodes.
+func syncRuntimeAnnotations(nodeImage *appsv1alpha1.NodeImage, node *v1.Node) (modified bool) {
+	for _, key := range runtimeAnnotationKeys {
+		value, ok := node.Annotations[key]
+		if oldValue, oldOK := nodeImage.Annotations[key]; ok == oldOK && value == oldValue {
+			continue
+		}
+		modified = true
+		if !ok {
+			delete(nodeImage.Annotations, key)
+			continue
+		}
+		if nodeImage.Annotations == nil {
+			nodeImage.Annotations = map[string]string{}
+		}
+		nodeImage.Annotations[key] = value
+	}
+	return modified
+}
+
 func (r *ReconcileNodeImage) doUpdateNodeImage(nodeImage *appsv1alpha1.NodeImage, node *v1.Node) (modified bool, messages []string, wait *requeueduration.Duration) {
 	wait = &requeueduration.Duration{}
 	if node != nil {
@@ -275,6 +409,10 @@ func (r *ReconcileNodeImage) doUpdateNodeImage(nodeImage *appsv1alpha1.NodeImage
 			messages = append(messages, "node labels changed")
 			nodeImage.Labels = node.Labels
 		}
+		if syncRuntimeAnnotations(nodeImage, node) {
+			modified = true
+			messages = append(messages, "node runtime annotations changed")
+		}
 	}
 
 	newImageMap := make(map[string]appsv1alpha1.ImageSpec, len(nodeImage.Spec.Images))
@@ -367,6 +505,7 @@ func (r *ReconcileNodeImage) doUpdateNodeImage(nodeImage *appsv1alpha1.NodeImage
 
 func (r *ReconcileNodeImage) updateNodeImageStatus(nodeImage *appsv1alpha1.NodeImage, duration *requeueduration.Duration) error {
 	now := metav1.NewTime(r.clock.Now())
+	nodeName, _ := utilimagejob.GetNodeNameOfNodeImage(nodeImage)
 
 	specFullImages := sets.NewString()
 	newStatus := nodeImage.Status.DeepCopy()
@@ -433,10 +572,10 @@ func (r *ReconcileNodeImage) updateNodeImageStatus(nodeImage *appsv1alpha1.NodeI
 			if failed {
 				if r.eventRecorder != nil {
 					for _, owner := range tagSpec.OwnerReferences {
-						r.eventRecorder.Eventf(&owner, v1.EventTypeWarning, "PullImageFailed", "Failed to pull image %v on node %v for %v", fullName, nodeImage.Name, tagStatus.Message)
+						r.eventRecorder.Eventf(&owner, v1.EventTypeWarning, "PullImageFailed", "Failed to pull image %v on node %v for %v", fullName, nodeName, tagStatus.Message)
 					}
 					if ref, _ := reference.GetReference(r.scheme, nodeImage); ref != nil {
-						r.eventRecorder.Eventf(ref, v1.EventTypeWarning, "PullImageFailed", "Failed to pull image %v on node %v for %v", fullName, nodeImage.Name, tagStatus.Message)
+						r.eventRecorder.Eventf(ref, v1.EventTypeWarning, "PullImageFailed", "Failed to pull image %v on node %v for %v", fullName, nodeName, tagStatus.Message)
 					}
 				}
 			}
@@ -0,0 +1,396 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podunavailablebudget
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	kruiseappsv1beta1 "github.com/openkruise/kruise/apis/apps/v1beta1"
+	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
+	"github.com/openkruise/kruise/pkg/control/pubcontrol"
+	"github.com/openkruise/kruise/pkg/util"
+	"github.com/openkruise/kruise/pkg/util/controllerfinder"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	appsvalidation "k8s.io/kubernetes/pkg/apis/apps/validation"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// defaultPubWorkload is the workload type protected by the namespace default budget policy
+type defaultPubWorkload struct {
+	gvk schema.GroupVersionKind
+	// the prefix of default pub name
+	prefix  string
+	newList func() client.ObjectList
+}
+
+var defaultPubWorkloads = []defaultPubWorkload{
+	{gvk: controllerfinder.ControllerKindDep, prefix: "deployment", newList: func() client.ObjectList { return &apps.DeploymentList{} }},
+	{gvk: controllerfinder.ControllerKindSS, prefix: "statefulset", newList: func() client.ObjectList { return &apps.StatefulSetList{} }},
+	{gvk: controllerfinder.ControllerKruiseKindCS, prefix: "cloneset", newList: func() client.ObjectList { return &kruiseappsv1alpha1.CloneSetList{} }},
+	{gvk: controllerfinder.ControllerKruiseKindSS, prefix: "advancedstatefulset", newList: func() client.ObjectList { return &kruiseappsv1beta1.StatefulSetList{} }},
+}
+
+// addDefaultPub adds the controller which creates default pubs for the workloads without explicit pub
+// in the namespaces annotated with default budget.
+func addDefaultPub(mgr manager.Manager) error {
+	r := &ReconcileDefaultPub{
+		Client:   mgr.GetClient(),
+		recorder: mgr.GetEventRecorderFor("default-podunavailablebudget-controller"),
+	}
+	c, err := controller.New("default-podunavailablebudget-controller", mgr, controller.Options{
+		Reconciler: r, MaxConcurrentReconciles: concurrentReconciles})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes of namespace default budget
+	err = c.Watch(&source.Kind{Type: &corev1.Namespace{}}, &handler.EnqueueRequestForObject{}, predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			_, minOK := e.Object.GetAnnotations()[pubcontrol.NamespaceDefaultMinAvailableAnnotation]
+			_, maxOK := e.Object.GetAnnotations()[pubcontrol.NamespaceDefaultMaxUnavailableAnnotation]
+			return minOK || maxOK
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldAnnotations, newAnnotations := e.ObjectOld.GetAnnotations(), e.ObjectNew.GetAnnotations()
+			return oldAnnotations[pubcontrol.NamespaceDefaultMinAvailableAnnotation] != newAnnotations[pubcontrol.NamespaceDefaultMinAvailableAnnotation] ||
+				oldAnnotations[pubcontrol.NamespaceDefaultMaxUnavailableAnnotation] != newAnnotations[pubcontrol.NamespaceDefaultMaxUnavailableAnnotation]
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return false
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Watch for pubs and workloads, and reconcile their namespaces
+	enqueueNamespace := handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: obj.GetNamespace()}}}
+	})
+	if err = c.Watch(&source.Kind{Type: &policyv1alpha1.PodUnavailableBudget{}}, enqueueNamespace, predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration()
+		},
+	}); err != nil {
+		return err
+	}
+	for _, workload := range defaultPubWorkloads {
+		obj, err := mgr.GetScheme().New(workload.gvk)
+		if err != nil {
+			return err
+		}
+		// the explicit pubs select workloads by template labels
+		if err = c.Watch(&source.Kind{Type: obj.(client.Object)}, enqueueNamespace, predicate.Funcs{
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				if e.ObjectOld.GetDeletionTimestamp().IsZero() != e.ObjectNew.GetDeletionTimestamp().IsZero() {
+					return true
+				}
+				return !reflect.DeepEqual(getWorkloadTemplateLabels(e.ObjectOld), getWorkloadTemplateLabels(e.ObjectNew))
+			},
+		}); err != nil {
+			return err
+		}
+	}
+
+	klog.Infof("add default podunavailablebudget reconcile.Reconciler success")
+	return nil
+}
+
+var _ reconcile.Reconciler = &ReconcileDefaultPub{}
+
+// ReconcileDefaultPub reconciles the default pubs in a namespace
+type ReconcileDefaultPub struct {
+	client.Client
+	recorder record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+
+func (r *ReconcileDefaultPub) Reconcile(_ context.Context, req ctrl.Request) (ctrl.Result, error) {
+	namespace := &corev1.Namespace{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: req.Name}, namespace); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	pubList := &policyv1alpha1.PodUnavailableBudgetList{}
+	if err := r.List(context.TODO(), pubList, &client.ListOptions{Namespace: namespace.Name}); err != nil {
+		return ctrl.Result{}, err
+	}
+	existingPubs := make(map[string]*policyv1alpha1.PodUnavailableBudget, len(pubList.Items))
+	for i := range pubList.Items {
+		existingPubs[pubList.Items[i].Name] = &pubList.Items[i]
+	}
+
+	budget, err := getNamespaceDefaultBudget(namespace)
+	if err != nil {
+		// keep the existing default pubs until the default budget is corrected
+		klog.Warningf("namespace(%s) default budget is invalid: %s", namespace.Name, err.Error())
+		r.recorder.Eventf(namespace, corev1.EventTypeWarning, "InvalidDefaultBudget", "Invalid default budget: %s", err.Error())
+		return ctrl.Result{}, nil
+	}
+	var desiredPubs map[string]*policyv1alpha1.PodUnavailableBudget
+	if budget != nil {
+		workloads, err := r.listWorkloads(namespace.Name)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		desiredPubs = calculateDefaultPubs(budget, workloads, pubList.Items)
+	}
+
+	for name, desired := range desiredPubs {
+		pub, ok := existingPubs[name]
+		if !ok {
+			if err := r.Create(context.TODO(), desired); err != nil && !errors.IsAlreadyExists(err) {
+				return ctrl.Result{}, err
+			}
+			klog.V(3).Infof("create default pub(%s/%s) for %s(%s)", desired.Namespace, desired.Name,
+				desired.Spec.TargetReference.Kind, desired.Spec.TargetReference.Name)
+			continue
+		}
+		if apiequality.Semantic.DeepEqual(pub.Spec, desired.Spec) {
+			continue
+		}
+		pubClone := pub.DeepCopy()
+		pubClone.Spec = desired.Spec
+		if err := r.Update(context.TODO(), pubClone); err != nil {
+			return ctrl.Result{}, err
+		}
+		klog.V(3).Infof("update default pub(%s/%s) spec", pub.Namespace, pub.Name)
+	}
+
+	// delete the default pubs which are no longer desired, e.g. the workload has an explicit pub
+	for name, pub := range existingPubs {
+		if _, ok := desiredPubs[name]; ok || pub.Labels[pubcontrol.PubDefaultPolicyLabel] != "true" {
+			continue
+		}
+		if err := r.Delete(context.TODO(), pub); err != nil && !errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		klog.V(3).Infof("delete default pub(%s/%s)", pub.Namespace, pub.Name)
+	}
+	return ctrl.Result{}, nil
+}
+
+func (r *ReconcileDefaultPub) listWorkloads(namespace string) ([]*controllerfinder.ScaleAndSelector, error) {
+	var workloads []*controllerfinder.ScaleAndSelector
+	for _, workload := range defaultPubWorkloads {
+		list := workload.newList()
+		if err := r.List(context.TODO(), list, &client.ListOptions{Namespace: namespace}); err != nil {
+			return nil, err
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			obj := item.(client.Object)
+			if !obj.GetDeletionTimestamp().IsZero() {
+				continue
+			}
+			workloads = append(workloads, &controllerfinder.ScaleAndSelector{
+				ControllerReference: controllerfinder.ControllerReference{
+					APIVersion: workload.gvk.GroupVersion().String(),
+					Kind:       workload.gvk.Kind,
+					Name:       obj.GetName(),
+					UID:        obj.GetUID(),
+				},
+				Metadata:   metav1.ObjectMeta{Namespace: obj.GetNamespace(), Name: obj.GetName(), UID: obj.GetUID()},
+				TempLabels: getWorkloadTemplateLabels(obj),
+			})
+		}
+	}
+	return workloads, nil
+}
+
+func getWorkloadTemplateLabels(obj client.Object) map[string]string {
+	switch workload := obj.(type) {
+	case *apps.Deployment:
+		return workload.Spec.Template.Labels
+	case *apps.StatefulSet:
+		return workload.Spec.Template.Labels
+	case *kruiseappsv1alpha1.CloneSet:
+		return workload.Spec.Template.Labels
+	case *kruiseappsv1beta1.StatefulSet:
+		return workload.Spec.Template.Labels
+	}
+	return nil
+}
+
+// defaultBudget is the budget defined by namespace annotations
+type defaultBudget struct {
+	minAvailable   *intstr.IntOrString
+	maxUnavailable *intstr.IntOrString
+}
+
+// getNamespaceDefaultBudget returns nil if the namespace has no default budget,
+// and returns error if the default budget is not a non-negative integer or a percentage not greater than 100%.
+func getNamespaceDefaultBudget(namespace *corev1.Namespace) (*defaultBudget, error) {
+	if value, ok := namespace.Annotations[pubcontrol.NamespaceDefaultMaxUnavailableAnnotation]; ok && value != "" {
+		maxUnavailable, err := parseDefaultBudget(value, pubcontrol.NamespaceDefaultMaxUnavailableAnnotation)
+		if err != nil {
+			return nil, err
+		}
+		return &defaultBudget{maxUnavailable: maxUnavailable}, nil
+	}
+	if value, ok := namespace.Annotations[pubcontrol.NamespaceDefaultMinAvailableAnnotation]; ok && value != "" {
+		minAvailable, err := parseDefaultBudget(value, pubcontrol.NamespaceDefaultMinAvailableAnnotation)
+		if err != nil {
+			return nil, err
+		}
+		return &defaultBudget{minAvailable: minAvailable}, nil
+	}
+	return nil, nil
+}
+
+func parseDefaultBudget(value, annotation string) (*intstr.IntOrString, error) {
+	budget := intstr.Parse(value)
+	fldPath := field.NewPath("metadata", "annotations").Key(annotation)
+	allErrs := appsvalidation.ValidatePositiveIntOrPercent(budget, fldPath)
+	allErrs = append(allErrs, appsvalidation.IsNotMoreThan100Percent(budget, fldPath)...)
+	if len(allErrs) > 0 {
+		return nil, allErrs.ToAggregate()
+	}
+	return &budget, nil
+}
+
+// calculateDefaultPubs returns the desired default pubs for the workloads without explicit pub, key is pub name.
+func calculateDefaultPubs(budget *defaultBudget, workloads []*controllerfinder.ScaleAndSelector,
+	pubs []policyv1alpha1.PodUnavailableBudget) map[string]*policyv1alpha1.PodUnavailableBudget {
+
+	explicitPubs := make([]*policyv1alpha1.PodUnavailableBudget, 0, len(pubs))
+	pubNames := make(map[string]bool, len(pubs))
+	for i := range pubs {
+		pubNames[pubs[i].Name] = true
+		if pubs[i].Labels[pubcontrol.PubDefaultPolicyLabel] != "true" {
+			explicitPubs = append(explicitPubs, &pubs[i])
+		}
+	}
+
+	desiredPubs := make(map[string]*policyv1alpha1.PodUnavailableBudget, len(workloads))
+	for _, workload := range workloads {
+		if isWorkloadProtectedByPubs(workload, explicitPubs) {
+			continue
+		}
+		name := getDefaultPubName(workload)
+		// the name is invalid or conflicts with an explicit pub
+		if len(validation.IsDNS1123Subdomain(name)) > 0 {
+			klog.Warningf("default pub name(%s) for %s(%s/%s) is invalid", name, workload.Kind, workload.Metadata.Namespace, workload.Name)
+			continue
+		}
+		if pubNames[name] && !isDefaultPubName(name, pubs) {
+			continue
+		}
+		desiredPubs[name] = &policyv1alpha1.PodUnavailableBudget{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: workload.Metadata.Namespace,
+				Name:      name,
+				Labels:    map[string]string{pubcontrol.PubDefaultPolicyLabel: "true"},
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: workload.APIVersion,
+					Kind:       workload.Kind,
+					Name:       workload.Name,
+					UID:        workload.UID,
+				}},
+			},
+			Spec: policyv1alpha1.PodUnavailableBudgetSpec{
+				TargetReference: &policyv1alpha1.TargetReference{
+					APIVersion: workload.APIVersion,
+					Kind:       workload.Kind,
+					Name:       workload.Name,
+				},
+				MinAvailable:   budget.minAvailable,
+				MaxUnavailable: budget.maxUnavailable,
+			},
+		}
+	}
+	return desiredPubs
+}
+
+func isWorkloadProtectedByPubs(workload *controllerfinder.ScaleAndSelector, pubs []*policyv1alpha1.PodUnavailableBudget) bool {
+	targetRef := &policyv1alpha1.TargetReference{APIVersion: workload.APIVersion, Kind: workload.Kind, Name: workload.Name}
+	for _, pub := range pubs {
+		if pub.Spec.TargetReference != nil {
+			if isReferenceEqual(targetRef, pub.Spec.TargetReference) {
+				return true
+			}
+			continue
+		}
+		// This error is irreversible, so continue
+		labelSelector, err := util.GetFastLabelSelector(pub.Spec.Selector)
+		if err != nil {
+			continue
+		}
+		if !labelSelector.Empty() && labelSelector.Matches(labels.Set(workload.TempLabels)) {
+			return true
+		}
+	}
+	return false
+}
+
+func getDefaultPubName(workload *controllerfinder.ScaleAndSelector) string {
+	for _, w := range defaultPubWorkloads {
+		if ok, _ := isGroupKindEqual(workload.APIVersion, workload.Kind, w.gvk); ok {
+			return fmt.Sprintf("default-%s-%s", w.prefix, workload.Name)
+		}
+	}
+	return fmt.Sprintf("default-%s-%s", strings.ToLower(workload.Kind), workload.Name)
+}
+
+func isDefaultPubName(name string, pubs []policyv1alpha1.PodUnavailableBudget) bool {
+	for i := range pubs {
+		if pubs[i].Name == name {
+			return pubs[i].Labels[pubcontrol.PubDefaultPolicyLabel] == "true"
+		}
+	}
+	return false
+}
+
+func isGroupKindEqual(apiVersion, kind string, gvk schema.GroupVersionKind) (bool, error) {
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return false, err
+	}
+	return gv.Group == gvk.Group && kind == gvk.Kind, nil
+}
@@ -0,0 +1,234 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podunavailablebudget
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	kruiseappsv1beta1 "github.com/openkruise/kruise/apis/apps/v1beta1"
+	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
+	"github.com/openkruise/kruise/pkg/control/pubcontrol"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDefaultPubReconcile(t *testing.T) {
+	cases := []struct {
+		name               string
+		getNamespace       func() *corev1.Namespace
+		getObjects         func() []client.Object
+		expectPubs         []string
+		expectMinAvailable *intstr.IntOrString
+		expectEvent        bool
+	}{
+		{
+			name: "namespace without default budget, delete default pubs",
+			getNamespace: func() *corev1.Namespace {
+				return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+			},
+			getObjects: func() []client.Object {
+				defaultPub := newDefaultPubForTest("default-deployment-nginx")
+				explicitPub := pubDemo.DeepCopy()
+				return []client.Object{deploymentDemo.DeepCopy(), defaultPub, explicitPub}
+			},
+			expectPubs: []string{"pub-test"},
+		},
+		{
+			name: "namespace with default budget, create default pubs for workloads without explicit pub",
+			getNamespace: func() *corev1.Namespace {
+				return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+					Name:        "default",
+					Annotations: map[string]string{pubcontrol.NamespaceDefaultMinAvailableAnnotation: "50%"},
+				}}
+			},
+			getObjects: func() []client.Object {
+				deployment := deploymentDemo.DeepCopy()
+				deployment.Spec.Template.Labels = map[string]string{"app": "nginx"}
+				cloneSet := &kruiseappsv1alpha1.CloneSet{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "protected", UID: types.UID("cloneset-uid")},
+				}
+				cloneSet.Spec.Template.Labels = map[string]string{"pub-controller": "true"}
+				statefulSet := &kruiseappsv1beta1.StatefulSet{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web", UID: types.UID("statefulset-uid")},
+				}
+				// the default pub of deleted workload
+				stalePub := newDefaultPubForTest("default-deployment-deleted")
+				return []client.Object{deployment, cloneSet, statefulSet, pubDemo.DeepCopy(), stalePub}
+			},
+			expectPubs:         []string{"default-advancedstatefulset-web", "default-deployment-nginx", "pub-test"},
+			expectMinAvailable: &intstr.IntOrString{Type: intstr.String, StrVal: "50%"},
+		},
+		{
+			name: "update default pub when default budget changed",
+			getNamespace: func() *corev1.Namespace {
+				return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+					Name:        "default",
+					Annotations: map[string]string{pubcontrol.NamespaceDefaultMinAvailableAnnotation: "2"},
+				}}
+			},
+			getObjects: func() []client.Object {
+				return []client.Object{deploymentDemo.DeepCopy(), newDefaultPubForTest("default-deployment-nginx")}
+			},
+			expectPubs:         []string{"default-deployment-nginx"},
+			expectMinAvailable: &intstr.IntOrString{Type: intstr.Int, IntVal: 2},
+		},
+		{
+			name: "keep default pub when default budget is invalid",
+			getNamespace: func() *corev1.Namespace {
+				return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+					Name:        "default",
+					Annotations: map[string]string{pubcontrol.NamespaceDefaultMinAvailableAnnotation: "150%"},
+				}}
+			},
+			getObjects: func() []client.Object {
+				return []client.Object{deploymentDemo.DeepCopy(), newDefaultPubForTest("default-deployment-nginx")}
+			},
+			expectPubs:         []string{"default-deployment-nginx"},
+			expectMinAvailable: &intstr.IntOrString{Type: intstr.String, StrVal: "50%"},
+			expectEvent:        true,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			testScheme := runtime.NewScheme()
+			_ = policyv1alpha1.AddToScheme(testScheme)
+			_ = kruiseappsv1alpha1.AddToScheme(testScheme)
+			_ = kruiseappsv1beta1.AddToScheme(testScheme)
+			_ = corev1.AddToScheme(testScheme)
+			_ = apps.AddToScheme(testScheme)
+			fakeClient := fake.NewClientBuilder().WithScheme(testScheme).
+				WithObjects(cs.getNamespace()).WithObjects(cs.getObjects()...).Build()
+			recorder := record.NewFakeRecorder(10)
+			reconciler := ReconcileDefaultPub{Client: fakeClient, recorder: recorder}
+
+			if _, err := reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "default"}}); err != nil {
+				t.Fatalf("reconcile default pub failed: %s", err.Error())
+			}
+			pubList := &policyv1alpha1.PodUnavailableBudgetList{}
+			if err := fakeClient.List(context.TODO(), pubList); err != nil {
+				t.Fatalf("list pub failed: %s", err.Error())
+			}
+			var names []string
+			for _, pub := range pubList.Items {
+				names = append(names, pub.Name)
+				if pub.Labels[pubcontrol.PubDefaultPolicyLabel] != "true" {
+					continue
+				}
+				if pub.Spec.TargetReference == nil || pub.Spec.TargetReference.Name == "" {
+					t.Fatalf("expect default pub(%s) targetRef, but get nil", pub.Name)
+				}
+				if pub.Spec.MinAvailable == nil || *pub.Spec.MinAvailable != *cs.expectMinAvailable {
+					t.Fatalf("expect default pub(%s) minAvailable(%v), but get(%v)", pub.Name, cs.expectMinAvailable, pub.Spec.MinAvailable)
+				}
+			}
+			if len(recorder.Events) > 0 != cs.expectEvent {
+				t.Fatalf("expect event(%v), but get %d events", cs.expectEvent, len(recorder.Events))
+			}
+			if len(names) != len(cs.expectPubs) {
+				t.Fatalf("expect pubs(%v), but get(%v)", cs.expectPubs, names)
+			}
+			for i := range names {
+				if names[i] != cs.expectPubs[i] {
+					t.Fatalf("expect pubs(%v), but get(%v)", cs.expectPubs, names)
+				}
+			}
+		})
+	}
+}
+
+func TestGetNamespaceDefaultBudget(t *testing.T) {
+	cases := []struct {
+		name         string
+		annotations  map[string]string
+		expectBudget *defaultBudget
+		expectErr    bool
+	}{
+		{
+			name:         "no default budget",
+			annotations:  map[string]string{},
+			expectBudget: nil,
+		},
+		{
+			name:         "valid minAvailable percentage",
+			annotations:  map[string]string{pubcontrol.NamespaceDefaultMinAvailableAnnotation: "100%"},
+			expectBudget: &defaultBudget{minAvailable: &intstr.IntOrString{Type: intstr.String, StrVal: "100%"}},
+		},
+		{
+			name: "maxUnavailable takes precedence",
+			annotations: map[string]string{
+				pubcontrol.NamespaceDefaultMinAvailableAnnotation:   "2",
+				pubcontrol.NamespaceDefaultMaxUnavailableAnnotation: "1",
+			},
+			expectBudget: &defaultBudget{maxUnavailable: &intstr.IntOrString{Type: intstr.Int, IntVal: 1}},
+		},
+		{
+			name:        "percentage greater than 100%",
+			annotations: map[string]string{pubcontrol.NamespaceDefaultMaxUnavailableAnnotation: "101%"},
+			expectErr:   true,
+		},
+		{
+			name:        "negative integer",
+			annotations: map[string]string{pubcontrol.NamespaceDefaultMinAvailableAnnotation: "-1"},
+			expectErr:   true,
+		},
+		{
+			name:        "malformed value",
+			annotations: map[string]string{pubcontrol.NamespaceDefaultMinAvailableAnnotation: "half"},
+			expectErr:   true,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default", Annotations: cs.annotations}}
+			budget, err := getNamespaceDefaultBudget(namespace)
+			if (err != nil) != cs.expectErr {
+				t.Fatalf("expect error(%v), but get(%v)", cs.expectErr, err)
+			}
+			if !reflect.DeepEqual(budget, cs.expectBudget) {
+				t.Fatalf("expect budget(%+v), but get(%+v)", cs.expectBudget, budget)
+			}
+		})
+	}
+}
+
+func newDefaultPubForTest(name string) *policyv1alpha1.PodUnavailableBudget {
+	minAvailable := intstr.FromString("50%")
+	return &policyv1alpha1.PodUnavailableBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      name,
+			Labels:    map[string]string{pubcontrol.PubDefaultPolicyLabel: "true"},
+		},
+		Spec: policyv1alpha1.PodUnavailableBudgetSpec{
+			TargetReference: &policyv1alpha1.TargetReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "nginx"},
+			MinAvailable:    &minAvailable,
+		},
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -36,6 +37,7 @@ import (
 	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
 	"github.com/openkruise/kruise/pkg/util/ratelimiter"
 	apps "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -63,15 +65,24 @@ import (
 
 func init() {
 	flag.IntVar(&concurrentReconciles, "podunavailablebudget-workers", concurrentReconciles, "Max concurrent workers for PodUnavailableBudget controller.")
+	flag.DurationVar(&defaultStaleTimeout, "pub-stale-timeout", defaultStaleTimeout,
+		"Default time for PodUnavailableBudget to keep a pod in disruptedPods or unavailablePods before refunding the budget. "+
+			"Defaults to 0, which means 20s for deleted pods and 10s for updated pods.")
 }
 
 var (
 	concurrentReconciles = 3
-	controllerKind       = policyv1alpha1.SchemeGroupVersion.WithKind("PodUnavailableBudget")
+	// defaultStaleTimeout overrides DeletionTimeout and UpdatedDelayCheckTime if it is set,
+	// pub.spec.staleTimeoutSeconds is priority to take effect
+	defaultStaleTimeout time.Duration
+	controllerKind      = policyv1alpha1.SchemeGroupVersion.WithKind("PodUnavailableBudget")
 )
 
 const (
-	DeletionTimeout       = 20 * time.Second
+	DeletionTimeout = 20 * time.Second
+	// EvictionTimeout is shorter than DeletionTimeout, because the Eviction API deletes the pod immediately after admission,
+	// if the pod still exists the eviction must have been refused afterwards (e.g. by PodDisruptionBudget).
+	EvictionTimeout       = 10 * time.Second
 	UpdatedDelayCheckTime = 10 * time.Second
 
 	// patch related-pub annotation Reconcile prefix
@@ -100,7 +111,13 @@ func Add(mgr manager.Manager) error {
 		!utilfeature.DefaultFeatureGate.Enabled(features.PodUnavailableBudgetUpdateGate) {
 		return nil
 	}
-	return add(mgr, newReconciler(mgr))
+	if err := add(mgr, newReconciler(mgr)); err != nil {
+		return err
+	}
+	if utilfeature.DefaultFeatureGate.Enabled(features.PodUnavailableBudgetDefaultPolicy) {
+		return addDefaultPub(mgr)
+	}
+	return nil
 }
 
 // newReconciler returns a new reconcile.Reconciler
@@ -197,6 +214,19 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	// HorizontalPodAutoscaler, for the pubs resolving replicas against hpa desiredReplicas
+	if err = c.Watch(&source.Kind{Type: &autoscalingv1.HorizontalPodAutoscaler{}}, handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+		return getPubsResolvedByHPA(mgr.GetClient(), obj.GetNamespace())
+	}), predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			old := e.ObjectOld.(*autoscalingv1.HorizontalPodAutoscaler)
+			new := e.ObjectNew.(*autoscalingv1.HorizontalPodAutoscaler)
+			return old.Status.DesiredReplicas != new.Status.DesiredReplicas
+		},
+	}); err != nil {
+		return err
+	}
+
 	klog.Infof("add podunavailablebudget reconcile.Reconciler success")
 	return nil
 }
@@ -214,6 +244,8 @@ type ReconcilePodUnavailableBudget struct {
 
 // +kubebuilder:rbac:groups=policy.kruise.io,resources=podunavailablebudgets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=policy.kruise.io,resources=podunavailablebudgets/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=*,resources=*/scale,verbs=get
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch
 
 // pkg/controller/cloneset/cloneset_controller.go Watch for changes to CloneSet
 func (r *ReconcilePodUnavailableBudget) Reconcile(_ context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -243,6 +275,10 @@ func (r *ReconcilePodUnavailableBudget) Reconcile(_ context.Context, req ctrl.Re
 		}); cacheErr != nil {
 			klog.Errorf("Delete cache failed for PodUnavailableBudget(%s/%s): %s", req.Namespace, req.Name, err.Error())
 		}
+		// remove the related-pub annotation from pods, so that they can be protected by other pubs
+		if err = r.cleanRelatedPubAnnotationInPods(req.Namespace, req.Name); err != nil {
+			return reconcile.Result{}, err
+		}
 		// Object not found, return.  Created objects are automatically garbage collected.
 		// For additional cleanup logic use finalizers.
 		return reconcile.Result{}, nil
@@ -251,6 +287,16 @@ func (r *ReconcilePodUnavailableBudget) Reconcile(_ context.Context, req ctrl.Re
 		return reconcile.Result{}, err
 	}
 
+	// If the pods recorded in pub status by webhook have not been observed in informer cache yet, just skip this reconcile,
+	// otherwise unavailableAllowed is calculated with stale pods.
+	dirtyPods, waitDuration, err := r.getUnobservedPods(pub, time.Now())
+	if err != nil {
+		return ctrl.Result{}, err
+	} else if len(dirtyPods) > 0 {
+		klog.V(4).Infof("Not observed pods recorded in pub(%s/%s), dirtyPods=%v", pub.Namespace, pub.Name, dirtyPods)
+		return ctrl.Result{RequeueAfter: waitDuration}, nil
+	}
+
 	klog.V(3).Infof("begin to process podUnavailableBudget(%s/%s)", pub.Namespace, pub.Name)
 	recheckTime, err := r.syncPodUnavailableBudget(pub)
 	if err != nil {
@@ -272,6 +318,12 @@ func (r *ReconcilePodUnavailableBudget) syncPodUnavailableBudget(pub *policyv1al
 		r.recorder.Eventf(pub, corev1.EventTypeNormal, "NoPods", "No matching pods found")
 	}
 
+	if pub.Annotations[pubcontrol.PubReplicasFromHPAAnnotation] == "true" {
+		if expectedCount, err = r.getExpectedScaleFromHPA(pub, expectedCount); err != nil {
+			return nil, err
+		}
+	}
+
 	klog.V(3).Infof("pub(%s/%s) controller pods(%d) expectedCount(%d)", pub.Namespace, pub.Name, len(pods), expectedCount)
 	desiredAvailable, err := r.getDesiredAvailableForPub(pub, expectedCount)
 	if err != nil {
@@ -326,11 +378,12 @@ func (r *ReconcilePodUnavailableBudget) syncPodUnavailableBudget(pub *policyv1al
 		// disruptedPods contains information about pods whose eviction or deletion was processed by the API handler but has not yet been observed by the PodUnavailableBudget.
 		// unavailablePods contains information about pods whose specification changed(in-place update), in case of informer cache latency, after 5 seconds to remove it.
 		var disruptedPods, unavailablePods map[string]metav1.Time
-		disruptedPods, unavailablePods, recheckTime = r.buildDisruptedAndUnavailablePods(pods, pubClone, currentTime)
-		currentAvailable := countAvailablePods(pods, disruptedPods, unavailablePods, r.pubControl)
+		var evictedPods []string
+		disruptedPods, evictedPods, unavailablePods, recheckTime = r.buildDisruptedAndUnavailablePods(pods, pubClone, currentTime)
+		currentAvailable := countAvailablePods(pubClone, pods, disruptedPods, unavailablePods, r.pubControl)
 
 		start = time.Now()
-		updateErr := r.updatePubStatus(pubClone, currentAvailable, desiredAvailable, expectedCount, disruptedPods, unavailablePods)
+		updateErr := r.updatePubStatus(pubClone, currentAvailable, desiredAvailable, expectedCount, disruptedPods, evictedPods, unavailablePods)
 		costOfUpdate += time.Since(start)
 		if updateErr == nil {
 			return nil
@@ -359,7 +412,7 @@ func (r *ReconcilePodUnavailableBudget) patchRelatedPubAnnotationInPod(req ctrl.
 	}
 	// fetch workload
 	apiVersion, kind, ns, name := arr[1], arr[2], req.Namespace, arr[3]
-	workload, err := r.controllerFinder.GetScaleAndSelectorForRef(apiVersion, kind, ns, name, "")
+	workload, err := r.controllerFinder.GetScaleAndSelectorForTargetRef(apiVersion, kind, ns, name, "")
 	if err != nil {
 		return err
 	} else if workload == nil {
@@ -397,7 +450,22 @@ func (r *ReconcilePodUnavailableBudget) patchRelatedPubAnnotationInPod(req ctrl.
 	return nil
 }
 
-func countAvailablePods(pods []*corev1.Pod, disruptedPods, unavailablePods map[string]metav1.Time, control pubcontrol.PubControl) (currentAvailable int32) {
+func (r *ReconcilePodUnavailableBudget) cleanRelatedPubAnnotationInPods(namespace, pubName string) error {
+	pods, err := r.pubControl.GetPodsWithRelatedPub(namespace, pubName)
+	if err != nil || len(pods) == 0 {
+		return err
+	}
+	body := fmt.Sprintf(`{"metadata":{"annotations":{"%s":null}}}`, pubcontrol.PodRelatedPubAnnotation)
+	for _, pod := range pods {
+		if err = r.Patch(context.TODO(), pod.DeepCopy(), client.RawPatch(types.StrategicMergePatchType, []byte(body))); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	klog.V(3).Infof("clean pub(%s/%s) related-pub annotation in pods(%d) success", namespace, pubName, len(pods))
+	return nil
+}
+
+func countAvailablePods(pub *policyv1alpha1.PodUnavailableBudget, pods []*corev1.Pod, disruptedPods, unavailablePods map[string]metav1.Time, control pubcontrol.PubControl) (currentAvailable int32) {
 	recordPods := sets.String{}
 	for pName := range disruptedPods {
 		recordPods.Insert(pName)
@@ -415,7 +483,7 @@ func countAvailablePods(pods []*corev1.Pod, disruptedPods, unavailablePods map[s
 			continue
 		}
 		// pod consistent and ready
-		if control.IsPodStateConsistent(pod) && control.IsPodReady(pod) {
+		if control.IsPodStateConsistent(pod) && control.IsPodReady(pod, pub) {
 			currentAvailable++
 		}
 	}
@@ -540,18 +608,20 @@ func (r *ReconcilePodUnavailableBudget) getExpectedScale(pub *policyv1alpha1.Pod
 }
 
 func (r *ReconcilePodUnavailableBudget) buildDisruptedAndUnavailablePods(pods []*corev1.Pod, pub *policyv1alpha1.PodUnavailableBudget, currentTime time.Time) (
-	// disruptedPods, unavailablePods, recheckTime
-	map[string]metav1.Time, map[string]metav1.Time, *time.Time) {
+	// disruptedPods, evictedPods, unavailablePods, recheckTime
+	map[string]metav1.Time, []string, map[string]metav1.Time, *time.Time) {
 
 	disruptedPods := pub.Status.DisruptedPods
+	evictedPods := sets.NewString(pub.Status.EvictedPods...)
 	unavailablePods := pub.Status.UnavailablePods
 
 	resultDisruptedPods := make(map[string]metav1.Time)
+	var resultEvictedPods []string
 	resultUnavailablePods := make(map[string]metav1.Time)
 	var recheckTime *time.Time
 
 	if disruptedPods == nil && unavailablePods == nil {
-		return resultDisruptedPods, resultUnavailablePods, recheckTime
+		return resultDisruptedPods, resultEvictedPods, resultUnavailablePods, recheckTime
 	}
 	for _, pod := range pods {
 		if !kubecontroller.IsPodActive(pod) {
@@ -561,12 +631,19 @@ func (r *ReconcilePodUnavailableBudget) buildDisruptedAndUnavailablePods(pods []
 		//handle disruption pods which will be eviction or deletion
 		disruptionTime, found := disruptedPods[pod.Name]
 		if found {
-			expectedDeletion := disruptionTime.Time.Add(DeletionTimeout)
+			timeout := getStaleTimeout(pub, DeletionTimeout)
+			if evictedPods.Has(pod.Name) && timeout > EvictionTimeout {
+				timeout = EvictionTimeout
+			}
+			expectedDeletion := disruptionTime.Time.Add(timeout)
 			if expectedDeletion.Before(currentTime) {
 				r.recorder.Eventf(pod, corev1.EventTypeWarning, "NotDeleted", "Pod was expected by PUB %s/%s to be deleted but it wasn't",
 					pub.Namespace, pub.Name)
 			} else {
 				resultDisruptedPods[pod.Name] = disruptionTime
+				if evictedPods.Has(pod.Name) {
+					resultEvictedPods = append(resultEvictedPods, pod.Name)
+				}
 				if recheckTime == nil || expectedDeletion.Before(*recheckTime) {
 					recheckTime = &expectedDeletion
 				}
@@ -576,8 +653,8 @@ func (r *ReconcilePodUnavailableBudget) buildDisruptedAndUnavailablePods(pods []
 		// handle unavailable pods which have been in-updated specification
 		unavailableTime, found := unavailablePods[pod.Name]
 		if found {
-			// in case of informer cache latency, after 10 seconds to remove it
-			expectedUpdate := unavailableTime.Time.Add(UpdatedDelayCheckTime)
+			// in case of informer cache latency, after 10 seconds(by default) to remove it
+			expectedUpdate := unavailableTime.Time.Add(getStaleTimeout(pub, UpdatedDelayCheckTime))
 			if expectedUpdate.Before(currentTime) {
 				continue
 			} else {
@@ -589,16 +666,29 @@ func (r *ReconcilePodUnavailableBudget) buildDisruptedAndUnavailablePods(pods []
 
 		}
 	}
-	return resultDisruptedPods, resultUnavailablePods, recheckTime
+	sort.Strings(resultEvictedPods)
+	return resultDisruptedPods, resultEvictedPods, resultUnavailablePods, recheckTime
+}
+
+// getStaleTimeout returns how long a pod recorded in pub status stays unavailable before the budget is refunded.
+func getStaleTimeout(pub *policyv1alpha1.PodUnavailableBudget, defaultTimeout time.Duration) time.Duration {
+	if pub.Spec.StaleTimeoutSeconds != nil && *pub.Spec.StaleTimeoutSeconds > 0 {
+		return time.Duration(*pub.Spec.StaleTimeoutSeconds) * time.Second
+	}
+	if defaultStaleTimeout > 0 {
+		return defaultStaleTimeout
+	}
+	return defaultTimeout
 }
 
 func (r *ReconcilePodUnavailableBudget) updatePubStatus(pub *policyv1alpha1.PodUnavailableBudget, currentAvailable, desiredAvailable, expectedCount int32,
-	disruptedPods, unavailablePods map[string]metav1.Time) error {
+	disruptedPods map[string]metav1.Time, evictedPods []string, unavailablePods map[string]metav1.Time) error {
 
 	unavailableAllowed := currentAvailable - desiredAvailable
 	if unavailableAllowed <= 0 {
 		unavailableAllowed = 0
 	}
+	conditions := calculatePubConditions(pub.Status.Conditions, currentAvailable, desiredAvailable, unavailableAllowed, len(disruptedPods)+len(unavailablePods))
 
 	if pub.Status.CurrentAvailable == currentAvailable &&
 		pub.Status.DesiredAvailable == desiredAvailable &&
@@ -606,7 +696,9 @@ func (r *ReconcilePodUnavailableBudget) updatePubStatus(pub *policyv1alpha1.PodU
 		pub.Status.UnavailableAllowed == unavailableAllowed &&
 		pub.Status.ObservedGeneration == pub.Generation &&
 		apiequality.Semantic.DeepEqual(pub.Status.DisruptedPods, disruptedPods) &&
-		apiequality.Semantic.DeepEqual(pub.Status.UnavailablePods, unavailablePods) {
+		apiequality.Semantic.DeepEqual(pub.Status.EvictedPods, evictedPods) &&
+		apiequality.Semantic.DeepEqual(pub.Status.UnavailablePods, unavailablePods) &&
+		apiequality.Semantic.DeepEqual(pub.Status.Conditions, conditions) {
 		return nil
 	}
 
@@ -616,8 +708,13 @@ func (r *ReconcilePodUnavailableBudget) updatePubStatus(pub *policyv1alpha1.PodU
 		TotalReplicas:      expectedCount,
 		UnavailableAllowed: unavailableAllowed,
 		DisruptedPods:      disruptedPods,
+		EvictedPods:        evictedPods,
 		UnavailablePods:    unavailablePods,
 		ObservedGeneration: pub.Generation,
+		Conditions:         conditions,
+		// rejection statistics are recorded by webhook
+		LastRejectedTime: pub.Status.LastRejectedTime,
+		RejectedCount:    pub.Status.RejectedCount,
 	}
 	err := r.Client.Status().Update(context.TODO(), pub)
 	if err != nil {
@@ -631,6 +728,141 @@ func (r *ReconcilePodUnavailableBudget) updatePubStatus(pub *policyv1alpha1.PodU
 	return nil
 }
 
+// getExpectedScaleFromHPA replaces the replicas of the workloads protected by pub with the desiredReplicas of
+// HorizontalPodAutoscalers targeting them, because workload replicas lag behind HPA during scaling.
+func (r *ReconcilePodUnavailableBudget) getExpectedScaleFromHPA(pub *policyv1alpha1.PodUnavailableBudget, expectedCount int32) (int32, error) {
+	hpaList := &autoscalingv1.HorizontalPodAutoscalerList{}
+	if err := r.List(context.TODO(), hpaList, &client.ListOptions{Namespace: pub.Namespace}, utilclient.DisableDeepCopy); err != nil {
+		return 0, err
+	}
+	var labelSelector labels.Selector
+	if pub.Spec.TargetReference == nil && pub.Spec.Selector != nil {
+		var err error
+		// This error is irreversible, so there is no need to return error
+		if labelSelector, err = util.GetFastLabelSelector(pub.Spec.Selector); err != nil {
+			return expectedCount, nil
+		}
+	}
+
+	for i := range hpaList.Items {
+		hpa := &hpaList.Items[i]
+		// hpa hasn't calculated desired replicas yet
+		if hpa.Status.DesiredReplicas <= 0 {
+			continue
+		}
+		ref := hpa.Spec.ScaleTargetRef
+		if pub.Spec.TargetReference != nil {
+			if !isReferenceEqual(&policyv1alpha1.TargetReference{APIVersion: ref.APIVersion, Kind: ref.Kind, Name: ref.Name}, pub.Spec.TargetReference) {
+				continue
+			}
+		} else if labelSelector == nil {
+			continue
+		}
+		workload, err := r.controllerFinder.GetScaleAndSelectorForTargetRef(ref.APIVersion, ref.Kind, pub.Namespace, ref.Name, "")
+		if err != nil {
+			return 0, err
+		}
+		if workload == nil || !workload.Metadata.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if labelSelector != nil && (labelSelector.Empty() || !labelSelector.Matches(labels.Set(workload.TempLabels))) {
+			continue
+		}
+		klog.V(3).Infof("pub(%s/%s) resolve replicas of %s(%s) against hpa(%s) desiredReplicas(%d), workload replicas(%d)",
+			pub.Namespace, pub.Name, ref.Kind, ref.Name, hpa.Name, hpa.Status.DesiredReplicas, workload.Scale)
+		expectedCount += hpa.Status.DesiredReplicas - workload.Scale
+	}
+	if expectedCount < 0 {
+		expectedCount = 0
+	}
+	return expectedCount, nil
+}
+
+// isWorkloadSelectedByCustomTarget returns whether the workload template labels are selected by the custom workload
+// referenced by pub targetRef, whose selector is resolved via scale subresource.
+func (r *ReconcilePodUnavailableBudget) isWorkloadSelectedByCustomTarget(workload *controllerfinder.ScaleAndSelector, pub *policyv1alpha1.PodUnavailableBudget) (bool, error) {
+	ref := pub.Spec.TargetReference
+	if controllerfinder.IsValidGroupVersionKind(ref.APIVersion, ref.Kind) || len(workload.TempLabels) == 0 {
+		return false, nil
+	}
+	target, err := r.controllerFinder.GetScaleAndSelectorForTargetRef(ref.APIVersion, ref.Kind, pub.Namespace, ref.Name, "")
+	if err != nil || target == nil || target.Selector == nil {
+		return false, err
+	}
+	// This error is irreversible, so there is no need to return error
+	labelSelector, err := util.GetFastLabelSelector(target.Selector)
+	if err != nil {
+		return false, nil
+	}
+	return !labelSelector.Empty() && labelSelector.Matches(labels.Set(workload.TempLabels)), nil
+}
+
+// calculatePubConditions returns the conditions of pub status,
+// and the LastTransitionTime of a condition is kept if its status doesn't change.
+func calculatePubConditions(oldConditions []policyv1alpha1.PodUnavailableBudgetCondition, currentAvailable, desiredAvailable, unavailableAllowed int32,
+	unconfirmedCount int) []policyv1alpha1.PodUnavailableBudgetCondition {
+
+	newConditions := make([]policyv1alpha1.PodUnavailableBudgetCondition, 0, 3)
+	if currentAvailable >= desiredAvailable {
+		newConditions = append(newConditions, policyv1alpha1.PodUnavailableBudgetCondition{
+			Type:    policyv1alpha1.PubConditionSufficientPods,
+			Status:  corev1.ConditionTrue,
+			Reason:  "SufficientPods",
+			Message: fmt.Sprintf("currentAvailable(%d) is no less than desiredAvailable(%d)", currentAvailable, desiredAvailable),
+		})
+	} else {
+		newConditions = append(newConditions, policyv1alpha1.PodUnavailableBudgetCondition{
+			Type:    policyv1alpha1.PubConditionSufficientPods,
+			Status:  corev1.ConditionFalse,
+			Reason:  "InsufficientPods",
+			Message: fmt.Sprintf("currentAvailable(%d) is less than desiredAvailable(%d)", currentAvailable, desiredAvailable),
+		})
+	}
+
+	if unavailableAllowed <= 0 {
+		newConditions = append(newConditions, policyv1alpha1.PodUnavailableBudgetCondition{
+			Type:    policyv1alpha1.PubConditionBudgetExhausted,
+			Status:  corev1.ConditionTrue,
+			Reason:  "NoUnavailableAllowed",
+			Message: "no more pods are allowed to be unavailable, pod operations will be rejected",
+		})
+	} else {
+		newConditions = append(newConditions, policyv1alpha1.PodUnavailableBudgetCondition{
+			Type:    policyv1alpha1.PubConditionBudgetExhausted,
+			Status:  corev1.ConditionFalse,
+			Reason:  "UnavailableAllowed",
+			Message: fmt.Sprintf("%d pods are allowed to be unavailable", unavailableAllowed),
+		})
+	}
+
+	if unconfirmedCount > pubcontrol.MaxUnavailablePodSize {
+		newConditions = append(newConditions, policyv1alpha1.PodUnavailableBudgetCondition{
+			Type:    policyv1alpha1.PubConditionTooManyUnconfirmedPods,
+			Status:  corev1.ConditionTrue,
+			Reason:  "TooManyUnconfirmedPods",
+			Message: fmt.Sprintf("DisruptedPods and UnavailablePods exceed the max size(%d), pod operations will be rejected", pubcontrol.MaxUnavailablePodSize),
+		})
+	} else {
+		newConditions = append(newConditions, policyv1alpha1.PodUnavailableBudgetCondition{
+			Type:   policyv1alpha1.PubConditionTooManyUnconfirmedPods,
+			Status: corev1.ConditionFalse,
+			Reason: "UnconfirmedPodsWithinLimit",
+		})
+	}
+
+	now := metav1.Now()
+	for i := range newConditions {
+		newConditions[i].LastTransitionTime = now
+		for _, oldCondition := range oldConditions {
+			if oldCondition.Type == newConditions[i].Type && oldCondition.Status == newConditions[i].Status {
+				newConditions[i].LastTransitionTime = oldCondition.LastTransitionTime
+				break
+			}
+		}
+	}
+	return newConditions
+}
+
 func (r *ReconcilePodUnavailableBudget) getPubForWorkload(workload *controllerfinder.ScaleAndSelector) (*policyv1alpha1.PodUnavailableBudget, error) {
 	pubList := &policyv1alpha1.PodUnavailableBudgetList{}
 	if err := r.List(context.TODO(), pubList, &client.ListOptions{Namespace: workload.Metadata.Namespace}, utilclient.DisableDeepCopy); err != nil {
@@ -648,6 +880,12 @@ func (r *ReconcilePodUnavailableBudget) getPubForWorkload(workload *controllerfi
 			}, pub.Spec.TargetReference) {
 				return pub, nil
 			}
+			// the pods of custom workload may be owned by the intermediate workload, e.g. Argo Rollouts -> ReplicaSet -> Pod
+			if matched, err := r.isWorkloadSelectedByCustomTarget(workload, pub); err != nil {
+				return nil, err
+			} else if matched {
+				return pub, nil
+			}
 		} else {
 			// This error is irreversible, so continue
 			labelSelector, err := util.GetFastLabelSelector(pub.Spec.Selector)
@@ -28,6 +28,7 @@ import (
 	"github.com/openkruise/kruise/pkg/util"
 	"github.com/openkruise/kruise/pkg/util/controllerfinder"
 	apps "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -36,6 +37,7 @@ import (
 	"k8s.io/client-go/tools/record"
 	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
 	utilpointer "k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
@@ -45,6 +47,7 @@ func init() {
 	_ = policyv1alpha1.AddToScheme(scheme)
 	_ = corev1.AddToScheme(scheme)
 	_ = apps.AddToScheme(scheme)
+	_ = autoscalingv1.AddToScheme(scheme)
 }
 
 var (
@@ -754,6 +757,52 @@ func TestPubReconcile(t *testing.T) {
 				return *status
 			},
 		},
+		{
+			name: "select matched deployment, 10 DisruptionPods(5 eviction timeout)",
+			getPods: func() []*corev1.Pod {
+				var matchedPods []*corev1.Pod
+				for i := 0; i < 100; i++ {
+					pod := podDemo.DeepCopy()
+					pod.Name = fmt.Sprintf("%s-%d", pod.Name, i)
+					matchedPods = append(matchedPods, pod)
+				}
+				return matchedPods
+			},
+			getDeployment: func() *apps.Deployment {
+				object := deploymentDemo.DeepCopy()
+				object.Spec.Replicas = utilpointer.Int32Ptr(100)
+				return object
+			},
+			getReplicaSet: func() *apps.ReplicaSet {
+				object := replicaSetDemo.DeepCopy()
+				object.Spec.Replicas = utilpointer.Int32Ptr(100)
+				return object
+			},
+			getPub: func() *policyv1alpha1.PodUnavailableBudget {
+				pub := pubDemo.DeepCopy()
+				for i := 0; i < 10; i++ {
+					if i >= 0 && i < 5 {
+						pub.Status.DisruptedPods[fmt.Sprintf("test-pod-%d", i)] = metav1.Time{Time: time.Now().Add(-15 * time.Second)}
+					} else {
+						pub.Status.DisruptedPods[fmt.Sprintf("test-pod-%d", i)] = metav1.Now()
+					}
+					pub.Status.EvictedPods = append(pub.Status.EvictedPods, fmt.Sprintf("test-pod-%d", i))
+				}
+				return pub
+			},
+			expectPubStatus: func() policyv1alpha1.PodUnavailableBudgetStatus {
+				status := &policyv1alpha1.PodUnavailableBudgetStatus{DisruptedPods: map[string]metav1.Time{}}
+				for i := 5; i < 10; i++ {
+					status.DisruptedPods[fmt.Sprintf("test-pod-%d", i)] = metav1.Now()
+					status.EvictedPods = append(status.EvictedPods, fmt.Sprintf("test-pod-%d", i))
+				}
+				status.TotalReplicas = 100
+				status.DesiredAvailable = 70
+				status.CurrentAvailable = 95
+				status.UnavailableAllowed = 25
+				return *status
+			},
+		},
 	}
 
 	for _, cs := range cases {
@@ -814,6 +863,280 @@ func isPubStatusEqual(expectStatus, nowStatus policyv1alpha1.PodUnavailableBudge
 	for i := range nowStatus.DisruptedPods {
 		nowStatus.DisruptedPods[i] = nTime
 	}
+	// conditions are checked in TestCalculatePubConditions
+	expectStatus.Conditions = nil
+	nowStatus.Conditions = nil
 
 	return reflect.DeepEqual(expectStatus, nowStatus)
 }
+
+func TestCalculatePubConditions(t *testing.T) {
+	lastTime := metav1.NewTime(time.Now().Add(-time.Hour))
+	cases := []struct {
+		name               string
+		oldConditions      []policyv1alpha1.PodUnavailableBudgetCondition
+		currentAvailable   int32
+		desiredAvailable   int32
+		unavailableAllowed int32
+		unconfirmedCount   int
+		expectStatus       map[policyv1alpha1.PodUnavailableBudgetConditionType]corev1.ConditionStatus
+		expectKeepTime     map[policyv1alpha1.PodUnavailableBudgetConditionType]bool
+	}{
+		{
+			name:               "sufficient pods",
+			currentAvailable:   10,
+			desiredAvailable:   8,
+			unavailableAllowed: 2,
+			expectStatus: map[policyv1alpha1.PodUnavailableBudgetConditionType]corev1.ConditionStatus{
+				policyv1alpha1.PubConditionSufficientPods:         corev1.ConditionTrue,
+				policyv1alpha1.PubConditionBudgetExhausted:        corev1.ConditionFalse,
+				policyv1alpha1.PubConditionTooManyUnconfirmedPods: corev1.ConditionFalse,
+			},
+		},
+		{
+			name:               "insufficient pods, budget exhausted",
+			currentAvailable:   7,
+			desiredAvailable:   8,
+			unavailableAllowed: 0,
+			expectStatus: map[policyv1alpha1.PodUnavailableBudgetConditionType]corev1.ConditionStatus{
+				policyv1alpha1.PubConditionSufficientPods:         corev1.ConditionFalse,
+				policyv1alpha1.PubConditionBudgetExhausted:        corev1.ConditionTrue,
+				policyv1alpha1.PubConditionTooManyUnconfirmedPods: corev1.ConditionFalse,
+			},
+		},
+		{
+			name:               "too many unconfirmed pods, keep lastTransitionTime of unchanged condition",
+			currentAvailable:   3000,
+			desiredAvailable:   0,
+			unavailableAllowed: 3000,
+			unconfirmedCount:   pubcontrol.MaxUnavailablePodSize + 1,
+			oldConditions: []policyv1alpha1.PodUnavailableBudgetCondition{
+				{Type: policyv1alpha1.PubConditionSufficientPods, Status: corev1.ConditionTrue, LastTransitionTime: lastTime},
+				{Type: policyv1alpha1.PubConditionBudgetExhausted, Status: corev1.ConditionTrue, LastTransitionTime: lastTime},
+				{Type: policyv1alpha1.PubConditionTooManyUnconfirmedPods, Status: corev1.ConditionFalse, LastTransitionTime: lastTime},
+			},
+			expectStatus: map[policyv1alpha1.PodUnavailableBudgetConditionType]corev1.ConditionStatus{
+				policyv1alpha1.PubConditionSufficientPods:         corev1.ConditionTrue,
+				policyv1alpha1.PubConditionBudgetExhausted:        corev1.ConditionFalse,
+				policyv1alpha1.PubConditionTooManyUnconfirmedPods: corev1.ConditionTrue,
+			},
+			expectKeepTime: map[policyv1alpha1.PodUnavailableBudgetConditionType]bool{
+				policyv1alpha1.PubConditionSufficientPods: true,
+			},
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			conditions := calculatePubConditions(cs.oldConditions, cs.currentAvailable, cs.desiredAvailable, cs.unavailableAllowed, cs.unconfirmedCount)
+			if len(conditions) != len(cs.expectStatus) {
+				t.Fatalf("expect conditions(%d), but get(%d)", len(cs.expectStatus), len(conditions))
+			}
+			for _, condition := range conditions {
+				if condition.Status != cs.expectStatus[condition.Type] {
+					t.Fatalf("expect condition(%s) status(%s), but get(%s)", condition.Type, cs.expectStatus[condition.Type], condition.Status)
+				}
+				if keep := condition.LastTransitionTime.Equal(&lastTime); keep != cs.expectKeepTime[condition.Type] {
+					t.Fatalf("expect condition(%s) keep lastTransitionTime(%v), but get(%v)", condition.Type, cs.expectKeepTime[condition.Type], keep)
+				}
+			}
+		})
+	}
+}
+
+func TestGetStaleTimeout(t *testing.T) {
+	cases := []struct {
+		name                string
+		staleTimeoutSeconds *int32
+		globalTimeout       time.Duration
+		expectTimeout       time.Duration
+	}{
+		{
+			name:          "use default timeout",
+			expectTimeout: DeletionTimeout,
+		},
+		{
+			name:          "use global timeout",
+			globalTimeout: time.Minute,
+			expectTimeout: time.Minute,
+		},
+		{
+			name:                "use pub staleTimeoutSeconds",
+			staleTimeoutSeconds: utilpointer.Int32Ptr(120),
+			globalTimeout:       time.Minute,
+			expectTimeout:       2 * time.Minute,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			defer func(timeout time.Duration) { defaultStaleTimeout = timeout }(defaultStaleTimeout)
+			defaultStaleTimeout = cs.globalTimeout
+			pub := pubDemo.DeepCopy()
+			pub.Spec.StaleTimeoutSeconds = cs.staleTimeoutSeconds
+			if timeout := getStaleTimeout(pub, DeletionTimeout); timeout != cs.expectTimeout {
+				t.Fatalf("expect timeout(%s), but get(%s)", cs.expectTimeout, timeout)
+			}
+		})
+	}
+}
+
+func TestGetExpectedScaleFromHPA(t *testing.T) {
+	cases := []struct {
+		name            string
+		targetRef       *policyv1alpha1.TargetReference
+		templateLabels  map[string]string
+		hpaTarget       string
+		desiredReplicas int32
+		expectCount     int32
+	}{
+		{
+			name:            "targetRef, hpa scaling up",
+			targetRef:       &policyv1alpha1.TargetReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "nginx"},
+			hpaTarget:       "nginx",
+			desiredReplicas: 20,
+			expectCount:     20,
+		},
+		{
+			name:            "targetRef, hpa targets other workload",
+			targetRef:       &policyv1alpha1.TargetReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "nginx"},
+			hpaTarget:       "other",
+			desiredReplicas: 20,
+			expectCount:     10,
+		},
+		{
+			name:            "selector, hpa scaling down",
+			templateLabels:  map[string]string{"app": "nginx", "pub-controller": "true"},
+			hpaTarget:       "nginx",
+			desiredReplicas: 4,
+			expectCount:     4,
+		},
+		{
+			name:            "selector, workload not selected",
+			templateLabels:  map[string]string{"app": "nginx"},
+			hpaTarget:       "nginx",
+			desiredReplicas: 4,
+			expectCount:     10,
+		},
+		{
+			name:        "hpa desiredReplicas not calculated",
+			targetRef:   &policyv1alpha1.TargetReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "nginx"},
+			hpaTarget:   "nginx",
+			expectCount: 10,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			pub := pubDemo.DeepCopy()
+			pub.Annotations = map[string]string{pubcontrol.PubReplicasFromHPAAnnotation: "true"}
+			if cs.targetRef != nil {
+				pub.Spec.Selector = nil
+				pub.Spec.TargetReference = cs.targetRef
+			}
+			deployment := deploymentDemo.DeepCopy()
+			deployment.Spec.Template.Labels = cs.templateLabels
+			hpa := &autoscalingv1.HorizontalPodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "nginx-hpa"},
+				Spec: autoscalingv1.HorizontalPodAutoscalerSpec{
+					ScaleTargetRef: autoscalingv1.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: cs.hpaTarget},
+					MaxReplicas:    30,
+				},
+				Status: autoscalingv1.HorizontalPodAutoscalerStatus{DesiredReplicas: cs.desiredReplicas},
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(deployment, hpa, pub).Build()
+			reconciler := ReconcilePodUnavailableBudget{
+				Client:           fakeClient,
+				controllerFinder: controllerfinder.NewControllerFinder(fakeClient),
+			}
+			count, err := reconciler.getExpectedScaleFromHPA(pub, *deployment.Spec.Replicas)
+			if err != nil {
+				t.Fatalf("getExpectedScaleFromHPA failed: %s", err.Error())
+			}
+			if count != cs.expectCount {
+				t.Fatalf("expect count(%d), but get(%d)", cs.expectCount, count)
+			}
+		})
+	}
+}
+
+func TestReconcileWithUnobservedPods(t *testing.T) {
+	pub := pubDemo.DeepCopy()
+	pub.UID = "pub-expectations-uid"
+	pub.Status.UnavailableAllowed = 1
+	pub.Status.DisruptedPods = map[string]metav1.Time{podDemo.Name: metav1.Now()}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(deploymentDemo.DeepCopy(), replicaSetDemo.DeepCopy(), podDemo.DeepCopy(), pub).Build()
+	reconciler := ReconcilePodUnavailableBudget{
+		Client:           fakeClient,
+		recorder:         record.NewFakeRecorder(10),
+		controllerFinder: controllerfinder.NewControllerFinder(fakeClient),
+		pubControl:       pubcontrol.NewPubControl(fakeClient),
+	}
+	defer observations.forget(podDemo)
+	defer func() { _ = util.GlobalCache.Delete(pub) }()
+
+	// the pod recorded in pub status by webhook has not been observed, then skip
+	observations.forget(podDemo)
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: pub.Namespace, Name: pub.Name}}
+	result, err := reconciler.Reconcile(context.TODO(), request)
+	if err != nil {
+		t.Fatalf("reconcile pub failed: %s", err.Error())
+	}
+	if result.RequeueAfter <= 0 || result.RequeueAfter > DeletionTimeout {
+		t.Fatalf("expect requeue within %s, but get(%s)", DeletionTimeout, result.RequeueAfter)
+	}
+	newPub, _ := getLatestPub(fakeClient, pub)
+	if newPub.Status.UnavailableAllowed != 1 || newPub.Status.TotalReplicas != 0 {
+		t.Fatalf("expect pub status not rewritten, but get(%v)", newPub.Status)
+	}
+
+	// the pod is observed, then rewrite status
+	observations.observe(podDemo)
+	if _, err = reconciler.Reconcile(context.TODO(), request); err != nil {
+		t.Fatalf("reconcile pub failed: %s", err.Error())
+	}
+	newPub, _ = getLatestPub(fakeClient, pub)
+	if newPub.Status.TotalReplicas != 10 {
+		t.Fatalf("expect pub status rewritten, but get(%v)", newPub.Status)
+	}
+}
+
+func TestCleanRelatedPubAnnotationInPods(t *testing.T) {
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+	for i := 0; i < 4; i++ {
+		pod := podDemo.DeepCopy()
+		pod.Name = fmt.Sprintf("test-pod-%d", i)
+		pod.Annotations[pubcontrol.PodRelatedPubAnnotation] = pubDemo.Name
+		// pod protected by other pub
+		if i == 3 {
+			pod.Annotations[pubcontrol.PodRelatedPubAnnotation] = "other-pub"
+		}
+		if err := fakeClient.Create(context.TODO(), pod); err != nil {
+			t.Fatalf("create pod failed: %s", err.Error())
+		}
+	}
+	reconciler := ReconcilePodUnavailableBudget{
+		Client:           fakeClient,
+		recorder:         record.NewFakeRecorder(10),
+		controllerFinder: controllerfinder.NewControllerFinder(fakeClient),
+		pubControl:       pubcontrol.NewPubControl(fakeClient),
+	}
+	// pub has been deleted
+	_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: pubDemo.Namespace, Name: pubDemo.Name}})
+	if err != nil {
+		t.Fatalf("reconcile PodUnavailableBudget failed: %s", err.Error())
+	}
+	podList := &corev1.PodList{}
+	if err = fakeClient.List(context.TODO(), podList); err != nil {
+		t.Fatalf("list pods failed: %s", err.Error())
+	}
+	for _, pod := range podList.Items {
+		expect := ""
+		if pod.Name == "test-pod-3" {
+			expect = "other-pub"
+		}
+		if pod.Annotations[pubcontrol.PodRelatedPubAnnotation] != expect {
+			t.Fatalf("expect pod(%s) related-pub(%s), but get(%s)", pod.Name, expect, pod.Annotations[pubcontrol.PodRelatedPubAnnotation])
+		}
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podunavailablebudget
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// podObservations records the latest time that the pods related to pubs were observed in informer cache.
+// The pods recorded in pub.status.disruptedPods and pub.status.unavailablePods by webhook are the expectations of
+// pub controller, and each of them is satisfied once the pod has been observed since the time it was recorded.
+// So the expectations are persisted in pub status, and only the pub controller observes them.
+type podObservations struct {
+	sync.Mutex
+	times map[types.NamespacedName]time.Time
+}
+
+var observations = &podObservations{times: map[types.NamespacedName]time.Time{}}
+
+func (o *podObservations) observe(pod *corev1.Pod) {
+	o.Lock()
+	defer o.Unlock()
+	o.times[types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}] = time.Now()
+}
+
+func (o *podObservations) forget(pod *corev1.Pod) {
+	o.Lock()
+	defer o.Unlock()
+	delete(o.times, types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name})
+}
+
+func (o *podObservations) observedSince(key types.NamespacedName, since time.Time) bool {
+	o.Lock()
+	defer o.Unlock()
+	observed, ok := o.times[key]
+	return ok && !observed.Before(since)
+}
+
+// getUnobservedPods returns the pods recorded in pub status which have not been observed in informer cache,
+// and how long to wait for them. The pods recorded longer than the stale timeout are ignored.
+func (r *ReconcilePodUnavailableBudget) getUnobservedPods(pub *policyv1alpha1.PodUnavailableBudget, now time.Time) ([]string, time.Duration, error) {
+	var dirtyPods []string
+	var waitDuration time.Duration
+	check := func(recorded map[string]metav1.Time, timeout time.Duration, disrupted bool) error {
+		for podName, recordedTime := range recorded {
+			remaining := recordedTime.Add(timeout).Sub(now)
+			if remaining <= 0 {
+				continue
+			}
+			observed, err := r.isPodObserved(pub.Namespace, podName, recordedTime.Time, disrupted)
+			if err != nil {
+				return err
+			} else if observed {
+				continue
+			}
+			dirtyPods = append(dirtyPods, podName)
+			if waitDuration == 0 || remaining < waitDuration {
+				waitDuration = remaining
+			}
+		}
+		return nil
+	}
+	if err := check(pub.Status.DisruptedPods, getStaleTimeout(pub, DeletionTimeout), true); err != nil {
+		return nil, 0, err
+	}
+	if err := check(pub.Status.UnavailablePods, getStaleTimeout(pub, UpdatedDelayCheckTime), false); err != nil {
+		return nil, 0, err
+	}
+	sort.Strings(dirtyPods)
+	return dirtyPods, waitDuration, nil
+}
+
+func (r *ReconcilePodUnavailableBudget) isPodObserved(namespace, podName string, recordedTime time.Time, disrupted bool) (bool, error) {
+	pod := &corev1.Pod{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: podName}, pod); err != nil {
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	if disrupted && !pod.DeletionTimestamp.IsZero() {
+		return true, nil
+	}
+	return observations.observedSince(types.NamespacedName{Namespace: namespace, Name: podName}, recordedTime), nil
+}
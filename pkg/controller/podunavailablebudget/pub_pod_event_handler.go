@@ -26,6 +26,7 @@ import (
 	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
 	"github.com/openkruise/kruise/pkg/control/pubcontrol"
 	"github.com/openkruise/kruise/pkg/util"
+	utilclient "github.com/openkruise/kruise/pkg/util/client"
 	"github.com/openkruise/kruise/pkg/util/controllerfinder"
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -64,6 +65,7 @@ func (p *enqueueRequestForPod) Create(evt event.CreateEvent, q workqueue.RateLim
 }
 
 func (p *enqueueRequestForPod) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	p.deletePod(q, evt.Object)
 }
 
 func (p *enqueueRequestForPod) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {}
@@ -82,6 +84,7 @@ func (p *enqueueRequestForPod) addPod(q workqueue.RateLimitingInterface, obj run
 		p.enqueuePatchPubAnnotationRequest(q, pod)
 		return
 	}
+	observations.observe(pod)
 
 	// reconcile pub
 	pub, _ := p.pubControl.GetPubForPod(pod)
@@ -108,6 +111,7 @@ func (p *enqueueRequestForPod) updatePod(q workqueue.RateLimitingInterface, old,
 	if pub == nil {
 		return
 	}
+	observations.observe(newPod)
 	if isReconcile, enqueueDelayTime := isPodAvailableChanged(oldPod, newPod, pub, p.pubControl); isReconcile {
 		q.AddAfter(reconcile.Request{
 			NamespacedName: types.NamespacedName{
@@ -119,12 +123,32 @@ func (p *enqueueRequestForPod) updatePod(q workqueue.RateLimitingInterface, old,
 
 }
 
+func (p *enqueueRequestForPod) deletePod(q workqueue.RateLimitingInterface, obj runtime.Object) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return
+	}
+	observations.forget(pod)
+	pub, _ := p.pubControl.GetPubForPod(pod)
+	if pub == nil {
+		return
+	}
+	klog.V(3).Infof("delete pod(%s/%s) reconcile pub(%s/%s)", pod.Namespace, pod.Name, pub.Namespace, pub.Name)
+	q.Add(reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      pub.Name,
+			Namespace: pub.Namespace,
+		},
+	})
+}
+
 func (p *enqueueRequestForPod) enqueuePatchPubAnnotationRequest(q workqueue.RateLimitingInterface, pod *corev1.Pod) {
 	ref := metav1.GetControllerOf(pod)
 	if ref == nil {
 		return
 	}
-	if !controllerfinder.IsValidGroupVersionKind(ref.APIVersion, ref.Kind) {
+	// custom workloads are only supported when they are referenced by the targetRef of pub
+	if !controllerfinder.IsValidGroupVersionKind(ref.APIVersion, ref.Kind) && !p.isTargetedByPub(pod.Namespace, ref) {
 		return
 	}
 
@@ -137,6 +161,22 @@ func (p *enqueueRequestForPod) enqueuePatchPubAnnotationRequest(q workqueue.Rate
 	klog.V(6).Infof("reconcile patch workload(%s) related-pub annotation", name)
 }
 
+func (p *enqueueRequestForPod) isTargetedByPub(namespace string, ref *metav1.OwnerReference) bool {
+	pubList := &policyv1alpha1.PodUnavailableBudgetList{}
+	if err := p.client.List(context.TODO(), pubList, &client.ListOptions{Namespace: namespace}, utilclient.DisableDeepCopy); err != nil {
+		klog.Errorf("enqueueRequestForPod list pub failed: %s", err.Error())
+		return false
+	}
+	targetRef := &policyv1alpha1.TargetReference{APIVersion: ref.APIVersion, Kind: ref.Kind, Name: ref.Name}
+	for i := range pubList.Items {
+		pub := &pubList.Items[i]
+		if pub.Spec.TargetReference != nil && isReferenceEqual(targetRef, pub.Spec.TargetReference) {
+			return true
+		}
+	}
+	return false
+}
+
 func isPodAvailableChanged(oldPod, newPod *corev1.Pod, pub *policyv1alpha1.PodUnavailableBudget, control pubcontrol.PubControl) (bool, time.Duration) {
 	var enqueueDelayTime time.Duration
 	// If the pod's deletion timestamp is set, remove endpoint from ready address.
@@ -153,8 +193,8 @@ func isPodAvailableChanged(oldPod, newPod *corev1.Pod, pub *policyv1alpha1.PodUn
 	// will move from the unready endpoints set to the ready endpoints.
 	// So for the purposes of an endpoint, a readiness change on a pod
 	// means we have a changed pod.
-	oldReady := control.IsPodReady(oldPod) && control.IsPodStateConsistent(oldPod)
-	newReady := control.IsPodReady(newPod) && control.IsPodStateConsistent(newPod)
+	oldReady := control.IsPodReady(oldPod, pub) && control.IsPodStateConsistent(oldPod)
+	newReady := control.IsPodReady(newPod, pub) && control.IsPodStateConsistent(newPod)
 	if oldReady != newReady {
 		klog.V(3).Infof("pod(%s/%s) ConsistentAndReady changed(from %v to %v), and reconcile pub(%s/%s)",
 			newPod.Namespace, newPod.Name, oldReady, newReady, pub.Namespace, pub.Name)
@@ -276,3 +316,20 @@ func isReferenceEqual(ref1, ref2 *policyv1alpha1.TargetReference) bool {
 	}
 	return gv1.Group == gv2.Group && ref1.Kind == ref2.Kind && ref1.Name == ref2.Name
 }
+
+// getPubsResolvedByHPA returns the reconcile requests of pubs in namespace resolving replicas against hpa desiredReplicas
+func getPubsResolvedByHPA(c client.Client, namespace string) []reconcile.Request {
+	pubList := &policyv1alpha1.PodUnavailableBudgetList{}
+	if err := c.List(context.TODO(), pubList, &client.ListOptions{Namespace: namespace}, utilclient.DisableDeepCopy); err != nil {
+		klog.Errorf("List PodUnavailableBudget in namespace(%s) failed: %s", namespace, err.Error())
+		return nil
+	}
+	var requests []reconcile.Request
+	for i := range pubList.Items {
+		pub := &pubList.Items[i]
+		if pub.Annotations[pubcontrol.PubReplicasFromHPAAnnotation] == "true" {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: pub.Namespace, Name: pub.Name}})
+		}
+	}
+	return requests
+}
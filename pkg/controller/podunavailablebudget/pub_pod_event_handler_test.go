@@ -24,6 +24,8 @@ import (
 
 	"github.com/openkruise/kruise/pkg/control/pubcontrol"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/workqueue"
 	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -83,3 +85,37 @@ func TestPodEventHandler(t *testing.T) {
 		t.Errorf("unexpected update event handle queue size, expected 0 actual %d", updateQ.Len())
 	}
 }
+
+func TestPodEventHandlerObservePods(t *testing.T) {
+	pub := pubDemo.DeepCopy()
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pub).Build()
+	handler := newEnqueueRequestForPod(fakeClient)
+
+	oldPod := podDemo.DeepCopy()
+	oldPod.Annotations = map[string]string{pubcontrol.PodRelatedPubAnnotation: pub.Name}
+	key := types.NamespacedName{Namespace: oldPod.Namespace, Name: oldPod.Name}
+	defer observations.forget(oldPod)
+	recordedTime := time.Now()
+
+	// update, observe pod
+	newPod := oldPod.DeepCopy()
+	newPod.ResourceVersion = fmt.Sprintf("%d", time.Now().Unix())
+	newPod.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	handler.Update(event.UpdateEvent{ObjectOld: oldPod, ObjectNew: newPod}, workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()))
+	if !observations.observedSince(key, recordedTime) {
+		t.Fatalf("expect pod observed since %v", recordedTime)
+	}
+	if observations.observedSince(key, time.Now().Add(time.Minute)) {
+		t.Fatalf("expect pod not observed after the update")
+	}
+
+	// delete, forget pod
+	deleteQ := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	handler.Delete(event.DeleteEvent{Object: newPod}, deleteQ)
+	if deleteQ.Len() != 1 {
+		t.Errorf("unexpected delete event handle queue size, expected 1 actual %d", deleteQ.Len())
+	}
+	if observations.observedSince(key, recordedTime) {
+		t.Fatalf("expect pod forgotten after deletion")
+	}
+}
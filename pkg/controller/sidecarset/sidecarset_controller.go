@@ -22,9 +22,11 @@ import (
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
 	"github.com/openkruise/kruise/pkg/control/sidecarcontrol"
+	"github.com/openkruise/kruise/pkg/features"
 	"github.com/openkruise/kruise/pkg/util"
 	utildiscovery "github.com/openkruise/kruise/pkg/util/discovery"
 	"github.com/openkruise/kruise/pkg/util/expectations"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
 	"github.com/openkruise/kruise/pkg/util/ratelimiter"
 
 	corev1 "k8s.io/api/core/v1"
@@ -48,6 +50,9 @@ func init() {
 var (
 	concurrentReconciles = 3
 	controllerKind       = appsv1alpha1.SchemeGroupVersion.WithKind("SidecarSet")
+
+	// determined during controller initializing
+	isPreDownloadDisabled bool
 )
 
 /**
@@ -61,6 +66,11 @@ func Add(mgr manager.Manager) error {
 	if !utildiscovery.DiscoverGVK(controllerKind) {
 		return nil
 	}
+	if !utildiscovery.DiscoverGVK(appsv1alpha1.SchemeGroupVersion.WithKind("ImagePullJob")) ||
+		!utilfeature.DefaultFeatureGate.Enabled(features.KruiseDaemon) ||
+		!utilfeature.DefaultFeatureGate.Enabled(features.PreDownloadImageForInPlaceUpdate) {
+		isPreDownloadDisabled = true
+	}
 	return add(mgr, newReconciler(mgr))
 }
 
@@ -69,10 +79,21 @@ func newReconciler(mgr manager.Manager) reconcile.Reconciler {
 	expectations := expectations.NewUpdateExpectations(sidecarcontrol.RevisionAdapterImpl)
 	recorder := mgr.GetEventRecorderFor("sidecarset-controller")
 	cli := util.NewClientFromManager(mgr, "sidecarset-controller")
+	processor := NewSidecarSetProcessor(cli, expectations, recorder)
+	if hookRunner, err := newMigrationHookRunner(mgr.GetConfig()); err != nil {
+		klog.Errorf("Failed to create migration hook runner for sidecarset-controller: %v", err)
+	} else {
+		processor.hookRunner = hookRunner
+	}
+	if ephemeralControl, err := newEphemeralContainerControl(mgr.GetConfig()); err != nil {
+		klog.Errorf("Failed to create ephemeral container control for sidecarset-controller: %v", err)
+	} else {
+		processor.ephemeralControl = ephemeralControl
+	}
 	return &ReconcileSidecarSet{
 		Client:    cli,
 		scheme:    mgr.GetScheme(),
-		processor: NewSidecarSetProcessor(cli, expectations, recorder),
+		processor: processor,
 	}
 }
 
@@ -107,6 +128,11 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	// Watch for changes to Namespace
+	if err = c.Watch(&source.Kind{Type: &corev1.Namespace{}}, &enqueueRequestForNamespace{reader: mgr.GetCache()}); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -122,6 +148,10 @@ type ReconcileSidecarSet struct {
 
 // +kubebuilder:rbac:groups=apps.kruise.io,resources=sidecarsets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps.kruise.io,resources=sidecarsets/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=apps.kruise.io,resources=imagepulljobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=pods/ephemeralcontainers,verbs=get;update;patch
 // +kubebuilder:rbac:groups=apps,resources=controllerrevisions,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile reads that state of the cluster for a SidecarSet object and makes changes based on the state read
@@ -134,6 +164,7 @@ func (r *ReconcileSidecarSet) Reconcile(_ context.Context, request reconcile.Req
 		if errors.IsNotFound(err) {
 			// Object not found, return.  Created objects are automatically garbage collected.
 			// For additional cleanup logic use finalizers.
+			deleteMetrics(request.Name)
 			return reconcile.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
@@ -0,0 +1,162 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecarset
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/control/sidecarcontrol"
+	"github.com/openkruise/kruise/pkg/util"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+)
+
+// ephemeralContainerControl adds the ephemeral containers into the running pod.
+type ephemeralContainerControl interface {
+	CreateEphemeralContainers(pod *corev1.Pod, containers []corev1.EphemeralContainer) error
+}
+
+type realEphemeralContainerControl struct {
+	kubeClient kubernetes.Interface
+}
+
+func newEphemeralContainerControl(config *rest.Config) (ephemeralContainerControl, error) {
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return &realEphemeralContainerControl{kubeClient: kubeClient}, nil
+}
+
+func (c *realEphemeralContainerControl) CreateEphemeralContainers(pod *corev1.Pod, containers []corev1.EphemeralContainer) error {
+	oldPodJS, _ := json.Marshal(pod)
+	newPod := pod.DeepCopy()
+	newPod.Spec.EphemeralContainers = append(newPod.Spec.EphemeralContainers, containers...)
+	newPodJS, _ := json.Marshal(newPod)
+
+	patch, err := strategicpatch.CreateTwoWayMergePatch(oldPodJS, newPodJS, &corev1.Pod{})
+	if err != nil {
+		return fmt.Errorf("error creating patch to add ephemeral containers: %v", err)
+	}
+	klog.V(3).Infof("patch ephemeral containers to pod(%s/%s): %s", pod.Namespace, pod.Name, util.DumpJSON(patch))
+	_, err = c.kubeClient.CoreV1().Pods(pod.Namespace).
+		Patch(context.TODO(), pod.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{}, "ephemeralcontainers")
+	return err
+}
+
+// syncEphemeralContainers injects the containers of the ephemeral sidecarSet into the matched running pods
+// annotated with it as ephemeral containers, and updates the status of sidecarSet.
+func (p *Processor) syncEphemeralContainers(sidecarSet *appsv1alpha1.SidecarSet) error {
+	selector, err := util.GetFastLabelSelector(sidecarSet.Spec.Selector)
+	if err != nil {
+		return err
+	}
+	scopedNamespaces, err := p.getScopedNamespaces(sidecarSet)
+	if err != nil {
+		return err
+	}
+	pods, err := p.getSelectedPods(scopedNamespaces, selector)
+	if err != nil {
+		return err
+	}
+
+	if p.ephemeralControl == nil {
+		return fmt.Errorf("no ephemeral container control")
+	}
+
+	status := sidecarSet.Status.DeepCopy()
+	status.ObservedGeneration = sidecarSet.Generation
+	status.MatchedPods, status.UpdatedPods, status.ReadyPods, status.UpdatedReadyPods = 0, 0, 0, 0
+	// the failure of one pod should not block the others and the status update
+	var errs []error
+	for _, pod := range pods {
+		if !sidecarcontrol.IsActivePod(pod) || !isPodEphemeralTriggered(sidecarSet, pod) {
+			continue
+		}
+		status.MatchedPods++
+		containers := getEphemeralContainersToInject(sidecarSet, pod)
+		if len(containers) > 0 {
+			// ephemeral containers can only be added into the running pods
+			if pod.Status.Phase != corev1.PodRunning {
+				continue
+			}
+			if err := p.ephemeralControl.CreateEphemeralContainers(pod, containers); err != nil {
+				p.recorder.Eventf(pod, corev1.EventTypeWarning, "InjectEphemeralContainersFailed",
+					"failed to inject ephemeral containers of sidecarSet %s: %s", sidecarSet.Name, err.Error())
+				errs = append(errs, fmt.Errorf("failed to inject ephemeral containers into pod %s/%s: %v", pod.Namespace, pod.Name, err))
+				continue
+			}
+			p.recorder.Eventf(pod, corev1.EventTypeNormal, "InjectEphemeralContainersSucceed",
+				"inject ephemeral containers of sidecarSet %s successfully", sidecarSet.Name)
+		}
+		status.UpdatedPods++
+	}
+	if err := p.updateSidecarSetStatus(sidecarSet, status); err != nil {
+		errs = append(errs, err)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// isPodEphemeralTriggered returns whether the pod is annotated to inject the containers of sidecarSet as ephemeral containers.
+func isPodEphemeralTriggered(sidecarSet *appsv1alpha1.SidecarSet, pod *corev1.Pod) bool {
+	for _, name := range strings.Split(pod.Annotations[sidecarcontrol.SidecarSetEphemeralAnnotation], ",") {
+		if strings.TrimSpace(name) == sidecarSet.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// getEphemeralContainersToInject returns the containers of sidecarSet not injected into the pod yet as ephemeral containers.
+func getEphemeralContainersToInject(sidecarSet *appsv1alpha1.SidecarSet, pod *corev1.Pod) []corev1.EphemeralContainer {
+	var containers []corev1.EphemeralContainer
+	for i := range sidecarSet.Spec.Containers {
+		sidecarContainer := &sidecarSet.Spec.Containers[i]
+		if util.GetContainer(sidecarContainer.Name, pod) != nil || isEphemeralContainerInPod(sidecarContainer.Name, pod) {
+			continue
+		}
+		container := sidecarContainer.Container.DeepCopy()
+		container.Env = append(container.Env, corev1.EnvVar{Name: sidecarcontrol.SidecarEnvKey, Value: "true"})
+		volumeMounts, envs := sidecarcontrol.GetInjectedVolumeMountsAndEnvs(sidecarcontrol.New(sidecarSet), sidecarContainer, pod)
+		container.VolumeMounts = util.MergeVolumeMounts(container.VolumeMounts, volumeMounts)
+		container.Env = util.MergeEnvVar(container.Env, envs)
+		containers = append(containers, corev1.EphemeralContainer{
+			EphemeralContainerCommon: corev1.EphemeralContainerCommon(*container),
+		})
+	}
+	return containers
+}
+
+func isEphemeralContainerInPod(name string, pod *corev1.Pod) bool {
+	for i := range pod.Spec.EphemeralContainers {
+		if pod.Spec.EphemeralContainers[i].Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecarset
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/control/sidecarcontrol"
+	"github.com/openkruise/kruise/pkg/util/expectations"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type fakeEphemeralContainerControl struct {
+	client client.Client
+	pods   []string
+	// failedPods are the pods which fail to be injected
+	failedPods sets.String
+}
+
+func (c *fakeEphemeralContainerControl) CreateEphemeralContainers(pod *corev1.Pod, containers []corev1.EphemeralContainer) error {
+	c.pods = append(c.pods, pod.Name)
+	if c.failedPods.Has(pod.Name) {
+		return fmt.Errorf("injection failed")
+	}
+	podClone := pod.DeepCopy()
+	podClone.Spec.EphemeralContainers = append(podClone.Spec.EphemeralContainers, containers...)
+	return c.client.Update(context.TODO(), podClone)
+}
+
+func TestSyncEphemeralContainers(t *testing.T) {
+	sidecarSet := sidecarSetDemo.DeepCopy()
+	sidecarSet.Spec.InjectionStrategy.Ephemeral = true
+	sidecarSet.Spec.Containers[0].ShareVolumePolicy.Type = appsv1alpha1.ShareVolumePolicyEnabled
+
+	newPod := func(name string, triggered bool) *corev1.Pod {
+		pod := podDemo.DeepCopy()
+		pod.Name = name
+		pod.Annotations = map[string]string{}
+		pod.Spec.Containers = pod.Spec.Containers[:1]
+		if triggered {
+			pod.Annotations[sidecarcontrol.SidecarSetEphemeralAnnotation] = "other-sidecarset, test-sidecarset"
+		}
+		return pod
+	}
+	triggeredPod := newPod("test-pod-1", true)
+	untriggeredPod := newPod("test-pod-2", false)
+	pendingPod := newPod("test-pod-3", true)
+	pendingPod.Status.Phase = corev1.PodPending
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(sidecarSet, triggeredPod, untriggeredPod, pendingPod).Build()
+	exps := expectations.NewUpdateExpectations(sidecarcontrol.RevisionAdapterImpl)
+	processor := NewSidecarSetProcessor(fakeClient, exps, record.NewFakeRecorder(10))
+	ephemeralControl := &fakeEphemeralContainerControl{client: fakeClient}
+	processor.ephemeralControl = ephemeralControl
+
+	for i := 0; i < 2; i++ {
+		latest, err := getLatestSidecarSet(fakeClient, sidecarSet)
+		if err != nil {
+			t.Fatalf("get latest sidecarSet failed: %s", err.Error())
+		}
+		if _, err := processor.UpdateSidecarSet(latest); err != nil {
+			t.Fatalf("sync sidecarSet failed: %s", err.Error())
+		}
+	}
+	// the injected pod should not be patched again
+	if len(ephemeralControl.pods) != 1 || ephemeralControl.pods[0] != triggeredPod.Name {
+		t.Fatalf("expect ephemeral containers injected into pod %s once, but got %v", triggeredPod.Name, ephemeralControl.pods)
+	}
+
+	podOutput, err := getLatestPod(fakeClient, triggeredPod)
+	if err != nil {
+		t.Fatalf("get latest pod failed: %s", err.Error())
+	}
+	if len(podOutput.Spec.Containers) != 1 || len(podOutput.Spec.EphemeralContainers) != 1 {
+		t.Fatalf("expect only an ephemeral container injected, but got %v", podOutput.Spec)
+	}
+	container := podOutput.Spec.EphemeralContainers[0]
+	if container.Name != "test-sidecar" || container.Image != "test-image:v2" {
+		t.Fatalf("expect ephemeral container test-sidecar with image test-image:v2, but got %s %s", container.Name, container.Image)
+	}
+	if len(container.VolumeMounts) != 1 || container.VolumeMounts[0].Name != "nginx-volume" {
+		t.Fatalf("expect volumeMounts of app container shared, but got %v", container.VolumeMounts)
+	}
+
+	sidecarSetOutput, err := getLatestSidecarSet(fakeClient, sidecarSet)
+	if err != nil {
+		t.Fatalf("get latest sidecarSet failed: %s", err.Error())
+	}
+	if sidecarSetOutput.Status.MatchedPods != 2 || sidecarSetOutput.Status.UpdatedPods != 1 {
+		t.Fatalf("expect matchedPods 2 and updatedPods 1, but got %d and %d",
+			sidecarSetOutput.Status.MatchedPods, sidecarSetOutput.Status.UpdatedPods)
+	}
+}
+
+func TestSyncEphemeralContainersWithFailedPod(t *testing.T) {
+	sidecarSet := sidecarSetDemo.DeepCopy()
+	sidecarSet.Spec.InjectionStrategy.Ephemeral = true
+
+	var objs []client.Object
+	for _, name := range []string{"test-pod-1", "test-pod-2"} {
+		pod := podDemo.DeepCopy()
+		pod.Name = name
+		pod.Annotations = map[string]string{sidecarcontrol.SidecarSetEphemeralAnnotation: sidecarSet.Name}
+		pod.Spec.Containers = pod.Spec.Containers[:1]
+		objs = append(objs, pod)
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(objs, sidecarSet)...).Build()
+	exps := expectations.NewUpdateExpectations(sidecarcontrol.RevisionAdapterImpl)
+	processor := NewSidecarSetProcessor(fakeClient, exps, record.NewFakeRecorder(10))
+	ephemeralControl := &fakeEphemeralContainerControl{client: fakeClient, failedPods: sets.NewString("test-pod-1")}
+	processor.ephemeralControl = ephemeralControl
+
+	latest, err := getLatestSidecarSet(fakeClient, sidecarSet)
+	if err != nil {
+		t.Fatalf("get latest sidecarSet failed: %s", err.Error())
+	}
+	if _, err := processor.UpdateSidecarSet(latest); err == nil {
+		t.Fatalf("expect error of the failed pod, but got nil")
+	}
+	// the failed pod should not block the others and the status update
+	if !sets.NewString(ephemeralControl.pods...).Equal(sets.NewString("test-pod-1", "test-pod-2")) {
+		t.Fatalf("expect ephemeral containers injected into all the pods, but got %v", ephemeralControl.pods)
+	}
+	sidecarSetOutput, err := getLatestSidecarSet(fakeClient, sidecarSet)
+	if err != nil {
+		t.Fatalf("get latest sidecarSet failed: %s", err.Error())
+	}
+	if sidecarSetOutput.Status.MatchedPods != 2 || sidecarSetOutput.Status.UpdatedPods != 1 {
+		t.Fatalf("expect matchedPods 2 and updatedPods 1, but got %d and %d",
+			sidecarSetOutput.Status.MatchedPods, sidecarSetOutput.Status.UpdatedPods)
+	}
+}
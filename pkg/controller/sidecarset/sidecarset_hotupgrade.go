@@ -26,20 +26,37 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
 )
 
-func (p *Processor) flipHotUpgradingContainers(control sidecarcontrol.SidecarControl, pods []*corev1.Pod) error {
+// flipHotUpgradingContainers resets the old hot upgrade sidecar containers to empty once their migration hooks succeeded,
+// and returns true if some of the hooks are still running.
+func (p *Processor) flipHotUpgradingContainers(control sidecarcontrol.SidecarControl, pods []*corev1.Pod) (bool, error) {
+	var hookRunning bool
+	var errs []error
 	for _, pod := range pods {
+		succeeded, hookKeys, err := p.runMigrationHooks(control.GetSidecarset(), pod)
+		if err != nil {
+			recordHotUpgradeFailure(control.GetSidecarset())
+			p.recorder.Eventf(pod, corev1.EventTypeWarning, "MigrationHookFailed", fmt.Sprintf("run migration hook failed: %s", err.Error()))
+			errs = append(errs, err)
+			continue
+		} else if !succeeded {
+			hookRunning = true
+			continue
+		}
 		if err := p.flipPodSidecarContainer(control, pod); err != nil {
+			recordHotUpgradeFailure(control.GetSidecarset())
 			p.recorder.Eventf(pod, corev1.EventTypeWarning, "ResetContainerFailed", fmt.Sprintf("reset sidecar container image empty failed: %s", err.Error()))
-			return err
+			return hookRunning, err
 		}
+		p.hookStates.forget(hookKeys)
 		p.recorder.Eventf(pod, corev1.EventTypeNormal, "ResetContainerSucceed", fmt.Sprintf("reset sidecar container image empty successfully"))
 	}
-	return nil
+	return hookRunning, utilerrors.NewAggregate(errs)
 }
 
 func (p *Processor) flipPodSidecarContainer(control sidecarcontrol.SidecarControl, pod *corev1.Pod) error {
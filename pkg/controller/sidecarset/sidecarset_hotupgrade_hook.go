@@ -0,0 +1,324 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecarset
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/control/sidecarcontrol"
+	"github.com/openkruise/kruise/pkg/util"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/client-go/transport/spdy"
+)
+
+var (
+	// timeout of executing the migration hook of hot upgrade sidecar container
+	migrationHookTimeout = 30 * time.Second
+)
+
+// migrationHookRunner runs the migration hook in the old hot upgrade sidecar container.
+type migrationHookRunner interface {
+	Run(pod *corev1.Pod, container *corev1.Container, hook *appsv1alpha1.ProbeHandler) error
+}
+
+type realMigrationHookRunner struct {
+	config     *rest.Config
+	kubeClient kubernetes.Interface
+	httpClient *http.Client
+}
+
+func newMigrationHookRunner(config *rest.Config) (migrationHookRunner, error) {
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return &realMigrationHookRunner{
+		config:     config,
+		kubeClient: kubeClient,
+		httpClient: &http.Client{Timeout: migrationHookTimeout},
+	}, nil
+}
+
+func (r *realMigrationHookRunner) Run(pod *corev1.Pod, container *corev1.Container, hook *appsv1alpha1.ProbeHandler) error {
+	switch {
+	case hook.Exec != nil:
+		return r.runExec(pod, container, hook.Exec)
+	case hook.HTTPGet != nil:
+		return r.runHTTPGet(pod, container, hook.HTTPGet)
+	default:
+		return fmt.Errorf("no exec or httpGet specified in migration hook")
+	}
+}
+
+func (r *realMigrationHookRunner) runExec(pod *corev1.Pod, container *corev1.Container, action *corev1.ExecAction) error {
+	req := r.kubeClient.CoreV1().RESTClient().Post().
+		Resource("pods").Namespace(pod.Namespace).Name(pod.Name).SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container.Name,
+			Command:   action.Command,
+			Stdout:    true,
+			Stderr:    true,
+		}, clientgoscheme.ParameterCodec)
+	transport, upgrader, err := spdy.RoundTripperFor(r.config)
+	if err != nil {
+		return err
+	}
+	conns := &streamConnections{Upgrader: upgrader}
+	executor, err := remotecommand.NewSPDYExecutorForTransports(transport, conns, "POST", req.URL())
+	if err != nil {
+		return err
+	}
+
+	var stdout, stderr bytes.Buffer
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- executor.Stream(remotecommand.StreamOptions{Stdout: &stdout, Stderr: &stderr})
+	}()
+	select {
+	case err = <-errCh:
+	case <-time.After(migrationHookTimeout):
+		// the stream can not be cancelled, so close its connection to make it return
+		conns.closeAll()
+		<-errCh
+		return fmt.Errorf("exec %v timeout after %v", action.Command, migrationHookTimeout)
+	}
+	if err != nil {
+		return fmt.Errorf("exec %v failed: %v, stdout: %s, stderr: %s", action.Command, err, stdout.String(), stderr.String())
+	}
+	return nil
+}
+
+// streamConnections records the connections upgraded for the exec stream, so that they can be closed on timeout.
+type streamConnections struct {
+	spdy.Upgrader
+	mu     sync.Mutex
+	closed bool
+	conns  []httpstream.Connection
+}
+
+func (c *streamConnections) NewConnection(resp *http.Response) (httpstream.Connection, error) {
+	conn, err := c.Upgrader.NewConnection(resp)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		conn.Close()
+		return nil, fmt.Errorf("connection closed")
+	}
+	c.conns = append(c.conns, conn)
+	return conn, nil
+}
+
+func (c *streamConnections) closeAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	for _, conn := range c.conns {
+		conn.Close()
+	}
+}
+
+func (r *realMigrationHookRunner) runHTTPGet(pod *corev1.Pod, container *corev1.Container, action *corev1.HTTPGetAction) error {
+	// always request the pod itself, and host is forbidden by webhook
+	host := pod.Status.PodIP
+	if host == "" {
+		return fmt.Errorf("no pod ip for httpGet")
+	}
+	port, err := resolvePort(action.Port, container)
+	if err != nil {
+		return err
+	}
+	scheme := strings.ToLower(string(action.Scheme))
+	if scheme == "" {
+		scheme = "http"
+	}
+	u := &url.URL{Scheme: scheme, Host: net.JoinHostPort(host, strconv.Itoa(port)), Path: action.Path}
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	for _, header := range action.HTTPHeaders {
+		req.Header.Add(header.Name, header.Value)
+	}
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("httpGet %s failed: %v", u.String(), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("httpGet %s failed with status code %d", u.String(), resp.StatusCode)
+	}
+	return nil
+}
+
+func resolvePort(portReference intstr.IntOrString, container *corev1.Container) (int, error) {
+	if portReference.Type == intstr.Int {
+		return portReference.IntValue(), nil
+	}
+	for _, portSpec := range container.Ports {
+		if portSpec.Name == portReference.StrVal {
+			return int(portSpec.ContainerPort), nil
+		}
+	}
+	return strconv.Atoi(portReference.StrVal)
+}
+
+// migrationHookState is the state of a migration hook running in a container.
+type migrationHookState struct {
+	finished   bool
+	err        error
+	finishedAt time.Time
+}
+
+// migrationHookStates tracks the migration hooks running asynchronously, keyed by pod uid, container name and image,
+// so that a slow or failed hook of one pod never blocks the hot upgrade of other pods.
+type migrationHookStates struct {
+	sync.Mutex
+	states map[string]*migrationHookState
+}
+
+func newMigrationHookStates() *migrationHookStates {
+	return &migrationHookStates{states: map[string]*migrationHookState{}}
+}
+
+func migrationHookKey(pod *corev1.Pod, container *corev1.Container) string {
+	return fmt.Sprintf("%s/%s/%s", pod.UID, container.Name, container.Image)
+}
+
+// check starts the hook if it has not been started, and returns whether it has succeeded.
+// The failed hook is returned once and then forgotten, so that it will be retried in the next check.
+func (s *migrationHookStates) check(key string, run func() error) (bool, error) {
+	s.Lock()
+	defer s.Unlock()
+	s.gc()
+	state, ok := s.states[key]
+	if !ok {
+		state = &migrationHookState{}
+		s.states[key] = state
+		go func() {
+			err := run()
+			s.Lock()
+			defer s.Unlock()
+			state.finished, state.err, state.finishedAt = true, err, time.Now()
+		}()
+		return false, nil
+	}
+	if !state.finished {
+		return false, nil
+	}
+	if state.err != nil {
+		delete(s.states, key)
+		return false, state.err
+	}
+	return true, nil
+}
+
+// forget removes the succeeded hooks of pod once its old sidecar containers have been reset.
+func (s *migrationHookStates) forget(keys []string) {
+	s.Lock()
+	defer s.Unlock()
+	for _, key := range keys {
+		delete(s.states, key)
+	}
+}
+
+// gc removes the finished hooks that nobody checks anymore, e.g. the pod has been deleted.
+func (s *migrationHookStates) gc() {
+	for key, state := range s.states {
+		if state.finished && time.Since(state.finishedAt) > 10*migrationHookTimeout {
+			delete(s.states, key)
+		}
+	}
+}
+
+// runMigrationHooks runs asynchronously the migration hooks in the old hot upgrade sidecar containers that will be reset to empty.
+// It returns whether all the hooks of pod have succeeded, and the keys of them to forget after reset.
+func (p *Processor) runMigrationHooks(sidecarSet *appsv1alpha1.SidecarSet, pod *corev1.Pod) (bool, []string, error) {
+	allSucceeded := true
+	var keys []string
+	for i := range sidecarSet.Spec.Containers {
+		sidecarContainer := &sidecarSet.Spec.Containers[i]
+		if !sidecarcontrol.IsHotUpgradeContainer(sidecarContainer) || sidecarContainer.UpgradeStrategy.HotUpgradeMigrationHook == nil {
+			continue
+		}
+		_, emptyContainer := sidecarcontrol.GetPodHotUpgradeContainers(sidecarContainer.Name, pod)
+		container := util.GetContainer(emptyContainer, pod)
+		if container == nil || container.Image == sidecarContainer.UpgradeStrategy.HotUpgradeEmptyImage {
+			continue
+		}
+		if p.hookRunner == nil {
+			return false, nil, fmt.Errorf("no migration hook runner")
+		}
+		key := migrationHookKey(pod, container)
+		hook := sidecarContainer.UpgradeStrategy.HotUpgradeMigrationHook
+		podCopy, containerCopy := pod.DeepCopy(), container.DeepCopy()
+		succeeded, err := p.hookStates.check(key, func() error {
+			return p.hookRunner.Run(podCopy, containerCopy, hook)
+		})
+		if err != nil {
+			return false, nil, fmt.Errorf("run migration hook in container %s failed: %v", container.Name, err)
+		}
+		allSucceeded = allSucceeded && succeeded
+		keys = append(keys, key)
+	}
+	return allSucceeded, keys, nil
+}
+
+// getHotUpgradeMinReadyWait returns how long to wait for the new hot upgrade sidecar containers of pod
+// being ready for HotUpgradeMinReadySeconds, and 0 means no need to wait.
+func getHotUpgradeMinReadyWait(sidecarSet *appsv1alpha1.SidecarSet, pod *corev1.Pod) time.Duration {
+	var wait time.Duration
+	for i := range sidecarSet.Spec.Containers {
+		sidecarContainer := &sidecarSet.Spec.Containers[i]
+		minReadySeconds := sidecarContainer.UpgradeStrategy.HotUpgradeMinReadySeconds
+		if !sidecarcontrol.IsHotUpgradeContainer(sidecarContainer) || minReadySeconds <= 0 {
+			continue
+		}
+		workContainer, _ := sidecarcontrol.GetPodHotUpgradeContainers(sidecarContainer.Name, pod)
+		status := util.GetContainerStatus(workContainer, pod)
+		minReady := time.Duration(minReadySeconds) * time.Second
+		if status == nil || status.State.Running == nil {
+			if wait < minReady {
+				wait = minReady
+			}
+			continue
+		}
+		if left := minReady - time.Since(status.State.Running.StartedAt.Time); left > wait {
+			wait = left
+		}
+	}
+	return wait
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecarset
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/control/sidecarcontrol"
+	"github.com/openkruise/kruise/pkg/util/expectations"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type fakeMigrationHookRunner struct {
+	sync.Mutex
+	err        error
+	containers []string
+}
+
+func (r *fakeMigrationHookRunner) Run(pod *corev1.Pod, container *corev1.Container, hook *appsv1alpha1.ProbeHandler) error {
+	r.Lock()
+	defer r.Unlock()
+	r.containers = append(r.containers, container.Name)
+	return r.err
+}
+
+func (r *fakeMigrationHookRunner) setErr(err error) {
+	r.Lock()
+	defer r.Unlock()
+	r.err = err
+	r.containers = nil
+}
+
+func (r *fakeMigrationHookRunner) getContainers() []string {
+	r.Lock()
+	defer r.Unlock()
+	return append([]string{}, r.containers...)
+}
+
+func TestHotUpgradeMigrationHook(t *testing.T) {
+	sidecarSetInput := sidecarSetHotUpgrade.DeepCopy()
+	sidecarSetInput.Spec.Containers[0].UpgradeStrategy.HotUpgradeMigrationHook = &appsv1alpha1.ProbeHandler{
+		Exec: &corev1.ExecAction{Command: []string{"/drain.sh"}},
+	}
+	sidecarSetInput.Spec.Containers[0].UpgradeStrategy.HotUpgradeMinReadySeconds = 60
+	podInput := podHotUpgrade.DeepCopy()
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(sidecarSetInput, podInput).Build()
+	exps := expectations.NewUpdateExpectations(sidecarcontrol.RevisionAdapterImpl)
+	runner := &fakeMigrationHookRunner{}
+	processor := NewSidecarSetProcessor(fakeClient, exps, record.NewFakeRecorder(10))
+	processor.hookRunner = runner
+
+	syncAndGetPod := func() (*corev1.Pod, time.Duration, error) {
+		sidecarSet, err := getLatestSidecarSet(fakeClient, sidecarSetInput)
+		if err != nil {
+			t.Fatalf("get latest sidecarset failed: %s", err.Error())
+		}
+		result, syncErr := processor.UpdateSidecarSet(sidecarSet)
+		pod, err := getLatestPod(fakeClient, podInput)
+		if err != nil {
+			t.Fatalf("get latest pod failed: %s", err.Error())
+		}
+		return pod, result.RequeueAfter, syncErr
+	}
+	updatePodStatus := func(pod *corev1.Pod, startedAt time.Time) {
+		pod.Status.ContainerStatuses[2].Image = "test-image:v2"
+		pod.Status.ContainerStatuses[2].ImageID = testImageV2ImageID
+		pod.Status.ContainerStatuses[2].State = corev1.ContainerState{
+			Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(startedAt)},
+		}
+		if err := fakeClient.Status().Update(context.TODO(), pod); err != nil {
+			t.Fatalf("update pod status failed: %s", err.Error())
+		}
+	}
+
+	// upgrade test-sidecar-2 container
+	pod, _, err := syncAndGetPod()
+	if err != nil {
+		t.Fatalf("sync sidecarset failed: %s", err.Error())
+	}
+	if c := getPodContainerByName("test-sidecar-2", pod); c.Image != "test-image:v2" {
+		t.Fatalf("expect test-sidecar-2 upgraded, but get image %s", c.Image)
+	}
+
+	// test-sidecar-2 is not ready for minReadySeconds
+	updatePodStatus(pod, time.Now())
+	pod, requeueAfter, err := syncAndGetPod()
+	if err != nil {
+		t.Fatalf("sync sidecarset failed: %s", err.Error())
+	}
+	if requeueAfter <= time.Second || requeueAfter > time.Minute {
+		t.Fatalf("expect requeue after minReadySeconds, but get %v", requeueAfter)
+	}
+	if containers := runner.getContainers(); len(containers) != 0 {
+		t.Fatalf("expect migration hook not run, but run in %v", containers)
+	}
+	if c := getPodContainerByName("test-sidecar-1", pod); c.Image != "test-image:v1" {
+		t.Fatalf("expect test-sidecar-1 not reset, but get image %s", c.Image)
+	}
+
+	// runHook syncs to start the migration hook asynchronously, and waits for it finished
+	runHook := func() {
+		pod, requeueAfter, err := syncAndGetPod()
+		if err != nil {
+			t.Fatalf("sync sidecarset failed: %s", err.Error())
+		}
+		if requeueAfter != time.Second {
+			t.Fatalf("expect requeue after 1s for running hook, but get %v", requeueAfter)
+		}
+		if c := getPodContainerByName("test-sidecar-1", pod); c.Image != "test-image:v1" {
+			t.Fatalf("expect test-sidecar-1 not reset, but get image %s", c.Image)
+		}
+		if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+			processor.hookStates.Lock()
+			defer processor.hookStates.Unlock()
+			for _, state := range processor.hookStates.states {
+				if !state.finished {
+					return false, nil
+				}
+			}
+			return true, nil
+		}); err != nil {
+			t.Fatalf("wait for migration hook failed: %s", err.Error())
+		}
+	}
+
+	// migration hook failed
+	updatePodStatus(pod, time.Now().Add(-time.Hour))
+	runner.setErr(fmt.Errorf("drain failed"))
+	runHook()
+	pod, _, err = syncAndGetPod()
+	if err == nil {
+		t.Fatalf("expect sync failed by migration hook")
+	}
+	if c := getPodContainerByName("test-sidecar-1", pod); c.Image != "test-image:v1" {
+		t.Fatalf("expect test-sidecar-1 not reset, but get image %s", c.Image)
+	}
+
+	// migration hook succeeded
+	runner.setErr(nil)
+	runHook()
+	pod, _, err = syncAndGetPod()
+	if err != nil {
+		t.Fatalf("sync sidecarset failed: %s", err.Error())
+	}
+	if containers := runner.getContainers(); len(containers) != 1 || containers[0] != "test-sidecar-1" {
+		t.Fatalf("expect migration hook run in test-sidecar-1, but run in %v", containers)
+	}
+	if c := getPodContainerByName("test-sidecar-1", pod); c.Image != hotUpgradeEmptyImage {
+		t.Fatalf("expect test-sidecar-1 reset to empty image, but get image %s", c.Image)
+	}
+	if len(processor.hookStates.states) != 0 {
+		t.Fatalf("expect migration hook states forgotten after reset, but get %v", processor.hookStates.states)
+	}
+}
+
+func TestMigrationHookStates(t *testing.T) {
+	states := newMigrationHookStates()
+	block := make(chan struct{})
+	if succeeded, err := states.check("slow", func() error { <-block; return nil }); succeeded || err != nil {
+		t.Fatalf("expect slow hook started, but get %v, %v", succeeded, err)
+	}
+	// the slow hook never blocks the others
+	done := make(chan struct{})
+	if succeeded, err := states.check("fast", func() error { defer close(done); return nil }); succeeded || err != nil {
+		t.Fatalf("expect fast hook started, but get %v, %v", succeeded, err)
+	}
+	<-done
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return states.check("fast", nil)
+	}); err != nil {
+		t.Fatalf("expect fast hook succeeded, but get %v", err)
+	}
+	if succeeded, err := states.check("slow", nil); succeeded || err != nil {
+		t.Fatalf("expect slow hook running, but get %v, %v", succeeded, err)
+	}
+	close(block)
+	states.forget([]string{"fast"})
+	if _, ok := states.states["fast"]; ok {
+		t.Fatalf("expect fast hook forgotten")
+	}
+}
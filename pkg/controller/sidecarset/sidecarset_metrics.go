@@ -0,0 +1,86 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecarset
+
+import (
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// matchedPodsGauge is the number of active pods selected by SidecarSet, whether they are injected or not
+	matchedPodsGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kruise_sidecarset_matched_pods",
+			Help: "Number of active pods selected by SidecarSet",
+		},
+		[]string{"name"},
+	)
+
+	injectedPodsGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kruise_sidecarset_injected_pods",
+			Help: "Number of pods injected with SidecarSet",
+		},
+		[]string{"name"},
+	)
+
+	// pendingUpgradePodsGauge is the number of injected pods whose sidecar containers are not updated to the latest SidecarSet
+	pendingUpgradePodsGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kruise_sidecarset_pending_upgrade_pods",
+			Help: "Number of injected pods pending in-place upgrade of SidecarSet",
+		},
+		[]string{"name"},
+	)
+
+	hotUpgradeFailureCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kruise_sidecarset_hot_upgrade_failures_total",
+			Help: "Number of failures when resetting the old hot upgrade sidecar containers of SidecarSet",
+		},
+		[]string{"name"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(matchedPodsGauge, injectedPodsGauge, pendingUpgradePodsGauge, hotUpgradeFailureCounter)
+}
+
+// recordMatchedPodsMetrics records the number of active pods selected by sidecarSet.
+func recordMatchedPodsMetrics(sidecarSet *appsv1alpha1.SidecarSet, matchedPods int) {
+	matchedPodsGauge.WithLabelValues(sidecarSet.Name).Set(float64(matchedPods))
+}
+
+// recordRolloutMetrics records the rollout progress of sidecarSet from the calculated status.
+func recordRolloutMetrics(sidecarSet *appsv1alpha1.SidecarSet, status *appsv1alpha1.SidecarSetStatus) {
+	injectedPodsGauge.WithLabelValues(sidecarSet.Name).Set(float64(status.MatchedPods))
+	pendingUpgradePodsGauge.WithLabelValues(sidecarSet.Name).Set(float64(status.MatchedPods - status.UpdatedPods))
+}
+
+func recordHotUpgradeFailure(sidecarSet *appsv1alpha1.SidecarSet) {
+	hotUpgradeFailureCounter.WithLabelValues(sidecarSet.Name).Inc()
+}
+
+// deleteMetrics deletes all metrics of the SidecarSet, which has been deleted.
+func deleteMetrics(name string) {
+	matchedPodsGauge.DeleteLabelValues(name)
+	injectedPodsGauge.DeleteLabelValues(name)
+	pendingUpgradePodsGauge.DeleteLabelValues(name)
+	hotUpgradeFailureCounter.DeleteLabelValues(name)
+}
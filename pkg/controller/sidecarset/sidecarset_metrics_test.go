@@ -0,0 +1,65 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecarset
+
+import (
+	"testing"
+
+	"github.com/openkruise/kruise/pkg/control/sidecarcontrol"
+	"github.com/openkruise/kruise/pkg/util/expectations"
+	utilmetrics "github.com/openkruise/kruise/pkg/util/metrics"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSidecarSetMetrics(t *testing.T) {
+	sidecarSet := sidecarSetDemo.DeepCopy()
+	sidecarSet.Name = "test-sidecarset-metrics"
+	sidecarSet.Spec.UpdateStrategy.Paused = true
+	injectedPod := podDemo.DeepCopy()
+	injectedPod.Annotations[sidecarcontrol.SidecarSetHashAnnotation] = `{"test-sidecarset-metrics":{"hash":"aaa","sidecarList":["test-sidecar"]}}`
+	injectedPod.Annotations[sidecarcontrol.SidecarSetListAnnotation] = "test-sidecarset-metrics"
+	uninjectedPod := podDemo.DeepCopy()
+	uninjectedPod.Name = "test-pod-2"
+	uninjectedPod.Annotations = map[string]string{}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(sidecarSet, injectedPod, uninjectedPod).Build()
+	exps := expectations.NewUpdateExpectations(sidecarcontrol.RevisionAdapterImpl)
+	processor := NewSidecarSetProcessor(fakeClient, exps, record.NewFakeRecorder(10))
+
+	if _, err := processor.UpdateSidecarSet(sidecarSet); err != nil {
+		t.Fatalf("processor update sidecarset failed: %s", err.Error())
+	}
+	if value := utilmetrics.GetMetricValueDuringTest(t, matchedPodsGauge.WithLabelValues(sidecarSet.Name)); value != 2 {
+		t.Fatalf("expected 2 matched pods, got %v", value)
+	}
+	if value := utilmetrics.GetMetricValueDuringTest(t, injectedPodsGauge.WithLabelValues(sidecarSet.Name)); value != 1 {
+		t.Fatalf("expected 1 injected pod, got %v", value)
+	}
+	if value := utilmetrics.GetMetricValueDuringTest(t, pendingUpgradePodsGauge.WithLabelValues(sidecarSet.Name)); value != 1 {
+		t.Fatalf("expected 1 pending upgrade pod, got %v", value)
+	}
+
+	recordHotUpgradeFailure(sidecarSet)
+	if value := utilmetrics.GetMetricValueDuringTest(t, hotUpgradeFailureCounter.WithLabelValues(sidecarSet.Name)); value != 1 {
+		t.Fatalf("expected 1 hot upgrade failure, got %v", value)
+	}
+
+	deleteMetrics(sidecarSet.Name)
+	if matchedPodsGauge.DeleteLabelValues(sidecarSet.Name) || hotUpgradeFailureCounter.DeleteLabelValues(sidecarSet.Name) {
+		t.Fatalf("expected metrics deleted")
+	}
+}
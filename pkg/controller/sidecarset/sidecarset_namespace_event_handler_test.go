@@ -0,0 +1,80 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecarset
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestNamespaceEventHandler(t *testing.T) {
+	sidecarSet := sidecarSetDemo.DeepCopy()
+	sidecarSet.Name = "test-sidecarset-namespace-selector"
+	sidecarSet.Spec.NamespaceSelector = &metav1.LabelSelector{
+		MatchLabels: map[string]string{"sidecar-injection": "enabled"},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(sidecarSet, sidecarSetDemo.DeepCopy()).Build()
+	handler := enqueueRequestForNamespace{reader: fakeClient}
+
+	cases := []struct {
+		name      string
+		oldLabels map[string]string
+		newLabels map[string]string
+		expected  int
+	}{
+		{
+			name:      "namespace selected",
+			newLabels: map[string]string{"sidecar-injection": "enabled"},
+			expected:  1,
+		},
+		{
+			name:      "namespace unselected",
+			oldLabels: map[string]string{"sidecar-injection": "enabled"},
+			newLabels: map[string]string{"sidecar-injection": "disabled"},
+			expected:  1,
+		},
+		{
+			name:      "selected namespace labels changed",
+			oldLabels: map[string]string{"sidecar-injection": "enabled"},
+			newLabels: map[string]string{"sidecar-injection": "enabled", "foo": "bar"},
+			expected:  0,
+		},
+		{
+			name:      "unselected namespace labels changed",
+			newLabels: map[string]string{"foo": "bar"},
+			expected:  0,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+			handler.Update(event.UpdateEvent{
+				ObjectOld: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Labels: cs.oldLabels}},
+				ObjectNew: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Labels: cs.newLabels}},
+			}, q)
+			if q.Len() != cs.expected {
+				t.Fatalf("expect queue size %d, but got %d", cs.expected, q.Len())
+			}
+		})
+	}
+}
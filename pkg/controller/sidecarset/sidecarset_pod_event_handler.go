@@ -2,6 +2,7 @@ package sidecarset
 
 import (
 	"context"
+	"reflect"
 	"strings"
 	"time"
 
@@ -49,6 +50,7 @@ func (p *enqueueRequestForPod) addPod(q workqueue.RateLimitingInterface, obj run
 		return
 	}
 
+	enqueueEphemeralSidecarSets(q, nil, pod)
 	sidecarSets, err := p.getPodMatchedSidecarSets(pod)
 	if err != nil {
 		klog.Errorf("unable to get sidecarSets related with pod %s/%s, err: %v", pod.Namespace, pod.Name, err)
@@ -71,6 +73,7 @@ func (p *enqueueRequestForPod) updatePod(q workqueue.RateLimitingInterface, old,
 	if newPod.ResourceVersion == oldPod.ResourceVersion {
 		return
 	}
+	enqueueEphemeralSidecarSets(q, oldPod, newPod)
 
 	matchedSidecarSets, err := p.getPodMatchedSidecarSets(newPod)
 	if err != nil {
@@ -78,6 +81,16 @@ func (p *enqueueRequestForPod) updatePod(q workqueue.RateLimitingInterface, old,
 		return
 	}
 	for _, sidecarSet := range matchedSidecarSets {
+		// the pod may no longer match the sidecarSet, and its sidecars should be uninjected
+		if sidecarSet.Spec.UninjectionStrategy != nil && !reflect.DeepEqual(oldPod.Labels, newPod.Labels) {
+			klog.V(3).Infof("pod(%s/%s) labels changed, and reconcile sidecarSet(%s)", newPod.Namespace, newPod.Name, sidecarSet.Name)
+			q.Add(reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name: sidecarSet.Name,
+				},
+			})
+			continue
+		}
 		if sidecarSet.Spec.UpdateStrategy.Type == appsv1alpha1.NotUpdateSidecarSetStrategyType {
 			continue
 		}
@@ -99,6 +112,29 @@ func (p *enqueueRequestForPod) updatePod(q workqueue.RateLimitingInterface, old,
 
 }
 
+// enqueueEphemeralSidecarSets enqueues the sidecarSets in the ephemeral annotation of pod,
+// when the annotation is changed or the pod becomes running.
+func enqueueEphemeralSidecarSets(q workqueue.RateLimitingInterface, oldPod, newPod *corev1.Pod) {
+	value := newPod.Annotations[sidecarcontrol.SidecarSetEphemeralAnnotation]
+	if value == "" {
+		return
+	}
+	if oldPod != nil && oldPod.Annotations[sidecarcontrol.SidecarSetEphemeralAnnotation] == value && oldPod.Status.Phase == newPod.Status.Phase {
+		return
+	}
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		klog.V(3).Infof("pod(%s/%s) triggers ephemeral sidecarSet(%s)", newPod.Namespace, newPod.Name, name)
+		q.Add(reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name: name,
+			},
+		})
+	}
+}
+
 func (p *enqueueRequestForPod) getPodMatchedSidecarSets(pod *corev1.Pod) ([]*appsv1alpha1.SidecarSet, error) {
 	sidecarSetNames, ok := pod.Annotations[sidecarcontrol.SidecarSetListAnnotation]
 
@@ -130,7 +166,7 @@ func (p *enqueueRequestForPod) getPodMatchedSidecarSets(pod *corev1.Pod) ([]*app
 	}
 
 	for _, sidecarSet := range sidecarSets.Items {
-		matched, err := sidecarcontrol.PodMatchedSidecarSet(pod, sidecarSet)
+		matched, err := sidecarcontrol.PodMatchedSidecarSet(p.reader, pod, sidecarSet)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,151 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecarset
+
+import (
+	"context"
+	"fmt"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/control/sidecarcontrol"
+	imagejobutilfunc "github.com/openkruise/kruise/pkg/util/imagejob/utilfunction"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	intstrutil "k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/controller/history"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	minimumReplicasToPreDownloadImage = 3
+)
+
+// syncImagePreDownload creates ImagePullJobs for the nodes of the pods before their sidecars are updated,
+// and deletes the jobs once all the pods have been updated.
+func (p *Processor) syncImagePreDownload(sidecarSet *appsv1alpha1.SidecarSet, latestRevision *apps.ControllerRevision,
+	pods []*corev1.Pod, status *appsv1alpha1.SidecarSetStatus) error {
+	if isSidecarSetUpdateFinish(status) {
+		return imagejobutilfunc.DeleteJobsForWorkload(p.Client, sidecarSet)
+	}
+	return p.createImagePullJobsForSidecarUpdate(sidecarSet, latestRevision, pods)
+}
+
+func (p *Processor) createImagePullJobsForSidecarUpdate(sidecarSet *appsv1alpha1.SidecarSet, latestRevision *apps.ControllerRevision, pods []*corev1.Pod) error {
+	if _, ok := latestRevision.Labels[appsv1alpha1.ImagePreDownloadCreatedKey]; ok {
+		return nil
+	} else if _, ok := latestRevision.Labels[appsv1alpha1.ImagePreDownloadIgnoredKey]; ok {
+		return nil
+	}
+
+	// ignore if matched pods <= minimumReplicasToPreDownloadImage
+	if len(pods) <= minimumReplicasToPreDownloadImage {
+		klog.V(4).Infof("SidecarSet %s skipped to create ImagePullJob for matched pods %d <= %d",
+			sidecarSet.Name, len(pods), minimumReplicasToPreDownloadImage)
+		return p.patchControllerRevisionLabels(latestRevision, appsv1alpha1.ImagePreDownloadIgnoredKey, "true")
+	}
+
+	// ignore if all Pods update in one batch
+	strategy := sidecarSet.Spec.UpdateStrategy
+	var partition int
+	if strategy.Partition != nil {
+		partition, _ = intstrutil.GetValueFromIntOrPercent(strategy.Partition, len(pods), false)
+	}
+	maxUnavailable := 1
+	if strategy.MaxUnavailable != nil {
+		maxUnavailable, _ = intstrutil.GetValueFromIntOrPercent(strategy.MaxUnavailable, len(pods), false)
+	}
+	if partition == 0 && maxUnavailable >= len(pods) {
+		klog.V(4).Infof("SidecarSet %s skipped to create ImagePullJob for all Pods update in one batch, replicas=%d, partition=%d, maxUnavailable=%d",
+			sidecarSet.Name, len(pods), partition, maxUnavailable)
+		return p.patchControllerRevisionLabels(latestRevision, appsv1alpha1.ImagePreDownloadIgnoredKey, "true")
+	}
+
+	// start to create jobs
+
+	// the secrets are referenced in the namespaces of pods, so are the jobs created
+	var pullSecrets []string
+	for _, s := range sidecarSet.Spec.ImagePullSecrets {
+		pullSecrets = append(pullSecrets, s.Name)
+	}
+
+	labelMap := map[string]string{
+		history.ControllerRevisionHashLabel: sidecarcontrol.GetSidecarSetRevision(sidecarSet),
+	}
+
+	klog.V(3).Infof("SidecarSet %s begin to create ImagePullJobs for revision %s", sidecarSet.Name, latestRevision.Name)
+	for i := range sidecarSet.Spec.Containers {
+		sidecarContainer := &sidecarSet.Spec.Containers[i]
+		// job name is revision name + container name, it can not be more than 255 characters
+		jobName := fmt.Sprintf("%s-%s", latestRevision.Name, sidecarContainer.Name)
+		for namespace, nodeNames := range getNodesToPreDownload(sidecarSet, sidecarContainer, pods) {
+			err := imagejobutilfunc.CreateJobForWorkloadOnNodeNames(p.Client, sidecarSet, controllerKind, namespace, jobName,
+				sidecarContainer.Image, labelMap, nodeNames.List(), pullSecrets)
+			if err != nil {
+				if !errors.IsAlreadyExists(err) {
+					klog.Errorf("SidecarSet %s failed to create ImagePullJob %s/%s: %v", sidecarSet.Name, namespace, jobName, err)
+					p.recorder.Eventf(sidecarSet, corev1.EventTypeNormal, "FailedCreateImagePullJob", "failed to create ImagePullJob %s/%s: %v", namespace, jobName, err)
+				}
+				continue
+			}
+			klog.V(3).Infof("SidecarSet %s created ImagePullJob %s/%s for image: %s", sidecarSet.Name, namespace, jobName, sidecarContainer.Image)
+			p.recorder.Eventf(sidecarSet, corev1.EventTypeNormal, "CreatedImagePullJob", "created ImagePullJob %s/%s for image: %s", namespace, jobName, sidecarContainer.Image)
+		}
+	}
+
+	return p.patchControllerRevisionLabels(latestRevision, appsv1alpha1.ImagePreDownloadCreatedKey, "true")
+}
+
+// getNodesToPreDownload returns the nodes of the pods to be updated which have not run the image of sidecar container,
+// grouped by the namespaces of pods.
+func getNodesToPreDownload(sidecarSet *appsv1alpha1.SidecarSet, sidecarContainer *appsv1alpha1.SidecarContainer, pods []*corev1.Pod) map[string]sets.String {
+	containerNames := sets.NewString(sidecarContainer.Name)
+	if sidecarcontrol.IsHotUpgradeContainer(sidecarContainer) {
+		name1, name2 := sidecarcontrol.GetHotUpgradeContainerName(sidecarContainer.Name)
+		containerNames.Insert(name1, name2)
+	}
+
+	nodesByNamespace := make(map[string]sets.String)
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" || sidecarcontrol.IsPodSidecarUpdated(sidecarSet, pod) {
+			continue
+		}
+		var found bool
+		for i := range pod.Spec.Containers {
+			if containerNames.Has(pod.Spec.Containers[i].Name) && pod.Spec.Containers[i].Image == sidecarContainer.Image {
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+		if _, ok := nodesByNamespace[pod.Namespace]; !ok {
+			nodesByNamespace[pod.Namespace] = sets.NewString()
+		}
+		nodesByNamespace[pod.Namespace].Insert(pod.Spec.NodeName)
+	}
+	return nodesByNamespace
+}
+
+func (p *Processor) patchControllerRevisionLabels(revision *apps.ControllerRevision, key, value string) error {
+	body := fmt.Sprintf(`{"metadata":{"labels":{"%s":"%s"}}}`, key, value)
+	return p.Client.Patch(context.TODO(), revision, client.RawPatch(types.StrategicMergePatchType, []byte(body)))
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecarset
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCreateImagePullJobsForSidecarUpdate(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = appsv1alpha1.AddToScheme(scheme)
+
+	sidecarSet := factorySidecarSet()
+	sidecarSet.UID = "uid"
+	sidecarSet.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "secret"}}
+	// pod-0 and pod-1 are updated, pod-2 has run the new image
+	pods := factoryPodsCommon(6, 2, sidecarSet)
+	for i, pod := range pods {
+		pod.Namespace = "ns-a"
+		pod.Spec.NodeName = fmt.Sprintf("node-%d", i)
+	}
+	pods[2].Spec.Containers[1].Image = "test-image:v2"
+	pods[5].Namespace = "ns-b"
+
+	expectedNodes := map[string]sets.String{
+		"ns-a": sets.NewString("node-3", "node-4"),
+		"ns-b": sets.NewString("node-5"),
+	}
+	if nodes := getNodesToPreDownload(sidecarSet, &sidecarSet.Spec.Containers[0], pods); !reflect.DeepEqual(nodes, expectedNodes) {
+		t.Fatalf("expected nodes %v, got %v", expectedNodes, nodes)
+	}
+
+	revision := &apps.ControllerRevision{ObjectMeta: metav1.ObjectMeta{Namespace: "kruise-system", Name: "test-sidecarset-rev"}}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(revision).Build()
+	p := &Processor{Client: fakeClient, recorder: record.NewFakeRecorder(10)}
+	if err := p.createImagePullJobsForSidecarUpdate(sidecarSet, revision, pods); err != nil {
+		t.Fatalf("failed to create jobs: %v", err)
+	}
+
+	for namespace, nodes := range expectedNodes {
+		job := &appsv1alpha1.ImagePullJob{}
+		if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: "test-sidecarset-rev-test-sidecar"}, job); err != nil {
+			t.Fatalf("failed to get job in %s: %v", namespace, err)
+		}
+		if job.Spec.Image != "test-image:v2" {
+			t.Fatalf("expected image test-image:v2, got %s", job.Spec.Image)
+		}
+		if job.Spec.Selector == nil || !reflect.DeepEqual(job.Spec.Selector.Names, nodes.List()) {
+			t.Fatalf("expected nodes %v, got %v", nodes.List(), job.Spec.Selector)
+		}
+		if !reflect.DeepEqual(job.Spec.PullSecrets, []string{"secret"}) {
+			t.Fatalf("unexpected pull secrets %v", job.Spec.PullSecrets)
+		}
+		if owner := metav1.GetControllerOf(job); owner == nil || owner.UID != sidecarSet.UID {
+			t.Fatalf("unexpected owner %v", owner)
+		}
+	}
+
+	newRevision := &apps.ControllerRevision{}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: revision.Namespace, Name: revision.Name}, newRevision); err != nil {
+		t.Fatalf("failed to get revision: %v", err)
+	}
+	if newRevision.Labels[appsv1alpha1.ImagePreDownloadCreatedKey] != "true" {
+		t.Fatalf("expected revision labeled created, got %v", newRevision.Labels)
+	}
+}
+
+func TestCreateImagePullJobsForSidecarUpdateIgnored(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = appsv1alpha1.AddToScheme(scheme)
+
+	sidecarSet := factorySidecarSet()
+	pods := factoryPodsCommon(3, 0, sidecarSet)
+	revision := &apps.ControllerRevision{ObjectMeta: metav1.ObjectMeta{Namespace: "kruise-system", Name: "test-sidecarset-rev"}}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(revision).Build()
+	p := &Processor{Client: fakeClient, recorder: record.NewFakeRecorder(10)}
+	if err := p.createImagePullJobsForSidecarUpdate(sidecarSet, revision, pods); err != nil {
+		t.Fatalf("failed to create jobs: %v", err)
+	}
+
+	jobList := &appsv1alpha1.ImagePullJobList{}
+	if err := fakeClient.List(context.TODO(), jobList); err != nil {
+		t.Fatalf("failed to list jobs: %v", err)
+	}
+	if len(jobList.Items) != 0 {
+		t.Fatalf("expected no job for too few pods, got %d", len(jobList.Items))
+	}
+	newRevision := &apps.ControllerRevision{}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: revision.Namespace, Name: revision.Name}, newRevision); err != nil {
+		t.Fatalf("failed to get revision: %v", err)
+	}
+	if newRevision.Labels[appsv1alpha1.ImagePreDownloadIgnoredKey] != "true" {
+		t.Fatalf("expected revision labeled ignored, got %v", newRevision.Labels)
+	}
+}
@@ -20,10 +20,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"sort"
 	"strings"
 	"time"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/control/pubcontrol"
 	"github.com/openkruise/kruise/pkg/control/sidecarcontrol"
 	"github.com/openkruise/kruise/pkg/util"
 	utilclient "github.com/openkruise/kruise/pkg/util/client"
@@ -52,6 +55,10 @@ type Processor struct {
 	recorder           record.EventRecorder
 	historyController  history.Interface
 	updateExpectations expectations.UpdateExpectations
+	hookRunner         migrationHookRunner
+	hookStates         *migrationHookStates
+	pubControl         pubcontrol.PubControl
+	ephemeralControl   ephemeralContainerControl
 }
 
 func NewSidecarSetProcessor(cli client.Client, expectations expectations.UpdateExpectations, rec record.EventRecorder) *Processor {
@@ -60,6 +67,8 @@ func NewSidecarSetProcessor(cli client.Client, expectations expectations.UpdateE
 		updateExpectations: expectations,
 		recorder:           rec,
 		historyController:  historyutil.NewHistory(cli),
+		hookStates:         newMigrationHookStates(),
+		pubControl:         pubcontrol.NewPubControl(cli),
 	}
 }
 
@@ -69,6 +78,14 @@ func (p *Processor) UpdateSidecarSet(sidecarSet *appsv1alpha1.SidecarSet) (recon
 	if !control.IsActiveSidecarSet() {
 		return reconcile.Result{}, nil
 	}
+	// the ephemeral sidecarSet is injected into the annotated pods as ephemeral containers, and never updated
+	if sidecarSet.Spec.InjectionStrategy.Ephemeral {
+		return reconcile.Result{}, p.syncEphemeralContainers(sidecarSet)
+	}
+	// uninject the sidecars from the pods no longer matched, or all the injected pods if sidecarSet is deleting
+	if deleting, err := p.syncUninjection(sidecarSet); err != nil || deleting {
+		return reconcile.Result{}, err
+	}
 	// 1. get matching pods with the sidecarSet
 	pods, err := p.getMatchingPods(sidecarSet)
 	if err != nil {
@@ -84,8 +101,14 @@ func (p *Processor) UpdateSidecarSet(sidecarSet *appsv1alpha1.SidecarSet) (recon
 		return reconcile.Result{}, err
 	}
 
+	// roll back the sidecarSet if rollbackTo is set, and it will be synced again after updated
+	if rolledBack, err := p.rollback(sidecarSet, latestRevision); err != nil || rolledBack {
+		return reconcile.Result{}, err
+	}
+
 	// 2. calculate SidecarSet status based on pod and revision information
 	status := calculateStatus(control, pods, latestRevision, collisionCount)
+	recordRolloutMetrics(sidecarSet, status)
 	//update sidecarSet status in store
 	if err := p.updateSidecarSetStatus(sidecarSet, status); err != nil {
 		return reconcile.Result{}, err
@@ -103,6 +126,7 @@ func (p *Processor) UpdateSidecarSet(sidecarSet *appsv1alpha1.SidecarSet) (recon
 	}
 
 	// 3. If sidecar container hot upgrade complete, then set the other one(empty sidecar container) image to HotUpgradeEmptyImage
+	var requeueAfter time.Duration
 	if isSidecarSetHasHotUpgradeContainer(sidecarSet) {
 		var podsInHotUpgrading []*corev1.Pod
 		for _, pod := range pods {
@@ -121,63 +145,123 @@ func (p *Processor) UpdateSidecarSet(sidecarSet *appsv1alpha1.SidecarSet) (recon
 			}
 			if isPodSidecarInHotUpgrading(sidecarSet, pod) && control.IsPodStateConsistent(pod, sidecarContainers) &&
 				isHotUpgradingReady(sidecarSet, pod) {
+				// wait for the new sidecar containers ready for minReadySeconds
+				if wait := getHotUpgradeMinReadyWait(sidecarSet, pod); wait > 0 {
+					if requeueAfter == 0 || wait < requeueAfter {
+						requeueAfter = wait
+					}
+					continue
+				}
 				podsInHotUpgrading = append(podsInHotUpgrading, pod)
 			}
 		}
-		if err := p.flipHotUpgradingContainers(control, podsInHotUpgrading); err != nil {
+		hookRunning, err := p.flipHotUpgradingContainers(control, podsInHotUpgrading)
+		if err != nil {
 			return reconcile.Result{}, err
 		}
+		// check the running migration hooks in seconds
+		if hookRunning && (requeueAfter == 0 || requeueAfter > time.Second) {
+			requeueAfter = time.Second
+		}
 	}
 
 	// 4. SidecarSet upgrade strategy type is NotUpdate
 	if !isSidecarSetNotUpdate(sidecarSet) {
-		return reconcile.Result{}, nil
+		return reconcile.Result{RequeueAfter: requeueAfter}, nil
+	}
+
+	// pre-download the new images on the nodes before the sidecars of pods updated in-place
+	if !isPreDownloadDisabled {
+		if err := p.syncImagePreDownload(sidecarSet, latestRevision, pods, status); err != nil {
+			klog.Errorf("Failed to sync ImagePullJobs for sidecarSet %s: %v", sidecarSet.Name, err)
+		}
 	}
 
 	// 5. sidecarset already updates all matched pods, then return
 	if isSidecarSetUpdateFinish(status) {
 		klog.V(3).Infof("sidecarSet(%s) matched pods(number=%d) are latest, and don't need update", sidecarSet.Name, len(pods))
-		return reconcile.Result{}, nil
+		return reconcile.Result{RequeueAfter: requeueAfter}, nil
 	}
 
 	// 6. Paused indicates that the SidecarSet is paused to update matched pods
 	if sidecarSet.Spec.UpdateStrategy.Paused {
 		klog.V(3).Infof("sidecarSet is paused, name: %s", sidecarSet.Name)
-		return reconcile.Result{}, nil
+		return reconcile.Result{RequeueAfter: requeueAfter}, nil
 	}
 
 	// 7. upgrade pod sidecar
-	if err := p.updatePods(control, pods); err != nil {
+	rejected, err := p.updatePods(control, pods)
+	if err != nil {
 		return reconcile.Result{}, err
 	}
-	return reconcile.Result{}, nil
+	// retry the pods rejected by PodUnavailableBudget in seconds
+	if rejected && (requeueAfter == 0 || requeueAfter > time.Second) {
+		requeueAfter = time.Second
+	}
+	return reconcile.Result{RequeueAfter: requeueAfter}, nil
 }
 
-func (p *Processor) updatePods(control sidecarcontrol.SidecarControl, pods []*corev1.Pod) error {
+// updatePods updates the sidecar containers of the next pods to upgrade,
+// and returns true if some of them are rejected by PodUnavailableBudget.
+func (p *Processor) updatePods(control sidecarcontrol.SidecarControl, pods []*corev1.Pod) (bool, error) {
 	sidecarset := control.GetSidecarset()
 	// compute next updated pods based on the sidecarset upgrade strategy
-	upgradePods := NewStrategy().GetNextUpgradePods(control, pods)
+	upgradePods := NewStrategyWithNodeReader(p.Client).GetNextUpgradePods(control, pods)
 	if len(upgradePods) == 0 {
 		klog.V(3).Infof("sidecarSet next update is nil, skip this round, name: %s", sidecarset.Name)
-		return nil
+		return false, nil
 	}
 	// mark upgrade pods list
 	podNames := make([]string, 0, len(upgradePods))
+	var rejected bool
 	// upgrade pod sidecar
 	for _, pod := range upgradePods {
+		if sidecarset.Spec.UpdateStrategy.PodUnavailableBudgetGated {
+			allowed, err := p.isPodUpdateAllowedByPub(sidecarset, pod)
+			if err != nil {
+				return false, err
+			} else if !allowed {
+				rejected = true
+				continue
+			}
+		}
 		podNames = append(podNames, pod.Name)
 		if err := p.updatePodSidecarAndHash(control, pod); err != nil {
 			err := fmt.Errorf("updatePodSidecarAndHash error, s:%s, pod:%s, err:%v", sidecarset.Name, pod.Name, err)
-			return err
+			return false, err
 		}
 		p.updateExpectations.ExpectUpdated(sidecarset.Name, sidecarcontrol.GetSidecarSetRevision(sidecarset), pod)
 	}
 
 	klog.V(3).Infof("sidecarSet(%s) updated pods(%s)", sidecarset.Name, strings.Join(podNames, ","))
-	return nil
+	return rejected, nil
+}
+
+// isPodUpdateAllowedByPub returns whether the pod can be updated now according to its PodUnavailableBudget.
+// It is a dry run, and the budget will be decremented by PUB webhook when the pod is actually updated.
+func (p *Processor) isPodUpdateAllowedByPub(sidecarSet *appsv1alpha1.SidecarSet, pod *corev1.Pod) (bool, error) {
+	pub, err := p.pubControl.GetPubForPod(pod)
+	if err != nil || pub == nil || pubcontrol.IsPodNoProtected(pod, pubcontrol.UpdateOperation) {
+		return err == nil, err
+	}
+	allowed, reason, err := pubcontrol.PodUnavailableBudgetValidatePod(p.Client, p.pubControl, pub, pod, pubcontrol.UpdateOperation, true)
+	if err != nil {
+		return false, err
+	} else if !allowed {
+		pubcontrol.RecordPubRejection(p.recorder, pub, pod, pubcontrol.UpdateOperation, fmt.Sprintf("SidecarSet %s", sidecarSet.Name), reason)
+	}
+	return allowed, nil
 }
 
 func (p *Processor) updatePodSidecarAndHash(control sidecarcontrol.SidecarControl, pod *corev1.Pod) error {
+	// merge the overrides in pod namespace into the sidecars, as it is done at injection time,
+	// otherwise the upgrade reverts them
+	sidecarSetCopy := control.GetSidecarset().DeepCopy()
+	if err := sidecarcontrol.ApplySidecarSetOverrides(p.Client, pod.Namespace, sidecarSetCopy); err != nil {
+		return err
+	}
+	control = sidecarcontrol.New(sidecarSetCopy)
+
 	podClone := pod.DeepCopy()
 	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
 		// update pod sidecar container
@@ -219,7 +303,7 @@ func (p *Processor) listMatchedSidecarSets(pod *corev1.Pod) string {
 	//matched SidecarSet.Name list
 	sidecarSetNames := make([]string, 0)
 	for _, sidecarSet := range sidecarSetList.Items {
-		if matched, _ := sidecarcontrol.PodMatchedSidecarSet(pod, sidecarSet); matched {
+		if matched, _ := sidecarcontrol.PodMatchedSidecarSet(p.Client, pod, sidecarSet); matched {
 			sidecarSetNames = append(sidecarSetNames, sidecarSet.Name)
 		}
 	}
@@ -266,8 +350,10 @@ func (p *Processor) getMatchingPods(s *appsv1alpha1.SidecarSet) ([]*corev1.Pod,
 		return nil, err
 	}
 
-	// If sidecarSet.Spec.Namespace is empty, then select in cluster
-	scopedNamespaces := []string{s.Spec.Namespace}
+	scopedNamespaces, err := p.getScopedNamespaces(s)
+	if err != nil {
+		return nil, err
+	}
 	selectedPods, err := p.getSelectedPods(scopedNamespaces, selector)
 	if err != nil {
 		return nil, err
@@ -278,15 +364,42 @@ func (p *Processor) getMatchingPods(s *appsv1alpha1.SidecarSet) ([]*corev1.Pod,
 	// 2. ignore namespace: "kube-system", "kube-public"
 	// 3. never be injected sidecar container
 	var filteredPods []*corev1.Pod
+	var activePods int
 	for _, pod := range selectedPods {
-		if sidecarcontrol.IsActivePod(pod) && sidecarcontrol.IsPodInjectedSidecarSet(pod, s) &&
-			sidecarcontrol.IsPodConsistentWithSidecarSet(pod, s) {
+		if !sidecarcontrol.IsActivePod(pod) {
+			continue
+		}
+		activePods++
+		if sidecarcontrol.IsPodInjectedSidecarSet(pod, s) && sidecarcontrol.IsPodConsistentWithSidecarSet(pod, s) {
 			filteredPods = append(filteredPods, pod)
 		}
 	}
+	recordMatchedPodsMetrics(s, activePods)
 	return filteredPods, nil
 }
 
+// getScopedNamespaces returns the namespaces to select pods in for sidecarSet,
+// and an empty namespace means all namespaces in cluster.
+func (p *Processor) getScopedNamespaces(s *appsv1alpha1.SidecarSet) ([]string, error) {
+	// If sidecarSet.Spec.Namespace and NamespaceSelector are empty, then select in cluster
+	if s.Spec.NamespaceSelector == nil {
+		return []string{s.Spec.Namespace}, nil
+	}
+	selector, err := util.GetFastLabelSelector(s.Spec.NamespaceSelector)
+	if err != nil {
+		return nil, err
+	}
+	namespaces := &corev1.NamespaceList{}
+	if err = p.Client.List(context.TODO(), namespaces, &client.ListOptions{LabelSelector: selector}); err != nil {
+		return nil, fmt.Errorf("sidecarSet list namespaces error, err:%v", err)
+	}
+	var scopedNamespaces []string
+	for i := range namespaces.Items {
+		scopedNamespaces = append(scopedNamespaces, namespaces.Items[i].Name)
+	}
+	return scopedNamespaces, nil
+}
+
 // get selected pods(DisableDeepCopy:true, indicates must be deep copy before update pod objection)
 func (p *Processor) getSelectedPods(namespaces []string, selector labels.Selector) (relatedPods []*corev1.Pod, err error) {
 	// DisableDeepCopy:true, indicates must be deep copy before update pod objection
@@ -448,12 +561,17 @@ func replaceRevision(revisions []*apps.ControllerRevision, oldOne, newOne *apps.
 // ReadyPods: ready pods number
 // UpdatedReadyPods: updated and ready pods number
 // UnavailablePods: MatchedPods - UpdatedReadyPods
+// RevisionPods: pods number in each revision
 func calculateStatus(control sidecarcontrol.SidecarControl, pods []*corev1.Pod, latestRevision *apps.ControllerRevision, collisionCount int32,
 ) *appsv1alpha1.SidecarSetStatus {
 	sidecarset := control.GetSidecarset()
 	var matchedPods, updatedPods, readyPods, updatedAndReady int32
 	matchedPods = int32(len(pods))
+	revisionPods := make(map[string]int32)
 	for _, pod := range pods {
+		if revision := sidecarcontrol.GetPodSidecarSetControllerRevision(sidecarset.Name, pod); revision != "" {
+			revisionPods[revision]++
+		}
 		updated := sidecarcontrol.IsPodSidecarUpdated(sidecarset, pod)
 		if updated {
 			updatedPods++
@@ -473,9 +591,24 @@ func calculateStatus(control sidecarcontrol.SidecarControl, pods []*corev1.Pod,
 		UpdatedReadyPods:   updatedAndReady,
 		LatestRevision:     latestRevision.Name,
 		CollisionCount:     pointer.Int32Ptr(collisionCount),
+		RevisionPods:       getSidecarSetRevisionPods(revisionPods),
 	}
 }
 
+func getSidecarSetRevisionPods(revisionPods map[string]int32) []appsv1alpha1.SidecarSetRevisionPods {
+	if len(revisionPods) == 0 {
+		return nil
+	}
+	revisions := make([]appsv1alpha1.SidecarSetRevisionPods, 0, len(revisionPods))
+	for revision, count := range revisionPods {
+		revisions = append(revisions, appsv1alpha1.SidecarSetRevisionPods{Revision: revision, Pods: count})
+	}
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Revision < revisions[j].Revision
+	})
+	return revisions
+}
+
 func isSidecarSetNotUpdate(s *appsv1alpha1.SidecarSet) bool {
 	if s.Spec.UpdateStrategy.Type == appsv1alpha1.NotUpdateSidecarSetStrategyType {
 		klog.V(3).Infof("sidecarSet spreading RollingUpdate config type, name: %s, type: %s", s.Name, s.Spec.UpdateStrategy.Type)
@@ -555,7 +688,8 @@ func inconsistentStatus(sidecarSet *appsv1alpha1.SidecarSet, status *appsv1alpha
 		status.ReadyPods != sidecarSet.Status.ReadyPods ||
 		status.UpdatedReadyPods != sidecarSet.Status.UpdatedReadyPods ||
 		status.LatestRevision != sidecarSet.Status.LatestRevision ||
-		status.CollisionCount != sidecarSet.Status.CollisionCount
+		status.CollisionCount != sidecarSet.Status.CollisionCount ||
+		!reflect.DeepEqual(status.RevisionPods, sidecarSet.Status.RevisionPods)
 }
 
 func isSidecarSetUpdateFinish(status *appsv1alpha1.SidecarSetStatus) bool {
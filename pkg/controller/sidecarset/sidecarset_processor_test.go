@@ -20,10 +20,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"strconv"
 	"testing"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
+	"github.com/openkruise/kruise/pkg/control/pubcontrol"
 	"github.com/openkruise/kruise/pkg/control/sidecarcontrol"
 	"github.com/openkruise/kruise/pkg/util"
 	"github.com/openkruise/kruise/pkg/util/expectations"
@@ -31,6 +34,7 @@ import (
 
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/kubernetes/pkg/controller/history"
@@ -266,6 +270,45 @@ func TestScopeNamespacePods(t *testing.T) {
 	}
 }
 
+func TestScopeNamespaceSelectorPods(t *testing.T) {
+	sidecarSet := sidecarSetDemo.DeepCopy()
+	sidecarSet.Spec.NamespaceSelector = &metav1.LabelSelector{
+		MatchLabels: map[string]string{"sidecar-injection": "enabled"},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(sidecarSet,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns-1", Labels: map[string]string{"sidecar-injection": "enabled"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns-2", Labels: map[string]string{"sidecar-injection": "enabled"}}},
+	).Build()
+	for i := 0; i < 90; i++ {
+		pod := podDemo.DeepCopy()
+		pod.Name = fmt.Sprintf("%s-%d", pod.Name, i)
+		if i >= 60 {
+			pod.Namespace = "test-ns-2"
+		} else if i >= 30 {
+			pod.Namespace = "test-ns-1"
+		}
+		fakeClient.Create(context.TODO(), pod)
+	}
+	exps := expectations.NewUpdateExpectations(sidecarcontrol.RevisionAdapterImpl)
+	processor := NewSidecarSetProcessor(fakeClient, exps, record.NewFakeRecorder(10))
+	pods, err := processor.getMatchingPods(sidecarSet)
+	if err != nil {
+		t.Fatalf("getMatchingPods failed: %s", err.Error())
+	}
+	if len(pods) != 60 {
+		t.Fatalf("except matching pods count(%d), but get count(%d)", 60, len(pods))
+	}
+	for _, pod := range pods {
+		if matched, err := sidecarcontrol.PodMatchedSidecarSet(fakeClient, pod, *sidecarSet); err != nil || !matched {
+			t.Fatalf("expect pod(%s/%s) matched sidecarSet, but get %v, err: %v", pod.Namespace, pod.Name, matched, err)
+		}
+	}
+	if matched, _ := sidecarcontrol.PodMatchedSidecarSet(fakeClient, podDemo, *sidecarSet); matched {
+		t.Fatalf("expect pod(%s/%s) not matched sidecarSet", podDemo.Namespace, podDemo.Name)
+	}
+}
+
 func TestCanUpgradePods(t *testing.T) {
 	sidecarSet := factorySidecarSet()
 	sidecarSet.Annotations[sidecarcontrol.SidecarSetHashWithoutImageAnnotation] = "without-bbb"
@@ -521,3 +564,98 @@ func TestTruncateHistory(t *testing.T) {
 		t.Fatalf("expected name %s, actual : %s", getName(15), rvs[9].Name)
 	}
 }
+
+func TestCalculateStatusRevisionPods(t *testing.T) {
+	sidecarSet := sidecarSetDemo.DeepCopy()
+	pods := factoryPodsCommon(5, 0, sidecarSet)
+	for i := range pods {
+		if i == 0 {
+			// pods injected before revisions recorded are not counted
+			continue
+		}
+		sidecarSetHash := map[string]sidecarcontrol.SidecarSetUpgradeSpec{
+			sidecarSet.Name: {SidecarSetControllerRevision: "revision-" + strconv.Itoa(i%2)},
+		}
+		by, _ := json.Marshal(&sidecarSetHash)
+		pods[i].Annotations[sidecarcontrol.SidecarSetHashAnnotation] = string(by)
+	}
+	latestRevision := &apps.ControllerRevision{}
+	latestRevision.SetName("revision-1")
+
+	status := calculateStatus(sidecarcontrol.New(sidecarSet), pods, latestRevision, 0)
+	expected := []appsv1alpha1.SidecarSetRevisionPods{
+		{Revision: "revision-0", Pods: 2},
+		{Revision: "revision-1", Pods: 2},
+	}
+	if !reflect.DeepEqual(status.RevisionPods, expected) {
+		t.Fatalf("expect revision pods %v, but get %v", expected, status.RevisionPods)
+	}
+}
+
+func TestUpdatePodsGatedByPodUnavailableBudget(t *testing.T) {
+	sidecarSet := sidecarSetDemo.DeepCopy()
+	sidecarSet.Spec.UpdateStrategy.PodUnavailableBudgetGated = true
+	pod := podDemo.DeepCopy()
+	pod.Annotations[pubcontrol.PodRelatedPubAnnotation] = "test-pub"
+	pub := &policyv1alpha1.PodUnavailableBudget{
+		ObjectMeta: metav1.ObjectMeta{Namespace: pod.Namespace, Name: "test-pub"},
+		Status:     policyv1alpha1.PodUnavailableBudgetStatus{UnavailableAllowed: 0},
+	}
+
+	for _, unavailableAllowed := range []int32{0, 1} {
+		pub.Status.UnavailableAllowed = unavailableAllowed
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(sidecarSet.DeepCopy(), pod.DeepCopy(), pub.DeepCopy()).Build()
+		exps := expectations.NewUpdateExpectations(sidecarcontrol.RevisionAdapterImpl)
+		processor := NewSidecarSetProcessor(fakeClient, exps, record.NewFakeRecorder(10))
+		rejected, err := processor.updatePods(sidecarcontrol.New(sidecarSet), []*corev1.Pod{pod})
+		if err != nil {
+			t.Fatalf("update pods failed: %s", err.Error())
+		}
+		podOutput, err := getLatestPod(fakeClient, pod)
+		if err != nil {
+			t.Fatalf("get latest pod failed: %s", err.Error())
+		}
+		updated := isSidecarImageUpdated(podOutput, "test-sidecar", "test-image:v2")
+		if expected := unavailableAllowed > 0; updated != expected || rejected == expected {
+			t.Fatalf("unavailableAllowed %d: expect pod updated %v, but get updated %v, rejected %v",
+				unavailableAllowed, expected, updated, rejected)
+		}
+	}
+}
+
+func TestUpdatePodsWithSidecarSetOverrides(t *testing.T) {
+	sidecarSet := sidecarSetDemo.DeepCopy()
+	pod := podDemo.DeepCopy()
+	override := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: pod.Namespace,
+			Name:      "test-override",
+			Labels:    map[string]string{sidecarcontrol.SidecarSetOverrideLabel: sidecarSet.Name},
+		},
+		Data: map[string]string{"test-sidecar": `imageTag: v3`},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(sidecarSet.DeepCopy(), pod.DeepCopy(), override).Build()
+	exps := expectations.NewUpdateExpectations(sidecarcontrol.RevisionAdapterImpl)
+	processor := NewSidecarSetProcessor(fakeClient, exps, record.NewFakeRecorder(10))
+	if _, err := processor.updatePods(sidecarcontrol.New(sidecarSet), []*corev1.Pod{pod}); err != nil {
+		t.Fatalf("update pods failed: %s", err.Error())
+	}
+	podOutput, err := getLatestPod(fakeClient, pod)
+	if err != nil {
+		t.Fatalf("get latest pod failed: %s", err.Error())
+	}
+	if !isSidecarImageUpdated(podOutput, "test-sidecar", "test-image:v3") {
+		t.Fatalf("expect sidecar upgraded with the override image test-image:v3, but got %v", podOutput.Spec.Containers)
+	}
+	if sidecarSet.Spec.Containers[0].Image != "test-image:v2" {
+		t.Fatalf("expect sidecarSet unchanged, but got image %s", sidecarSet.Spec.Containers[0].Image)
+	}
+
+	override.Data["test-sidecar"] = `imageTag: [`
+	fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(sidecarSet.DeepCopy(), pod.DeepCopy(), override).Build()
+	processor = NewSidecarSetProcessor(fakeClient, exps, record.NewFakeRecorder(10))
+	if _, err := processor.updatePods(sidecarcontrol.New(sidecarSet), []*corev1.Pod{pod}); err == nil {
+		t.Fatalf("expect error for invalid override, but got nil")
+	}
+}
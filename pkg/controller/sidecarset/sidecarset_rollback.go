@@ -0,0 +1,122 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecarset
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/control/sidecarcontrol"
+	"github.com/openkruise/kruise/pkg/util"
+
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/controller/history"
+)
+
+// rollback restores the sidecarSet to the revision in spec.updateStrategy.rollbackTo, and clears rollbackTo.
+// It returns true if rollbackTo is handled, and the sidecarSet will be synced again after it is updated.
+func (p *Processor) rollback(sidecarSet *appsv1alpha1.SidecarSet, latestRevision *apps.ControllerRevision) (bool, error) {
+	rollbackTo := sidecarSet.Spec.UpdateStrategy.RollbackTo
+	if rollbackTo == nil {
+		return false, nil
+	}
+
+	hc := sidecarcontrol.NewHistoryControl(p.Client)
+	selector, err := util.GetFastLabelSelector(hc.GetRevisionLabelSelector(sidecarSet))
+	if err != nil {
+		return true, err
+	}
+	revisions, err := p.historyController.ListControllerRevisions(sidecarSet, selector)
+	if err != nil {
+		return true, err
+	}
+	history.SortControllerRevisions(revisions)
+
+	target := findRollbackRevision(revisions, latestRevision, rollbackTo.Revision)
+	if target == nil {
+		p.recorder.Eventf(sidecarSet, corev1.EventTypeWarning, "RollbackRevisionNotFound",
+			fmt.Sprintf("unable to find revision %d to roll back", rollbackTo.Revision))
+		return true, p.updateSidecarSetRollback(sidecarSet, nil)
+	}
+
+	revisionSidecarSet := &appsv1alpha1.SidecarSet{}
+	if err := json.Unmarshal(target.Data.Raw, revisionSidecarSet); err != nil {
+		return true, fmt.Errorf("failed to decode revision %s: %v", target.Name, err)
+	}
+	if err := p.updateSidecarSetRollback(sidecarSet, &revisionSidecarSet.Spec); err != nil {
+		return true, err
+	}
+	p.recorder.Eventf(sidecarSet, corev1.EventTypeNormal, "RollbackDone",
+		fmt.Sprintf("rolled back to revision %s(%d)", target.Name, target.Revision))
+	return true, nil
+}
+
+// findRollbackRevision returns the revision with the revision number, or the last revision before
+// the latest one if the revision number is 0. The revisions must be sorted by increasing Revision.
+func findRollbackRevision(revisions []*apps.ControllerRevision, latestRevision *apps.ControllerRevision, revision int64) *apps.ControllerRevision {
+	if revision != 0 {
+		for _, r := range revisions {
+			if r.Revision == revision {
+				return r
+			}
+		}
+		return nil
+	}
+	for i := len(revisions) - 1; i >= 0; i-- {
+		if revisions[i].Revision < latestRevision.Revision {
+			return revisions[i]
+		}
+	}
+	return nil
+}
+
+// updateSidecarSetRollback clears rollbackTo of sidecarSet, and restores the fields recorded in revision
+// from revisionSpec if it is not nil.
+func (p *Processor) updateSidecarSetRollback(sidecarSet *appsv1alpha1.SidecarSet, revisionSpec *appsv1alpha1.SidecarSetSpec) error {
+	sidecarSetClone := sidecarSet.DeepCopy()
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		sidecarSetClone.Spec.UpdateStrategy.RollbackTo = nil
+		if revisionSpec != nil {
+			sidecarSetClone.Spec.Containers = revisionSpec.Containers
+			sidecarSetClone.Spec.InitContainers = revisionSpec.InitContainers
+			sidecarSetClone.Spec.Volumes = revisionSpec.Volumes
+			sidecarSetClone.Spec.ImagePullSecrets = revisionSpec.ImagePullSecrets
+		}
+		updateErr := p.Client.Update(context.TODO(), sidecarSetClone)
+		if updateErr == nil {
+			return nil
+		}
+
+		key := types.NamespacedName{
+			Name: sidecarSetClone.Name,
+		}
+		if err := p.Client.Get(context.TODO(), key, sidecarSetClone); err != nil {
+			klog.Errorf("error getting updated sidecarSet %s from client", sidecarSetClone.Name)
+		}
+		return updateErr
+	})
+	if err != nil {
+		return fmt.Errorf("failed to roll back sidecarSet %s: %v", sidecarSet.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecarset
+
+import (
+	"context"
+	"testing"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/control/sidecarcontrol"
+	"github.com/openkruise/kruise/pkg/util/expectations"
+
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSidecarSetRollback(t *testing.T) {
+	sidecarSetInput := sidecarSetDemo.DeepCopy()
+	sidecarSetInput.Spec.Containers[0].Image = "test-image:v1"
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(sidecarSetInput).Build()
+	exps := expectations.NewUpdateExpectations(sidecarcontrol.RevisionAdapterImpl)
+	processor := NewSidecarSetProcessor(fakeClient, exps, record.NewFakeRecorder(10))
+
+	syncSidecarSet := func() *appsv1alpha1.SidecarSet {
+		sidecarSet, err := getLatestSidecarSet(fakeClient, sidecarSetInput)
+		if err != nil {
+			t.Fatalf("get latest sidecarset failed: %s", err.Error())
+		}
+		if _, err = processor.UpdateSidecarSet(sidecarSet); err != nil {
+			t.Fatalf("processor update sidecarset failed: %s", err.Error())
+		}
+		if sidecarSet, err = getLatestSidecarSet(fakeClient, sidecarSetInput); err != nil {
+			t.Fatalf("get latest sidecarset failed: %s", err.Error())
+		}
+		return sidecarSet
+	}
+	updateSidecarSet := func(sidecarSet *appsv1alpha1.SidecarSet) {
+		if err := fakeClient.Update(context.TODO(), sidecarSet); err != nil {
+			t.Fatalf("update sidecarset failed: %s", err.Error())
+		}
+	}
+
+	// revision 1: test-image:v1, revision 2: test-image:v2, revision 3: test-image:v3
+	sidecarSet := syncSidecarSet()
+	for _, image := range []string{"test-image:v2", "test-image:v3"} {
+		sidecarSet.Spec.Containers[0].Image = image
+		updateSidecarSet(sidecarSet)
+		sidecarSet = syncSidecarSet()
+	}
+
+	cases := []struct {
+		name          string
+		rollbackTo    int64
+		expectedImage string
+	}{
+		{
+			name:          "roll back to the previous revision",
+			rollbackTo:    0,
+			expectedImage: "test-image:v2",
+		},
+		{
+			name:          "roll back to revision 1",
+			rollbackTo:    1,
+			expectedImage: "test-image:v1",
+		},
+		{
+			name:          "roll back to revision not found",
+			rollbackTo:    100,
+			expectedImage: "test-image:v1",
+		},
+	}
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			sidecarSet.Spec.UpdateStrategy.RollbackTo = &appsv1alpha1.SidecarSetRollbackConfig{Revision: cs.rollbackTo}
+			updateSidecarSet(sidecarSet)
+			sidecarSet = syncSidecarSet()
+			if sidecarSet.Spec.UpdateStrategy.RollbackTo != nil {
+				t.Fatalf("expect rollbackTo cleared")
+			}
+			if image := sidecarSet.Spec.Containers[0].Image; image != cs.expectedImage {
+				t.Fatalf("expect image %s, but get %s", cs.expectedImage, image)
+			}
+			// register the rolled back revision
+			sidecarSet = syncSidecarSet()
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package sidecarset
 
 import (
+	"context"
 	"sort"
+	"strings"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
 	"github.com/openkruise/kruise/pkg/control/sidecarcontrol"
@@ -10,8 +12,10 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	intstrutil "k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type Strategy interface {
@@ -25,7 +29,11 @@ type Strategy interface {
 	GetNextUpgradePods(control sidecarcontrol.SidecarControl, pods []*corev1.Pod) []*corev1.Pod
 }
 
-type spreadingStrategy struct{}
+type spreadingStrategy struct {
+	// nodeReader gets the nodes of pods to scatter the pods by topology,
+	// and only the hostname of pods can be known if it is nil.
+	nodeReader client.Reader
+}
 
 var (
 	globalSpreadingStrategy = &spreadingStrategy{}
@@ -35,6 +43,11 @@ func NewStrategy() Strategy {
 	return globalSpreadingStrategy
 }
 
+// NewStrategyWithNodeReader returns the strategy which reads the nodes of pods to scatter them by topology.
+func NewStrategyWithNodeReader(reader client.Reader) Strategy {
+	return &spreadingStrategy{nodeReader: reader}
+}
+
 func (p *spreadingStrategy) GetNextUpgradePods(control sidecarcontrol.SidecarControl, pods []*corev1.Pod) (upgradePods []*corev1.Pod) {
 	sidecarset := control.GetSidecarset()
 	// wait to upgrade pod index
@@ -61,14 +74,29 @@ func (p *spreadingStrategy) GetNextUpgradePods(control sidecarcontrol.SidecarCon
 		return false
 	}
 
+	// If pausedSelector is not nil, check whether the pods is paused to upgrade
+	isPaused := func(pod *corev1.Pod) bool {
+		if strategy.PausedSelector == nil {
+			return false
+		}
+		// if selector failed, always return true
+		selector, err := metav1.LabelSelectorAsSelector(strategy.PausedSelector)
+		if err != nil {
+			klog.Errorf("sidecarSet(%s) paused selector error, err: %v", sidecarset.Name, err)
+			return true
+		}
+		return selector.Matches(labels.Set(pod.Labels))
+	}
+
 	//1. select which pods can be upgraded, the following:
 	//	* pod must be not updated for the latest sidecarSet
 	//	* If selector is not nil, this upgrade will only update the selected pods.
+	//	* If pausedSelector is not nil, this upgrade will not update the selected pods.
 	//  * In kubernetes cluster, when inplace update pod, only fields such as image can be updated for the container.
 	//  * It is to determine whether there are other fields that have been modified for pod.
 	for index, pod := range pods {
 		isUpdated := sidecarcontrol.IsPodSidecarUpdated(sidecarset, pod)
-		if !isUpdated && isSelected(pod) && control.IsSidecarSetUpgradable(pod) {
+		if !isUpdated && isSelected(pod) && !isPaused(pod) && control.IsSidecarSetUpgradable(pod) {
 			waitUpgradedIndexes = append(waitUpgradedIndexes, index)
 		}
 	}
@@ -76,6 +104,9 @@ func (p *spreadingStrategy) GetNextUpgradePods(control sidecarcontrol.SidecarCon
 	klog.V(3).Infof("sidecarSet(%s) matchedPods(%d) waitUpdated(%d)", sidecarset.Name, len(pods), len(waitUpgradedIndexes))
 	//2. sort Pods with default sequence and scatter
 	waitUpgradedIndexes = SortUpdateIndexes(strategy, pods, waitUpgradedIndexes)
+	if len(strategy.TopologyScatterKeys) > 0 {
+		waitUpgradedIndexes = scatterUpdateIndexesByTopology(strategy.TopologyScatterKeys, pods, waitUpgradedIndexes, p.getNode)
+	}
 
 	//3. calculate to be upgraded pods number for the time
 	needToUpgradeCount := calculateUpgradeCount(control, waitUpgradedIndexes, pods)
@@ -112,6 +143,67 @@ func SortUpdateIndexes(strategy appsv1alpha1.SidecarSetUpdateStrategy, pods []*c
 	return waitUpdateIndexes
 }
 
+func (p *spreadingStrategy) getNode(name string) *corev1.Node {
+	if p.nodeReader == nil {
+		return nil
+	}
+	node := &corev1.Node{}
+	if err := p.nodeReader.Get(context.TODO(), types.NamespacedName{Name: name}, node); err != nil {
+		klog.V(4).Infof("failed to get node %s: %v", name, err)
+		return nil
+	}
+	return node
+}
+
+// scatterUpdateIndexesByTopology picks the waitUpdateIndexes from the topology domains of pods in turn,
+// and keeps the order of the pods in the same domain.
+func scatterUpdateIndexesByTopology(keys []string, pods []*corev1.Pod, waitUpdateIndexes []int, getNode func(string) *corev1.Node) []int {
+	nodes := make(map[string]*corev1.Node)
+	getDomain := func(pod *corev1.Pod) string {
+		if pod.Spec.NodeName == "" {
+			return ""
+		}
+		node, ok := nodes[pod.Spec.NodeName]
+		if !ok {
+			node = getNode(pod.Spec.NodeName)
+			nodes[pod.Spec.NodeName] = node
+		}
+		values := make([]string, 0, len(keys))
+		for _, key := range keys {
+			var value string
+			if node != nil {
+				value = node.Labels[key]
+			}
+			if value == "" && key == corev1.LabelHostname {
+				value = pod.Spec.NodeName
+			}
+			values = append(values, value)
+		}
+		return strings.Join(values, ",")
+	}
+
+	var domains []string
+	domainIndexes := make(map[string][]int)
+	for _, idx := range waitUpdateIndexes {
+		domain := getDomain(pods[idx])
+		if _, ok := domainIndexes[domain]; !ok {
+			domains = append(domains, domain)
+		}
+		domainIndexes[domain] = append(domainIndexes[domain], idx)
+	}
+
+	scattered := make([]int, 0, len(waitUpdateIndexes))
+	for len(scattered) < len(waitUpdateIndexes) {
+		for _, domain := range domains {
+			if indexes := domainIndexes[domain]; len(indexes) > 0 {
+				scattered = append(scattered, indexes[0])
+				domainIndexes[domain] = indexes[1:]
+			}
+		}
+	}
+	return scattered
+}
+
 func calculateUpgradeCount(coreControl sidecarcontrol.SidecarControl, waitUpdateIndexes []int, pods []*corev1.Pod) int {
 	totalReplicas := len(pods)
 	sidecarSet := coreControl.GetSidecarset()
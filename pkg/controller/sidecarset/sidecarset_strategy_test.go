@@ -317,6 +317,28 @@ func testGetNextUpgradePods(t *testing.T, factoryPods FactoryPods, factorySideca
 			},
 			exceptNeedUpgradeCount: 30,
 		},
+		{
+			name: "pausedSelector(tenant=critical, count=30) maxUnavailable(int=100), and pods(count=100, upgraded=0, upgradedAndReady=0)",
+			getPods: func() []*corev1.Pod {
+				pods := factoryPods(100, 0, 0)
+				for i := 0; i < 30; i++ {
+					pods[i].Labels["tenant"] = "critical"
+				}
+				return Random(pods)
+			},
+			getSidecarset: func() *appsv1alpha1.SidecarSet {
+				sidecarSet := factorySidecar()
+				sidecarSet.Spec.UpdateStrategy.MaxUnavailable = &intstr.IntOrString{
+					Type:   intstr.Int,
+					IntVal: 100,
+				}
+				sidecarSet.Spec.UpdateStrategy.PausedSelector = &metav1.LabelSelector{
+					MatchLabels: map[string]string{"tenant": "critical"},
+				}
+				return sidecarSet
+			},
+			exceptNeedUpgradeCount: 70,
+		},
 	}
 	strategy := NewStrategy()
 	for _, cs := range cases {
@@ -536,3 +558,59 @@ func testSortNextUpgradePods(t *testing.T, factoryPods FactoryPods, factorySidec
 		})
 	}
 }
+
+func TestScatterUpdateIndexesByTopology(t *testing.T) {
+	nodes := map[string]*corev1.Node{
+		"node-a1": {ObjectMeta: metav1.ObjectMeta{Name: "node-a1", Labels: map[string]string{corev1.LabelTopologyZone: "zone-a"}}},
+		"node-a2": {ObjectMeta: metav1.ObjectMeta{Name: "node-a2", Labels: map[string]string{corev1.LabelTopologyZone: "zone-a"}}},
+		"node-b1": {ObjectMeta: metav1.ObjectMeta{Name: "node-b1", Labels: map[string]string{corev1.LabelTopologyZone: "zone-b"}}},
+	}
+	getNode := func(name string) *corev1.Node {
+		return nodes[name]
+	}
+	newPods := func(nodeNames ...string) []*corev1.Pod {
+		var pods []*corev1.Pod
+		for _, nodeName := range nodeNames {
+			pods = append(pods, &corev1.Pod{Spec: corev1.PodSpec{NodeName: nodeName}})
+		}
+		return pods
+	}
+
+	cases := []struct {
+		name     string
+		keys     []string
+		pods     []*corev1.Pod
+		indexes  []int
+		expected []int
+	}{
+		{
+			name:     "scatter by zone",
+			keys:     []string{corev1.LabelTopologyZone},
+			pods:     newPods("node-a1", "node-a2", "node-a1", "node-b1", "node-b1"),
+			indexes:  []int{0, 1, 2, 3, 4},
+			expected: []int{0, 3, 1, 4, 2},
+		},
+		{
+			name:     "scatter by hostname",
+			keys:     []string{corev1.LabelHostname},
+			pods:     newPods("node-a1", "node-a1", "node-a2", "node-b1", "node-x"),
+			indexes:  []int{1, 0, 2, 3, 4},
+			expected: []int{1, 2, 3, 4, 0},
+		},
+		{
+			name:     "unscheduled pods in the same domain",
+			keys:     []string{corev1.LabelTopologyZone},
+			pods:     newPods("", "", "node-b1"),
+			indexes:  []int{0, 1, 2},
+			expected: []int{0, 2, 1},
+		},
+	}
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			indexes := scatterUpdateIndexesByTopology(cs.keys, cs.pods, cs.indexes, getNode)
+			if !reflect.DeepEqual(indexes, cs.expected) {
+				t.Fatalf("expect indexes %v, but get %v", cs.expected, indexes)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecarset
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/control/sidecarcontrol"
+	utilclient "github.com/openkruise/kruise/pkg/util/client"
+	"github.com/openkruise/kruise/pkg/util/fieldindex"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// SidecarSetUninjectionFinalizer is added to the SidecarSet with uninjectionStrategy,
+// to uninject the sidecars from all the injected pods before SidecarSet is deleted.
+const SidecarSetUninjectionFinalizer = "apps.kruise.io/sidecarset-uninjection"
+
+// syncUninjection uninjects the sidecars from the pods no longer matched by the sidecarSet, or all the injected pods
+// when the sidecarSet is deleting, and maintains the uninjection finalizer of sidecarSet.
+// It returns true if the sidecarSet is deleting and nothing else needs to be done.
+func (p *Processor) syncUninjection(sidecarSet *appsv1alpha1.SidecarSet) (bool, error) {
+	hasFinalizer := controllerutil.ContainsFinalizer(sidecarSet, SidecarSetUninjectionFinalizer)
+	if sidecarSet.DeletionTimestamp != nil {
+		if !hasFinalizer {
+			return false, nil
+		}
+		if sidecarSet.Spec.UninjectionStrategy != nil {
+			if err := p.uninjectPods(sidecarSet); err != nil {
+				return true, err
+			}
+		}
+		return true, p.updateUninjectionFinalizer(sidecarSet, false)
+	}
+
+	if sidecarSet.Spec.UninjectionStrategy == nil {
+		if hasFinalizer {
+			return false, p.updateUninjectionFinalizer(sidecarSet, false)
+		}
+		return false, nil
+	}
+	if !hasFinalizer {
+		if err := p.updateUninjectionFinalizer(sidecarSet, true); err != nil {
+			return false, err
+		}
+	}
+	return false, p.uninjectPods(sidecarSet)
+}
+
+// uninjectPods uninjects the sidecars from the injected pods that no longer match the sidecarSet,
+// or all the injected pods if the sidecarSet is deleting.
+func (p *Processor) uninjectPods(sidecarSet *appsv1alpha1.SidecarSet) error {
+	// pods in the namespaces that no longer match the namespaceSelector should also be uninjected,
+	// so list them by the injected sidecarSets regardless of the selectors
+	podList := &corev1.PodList{}
+	listOpts := &client.ListOptions{
+		Namespace:     sidecarSet.Spec.Namespace,
+		FieldSelector: fields.SelectorFromSet(fields.Set{fieldindex.IndexNameForInjectedSidecarSet: sidecarSet.Name}),
+	}
+	if err := p.Client.List(context.TODO(), podList, listOpts, utilclient.DisableDeepCopy); err != nil {
+		return fmt.Errorf("sidecarSet list injected pods error: %v", err)
+	}
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if !sidecarcontrol.IsActivePod(pod) || !sidecarcontrol.IsPodInjectedSidecarSet(pod, sidecarSet) {
+			continue
+		}
+		if sidecarSet.DeletionTimestamp == nil {
+			matched, err := sidecarcontrol.PodMatchedSidecarSet(p.Client, pod, *sidecarSet)
+			if err != nil {
+				return err
+			} else if matched {
+				continue
+			}
+		}
+		if err := p.uninjectPod(sidecarSet, pod); err != nil {
+			p.recorder.Eventf(pod, corev1.EventTypeWarning, "UninjectSidecarFailed",
+				"failed to uninject sidecars of sidecarSet %s: %s", sidecarSet.Name, err.Error())
+			return err
+		}
+		p.recorder.Eventf(pod, corev1.EventTypeNormal, "UninjectSidecarSucceed",
+			"uninject sidecars of sidecarSet %s successfully", sidecarSet.Name)
+	}
+	return nil
+}
+
+func (p *Processor) uninjectPod(sidecarSet *appsv1alpha1.SidecarSet, pod *corev1.Pod) error {
+	podClone := pod.DeepCopy()
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		uninjectPodSidecarSet(sidecarSet, podClone)
+		updateErr := p.Client.Update(context.TODO(), podClone)
+		if updateErr == nil || errors.IsNotFound(updateErr) {
+			return nil
+		}
+
+		key := types.NamespacedName{
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+		}
+		if err := p.Client.Get(context.TODO(), key, podClone); err != nil {
+			klog.Errorf("error getting updated pod(%s/%s) from client", pod.Namespace, pod.Name)
+		}
+		return updateErr
+	})
+}
+
+// uninjectPodSidecarSet replaces the sidecar containers of sidecarSet with the empty image,
+// and removes the sidecarSet from the annotations of pod.
+func uninjectPodSidecarSet(sidecarSet *appsv1alpha1.SidecarSet, pod *corev1.Pod) {
+	sidecarContainers := sidecarcontrol.GetSidecarContainersInPod(sidecarSet)
+	for i := range pod.Spec.Containers {
+		if sidecarContainers.Has(pod.Spec.Containers[i].Name) {
+			pod.Spec.Containers[i].Image = sidecarSet.Spec.UninjectionStrategy.EmptyImage
+		}
+	}
+
+	var sidecarSetNames []string
+	for _, name := range strings.Split(pod.Annotations[sidecarcontrol.SidecarSetListAnnotation], ",") {
+		if name != "" && name != sidecarSet.Name {
+			sidecarSetNames = append(sidecarSetNames, name)
+		}
+	}
+	setPodAnnotation(pod, sidecarcontrol.SidecarSetListAnnotation, strings.Join(sidecarSetNames, ","))
+	for _, key := range []string{sidecarcontrol.SidecarSetHashAnnotation, sidecarcontrol.SidecarSetHashWithoutImageAnnotation,
+		sidecarcontrol.SidecarsetInplaceUpdateStateKey} {
+		// format: sidecarset.name -> value
+		values := make(map[string]json.RawMessage)
+		if err := json.Unmarshal([]byte(pod.Annotations[key]), &values); err != nil {
+			continue
+		}
+		delete(values, sidecarSet.Name)
+		value := ""
+		if len(values) > 0 {
+			by, _ := json.Marshal(values)
+			value = string(by)
+		}
+		setPodAnnotation(pod, key, value)
+	}
+}
+
+func setPodAnnotation(pod *corev1.Pod, key, value string) {
+	if value == "" {
+		delete(pod.Annotations, key)
+		return
+	}
+	pod.Annotations[key] = value
+}
+
+func (p *Processor) updateUninjectionFinalizer(sidecarSet *appsv1alpha1.SidecarSet, add bool) error {
+	sidecarSetClone := sidecarSet.DeepCopy()
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if add {
+			controllerutil.AddFinalizer(sidecarSetClone, SidecarSetUninjectionFinalizer)
+		} else {
+			controllerutil.RemoveFinalizer(sidecarSetClone, SidecarSetUninjectionFinalizer)
+		}
+		updateErr := p.Client.Update(context.TODO(), sidecarSetClone)
+		if updateErr == nil {
+			return nil
+		}
+
+		key := types.NamespacedName{
+			Name: sidecarSetClone.Name,
+		}
+		if err := p.Client.Get(context.TODO(), key, sidecarSetClone); err != nil {
+			klog.Errorf("error getting updated sidecarSet %s from client", sidecarSetClone.Name)
+		}
+		return updateErr
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update finalizer of sidecarSet %s: %v", sidecarSet.Name, err)
+	}
+	sidecarSet.Finalizers = sidecarSetClone.Finalizers
+	sidecarSet.ResourceVersion = sidecarSetClone.ResourceVersion
+	return nil
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecarset
+
+import (
+	"testing"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/control/sidecarcontrol"
+	"github.com/openkruise/kruise/pkg/util/expectations"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+func TestSyncUninjection(t *testing.T) {
+	sidecarSet := sidecarSetDemo.DeepCopy()
+	sidecarSet.Spec.UninjectionStrategy = &appsv1alpha1.SidecarSetUninjectionStrategy{EmptyImage: "empty:v1"}
+	matchedPod := podDemo.DeepCopy()
+	matchedPod.Annotations[sidecarcontrol.SidecarSetListAnnotation] = "test-sidecarset,other-sidecarset"
+	unmatchedPod := matchedPod.DeepCopy()
+	unmatchedPod.Name = "test-pod-2"
+	unmatchedPod.Labels = map[string]string{"app": "other"}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(sidecarSet, matchedPod, unmatchedPod).Build()
+	exps := expectations.NewUpdateExpectations(sidecarcontrol.RevisionAdapterImpl)
+	processor := NewSidecarSetProcessor(fakeClient, exps, record.NewFakeRecorder(10))
+
+	assertUninjected := func(pod *corev1.Pod, expected bool) {
+		podOutput, err := getLatestPod(fakeClient, pod)
+		if err != nil {
+			t.Fatalf("get latest pod(%s) failed: %s", pod.Name, err.Error())
+		}
+		if uninjected := isSidecarImageUpdated(podOutput, "test-sidecar", "empty:v1"); uninjected != expected {
+			t.Fatalf("expect pod(%s) uninjected %v, but get %v", pod.Name, expected, uninjected)
+		}
+		if sidecarcontrol.IsPodInjectedSidecarSet(podOutput, sidecarSet) == expected {
+			t.Fatalf("expect pod(%s) injected sidecarSet %v", pod.Name, !expected)
+		}
+		if !expected {
+			return
+		}
+		if podOutput.Annotations[sidecarcontrol.SidecarSetListAnnotation] != "other-sidecarset" {
+			t.Fatalf("expect pod(%s) other sidecarSets left, but get %s", pod.Name, podOutput.Annotations[sidecarcontrol.SidecarSetListAnnotation])
+		}
+		if _, ok := podOutput.Annotations[sidecarcontrol.SidecarSetHashAnnotation]; ok {
+			t.Fatalf("expect pod(%s) sidecarSet hash removed", pod.Name)
+		}
+	}
+
+	// uninject the pod no longer matched
+	if deleting, err := processor.syncUninjection(sidecarSet); err != nil || deleting {
+		t.Fatalf("sync uninjection failed: %v, deleting: %v", err, deleting)
+	}
+	if !controllerutil.ContainsFinalizer(sidecarSet, SidecarSetUninjectionFinalizer) {
+		t.Fatalf("expect uninjection finalizer added")
+	}
+	assertUninjected(matchedPod, false)
+	assertUninjected(unmatchedPod, true)
+
+	// uninject all the pods when sidecarSet is deleting
+	now := metav1.Now()
+	sidecarSet.DeletionTimestamp = &now
+	if deleting, err := processor.syncUninjection(sidecarSet); err != nil || !deleting {
+		t.Fatalf("sync uninjection failed: %v, deleting: %v", err, deleting)
+	}
+	if controllerutil.ContainsFinalizer(sidecarSet, SidecarSetUninjectionFinalizer) {
+		t.Fatalf("expect uninjection finalizer removed")
+	}
+	assertUninjected(matchedPod, true)
+}
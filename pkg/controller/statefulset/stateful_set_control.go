@@ -28,7 +28,6 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	intstrutil "k8s.io/apimachinery/pkg/util/intstr"
-	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/controller/history"
@@ -37,11 +36,13 @@ import (
 	appspub "github.com/openkruise/kruise/apis/apps/pub"
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
 	appsv1beta1 "github.com/openkruise/kruise/apis/apps/v1beta1"
+	"github.com/openkruise/kruise/pkg/control/pubcontrol"
 	"github.com/openkruise/kruise/pkg/features"
 	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
 	imagejobutilfunc "github.com/openkruise/kruise/pkg/util/imagejob/utilfunction"
 	"github.com/openkruise/kruise/pkg/util/inplaceupdate"
 	"github.com/openkruise/kruise/pkg/util/lifecycle"
+	"github.com/openkruise/kruise/pkg/util/revisiondiff"
 )
 
 // StatefulSetControlInterface implements the control logic for updating StatefulSets and their children Pods. It is implemented
@@ -134,11 +135,16 @@ func (ssc *defaultStatefulSetControl) performUpdate(
 
 	// perform the main update function and get the status
 	currentStatus, getStatusErr := ssc.updateStatefulSet(set, currentRevision, updateRevision, collisionCount, pods, revisions)
+	// expand the existing pvcs to volumeClaimTemplates replica by replica
+	expandErr := ssc.syncVolumeClaimExpansion(set, currentStatus, pods)
 	updateStatusErr := ssc.updateStatefulSetStatus(set, currentStatus)
 
 	if getStatusErr != nil {
 		return currentRevision, updateRevision, getStatusErr
 	}
+	if expandErr != nil {
+		return currentRevision, updateRevision, expandErr
+	}
 	if updateStatusErr != nil {
 		return currentRevision, updateRevision, updateStatusErr
 	}
@@ -358,17 +364,20 @@ func (ssc *defaultStatefulSetControl) updateStatefulSet(
 	status.CollisionCount = utilpointer.Int32Ptr(collisionCount)
 	status.LabelSelector = selector.String()
 
-	reserveOrdinals := sets.NewInt(set.Spec.ReserveOrdinals...)
-	replicaCount := 0
-	for realReplicaCount := 0; realReplicaCount < int(*set.Spec.Replicas); replicaCount++ {
-		if reserveOrdinals.Has(replicaCount) {
-			continue
-		}
-		realReplicaCount++
+	// reuse the diff in status if the revisions have not changed
+	if set.Status.CurrentRevision == currentRevision.Name && set.Status.UpdateRevision == updateRevision.Name && set.Status.RevisionDiff != nil {
+		status.RevisionDiff = set.Status.RevisionDiff.DeepCopy()
+	} else if status.RevisionDiff, err = revisiondiff.Calculate(currentRevision, updateRevision); err != nil {
+		klog.Warningf("Failed to calculate revision diff for StatefulSet %s: %v", getStatefulSetKey(set), err)
 	}
-	// slice that will contain all Pods such that 0 <= getOrdinal(pod) < replicaCount and not in reserveOrdinals
+
+	reserveOrdinals := getReservedOrdinals(set)
+	startOrdinal := getStartOrdinal(set)
+	replicaCount := getEndOrdinal(set, reserveOrdinals)
+	// slice that will contain all Pods such that startOrdinal <= getOrdinal(pod) < replicaCount and not in reserveOrdinals,
+	// which is indexed by ordinal and the indexes less than startOrdinal are always nil
 	replicas := make([]*v1.Pod, replicaCount)
-	// slice that will contain all Pods such that replicaCount <= getOrdinal(pod) or in reserveOrdinals
+	// slice that will contain all Pods such that getOrdinal(pod) < startOrdinal, replicaCount <= getOrdinal(pod) or in reserveOrdinals
 	condemned := make([]*v1.Pod, 0, len(pods))
 	unhealthy := 0
 	firstUnhealthyOrdinal := math.MaxInt32
@@ -413,30 +422,33 @@ func (ssc *defaultStatefulSetControl) updateStatefulSet(
 			}
 		}
 
-		if ord := getOrdinal(pods[i]); 0 <= ord && ord < replicaCount && !reserveOrdinals.Has(ord) {
+		if ord := getOrdinal(pods[i]); startOrdinal <= ord && ord < replicaCount && !reserveOrdinals.Has(ord) {
 			// if the ordinal of the pod is within the range of the current number of replicas and not in reserveOrdinals,
 			// insert it at the indirection of its ordinal
 			replicas[ord] = pods[i]
 
-		} else if ord >= replicaCount || reserveOrdinals.Has(ord) {
-			// if the ordinal is greater than the number of replicas or in reserveOrdinals,
+		} else if ord >= 0 {
+			// if the ordinal is less than the start ordinal, greater than the number of replicas or in reserveOrdinals,
 			// add it to the condemned list
 			condemned = append(condemned, pods[i])
 		}
 		// If the ordinal could not be parsed (ord < 0), ignore the Pod.
 	}
 
-	// for any empty indices in the sequence [0,set.Spec.Replicas) create a new Pod at the correct revision
-	for ord := 0; ord < replicaCount; ord++ {
+	// for any empty indices in the sequence [startOrdinal,replicaCount) create a new Pod at the correct revision
+	for ord := startOrdinal; ord < replicaCount; ord++ {
 		if reserveOrdinals.Has(ord) {
 			continue
 		}
 		if replicas[ord] == nil {
-			replicas[ord] = newVersionedStatefulSetPod(
+			if replicas[ord], err = newVersionedStatefulSetPod(
 				currentSet,
 				updateSet,
 				currentRevision.Name,
-				updateRevision.Name, ord, replicas)
+				updateRevision.Name, ord, replicas); err != nil {
+				ssc.recorder.Event(set, v1.EventTypeWarning, "FailedApplyOrdinalOverrides", err.Error())
+				return &status, err
+			}
 		}
 	}
 
@@ -488,6 +500,9 @@ func (ssc *defaultStatefulSetControl) updateStatefulSet(
 		}
 		// delete and recreate failed pods
 		if isFailed(replicas[i]) {
+			if !allowPodOperation(set) {
+				return &status, nil
+			}
 			ssc.recorder.Eventf(set, v1.EventTypeWarning, "RecreatingFailedPod",
 				"StatefulSet %s/%s is recreating failed Pod %s",
 				set.Namespace,
@@ -503,12 +518,15 @@ func (ssc *defaultStatefulSetControl) updateStatefulSet(
 				status.UpdatedReplicas--
 			}
 			status.Replicas--
-			replicas[i] = newVersionedStatefulSetPod(
+			if replicas[i], err = newVersionedStatefulSetPod(
 				currentSet,
 				updateSet,
 				currentRevision.Name,
 				updateRevision.Name,
-				i, replicas)
+				i, replicas); err != nil {
+				ssc.recorder.Event(set, v1.EventTypeWarning, "FailedApplyOrdinalOverrides", err.Error())
+				return &status, err
+			}
 		}
 		// If we find a Pod that has not been created we create the Pod
 		if !isCreated(replicas[i]) {
@@ -521,6 +539,9 @@ func (ssc *defaultStatefulSetControl) updateStatefulSet(
 				}
 			}
 
+			if !allowPodOperation(set) {
+				return &status, nil
+			}
 			lifecycle.SetPodLifecycle(appspub.LifecycleStateNormal)(replicas[i])
 			if err := ssc.podControl.CreateStatefulPod(set, replicas[i]); err != nil {
 				msg := fmt.Sprintf("StatefulPodControl failed to create Pod error: %s", err)
@@ -659,6 +680,9 @@ func (ssc *defaultStatefulSetControl) updateStatefulSet(
 			set.Name,
 			condemned[target].Name)
 
+		if !allowPodOperation(set) {
+			return &status, nil
+		}
 		modified, err := ssc.deletePod(set, condemned[target])
 		if err != nil || modified {
 			return &status, err
@@ -722,20 +746,37 @@ func (ssc *defaultStatefulSetControl) updateStatefulSet(
 	}
 
 	var unavailablePods []string
-	updateIndexes := sortPodsToUpdate(set.Spec.UpdateStrategy.RollingUpdate, updateRevision.Name, *set.Spec.Replicas, replicas)
+	updateIndexes := sortPodsToUpdate(set.Spec.UpdateStrategy.RollingUpdate, updateRevision.Name, *set.Spec.Replicas, startOrdinal, replicas)
 	klog.V(3).Infof("Prepare to update pods indexes %v for StatefulSet %s", updateIndexes, getStatefulSetKey(set))
+	// for unordered update, maxUnavailable limits the unavailable Pods among all replicas, not only the Pods to update
+	if set.Spec.UpdateStrategy.RollingUpdate != nil && set.Spec.UpdateStrategy.RollingUpdate.UnorderedUpdate != nil {
+		unavailablePods = getUnavailablePodsNotToUpdate(replicas, updateIndexes, minReadySeconds)
+	}
 	minWaitTime := appsv1beta1.MaxMinReadySeconds * time.Second
 	// update pods in sequence
 	for _, target := range updateIndexes {
 
 		// delete the Pod if it is not already terminating and does not match the update revision.
 		if getPodRevision(replicas[target]) != updateRevision.Name && !isTerminating(replicas[target]) {
-			// todo validate in-place for pub
+			if len(unavailablePods) >= maxUnavailable {
+				klog.V(4).Infof("StatefulSet %s/%s is waiting for unavailable Pods %v before updating Pod %s",
+					set.Namespace, set.Name, unavailablePods, replicas[target].Name)
+				return &status, nil
+			}
+			if allowed, err := ssc.isPodUpdateAllowedByPub(set, replicas[target]); err != nil {
+				return &status, err
+			} else if !allowed {
+				durationStore.Push(getStatefulSetKey(set), time.Second)
+				return &status, nil
+			}
 			inplacing, inplaceUpdateErr := ssc.inPlaceUpdatePod(set, replicas[target], updateRevision, revisions)
 			if inplaceUpdateErr != nil {
 				return &status, inplaceUpdateErr
 			}
 			if !inplacing {
+				if !allowPodOperation(set) {
+					return &status, nil
+				}
 				klog.V(2).Infof("StatefulSet %s/%s terminating Pod %s for update",
 					set.Namespace,
 					set.Name,
@@ -788,6 +829,26 @@ func (ssc *defaultStatefulSetControl) updateStatefulSet(
 	return &status, nil
 }
 
+// isPodUpdateAllowedByPub returns whether the Pod can be updated now according to its PodUnavailableBudget.
+// It is a dry run, and the budget will be decremented by PUB webhook when the Pod is actually updated.
+func (ssc *defaultStatefulSetControl) isPodUpdateAllowedByPub(set *appsv1beta1.StatefulSet, pod *v1.Pod) (bool, error) {
+	if !utilfeature.DefaultFeatureGate.Enabled(features.PodUnavailableBudgetUpdateGate) || sigsruntimeClient == nil {
+		return true, nil
+	}
+	pubControl := pubcontrol.NewPubControl(sigsruntimeClient)
+	pub, err := pubControl.GetPubForPod(pod)
+	if err != nil || pub == nil || pubcontrol.IsPodNoProtected(pod, pubcontrol.UpdateOperation) {
+		return true, err
+	}
+	allowed, reason, err := pubcontrol.PodUnavailableBudgetValidatePod(sigsruntimeClient, pubControl, pub, pod, pubcontrol.UpdateOperation, true)
+	if err != nil {
+		return false, err
+	} else if !allowed {
+		pubcontrol.RecordPubRejection(ssc.recorder, pub, pod, pubcontrol.UpdateOperation, fmt.Sprintf("StatefulSet %s", set.Name), reason)
+	}
+	return allowed, nil
+}
+
 func (ssc *defaultStatefulSetControl) deletePod(set *appsv1beta1.StatefulSet, pod *v1.Pod) (bool, error) {
 	if set.Spec.Lifecycle != nil && lifecycle.IsPodHooked(set.Spec.Lifecycle.PreDelete, pod) {
 		if updated, _, err := ssc.lifecycleControl.UpdatePodLifecycle(pod, appspub.LifecycleStatePreparingDelete); err != nil {
@@ -823,6 +884,24 @@ func (ssc *defaultStatefulSetControl) refreshPodState(set *appsv1beta1.StatefulS
 		return false, 0, res.RefreshErr
 	}
 
+	// back off to recreate the pod if the node can not satisfy its in-place resize
+	if lifecycle.GetPodLifecycleState(pod) == appspub.LifecycleStateUpdating && inplaceupdate.IsPodResizeInfeasible(pod) &&
+		set.Spec.UpdateStrategy.RollingUpdate.PodUpdatePolicy != appsv1beta1.InPlaceOnlyPodUpdateStrategyType && !isTerminating(pod) {
+		if !allowPodOperation(set) {
+			return true, res.DelayDuration, nil
+		}
+		klog.Warningf("AdvancedStatefulSet %s find pod %s resize infeasible, so it will back off to ReCreate",
+			getStatefulSetKey(set), pod.Name)
+		if err := ssc.podControl.DeleteStatefulPod(set, pod); err != nil {
+			ssc.recorder.Eventf(set, v1.EventTypeWarning, "FailedUpdatePodReCreate",
+				"failed to delete pod %s for resize infeasible: %v", pod.Name, err)
+			return false, 0, err
+		}
+		ssc.recorder.Eventf(set, v1.EventTypeWarning, "ResizeInfeasible",
+			"pod %s can not be resized in-place on its node, delete it to recreate", pod.Name)
+		return true, res.DelayDuration, nil
+	}
+
 	var state appspub.LifecycleStateType
 	switch lifecycle.GetPodLifecycleState(pod) {
 	case appspub.LifecycleStateUpdating:
@@ -879,7 +958,8 @@ func (ssc *defaultStatefulSetControl) inPlaceUpdatePod(
 		opts.GracePeriodSeconds = set.Spec.UpdateStrategy.RollingUpdate.InPlaceUpdateStrategy.GracePeriodSeconds
 	}
 
-	if ssc.inplaceControl.CanUpdateInPlace(oldRevision, updateRevision, opts) {
+	if ssc.inplaceControl.CanUpdateInPlace(oldRevision, updateRevision, opts) &&
+		!isOrdinalOverrideChanged(set, oldRevision, updateRevision, getOrdinal(pod)) {
 		state := lifecycle.GetPodLifecycleState(pod)
 		switch state {
 		case "", appspub.LifecycleStateNormal:
@@ -954,11 +1034,18 @@ func (ssc *defaultStatefulSetControl) updateStatefulSetStatus(
 		return nil
 	}
 
+	// batch the updates of replicas counters for large StatefulSets
+	if delay := statusUpdateTimes.shouldDelay(set, status); delay > 0 {
+		durationStore.Push(getStatefulSetKey(set), delay)
+		return nil
+	}
+
 	// copy set and update its status
 	set = set.DeepCopy()
 	if err := ssc.statusUpdater.UpdateStatefulSetStatus(set, status); err != nil {
 		return err
 	}
+	statusUpdateTimes.observe(getStatefulSetKey(set))
 
 	return nil
 }
@@ -31,6 +31,7 @@ import (
 	"github.com/pkg/errors"
 	apps "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -2800,6 +2801,7 @@ type fakeObjectManager struct {
 	claimsIndexer    cache.Indexer
 	setsIndexer      cache.Indexer
 	revisionsIndexer cache.Indexer
+	classesIndexer   cache.Indexer
 	createPodTracker requestTracker
 	updatePodTracker requestTracker
 	deletePodTracker requestTracker
@@ -2810,6 +2812,7 @@ func newFakeObjectManager(informerFactory informers.SharedInformerFactory, kruis
 	claimInformer := informerFactory.Core().V1().PersistentVolumeClaims()
 	revisionInformer := informerFactory.Apps().V1().ControllerRevisions()
 	setInformer := kruiseInformerFactory.Apps().V1beta1().StatefulSets()
+	classInformer := informerFactory.Storage().V1().StorageClasses()
 
 	return &fakeObjectManager{
 		podInformer.Lister(),
@@ -2819,6 +2822,7 @@ func newFakeObjectManager(informerFactory informers.SharedInformerFactory, kruis
 		claimInformer.Informer().GetIndexer(),
 		setInformer.Informer().GetIndexer(),
 		revisionInformer.Informer().GetIndexer(),
+		classInformer.Informer().GetIndexer(),
 		requestTracker{0, nil, 0},
 		requestTracker{0, nil, 0},
 		requestTracker{0, nil, 0}}
@@ -2879,6 +2883,16 @@ func (om *fakeObjectManager) UpdateClaim(claim *v1.PersistentVolumeClaim) error
 	return nil
 }
 
+func (om *fakeObjectManager) GetStorageClass(name string) (*storagev1.StorageClass, error) {
+	obj, found, err := om.classesIndexer.GetByKey(name)
+	if err != nil {
+		return nil, err
+	} else if !found {
+		return nil, apierrors.NewNotFound(storagev1.Resource("storageclasses"), name)
+	}
+	return obj.(*storagev1.StorageClass), nil
+}
+
 func (om *fakeObjectManager) SetCreateStatefulPodError(err error, after int) {
 	om.createPodTracker.err = err
 	om.createPodTracker.after = after
@@ -3578,6 +3592,151 @@ func TestScaleUpWithMaxUnavailable(t *testing.T) {
 	}
 }
 
+func TestScaleStatefulSetWithStartOrdinal(t *testing.T) {
+	set := newStatefulSet(3)
+	set.Spec.Ordinals = &appsv1beta1.StatefulSetOrdinals{Start: 2}
+
+	client := fake.NewSimpleClientset()
+	kruiseClient := kruisefake.NewSimpleClientset(set)
+	spc, _, ssc, stop := setupController(client, kruiseClient)
+	defer close(stop)
+
+	noInvariants := func(set *appsv1beta1.StatefulSet, om *fakeObjectManager) error { return nil }
+	if err := scaleUpStatefulSetControl(set, ssc, spc, noInvariants); err != nil {
+		t.Fatalf("Failed to scale up StatefulSet: %v", err)
+	}
+	assertOrdinals := func(expected []int) {
+		selector, _ := metav1.LabelSelectorAsSelector(set.Spec.Selector)
+		pods, err := spc.podsLister.Pods(set.Namespace).List(selector)
+		if err != nil {
+			t.Fatalf("Failed to list pods: %v", err)
+		}
+		sort.Sort(ascendingOrdinal(pods))
+		var ordinals []int
+		for _, pod := range pods {
+			ordinals = append(ordinals, getOrdinal(pod))
+		}
+		if !reflect.DeepEqual(ordinals, expected) {
+			t.Fatalf("Expected pod ordinals %v, got %v", expected, ordinals)
+		}
+	}
+	assertOrdinals([]int{2, 3, 4})
+
+	// move the start ordinal, the pod with ordinal 2 is replaced by 5
+	set, err := spc.setsLister.StatefulSets(set.Namespace).Get(set.Name)
+	if err != nil {
+		t.Fatalf("Error getting updated StatefulSet: %v", err)
+	}
+	set.Spec.Ordinals.Start = 3
+	for i := 0; i < 5; i++ {
+		selector, _ := metav1.LabelSelectorAsSelector(set.Spec.Selector)
+		pods, err := spc.podsLister.Pods(set.Namespace).List(selector)
+		if err != nil {
+			t.Fatalf("Failed to list pods: %v", err)
+		}
+		if err := ssc.UpdateStatefulSet(set, pods); err != nil {
+			t.Fatalf("Failed to update StatefulSet: %v", err)
+		}
+		pods, _ = spc.podsLister.Pods(set.Namespace).List(selector)
+		for j := range pods {
+			spc.setPodRunning(set, j)
+			spc.setPodReady(set, j)
+		}
+	}
+	assertOrdinals([]int{3, 4, 5})
+}
+
+func TestStatefulSetRevisionDiff(t *testing.T) {
+	set := newStatefulSet(3)
+	set.Spec.UpdateStrategy.RollingUpdate = &appsv1beta1.RollingUpdateStatefulSetStrategy{Partition: utilpointer.Int32Ptr(3)}
+
+	client := fake.NewSimpleClientset()
+	kruiseClient := kruisefake.NewSimpleClientset(set)
+	spc, _, ssc, stop := setupController(client, kruiseClient)
+	defer close(stop)
+	if err := scaleUpStatefulSetControl(set, ssc, spc, assertMonotonicInvariants); err != nil {
+		t.Fatalf("Failed to scale up StatefulSet: %v", err)
+	}
+	set, err := spc.setsLister.StatefulSets(set.Namespace).Get(set.Name)
+	if err != nil {
+		t.Fatalf("Error getting updated StatefulSet: %v", err)
+	}
+	if set.Status.RevisionDiff != nil {
+		t.Fatalf("Expected no revision diff before update, got %+v", set.Status.RevisionDiff)
+	}
+
+	set.Spec.Template.Spec.Containers[0].Image = "foo"
+	selector, _ := metav1.LabelSelectorAsSelector(set.Spec.Selector)
+	pods, err := spc.podsLister.Pods(set.Namespace).List(selector)
+	if err != nil {
+		t.Fatalf("Failed to list pods: %v", err)
+	}
+	if err := ssc.UpdateStatefulSet(set, pods); err != nil {
+		t.Fatalf("Failed to update StatefulSet: %v", err)
+	}
+	set, err = spc.setsLister.StatefulSets(set.Namespace).Get(set.Name)
+	if err != nil {
+		t.Fatalf("Error getting updated StatefulSet: %v", err)
+	}
+	expectedFields := []string{"spec.template.spec.containers[nginx].image"}
+	if set.Status.RevisionDiff == nil || !reflect.DeepEqual(set.Status.RevisionDiff.ChangedFields, expectedFields) {
+		t.Fatalf("Expected revision diff %v, got %+v", expectedFields, set.Status.RevisionDiff)
+	}
+}
+
+func TestRefreshPodStateResizeInfeasible(t *testing.T) {
+	cases := []struct {
+		name            string
+		podUpdatePolicy appsv1beta1.PodUpdateStrategyType
+		reason          string
+		expectDeleted   bool
+	}{
+		{
+			name:            "recreate pod for infeasible resize",
+			podUpdatePolicy: appsv1beta1.InPlaceIfPossiblePodUpdateStrategyType,
+			reason:          inplaceupdate.PodReasonInfeasible,
+			expectDeleted:   true,
+		},
+		{
+			name:            "keep pod for deferred resize",
+			podUpdatePolicy: appsv1beta1.InPlaceIfPossiblePodUpdateStrategyType,
+			reason:          "Deferred",
+		},
+		{
+			name:            "keep pod for InPlaceOnly",
+			podUpdatePolicy: appsv1beta1.InPlaceOnlyPodUpdateStrategyType,
+			reason:          inplaceupdate.PodReasonInfeasible,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			set := newStatefulSet(1)
+			set.Spec.UpdateStrategy.RollingUpdate = &appsv1beta1.RollingUpdateStatefulSetStrategy{PodUpdatePolicy: tc.podUpdatePolicy}
+
+			client := fake.NewSimpleClientset()
+			kruiseClient := kruisefake.NewSimpleClientset(set)
+			spc, _, ssc, stop := setupController(client, kruiseClient)
+			defer close(stop)
+
+			pod := newStatefulSetPod(set, 0)
+			pod.Labels[appspub.LifecycleStateKey] = string(appspub.LifecycleStateUpdating)
+			pod.Status.Conditions = []v1.PodCondition{{Type: inplaceupdate.PodResizePending, Status: v1.ConditionTrue, Reason: tc.reason}}
+			if err := spc.CreatePod(pod); err != nil {
+				t.Fatalf("Failed to create pod: %v", err)
+			}
+
+			if _, _, err := ssc.(*defaultStatefulSetControl).refreshPodState(set, pod); err != nil {
+				t.Fatalf("Failed to refresh pod state: %v", err)
+			}
+			_, err := spc.podsLister.Pods(pod.Namespace).Get(pod.Name)
+			if deleted := apierrors.IsNotFound(err); deleted != tc.expectDeleted {
+				t.Fatalf("Expected pod deleted %v, got %v", tc.expectDeleted, err)
+			}
+		})
+	}
+}
+
 func isOrHasInternalError(err error) bool {
 	agg, ok := err.(utilerrors.Aggregate)
 	return !ok && !apierrors.IsInternalError(err) || ok && len(agg.Errors()) > 0 && !apierrors.IsInternalError(agg.Errors()[0])
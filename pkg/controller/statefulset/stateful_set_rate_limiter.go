@@ -0,0 +1,136 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statefulset
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+
+	appsv1beta1 "github.com/openkruise/kruise/apis/apps/v1beta1"
+)
+
+var (
+	// default rate limit of Pod creations and deletions for each StatefulSet, 0 means no limit
+	podOperationQPS   float64
+	podOperationBurst int
+	// min interval between status updates which only change the replicas counters, 0 means no batching
+	statusUpdateInterval time.Duration
+
+	podOperationLimiters = &podOperationLimiterStore{limiters: make(map[string]*podOperationLimiter)}
+	statusUpdateTimes    = &statusUpdateTimeStore{times: make(map[string]time.Time)}
+)
+
+type podOperationLimiter struct {
+	qps     float64
+	burst   int
+	limiter *rate.Limiter
+}
+
+type podOperationLimiterStore struct {
+	sync.Mutex
+	limiters map[string]*podOperationLimiter
+}
+
+// get returns the limiter for the StatefulSet, or nil if Pod operations are not limited.
+func (s *podOperationLimiterStore) get(set *appsv1beta1.StatefulSet) *rate.Limiter {
+	qps, burst := podOperationQPS, podOperationBurst
+	if set.Spec.ScaleStrategy != nil && set.Spec.ScaleStrategy.PodOperationRateLimit != nil {
+		qps, burst = float64(set.Spec.ScaleStrategy.PodOperationRateLimit.QPS), int(set.Spec.ScaleStrategy.PodOperationRateLimit.Burst)
+	}
+
+	key := getStatefulSetKey(set)
+	s.Lock()
+	defer s.Unlock()
+	if qps <= 0 {
+		delete(s.limiters, key)
+		return nil
+	}
+	if burst <= 0 {
+		burst = int(math.Ceil(qps))
+	}
+	l, ok := s.limiters[key]
+	if !ok || l.qps != qps || l.burst != burst {
+		l = &podOperationLimiter{qps: qps, burst: burst, limiter: rate.NewLimiter(rate.Limit(qps), burst)}
+		s.limiters[key] = l
+	}
+	return l.limiter
+}
+
+func (s *podOperationLimiterStore) delete(key string) {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.limiters, key)
+}
+
+// allowPodOperation returns whether a Pod creation or deletion of the StatefulSet is allowed now.
+// If not, the StatefulSet will be requeued after the rate limit delay.
+func allowPodOperation(set *appsv1beta1.StatefulSet) bool {
+	limiter := podOperationLimiters.get(set)
+	if limiter == nil || limiter.Allow() {
+		return true
+	}
+	r := limiter.Reserve()
+	delay := r.Delay()
+	r.Cancel()
+	durationStore.Push(getStatefulSetKey(set), delay)
+	return false
+}
+
+type statusUpdateTimeStore struct {
+	sync.Mutex
+	times map[string]time.Time
+}
+
+// shouldDelay returns the duration to delay the status update of the StatefulSet, or 0 if it should be updated now.
+// Only the updates that just change the replicas counters can be delayed.
+func (s *statusUpdateTimeStore) shouldDelay(set *appsv1beta1.StatefulSet, status *appsv1beta1.StatefulSetStatus) time.Duration {
+	if statusUpdateInterval <= 0 ||
+		status.ObservedGeneration != set.Status.ObservedGeneration ||
+		status.CurrentRevision != set.Status.CurrentRevision ||
+		status.UpdateRevision != set.Status.UpdateRevision ||
+		status.LabelSelector != set.Status.LabelSelector ||
+		!apiequality.Semantic.DeepEqual(status.VolumeClaimTemplates, set.Status.VolumeClaimTemplates) {
+		return 0
+	}
+	s.Lock()
+	defer s.Unlock()
+	if last, ok := s.times[getStatefulSetKey(set)]; ok {
+		if elapsed := time.Since(last); elapsed < statusUpdateInterval {
+			return statusUpdateInterval - elapsed
+		}
+	}
+	return 0
+}
+
+func (s *statusUpdateTimeStore) observe(key string) {
+	if statusUpdateInterval <= 0 {
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	s.times[key] = time.Now()
+}
+
+func (s *statusUpdateTimeStore) delete(key string) {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.times, key)
+}
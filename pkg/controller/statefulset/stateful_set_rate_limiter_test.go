@@ -0,0 +1,81 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statefulset
+
+import (
+	"testing"
+	"time"
+
+	apps "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	appsv1beta1 "github.com/openkruise/kruise/apis/apps/v1beta1"
+	kruisefake "github.com/openkruise/kruise/pkg/client/clientset/versioned/fake"
+)
+
+func TestScaleUpWithPodOperationRateLimit(t *testing.T) {
+	set := newStatefulSet(5)
+	set.Spec.PodManagementPolicy = apps.ParallelPodManagement
+	set.Spec.ScaleStrategy = &appsv1beta1.StatefulSetScaleStrategy{
+		PodOperationRateLimit: &appsv1beta1.StatefulSetPodOperationRateLimit{QPS: 1, Burst: 2},
+	}
+	defer podOperationLimiters.delete(getStatefulSetKey(set))
+
+	client := fake.NewSimpleClientset()
+	kruiseClient := kruisefake.NewSimpleClientset(set)
+	spc, _, ssc, stop := setupController(client, kruiseClient)
+	defer close(stop)
+
+	if err := ssc.UpdateStatefulSet(set, nil); err != nil {
+		t.Fatalf("Failed to update StatefulSet: %v", err)
+	}
+	selector, _ := metav1.LabelSelectorAsSelector(set.Spec.Selector)
+	pods, err := spc.podsLister.Pods(set.Namespace).List(selector)
+	if err != nil {
+		t.Fatalf("Failed to list pods: %v", err)
+	}
+	if len(pods) != 2 {
+		t.Fatalf("Expected 2 pods created in a burst, got %d", len(pods))
+	}
+	if delay := durationStore.Pop(getStatefulSetKey(set)); delay <= 0 || delay > time.Second {
+		t.Fatalf("Expected requeue in a second for rate limit, got %v", delay)
+	}
+}
+
+func TestStatusUpdateDelay(t *testing.T) {
+	defer func(interval time.Duration) { statusUpdateInterval = interval }(statusUpdateInterval)
+	statusUpdateInterval = time.Minute
+
+	set := newStatefulSet(3)
+	key := getStatefulSetKey(set)
+	defer statusUpdateTimes.delete(key)
+	status := set.Status.DeepCopy()
+	status.ReadyReplicas = 2
+
+	if delay := statusUpdateTimes.shouldDelay(set, status); delay != 0 {
+		t.Fatalf("Expected the first status update not delayed, got %v", delay)
+	}
+	statusUpdateTimes.observe(key)
+	if delay := statusUpdateTimes.shouldDelay(set, status); delay <= 0 || delay > time.Minute {
+		t.Fatalf("Expected the replicas update delayed, got %v", delay)
+	}
+	status.ObservedGeneration = set.Status.ObservedGeneration + 1
+	if delay := statusUpdateTimes.shouldDelay(set, status); delay != 0 {
+		t.Fatalf("Expected the generation update not delayed, got %v", delay)
+	}
+}
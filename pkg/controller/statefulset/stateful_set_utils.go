@@ -21,6 +21,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"regexp"
 	"sort"
 	"strconv"
@@ -28,8 +29,10 @@ import (
 
 	apps "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/klog/v2"
@@ -39,6 +42,7 @@ import (
 
 	appspub "github.com/openkruise/kruise/apis/apps/pub"
 	appsv1beta1 "github.com/openkruise/kruise/apis/apps/v1beta1"
+	"github.com/openkruise/kruise/pkg/util"
 	"github.com/openkruise/kruise/pkg/util/lifecycle"
 )
 
@@ -70,6 +74,50 @@ func getParentName(pod *v1.Pod) string {
 	return parent
 }
 
+// getStartOrdinal returns the first ordinal of replicas in the StatefulSet, which is 0 by default.
+func getStartOrdinal(set *appsv1beta1.StatefulSet) int {
+	if set.Spec.Ordinals != nil {
+		return int(set.Spec.Ordinals.Start)
+	}
+	return 0
+}
+
+// getEndOrdinal returns the end ordinal (exclusive) of replicas in the StatefulSet, which makes
+// the number of ordinals in [start, end) excluding reserveOrdinals equal to replicas.
+func getEndOrdinal(set *appsv1beta1.StatefulSet, reserveOrdinals *util.ReservedOrdinals) int {
+	end := getStartOrdinal(set)
+	for realReplicaCount := 0; realReplicaCount < int(*set.Spec.Replicas); realReplicaCount++ {
+		end = reserveOrdinals.NextUnreserved(end) + 1
+	}
+	return end
+}
+
+// getReservedOrdinals returns the ordinals reserved by spec.reserveOrdinals and spec.reserveOrdinalRanges.
+func getReservedOrdinals(set *appsv1beta1.StatefulSet) *util.ReservedOrdinals {
+	return util.NewReservedOrdinals(set.Spec.ReserveOrdinals, set.Spec.ReserveOrdinalRanges)
+}
+
+// isScaledDownOrdinal returns true if the ordinal is out of the range [start, end) of the StatefulSet or reserved.
+func isScaledDownOrdinal(set *appsv1beta1.StatefulSet, ordinal int) bool {
+	reserveOrdinals := getReservedOrdinals(set)
+	return reserveOrdinals.Has(ordinal) || ordinal < getStartOrdinal(set) || ordinal >= getEndOrdinal(set, reserveOrdinals)
+}
+
+// getCordonedOrdinals returns the ordinals of Pods annotated with cordon-ordinal that have not been reserved yet.
+func getCordonedOrdinals(set *appsv1beta1.StatefulSet, pods []*v1.Pod) []int {
+	reserveOrdinals := getReservedOrdinals(set)
+	cordoned := sets.NewInt()
+	for _, pod := range pods {
+		if pod.Annotations[appsv1beta1.CordonOrdinalAnnotationKey] != "true" {
+			continue
+		}
+		if ord := getOrdinal(pod); ord >= 0 && !reserveOrdinals.Has(ord) {
+			cordoned.Insert(ord)
+		}
+	}
+	return cordoned.List()
+}
+
 //  getOrdinal gets pod's ordinal. If pod has no ordinal, -1 is returned.
 func getOrdinal(pod *v1.Pod) int {
 	_, ordinal := getParentNameAndOrdinal(pod)
@@ -161,12 +209,12 @@ func claimOwnerMatchesSetAndPod(claim *v1.PersistentVolumeClaim, set *appsv1beta
 		if hasOwnerRef(claim, set) {
 			return false
 		}
-		podScaledDown := getOrdinal(pod) >= int(*set.Spec.Replicas)
+		podScaledDown := isScaledDownOrdinal(set, getOrdinal(pod))
 		if podScaledDown != hasOwnerRef(claim, pod) {
 			return false
 		}
 	case policy.WhenScaled == delete && policy.WhenDeleted == delete:
-		podScaledDown := getOrdinal(pod) >= int(*set.Spec.Replicas)
+		podScaledDown := isScaledDownOrdinal(set, getOrdinal(pod))
 		// If a pod is scaled down, there should be no set ref and a pod ref;
 		// if the pod is not scaled down it's the other way around.
 		if podScaledDown == hasOwnerRef(claim, set) {
@@ -217,7 +265,7 @@ func updateClaimOwnerRefForSetAndPod(claim *v1.PersistentVolumeClaim, set *appsv
 		needsUpdate = removeOwnerRef(claim, pod) || needsUpdate
 	case policy.WhenScaled == delete && policy.WhenDeleted == retain:
 		needsUpdate = removeOwnerRef(claim, set) || needsUpdate
-		podScaledDown := getOrdinal(pod) >= int(*set.Spec.Replicas)
+		podScaledDown := isScaledDownOrdinal(set, getOrdinal(pod))
 		if podScaledDown {
 			needsUpdate = setOwnerRef(claim, pod, &podMeta) || needsUpdate
 		}
@@ -225,7 +273,7 @@ func updateClaimOwnerRefForSetAndPod(claim *v1.PersistentVolumeClaim, set *appsv
 			needsUpdate = removeOwnerRef(claim, pod) || needsUpdate
 		}
 	case policy.WhenScaled == delete && policy.WhenDeleted == delete:
-		podScaledDown := getOrdinal(pod) >= int(*set.Spec.Replicas)
+		podScaledDown := isScaledDownOrdinal(set, getOrdinal(pod))
 		if podScaledDown {
 			needsUpdate = removeOwnerRef(claim, set) || needsUpdate
 			needsUpdate = setOwnerRef(claim, pod, &podMeta) || needsUpdate
@@ -452,21 +500,94 @@ func newStatefulSetPod(set *appsv1beta1.StatefulSet, ordinal int) *v1.Pod {
 	return pod
 }
 
+// newOrdinalStatefulSetPod returns a new Pod like newStatefulSetPod, patched with the ordinalOverrides of set
+// that match the ordinal. The error is returned if the overrides can not be applied.
+func newOrdinalStatefulSetPod(set *appsv1beta1.StatefulSet, ordinal int) (*v1.Pod, error) {
+	pod, _ := controller.GetPodFromTemplate(&set.Spec.Template, set, metav1.NewControllerRef(set, controllerKind))
+	pod.Name = getPodName(set, ordinal)
+	if err := applyOrdinalOverrides(set, ordinal, pod); err != nil {
+		return nil, fmt.Errorf("failed to apply ordinalOverrides to Pod %s: %v", pod.Name, err)
+	}
+	initIdentity(set, pod)
+	updateStorage(set, pod)
+	return pod, nil
+}
+
+// getOrdinalOverridePatches returns the patches in ordinalOverrides of set that match the ordinal.
+func getOrdinalOverridePatches(set *appsv1beta1.StatefulSet, ordinal int) [][]byte {
+	var patches [][]byte
+	for i := range set.Spec.OrdinalOverrides {
+		override := &set.Spec.OrdinalOverrides[i]
+		if override.Patch.Raw == nil {
+			continue
+		}
+		for _, item := range override.Ordinals {
+			if r, err := util.ParseOrdinalRange(item); err == nil && r.Start <= ordinal && ordinal <= r.End {
+				patches = append(patches, override.Patch.Raw)
+				break
+			}
+		}
+	}
+	return patches
+}
+
+// applyOrdinalOverrides patches the Pod with the ordinalOverrides of set that match the ordinal.
+func applyOrdinalOverrides(set *appsv1beta1.StatefulSet, ordinal int, pod *v1.Pod) error {
+	patches := getOrdinalOverridePatches(set, ordinal)
+	if len(patches) == 0 {
+		return nil
+	}
+	podBytes, err := json.Marshal(pod)
+	if err != nil {
+		return err
+	}
+	for _, patch := range patches {
+		if podBytes, err = strategicpatch.StrategicMergePatch(podBytes, patch, &v1.Pod{}); err != nil {
+			return err
+		}
+	}
+	patched := &v1.Pod{}
+	if err = json.Unmarshal(podBytes, patched); err != nil {
+		return err
+	}
+	*pod = *patched
+	return nil
+}
+
+// isOrdinalOverrideChanged returns true if the ordinalOverrides matching the ordinal are different between
+// the two revisions, which can not be updated in-place.
+func isOrdinalOverrideChanged(set *appsv1beta1.StatefulSet, oldRevision, newRevision *apps.ControllerRevision, ordinal int) bool {
+	if oldRevision == nil || newRevision == nil {
+		return false
+	}
+	oldSet, err := ApplyRevision(set, oldRevision)
+	if err != nil {
+		return true
+	}
+	newSet, err := ApplyRevision(set, newRevision)
+	if err != nil {
+		return true
+	}
+	return !reflect.DeepEqual(getOrdinalOverridePatches(oldSet, ordinal), getOrdinalOverridePatches(newSet, ordinal))
+}
+
 // newVersionedStatefulSetPod creates a new Pod for a StatefulSet. currentSet is the representation of the set at the
 // current revision. updateSet is the representation of the set at the updateRevision. currentRevision is the name of
 // the current revision. updateRevision is the name of the update revision. ordinal is the ordinal of the Pod. If the
 // returned error is nil, the returned Pod is valid.
 func newVersionedStatefulSetPod(currentSet, updateSet *appsv1beta1.StatefulSet, currentRevision, updateRevision string,
 	ordinal int, replicas []*v1.Pod,
-) *v1.Pod {
+) (*v1.Pod, error) {
+	set, revision := updateSet, updateRevision
 	if isCurrentRevisionNeeded(currentSet, updateRevision, ordinal, replicas) {
-		pod := newStatefulSetPod(currentSet, ordinal)
-		setPodRevision(pod, currentRevision)
-		return pod
+		set, revision = currentSet, currentRevision
 	}
-	pod := newStatefulSetPod(updateSet, ordinal)
-	setPodRevision(pod, updateRevision)
-	return pod
+	pod, err := newOrdinalStatefulSetPod(set, ordinal)
+	if err != nil {
+		return nil, err
+	}
+	setPodRevision(pod, revision)
+	return pod, nil
 }
 
 // isCurrentRevisionNeeded calculate if the 'ordinal' Pod should be current revision.
@@ -475,10 +596,10 @@ func isCurrentRevisionNeeded(set *appsv1beta1.StatefulSet, updateRevision string
 		return false
 	}
 	if set.Spec.UpdateStrategy.RollingUpdate == nil {
-		return ordinal < int(set.Status.CurrentReplicas)
+		return ordinal < getStartOrdinal(set)+int(set.Status.CurrentReplicas)
 	}
 	if set.Spec.UpdateStrategy.RollingUpdate.UnorderedUpdate == nil {
-		return ordinal < int(*set.Spec.UpdateStrategy.RollingUpdate.Partition)
+		return ordinal < getStartOrdinal(set)+int(*set.Spec.UpdateStrategy.RollingUpdate.Partition)
 	}
 
 	var noUpdatedReplicas int
@@ -522,6 +643,9 @@ func getPatch(set *appsv1beta1.StatefulSet) ([]byte, error) {
 	template := spec["template"].(map[string]interface{})
 	specCopy["template"] = template
 	template["$patch"] = "replace"
+	if overrides, ok := spec["ordinalOverrides"]; ok {
+		specCopy["ordinalOverrides"] = overrides
+	}
 	objCopy["spec"] = specCopy
 	patch, err := json.Marshal(objCopy)
 	return patch, err
@@ -558,6 +682,8 @@ func newRevision(set *appsv1beta1.StatefulSet, revision int64, collisionCount *i
 // is nil, the returned StatefulSet is valid.
 func ApplyRevision(set *appsv1beta1.StatefulSet, revision *apps.ControllerRevision) (*appsv1beta1.StatefulSet, error) {
 	clone := set.DeepCopy()
+	// ordinalOverrides only exists in the revisions that have it
+	clone.Spec.OrdinalOverrides = nil
 	patched, err := strategicpatch.StrategicMergePatch([]byte(runtime.EncodeOrDie(patchCodec, clone)), revision.Data.Raw, clone)
 	if err != nil {
 		return nil, err
@@ -592,7 +718,9 @@ func inconsistentStatus(set *appsv1beta1.StatefulSet, status *appsv1beta1.Statef
 		status.UpdatedReplicas != set.Status.UpdatedReplicas ||
 		status.CurrentRevision != set.Status.CurrentRevision ||
 		status.UpdateRevision != set.Status.UpdateRevision ||
-		status.LabelSelector != set.Status.LabelSelector
+		status.LabelSelector != set.Status.LabelSelector ||
+		!apiequality.Semantic.DeepEqual(status.VolumeClaimTemplates, set.Status.VolumeClaimTemplates) ||
+		!apiequality.Semantic.DeepEqual(status.RevisionDiff, set.Status.RevisionDiff)
 }
 
 // completeRollingUpdate completes a rolling update when all of set's replica Pods have been updated
@@ -605,6 +733,7 @@ func completeRollingUpdate(set *appsv1beta1.StatefulSet, status *appsv1beta1.Sta
 		status.ReadyReplicas == status.Replicas {
 		status.CurrentReplicas = status.UpdatedReplicas
 		status.CurrentRevision = status.UpdateRevision
+		status.RevisionDiff = nil
 	}
 }
 
@@ -34,6 +34,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
 	"k8s.io/kubernetes/pkg/controller/history"
 	utilpointer "k8s.io/utils/pointer"
@@ -94,6 +95,33 @@ func TestGetParentNameAndOrdinal(t *testing.T) {
 	}
 }
 
+func TestGetCordonedOrdinals(t *testing.T) {
+	set := newStatefulSet(5)
+	set.Spec.ReserveOrdinalRanges = []string{"1-2"}
+	var pods []*v1.Pod
+	for i := 0; i < 5; i++ {
+		pods = append(pods, newStatefulSetPod(set, i))
+	}
+	pods[1].Annotations = map[string]string{appsv1beta1.CordonOrdinalAnnotationKey: "true"}
+	pods[3].Annotations = map[string]string{appsv1beta1.CordonOrdinalAnnotationKey: "true"}
+	pods[4].Annotations = map[string]string{appsv1beta1.CordonOrdinalAnnotationKey: "false"}
+	if cordoned := getCordonedOrdinals(set, pods); !reflect.DeepEqual(cordoned, []int{3}) {
+		t.Errorf("Expected cordoned ordinals [3] found %v", cordoned)
+	}
+}
+
+func TestIsScaledDownOrdinal(t *testing.T) {
+	set := newStatefulSet(3)
+	set.Spec.Ordinals = &appsv1beta1.StatefulSetOrdinals{Start: 1}
+	set.Spec.ReserveOrdinals = []int{2}
+	// replicas are [1, 3, 4]
+	for ordinal, expected := range map[int]bool{0: true, 1: false, 2: true, 3: false, 4: false, 5: true} {
+		if scaledDown := isScaledDownOrdinal(set, ordinal); scaledDown != expected {
+			t.Errorf("Expected ordinal %d scaled down %v found %v", ordinal, expected, scaledDown)
+		}
+	}
+}
+
 func TestGetClaimPodName(t *testing.T) {
 	set := appsv1beta1.StatefulSet{}
 	set.Name = "my-set"
@@ -754,6 +782,79 @@ func TestCreateApplyRevision(t *testing.T) {
 	}
 }
 
+func TestOrdinalOverrides(t *testing.T) {
+	set := newStatefulSet(3)
+	set.Status.CollisionCount = new(int32)
+	plainRevision, err := newRevision(set, 1, set.Status.CollisionCount)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	set.Spec.OrdinalOverrides = []appsv1beta1.StatefulSetOrdinalOverride{
+		{Ordinals: []intstr.IntOrString{intstr.FromInt(0)}, Patch: runtime.RawExtension{Raw: []byte(`{"spec":{"nodeSelector":{"role":"seed"}}}`)}},
+		{Ordinals: []intstr.IntOrString{intstr.FromString("0-1")}, Patch: runtime.RawExtension{Raw: []byte(`{"spec":{"containers":[{"name":"nginx","env":[{"name":"FOO","value":"bar"}]}]}}`)}},
+	}
+	overrideRevision, err := newRevision(set, 2, set.Status.CollisionCount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if history.EqualRevision(plainRevision, overrideRevision) {
+		t.Fatalf("expected different revisions for ordinalOverrides")
+	}
+
+	pod0, err := newOrdinalStatefulSetPod(set, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pod0.Spec.NodeSelector["role"] != "seed" || len(pod0.Spec.Containers[0].Env) != 1 || pod0.Spec.Containers[0].Image != set.Spec.Template.Spec.Containers[0].Image {
+		t.Errorf("unexpected pod-0 spec %+v", pod0.Spec)
+	}
+	if pod0.Name != getPodName(set, 0) || !identityMatches(set, pod0) {
+		t.Errorf("unexpected pod-0 identity %s", pod0.Name)
+	}
+	pod1, err := newOrdinalStatefulSetPod(set, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pod1.Spec.NodeSelector != nil || len(pod1.Spec.Containers[0].Env) != 1 {
+		t.Errorf("unexpected pod-1 spec %+v", pod1.Spec)
+	}
+	pod2, err := newOrdinalStatefulSetPod(set, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pod2.Spec.NodeSelector != nil || len(pod2.Spec.Containers[0].Env) != 0 {
+		t.Errorf("unexpected pod-2 spec %+v", pod2.Spec)
+	}
+
+	// the invalid override should be reported instead of creating the pod without it
+	invalidSet := set.DeepCopy()
+	invalidSet.Spec.OrdinalOverrides = []appsv1beta1.StatefulSetOrdinalOverride{
+		{Ordinals: []intstr.IntOrString{intstr.FromInt(2)}, Patch: runtime.RawExtension{Raw: []byte(`{"spec":{"containers":"invalid"}}`)}},
+	}
+	if _, err := newOrdinalStatefulSetPod(invalidSet, 2); err == nil {
+		t.Errorf("expected error for invalid ordinalOverrides")
+	}
+	if _, err := newVersionedStatefulSetPod(invalidSet, invalidSet, "current", "update", 2, nil); err == nil {
+		t.Errorf("expected error for invalid ordinalOverrides of the versioned pod")
+	}
+
+	// the overrides of set should not leak into the revision without overrides
+	restoredSet, err := ApplyRevision(set, plainRevision)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(restoredSet.Spec.OrdinalOverrides) != 0 {
+		t.Errorf("unexpected ordinalOverrides %v restored", restoredSet.Spec.OrdinalOverrides)
+	}
+	if !isOrdinalOverrideChanged(set, plainRevision, overrideRevision, 1) {
+		t.Errorf("expected overrides of ordinal 1 changed")
+	}
+	if isOrdinalOverrideChanged(set, plainRevision, overrideRevision, 2) {
+		t.Errorf("expected overrides of ordinal 2 not changed")
+	}
+}
+
 func TestRollingUpdateApplyRevision(t *testing.T) {
 	set := newStatefulSet(1)
 	set.Status.CollisionCount = new(int32)
@@ -0,0 +1,199 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statefulset
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog/v2"
+
+	appsv1beta1 "github.com/openkruise/kruise/apis/apps/v1beta1"
+)
+
+var (
+	// interval to check the progress of the expanding PVCs, for the PVC events are not watched
+	volumeExpansionCheckInterval = 10 * time.Second
+	// how long the file system resize can keep pending before restarting the Pod to finish an offline expansion
+	fileSystemResizePendingTimeout = 2 * time.Minute
+)
+
+// syncVolumeClaimExpansion expands the storage requests of existing PVCs to the volumeClaimTemplates replica by replica
+// in ordinal order, and records the expansion progress into status, only if volumeClaimUpdateStrategy type is Expand.
+// The PVCs of a replica will not be expanded until all PVCs of the replicas with smaller ordinals have been expanded.
+func (ssc *defaultStatefulSetControl) syncVolumeClaimExpansion(set *appsv1beta1.StatefulSet, status *appsv1beta1.StatefulSetStatus, pods []*v1.Pod) error {
+	if set.DeletionTimestamp != nil || set.Spec.VolumeClaimUpdateStrategy == nil ||
+		set.Spec.VolumeClaimUpdateStrategy.Type != appsv1beta1.ExpandVolumeClaimUpdateStrategyType {
+		return nil
+	}
+
+	replicas := make([]*v1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		if ord := getOrdinal(pod); ord >= 0 && !isScaledDownOrdinal(set, ord) {
+			replicas = append(replicas, pod)
+		}
+	}
+	sort.Sort(ascendingOrdinal(replicas))
+
+	var errs []error
+	var blocked bool
+	expandable := make(map[string]bool)
+	templateStatuses := make([]appsv1beta1.StatefulSetVolumeClaimTemplateStatus, 0, len(set.Spec.VolumeClaimTemplates))
+	for i := range set.Spec.VolumeClaimTemplates {
+		template := &set.Spec.VolumeClaimTemplates[i]
+		templateStatuses = append(templateStatuses, appsv1beta1.StatefulSetVolumeClaimTemplateStatus{
+			Name:    template.Name,
+			Storage: template.Spec.Resources.Requests[v1.ResourceStorage],
+		})
+	}
+
+	for _, pod := range replicas {
+		var expanding bool
+		for i := range set.Spec.VolumeClaimTemplates {
+			template := &set.Spec.VolumeClaimTemplates[i]
+			templateStatus := &templateStatuses[i]
+			if templateStatus.Storage.IsZero() {
+				continue
+			}
+
+			claimName := getPersistentVolumeClaimName(set, template, getOrdinal(pod))
+			pvc, err := ssc.podControl.objectMgr.GetClaim(set.Namespace, claimName)
+			if err != nil {
+				if !apierrors.IsNotFound(err) {
+					errs = append(errs, fmt.Errorf("failed to get pvc %s: %v", claimName, err))
+				}
+				continue
+			}
+			// only the requests of bound claims can be modified
+			if pvc.Status.Phase != v1.ClaimBound {
+				continue
+			}
+
+			capacity := pvc.Status.Capacity[v1.ResourceStorage]
+			if capacity.Cmp(templateStatus.Storage) >= 0 {
+				templateStatus.ExpandedReplicas++
+				continue
+			}
+
+			requests := pvc.Spec.Resources.Requests[v1.ResourceStorage]
+			if requests.Cmp(templateStatus.Storage) < 0 {
+				allowed, err := ssc.isVolumeExpansionAllowed(pvc, expandable)
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				if !allowed {
+					templateStatus.UnsupportedReplicas++
+					continue
+				}
+				// wait for the replicas with smaller ordinals to be expanded
+				if blocked {
+					continue
+				}
+				if err := ssc.updateVolumeClaimStorage(pvc, templateStatus.Storage); err != nil {
+					errs = append(errs, fmt.Errorf("failed to expand pvc %s: %v", pvc.Name, err))
+					continue
+				}
+				klog.V(3).Infof("StatefulSet %s expanded pvc %s from %s to %s",
+					getStatefulSetKey(set), pvc.Name, requests.String(), templateStatus.Storage.String())
+			} else if err := ssc.restartPodForFileSystemResize(set, pod, pvc); err != nil {
+				errs = append(errs, err)
+			}
+			templateStatus.ExpandingReplicas++
+			expanding = true
+		}
+		if expanding {
+			blocked = true
+		}
+	}
+
+	if blocked {
+		durationStore.Push(getStatefulSetKey(set), volumeExpansionCheckInterval)
+	}
+	status.VolumeClaimTemplates = nil
+	for i := range templateStatuses {
+		if !templateStatuses[i].Storage.IsZero() {
+			status.VolumeClaimTemplates = append(status.VolumeClaimTemplates, templateStatuses[i])
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// isVolumeExpansionAllowed returns whether the StorageClass of pvc allows volume expansion.
+// The results are cached in expandable by StorageClass names.
+func (ssc *defaultStatefulSetControl) isVolumeExpansionAllowed(pvc *v1.PersistentVolumeClaim, expandable map[string]bool) (bool, error) {
+	if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName == "" {
+		return false, nil
+	}
+	name := *pvc.Spec.StorageClassName
+	if allowed, ok := expandable[name]; ok {
+		return allowed, nil
+	}
+
+	sc, err := ssc.podControl.objectMgr.GetStorageClass(name)
+	if err != nil {
+		return false, fmt.Errorf("failed to get storageclass %s: %v", name, err)
+	}
+	expandable[name] = sc.AllowVolumeExpansion != nil && *sc.AllowVolumeExpansion
+	return expandable[name], nil
+}
+
+func (ssc *defaultStatefulSetControl) updateVolumeClaimStorage(pvc *v1.PersistentVolumeClaim, storage resource.Quantity) error {
+	clone := pvc.DeepCopy()
+	if clone.Spec.Resources.Requests == nil {
+		clone.Spec.Resources.Requests = v1.ResourceList{}
+	}
+	clone.Spec.Resources.Requests[v1.ResourceStorage] = storage
+	return ssc.podControl.objectMgr.UpdateClaim(clone)
+}
+
+// restartPodForFileSystemResize deletes the Pod if the file system resize of its pvc has been pending for a while,
+// which means the volume can only be expanded offline and the file system will be resized when the Pod recreated.
+func (ssc *defaultStatefulSetControl) restartPodForFileSystemResize(set *appsv1beta1.StatefulSet, pod *v1.Pod, pvc *v1.PersistentVolumeClaim) error {
+	var condition *v1.PersistentVolumeClaimCondition
+	for i := range pvc.Status.Conditions {
+		if pvc.Status.Conditions[i].Type == v1.PersistentVolumeClaimFileSystemResizePending {
+			condition = &pvc.Status.Conditions[i]
+			break
+		}
+	}
+	// the Pod created after the resize pending has mounted the volume, so kubelet is resizing it
+	if condition == nil || condition.Status != v1.ConditionTrue || !isCreated(pod) || isTerminating(pod) ||
+		!pod.CreationTimestamp.Before(&condition.LastTransitionTime) {
+		return nil
+	}
+	if pending := time.Since(condition.LastTransitionTime.Time); pending < fileSystemResizePendingTimeout {
+		durationStore.Push(getStatefulSetKey(set), fileSystemResizePendingTimeout-pending)
+		return nil
+	}
+	if !allowPodOperation(set) {
+		return nil
+	}
+
+	klog.V(2).Infof("StatefulSet %s terminating Pod %s for file system resize of pvc %s", getStatefulSetKey(set), pod.Name, pvc.Name)
+	if err := ssc.podControl.DeleteStatefulPod(set, pod); err != nil {
+		return err
+	}
+	ssc.recorder.Eventf(set, v1.EventTypeNormal, "RestartForFileSystemResize",
+		"restart pod %s to resize the file system of pvc %s", pod.Name, pvc.Name)
+	return nil
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statefulset
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	utilpointer "k8s.io/utils/pointer"
+
+	appsv1beta1 "github.com/openkruise/kruise/apis/apps/v1beta1"
+	kruisefake "github.com/openkruise/kruise/pkg/client/clientset/versioned/fake"
+)
+
+func TestSyncVolumeClaimExpansion(t *testing.T) {
+	set := newStatefulSet(3)
+	set.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[v1.ResourceStorage] = resource.MustParse("20Gi")
+	set.Spec.VolumeClaimUpdateStrategy = &appsv1beta1.StatefulSetVolumeClaimUpdateStrategy{Type: appsv1beta1.ExpandVolumeClaimUpdateStrategyType}
+	defer durationStore.Pop(getStatefulSetKey(set))
+
+	client := fake.NewSimpleClientset()
+	kruiseClient := kruisefake.NewSimpleClientset(set)
+	spc, _, ssc, stop := setupController(client, kruiseClient)
+	defer close(stop)
+
+	spc.classesIndexer.Add(&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "expandable"}, AllowVolumeExpansion: utilpointer.BoolPtr(true)})
+	spc.classesIndexer.Add(&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "fixed"}})
+	var pods []*v1.Pod
+	for ord, storageClass := range []string{"expandable", "expandable", "fixed"} {
+		pod := newStatefulSetPod(set, ord)
+		pod.CreationTimestamp = metav1.Now()
+		pods = append(pods, pod)
+		pvc := newPVC(getPersistentVolumeClaimName(set, &set.Spec.VolumeClaimTemplates[0], ord))
+		pvc.Spec.StorageClassName = utilpointer.StringPtr(storageClass)
+		pvc.Spec.Resources.Requests[v1.ResourceStorage] = resource.MustParse("10Gi")
+		pvc.Status = v1.PersistentVolumeClaimStatus{
+			Phase:    v1.ClaimBound,
+			Capacity: v1.ResourceList{v1.ResourceStorage: resource.MustParse("10Gi")},
+		}
+		spc.claimsIndexer.Add(&pvc)
+	}
+
+	assertExpansion := func(expectedStatus appsv1beta1.StatefulSetVolumeClaimTemplateStatus, expectedRequests []string) {
+		status := &appsv1beta1.StatefulSetStatus{}
+		if err := ssc.(*defaultStatefulSetControl).syncVolumeClaimExpansion(set, status, pods); err != nil {
+			t.Fatalf("Failed to sync volume claim expansion: %v", err)
+		}
+		expectedStatus.Name = set.Spec.VolumeClaimTemplates[0].Name
+		expectedStatus.Storage = resource.MustParse("20Gi")
+		if !reflect.DeepEqual(status.VolumeClaimTemplates, []appsv1beta1.StatefulSetVolumeClaimTemplateStatus{expectedStatus}) {
+			t.Fatalf("Expected status %+v, got %+v", expectedStatus, status.VolumeClaimTemplates)
+		}
+		for ord, requests := range expectedRequests {
+			pvc, err := spc.GetClaim(set.Namespace, getPersistentVolumeClaimName(set, &set.Spec.VolumeClaimTemplates[0], ord))
+			if err != nil {
+				t.Fatalf("Failed to get pvc: %v", err)
+			}
+			if got := pvc.Spec.Resources.Requests[v1.ResourceStorage]; got.Cmp(resource.MustParse(requests)) != 0 {
+				t.Fatalf("Expected pvc %s requests %s, got %s", pvc.Name, requests, got.String())
+			}
+		}
+	}
+	setCapacity := func(ord int, capacity string, conditions ...v1.PersistentVolumeClaimCondition) {
+		pvc, _ := spc.GetClaim(set.Namespace, getPersistentVolumeClaimName(set, &set.Spec.VolumeClaimTemplates[0], ord))
+		pvc = pvc.DeepCopy()
+		pvc.Status.Capacity[v1.ResourceStorage] = resource.MustParse(capacity)
+		pvc.Status.Conditions = conditions
+		spc.claimsIndexer.Update(pvc)
+	}
+
+	// only the pvc of the first replica is expanded
+	assertExpansion(appsv1beta1.StatefulSetVolumeClaimTemplateStatus{ExpandingReplicas: 1, UnsupportedReplicas: 1}, []string{"20Gi", "10Gi", "10Gi"})
+
+	// restart the pod after the file system resize has been pending for long
+	pods[0].CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+	pods[0].Status.Phase = v1.PodRunning
+	spc.podsIndexer.Add(pods[0])
+	setCapacity(0, "10Gi", v1.PersistentVolumeClaimCondition{
+		Type:               v1.PersistentVolumeClaimFileSystemResizePending,
+		Status:             v1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+	})
+	assertExpansion(appsv1beta1.StatefulSetVolumeClaimTemplateStatus{ExpandingReplicas: 1, UnsupportedReplicas: 1}, []string{"20Gi", "10Gi", "10Gi"})
+	if _, err := spc.podsLister.Pods(set.Namespace).Get(pods[0].Name); err != nil {
+		t.Fatalf("Expected pod not restarted before timeout, got %v", err)
+	}
+	setCapacity(0, "10Gi", v1.PersistentVolumeClaimCondition{
+		Type:               v1.PersistentVolumeClaimFileSystemResizePending,
+		Status:             v1.ConditionTrue,
+		LastTransitionTime: metav1.NewTime(time.Now().Add(-fileSystemResizePendingTimeout - time.Second)),
+	})
+	assertExpansion(appsv1beta1.StatefulSetVolumeClaimTemplateStatus{ExpandingReplicas: 1, UnsupportedReplicas: 1}, []string{"20Gi", "10Gi", "10Gi"})
+	if _, err := spc.podsLister.Pods(set.Namespace).Get(pods[0].Name); !apierrors.IsNotFound(err) {
+		t.Fatalf("Expected pod restarted for file system resize, got %v", err)
+	}
+
+	// continue to expand the next replica
+	setCapacity(0, "20Gi")
+	assertExpansion(appsv1beta1.StatefulSetVolumeClaimTemplateStatus{ExpandedReplicas: 1, ExpandingReplicas: 1, UnsupportedReplicas: 1}, []string{"20Gi", "20Gi", "10Gi"})
+}
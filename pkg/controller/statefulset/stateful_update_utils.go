@@ -18,12 +18,13 @@ package statefulset
 
 import (
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	appsv1beta1 "github.com/openkruise/kruise/apis/apps/v1beta1"
 	"github.com/openkruise/kruise/pkg/util/updatesort"
 )
 
-func sortPodsToUpdate(rollingUpdateStrategy *appsv1beta1.RollingUpdateStatefulSetStrategy, updateRevision string, totalReplicas int32, replicas []*v1.Pod) []int {
+func sortPodsToUpdate(rollingUpdateStrategy *appsv1beta1.RollingUpdateStatefulSetStrategy, updateRevision string, totalReplicas int32, startOrdinal int, replicas []*v1.Pod) []int {
 	var updateMin int
 	if rollingUpdateStrategy != nil && rollingUpdateStrategy.Partition != nil {
 		updateMin = int(*rollingUpdateStrategy.Partition)
@@ -31,7 +32,7 @@ func sortPodsToUpdate(rollingUpdateStrategy *appsv1beta1.RollingUpdateStatefulSe
 
 	if rollingUpdateStrategy == nil || rollingUpdateStrategy.UnorderedUpdate == nil {
 		var indexes []int
-		for target := len(replicas) - 1; target >= updateMin; target-- {
+		for target := len(replicas) - 1; target >= startOrdinal+updateMin; target-- {
 			if replicas[target] == nil {
 				continue
 			}
@@ -72,3 +73,18 @@ func sortPodsToUpdate(rollingUpdateStrategy *appsv1beta1.RollingUpdateStatefulSe
 
 	return allIdxs
 }
+
+// getUnavailablePodsNotToUpdate returns the names of unavailable Pods in replicas that are not in updateIndexes.
+func getUnavailablePodsNotToUpdate(replicas []*v1.Pod, updateIndexes []int, minReadySeconds int32) []string {
+	toUpdate := sets.NewInt(updateIndexes...)
+	var unavailablePods []string
+	for i, pod := range replicas {
+		if pod == nil || toUpdate.Has(i) {
+			continue
+		}
+		if isAvailable, _ := isRunningAndAvailable(pod, minReadySeconds); !isHealthy(pod) || !isAvailable {
+			unavailablePods = append(unavailablePods, pod.Name)
+		}
+	}
+	return unavailablePods
+}
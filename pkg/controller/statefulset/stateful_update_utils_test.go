@@ -128,9 +128,30 @@ func TestSortPodsToUpdate(t *testing.T) {
 	}
 
 	for i, tc := range cases {
-		res := sortPodsToUpdate(tc.strategy, tc.updateRevision, tc.totalReplicas, tc.replicas)
+		res := sortPodsToUpdate(tc.strategy, tc.updateRevision, tc.totalReplicas, 0, tc.replicas)
 		if !reflect.DeepEqual(res, tc.expected) {
 			t.Fatalf("case #%d failed, expected %v, got %v", i, tc.expected, res)
 		}
 	}
 }
+
+func TestGetUnavailablePodsNotToUpdate(t *testing.T) {
+	readyPod := func(name string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: v1.PodStatus{
+				Phase:      v1.PodRunning,
+				Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}},
+			},
+		}
+	}
+	notReadyPod := func(name string) *v1.Pod {
+		return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}, Status: v1.PodStatus{Phase: v1.PodPending}}
+	}
+	replicas := []*v1.Pod{nil, readyPod("foo-1"), notReadyPod("foo-2"), notReadyPod("foo-3"), readyPod("foo-4")}
+
+	unavailablePods := getUnavailablePodsNotToUpdate(replicas, []int{3, 4}, 0)
+	if expected := []string{"foo-2"}; !reflect.DeepEqual(unavailablePods, expected) {
+		t.Fatalf("expected unavailable pods %v, got %v", expected, unavailablePods)
+	}
+}
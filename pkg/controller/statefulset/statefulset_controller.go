@@ -19,6 +19,7 @@ package statefulset
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"time"
@@ -28,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
 	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
@@ -66,6 +68,9 @@ import (
 
 func init() {
 	flag.IntVar(&concurrentReconciles, "statefulset-workers", concurrentReconciles, "Max concurrent workers for StatefulSet controller.")
+	flag.Float64Var(&podOperationQPS, "statefulset-pod-operation-qps", podOperationQPS, "Default QPS of Pod creations and deletions for each StatefulSet, 0 means no limit.")
+	flag.IntVar(&podOperationBurst, "statefulset-pod-operation-burst", podOperationBurst, "Default burst of Pod creations and deletions for each StatefulSet, defaults to the QPS.")
+	flag.DurationVar(&statusUpdateInterval, "statefulset-status-update-interval", statusUpdateInterval, "Min interval between StatefulSet status updates that only change the replicas, 0 means no batching.")
 }
 
 var (
@@ -220,6 +225,7 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 // +kubebuilder:rbac:groups=core,resources=pods/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
 // +kubebuilder:rbac:groups=apps,resources=controllerrevisions,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps.kruise.io,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps.kruise.io,resources=statefulsets/status,verbs=get;update;patch
@@ -249,6 +255,8 @@ func (ssc *ReconcileStatefulSet) Reconcile(_ context.Context, request reconcile.
 	if errors.IsNotFound(err) {
 		klog.Infof("StatefulSet has been deleted %v", key)
 		updateExpectations.DeleteExpectations(key)
+		podOperationLimiters.delete(key)
+		statusUpdateTimes.delete(key)
 		return reconcile.Result{}, nil
 	}
 	if err != nil {
@@ -272,6 +280,10 @@ func (ssc *ReconcileStatefulSet) Reconcile(_ context.Context, request reconcile.
 		return reconcile.Result{}, err
 	}
 
+	if set, err = ssc.reserveCordonedOrdinals(set, pods); err != nil {
+		return reconcile.Result{}, err
+	}
+
 	err = ssc.syncStatefulSet(set, pods)
 	return reconcile.Result{RequeueAfter: durationStore.Pop(getStatefulSetKey(set))}, err
 }
@@ -301,6 +313,27 @@ func (ssc *ReconcileStatefulSet) adoptOrphanRevisions(set *appsv1beta1.StatefulS
 	return nil
 }
 
+// reserveCordonedOrdinals appends the ordinals of cordoned Pods into spec.reserveOrdinals,
+// and returns the patched StatefulSet.
+func (ssc *ReconcileStatefulSet) reserveCordonedOrdinals(set *appsv1beta1.StatefulSet, pods []*v1.Pod) (*appsv1beta1.StatefulSet, error) {
+	cordoned := getCordonedOrdinals(set, pods)
+	if len(cordoned) == 0 || set.DeletionTimestamp != nil {
+		return set, nil
+	}
+
+	reserveOrdinals := append(append([]int{}, set.Spec.ReserveOrdinals...), cordoned...)
+	body, _ := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"resourceVersion": set.ResourceVersion},
+		"spec":     map[string]interface{}{"reserveOrdinals": reserveOrdinals},
+	})
+	patched, err := ssc.kruiseClient.AppsV1beta1().StatefulSets(set.Namespace).Patch(context.TODO(), set.Name, types.MergePatchType, body, metav1.PatchOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve cordoned ordinals %v: %v", cordoned, err)
+	}
+	klog.Infof("StatefulSet %s/%s reserved cordoned ordinals %v", set.Namespace, set.Name, cordoned)
+	return patched, nil
+}
+
 // getPodsForStatefulSet returns the Pods that a given StatefulSet should manage.
 // It also reconciles ControllerRef by adopting/orphaning.
 //
@@ -0,0 +1,200 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilpointer "k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/util/refmanager"
+)
+
+// AdvancedDaemonSetAdapter implements the Adapter interface for Advanced DaemonSet objects.
+type AdvancedDaemonSetAdapter struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// NewResourceObject creates a empty Advanced DaemonSet object.
+func (a *AdvancedDaemonSetAdapter) NewResourceObject() client.Object {
+	return &alpha1.DaemonSet{}
+}
+
+// NewResourceListObject creates a empty Advanced DaemonSet list object.
+func (a *AdvancedDaemonSetAdapter) NewResourceListObject() client.ObjectList {
+	return &alpha1.DaemonSetList{}
+}
+
+// GetStatusObservedGeneration returns the observed generation of the subset.
+func (a *AdvancedDaemonSetAdapter) GetStatusObservedGeneration(obj metav1.Object) int64 {
+	return obj.(*alpha1.DaemonSet).Status.ObservedGeneration
+}
+
+// GetReplicaDetails returns the replicas detail the subset needs.
+// The spec replicas of a daemon set is the number of nodes that should be running the daemon pod.
+func (a *AdvancedDaemonSetAdapter) GetReplicaDetails(obj metav1.Object, updatedRevision string) (specReplicas, specPartition *int32, statusReplicas, statusReadyReplicas, statusUpdatedReplicas, statusUpdatedReadyReplicas int32, err error) {
+	set := obj.(*alpha1.DaemonSet)
+	var pods []*corev1.Pod
+	pods, err = a.getDaemonSetPods(set)
+	if err != nil {
+		return
+	}
+
+	specReplicas = utilpointer.Int32Ptr(set.Status.DesiredNumberScheduled)
+	if set.Spec.UpdateStrategy.RollingUpdate != nil && set.Spec.UpdateStrategy.RollingUpdate.Partition != nil {
+		specPartition = utilpointer.Int32Ptr(*set.Spec.UpdateStrategy.RollingUpdate.Partition)
+	}
+
+	statusReplicas = set.Status.CurrentNumberScheduled
+	statusReadyReplicas = set.Status.NumberReady
+	statusUpdatedReplicas, statusUpdatedReadyReplicas = calculateUpdatedReplicas(pods, updatedRevision)
+	return
+}
+
+// GetSubsetFailure returns the failure information of the subset.
+// Advanced DaemonSet has no condition.
+func (a *AdvancedDaemonSetAdapter) GetSubsetFailure() *string {
+	return nil
+}
+
+// ApplySubsetTemplate updates the subset to the latest revision, depending on the AdvancedDaemonSetTemplate.
+// The replicas are ignored, for the daemon pods run on all the nodes selected by the subset.
+func (a *AdvancedDaemonSetAdapter) ApplySubsetTemplate(ud *alpha1.UnitedDeployment, subsetName, revision string, replicas, partition int32, obj runtime.Object) error {
+	set := obj.(*alpha1.DaemonSet)
+
+	var subSetConfig *alpha1.Subset
+	for _, subset := range ud.Spec.Topology.Subsets {
+		if subset.Name == subsetName {
+			subSetConfig = &subset
+			break
+		}
+	}
+	if subSetConfig == nil {
+		return fmt.Errorf("fail to find subset config %s", subsetName)
+	}
+
+	set.Namespace = ud.Namespace
+
+	if set.Labels == nil {
+		set.Labels = map[string]string{}
+	}
+	for k, v := range ud.Spec.Template.AdvancedDaemonSetTemplate.Labels {
+		set.Labels[k] = v
+	}
+	for k, v := range ud.Spec.Selector.MatchLabels {
+		set.Labels[k] = v
+	}
+	set.Labels[alpha1.ControllerRevisionHashLabelKey] = revision
+	// record the subset name as a label
+	set.Labels[alpha1.SubSetNameLabelKey] = subsetName
+
+	if set.Annotations == nil {
+		set.Annotations = map[string]string{}
+	}
+	for k, v := range ud.Spec.Template.AdvancedDaemonSetTemplate.Annotations {
+		set.Annotations[k] = v
+	}
+
+	set.GenerateName = getSubsetPrefix(ud.Name, subsetName)
+
+	selectors := ud.Spec.Selector.DeepCopy()
+	selectors.MatchLabels[alpha1.SubSetNameLabelKey] = subsetName
+
+	if err := controllerutil.SetControllerReference(ud, set, a.Scheme); err != nil {
+		return err
+	}
+
+	set.Spec.Selector = selectors
+	set.Spec.MinReadySeconds = ud.Spec.Template.AdvancedDaemonSetTemplate.Spec.MinReadySeconds
+	set.Spec.BurstReplicas = ud.Spec.Template.AdvancedDaemonSetTemplate.Spec.BurstReplicas
+	set.Spec.Lifecycle = ud.Spec.Template.AdvancedDaemonSetTemplate.Spec.Lifecycle
+	set.Spec.NodeApproval = ud.Spec.Template.AdvancedDaemonSetTemplate.Spec.NodeApproval
+	set.Spec.RevisionHistoryLimit = ud.Spec.Template.AdvancedDaemonSetTemplate.Spec.RevisionHistoryLimit
+
+	set.Spec.UpdateStrategy = *ud.Spec.Template.AdvancedDaemonSetTemplate.Spec.UpdateStrategy.DeepCopy()
+	if set.Spec.UpdateStrategy.Type == alpha1.RollingUpdateDaemonSetStrategyType || set.Spec.UpdateStrategy.Type == "" {
+		if set.Spec.UpdateStrategy.RollingUpdate == nil {
+			set.Spec.UpdateStrategy.RollingUpdate = &alpha1.RollingUpdateDaemonSet{}
+		}
+		set.Spec.UpdateStrategy.RollingUpdate.Partition = utilpointer.Int32Ptr(partition)
+		set.Spec.UpdateStrategy.RollingUpdate.PartitionPercent = nil
+	}
+
+	set.Spec.Template = *ud.Spec.Template.AdvancedDaemonSetTemplate.Spec.Template.DeepCopy()
+	if set.Spec.Template.Labels == nil {
+		set.Spec.Template.Labels = map[string]string{}
+	}
+	set.Spec.Template.Labels[alpha1.SubSetNameLabelKey] = subsetName
+	set.Spec.Template.Labels[alpha1.ControllerRevisionHashLabelKey] = revision
+
+	attachNodeAffinity(&set.Spec.Template.Spec, subSetConfig)
+	attachTolerations(&set.Spec.Template.Spec, subSetConfig)
+
+	return nil
+}
+
+// PostUpdate does some works after subset updated.
+func (a *AdvancedDaemonSetAdapter) PostUpdate(ud *alpha1.UnitedDeployment, obj runtime.Object, revision string, partition int32) error {
+	return nil
+}
+
+// IsExpected checks the subset is the expected revision or not.
+// The revision label can tell the current subset revision.
+func (a *AdvancedDaemonSetAdapter) IsExpected(obj metav1.Object, revision string) bool {
+	return obj.GetLabels()[alpha1.ControllerRevisionHashLabelKey] != revision
+}
+
+func (a *AdvancedDaemonSetAdapter) getDaemonSetPods(set *alpha1.DaemonSet) ([]*corev1.Pod, error) {
+	selector, err := metav1.LabelSelectorAsSelector(set.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+
+	podList := &corev1.PodList{}
+	err = a.Client.List(context.TODO(), podList, &client.ListOptions{Namespace: set.Namespace, LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+
+	manager, err := refmanager.New(a.Client, set.Spec.Selector, set, a.Scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make([]metav1.Object, len(podList.Items))
+	for i, pod := range podList.Items {
+		selected[i] = pod.DeepCopy()
+	}
+	claimed, err := manager.ClaimOwnedObjects(selected)
+	if err != nil {
+		return nil, err
+	}
+
+	claimedPods := make([]*corev1.Pod, len(claimed))
+	for i, pod := range claimed {
+		claimedPods[i] = pod.(*corev1.Pod)
+	}
+	return claimedPods, nil
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+)
+
+func TestAdvancedDaemonSetAdapter(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = appsv1alpha1.AddToScheme(scheme)
+
+	ud := &appsv1alpha1.UnitedDeployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "agent", UID: "ud-uid"},
+		Spec: appsv1alpha1.UnitedDeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "agent"}},
+			Template: appsv1alpha1.SubsetTemplate{
+				AdvancedDaemonSetTemplate: &appsv1alpha1.AdvancedDaemonSetTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "agent"}},
+					Spec: appsv1alpha1.DaemonSetSpec{
+						Template: corev1.PodTemplateSpec{
+							ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "agent"}},
+							Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "agent", Image: "agent:v1"}}},
+						},
+					},
+				},
+			},
+			Topology: appsv1alpha1.Topology{
+				Subsets: []appsv1alpha1.Subset{{
+					Name: "pool-a",
+					NodeSelectorTerm: corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{
+						{Key: "pool", Operator: corev1.NodeSelectorOpIn, Values: []string{"a"}},
+					}},
+				}},
+			},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+	adapter := &AdvancedDaemonSetAdapter{Client: fakeClient, Scheme: scheme}
+	set := adapter.NewResourceObject().(*appsv1alpha1.DaemonSet)
+	if err := adapter.ApplySubsetTemplate(ud, "pool-a", "rev-1", 5, 2, set); err != nil {
+		t.Fatalf("failed to apply subset template: %v", err)
+	}
+
+	if set.Spec.Selector.MatchLabels[appsv1alpha1.SubSetNameLabelKey] != "pool-a" ||
+		set.Spec.Template.Labels[appsv1alpha1.SubSetNameLabelKey] != "pool-a" ||
+		set.Spec.Template.Labels[appsv1alpha1.ControllerRevisionHashLabelKey] != "rev-1" {
+		t.Fatalf("unexpected labels of daemon set: %v, %v", set.Spec.Selector, set.Spec.Template.Labels)
+	}
+	if set.Spec.UpdateStrategy.RollingUpdate == nil || *set.Spec.UpdateStrategy.RollingUpdate.Partition != 2 {
+		t.Fatalf("expected partition 2, but got %v", set.Spec.UpdateStrategy.RollingUpdate)
+	}
+	affinity := set.Spec.Template.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil || affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil ||
+		len(affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms) != 1 {
+		t.Fatalf("expected node affinity of the subset attached, but got %v", affinity)
+	}
+	if ref := metav1.GetControllerOf(set); ref == nil || ref.UID != ud.UID {
+		t.Fatalf("expected daemon set controlled by UnitedDeployment, but got %v", ref)
+	}
+
+	set.Name = "agent-pool-a-xxx"
+	partition := int32(2)
+	set.Spec.UpdateStrategy.RollingUpdate.Partition = &partition
+	set.Status = appsv1alpha1.DaemonSetStatus{DesiredNumberScheduled: 4, CurrentNumberScheduled: 3, NumberReady: 2}
+	specReplicas, specPartition, statusReplicas, statusReadyReplicas, _, _, err := adapter.GetReplicaDetails(set, "rev-1")
+	if err != nil {
+		t.Fatalf("failed to get replica details: %v", err)
+	}
+	if *specReplicas != 4 || *specPartition != 2 || statusReplicas != 3 || statusReadyReplicas != 2 {
+		t.Fatalf("unexpected replica details: %d, %d, %d, %d", *specReplicas, *specPartition, statusReplicas, statusReadyReplicas)
+	}
+}
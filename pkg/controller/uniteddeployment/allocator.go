@@ -27,9 +27,11 @@ import (
 )
 
 type nameToReplicas struct {
-	SubsetName string
-	Replicas   int32
-	Specified  bool
+	SubsetName  string
+	Replicas    int32
+	Specified   bool
+	MinReplicas int32
+	Priority    int32
 }
 
 type subsetInfos []*nameToReplicas
@@ -57,12 +59,36 @@ func (n subsetInfos) Swap(i, j int) {
 // GetAllocatedReplicas returns a mapping from subset to next replicas.
 // Next replicas is allocated by replicasAllocator, which will consider the current replicas of each subset and
 // new replicas indicated from UnitedDeployment.Spec.Topology.Subsets.
+// The subsets in UnitedDeployment.Spec.SubsetAutoscaling are excluded from the allocation,
+// whose replicas are decided by their own autoscalers.
+// With the Adaptive schedule strategy, the replicas of the unschedulable subsets are moved to the schedulable ones.
+// The subsets of Advanced DaemonSet are not allocated, whose replicas are decided by the nodes they run on.
 func GetAllocatedReplicas(nameToSubset *map[string]*Subset, ud *appsv1alpha1.UnitedDeployment) (*map[string]int32, error) {
+	if ud.Spec.Template.AdvancedDaemonSetTemplate != nil {
+		return getDaemonSetSubsetReplicas(nameToSubset, ud), nil
+	}
+
 	subsetInfos := getSubsetInfos(nameToSubset, ud)
 	specifiedReplicas := getSpecifiedSubsetReplicas(ud)
+	autoscaledReplicas := getAutoscaledSubsetReplicas(nameToSubset, ud)
+
+	allocatedReplicas := &map[string]int32{}
+	if len(*subsetInfos) > 0 || len(autoscaledReplicas) == 0 {
+		// call SortToAllocator to sort all subset by subset.Replicas in order of increment
+		var err error
+		allocatedReplicas, err = subsetInfos.SortToAllocator().AllocateReplicas(*ud.Spec.Replicas, specifiedReplicas)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if getAdaptiveStrategy(ud) != nil {
+		rescheduleUnschedulableReplicas(nameToSubset, ud, allocatedReplicas)
+	}
 
-	// call SortToAllocator to sort all subset by subset.Replicas in order of increment
-	return subsetInfos.SortToAllocator().AllocateReplicas(*ud.Spec.Replicas, specifiedReplicas)
+	for name, replicas := range autoscaledReplicas {
+		(*allocatedReplicas)[name] = replicas
+	}
+	return allocatedReplicas, nil
 }
 
 func (n subsetInfos) SortToAllocator() *replicasAllocator {
@@ -101,6 +127,17 @@ func (s *replicasAllocator) validateReplicas(replicas int32, subsetReplicasLimit
 		}
 	}
 
+	var minReplicas int32
+	for _, subset := range *s.subsets {
+		if _, exist := (*subsetReplicasLimits)[subset.SubsetName]; !exist {
+			minReplicas += subset.MinReplicas
+		}
+	}
+	if specifiedReplicas+minReplicas > replicas {
+		return fmt.Errorf("specified subsets' replica (%d) and minReplicas of the other subsets (%d) are greater than UnitedDeployment replica (%d)",
+			specifiedReplicas, minReplicas, replicas)
+	}
+
 	return nil
 }
 
@@ -111,7 +148,7 @@ func getSpecifiedSubsetReplicas(ud *appsv1alpha1.UnitedDeployment) *map[string]i
 	}
 
 	for _, subsetDef := range ud.Spec.Topology.Subsets {
-		if subsetDef.Replicas == nil {
+		if subsetDef.Replicas == nil || getSubsetAutoscaling(ud, subsetDef.Name) != nil {
 			continue
 		}
 
@@ -126,21 +163,43 @@ func getSpecifiedSubsetReplicas(ud *appsv1alpha1.UnitedDeployment) *map[string]i
 	return &replicaLimits
 }
 
+// getDaemonSetSubsetReplicas returns the current replicas of the subsets of daemon sets,
+// which are decided by the nodes they run on.
+func getDaemonSetSubsetReplicas(nameToSubset *map[string]*Subset, ud *appsv1alpha1.UnitedDeployment) *map[string]int32 {
+	replicas := map[string]int32{}
+	for _, subsetDef := range ud.Spec.Topology.Subsets {
+		if subset, exist := (*nameToSubset)[subsetDef.Name]; exist {
+			replicas[subsetDef.Name] = subset.Spec.Replicas
+		} else {
+			replicas[subsetDef.Name] = 0
+		}
+	}
+	return &replicas
+}
+
 func getSubsetInfos(nameToSubset *map[string]*Subset, ud *appsv1alpha1.UnitedDeployment) *subsetInfos {
-	infos := make(subsetInfos, len(ud.Spec.Topology.Subsets))
-	for idx, subsetDef := range ud.Spec.Topology.Subsets {
+	infos := make(subsetInfos, 0, len(ud.Spec.Topology.Subsets))
+	for _, subsetDef := range ud.Spec.Topology.Subsets {
+		if getSubsetAutoscaling(ud, subsetDef.Name) != nil {
+			continue
+		}
 		var replicas int32
 		if subset, exist := (*nameToSubset)[subsetDef.Name]; exist {
 			replicas = subset.Spec.Replicas
 		}
-		infos[idx] = &nameToReplicas{SubsetName: subsetDef.Name, Replicas: replicas}
+		info := &nameToReplicas{SubsetName: subsetDef.Name, Replicas: replicas, Priority: subsetDef.Priority}
+		if subsetDef.MinReplicas != nil {
+			info.MinReplicas = *subsetDef.MinReplicas
+		}
+		infos = append(infos, info)
 	}
 
 	return &infos
 }
 
 // AllocateReplicas will first try to check the specifiedSubsetReplicas is valid or not.
-// If valid , normalAllocate will be called. It will apply these specified replicas, then average the rest replicas to left unspecified subsets.
+// If valid , normalAllocate will be called. It will apply these specified replicas, then average the rest replicas to left unspecified subsets,
+// but no less than their minReplicas.
 // If not, it will return error
 func (s *replicasAllocator) AllocateReplicas(replicas int32, specifiedSubsetReplicas *map[string]int32) (
 	*map[string]int32, error) {
@@ -165,29 +224,49 @@ func (s *replicasAllocator) normalAllocate(expectedReplicas int32, specifiedSubs
 	}
 
 	// Step 2: averagely allocate the rest replicas to left unspecified subsets.
-	leftSubsetCount := len(*s.subsets) - specifiedSubsetCount
-	if leftSubsetCount != 0 {
-		allocatableReplicas := expectedReplicas - specifiedReplicas
-		average := int(allocatableReplicas) / leftSubsetCount
-		remainder := int(allocatableReplicas) % leftSubsetCount
-
-		for i := len(*s.subsets) - 1; i >= 0; i-- {
-			subset := (*s.subsets)[i]
-			if subset.Specified {
-				continue
-			}
-
-			if remainder > 0 {
-				subset.Replicas = int32(average + 1)
-				remainder--
+	// The subsets whose minReplicas are greater than the average are allocated with their minReplicas first.
+	var leftSubsets []*nameToReplicas
+	for _, subset := range *s.subsets {
+		if !subset.Specified {
+			leftSubsets = append(leftSubsets, subset)
+		}
+	}
+	allocatableReplicas := expectedReplicas - specifiedReplicas
+	for len(leftSubsets) > 0 {
+		average := allocatableReplicas / int32(len(leftSubsets))
+		averageSubsets := make([]*nameToReplicas, 0, len(leftSubsets))
+		for _, subset := range leftSubsets {
+			if subset.MinReplicas > average {
+				subset.Replicas = subset.MinReplicas
+				allocatableReplicas -= subset.MinReplicas
 			} else {
-				subset.Replicas = int32(average)
+				averageSubsets = append(averageSubsets, subset)
 			}
+		}
+		if len(averageSubsets) == len(leftSubsets) {
+			break
+		}
+		leftSubsets = averageSubsets
+	}
+
+	// Step 3: the remainder goes to the subsets with higher priority first, then to the ones with more replicas.
+	if len(leftSubsets) != 0 {
+		average := allocatableReplicas / int32(len(leftSubsets))
+		remainder := int(allocatableReplicas % int32(len(leftSubsets)))
 
-			leftSubsetCount--
+		ordered := make([]*nameToReplicas, 0, len(leftSubsets))
+		for i := len(leftSubsets) - 1; i >= 0; i-- {
+			ordered = append(ordered, leftSubsets[i])
+		}
+		sort.SliceStable(ordered, func(i, j int) bool {
+			return ordered[i].Priority > ordered[j].Priority
+		})
 
-			if leftSubsetCount == 0 {
-				break
+		for i, subset := range ordered {
+			if i < remainder {
+				subset.Replicas = average + 1
+			} else {
+				subset.Replicas = average
 			}
 		}
 	}
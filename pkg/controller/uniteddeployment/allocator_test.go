@@ -197,6 +197,77 @@ func TestSpecifyInvalidReplicas(t *testing.T) {
 	}
 }
 
+func TestAllocateRemainderByPriority(t *testing.T) {
+	infos := subsetInfos{
+		createSubset("t1", 1),
+		createSubset("t2", 4),
+		createSubset("t3", 2),
+		createSubset("t4", 2),
+	}
+	infos[0].Priority = 1
+	allocator := infos.SortToAllocator()
+	allocator.AllocateReplicas(5, &map[string]int32{})
+	if " t2 -> 1; t3 -> 1; t4 -> 1; t1 -> 2;" != allocator.String() {
+		t.Fatalf("unexpected %s", allocator)
+	}
+
+	infos = subsetInfos{
+		createSubset("t1", 1),
+		createSubset("t2", 4),
+		createSubset("t3", 2),
+		createSubset("t4", 2),
+	}
+	infos[2].Priority = 1
+	infos[3].Priority = 1
+	allocator = infos.SortToAllocator()
+	allocator.AllocateReplicas(6, &map[string]int32{})
+	if " t1 -> 1; t2 -> 1; t3 -> 2; t4 -> 2;" != allocator.String() {
+		t.Fatalf("unexpected %s", allocator)
+	}
+}
+
+func TestAllocateWithMinReplicas(t *testing.T) {
+	infos := subsetInfos{
+		createSubset("t1", 0),
+		createSubset("t2", 0),
+		createSubset("t3", 0),
+	}
+	infos[0].MinReplicas = 5
+	allocator := infos.SortToAllocator()
+	allocator.AllocateReplicas(9, &map[string]int32{})
+	if " t2 -> 2; t3 -> 2; t1 -> 5;" != allocator.String() {
+		t.Fatalf("unexpected %s", allocator)
+	}
+
+	infos = subsetInfos{
+		createSubset("t1", 0),
+		createSubset("t2", 0),
+		createSubset("t3", 0),
+	}
+	infos[1].MinReplicas = 3
+	allocator = infos.SortToAllocator()
+	allocator.AllocateReplicas(8, &map[string]int32{
+		"t1": 4,
+	})
+	if " t3 -> 1; t2 -> 3; t1 -> 4;" != allocator.String() {
+		t.Fatalf("unexpected %s", allocator)
+	}
+
+	infos = subsetInfos{
+		createSubset("t1", 1),
+		createSubset("t2", 1),
+	}
+	infos[0].MinReplicas = 3
+	infos[1].MinReplicas = 3
+	allocator = infos.SortToAllocator()
+	if _, err := allocator.AllocateReplicas(5, &map[string]int32{}); err == nil {
+		t.Fatalf("expected error when minReplicas are greater than replicas")
+	}
+	if " t1 -> 1; t2 -> 1;" != allocator.String() {
+		t.Fatalf("unexpected %s", allocator)
+	}
+}
+
 func createSubset(name string, replicas int32) *nameToReplicas {
 	return &nameToReplicas{
 		Replicas:   replicas,
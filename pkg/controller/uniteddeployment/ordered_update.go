@@ -0,0 +1,88 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uniteddeployment
+
+import (
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+)
+
+// getOrderedUpdateSteps returns the steps in OrderedUpdate followed by the subsets not listed in the order of topology.
+func getOrderedUpdateSteps(ud *appsv1alpha1.UnitedDeployment) []appsv1alpha1.OrderedUpdateStep {
+	var steps []appsv1alpha1.OrderedUpdateStep
+	listed := sets.NewString()
+	if ud.Spec.UpdateStrategy.OrderedUpdate != nil {
+		for _, step := range ud.Spec.UpdateStrategy.OrderedUpdate.Steps {
+			if listed.Has(step.Subset) {
+				continue
+			}
+			listed.Insert(step.Subset)
+			steps = append(steps, step)
+		}
+	}
+	for _, subset := range ud.Spec.Topology.Subsets {
+		if !listed.Has(subset.Name) {
+			steps = append(steps, appsv1alpha1.OrderedUpdateStep{Subset: subset.Name})
+		}
+	}
+	return steps
+}
+
+// calcOrderedPartitions calculates the partitions of subsets for the Ordered update strategy.
+// The subsets are updated to the partitions of their steps one by one. The subsets after the step in progress
+// or paused keep their pods not updated yet in the old revision.
+func calcOrderedPartitions(ud *appsv1alpha1.UnitedDeployment, nameToSubset *map[string]*Subset, nextReplicas *map[string]int32) *map[string]int32 {
+	partitions := map[string]int32{}
+	blocked := false
+	for _, step := range getOrderedUpdateSteps(ud) {
+		replicas := (*nextReplicas)[step.Subset]
+		subset, exist := (*nameToSubset)[step.Subset]
+		if blocked {
+			// hold the pods not updated yet, so that the updated pods are not rolled back
+			// and the pods scaled out later are created in the old revision.
+			var partition int32
+			if exist && replicas > subset.Status.UpdatedReplicas {
+				partition = replicas - subset.Status.UpdatedReplicas
+			}
+			partitions[step.Subset] = partition
+			continue
+		}
+
+		var partition int32
+		if step.Partition != nil {
+			partition = *step.Partition
+		}
+		if partition > replicas {
+			partition = replicas
+		}
+		partitions[step.Subset] = partition
+
+		if !exist || step.Pause || !isSubsetUpdatedToPartition(subset, replicas, partition) {
+			blocked = true
+		}
+	}
+	return &partitions
+}
+
+// isSubsetUpdatedToPartition returns whether the pods of the subset out of the partition are all updated and ready.
+func isSubsetUpdatedToPartition(subset *Subset, replicas, partition int32) bool {
+	return subset.Status.ObservedGeneration >= subset.Generation &&
+		subset.Spec.Replicas == replicas &&
+		subset.Spec.UpdateStrategy.Partition == partition &&
+		subset.Status.UpdatedReadyReplicas >= replicas-partition
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uniteddeployment
+
+import (
+	"reflect"
+	"testing"
+
+	utilpointer "k8s.io/utils/pointer"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+)
+
+func TestCalcOrderedPartitions(t *testing.T) {
+	newUD := func(steps ...appsv1alpha1.OrderedUpdateStep) *appsv1alpha1.UnitedDeployment {
+		return &appsv1alpha1.UnitedDeployment{
+			Spec: appsv1alpha1.UnitedDeploymentSpec{
+				Topology: appsv1alpha1.Topology{
+					Subsets: []appsv1alpha1.Subset{{Name: "a"}, {Name: "b"}, {Name: "c"}},
+				},
+				UpdateStrategy: appsv1alpha1.UnitedDeploymentUpdateStrategy{
+					Type:          appsv1alpha1.OrderedUpdateStrategyType,
+					OrderedUpdate: &appsv1alpha1.OrderedUpdate{Steps: steps},
+				},
+			},
+		}
+	}
+	newSubset := func(replicas, partition, updated, updatedReady int32) *Subset {
+		subset := &Subset{}
+		subset.Spec.Replicas = replicas
+		subset.Spec.UpdateStrategy.Partition = partition
+		subset.Status.UpdatedReplicas = updated
+		subset.Status.UpdatedReadyReplicas = updatedReady
+		return subset
+	}
+	nextReplicas := map[string]int32{"a": 4, "b": 4, "c": 4}
+
+	cases := []struct {
+		name         string
+		ud           *appsv1alpha1.UnitedDeployment
+		nameToSubset map[string]*Subset
+		expected     map[string]int32
+	}{
+		{
+			name: "first step in progress",
+			ud:   newUD(appsv1alpha1.OrderedUpdateStep{Subset: "b", Partition: utilpointer.Int32(2)}),
+			nameToSubset: map[string]*Subset{
+				"a": newSubset(4, 4, 0, 0),
+				"b": newSubset(4, 2, 1, 1),
+				"c": newSubset(4, 4, 0, 0),
+			},
+			expected: map[string]int32{"a": 4, "b": 2, "c": 4},
+		},
+		{
+			name: "first step finished and the next ones start in order",
+			ud:   newUD(appsv1alpha1.OrderedUpdateStep{Subset: "b", Partition: utilpointer.Int32(2)}),
+			nameToSubset: map[string]*Subset{
+				"a": newSubset(4, 4, 0, 0),
+				"b": newSubset(4, 2, 2, 2),
+				"c": newSubset(4, 4, 0, 0),
+			},
+			expected: map[string]int32{"a": 0, "b": 2, "c": 4},
+		},
+		{
+			name: "rollout paused after the step",
+			ud:   newUD(appsv1alpha1.OrderedUpdateStep{Subset: "b", Pause: true}),
+			nameToSubset: map[string]*Subset{
+				"a": newSubset(4, 4, 1, 1),
+				"b": newSubset(4, 0, 4, 4),
+				"c": newSubset(4, 4, 0, 0),
+			},
+			expected: map[string]int32{"a": 3, "b": 0, "c": 4},
+		},
+		{
+			name: "all steps finished",
+			ud:   newUD(appsv1alpha1.OrderedUpdateStep{Subset: "c"}),
+			nameToSubset: map[string]*Subset{
+				"a": newSubset(4, 0, 4, 4),
+				"b": newSubset(4, 0, 4, 4),
+				"c": newSubset(4, 0, 4, 4),
+			},
+			expected: map[string]int32{"a": 0, "b": 0, "c": 0},
+		},
+		{
+			name: "subset of the step not created",
+			ud:   newUD(),
+			nameToSubset: map[string]*Subset{
+				"b": newSubset(4, 4, 0, 0),
+				"c": newSubset(4, 4, 0, 0),
+			},
+			expected: map[string]int32{"a": 0, "b": 4, "c": 4},
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			partitions := calcNextPartitions(cs.ud, &cs.nameToSubset, &nextReplicas)
+			if !reflect.DeepEqual(*partitions, cs.expected) {
+				t.Fatalf("expected partitions %v, but got %v", cs.expected, *partitions)
+			}
+		})
+	}
+}
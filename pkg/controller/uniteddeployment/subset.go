@@ -45,6 +45,13 @@ type SubsetStatus struct {
 	ReadyReplicas        int32
 	UpdatedReplicas      int32
 	UpdatedReadyReplicas int32
+	UnschedulableStatus  SubsetUnschedulableStatus
+}
+
+// SubsetUnschedulableStatus stores the schedulable state of the Subset.
+type SubsetUnschedulableStatus struct {
+	Unschedulable bool
+	PendingPods   int32
 }
 
 // SubsetUpdateStrategy stores the strategy detail of the Subset.
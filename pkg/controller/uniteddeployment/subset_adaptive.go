@@ -0,0 +1,243 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uniteddeployment
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+	kubecontroller "k8s.io/kubernetes/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/util"
+)
+
+const (
+	// SubsetPodsPendingReason means the subset has pods unschedulable for more than rescheduleCriticalSeconds.
+	SubsetPodsPendingReason = "PodsPending"
+	// SubsetRecoveredReason means the subset has been unschedulable for unschedulableLastSeconds and is schedulable again.
+	SubsetRecoveredReason = "Recovered"
+
+	defaultRescheduleCriticalSeconds int32 = 30
+	defaultUnschedulableLastSeconds  int32 = 300
+)
+
+func getAdaptiveStrategy(ud *appsv1alpha1.UnitedDeployment) *appsv1alpha1.AdaptiveUnitedDeploymentStrategy {
+	strategy := ud.Spec.Topology.ScheduleStrategy
+	if strategy.Type != appsv1alpha1.AdaptiveUnitedDeploymentScheduleStrategyType {
+		return nil
+	}
+	if strategy.Adaptive == nil {
+		return &appsv1alpha1.AdaptiveUnitedDeploymentStrategy{}
+	}
+	return strategy.Adaptive
+}
+
+func getSubsetCondition(status *appsv1alpha1.UnitedDeploymentSubsetStatus, condType appsv1alpha1.UnitedDeploymentSubsetConditionType) *appsv1alpha1.UnitedDeploymentSubsetCondition {
+	for i := range status.Conditions {
+		if status.Conditions[i].Type == condType {
+			return &status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// setSubsetCondition updates the subset to include the provided condition. The LastTransitionTime is kept
+// if the condition status is not changed.
+func setSubsetCondition(status *appsv1alpha1.UnitedDeploymentSubsetStatus, condition appsv1alpha1.UnitedDeploymentSubsetCondition) {
+	if current := getSubsetCondition(status, condition.Type); current != nil {
+		if current.Status == condition.Status {
+			condition.LastTransitionTime = current.LastTransitionTime
+		}
+		*current = condition
+		return
+	}
+	status.Conditions = append(status.Conditions, condition)
+}
+
+// countPendingPods returns the number of the active pods unschedulable for more than criticalDuration,
+// and the duration after which the other unschedulable pods exceed criticalDuration.
+func countPendingPods(pods []*corev1.Pod, criticalDuration time.Duration, now time.Time) (int32, time.Duration) {
+	var pending int32
+	var wait time.Duration
+	for _, pod := range pods {
+		if !kubecontroller.IsPodActive(pod) {
+			continue
+		}
+		if !isPodUnschedulable(pod) {
+			continue
+		}
+		_, condition := podutil.GetPodCondition(&pod.Status, corev1.PodScheduled)
+		if left := condition.LastTransitionTime.Add(criticalDuration).Sub(now); left > 0 {
+			wait = minPositiveDuration(wait, left)
+			continue
+		}
+		pending++
+	}
+	return pending, wait
+}
+
+func minPositiveDuration(a, b time.Duration) time.Duration {
+	if a <= 0 || (b > 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+// manageUnschedulableSubsets marks the subsets with pods unschedulable for more than rescheduleCriticalSeconds
+// unschedulable, and recovers them after unschedulableLastSeconds. The Schedulable conditions are recorded in
+// ud.Status.SubsetStatuses, and the unschedulable status is set to the subsets for allocating replicas.
+// It returns the duration after which the subsets should be checked again.
+func (r *ReconcileUnitedDeployment) manageUnschedulableSubsets(ud *appsv1alpha1.UnitedDeployment, nameToSubset *map[string]*Subset) (time.Duration, error) {
+	adaptive := getAdaptiveStrategy(ud)
+	if adaptive == nil {
+		ud.Status.SubsetStatuses = nil
+		return 0, nil
+	}
+	criticalDuration := time.Duration(defaultRescheduleCriticalSeconds) * time.Second
+	if adaptive.RescheduleCriticalSeconds != nil {
+		criticalDuration = time.Duration(*adaptive.RescheduleCriticalSeconds) * time.Second
+	}
+	lastDuration := time.Duration(defaultUnschedulableLastSeconds) * time.Second
+	if adaptive.UnschedulableLastSeconds != nil {
+		lastDuration = time.Duration(*adaptive.UnschedulableLastSeconds) * time.Second
+	}
+
+	selector, err := util.GetFastLabelSelector(ud.Spec.Selector)
+	if err != nil {
+		return 0, err
+	}
+	podList := &corev1.PodList{}
+	if err := r.List(context.TODO(), podList, client.InNamespace(ud.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return 0, err
+	}
+	subsetPods := map[string][]*corev1.Pod{}
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		subsetName := pod.Labels[appsv1alpha1.SubSetNameLabelKey]
+		subsetPods[subsetName] = append(subsetPods[subsetName], pod)
+	}
+
+	var requeueAfter time.Duration
+	now := time.Now()
+	subsetStatuses := make([]appsv1alpha1.UnitedDeploymentSubsetStatus, 0, len(ud.Spec.Topology.Subsets))
+	for _, subsetDef := range ud.Spec.Topology.Subsets {
+		subsetStatus := appsv1alpha1.UnitedDeploymentSubsetStatus{Name: subsetDef.Name}
+		for i := range ud.Status.SubsetStatuses {
+			if ud.Status.SubsetStatuses[i].Name == subsetDef.Name {
+				subsetStatus = *ud.Status.SubsetStatuses[i].DeepCopy()
+				break
+			}
+		}
+
+		pending, wait := countPendingPods(subsetPods[subsetDef.Name], criticalDuration, now)
+		requeueAfter = minPositiveDuration(requeueAfter, wait)
+		condition := getSubsetCondition(&subsetStatus, appsv1alpha1.UnitedDeploymentSubsetSchedulable)
+		switch {
+		case pending > 0:
+			if condition == nil || condition.Status != corev1.ConditionFalse {
+				r.recorder.Eventf(ud, corev1.EventTypeWarning, "SubsetUnschedulable",
+					"Subset %s has %d pods unschedulable for more than %s, move the replicas to other subsets", subsetDef.Name, pending, criticalDuration)
+			}
+			setSubsetCondition(&subsetStatus, appsv1alpha1.UnitedDeploymentSubsetCondition{
+				Type:               appsv1alpha1.UnitedDeploymentSubsetSchedulable,
+				Status:             corev1.ConditionFalse,
+				LastTransitionTime: metav1.NewTime(now),
+				Reason:             SubsetPodsPendingReason,
+				Message:            fmt.Sprintf("%d pods are unschedulable for more than %s", pending, criticalDuration),
+			})
+			requeueAfter = minPositiveDuration(requeueAfter, lastDuration)
+		case condition == nil:
+			setSubsetCondition(&subsetStatus, appsv1alpha1.UnitedDeploymentSubsetCondition{
+				Type:               appsv1alpha1.UnitedDeploymentSubsetSchedulable,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: metav1.NewTime(now),
+			})
+		case condition.Status == corev1.ConditionFalse:
+			if left := condition.LastTransitionTime.Add(lastDuration).Sub(now); left > 0 {
+				requeueAfter = minPositiveDuration(requeueAfter, left)
+				break
+			}
+			r.recorder.Eventf(ud, corev1.EventTypeNormal, "SubsetSchedulable",
+				"Subset %s has been unschedulable for %s, allow the replicas to move back", subsetDef.Name, lastDuration)
+			setSubsetCondition(&subsetStatus, appsv1alpha1.UnitedDeploymentSubsetCondition{
+				Type:               appsv1alpha1.UnitedDeploymentSubsetSchedulable,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: metav1.NewTime(now),
+				Reason:             SubsetRecoveredReason,
+			})
+		}
+
+		if subset, exist := (*nameToSubset)[subsetDef.Name]; exist {
+			condition = getSubsetCondition(&subsetStatus, appsv1alpha1.UnitedDeploymentSubsetSchedulable)
+			subset.Status.UnschedulableStatus = SubsetUnschedulableStatus{
+				Unschedulable: condition.Status == corev1.ConditionFalse,
+				PendingPods:   pending,
+			}
+		}
+		subsetStatuses = append(subsetStatuses, subsetStatus)
+	}
+	ud.Status.SubsetStatuses = subsetStatuses
+	return requeueAfter, nil
+}
+
+// rescheduleUnschedulableReplicas limits the replicas of the unschedulable subsets to the pods not pending in them,
+// and moves the rest replicas to the schedulable subsets evenly in the order of topology.
+// The subsets with autoscaling are not taken into account.
+func rescheduleUnschedulableReplicas(nameToSubset *map[string]*Subset, ud *appsv1alpha1.UnitedDeployment, allocatedReplicas *map[string]int32) {
+	limits := map[string]int32{}
+	var schedulable []string
+	for _, subsetDef := range ud.Spec.Topology.Subsets {
+		if getSubsetAutoscaling(ud, subsetDef.Name) != nil {
+			continue
+		}
+		subset, exist := (*nameToSubset)[subsetDef.Name]
+		if !exist || !subset.Status.UnschedulableStatus.Unschedulable {
+			schedulable = append(schedulable, subsetDef.Name)
+			continue
+		}
+		limit := subset.Spec.Replicas - subset.Status.UnschedulableStatus.PendingPods
+		if limit < 0 {
+			limit = 0
+		}
+		limits[subsetDef.Name] = limit
+	}
+	if len(schedulable) == 0 {
+		return
+	}
+
+	var moved int32
+	for name, limit := range limits {
+		if replicas := (*allocatedReplicas)[name]; replicas > limit {
+			moved += replicas - limit
+			(*allocatedReplicas)[name] = limit
+		}
+	}
+	average := moved / int32(len(schedulable))
+	remainder := moved % int32(len(schedulable))
+	for i, name := range schedulable {
+		(*allocatedReplicas)[name] += average
+		if int32(i) < remainder {
+			(*allocatedReplicas)[name]++
+		}
+	}
+}
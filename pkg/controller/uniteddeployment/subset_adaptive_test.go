@@ -0,0 +1,242 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uniteddeployment
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	utilpointer "k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+)
+
+func newAdaptiveUnitedDeployment() *appsv1alpha1.UnitedDeployment {
+	return &appsv1alpha1.UnitedDeployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "ud"},
+		Spec: appsv1alpha1.UnitedDeploymentSpec{
+			Replicas: utilpointer.Int32(6),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "demo"}},
+			Topology: appsv1alpha1.Topology{
+				Subsets: []appsv1alpha1.Subset{{Name: "a"}, {Name: "b"}, {Name: "c"}},
+				ScheduleStrategy: appsv1alpha1.UnitedDeploymentScheduleStrategy{
+					Type: appsv1alpha1.AdaptiveUnitedDeploymentScheduleStrategyType,
+					Adaptive: &appsv1alpha1.AdaptiveUnitedDeploymentStrategy{
+						RescheduleCriticalSeconds: utilpointer.Int32(10),
+						UnschedulableLastSeconds:  utilpointer.Int32(60),
+					},
+				},
+			},
+		},
+	}
+}
+
+func newSubsetPod(name, subsetName string, unschedulableSince time.Duration) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      name,
+			Labels:    map[string]string{"app": "demo", appsv1alpha1.SubSetNameLabelKey: subsetName},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	if unschedulableSince > 0 {
+		pod.Status.Phase = corev1.PodPending
+		pod.Status.Conditions = []corev1.PodCondition{{
+			Type:               corev1.PodScheduled,
+			Status:             corev1.ConditionFalse,
+			Reason:             corev1.PodReasonUnschedulable,
+			LastTransitionTime: metav1.NewTime(time.Now().Add(-unschedulableSince)),
+		}}
+	}
+	return pod
+}
+
+func TestManageUnschedulableSubsets(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = appsv1alpha1.AddToScheme(scheme)
+
+	cases := []struct {
+		name                  string
+		subsetStatuses        []appsv1alpha1.UnitedDeploymentSubsetStatus
+		pods                  []client.Object
+		expectedSchedulable   map[string]corev1.ConditionStatus
+		expectedUnschedulable map[string]SubsetUnschedulableStatus
+	}{
+		{
+			name: "pods pending longer than rescheduleCriticalSeconds",
+			pods: []client.Object{
+				newSubsetPod("a-0", "a", 0),
+				newSubsetPod("a-1", "a", time.Minute),
+				newSubsetPod("a-2", "a", time.Minute),
+				newSubsetPod("b-0", "b", time.Second),
+			},
+			expectedSchedulable: map[string]corev1.ConditionStatus{"a": corev1.ConditionFalse, "b": corev1.ConditionTrue, "c": corev1.ConditionTrue},
+			expectedUnschedulable: map[string]SubsetUnschedulableStatus{
+				"a": {Unschedulable: true, PendingPods: 2},
+				"b": {},
+				"c": {},
+			},
+		},
+		{
+			name: "subset keeps unschedulable within unschedulableLastSeconds",
+			subsetStatuses: []appsv1alpha1.UnitedDeploymentSubsetStatus{{Name: "a", Conditions: []appsv1alpha1.UnitedDeploymentSubsetCondition{{
+				Type:               appsv1alpha1.UnitedDeploymentSubsetSchedulable,
+				Status:             corev1.ConditionFalse,
+				LastTransitionTime: metav1.NewTime(time.Now().Add(-30 * time.Second)),
+			}}}},
+			pods:                  []client.Object{newSubsetPod("a-0", "a", 0)},
+			expectedSchedulable:   map[string]corev1.ConditionStatus{"a": corev1.ConditionFalse, "b": corev1.ConditionTrue, "c": corev1.ConditionTrue},
+			expectedUnschedulable: map[string]SubsetUnschedulableStatus{"a": {Unschedulable: true}, "b": {}, "c": {}},
+		},
+		{
+			name: "subset recovers after unschedulableLastSeconds",
+			subsetStatuses: []appsv1alpha1.UnitedDeploymentSubsetStatus{{Name: "a", Conditions: []appsv1alpha1.UnitedDeploymentSubsetCondition{{
+				Type:               appsv1alpha1.UnitedDeploymentSubsetSchedulable,
+				Status:             corev1.ConditionFalse,
+				LastTransitionTime: metav1.NewTime(time.Now().Add(-2 * time.Minute)),
+			}}}},
+			pods:                  []client.Object{newSubsetPod("a-0", "a", 0)},
+			expectedSchedulable:   map[string]corev1.ConditionStatus{"a": corev1.ConditionTrue, "b": corev1.ConditionTrue, "c": corev1.ConditionTrue},
+			expectedUnschedulable: map[string]SubsetUnschedulableStatus{"a": {}, "b": {}, "c": {}},
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			ud := newAdaptiveUnitedDeployment()
+			ud.Status.SubsetStatuses = cs.subsetStatuses
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cs.pods...).Build()
+			r := &ReconcileUnitedDeployment{Client: fakeClient, scheme: scheme, recorder: record.NewFakeRecorder(10)}
+			nameToSubset := map[string]*Subset{
+				"a": {Spec: SubsetSpec{SubsetName: "a", Replicas: 3}},
+				"b": {Spec: SubsetSpec{SubsetName: "b", Replicas: 2}},
+				"c": {Spec: SubsetSpec{SubsetName: "c", Replicas: 1}},
+			}
+
+			if _, err := r.manageUnschedulableSubsets(ud, &nameToSubset); err != nil {
+				t.Fatalf("failed to manage unschedulable subsets: %v", err)
+			}
+			if len(ud.Status.SubsetStatuses) != len(ud.Spec.Topology.Subsets) {
+				t.Fatalf("expected %d subset statuses, but got %v", len(ud.Spec.Topology.Subsets), ud.Status.SubsetStatuses)
+			}
+			for i := range ud.Status.SubsetStatuses {
+				subsetStatus := &ud.Status.SubsetStatuses[i]
+				condition := getSubsetCondition(subsetStatus, appsv1alpha1.UnitedDeploymentSubsetSchedulable)
+				if condition == nil || condition.Status != cs.expectedSchedulable[subsetStatus.Name] {
+					t.Fatalf("expected subset %s schedulable %s, but got %v", subsetStatus.Name, cs.expectedSchedulable[subsetStatus.Name], condition)
+				}
+			}
+			for name, subset := range nameToSubset {
+				if subset.Status.UnschedulableStatus != cs.expectedUnschedulable[name] {
+					t.Fatalf("expected subset %s unschedulable status %v, but got %v", name, cs.expectedUnschedulable[name], subset.Status.UnschedulableStatus)
+				}
+			}
+		})
+	}
+}
+
+func TestGetAllocatedReplicasWithUnschedulableSubsets(t *testing.T) {
+	newSubset := func(name string, replicas int32, unschedulable bool, pending int32) *Subset {
+		subset := &Subset{Spec: SubsetSpec{SubsetName: name, Replicas: replicas}}
+		subset.Status.UnschedulableStatus = SubsetUnschedulableStatus{Unschedulable: unschedulable, PendingPods: pending}
+		return subset
+	}
+
+	cases := []struct {
+		name         string
+		getUD        func() *appsv1alpha1.UnitedDeployment
+		nameToSubset map[string]*Subset
+		expected     map[string]int32
+	}{
+		{
+			name:  "all subsets are schedulable",
+			getUD: newAdaptiveUnitedDeployment,
+			nameToSubset: map[string]*Subset{
+				"a": newSubset("a", 2, false, 0),
+				"b": newSubset("b", 2, false, 0),
+				"c": newSubset("c", 2, false, 0),
+			},
+			expected: map[string]int32{"a": 2, "b": 2, "c": 2},
+		},
+		{
+			name:  "pending replicas are moved to schedulable subsets",
+			getUD: newAdaptiveUnitedDeployment,
+			nameToSubset: map[string]*Subset{
+				"a": newSubset("a", 2, true, 2),
+				"b": newSubset("b", 2, false, 0),
+				"c": newSubset("c", 2, false, 0),
+			},
+			expected: map[string]int32{"a": 0, "b": 3, "c": 3},
+		},
+		{
+			name:  "moved replicas are kept during unschedulable",
+			getUD: newAdaptiveUnitedDeployment,
+			nameToSubset: map[string]*Subset{
+				"a": newSubset("a", 1, true, 0),
+				"b": newSubset("b", 3, false, 0),
+				"c": newSubset("c", 2, false, 0),
+			},
+			expected: map[string]int32{"a": 1, "b": 3, "c": 2},
+		},
+		{
+			name: "fixed strategy does not move replicas",
+			getUD: func() *appsv1alpha1.UnitedDeployment {
+				ud := newAdaptiveUnitedDeployment()
+				ud.Spec.Topology.ScheduleStrategy = appsv1alpha1.UnitedDeploymentScheduleStrategy{}
+				return ud
+			},
+			nameToSubset: map[string]*Subset{
+				"a": newSubset("a", 2, true, 2),
+				"b": newSubset("b", 2, false, 0),
+				"c": newSubset("c", 2, false, 0),
+			},
+			expected: map[string]int32{"a": 2, "b": 2, "c": 2},
+		},
+		{
+			name:  "no schedulable subsets",
+			getUD: newAdaptiveUnitedDeployment,
+			nameToSubset: map[string]*Subset{
+				"a": newSubset("a", 2, true, 2),
+				"b": newSubset("b", 2, true, 1),
+				"c": newSubset("c", 2, true, 2),
+			},
+			expected: map[string]int32{"a": 2, "b": 2, "c": 2},
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			allocated, err := GetAllocatedReplicas(&cs.nameToSubset, cs.getUD())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(*allocated, cs.expected) {
+				t.Fatalf("expected allocated replicas %v, but got %v", cs.expected, *allocated)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uniteddeployment
+
+import (
+	"context"
+	"fmt"
+
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog/v2"
+	utilpointer "k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	appsv1beta1 "github.com/openkruise/kruise/apis/apps/v1beta1"
+)
+
+const (
+	eventTypeSubsetAutoscalerSync = "SyncSubsetAutoscaler"
+
+	defaultSubsetAutoscalingCPUUtilization int32 = 80
+)
+
+func getSubsetAutoscaling(ud *appsv1alpha1.UnitedDeployment, subsetName string) *appsv1alpha1.SubsetAutoscaling {
+	for i := range ud.Spec.SubsetAutoscaling {
+		if ud.Spec.SubsetAutoscaling[i].Name == subsetName {
+			return &ud.Spec.SubsetAutoscaling[i]
+		}
+	}
+	return nil
+}
+
+// getAutoscaledSubsetReplicas returns the next replicas of the subsets scaled by their own autoscalers.
+// The current replicas of the subset are kept if they are within the bounds of autoscaling,
+// and the subset to create starts with its minReplicas.
+func getAutoscaledSubsetReplicas(nameToSubset *map[string]*Subset, ud *appsv1alpha1.UnitedDeployment) map[string]int32 {
+	replicas := map[string]int32{}
+	for _, subsetDef := range ud.Spec.Topology.Subsets {
+		autoscaling := getSubsetAutoscaling(ud, subsetDef.Name)
+		if autoscaling == nil {
+			continue
+		}
+
+		minReplicas := utilpointer.Int32Deref(autoscaling.MinReplicas, 1)
+		next := minReplicas
+		if subset, exist := (*nameToSubset)[subsetDef.Name]; exist {
+			next = subset.Spec.Replicas
+		}
+		if next < minReplicas {
+			next = minReplicas
+		} else if next > autoscaling.MaxReplicas {
+			next = autoscaling.MaxReplicas
+		}
+		replicas[subsetDef.Name] = next
+	}
+	return replicas
+}
+
+func getSubsetAutoscalerName(ud *appsv1alpha1.UnitedDeployment, subsetName string) string {
+	return fmt.Sprintf("%s-%s", ud.Name, subsetName)
+}
+
+func getSubsetScaleTargetRef(subset *Subset, subsetType subSetType) autoscalingv2beta2.CrossVersionObjectReference {
+	ref := autoscalingv2beta2.CrossVersionObjectReference{Name: subset.Name}
+	switch subsetType {
+	case statefulSetSubSetType:
+		ref.APIVersion, ref.Kind = "apps/v1", "StatefulSet"
+	case advancedStatefulSetSubSetType:
+		ref.APIVersion, ref.Kind = appsv1beta1.GroupVersion.String(), "StatefulSet"
+	case cloneSetSubSetType:
+		ref.APIVersion, ref.Kind = appsv1alpha1.GroupVersion.String(), "CloneSet"
+	case deploymentSubSetType:
+		ref.APIVersion, ref.Kind = "apps/v1", "Deployment"
+	}
+	return ref
+}
+
+func newSubsetAutoscaler(ud *appsv1alpha1.UnitedDeployment, autoscaling *appsv1alpha1.SubsetAutoscaling, subset *Subset, subsetType subSetType) *autoscalingv2beta2.HorizontalPodAutoscaler {
+	hpa := &autoscalingv2beta2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: ud.Namespace,
+			Name:      getSubsetAutoscalerName(ud, autoscaling.Name),
+			Labels:    map[string]string{appsv1alpha1.SubSetNameLabelKey: autoscaling.Name},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(ud, controllerKind),
+			},
+		},
+		Spec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: getSubsetScaleTargetRef(subset, subsetType),
+			MinReplicas:    utilpointer.Int32(utilpointer.Int32Deref(autoscaling.MinReplicas, 1)),
+			MaxReplicas:    autoscaling.MaxReplicas,
+		},
+	}
+	metrics := autoscaling.Metrics
+	if len(metrics) == 0 {
+		metrics = []appsv1alpha1.SubsetAutoscalingMetric{{
+			Resource:                 corev1.ResourceCPU,
+			TargetAverageUtilization: utilpointer.Int32(defaultSubsetAutoscalingCPUUtilization),
+		}}
+	}
+	for i := range metrics {
+		hpa.Spec.Metrics = append(hpa.Spec.Metrics, convertSubsetAutoscalingMetric(&metrics[i]))
+	}
+	return hpa
+}
+
+func convertSubsetAutoscalingMetric(metric *appsv1alpha1.SubsetAutoscalingMetric) autoscalingv2beta2.MetricSpec {
+	target := autoscalingv2beta2.MetricTarget{}
+	if metric.TargetAverageUtilization != nil {
+		target.Type = autoscalingv2beta2.UtilizationMetricType
+		target.AverageUtilization = utilpointer.Int32(*metric.TargetAverageUtilization)
+	} else if metric.TargetAverageValue != nil {
+		target.Type = autoscalingv2beta2.AverageValueMetricType
+		value := metric.TargetAverageValue.DeepCopy()
+		target.AverageValue = &value
+	}
+	return autoscalingv2beta2.MetricSpec{
+		Type: autoscalingv2beta2.ResourceMetricSourceType,
+		Resource: &autoscalingv2beta2.ResourceMetricSource{
+			Name:   metric.Resource,
+			Target: target,
+		},
+	}
+}
+
+// isSubsetAutoscalerUpdated returns whether the existing autoscaler matches the desired one.
+// The fields unset in the desired autoscaler are ignored, for they may be defaulted by apiserver.
+func isSubsetAutoscalerUpdated(desired, existing *autoscalingv2beta2.HorizontalPodAutoscaler) bool {
+	if desired.Spec.Behavior == nil && existing.Spec.Behavior != nil {
+		return false
+	}
+	return apiequality.Semantic.DeepDerivative(desired.Spec, existing.Spec)
+}
+
+// syncSubsetAutoscalers creates or updates the HorizontalPodAutoscalers of the subsets in SubsetAutoscaling,
+// and deletes the ones of the subsets no longer autoscaled.
+func (r *ReconcileUnitedDeployment) syncSubsetAutoscalers(ud *appsv1alpha1.UnitedDeployment, nameToSubset *map[string]*Subset, subsetType subSetType) error {
+	hpaList := &autoscalingv2beta2.HorizontalPodAutoscalerList{}
+	if err := r.List(context.TODO(), hpaList, client.InNamespace(ud.Namespace), client.HasLabels{appsv1alpha1.SubSetNameLabelKey}); err != nil {
+		return err
+	}
+	existing := map[string]*autoscalingv2beta2.HorizontalPodAutoscaler{}
+	for i := range hpaList.Items {
+		hpa := &hpaList.Items[i]
+		if owner := metav1.GetControllerOf(hpa); owner == nil || owner.UID != ud.UID {
+			continue
+		}
+		existing[hpa.Name] = hpa
+	}
+
+	var errs []error
+	for i := range ud.Spec.SubsetAutoscaling {
+		autoscaling := &ud.Spec.SubsetAutoscaling[i]
+		name := getSubsetAutoscalerName(ud, autoscaling.Name)
+		subset, exist := (*nameToSubset)[autoscaling.Name]
+		if !exist {
+			// the autoscaler will be created after the subset is provisioned
+			continue
+		}
+
+		desired := newSubsetAutoscaler(ud, autoscaling, subset, subsetType)
+		hpa, found := existing[name]
+		delete(existing, name)
+		if !found {
+			if err := r.Create(context.TODO(), desired); err != nil && !errors.IsAlreadyExists(err) {
+				errs = append(errs, fmt.Errorf("fail to create HorizontalPodAutoscaler %s for subset %s: %s", name, autoscaling.Name, err))
+				continue
+			}
+			klog.V(3).Infof("UnitedDeployment %s/%s created HorizontalPodAutoscaler %s for subset %s", ud.Namespace, ud.Name, name, autoscaling.Name)
+			continue
+		}
+		if isSubsetAutoscalerUpdated(desired, hpa) {
+			continue
+		}
+
+		hpa = hpa.DeepCopy()
+		hpa.Labels = desired.Labels
+		hpa.Spec = desired.Spec
+		if err := r.Update(context.TODO(), hpa); err != nil {
+			errs = append(errs, fmt.Errorf("fail to update HorizontalPodAutoscaler %s for subset %s: %s", name, autoscaling.Name, err))
+			continue
+		}
+		klog.V(3).Infof("UnitedDeployment %s/%s updated HorizontalPodAutoscaler %s for subset %s", ud.Namespace, ud.Name, name, autoscaling.Name)
+	}
+
+	for name, hpa := range existing {
+		if err := r.Delete(context.TODO(), hpa); err != nil && !errors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("fail to delete HorizontalPodAutoscaler %s: %s", name, err))
+			continue
+		}
+		klog.V(3).Infof("UnitedDeployment %s/%s deleted HorizontalPodAutoscaler %s", ud.Namespace, ud.Name, name)
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
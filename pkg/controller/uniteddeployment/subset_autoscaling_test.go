@@ -0,0 +1,166 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uniteddeployment
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	utilpointer "k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+)
+
+func newAutoscalingUnitedDeployment() *appsv1alpha1.UnitedDeployment {
+	return &appsv1alpha1.UnitedDeployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "ud", UID: "ud-uid"},
+		Spec: appsv1alpha1.UnitedDeploymentSpec{
+			Replicas: utilpointer.Int32(5),
+			Topology: appsv1alpha1.Topology{
+				Subsets: []appsv1alpha1.Subset{{Name: "a"}, {Name: "b"}, {Name: "c"}},
+			},
+			SubsetAutoscaling: []appsv1alpha1.SubsetAutoscaling{
+				{Name: "c", MinReplicas: utilpointer.Int32(2), MaxReplicas: 6},
+			},
+		},
+	}
+}
+
+func TestGetAllocatedReplicasWithSubsetAutoscaling(t *testing.T) {
+	newSubset := func(name string, replicas int32) *Subset {
+		return &Subset{ObjectMeta: metav1.ObjectMeta{Name: "ud-" + name}, Spec: SubsetSpec{SubsetName: name, Replicas: replicas}}
+	}
+
+	cases := []struct {
+		name         string
+		getUD        func() *appsv1alpha1.UnitedDeployment
+		nameToSubset map[string]*Subset
+		expected     map[string]int32
+	}{
+		{
+			name:         "autoscaled subset to create starts with minReplicas",
+			getUD:        newAutoscalingUnitedDeployment,
+			nameToSubset: map[string]*Subset{},
+			expected:     map[string]int32{"a": 2, "b": 3, "c": 2},
+		},
+		{
+			name:         "autoscaled subset keeps current replicas",
+			getUD:        newAutoscalingUnitedDeployment,
+			nameToSubset: map[string]*Subset{"a": newSubset("a", 3), "b": newSubset("b", 2), "c": newSubset("c", 4)},
+			expected:     map[string]int32{"a": 3, "b": 2, "c": 4},
+		},
+		{
+			name:         "autoscaled subset is limited by maxReplicas",
+			getUD:        newAutoscalingUnitedDeployment,
+			nameToSubset: map[string]*Subset{"c": newSubset("c", 10)},
+			expected:     map[string]int32{"a": 2, "b": 3, "c": 6},
+		},
+		{
+			name: "all subsets are autoscaled",
+			getUD: func() *appsv1alpha1.UnitedDeployment {
+				ud := newAutoscalingUnitedDeployment()
+				ud.Spec.Topology.Subsets = ud.Spec.Topology.Subsets[2:]
+				return ud
+			},
+			nameToSubset: map[string]*Subset{"c": newSubset("c", 1)},
+			expected:     map[string]int32{"c": 2},
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			allocated, err := GetAllocatedReplicas(&cs.nameToSubset, cs.getUD())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(*allocated, cs.expected) {
+				t.Fatalf("expected allocated replicas %v, but got %v", cs.expected, *allocated)
+			}
+		})
+	}
+}
+
+func TestSyncSubsetAutoscalers(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = appsv1alpha1.AddToScheme(scheme)
+
+	ud := newAutoscalingUnitedDeployment()
+	ud.Spec.SubsetAutoscaling[0].Metrics = []appsv1alpha1.SubsetAutoscalingMetric{
+		{Resource: corev1.ResourceMemory, TargetAverageValue: resource.NewQuantity(1<<30, resource.BinarySI)},
+	}
+	staleHPA := &autoscalingv2beta2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       ud.Namespace,
+			Name:            getSubsetAutoscalerName(ud, "b"),
+			Labels:          map[string]string{appsv1alpha1.SubSetNameLabelKey: "b"},
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(ud, controllerKind)},
+		},
+	}
+	otherHPA := staleHPA.DeepCopy()
+	otherHPA.Name = "other"
+	otherHPA.OwnerReferences = nil
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(ud, staleHPA, otherHPA).Build()
+	r := &ReconcileUnitedDeployment{Client: fakeClient, scheme: scheme, recorder: record.NewFakeRecorder(10)}
+	nameToSubset := map[string]*Subset{
+		"b": {ObjectMeta: metav1.ObjectMeta{Name: "ud-b-xxx"}, Spec: SubsetSpec{SubsetName: "b"}},
+		"c": {ObjectMeta: metav1.ObjectMeta{Name: "ud-c-xxx"}, Spec: SubsetSpec{SubsetName: "c"}},
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := r.syncSubsetAutoscalers(ud, &nameToSubset, cloneSetSubSetType); err != nil {
+			t.Fatalf("failed to sync subset autoscalers: %v", err)
+		}
+	}
+
+	hpaList := &autoscalingv2beta2.HorizontalPodAutoscalerList{}
+	if err := fakeClient.List(context.TODO(), hpaList); err != nil {
+		t.Fatalf("failed to list hpa: %v", err)
+	}
+	if len(hpaList.Items) != 2 {
+		t.Fatalf("expected 2 hpa, but got %d", len(hpaList.Items))
+	}
+	for _, hpa := range hpaList.Items {
+		switch hpa.Name {
+		case "other":
+		case getSubsetAutoscalerName(ud, "c"):
+			expectedRef := autoscalingv2beta2.CrossVersionObjectReference{APIVersion: "apps.kruise.io/v1alpha1", Kind: "CloneSet", Name: "ud-c-xxx"}
+			if hpa.Spec.ScaleTargetRef != expectedRef {
+				t.Fatalf("expected scaleTargetRef %v, but got %v", expectedRef, hpa.Spec.ScaleTargetRef)
+			}
+			if *hpa.Spec.MinReplicas != 2 || hpa.Spec.MaxReplicas != 6 || len(hpa.Spec.Metrics) != 1 {
+				t.Fatalf("unexpected hpa spec %v", hpa.Spec)
+			}
+			target := hpa.Spec.Metrics[0].Resource.Target
+			if hpa.Spec.Metrics[0].Resource.Name != corev1.ResourceMemory || target.Type != autoscalingv2beta2.AverageValueMetricType || target.AverageValue.String() != "1Gi" {
+				t.Fatalf("unexpected hpa metrics %v", hpa.Spec.Metrics)
+			}
+		default:
+			t.Fatalf("unexpected hpa %s", hpa.Name)
+		}
+	}
+}
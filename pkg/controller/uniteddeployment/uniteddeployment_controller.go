@@ -29,6 +29,7 @@ import (
 	utildiscovery "github.com/openkruise/kruise/pkg/util/discovery"
 	"github.com/openkruise/kruise/pkg/util/ratelimiter"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -70,6 +71,7 @@ const (
 	advancedStatefulSetSubSetType subSetType = "AdvancedStatefulSet"
 	cloneSetSubSetType            subSetType = "CloneSet"
 	deploymentSubSetType          subSetType = "Deployment"
+	advancedDaemonSetSubSetType   subSetType = "AdvancedDaemonSet"
 )
 
 // Add creates a new UnitedDeployment Controller and adds it to the Manager with default RBAC. The Manager will set fields on the Controller
@@ -94,6 +96,7 @@ func newReconciler(mgr manager.Manager) reconcile.Reconciler {
 			advancedStatefulSetSubSetType: &SubsetControl{Client: cli, scheme: mgr.GetScheme(), adapter: &adapter.AdvancedStatefulSetAdapter{Client: cli, Scheme: mgr.GetScheme()}},
 			cloneSetSubSetType:            &SubsetControl{Client: cli, scheme: mgr.GetScheme(), adapter: &adapter.CloneSetAdapter{Client: cli, Scheme: mgr.GetScheme()}},
 			deploymentSubSetType:          &SubsetControl{Client: cli, scheme: mgr.GetScheme(), adapter: &adapter.DeploymentAdapter{Client: cli, Scheme: mgr.GetScheme()}},
+			advancedDaemonSetSubSetType:   &SubsetControl{Client: cli, scheme: mgr.GetScheme(), adapter: &adapter.AdvancedDaemonSetAdapter{Client: cli, Scheme: mgr.GetScheme()}},
 		},
 	}
 }
@@ -146,6 +149,28 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	err = c.Watch(&source.Kind{Type: &appsv1alpha1.DaemonSet{}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &appsv1alpha1.UnitedDeployment{},
+	})
+	if err != nil {
+		return err
+	}
+
+	err = c.Watch(&source.Kind{Type: &autoscalingv2beta2.HorizontalPodAutoscaler{}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &appsv1alpha1.UnitedDeployment{},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Watch for pods becoming unschedulable, for the Adaptive schedule strategy
+	err = c.Watch(&source.Kind{Type: &corev1.Pod{}}, &enqueueRequestForPod{reader: mgr.GetCache()})
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -168,8 +193,12 @@ type ReconcileUnitedDeployment struct {
 // +kubebuilder:rbac:groups=apps.kruise.io,resources=statefulsets/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps,resources=deployments/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=apps.kruise.io,resources=daemonsets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps.kruise.io,resources=daemonsets/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get;list;watch
 // +kubebuilder:rbac:groups=apps,resources=replicasets/status,verbs=get
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile reads that state of the cluster for a UnitedDeployment object and makes changes based on the state read
 // and what is in the UnitedDeployment.Spec
@@ -212,6 +241,12 @@ func (r *ReconcileUnitedDeployment) Reconcile(_ context.Context, request reconci
 		return reconcile.Result{}, nil
 	}
 
+	requeueAfter, err := r.manageUnschedulableSubsets(instance, nameToSubset)
+	if err != nil {
+		klog.Errorf("Fail to check unschedulable Subsets of UnitedDeployment %s/%s: %s", instance.Namespace, instance.Name, err)
+		return reconcile.Result{}, err
+	}
+
 	nextReplicas, err := GetAllocatedReplicas(nameToSubset, instance)
 	klog.V(4).Infof("Get UnitedDeployment %s/%s next replicas %v", instance.Namespace, instance.Name, nextReplicas)
 	if err != nil {
@@ -222,7 +257,7 @@ func (r *ReconcileUnitedDeployment) Reconcile(_ context.Context, request reconci
 		return reconcile.Result{}, nil
 	}
 
-	nextPartitions := calcNextPartitions(instance, nextReplicas)
+	nextPartitions := calcNextPartitions(instance, nameToSubset, nextReplicas)
 	klog.V(4).Infof("Get UnitedDeployment %s/%s next partition %v", instance.Namespace, instance.Name, nextPartitions)
 
 	newStatus, err := r.manageSubsets(instance, nameToSubset, nextReplicas, nextPartitions, currentRevision, updatedRevision, subsetType)
@@ -231,7 +266,16 @@ func (r *ReconcileUnitedDeployment) Reconcile(_ context.Context, request reconci
 		r.recorder.Event(instance.DeepCopy(), corev1.EventTypeWarning, fmt.Sprintf("Failed%s", eventTypeSubsetsUpdate), err.Error())
 	}
 
-	return r.updateStatus(instance, newStatus, oldStatus, nameToSubset, nextReplicas, nextPartitions, currentRevision, updatedRevision, collisionCount, control)
+	if err := r.syncSubsetAutoscalers(instance, nameToSubset, subsetType); err != nil {
+		klog.Errorf("Fail to sync subset autoscalers of UnitedDeployment %s/%s: %s", instance.Namespace, instance.Name, err)
+		r.recorder.Event(instance.DeepCopy(), corev1.EventTypeWarning, fmt.Sprintf("Failed%s", eventTypeSubsetAutoscalerSync), err.Error())
+	}
+
+	result, err := r.updateStatus(instance, newStatus, oldStatus, nameToSubset, nextReplicas, nextPartitions, currentRevision, updatedRevision, collisionCount, control)
+	if err == nil && requeueAfter > 0 {
+		result.RequeueAfter = requeueAfter
+	}
+	return result, err
 }
 
 func (r *ReconcileUnitedDeployment) getNameToSubset(instance *appsv1alpha1.UnitedDeployment, control ControlInterface, expectedRevision string) (*map[string]*Subset, error) {
@@ -253,7 +297,11 @@ func (r *ReconcileUnitedDeployment) getNameToSubset(instance *appsv1alpha1.Unite
 	return nameToSubset, nil
 }
 
-func calcNextPartitions(ud *appsv1alpha1.UnitedDeployment, nextReplicas *map[string]int32) *map[string]int32 {
+func calcNextPartitions(ud *appsv1alpha1.UnitedDeployment, nameToSubset *map[string]*Subset, nextReplicas *map[string]int32) *map[string]int32 {
+	if ud.Spec.UpdateStrategy.Type == appsv1alpha1.OrderedUpdateStrategyType {
+		return calcOrderedPartitions(ud, nameToSubset, nextReplicas)
+	}
+
 	partitions := map[string]int32{}
 	for _, subset := range ud.Spec.Topology.Subsets {
 		var subsetPartition int32
@@ -314,6 +362,10 @@ func (r *ReconcileUnitedDeployment) getSubsetControls(instance *appsv1alpha1.Uni
 		return r.subSetControls[deploymentSubSetType], deploymentSubSetType
 	}
 
+	if instance.Spec.Template.AdvancedDaemonSetTemplate != nil {
+		return r.subSetControls[advancedDaemonSetSubSetType], advancedDaemonSetSubSetType
+	}
+
 	// unexpected
 	return nil, statefulSetSubSetType
 }
@@ -415,7 +467,8 @@ func (r *ReconcileUnitedDeployment) updateUnitedDeployment(ud *appsv1alpha1.Unit
 		ud.Generation == newStatus.ObservedGeneration &&
 		reflect.DeepEqual(oldStatus.SubsetReplicas, newStatus.SubsetReplicas) &&
 		reflect.DeepEqual(oldStatus.UpdateStatus, newStatus.UpdateStatus) &&
-		reflect.DeepEqual(oldStatus.Conditions, newStatus.Conditions) {
+		reflect.DeepEqual(oldStatus.Conditions, newStatus.Conditions) &&
+		reflect.DeepEqual(oldStatus.SubsetStatuses, newStatus.SubsetStatuses) {
 		return ud, nil
 	}
 
@@ -0,0 +1,93 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uniteddeployment
+
+import (
+	"context"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ handler.EventHandler = &enqueueRequestForPod{}
+
+// enqueueRequestForPod enqueues the UnitedDeployments with Adaptive schedule strategy when their pods become unschedulable,
+// so that the replicas can be moved to the other subsets in time.
+type enqueueRequestForPod struct {
+	reader client.Reader
+}
+
+func (e *enqueueRequestForPod) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	pod, ok := evt.Object.(*corev1.Pod)
+	if !ok || !isPodUnschedulable(pod) {
+		return
+	}
+	e.enqueueUnitedDeployments(q, pod)
+}
+
+func (e *enqueueRequestForPod) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	oldPod, oldOK := evt.ObjectOld.(*corev1.Pod)
+	newPod, newOK := evt.ObjectNew.(*corev1.Pod)
+	if !oldOK || !newOK || isPodUnschedulable(oldPod) == isPodUnschedulable(newPod) {
+		return
+	}
+	e.enqueueUnitedDeployments(q, newPod)
+}
+
+func (e *enqueueRequestForPod) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+}
+
+func (e *enqueueRequestForPod) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+}
+
+func (e *enqueueRequestForPod) enqueueUnitedDeployments(q workqueue.RateLimitingInterface, pod *corev1.Pod) {
+	if _, ok := pod.Labels[appsv1alpha1.SubSetNameLabelKey]; !ok {
+		return
+	}
+	udList := &appsv1alpha1.UnitedDeploymentList{}
+	if err := e.reader.List(context.TODO(), udList, client.InNamespace(pod.Namespace)); err != nil {
+		klog.Errorf("Failed to list UnitedDeployments for pod %s/%s: %v", pod.Namespace, pod.Name, err)
+		return
+	}
+	for i := range udList.Items {
+		ud := &udList.Items[i]
+		if getAdaptiveStrategy(ud) == nil {
+			continue
+		}
+		selector, err := util.GetFastLabelSelector(ud.Spec.Selector)
+		if err != nil || !selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		klog.V(4).Infof("Pod %s/%s scheduling changed, enqueue UnitedDeployment %s", pod.Namespace, pod.Name, ud.Name)
+		q.Add(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: ud.Namespace, Name: ud.Name}})
+	}
+}
+
+func isPodUnschedulable(pod *corev1.Pod) bool {
+	_, condition := podutil.GetPodCondition(&pod.Status, corev1.PodScheduled)
+	return condition != nil && condition.Status == corev1.ConditionFalse && condition.Reason == corev1.PodReasonUnschedulable
+}
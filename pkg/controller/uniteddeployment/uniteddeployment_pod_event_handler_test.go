@@ -0,0 +1,113 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uniteddeployment
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+)
+
+func TestEnqueueRequestForPod(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = appsv1alpha1.AddToScheme(scheme)
+
+	adaptive := newAdaptiveUnitedDeployment()
+	fixed := newAdaptiveUnitedDeployment()
+	fixed.Name = "ud-fixed"
+	fixed.Spec.Topology.ScheduleStrategy = appsv1alpha1.UnitedDeploymentScheduleStrategy{}
+	other := newAdaptiveUnitedDeployment()
+	other.Name = "ud-other"
+	other.Spec.Selector.MatchLabels = map[string]string{"app": "other"}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(adaptive, fixed, other).Build()
+	handler := &enqueueRequestForPod{reader: fakeClient}
+
+	scheduledPod := newSubsetPod("pod-0", "a", 0)
+	unschedulablePod := newSubsetPod("pod-0", "a", time.Second)
+	unlabeledPod := unschedulablePod.DeepCopy()
+	delete(unlabeledPod.Labels, appsv1alpha1.SubSetNameLabelKey)
+
+	cases := []struct {
+		name     string
+		trigger  func(q workqueue.RateLimitingInterface)
+		expected int
+	}{
+		{
+			name:     "create scheduled pod",
+			trigger:  func(q workqueue.RateLimitingInterface) { handler.Create(event.CreateEvent{Object: scheduledPod}, q) },
+			expected: 0,
+		},
+		{
+			name: "create unschedulable pod",
+			trigger: func(q workqueue.RateLimitingInterface) {
+				handler.Create(event.CreateEvent{Object: unschedulablePod}, q)
+			},
+			expected: 1,
+		},
+		{
+			name:     "create unschedulable pod without subset label",
+			trigger:  func(q workqueue.RateLimitingInterface) { handler.Create(event.CreateEvent{Object: unlabeledPod}, q) },
+			expected: 0,
+		},
+		{
+			name: "update pod to unschedulable",
+			trigger: func(q workqueue.RateLimitingInterface) {
+				handler.Update(event.UpdateEvent{ObjectOld: scheduledPod, ObjectNew: unschedulablePod}, q)
+			},
+			expected: 1,
+		},
+		{
+			name: "update pod to scheduled",
+			trigger: func(q workqueue.RateLimitingInterface) {
+				handler.Update(event.UpdateEvent{ObjectOld: unschedulablePod, ObjectNew: scheduledPod}, q)
+			},
+			expected: 1,
+		},
+		{
+			name: "update unschedulable pod",
+			trigger: func(q workqueue.RateLimitingInterface) {
+				handler.Update(event.UpdateEvent{ObjectOld: unschedulablePod, ObjectNew: unschedulablePod.DeepCopy()}, q)
+			},
+			expected: 0,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+			cs.trigger(q)
+			if q.Len() != cs.expected {
+				t.Fatalf("expected %d requests, got %d", cs.expected, q.Len())
+			}
+			if cs.expected > 0 {
+				item, _ := q.Get()
+				if item.(reconcile.Request).Name != adaptive.Name {
+					t.Fatalf("expected request for %s, got %v", adaptive.Name, item)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloadspread
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+	kubecontroller "k8s.io/kubernetes/pkg/controller"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	wsutil "github.com/openkruise/kruise/pkg/webhook/workloadspread/validating"
+)
+
+const (
+	// SubsetPendingPodsExceededReason means the unschedulable Pods of the subset exceed maxPendingPodPercent.
+	SubsetPendingPodsExceededReason = "PendingPodsExceeded"
+	// SubsetNotReadyNodesExceededReason means the NotReady nodes of the subset exceed maxNotReadyNodePercent.
+	SubsetNotReadyNodesExceededReason = "NotReadyNodesExceeded"
+	// SubsetRecoveringReason means the subset is healthy again and waiting for recoverySeconds to be schedulable.
+	SubsetRecoveringReason = "Recovering"
+
+	// failoverNodeResyncDuration is the period to check the nodes of subsets again, for nodes are not watched.
+	failoverNodeResyncDuration = 30 * time.Second
+)
+
+func getFailoverStrategy(ws *appsv1alpha1.WorkloadSpread) *appsv1alpha1.WorkloadSpreadFailoverStrategy {
+	if ws.Spec.ScheduleStrategy.Type != appsv1alpha1.AdaptiveWorkloadSpreadScheduleStrategyType ||
+		ws.Spec.ScheduleStrategy.Adaptive == nil {
+		return nil
+	}
+	return ws.Spec.ScheduleStrategy.Adaptive.Failover
+}
+
+// listNodesForFailover returns all the nodes if the failover strategy needs to check the NotReady nodes.
+func (r *ReconcileWorkloadSpread) listNodesForFailover(ws *appsv1alpha1.WorkloadSpread) []*corev1.Node {
+	failover := getFailoverStrategy(ws)
+	if failover == nil || failover.MaxNotReadyNodePercent == nil {
+		return nil
+	}
+	nodeList := &corev1.NodeList{}
+	if err := r.List(context.TODO(), nodeList); err != nil {
+		klog.Errorf("WorkloadSpread (%s/%s) failed to list nodes for failover: %v", ws.Namespace, ws.Name, err)
+		return nil
+	}
+	durationStore.Push(getWorkloadSpreadKey(ws), failoverNodeResyncDuration)
+	nodes := make([]*corev1.Node, 0, len(nodeList.Items))
+	for i := range nodeList.Items {
+		nodes = append(nodes, &nodeList.Items[i])
+	}
+	return nodes
+}
+
+// checkSubsetHealth returns the reason and message if the subset is unhealthy according to the failover strategy,
+// or empty reason if it is healthy.
+func checkSubsetHealth(failover *appsv1alpha1.WorkloadSpreadFailoverStrategy, subset *appsv1alpha1.WorkloadSpreadSubset,
+	pods []*corev1.Pod, nodes []*corev1.Node) (string, string) {
+	if failover.MaxPendingPodPercent != nil {
+		var active, pending int
+		for _, pod := range pods {
+			if !kubecontroller.IsPodActive(pod) {
+				continue
+			}
+			active++
+			if _, condition := podutil.GetPodCondition(&pod.Status, corev1.PodScheduled); condition != nil &&
+				condition.Status == corev1.ConditionFalse && condition.Reason == corev1.PodReasonUnschedulable {
+				pending++
+			}
+		}
+		if active > 0 && pending*100 > int(*failover.MaxPendingPodPercent)*active {
+			return SubsetPendingPodsExceededReason, fmt.Sprintf("%d of %d pods are unschedulable", pending, active)
+		}
+	}
+
+	if failover.MaxNotReadyNodePercent != nil {
+		var matched, notReady int
+		for _, node := range nodes {
+			if ok, err := matchesSubsetRequiredAndToleration(&corev1.Pod{}, node, subset); err != nil || !ok {
+				continue
+			}
+			matched++
+			if _, condition := getNodeCondition(node, corev1.NodeReady); condition == nil || condition.Status != corev1.ConditionTrue {
+				notReady++
+			}
+		}
+		if matched > 0 && notReady*100 > int(*failover.MaxNotReadyNodePercent)*matched {
+			return SubsetNotReadyNodesExceededReason, fmt.Sprintf("%d of %d nodes are not ready", notReady, matched)
+		}
+	}
+	return "", ""
+}
+
+func getNodeCondition(node *corev1.Node, conditionType corev1.NodeConditionType) (int, *corev1.NodeCondition) {
+	for i := range node.Status.Conditions {
+		if node.Status.Conditions[i].Type == conditionType {
+			return i, &node.Status.Conditions[i]
+		}
+	}
+	return -1, nil
+}
+
+// failoverSubset marks the subset unschedulable if it is unhealthy. After the subset becomes healthy again,
+// it keeps unschedulable for recoverySeconds and then is recovered to schedulable.
+// unschedulable indicates whether the subset has been marked unschedulable for the schedule failed Pods in this reconcile.
+func (r *ReconcileWorkloadSpread) failoverSubset(ws *appsv1alpha1.WorkloadSpread, subset *appsv1alpha1.WorkloadSpreadSubset,
+	pods []*corev1.Pod, nodes []*corev1.Node, subsetStatus, oldSubsetStatus *appsv1alpha1.WorkloadSpreadSubsetStatus, unschedulable bool) {
+	failover := getFailoverStrategy(ws)
+	condition := GetWorkloadSpreadSubsetCondition(subsetStatus, appsv1alpha1.SubsetSchedulable)
+	if condition == nil {
+		if condition = GetWorkloadSpreadSubsetCondition(oldSubsetStatus, appsv1alpha1.SubsetSchedulable); condition != nil {
+			setWorkloadSpreadSubsetCondition(subsetStatus, condition.DeepCopy())
+		}
+	}
+
+	reason, message := checkSubsetHealth(failover, subset, pods, nodes)
+	if reason != "" {
+		if condition == nil || condition.Status != corev1.ConditionFalse || condition.Reason != reason {
+			r.recorder.Eventf(ws, corev1.EventTypeWarning, "SubsetFailover",
+				"Subset %s of WorkloadSpread %s/%s is unhealthy and marked unschedulable: %s", subset.Name, ws.Namespace, ws.Name, message)
+		}
+		setWorkloadSpreadSubsetCondition(subsetStatus, NewWorkloadSpreadSubsetCondition(appsv1alpha1.SubsetSchedulable, corev1.ConditionFalse, reason, message))
+		return
+	}
+
+	if condition == nil {
+		setWorkloadSpreadSubsetCondition(subsetStatus, NewWorkloadSpreadSubsetCondition(appsv1alpha1.SubsetSchedulable, corev1.ConditionTrue, "", ""))
+		return
+	}
+	if condition.Status != corev1.ConditionFalse || unschedulable {
+		return
+	}
+
+	recoveryDuration := wsutil.MaxScheduledFailedDuration
+	if failover.RecoverySeconds != nil {
+		recoveryDuration = time.Duration(*failover.RecoverySeconds) * time.Second
+	}
+	// the subset becomes healthy, start the recovery window from now on.
+	if condition.Reason != SubsetRecoveringReason {
+		removeWorkloadSpreadSubsetCondition(subsetStatus, appsv1alpha1.SubsetSchedulable)
+		setWorkloadSpreadSubsetCondition(subsetStatus, NewWorkloadSpreadSubsetCondition(appsv1alpha1.SubsetSchedulable, corev1.ConditionFalse,
+			SubsetRecoveringReason, "subset is healthy and waiting for recovery"))
+		durationStore.Push(getWorkloadSpreadKey(ws), recoveryDuration)
+		return
+	}
+
+	expectRecovery := condition.LastTransitionTime.Add(recoveryDuration)
+	currentTime := time.Now()
+	if expectRecovery.Before(currentTime) {
+		r.recorder.Eventf(ws, corev1.EventTypeNormal,
+			"RecoverSchedulable", "Subset %s of WorkloadSpread %s/%s is recovered from unschedulable to schedulable",
+			subset.Name, ws.Namespace, ws.Name)
+		setWorkloadSpreadSubsetCondition(subsetStatus, NewWorkloadSpreadSubsetCondition(appsv1alpha1.SubsetSchedulable, corev1.ConditionTrue, "", ""))
+	} else {
+		durationStore.Push(getWorkloadSpreadKey(ws), expectRecovery.Sub(currentTime))
+	}
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloadspread
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+)
+
+func TestFailoverSubset(t *testing.T) {
+	newPod := func(unschedulable bool) *corev1.Pod {
+		pod := &corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodRunning}}
+		if unschedulable {
+			pod.Status.Phase = corev1.PodPending
+			pod.Status.Conditions = []corev1.PodCondition{
+				{Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: corev1.PodReasonUnschedulable},
+			}
+		}
+		return pod
+	}
+	newNode := func(zone string, ready bool) *corev1.Node {
+		status := corev1.ConditionTrue
+		if !ready {
+			status = corev1.ConditionFalse
+		}
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"zone": zone}},
+			Status:     corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}}},
+		}
+	}
+	newCondition := func(status corev1.ConditionStatus, reason string, transitionTime time.Time) *appsv1alpha1.WorkloadSpreadSubsetCondition {
+		condition := NewWorkloadSpreadSubsetCondition(appsv1alpha1.SubsetSchedulable, status, reason, "")
+		condition.LastTransitionTime = metav1.NewTime(transitionTime)
+		return condition
+	}
+
+	ws := workloadSpreadDemo.DeepCopy()
+	ws.Spec.ScheduleStrategy = appsv1alpha1.WorkloadSpreadScheduleStrategy{
+		Type: appsv1alpha1.AdaptiveWorkloadSpreadScheduleStrategyType,
+		Adaptive: &appsv1alpha1.AdaptiveWorkloadSpreadStrategy{
+			Failover: &appsv1alpha1.WorkloadSpreadFailoverStrategy{
+				MaxPendingPodPercent:   pointer.Int32Ptr(50),
+				MaxNotReadyNodePercent: pointer.Int32Ptr(30),
+				RecoverySeconds:        pointer.Int32Ptr(60),
+			},
+		},
+	}
+	subset := &appsv1alpha1.WorkloadSpreadSubset{
+		Name: "subset-a",
+		RequiredNodeSelectorTerm: &corev1.NodeSelectorTerm{
+			MatchExpressions: []corev1.NodeSelectorRequirement{
+				{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"a"}},
+			},
+		},
+	}
+	now := time.Now()
+
+	cases := []struct {
+		name         string
+		pods         []*corev1.Pod
+		nodes        []*corev1.Node
+		oldCondition *appsv1alpha1.WorkloadSpreadSubsetCondition
+		expectStatus corev1.ConditionStatus
+		expectReason string
+	}{
+		{
+			name:         "healthy subset",
+			pods:         []*corev1.Pod{newPod(false), newPod(true)},
+			nodes:        []*corev1.Node{newNode("a", true), newNode("a", true), newNode("b", false)},
+			expectStatus: corev1.ConditionTrue,
+		},
+		{
+			name:         "too many pending pods",
+			pods:         []*corev1.Pod{newPod(false), newPod(true), newPod(true)},
+			expectStatus: corev1.ConditionFalse,
+			expectReason: SubsetPendingPodsExceededReason,
+		},
+		{
+			name:         "too many not ready nodes",
+			nodes:        []*corev1.Node{newNode("a", true), newNode("a", false), newNode("b", true)},
+			oldCondition: newCondition(corev1.ConditionTrue, "", now.Add(-time.Hour)),
+			expectStatus: corev1.ConditionFalse,
+			expectReason: SubsetNotReadyNodesExceededReason,
+		},
+		{
+			name:         "become healthy and start recovering",
+			nodes:        []*corev1.Node{newNode("a", true)},
+			oldCondition: newCondition(corev1.ConditionFalse, SubsetNotReadyNodesExceededReason, now.Add(-time.Hour)),
+			expectStatus: corev1.ConditionFalse,
+			expectReason: SubsetRecoveringReason,
+		},
+		{
+			name:         "keep recovering in the recovery window",
+			nodes:        []*corev1.Node{newNode("a", true)},
+			oldCondition: newCondition(corev1.ConditionFalse, SubsetRecoveringReason, now.Add(-30*time.Second)),
+			expectStatus: corev1.ConditionFalse,
+			expectReason: SubsetRecoveringReason,
+		},
+		{
+			name:         "recovered after the recovery window",
+			nodes:        []*corev1.Node{newNode("a", true)},
+			oldCondition: newCondition(corev1.ConditionFalse, SubsetRecoveringReason, now.Add(-2*time.Minute)),
+			expectStatus: corev1.ConditionTrue,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			r := &ReconcileWorkloadSpread{recorder: record.NewFakeRecorder(10)}
+			oldSubsetStatus := &appsv1alpha1.WorkloadSpreadSubsetStatus{Name: subset.Name}
+			if cs.oldCondition != nil {
+				oldSubsetStatus.Conditions = []appsv1alpha1.WorkloadSpreadSubsetCondition{*cs.oldCondition}
+			}
+			subsetStatus := &appsv1alpha1.WorkloadSpreadSubsetStatus{Name: subset.Name}
+			r.failoverSubset(ws, subset, cs.pods, cs.nodes, subsetStatus, oldSubsetStatus, false)
+
+			condition := GetWorkloadSpreadSubsetCondition(subsetStatus, appsv1alpha1.SubsetSchedulable)
+			if condition == nil {
+				t.Fatalf("expect schedulable condition, but got nil")
+			}
+			if condition.Status != cs.expectStatus || condition.Reason != cs.expectReason {
+				t.Fatalf("expect condition status %s reason %s, but got %s %s",
+					cs.expectStatus, cs.expectReason, condition.Status, condition.Reason)
+			}
+		})
+	}
+}
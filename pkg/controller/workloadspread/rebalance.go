@@ -0,0 +1,159 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloadspread
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/klog/v2"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+	kubecontroller "k8s.io/kubernetes/pkg/controller"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+)
+
+const (
+	rebalanceInitialBackoff = time.Minute
+	rebalanceMaxBackoff     = 30 * time.Minute
+)
+
+// rebalanceBackoff delays the rebalancing of a WorkloadSpread after its Pods failed to be scheduled. The subsets
+// may have missing replicas but no capacity for them, so the Pods deleted for rebalancing would be rescheduled
+// back to the lower-priority subsets and deleted again and again. The delay doubles on every scheduling failure.
+var rebalanceBackoff = flowcontrol.NewBackOff(rebalanceInitialBackoff, rebalanceMaxBackoff)
+
+// rebalanceSubsets deletes some Pods in lower-priority subsets when the higher-priority subsets have missing replicas
+// and are schedulable, so that the Pods recreated by the workload will be injected into the higher-priority subsets
+// by webhook. The number of unavailable Pods of the workload is limited by rebalance.maxUnavailable.
+// scheduleFailed indicates some Pods of the workload failed to be scheduled, and the rebalancing backs off.
+func (r *ReconcileWorkloadSpread) rebalanceSubsets(ws *appsv1alpha1.WorkloadSpread, podMap map[string][]*corev1.Pod,
+	status *appsv1alpha1.WorkloadSpreadStatus, workloadReplicas int32, scheduleFailed bool) error {
+	if ws.Spec.ScheduleStrategy.Type != appsv1alpha1.AdaptiveWorkloadSpreadScheduleStrategyType ||
+		ws.Spec.ScheduleStrategy.Adaptive == nil || ws.Spec.ScheduleStrategy.Adaptive.Rebalance == nil {
+		return nil
+	}
+
+	key := getWorkloadSpreadKey(ws)
+	now := rebalanceBackoff.Clock.Now()
+	if scheduleFailed {
+		rebalanceBackoff.Next(key, now)
+		klog.V(3).Infof("WorkloadSpread (%s/%s) has Pods failed to be scheduled, back off rebalancing for %v",
+			ws.Namespace, ws.Name, rebalanceBackoff.Get(key))
+	}
+	if rebalanceBackoff.IsInBackOffSinceUpdate(key, now) {
+		durationStore.Push(key, rebalanceBackoff.Get(key))
+		return nil
+	}
+
+	podsToDelete := calculateRebalancePods(ws, podMap, status, workloadReplicas)
+	for subsetName, pods := range podsToDelete {
+		for _, pod := range pods {
+			if err := r.Client.Delete(context.TODO(), pod); err != nil {
+				// the deletion may be rejected by PodUnavailableBudget, and it will be retried in the next reconcile.
+				r.recorder.Eventf(ws, corev1.EventTypeWarning,
+					"RebalancePodFailed", "Failed to delete Pod %s/%s in Subset %s for rebalancing: %v",
+					pod.Namespace, pod.Name, subsetName, err)
+				return err
+			}
+			r.recorder.Eventf(ws, corev1.EventTypeNormal,
+				"RebalancePod", "Deleted Pod %s/%s in Subset %s for rebalancing", pod.Namespace, pod.Name, subsetName)
+			klog.V(3).Infof("WorkloadSpread (%s/%s) delete Pod (%s/%s) in Subset %s for rebalancing",
+				ws.Namespace, ws.Name, pod.Namespace, pod.Name, subsetName)
+		}
+	}
+	return nil
+}
+
+// calculateRebalancePods returns a map, the key is the subset name, the value is the Pods in the subset to delete for rebalancing.
+// The Pods are deleted from the last subset first, and only the ready Pods in the subsets without missing replicas
+// can be deleted, and the number of them is no more than the missing replicas of the subsets in front of them.
+func calculateRebalancePods(ws *appsv1alpha1.WorkloadSpread, podMap map[string][]*corev1.Pod,
+	status *appsv1alpha1.WorkloadSpreadStatus, workloadReplicas int32) map[string][]*corev1.Pod {
+	rebalance := ws.Spec.ScheduleStrategy.Adaptive.Rebalance
+	maxUnavailable, err := intstr.GetScaledValueFromIntOrPercent(
+		intstr.ValueOrDefault(rebalance.MaxUnavailable, intstr.FromInt(1)), int(workloadReplicas), true)
+	if err != nil {
+		klog.Errorf("failed to get rebalance maxUnavailable value of WorkloadSpread (%s/%s): %v", ws.Namespace, ws.Name, err)
+		return nil
+	}
+
+	// the Pods being created, deleted or not ready are unavailable.
+	unavailable := 0
+	for _, subsetStatus := range status.SubsetStatuses {
+		unavailable += len(subsetStatus.CreatingPods)
+	}
+	for _, pods := range podMap {
+		for _, pod := range pods {
+			if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+				continue
+			}
+			if pod.DeletionTimestamp != nil || !podutil.IsPodReady(pod) {
+				unavailable++
+			}
+		}
+	}
+	budget := maxUnavailable - unavailable
+	if budget <= 0 {
+		return nil
+	}
+
+	// room[i] is the number of Pods that subset i can accept.
+	room := make([]int, len(ws.Spec.Subsets))
+	for i := range ws.Spec.Subsets {
+		subsetStatus := &status.SubsetStatuses[i]
+		condition := GetWorkloadSpreadSubsetCondition(subsetStatus, appsv1alpha1.SubsetSchedulable)
+		if subsetStatus.MissingReplicas <= 0 || (condition != nil && condition.Status == corev1.ConditionFalse) {
+			continue
+		}
+		room[i] = int(subsetStatus.MissingReplicas)
+	}
+
+	podsToDelete := make(map[string][]*corev1.Pod)
+	deleted := 0
+	for j := len(ws.Spec.Subsets) - 1; j > 0 && deleted < budget; j-- {
+		if room[j] > 0 {
+			continue
+		}
+		roomBefore := 0
+		for i := 0; i < j; i++ {
+			roomBefore += room[i]
+		}
+		if roomBefore <= deleted {
+			continue
+		}
+
+		subsetName := ws.Spec.Subsets[j].Name
+		var readyPods []*corev1.Pod
+		for _, pod := range podMap[subsetName] {
+			if kubecontroller.IsPodActive(pod) && podutil.IsPodReady(pod) {
+				readyPods = append(readyPods, pod)
+			}
+		}
+		for _, idx := range sortDeleteIndexes(readyPods) {
+			if deleted >= budget || deleted >= roomBefore {
+				break
+			}
+			podsToDelete[subsetName] = append(podsToDelete[subsetName], readyPods[idx])
+			deleted++
+		}
+	}
+	return podsToDelete
+}
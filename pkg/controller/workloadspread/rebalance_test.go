@@ -0,0 +1,236 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloadspread
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/flowcontrol"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+)
+
+func TestCalculateRebalancePods(t *testing.T) {
+	newReadyPods := func(subsetName string, num int) []*corev1.Pod {
+		pods := make([]*corev1.Pod, 0, num)
+		for i := 0; i < num; i++ {
+			pods = append(pods, &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: fmt.Sprintf("%s-%d", subsetName, i)},
+				Spec:       corev1.PodSpec{NodeName: "node"},
+				Status: corev1.PodStatus{
+					Phase:      corev1.PodRunning,
+					Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+				},
+			})
+		}
+		return pods
+	}
+
+	wsDemo := workloadSpreadDemo.DeepCopy()
+	wsDemo.Spec.ScheduleStrategy = appsv1alpha1.WorkloadSpreadScheduleStrategy{
+		Type: appsv1alpha1.AdaptiveWorkloadSpreadScheduleStrategyType,
+		Adaptive: &appsv1alpha1.AdaptiveWorkloadSpreadStrategy{
+			Rebalance: &appsv1alpha1.WorkloadSpreadRebalanceStrategy{},
+		},
+	}
+	wsDemo.Spec.Subsets = []appsv1alpha1.WorkloadSpreadSubset{
+		{Name: "subset-a", MaxReplicas: &intstr.IntOrString{Type: intstr.Int, IntVal: 3}},
+		{Name: "subset-b", MaxReplicas: &intstr.IntOrString{Type: intstr.Int, IntVal: 2}},
+		{Name: "subset-c"},
+	}
+	statusDemo := &appsv1alpha1.WorkloadSpreadStatus{
+		SubsetStatuses: []appsv1alpha1.WorkloadSpreadSubsetStatus{
+			{Name: "subset-a", Replicas: 1, MissingReplicas: 2},
+			{Name: "subset-b", Replicas: 2, MissingReplicas: 0},
+			{Name: "subset-c", Replicas: 3, MissingReplicas: -1},
+		},
+	}
+
+	cases := []struct {
+		name           string
+		maxUnavailable *intstr.IntOrString
+		getStatus      func() *appsv1alpha1.WorkloadSpreadStatus
+		getPodMap      func() map[string][]*corev1.Pod
+		expectDeleted  map[string]int
+	}{
+		{
+			name:      "delete one pod from the last subset by default",
+			getStatus: statusDemo.DeepCopy,
+			getPodMap: func() map[string][]*corev1.Pod {
+				return map[string][]*corev1.Pod{
+					"subset-a": newReadyPods("subset-a", 1),
+					"subset-b": newReadyPods("subset-b", 2),
+					"subset-c": newReadyPods("subset-c", 3),
+				}
+			},
+			expectDeleted: map[string]int{"subset-c": 1},
+		},
+		{
+			name:           "delete no more than missing replicas of higher-priority subsets",
+			maxUnavailable: &intstr.IntOrString{Type: intstr.String, StrVal: "50%"},
+			getStatus:      statusDemo.DeepCopy,
+			getPodMap: func() map[string][]*corev1.Pod {
+				return map[string][]*corev1.Pod{
+					"subset-a": newReadyPods("subset-a", 1),
+					"subset-b": newReadyPods("subset-b", 2),
+					"subset-c": newReadyPods("subset-c", 3),
+				}
+			},
+			expectDeleted: map[string]int{"subset-c": 2},
+		},
+		{
+			name:           "delete from the middle subset when the last subset is empty",
+			maxUnavailable: &intstr.IntOrString{Type: intstr.Int, IntVal: 3},
+			getStatus:      statusDemo.DeepCopy,
+			getPodMap: func() map[string][]*corev1.Pod {
+				return map[string][]*corev1.Pod{
+					"subset-a": newReadyPods("subset-a", 1),
+					"subset-b": newReadyPods("subset-b", 2),
+				}
+			},
+			expectDeleted: map[string]int{"subset-b": 2},
+		},
+		{
+			name: "higher-priority subset is unschedulable",
+			getStatus: func() *appsv1alpha1.WorkloadSpreadStatus {
+				status := statusDemo.DeepCopy()
+				setWorkloadSpreadSubsetCondition(&status.SubsetStatuses[0],
+					NewWorkloadSpreadSubsetCondition(appsv1alpha1.SubsetSchedulable, corev1.ConditionFalse, "", ""))
+				return status
+			},
+			getPodMap: func() map[string][]*corev1.Pod {
+				return map[string][]*corev1.Pod{
+					"subset-a": newReadyPods("subset-a", 1),
+					"subset-b": newReadyPods("subset-b", 2),
+					"subset-c": newReadyPods("subset-c", 3),
+				}
+			},
+			expectDeleted: map[string]int{},
+		},
+		{
+			name:      "unavailable pods exceed maxUnavailable",
+			getStatus: statusDemo.DeepCopy,
+			getPodMap: func() map[string][]*corev1.Pod {
+				pods := newReadyPods("subset-a", 1)
+				pods[0].Status.Conditions = nil
+				return map[string][]*corev1.Pod{
+					"subset-a": pods,
+					"subset-b": newReadyPods("subset-b", 2),
+					"subset-c": newReadyPods("subset-c", 3),
+				}
+			},
+			expectDeleted: map[string]int{},
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			ws := wsDemo.DeepCopy()
+			ws.Spec.ScheduleStrategy.Adaptive.Rebalance.MaxUnavailable = cs.maxUnavailable
+			podsToDelete := calculateRebalancePods(ws, cs.getPodMap(), cs.getStatus(), 6)
+			deleted := make(map[string]int)
+			for subsetName, pods := range podsToDelete {
+				deleted[subsetName] = len(pods)
+			}
+			if len(deleted) != len(cs.expectDeleted) {
+				t.Fatalf("expect deleted %v, but got %v", cs.expectDeleted, deleted)
+			}
+			for subsetName, num := range cs.expectDeleted {
+				if deleted[subsetName] != num {
+					t.Fatalf("expect deleted %v, but got %v", cs.expectDeleted, deleted)
+				}
+			}
+		})
+	}
+}
+
+func TestRebalanceSubsetsBackoff(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	defer func(backoff *flowcontrol.Backoff) { rebalanceBackoff = backoff }(rebalanceBackoff)
+	rebalanceBackoff = flowcontrol.NewFakeBackOff(rebalanceInitialBackoff, rebalanceMaxBackoff, fakeClock)
+
+	ws := workloadSpreadDemo.DeepCopy()
+	ws.Spec.ScheduleStrategy = appsv1alpha1.WorkloadSpreadScheduleStrategy{
+		Type: appsv1alpha1.AdaptiveWorkloadSpreadScheduleStrategyType,
+		Adaptive: &appsv1alpha1.AdaptiveWorkloadSpreadStrategy{
+			Rebalance: &appsv1alpha1.WorkloadSpreadRebalanceStrategy{},
+		},
+	}
+	ws.Spec.Subsets = []appsv1alpha1.WorkloadSpreadSubset{
+		{Name: "subset-a", MaxReplicas: &intstr.IntOrString{Type: intstr.Int, IntVal: 3}},
+		{Name: "subset-b"},
+	}
+	status := &appsv1alpha1.WorkloadSpreadStatus{
+		SubsetStatuses: []appsv1alpha1.WorkloadSpreadSubsetStatus{
+			{Name: "subset-a", Replicas: 0, MissingReplicas: 3},
+			{Name: "subset-b", Replicas: 3, MissingReplicas: -1},
+		},
+	}
+	var pods []*corev1.Pod
+	var objects []client.Object
+	for i := 0; i < 3; i++ {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ws.Namespace, Name: fmt.Sprintf("subset-b-%d", i)},
+			Spec:       corev1.PodSpec{NodeName: "node"},
+			Status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			},
+		}
+		pods = append(pods, pod)
+		objects = append(objects, pod)
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+	r := &ReconcileWorkloadSpread{Client: fakeClient, recorder: record.NewFakeRecorder(10)}
+
+	countPods := func() int {
+		podList := &corev1.PodList{}
+		if err := fakeClient.List(context.TODO(), podList); err != nil {
+			t.Fatalf("failed to list pods: %v", err)
+		}
+		return len(podList.Items)
+	}
+	steps := []struct {
+		elapsed        time.Duration
+		scheduleFailed bool
+		expectPods     int
+	}{
+		{elapsed: 0, scheduleFailed: true, expectPods: 3},
+		{elapsed: 30 * time.Second, expectPods: 3},
+		{elapsed: time.Minute, expectPods: 2},
+	}
+	for i, step := range steps {
+		fakeClock.Step(step.elapsed)
+		podMap := map[string][]*corev1.Pod{"subset-b": pods[3-countPods():]}
+		if err := r.rebalanceSubsets(ws, podMap, status, 3, step.scheduleFailed); err != nil {
+			t.Fatalf("step %d: failed to rebalance subsets: %v", i, err)
+		}
+		if got := countPods(); got != step.expectPods {
+			t.Fatalf("step %d: expect %d pods, but got %d", i, step.expectPods, got)
+		}
+	}
+}
@@ -64,8 +64,8 @@ func (r *ReconcileWorkloadSpread) rescheduleSubset(ws *appsv1alpha1.WorkloadSpre
 	if unschedulable {
 		setWorkloadSpreadSubsetCondition(subsetStatus, NewWorkloadSpreadSubsetCondition(appsv1alpha1.SubsetSchedulable, corev1.ConditionFalse, "", ""))
 	} else {
-		// consider to recover
-		if oldCondition.Status == corev1.ConditionFalse {
+		// consider to recover, which is handled by failoverSubset if the failover strategy is set.
+		if oldCondition.Status == corev1.ConditionFalse && getFailoverStrategy(ws) == nil {
 			expectReschedule := oldCondition.LastTransitionTime.Add(wsutil.MaxScheduledFailedDuration)
 			currentTime := time.Now()
 			// the duration of unschedule status more than 5 minutes, recover to schedulable.
@@ -104,6 +104,7 @@ func (r *ReconcileWorkloadSpread) deletePodsForSubset(ws *appsv1alpha1.WorkloadS
 				pod.Namespace, pod.Name, subsetName, ws.Namespace, ws.Name)
 			return err
 		}
+		recordSubsetReschedule(ws, subsetName)
 		klog.V(3).Infof("WorkloadSpread (%s/%s) delete unschedulabe Pod (%s/%s) in Subset %s successfully",
 			ws.Namespace, ws.Name, pod.Namespace, pod.Name, subsetName)
 	}
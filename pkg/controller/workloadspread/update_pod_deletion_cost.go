@@ -26,6 +26,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/integer"
 	kubecontroller "k8s.io/kubernetes/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -68,6 +69,8 @@ func (r *ReconcileWorkloadSpread) updateDeletionCost(ws *appsv1alpha1.WorkloadSp
 //    maxReplicas    10            10           nil
 //    pods number    20            20           20
 //    deletion-cost (300,-100)    (200,-200)    100
+// If the deletionCostPolicy is Tiered, the Pods in each class are further ranked within [deletion-cost, deletion-cost + 99]
+// according to their conditions and ages, e.g. the Pods in subset-b will get deletion-cost from 200 to 209 for case 1.
 func (r *ReconcileWorkloadSpread) syncSubsetPodDeletionCost(
 	ws *appsv1alpha1.WorkloadSpread,
 	subset *appsv1alpha1.WorkloadSpreadSubset,
@@ -126,11 +129,11 @@ func (r *ReconcileWorkloadSpread) syncSubsetPodDeletionCost(
 		}
 	}
 
-	err = r.updateDeletionCostForSubsetPods(ws, subset, positivePods, strconv.Itoa(wsutil.PodDeletionCostPositive*(len(ws.Spec.Subsets)-subsetIndex)))
+	err = r.updateDeletionCostForSubsetPods(ws, subset, positivePods, wsutil.PodDeletionCostPositive*(len(ws.Spec.Subsets)-subsetIndex))
 	if err != nil {
 		return err
 	}
-	err = r.updateDeletionCostForSubsetPods(ws, subset, negativePods, strconv.Itoa(wsutil.PodDeletionCostNegative*(subsetIndex+1)))
+	err = r.updateDeletionCostForSubsetPods(ws, subset, negativePods, wsutil.PodDeletionCostNegative*(subsetIndex+1))
 	if err != nil {
 		return err
 	}
@@ -138,9 +141,23 @@ func (r *ReconcileWorkloadSpread) syncSubsetPodDeletionCost(
 	return nil
 }
 
+// updateDeletionCostForSubsetPods sets deletion-cost of the Pods to the tier of deletionCost. If the deletionCostPolicy
+// is Tiered, the Pods are ranked in the tier, i.e. [deletionCost, deletionCost + 99], by sortDeleteIndexes,
+// the Pod to delete first has the lowest deletion-cost.
 func (r *ReconcileWorkloadSpread) updateDeletionCostForSubsetPods(ws *appsv1alpha1.WorkloadSpread,
-	subset *appsv1alpha1.WorkloadSpreadSubset, pods []*corev1.Pod, deletionCostStr string) error {
-	for _, pod := range pods {
+	subset *appsv1alpha1.WorkloadSpreadSubset, pods []*corev1.Pod, deletionCost int) error {
+	deletionCosts := make([]int, len(pods))
+	for i := range pods {
+		deletionCosts[i] = deletionCost
+	}
+	if ws.Spec.DeletionCostPolicy == appsv1alpha1.TieredWorkloadSpreadDeletionCostPolicyType {
+		for rank, idx := range sortDeleteIndexes(pods) {
+			deletionCosts[idx] += integer.IntMin(rank, wsutil.PodDeletionCostPositive-1)
+		}
+	}
+
+	for i, pod := range pods {
+		deletionCostStr := strconv.Itoa(deletionCosts[i])
 		if err := r.patchPodDeletionCost(ws, pod, deletionCostStr); err != nil {
 			subsetName := FakeSubsetName
 			if subset != nil {
@@ -148,7 +165,7 @@ func (r *ReconcileWorkloadSpread) updateDeletionCostForSubsetPods(ws *appsv1alph
 			}
 			r.recorder.Eventf(ws, corev1.EventTypeWarning,
 				"PatchPodDeletionCostFailed",
-				"WorkloadSpread %s/%s failed to patch deletion-cost annotation to %d for Pod %s/%s in subset %s",
+				"WorkloadSpread %s/%s failed to patch deletion-cost annotation to %s for Pod %s/%s in subset %s",
 				ws.Namespace, ws.Name, deletionCostStr, pod.Namespace, pod.Name, subsetName)
 			return err
 		}
@@ -175,6 +175,8 @@ type ReconcileWorkloadSpread struct {
 // +kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get;list;watch
 // +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=*,resources=*/scale,verbs=get
 
 func (r *ReconcileWorkloadSpread) Reconcile(_ context.Context, req reconcile.Request) (reconcile.Result, error) {
 	ws := &appsv1alpha1.WorkloadSpread{}
@@ -194,6 +196,8 @@ func (r *ReconcileWorkloadSpread) Reconcile(_ context.Context, req reconcile.Req
 		}); cacheErr != nil {
 			klog.Warningf("Failed to delete workloadSpread(%s/%s) cache after deletion, err: %v", req.Namespace, req.Name, cacheErr)
 		}
+		deleteMetrics(req.Namespace, req.Name)
+		rebalanceBackoff.DeleteEntry(req.Namespace + "/" + req.Name)
 		return reconcile.Result{}, nil
 	} else if err != nil {
 		// Error reading the object - requeue the request.
@@ -268,9 +272,13 @@ func (r *ReconcileWorkloadSpread) getPodsForWorkloadSpread(ws *appsv1alpha1.Work
 	case controllerKindJob.Kind:
 		pods, workloadReplicas, err = r.getPodJob(targetRef, ws.Namespace)
 	default:
-		r.recorder.Eventf(ws, corev1.EventTypeWarning,
-			"TargetReferenceError", "targetReference is not been recognized")
-		return nil, -1, nil
+		if !wsutil.IsCustomWorkload(targetRef) {
+			r.recorder.Eventf(ws, corev1.EventTypeWarning,
+				"TargetReferenceError", "targetReference is not been recognized")
+			return nil, -1, nil
+		}
+		// custom workloads exposing the scale subresource, such as Argo Rollouts.
+		pods, workloadReplicas, err = r.controllerFinder.GetPodsForRef(targetRef.APIVersion, targetRef.Kind, targetRef.Name, ws.Namespace, false)
 	}
 
 	if err != nil {
@@ -284,7 +292,7 @@ func (r *ReconcileWorkloadSpread) getPodsForWorkloadSpread(ws *appsv1alpha1.Work
 // syncWorkloadSpread is the main logic of the WorkloadSpread controller. Firstly, we get Pods from workload managed by
 // WorkloadSpread and then classify these Pods to each corresponding subset. Secondly, we set Pod deletion-cost annotation
 // value by compare the number of subset's Pods with the subset's maxReplicas, and then we consider rescheduling failed Pods.
-// Lastly, we update the WorkloadSpread's Status and clean up scheduled failed Pods. controller should collaborate with webhook
+// Lastly, we update the WorkloadSpread's Status, clean up scheduled failed Pods and rebalance Pods between subsets. controller should collaborate with webhook
 // to maintain WorkloadSpread status together. The controller is responsible for calculating the real status, and the webhook
 // mainly counts missingReplicas and records the creation or deletion entry of Pod into map.
 func (r *ReconcileWorkloadSpread) syncWorkloadSpread(ws *appsv1alpha1.WorkloadSpread) error {
@@ -316,6 +324,7 @@ func (r *ReconcileWorkloadSpread) syncWorkloadSpread(ws *appsv1alpha1.WorkloadSp
 	if status == nil {
 		return nil
 	}
+	recordSubsetMetrics(ws, status)
 
 	// update status
 	err = r.UpdateWorkloadSpreadStatus(ws, status)
@@ -324,7 +333,16 @@ func (r *ReconcileWorkloadSpread) syncWorkloadSpread(ws *appsv1alpha1.WorkloadSp
 	}
 
 	// clean up unschedulable Pods
-	return r.cleanupUnscheduledPods(ws, scheduleFailedPodMap)
+	if err = r.cleanupUnscheduledPods(ws, scheduleFailedPodMap); err != nil {
+		return err
+	}
+
+	// rebalance Pods back to the higher-priority subsets
+	var scheduleFailed bool
+	for _, pods := range scheduleFailedPodMap {
+		scheduleFailed = scheduleFailed || len(pods) > 0
+	}
+	return r.rebalanceSubsets(ws, podMap, status, workloadReplicas, scheduleFailed)
 }
 
 func getInjectWorkloadSpreadFromPod(pod *corev1.Pod) *wsutil.InjectWorkloadSpread {
@@ -495,6 +513,9 @@ func (r *ReconcileWorkloadSpread) calculateWorkloadSpreadStatus(ws *appsv1alpha1
 		rescheduleCriticalSeconds = *ws.Spec.ScheduleStrategy.Adaptive.RescheduleCriticalSeconds
 	}
 
+	failover := getFailoverStrategy(ws)
+	nodes := r.listNodesForFailover(ws)
+
 	for i := 0; i < len(ws.Spec.Subsets); i++ {
 		subset := &ws.Spec.Subsets[i]
 
@@ -505,11 +526,17 @@ func (r *ReconcileWorkloadSpread) calculateWorkloadSpreadStatus(ws *appsv1alpha1
 			return nil, nil
 		}
 
-		// don't reschedule the last subset.
-		if rescheduleCriticalSeconds > 0 {
+		// don't reschedule or failover the last subset.
+		if rescheduleCriticalSeconds > 0 || failover != nil {
 			if i != len(ws.Spec.Subsets)-1 {
-				pods := r.rescheduleSubset(ws, podMap[subset.Name], subsetStatus, oldSubsetStatusMap[subset.Name])
-				scheduleFailedPodMap[subset.Name] = pods
+				var pods []*corev1.Pod
+				if rescheduleCriticalSeconds > 0 {
+					pods = r.rescheduleSubset(ws, podMap[subset.Name], subsetStatus, oldSubsetStatusMap[subset.Name])
+					scheduleFailedPodMap[subset.Name] = pods
+				}
+				if failover != nil {
+					r.failoverSubset(ws, subset, podMap[subset.Name], nodes, subsetStatus, oldSubsetStatusMap[subset.Name], len(pods) > 0)
+				}
 			} else {
 				oldCondition := GetWorkloadSpreadSubsetCondition(oldSubsetStatusMap[subset.Name], appsv1alpha1.SubsetSchedulable)
 				if oldCondition != nil {
@@ -637,9 +664,28 @@ func (r *ReconcileWorkloadSpread) calculateWorkloadSpreadSubsetStatus(ws *appsv1
 		}
 	}
 
+	setSubsetSaturationCondition(subsetStatus, oldSubsetStatus, subsetMaxReplicas)
 	return subsetStatus
 }
 
+// setSubsetSaturationCondition sets the SaturationReached condition of subset according to its missingReplicas,
+// the subset without maxReplicas will never be saturated.
+func setSubsetSaturationCondition(subsetStatus, oldSubsetStatus *appsv1alpha1.WorkloadSpreadSubsetStatus, subsetMaxReplicas int) {
+	if subsetMaxReplicas < 0 {
+		return
+	}
+	if oldCondition := GetWorkloadSpreadSubsetCondition(oldSubsetStatus, appsv1alpha1.SubsetSaturationReached); oldCondition != nil {
+		setWorkloadSpreadSubsetCondition(subsetStatus, oldCondition)
+	}
+	if subsetStatus.MissingReplicas == 0 {
+		setWorkloadSpreadSubsetCondition(subsetStatus, NewWorkloadSpreadSubsetCondition(appsv1alpha1.SubsetSaturationReached,
+			corev1.ConditionTrue, "MaxReplicasReached", "the active and creating pods have reached maxReplicas"))
+	} else {
+		setWorkloadSpreadSubsetCondition(subsetStatus, NewWorkloadSpreadSubsetCondition(appsv1alpha1.SubsetSaturationReached,
+			corev1.ConditionFalse, "MissingReplicas", "the active and creating pods are less than maxReplicas"))
+	}
+}
+
 func (r *ReconcileWorkloadSpread) UpdateWorkloadSpreadStatus(ws *appsv1alpha1.WorkloadSpread,
 	status *appsv1alpha1.WorkloadSpreadStatus) error {
 	if status.ObservedGeneration == ws.Status.ObservedGeneration &&
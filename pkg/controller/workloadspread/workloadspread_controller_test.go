@@ -676,6 +676,71 @@ func TestSubsetPodDeletionCost(t *testing.T) {
 				return pods
 			},
 		},
+		{
+			name: "tiered deletion-cost, subsetsLen = 2, subsetIndex = 0, maxReplicas is 3, pods number is 3",
+			getPods: func() []*corev1.Pod {
+				pods := make([]*corev1.Pod, 3)
+				for i := range pods {
+					pods[i] = podDemo.DeepCopy()
+					pods[i].Name = fmt.Sprintf("test-pods-%d", i)
+					pods[i].CreationTimestamp = metav1.NewTime(time.Now().Add(time.Duration(i) * time.Minute))
+				}
+				return pods
+			},
+			getWorkloadSpread: func() *appsv1alpha1.WorkloadSpread {
+				workloadSpread := workloadSpreadDemo.DeepCopy()
+				workloadSpread.Spec.DeletionCostPolicy = appsv1alpha1.TieredWorkloadSpreadDeletionCostPolicyType
+				workloadSpread.Spec.Subsets = make([]appsv1alpha1.WorkloadSpreadSubset, 2)
+				workloadSpread.Spec.Subsets[0].MaxReplicas = &intstr.IntOrString{Type: intstr.Int, IntVal: 3}
+				workloadSpread.Spec.Subsets[1].MaxReplicas = &intstr.IntOrString{Type: intstr.Int, IntVal: 3}
+				return workloadSpread
+			},
+			expectPods: func() []*corev1.Pod {
+				pods := make([]*corev1.Pod, 3)
+				for i := range pods {
+					pods[i] = podDemo.DeepCopy()
+					pods[i].Name = fmt.Sprintf("test-pods-%d", i)
+				}
+				// the newer pods are deleted first
+				pods[0].Annotations = map[string]string{PodDeletionCostAnnotation: "202"}
+				pods[1].Annotations = map[string]string{PodDeletionCostAnnotation: "201"}
+				pods[2].Annotations = map[string]string{PodDeletionCostAnnotation: "200"}
+				return pods
+			},
+		},
+		{
+			name:        "tiered deletion-cost, subsetsLen = 2, subsetIndex = 1, maxReplicas is 3, pods number is 4",
+			subsetIndex: 1,
+			getPods: func() []*corev1.Pod {
+				pods := make([]*corev1.Pod, 4)
+				for i := range pods {
+					pods[i] = podDemo.DeepCopy()
+					pods[i].Name = fmt.Sprintf("test-pods-%d", i)
+					pods[i].CreationTimestamp = metav1.NewTime(time.Now().Add(time.Duration(i) * time.Minute))
+				}
+				return pods
+			},
+			getWorkloadSpread: func() *appsv1alpha1.WorkloadSpread {
+				workloadSpread := workloadSpreadDemo.DeepCopy()
+				workloadSpread.Spec.DeletionCostPolicy = appsv1alpha1.TieredWorkloadSpreadDeletionCostPolicyType
+				workloadSpread.Spec.Subsets = make([]appsv1alpha1.WorkloadSpreadSubset, 2)
+				workloadSpread.Spec.Subsets[0].MaxReplicas = &intstr.IntOrString{Type: intstr.Int, IntVal: 3}
+				workloadSpread.Spec.Subsets[1].MaxReplicas = &intstr.IntOrString{Type: intstr.Int, IntVal: 3}
+				return workloadSpread
+			},
+			expectPods: func() []*corev1.Pod {
+				pods := make([]*corev1.Pod, 4)
+				for i := range pods {
+					pods[i] = podDemo.DeepCopy()
+					pods[i].Name = fmt.Sprintf("test-pods-%d", i)
+				}
+				pods[0].Annotations = map[string]string{PodDeletionCostAnnotation: "102"}
+				pods[1].Annotations = map[string]string{PodDeletionCostAnnotation: "101"}
+				pods[2].Annotations = map[string]string{PodDeletionCostAnnotation: "100"}
+				pods[3].Annotations = map[string]string{PodDeletionCostAnnotation: "-200"}
+				return pods
+			},
+		},
 	}
 	for _, cs := range cases {
 		t.Run(cs.name, func(t *testing.T) {
@@ -1436,6 +1501,7 @@ func TestWorkloadSpreadReconcile(t *testing.T) {
 			}
 
 			latestStatus := latestWorkloadSpread.Status
+			checkAndTrimSaturationCondition(t, &latestStatus)
 			by, _ := json.Marshal(latestStatus)
 			fmt.Println(string(by))
 
@@ -1450,6 +1516,29 @@ func TestWorkloadSpreadReconcile(t *testing.T) {
 	}
 }
 
+// checkAndTrimSaturationCondition checks the SaturationReached condition of subsets is consistent with missingReplicas,
+// and then removes it to compare the other fields of status.
+func checkAndTrimSaturationCondition(t *testing.T, status *appsv1alpha1.WorkloadSpreadStatus) {
+	for i := range status.SubsetStatuses {
+		subsetStatus := &status.SubsetStatuses[i]
+		condition := GetWorkloadSpreadSubsetCondition(subsetStatus, appsv1alpha1.SubsetSaturationReached)
+		if subsetStatus.MissingReplicas < 0 {
+			if condition != nil {
+				t.Fatalf("expect no SaturationReached condition for subset %s without maxReplicas", subsetStatus.Name)
+			}
+			continue
+		}
+		expectStatus := corev1.ConditionFalse
+		if subsetStatus.MissingReplicas == 0 {
+			expectStatus = corev1.ConditionTrue
+		}
+		if condition == nil || condition.Status != expectStatus {
+			t.Fatalf("expect SaturationReached condition %s for subset %s, but got %v", expectStatus, subsetStatus.Name, condition)
+		}
+		removeWorkloadSpreadSubsetCondition(subsetStatus, appsv1alpha1.SubsetSaturationReached)
+	}
+}
+
 // This test checks that user changes subsets sequence of WorkloadSpreadSpec.
 func TestUpdateSubsetSequence(t *testing.T) {
 	pods := make([]*corev1.Pod, 2)
@@ -0,0 +1,102 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloadspread
+
+import (
+	"sync"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	subsetReplicasGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kruise_workloadspread_subset_replicas",
+			Help: "Number of active replicas in the subset of WorkloadSpread",
+		},
+		[]string{"namespace", "name", "subset"},
+	)
+
+	// subsetMissingReplicasGauge is the missingReplicas of the subset, -1 means the subset has no maxReplicas
+	subsetMissingReplicasGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kruise_workloadspread_subset_missing_replicas",
+			Help: "Number of replicas the subset of WorkloadSpread is missing to reach maxReplicas",
+		},
+		[]string{"namespace", "name", "subset"},
+	)
+
+	subsetRescheduleCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kruise_workloadspread_subset_rescheduled_pods_total",
+			Help: "Number of unschedulable pods deleted from the subset of WorkloadSpread for rescheduling",
+		},
+		[]string{"namespace", "name", "subset"},
+	)
+
+	// recordedSubsets records the subsets with metrics of each WorkloadSpread, so that the metrics can be deleted
+	// after the subsets or the WorkloadSpread are deleted.
+	recordedSubsets     = map[string]sets.String{}
+	recordedSubsetsLock sync.Mutex
+)
+
+func init() {
+	metrics.Registry.MustRegister(subsetReplicasGauge, subsetMissingReplicasGauge, subsetRescheduleCounter)
+}
+
+// recordSubsetMetrics records the replicas and missingReplicas of each subset from the calculated status,
+// and deletes the metrics of the subsets removed from WorkloadSpread.
+func recordSubsetMetrics(ws *appsv1alpha1.WorkloadSpread, status *appsv1alpha1.WorkloadSpreadStatus) {
+	subsets := sets.NewString()
+	for _, subsetStatus := range status.SubsetStatuses {
+		subsets.Insert(subsetStatus.Name)
+		subsetReplicasGauge.WithLabelValues(ws.Namespace, ws.Name, subsetStatus.Name).Set(float64(subsetStatus.Replicas))
+		subsetMissingReplicasGauge.WithLabelValues(ws.Namespace, ws.Name, subsetStatus.Name).Set(float64(subsetStatus.MissingReplicas))
+	}
+
+	recordedSubsetsLock.Lock()
+	defer recordedSubsetsLock.Unlock()
+	key := getWorkloadSpreadKey(ws)
+	for _, subset := range recordedSubsets[key].Difference(subsets).UnsortedList() {
+		deleteSubsetMetrics(ws.Namespace, ws.Name, subset)
+	}
+	recordedSubsets[key] = subsets
+}
+
+func recordSubsetReschedule(ws *appsv1alpha1.WorkloadSpread, subset string) {
+	subsetRescheduleCounter.WithLabelValues(ws.Namespace, ws.Name, subset).Inc()
+}
+
+// deleteMetrics deletes all metrics of the WorkloadSpread, which has been deleted.
+func deleteMetrics(namespace, name string) {
+	recordedSubsetsLock.Lock()
+	defer recordedSubsetsLock.Unlock()
+	key := namespace + "/" + name
+	for subset := range recordedSubsets[key] {
+		deleteSubsetMetrics(namespace, name, subset)
+	}
+	delete(recordedSubsets, key)
+}
+
+func deleteSubsetMetrics(namespace, name, subset string) {
+	subsetReplicasGauge.DeleteLabelValues(namespace, name, subset)
+	subsetMissingReplicasGauge.DeleteLabelValues(namespace, name, subset)
+	subsetRescheduleCounter.DeleteLabelValues(namespace, name, subset)
+}
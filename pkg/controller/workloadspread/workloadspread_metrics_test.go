@@ -0,0 +1,64 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloadspread
+
+import (
+	"testing"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	utilmetrics "github.com/openkruise/kruise/pkg/util/metrics"
+)
+
+func TestWorkloadSpreadMetrics(t *testing.T) {
+	ws := workloadSpreadDemo.DeepCopy()
+	ws.Name = "test-workloadspread-metrics"
+	status := &appsv1alpha1.WorkloadSpreadStatus{
+		SubsetStatuses: []appsv1alpha1.WorkloadSpreadSubsetStatus{
+			{Name: "subset-a", Replicas: 3, MissingReplicas: 2},
+			{Name: "subset-b", Replicas: 1, MissingReplicas: -1},
+		},
+	}
+
+	recordSubsetMetrics(ws, status)
+	if value := utilmetrics.GetMetricValueDuringTest(t, subsetReplicasGauge.WithLabelValues(ws.Namespace, ws.Name, "subset-a")); value != 3 {
+		t.Fatalf("expected 3 replicas of subset-a, got %v", value)
+	}
+	if value := utilmetrics.GetMetricValueDuringTest(t, subsetMissingReplicasGauge.WithLabelValues(ws.Namespace, ws.Name, "subset-a")); value != 2 {
+		t.Fatalf("expected 2 missing replicas of subset-a, got %v", value)
+	}
+	if value := utilmetrics.GetMetricValueDuringTest(t, subsetMissingReplicasGauge.WithLabelValues(ws.Namespace, ws.Name, "subset-b")); value != -1 {
+		t.Fatalf("expected -1 missing replicas of subset-b, got %v", value)
+	}
+
+	recordSubsetReschedule(ws, "subset-a")
+	if value := utilmetrics.GetMetricValueDuringTest(t, subsetRescheduleCounter.WithLabelValues(ws.Namespace, ws.Name, "subset-a")); value != 1 {
+		t.Fatalf("expected 1 rescheduled pod of subset-a, got %v", value)
+	}
+
+	// subset-b is removed from WorkloadSpread
+	status.SubsetStatuses = status.SubsetStatuses[:1]
+	recordSubsetMetrics(ws, status)
+	if subsetReplicasGauge.DeleteLabelValues(ws.Namespace, ws.Name, "subset-b") {
+		t.Fatalf("expected metrics of subset-b deleted")
+	}
+
+	deleteMetrics(ws.Namespace, ws.Name)
+	if subsetReplicasGauge.DeleteLabelValues(ws.Namespace, ws.Name, "subset-a") ||
+		subsetRescheduleCounter.DeleteLabelValues(ws.Namespace, ws.Name, "subset-a") {
+		t.Fatalf("expected metrics of subset-a deleted")
+	}
+}
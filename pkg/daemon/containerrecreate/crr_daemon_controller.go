@@ -32,8 +32,10 @@ import (
 	daemonruntime "github.com/openkruise/kruise/pkg/daemon/criruntime"
 	"github.com/openkruise/kruise/pkg/daemon/kuberuntime"
 	daemonoptions "github.com/openkruise/kruise/pkg/daemon/options"
+	"github.com/openkruise/kruise/pkg/features"
 	"github.com/openkruise/kruise/pkg/util"
 	"github.com/openkruise/kruise/pkg/util/expectations"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -55,6 +57,8 @@ const (
 	workers = 32
 
 	maxExpectationWaitDuration = 10 * time.Second
+
+	waitingForPubMessage = "waiting for PodUnavailableBudget"
 )
 
 var (
@@ -239,7 +243,15 @@ func (c *Controller) sync(key string) (retErr error) {
 
 	// once first update its phase to recreating
 	if crr.Status.Phase != appsv1alpha1.ContainerRecreateRequestRecreating {
-		return c.updateCRRPhase(crr, appsv1alpha1.ContainerRecreateRequestRecreating)
+		// recreating containers makes pod unavailable, so it should wait until kruise-manager grants it by PodUnavailableBudget
+		if isWaitingForPodUnavailableBudget(crr) {
+			klog.Infof("CRR %s/%s is waiting for PodUnavailableBudget.", crr.Namespace, crr.Name)
+			if crr.Status.Phase == appsv1alpha1.ContainerRecreateRequestPending && crr.Status.Message == waitingForPubMessage {
+				return nil
+			}
+			return c.updateCRRPhaseWithMessage(crr, appsv1alpha1.ContainerRecreateRequestPending, waitingForPubMessage)
+		}
+		return c.updateCRRPhaseWithMessage(crr, appsv1alpha1.ContainerRecreateRequestRecreating, "")
 	}
 
 	if crr.Spec.Strategy.UnreadyGracePeriodSeconds != nil {
@@ -266,6 +278,14 @@ func (c *Controller) sync(key string) (retErr error) {
 	return c.manage(crr)
 }
 
+// isWaitingForPodUnavailableBudget returns whether the CRR should wait for kruise-manager to label it granted by PodUnavailableBudget.
+func isWaitingForPodUnavailableBudget(crr *appsv1alpha1.ContainerRecreateRequest) bool {
+	if !utilfeature.DefaultFeatureGate.Enabled(features.PodUnavailableBudgetUpdateGate) || crr.Spec.Strategy.ForceRecreate {
+		return false
+	}
+	return crr.Labels[appsv1alpha1.ContainerRecreateRequestPubGrantedKey] != "true"
+}
+
 func (c *Controller) pickRecreateRequest(crrList []*appsv1alpha1.ContainerRecreateRequest) (*appsv1alpha1.ContainerRecreateRequest, error) {
 	sort.Sort(crrListByPhaseAndCreated(crrList))
 	var picked *appsv1alpha1.ContainerRecreateRequest
@@ -345,12 +365,40 @@ func (c *Controller) manage(crr *appsv1alpha1.ContainerRecreateRequest) error {
 			break
 		}
 
+		// wait for the backoff if the last recreation has failed
+		if leftTime := getRetryBackoffLeft(crr, state); leftTime > 0 {
+			klog.V(4).Infof("CRR %s/%s is waiting %v to retry container %s", crr.Namespace, crr.Name, leftTime, state.Name)
+			c.queue.AddAfter(objectKey(crr), leftTime)
+			if crr.Spec.Strategy.OrderedRecreate {
+				break
+			}
+			continue
+		}
+
 		msg := fmt.Sprintf("Stopping container %s by ContainerRecreateRequest %s", state.Name, crr.Name)
-		err := runtimeManager.KillContainer(pod, kubeContainerStatus.ID, state.Name, msg, nil)
+		var err error
+		container := getCRRContainer(crr, state.Name)
+		if killPod := getPodToKillContainer(pod, container); container != nil && container.Signal != "" {
+			var signaled bool
+			signaled, err = runtimeManager.KillContainerWithSignal(killPod, kubeContainerStatus.ID, state.Name, msg, string(container.Signal))
+			if err == nil && !signaled {
+				c.eventRecorder.Eventf(crr, v1.EventTypeWarning, "FailedSignal",
+					"Failed to send %s to container %s, stopped it with the default stop signal", container.Signal, state.Name)
+				state.Message = fmt.Sprintf("failed to send %s, stopped by the default stop signal", container.Signal)
+			}
+		} else {
+			err = runtimeManager.KillContainer(killPod, kubeContainerStatus.ID, state.Name, msg, nil)
+		}
 		if err != nil {
 			klog.Errorf("Failed to kill container %s in Pod %s/%s for CRR %s/%s: %v", state.Name, pod.Namespace, pod.Name, crr.Namespace, crr.Name, err)
-			state.Phase = appsv1alpha1.ContainerRecreateRequestFailed
 			state.Message = fmt.Sprintf("kill container error: %v", err)
+			if container != nil && state.Retries < container.MaxRetries {
+				state.Retries++
+				state.LastFailureTime = &metav1.Time{Time: time.Now()}
+				c.queue.AddAfter(objectKey(crr), getRetryBackoff(crr, state.Retries))
+				return c.patchCRRContainerRecreateStates(crr, newCRRContainerRecreateStates)
+			}
+			state.Phase = appsv1alpha1.ContainerRecreateRequestFailed
 			if crr.Spec.Strategy.FailurePolicy == appsv1alpha1.ContainerRecreateRequestFailurePolicyIgnore {
 				continue
 			}
@@ -369,8 +417,9 @@ func (c *Controller) manage(crr *appsv1alpha1.ContainerRecreateRequest) error {
 		return c.completeCRRStatus(crr, "")
 	}
 
-	if crr.Spec.Strategy != nil && crr.Spec.Strategy.MinStartedSeconds > 0 {
-		c.queue.AddAfter(objectKey(crr), time.Duration(crr.Spec.Strategy.MinStartedSeconds)*time.Second)
+	// check again after minStartedSeconds or readinessTimeoutSeconds, for no event will trigger it
+	if recheckAfter := getRecheckDuration(crr); recheckAfter > 0 {
+		c.queue.AddAfter(objectKey(crr), recheckAfter)
 	}
 	return nil
 }
@@ -389,8 +438,13 @@ func (c *Controller) patchCRRContainerRecreateStates(crr *appsv1alpha1.Container
 }
 
 func (c *Controller) updateCRRPhase(crr *appsv1alpha1.ContainerRecreateRequest, phase appsv1alpha1.ContainerRecreateRequestPhase) error {
+	return c.updateCRRPhaseWithMessage(crr, phase, crr.Status.Message)
+}
+
+func (c *Controller) updateCRRPhaseWithMessage(crr *appsv1alpha1.ContainerRecreateRequest, phase appsv1alpha1.ContainerRecreateRequestPhase, msg string) error {
 	crr = crr.DeepCopy()
 	crr.Status.Phase = phase
+	crr.Status.Message = msg
 	oldRev := crr.ResourceVersion
 	defer func() {
 		if crr.ResourceVersion != oldRev {
@@ -0,0 +1,61 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package containerrecreate
+
+import (
+	"testing"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/features"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsWaitingForPodUnavailableBudget(t *testing.T) {
+	newCRR := func(granted, force bool) *appsv1alpha1.ContainerRecreateRequest {
+		crr := &appsv1alpha1.ContainerRecreateRequest{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{}},
+			Spec: appsv1alpha1.ContainerRecreateRequestSpec{
+				Strategy: &appsv1alpha1.ContainerRecreateRequestStrategy{ForceRecreate: force},
+			},
+		}
+		if granted {
+			crr.Labels[appsv1alpha1.ContainerRecreateRequestPubGrantedKey] = "true"
+		}
+		return crr
+	}
+
+	cases := []struct {
+		name        string
+		gateEnabled bool
+		crr         *appsv1alpha1.ContainerRecreateRequest
+		expected    bool
+	}{
+		{name: "gate disabled", gateEnabled: false, crr: newCRR(false, false), expected: false},
+		{name: "not granted", gateEnabled: true, crr: newCRR(false, false), expected: true},
+		{name: "granted", gateEnabled: true, crr: newCRR(true, false), expected: false},
+		{name: "force recreate", gateEnabled: true, crr: newCRR(false, true), expected: false},
+	}
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			defer utilfeature.SetFeatureGateDuringTest(t, utilfeature.DefaultFeatureGate, features.PodUnavailableBudgetUpdateGate, cs.gateEnabled)()
+			if got := isWaitingForPodUnavailableBudget(cs.crr); got != cs.expected {
+				t.Fatalf("expected %v, got %v", cs.expected, got)
+			}
+		})
+	}
+}
@@ -18,6 +18,7 @@ package containerrecreate
 
 import (
 	"encoding/json"
+	"fmt"
 	"time"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
@@ -29,6 +30,11 @@ import (
 	utilpointer "k8s.io/utils/pointer"
 )
 
+const (
+	defaultRetryBackoff = 10 * time.Second
+	maxRetryBackoff     = 5 * time.Minute
+)
+
 type crrListByPhaseAndCreated []*appsv1alpha1.ContainerRecreateRequest
 
 func (c crrListByPhaseAndCreated) Len() int      { return len(c) }
@@ -57,11 +63,6 @@ func getCurrentCRRContainersRecreateStates(
 	podStatus *kubeletcontainer.PodStatus,
 ) []appsv1alpha1.ContainerRecreateRequestContainerRecreateState {
 
-	var minStartedDuration time.Duration
-	if crr.Spec.Strategy != nil {
-		minStartedDuration = time.Duration(crr.Spec.Strategy.MinStartedSeconds) * time.Second
-	}
-
 	syncContainerStatuses := getCRRSyncContainerStatuses(crr)
 	var statuses []appsv1alpha1.ContainerRecreateRequestContainerRecreateState
 
@@ -75,44 +76,52 @@ func getCurrentCRRContainersRecreateStates(
 			continue
 		}
 
+		// the retry context is inherited from the previous state
+		var currentState appsv1alpha1.ContainerRecreateRequestContainerRecreateState
+		if previousContainerRecreateState != nil {
+			currentState.Retries = previousContainerRecreateState.Retries
+			currentState.LastFailureTime = previousContainerRecreateState.LastFailureTime
+			currentState.ContainerID = previousContainerRecreateState.ContainerID
+		}
+		currentState.Name = c.Name
+
 		syncContainerStatus := syncContainerStatuses[c.Name]
 		kubeContainerStatus := podStatus.FindContainerStatusByName(c.Name)
 
-		var currentState appsv1alpha1.ContainerRecreateRequestContainerRecreateState
 		if kubeContainerStatus == nil {
 			// not found the real container
-			currentState = appsv1alpha1.ContainerRecreateRequestContainerRecreateState{
-				Name:    c.Name,
-				Phase:   appsv1alpha1.ContainerRecreateRequestPending,
-				Message: "not found container on Node",
-			}
+			currentState.Phase = appsv1alpha1.ContainerRecreateRequestPending
+			currentState.Message = "not found container on Node"
 
 		} else if kubeContainerStatus.State != kubeletcontainer.ContainerStateRunning {
 			// for no-running state, we consider it will be recreated or restarted soon
-			currentState = appsv1alpha1.ContainerRecreateRequestContainerRecreateState{
-				Name:  c.Name,
-				Phase: appsv1alpha1.ContainerRecreateRequestRecreating,
-			}
+			currentState.Phase = appsv1alpha1.ContainerRecreateRequestRecreating
 
-		} else if kubeContainerStatus.ID.String() != c.StatusContext.ContainerID ||
-			kubeContainerStatus.RestartCount > int(c.StatusContext.RestartCount) ||
-			kubeContainerStatus.StartedAt.After(crr.CreationTimestamp.Time) {
+		} else if isContainerRecreated(crr, c, currentState.ContainerID, kubeContainerStatus) {
 			// already recreated or restarted
-			currentState = appsv1alpha1.ContainerRecreateRequestContainerRecreateState{
-				Name:  c.Name,
-				Phase: appsv1alpha1.ContainerRecreateRequestRecreating,
-			}
-			if syncContainerStatus != nil &&
-				syncContainerStatus.ContainerID == kubeContainerStatus.ID.String() &&
-				time.Since(kubeContainerStatus.StartedAt) > minStartedDuration &&
-				syncContainerStatus.Ready {
+			currentState.Phase = appsv1alpha1.ContainerRecreateRequestRecreating
+			ready := syncContainerStatus != nil && syncContainerStatus.ContainerID == kubeContainerStatus.ID.String() && syncContainerStatus.Ready
+			if ready && time.Since(kubeContainerStatus.StartedAt) > getMinStartedDuration(crr, c) {
 				currentState.Phase = appsv1alpha1.ContainerRecreateRequestSucceeded
+			} else if !ready && c.ReadinessTimeoutSeconds != nil &&
+				time.Since(kubeContainerStatus.StartedAt) > time.Duration(*c.ReadinessTimeoutSeconds)*time.Second {
+				currentState.Message = fmt.Sprintf("container %s is not ready in %ds", kubeContainerStatus.ID.String(), *c.ReadinessTimeoutSeconds)
+				if currentState.Retries < c.MaxRetries {
+					// recreate the new container again after backoff
+					currentState.Phase = appsv1alpha1.ContainerRecreateRequestPending
+					currentState.Retries++
+					currentState.LastFailureTime = &metav1.Time{Time: time.Now()}
+					currentState.ContainerID = kubeContainerStatus.ID.String()
+				} else {
+					currentState.Phase = appsv1alpha1.ContainerRecreateRequestFailed
+				}
 			}
 
 		} else {
-			currentState = appsv1alpha1.ContainerRecreateRequestContainerRecreateState{
-				Name:  c.Name,
-				Phase: appsv1alpha1.ContainerRecreateRequestPending,
+			currentState.Phase = appsv1alpha1.ContainerRecreateRequestPending
+			if currentState.LastFailureTime != nil {
+				// keep the reason of last failure until retry
+				currentState.Message = previousContainerRecreateState.Message
 			}
 		}
 
@@ -122,6 +131,88 @@ func getCurrentCRRContainersRecreateStates(
 	return statuses
 }
 
+// isContainerRecreated returns whether the container has been recreated since the CRR created,
+// or since the last retry if retryContainerID is not empty.
+func isContainerRecreated(crr *appsv1alpha1.ContainerRecreateRequest, c *appsv1alpha1.ContainerRecreateRequestContainer,
+	retryContainerID string, kubeContainerStatus *kubeletcontainer.Status) bool {
+	if retryContainerID != "" {
+		return kubeContainerStatus.ID.String() != retryContainerID
+	}
+	return kubeContainerStatus.ID.String() != c.StatusContext.ContainerID ||
+		kubeContainerStatus.RestartCount > int(c.StatusContext.RestartCount) ||
+		kubeContainerStatus.StartedAt.After(crr.CreationTimestamp.Time)
+}
+
+func getMinStartedDuration(crr *appsv1alpha1.ContainerRecreateRequest, c *appsv1alpha1.ContainerRecreateRequestContainer) time.Duration {
+	if c.MinStartedSeconds != nil {
+		return time.Duration(*c.MinStartedSeconds) * time.Second
+	}
+	if crr.Spec.Strategy != nil {
+		return time.Duration(crr.Spec.Strategy.MinStartedSeconds) * time.Second
+	}
+	return 0
+}
+
+// getRetryBackoff returns the delay before the given retry, which doubles on each retry and is capped at maxRetryBackoff.
+func getRetryBackoff(crr *appsv1alpha1.ContainerRecreateRequest, retries int32) time.Duration {
+	backoff := defaultRetryBackoff
+	if crr.Spec.Strategy != nil && crr.Spec.Strategy.RetryBackoffSeconds != nil {
+		backoff = time.Duration(*crr.Spec.Strategy.RetryBackoffSeconds) * time.Second
+	}
+	for i := int32(1); i < retries && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxRetryBackoff {
+		backoff = maxRetryBackoff
+	}
+	return backoff
+}
+
+// getRetryBackoffLeft returns the duration left to wait before retrying the container.
+func getRetryBackoffLeft(crr *appsv1alpha1.ContainerRecreateRequest, state *appsv1alpha1.ContainerRecreateRequestContainerRecreateState) time.Duration {
+	if state.LastFailureTime == nil {
+		return 0
+	}
+	return getRetryBackoff(crr, state.Retries) - time.Since(state.LastFailureTime.Time)
+}
+
+// getRecheckDuration returns the minimal duration of minStartedSeconds and readinessTimeoutSeconds of the containers.
+func getRecheckDuration(crr *appsv1alpha1.ContainerRecreateRequest) time.Duration {
+	var recheckAfter time.Duration
+	update := func(d time.Duration) {
+		if d > 0 && (recheckAfter == 0 || d < recheckAfter) {
+			recheckAfter = d
+		}
+	}
+	for i := range crr.Spec.Containers {
+		c := &crr.Spec.Containers[i]
+		update(getMinStartedDuration(crr, c))
+		if c.ReadinessTimeoutSeconds != nil {
+			update(time.Duration(*c.ReadinessTimeoutSeconds) * time.Second)
+		}
+	}
+	return recheckAfter
+}
+
+func getCRRContainer(crr *appsv1alpha1.ContainerRecreateRequest, name string) *appsv1alpha1.ContainerRecreateRequestContainer {
+	for i := range crr.Spec.Containers {
+		if crr.Spec.Containers[i].Name == name {
+			return &crr.Spec.Containers[i]
+		}
+	}
+	return nil
+}
+
+// getPodToKillContainer returns the pod with the termination grace period of the container, if it is overridden.
+func getPodToKillContainer(pod *v1.Pod, c *appsv1alpha1.ContainerRecreateRequestContainer) *v1.Pod {
+	if c == nil || c.TerminationGracePeriodSeconds == nil {
+		return pod
+	}
+	pod = pod.DeepCopy()
+	pod.Spec.TerminationGracePeriodSeconds = c.TerminationGracePeriodSeconds
+	return pod
+}
+
 func getCRRContainerRecreateState(crr *appsv1alpha1.ContainerRecreateRequest, name string) *appsv1alpha1.ContainerRecreateRequestContainerRecreateState {
 	for i := range crr.Status.ContainerRecreateStates {
 		c := &crr.Status.ContainerRecreateStates[i]
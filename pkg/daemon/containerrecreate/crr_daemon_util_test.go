@@ -0,0 +1,143 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package containerrecreate
+
+import (
+	"testing"
+	"time"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeletcontainer "k8s.io/kubernetes/pkg/kubelet/container"
+	utilpointer "k8s.io/utils/pointer"
+)
+
+func TestGetCurrentCRRContainersRecreateStatesWithRetry(t *testing.T) {
+	created := time.Now().Add(-time.Hour)
+	crr := &appsv1alpha1.ContainerRecreateRequest{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "crr", CreationTimestamp: metav1.NewTime(created)},
+		Spec: appsv1alpha1.ContainerRecreateRequestSpec{
+			PodName: "pod-0",
+			Containers: []appsv1alpha1.ContainerRecreateRequestContainer{
+				{
+					Name:                    "main",
+					ReadinessTimeoutSeconds: utilpointer.Int32(60),
+					MaxRetries:              1,
+					StatusContext:           &appsv1alpha1.ContainerRecreateRequestContainerContext{ContainerID: "containerd://main-0"},
+				},
+			},
+			Strategy: &appsv1alpha1.ContainerRecreateRequestStrategy{},
+		},
+	}
+	newPodStatus := func(id string, startedAt time.Time) *kubeletcontainer.PodStatus {
+		return &kubeletcontainer.PodStatus{ContainerStatuses: []*kubeletcontainer.Status{{
+			ID:        kubeletcontainer.ContainerID{Type: "containerd", ID: id},
+			Name:      "main",
+			State:     kubeletcontainer.ContainerStateRunning,
+			StartedAt: startedAt,
+		}}}
+	}
+	setReady := func(id string, ready bool) {
+		crr.Annotations = map[string]string{appsv1alpha1.ContainerRecreateRequestSyncContainerStatusesKey: util.DumpJSON(
+			[]appsv1alpha1.ContainerRecreateRequestSyncContainerStatus{{Name: "main", Ready: ready, ContainerID: "containerd://" + id}},
+		)}
+	}
+
+	// the original container is not recreated yet
+	states := getCurrentCRRContainersRecreateStates(crr, newPodStatus("main-0", created.Add(-time.Hour)))
+	if states[0].Phase != appsv1alpha1.ContainerRecreateRequestPending {
+		t.Fatalf("expected Pending, got %#v", states[0])
+	}
+
+	// the new container is not ready within readinessTimeoutSeconds, so it should retry
+	setReady("main-1", false)
+	states = getCurrentCRRContainersRecreateStates(crr, newPodStatus("main-1", time.Now().Add(-2*time.Minute)))
+	if s := states[0]; s.Phase != appsv1alpha1.ContainerRecreateRequestPending || s.Retries != 1 || s.LastFailureTime == nil || s.ContainerID != "containerd://main-1" {
+		t.Fatalf("expected retry, got %#v", s)
+	}
+	crr.Status.ContainerRecreateStates = states
+
+	// still waiting for the failed container to be recreated
+	states = getCurrentCRRContainersRecreateStates(crr, newPodStatus("main-1", time.Now().Add(-2*time.Minute)))
+	if s := states[0]; s.Phase != appsv1alpha1.ContainerRecreateRequestPending || s.Retries != 1 || s.Message == "" {
+		t.Fatalf("expected Pending for retry, got %#v", s)
+	}
+	if left := getRetryBackoffLeft(crr, &states[0]); left <= 0 || left > defaultRetryBackoff {
+		t.Fatalf("unexpected backoff left %v", left)
+	}
+
+	// the retried container is not ready again, and no retries left
+	setReady("main-2", false)
+	states = getCurrentCRRContainersRecreateStates(crr, newPodStatus("main-2", time.Now().Add(-2*time.Minute)))
+	if s := states[0]; s.Phase != appsv1alpha1.ContainerRecreateRequestFailed || s.Retries != 1 {
+		t.Fatalf("expected Failed, got %#v", s)
+	}
+
+	// the retried container is ready
+	setReady("main-2", true)
+	states = getCurrentCRRContainersRecreateStates(crr, newPodStatus("main-2", time.Now().Add(-2*time.Minute)))
+	if s := states[0]; s.Phase != appsv1alpha1.ContainerRecreateRequestSucceeded || s.Retries != 1 {
+		t.Fatalf("expected Succeeded, got %#v", s)
+	}
+}
+
+func TestGetRetryBackoff(t *testing.T) {
+	crr := &appsv1alpha1.ContainerRecreateRequest{Spec: appsv1alpha1.ContainerRecreateRequestSpec{Strategy: &appsv1alpha1.ContainerRecreateRequestStrategy{}}}
+	cases := []struct {
+		backoffSeconds *int32
+		retries        int32
+		expected       time.Duration
+	}{
+		{retries: 1, expected: 10 * time.Second},
+		{retries: 3, expected: 40 * time.Second},
+		{retries: 10, expected: maxRetryBackoff},
+		{backoffSeconds: utilpointer.Int32(1), retries: 2, expected: 2 * time.Second},
+		{backoffSeconds: utilpointer.Int32(0), retries: 5, expected: 0},
+	}
+	for i, tc := range cases {
+		crr.Spec.Strategy.RetryBackoffSeconds = tc.backoffSeconds
+		if got := getRetryBackoff(crr, tc.retries); got != tc.expected {
+			t.Fatalf("case #%d expected %v, got %v", i, tc.expected, got)
+		}
+	}
+}
+
+func TestGetPodToKillContainer(t *testing.T) {
+	crr := &appsv1alpha1.ContainerRecreateRequest{
+		Spec: appsv1alpha1.ContainerRecreateRequestSpec{
+			PodName: "pod-0",
+			Containers: []appsv1alpha1.ContainerRecreateRequestContainer{
+				{Name: "main"},
+				{Name: "sidecar", TerminationGracePeriodSeconds: utilpointer.Int64(5)},
+			},
+			Strategy: &appsv1alpha1.ContainerRecreateRequestStrategy{TerminationGracePeriodSeconds: utilpointer.Int64(60)},
+		},
+	}
+	pod := convertCRRToPod(crr)
+
+	if got := getPodToKillContainer(pod, getCRRContainer(crr, "main")); got != pod {
+		t.Fatalf("expected the original pod for container without override")
+	}
+	got := getPodToKillContainer(pod, getCRRContainer(crr, "sidecar"))
+	if *got.Spec.TerminationGracePeriodSeconds != 5 {
+		t.Fatalf("expected grace period 5, got %d", *got.Spec.TerminationGracePeriodSeconds)
+	}
+	if *pod.Spec.TerminationGracePeriodSeconds != 60 {
+		t.Fatalf("expected the original pod unchanged, got %d", *pod.Spec.TerminationGracePeriodSeconds)
+	}
+}
@@ -21,8 +21,11 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
 	runtimeimage "github.com/openkruise/kruise/pkg/daemon/criruntime/imageruntime"
 	daemonutil "github.com/openkruise/kruise/pkg/daemon/util"
 	criapi "k8s.io/cri-api/pkg/apis"
@@ -34,10 +37,15 @@ import (
 
 const (
 	kubeRuntimeAPIVersion = "0.1.0"
+
+	// kubeadmCRISocketAnnotationKey is annotated on the Node by kubeadm with the CRI socket used by kubelet
+	kubeadmCRISocketAnnotationKey = "kubeadm.alpha.kubernetes.io/cri-socket"
 )
 
 var (
-	CRISocketFileName = flag.String("socket-file", "", "The name of CRI socket file, and it should be in the mounted /hostvarrun directory.")
+	CRISocketFileName   = flag.String("socket-file", "", "The name of CRI socket file, and it should be in the mounted /hostvarrun directory.")
+	ContainerdNamespace = flag.String("containerd-namespace", runtimeimage.K8sContainerdNamespace,
+		"The containerd namespace which images are pulled into, it can be overridden by the annotation apps.kruise.io/containerd-namespace on each node.")
 )
 
 // Factory is the interface to get container and image runtime service
@@ -73,8 +81,9 @@ type runtimeImpl struct {
 	runtimeService criapi.RuntimeService
 }
 
-func NewFactory(varRunPath string, accountManager daemonutil.ImagePullAccountManager) (Factory, error) {
-	cfgs := detectRuntime(varRunPath)
+// NewFactory returns the Factory of the runtimes found on the node, which can be configured by the annotations on the node.
+func NewFactory(varRunPath string, annotations map[string]string, accountManager daemonutil.ImagePullAccountManager) (Factory, error) {
+	cfgs := detectRuntime(varRunPath, annotations)
 	if len(cfgs) == 0 {
 		return nil, fmt.Errorf("not found container runtime sock")
 	}
@@ -108,7 +117,7 @@ func NewFactory(varRunPath string, accountManager daemonutil.ImagePullAccountMan
 				klog.Warningf("Failed to get address for %v (%s, %s): %v", cfg.runtimeType, cfg.runtimeURI, cfg.runtimeRemoteURI, err)
 				continue
 			}
-			imageService, err = runtimeimage.NewContainerdImageService(addr, accountManager)
+			imageService, err = runtimeimage.NewContainerdImageService(addr, getContainerdNamespace(annotations), accountManager)
 			if err != nil {
 				klog.Warningf("Failed to new image service for %v (%s, %s): %v", cfg.runtimeType, cfg.runtimeURI, cfg.runtimeRemoteURI, err)
 				continue
@@ -173,10 +182,64 @@ func (f *factory) GetRuntimeServiceByName(runtimeName string) criapi.RuntimeServ
 	return nil
 }
 
-func detectRuntime(varRunPath string) (cfgs []runtimeConfig) {
+func getContainerdNamespace(annotations map[string]string) string {
+	if namespace := annotations[appsv1alpha1.NodeContainerdNamespaceAnnotationKey]; namespace != "" {
+		return namespace
+	}
+	return *ContainerdNamespace
+}
+
+// getRuntimeConfigFromSocket returns the runtime config of the CRI socket on the node, such as unix:///run/custom/containerd.sock,
+// which should be under /var/run or /run of the node so that it can be found in the mounted varRunPath.
+func getRuntimeConfigFromSocket(varRunPath, socket string) (*runtimeConfig, error) {
+	socketPath := strings.TrimPrefix(socket, "unix://")
+	var relativePath string
+	for _, prefix := range []string{"/var/run/", "/run/"} {
+		if strings.HasPrefix(socketPath, prefix) {
+			relativePath = strings.TrimPrefix(socketPath, prefix)
+			break
+		}
+	}
+	if relativePath == "" {
+		return nil, fmt.Errorf("CRI socket %s is not under /var/run or /run", socket)
+	}
+	for _, elem := range strings.Split(relativePath, "/") {
+		if elem == ".." {
+			return nil, fmt.Errorf("CRI socket %s should not contain '..'", socket)
+		}
+	}
+	if filepath.IsAbs(relativePath) {
+		return nil, fmt.Errorf("CRI socket %s should not contain an absolute path under /var/run or /run", socket)
+	}
+
+	filePath := filepath.Join(varRunPath, relativePath)
+	if _, err := os.Stat(filePath); err != nil {
+		return nil, err
+	}
+	cfg := &runtimeConfig{
+		runtimeType:      ContainerRuntimeCommonCRI,
+		runtimeRemoteURI: fmt.Sprintf("unix://%s", filePath),
+	}
+	if strings.Contains(filepath.Base(filePath), "containerd") {
+		cfg.runtimeType = ContainerRuntimeContainerd
+	}
+	return cfg, nil
+}
+
+func detectRuntime(varRunPath string, annotations map[string]string) (cfgs []runtimeConfig) {
 	var err error
 
-	// firstly check if it is configured from flag
+	// firstly check if it is configured by the annotation on the node
+	if socket := annotations[appsv1alpha1.NodeCRISocketAnnotationKey]; socket != "" {
+		cfg, err := getRuntimeConfigFromSocket(varRunPath, socket)
+		if err == nil {
+			klog.Infof("Find configured CRI socket %s with node annotation", socket)
+			return []runtimeConfig{*cfg}
+		}
+		klog.Errorf("Failed to find the CRI socket %s with node annotation: %v", socket, err)
+	}
+
+	// then check if it is configured from flag
 	if CRISocketFileName != nil && len(*CRISocketFileName) > 0 {
 		filePath := fmt.Sprintf("%s/%s", varRunPath, *CRISocketFileName)
 		if _, err = os.Stat(filePath); err == nil {
@@ -258,5 +321,18 @@ func detectRuntime(varRunPath string) (cfgs []runtimeConfig) {
 			})
 		}
 	}
+
+	// finally try the CRI socket annotated by kubeadm, which may be in a non-standard path
+	if len(cfgs) == 0 {
+		if socket := annotations[kubeadmCRISocketAnnotationKey]; socket != "" {
+			cfg, err := getRuntimeConfigFromSocket(varRunPath, socket)
+			if err == nil {
+				klog.Infof("Find CRI socket %s with kubeadm annotation", socket)
+				cfgs = append(cfgs, *cfg)
+			} else {
+				klog.Warningf("Failed to find the CRI socket %s with kubeadm annotation: %v", socket, err)
+			}
+		}
+	}
 	return cfgs
 }
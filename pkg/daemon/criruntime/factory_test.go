@@ -0,0 +1,130 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package criruntime
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+)
+
+func TestDetectRuntimeWithAnnotations(t *testing.T) {
+	varRunPath := t.TempDir()
+	for _, f := range []string{"containerd/containerd.sock", "custom/containerd-custom.sock", "custom/crio-custom.sock"} {
+		path := filepath.Join(varRunPath, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		expected    []runtimeConfig
+	}{
+		{
+			name: "standard path",
+			expected: []runtimeConfig{{
+				runtimeType:      ContainerRuntimeContainerd,
+				runtimeRemoteURI: "unix://" + varRunPath + "/containerd/containerd.sock",
+			}},
+		},
+		{
+			name:        "containerd socket annotated",
+			annotations: map[string]string{appsv1alpha1.NodeCRISocketAnnotationKey: "unix:///run/custom/containerd-custom.sock"},
+			expected: []runtimeConfig{{
+				runtimeType:      ContainerRuntimeContainerd,
+				runtimeRemoteURI: "unix://" + varRunPath + "/custom/containerd-custom.sock",
+			}},
+		},
+		{
+			name:        "cri socket annotated",
+			annotations: map[string]string{appsv1alpha1.NodeCRISocketAnnotationKey: "/var/run/custom/crio-custom.sock"},
+			expected: []runtimeConfig{{
+				runtimeType:      ContainerRuntimeCommonCRI,
+				runtimeRemoteURI: "unix://" + varRunPath + "/custom/crio-custom.sock",
+			}},
+		},
+		{
+			name:        "annotated socket not found",
+			annotations: map[string]string{appsv1alpha1.NodeCRISocketAnnotationKey: "/opt/containerd.sock"},
+			expected: []runtimeConfig{{
+				runtimeType:      ContainerRuntimeContainerd,
+				runtimeRemoteURI: "unix://" + varRunPath + "/containerd/containerd.sock",
+			}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfgs := detectRuntime(varRunPath, tc.annotations)
+			if !reflect.DeepEqual(cfgs, tc.expected) {
+				t.Fatalf("expected %+v, got %+v", tc.expected, cfgs)
+			}
+		})
+	}
+}
+
+func TestDetectRuntimeWithKubeadmAnnotation(t *testing.T) {
+	varRunPath := t.TempDir()
+	path := filepath.Join(varRunPath, "k3s/containerd/containerd.sock")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfgs := detectRuntime(varRunPath, map[string]string{kubeadmCRISocketAnnotationKey: "unix:///run/k3s/containerd/containerd.sock"})
+	expected := []runtimeConfig{{
+		runtimeType:      ContainerRuntimeContainerd,
+		runtimeRemoteURI: "unix://" + path,
+	}}
+	if !reflect.DeepEqual(cfgs, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, cfgs)
+	}
+
+	if ns := getContainerdNamespace(map[string]string{appsv1alpha1.NodeContainerdNamespaceAnnotationKey: "custom"}); ns != "custom" {
+		t.Fatalf("expected namespace custom, got %s", ns)
+	}
+	if ns := getContainerdNamespace(nil); ns != "k8s.io" {
+		t.Fatalf("expected namespace k8s.io, got %s", ns)
+	}
+}
+
+func TestGetRuntimeConfigFromSocketOutsideVarRun(t *testing.T) {
+	root := t.TempDir()
+	varRunPath := filepath.Join(root, "run")
+	if err := os.MkdirAll(varRunPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "containerd.sock"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, socket := range []string{"unix:///run/../containerd.sock", "/var/run/custom/../../containerd.sock", "/var/run//containerd.sock"} {
+		if cfg, err := getRuntimeConfigFromSocket(varRunPath, socket); err == nil {
+			t.Fatalf("expected socket %s rejected, got %+v", socket, cfg)
+		}
+	}
+}
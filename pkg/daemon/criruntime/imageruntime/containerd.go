@@ -25,6 +25,7 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/alibaba/pouch/pkg/jsonstream"
@@ -40,21 +41,28 @@ import (
 	daemonutil "github.com/openkruise/kruise/pkg/daemon/util"
 	"github.com/pkg/errors"
 	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/time/rate"
 	"google.golang.org/grpc"
 	v1 "k8s.io/api/core/v1"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1alpha2"
 	"k8s.io/klog/v2"
 )
 
 const (
-	defaultTag             = "latest"
-	k8sContainerdNamespace = "k8s.io"
+	defaultTag = "latest"
+	// K8sContainerdNamespace is the containerd namespace of the images used by kubelet through CRI
+	K8sContainerdNamespace = "k8s.io"
+	// pinnedImageLabelKey is the label of the images pinned by cri-containerd, such as the sandbox image
+	pinnedImageLabelKey   = "io.cri-containerd.pinned"
+	pinnedImageLabelValue = "pinned"
 )
 
-// NewContainerdImageService returns containerd-type ImageService
+// NewContainerdImageService returns containerd-type ImageService, which pulls images into the given namespace
 func NewContainerdImageService(
 	runtimeURI string,
+	namespace string,
 	accountManager daemonutil.ImagePullAccountManager,
 ) (ImageService, error) {
 	client, err := containerd.New(runtimeURI)
@@ -62,37 +70,44 @@ func NewContainerdImageService(
 		return nil, err
 	}
 	conn := client.Conn()
-	snapshotter, httpProxy, err := getDefaultValuesFromCRIStatus(conn)
+	snapshotter, httpProxy, sandboxImage, err := getDefaultValuesFromCRIStatus(conn)
 	if err != nil {
 		return nil, err
 	}
 
 	return &containerdImageClient{
+		namespace:      namespace,
 		accountManager: accountManager,
 		snapshotter:    snapshotter,
 		client:         client,
 		criImageClient: runtimeapi.NewImageServiceClient(conn),
 		httpProxy:      httpProxy,
+		sandboxImage:   sandboxImage,
+		limiter:        newBandwidthLimiter(),
 	}, nil
 }
 
 type containerdImageClient struct {
+	namespace      string
 	accountManager daemonutil.ImagePullAccountManager
 	snapshotter    string
 	client         *containerd.Client
 	criImageClient runtimeapi.ImageServiceClient
 	httpProxy      string
+	sandboxImage   string
+	// limiter limits the bandwidth of pulling images, nil means no limit.
+	limiter *rate.Limiter
 }
 
 // PullImage implements ImageService.PullImage.
 func (d *containerdImageClient) PullImage(ctx context.Context, imageName, tag string, pullSecrets []v1.Secret) (ImagePullStatusReader, error) {
-	ctx = namespaces.WithNamespace(ctx, k8sContainerdNamespace)
+	ctx = namespaces.WithNamespace(ctx, d.namespace)
 
 	if tag == "" {
 		tag = defaultTag
 	}
 
-	imageRef := fmt.Sprintf("%s:%s", imageName, tag)
+	imageRef := daemonutil.JoinImageNameTag(imageName, tag)
 	namedRef, err := daemonutil.NormalizeImageRef(imageRef)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to parse image reference %q", imageRef)
@@ -107,23 +122,137 @@ func (d *containerdImageClient) PullImage(ctx context.Context, imageName, tag st
 
 // ListImages implements ImageService.ListImages.
 func (d *containerdImageClient) ListImages(ctx context.Context) ([]ImageInfo, error) {
+	// the images in other namespaces are invisible to CRI
+	if d.namespace != K8sContainerdNamespace {
+		return d.listImagesInNamespace(ctx)
+	}
+
 	resp, err := d.criImageClient.ListImages(ctx, &runtimeapi.ListImagesRequest{})
 	if err != nil {
 		return nil, err
 	}
 
+	pinnedNames := d.getPinnedImageNames(ctx)
 	collection := make([]ImageInfo, 0, len(resp.Images))
 	for _, info := range resp.Images {
-		collection = append(collection, ImageInfo{
+		imageInfo := ImageInfo{
 			ID:          info.Id,
 			RepoTags:    info.RepoTags,
 			RepoDigests: info.RepoDigests,
 			Size:        int64(info.Size_),
-		})
+		}
+		for _, name := range append(info.RepoTags, info.RepoDigests...) {
+			if pinnedNames.Has(normalizeImageName(name)) {
+				imageInfo.Pinned = true
+				break
+			}
+		}
+		collection = append(collection, imageInfo)
 	}
 	return collection, nil
 }
 
+// getPinnedImageNames returns the normalized names of the sandbox image and the images pinned by cri-containerd.
+func (d *containerdImageClient) getPinnedImageNames(ctx context.Context) sets.String {
+	names := sets.NewString()
+	if d.sandboxImage != "" {
+		names.Insert(normalizeImageName(d.sandboxImage))
+	}
+	ctx = namespaces.WithNamespace(ctx, K8sContainerdNamespace)
+	imgs, err := d.client.ImageService().List(ctx, fmt.Sprintf("labels.%q==%s", pinnedImageLabelKey, pinnedImageLabelValue))
+	if err != nil {
+		klog.Warningf("Failed to list pinned images: %v", err)
+		return names
+	}
+	for _, img := range imgs {
+		names.Insert(normalizeImageName(img.Name))
+	}
+	return names
+}
+
+// normalizeImageName returns the normalized reference of the image, or itself if it is not a valid reference.
+func normalizeImageName(image string) string {
+	named, err := daemonutil.NormalizeImageRef(image)
+	if err != nil {
+		return image
+	}
+	return named.String()
+}
+
+// UntagImage implements ImageUntagger.UntagImage, which deletes the image record of the name only,
+// and the content is kept if it is referenced by the other names.
+func (d *containerdImageClient) UntagImage(ctx context.Context, name string) error {
+	ctx = namespaces.WithNamespace(ctx, d.namespace)
+	if err := d.client.ImageService().Delete(ctx, name); err != nil && !errdefs.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// RemoveImage implements ImageService.RemoveImage.
+func (d *containerdImageClient) RemoveImage(ctx context.Context, image string) error {
+	if d.namespace != K8sContainerdNamespace {
+		return d.removeImageInNamespace(ctx, image)
+	}
+
+	_, err := d.criImageClient.RemoveImage(ctx, &runtimeapi.RemoveImageRequest{Image: &runtimeapi.ImageSpec{Image: image}})
+	return err
+}
+
+// listImagesInNamespace lists the images in the namespace by containerd, and groups the names by the image digest.
+func (d *containerdImageClient) listImagesInNamespace(ctx context.Context) ([]ImageInfo, error) {
+	ctx = namespaces.WithNamespace(ctx, d.namespace)
+	imgs, err := d.client.ListImages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var collection []ImageInfo
+	idToIndex := make(map[string]int, len(imgs))
+	for _, img := range imgs {
+		id := img.Target().Digest.String()
+		idx, ok := idToIndex[id]
+		if !ok {
+			size, err := img.Size(ctx)
+			if err != nil {
+				klog.V(4).Infof("Failed to get size of image %s in namespace %s: %v", img.Name(), d.namespace, err)
+			}
+			collection = append(collection, ImageInfo{ID: id, Size: size})
+			idx = len(collection) - 1
+			idToIndex[id] = idx
+		}
+		if img.Labels()[pinnedImageLabelKey] == pinnedImageLabelValue {
+			collection[idx].Pinned = true
+		}
+		if strings.Contains(img.Name(), "@") {
+			collection[idx].RepoDigests = append(collection[idx].RepoDigests, img.Name())
+		} else {
+			collection[idx].RepoTags = append(collection[idx].RepoTags, img.Name())
+		}
+	}
+	return collection, nil
+}
+
+// removeImageInNamespace removes the image by its name, or all the names of the image by its ID.
+func (d *containerdImageClient) removeImageInNamespace(ctx context.Context, image string) error {
+	ctx = namespaces.WithNamespace(ctx, d.namespace)
+	names := []string{image}
+	if imgs, err := d.listImagesInNamespace(ctx); err == nil {
+		for _, img := range imgs {
+			if img.ID == image {
+				names = append(img.RepoTags, img.RepoDigests...)
+				break
+			}
+		}
+	}
+	for _, name := range names {
+		if err := d.client.ImageService().Delete(ctx, name, images.SynchronousDelete()); err != nil && !errdefs.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
 // doPullImage returns pipe reader as ImagePullStatusReader to notify the progressing.
 func (d *containerdImageClient) doPullImage(ctx context.Context, ref reference.Named, isSchema1 bool, resolver remotes.Resolver) ImagePullStatusReader {
 	ongoing := newFetchJobs(ref.String())
@@ -143,6 +272,9 @@ func (d *containerdImageClient) doPullImage(ctx context.Context, ref reference.N
 				}),
 		),
 	}
+	if maxConcurrentDownloadsForPullImage > 0 {
+		opts = append(opts, containerd.WithMaxConcurrentDownloads(maxConcurrentDownloadsForPullImage))
+	}
 
 	pipeR, pipeW := io.Pipe()
 	stream := jsonstream.New(pipeW, nil)
@@ -282,12 +414,17 @@ func (d *containerdImageClient) resolverGenerator(authInfo *daemonutil.AuthInfo)
 		ExpectContinueTimeout: 5 * time.Second,
 	}
 
+	var transport http.RoundTripper = tr
+	if d.limiter != nil {
+		transport = &rateLimitedTransport{RoundTripper: tr, limiter: d.limiter}
+	}
+
 	return docker.NewResolver(docker.ResolverOptions{
 		Credentials: func(host string) (string, string, error) {
 			return username, secret, nil
 		},
 		Client: &http.Client{
-			Transport: tr,
+			Transport: transport,
 		},
 	})
 }
@@ -318,15 +455,15 @@ func (d *containerdImageClient) createRepoDigestRecord(ctx context.Context, ref
 	return nil
 }
 
-// getDefaultValuesFromCRIStatus returns default snapshotter/httpproxy from cri-containerd.
-func getDefaultValuesFromCRIStatus(conn *grpc.ClientConn) (snapshotter string, httpproxy string, _ error) {
+// getDefaultValuesFromCRIStatus returns default snapshotter/httpproxy/sandboxImage from cri-containerd.
+func getDefaultValuesFromCRIStatus(conn *grpc.ClientConn) (snapshotter string, httpproxy string, sandboxImage string, _ error) {
 	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Second)
 	defer cancel()
 
 	rclient := runtimeapi.NewRuntimeServiceClient(conn)
 	resp, err := rclient.Status(ctx, &runtimeapi.StatusRequest{Verbose: true})
 	if err != nil {
-		return "", "", errors.Wrap(err, "failed to fetch cri-containerd status")
+		return "", "", "", errors.Wrap(err, "failed to fetch cri-containerd status")
 	}
 
 	var partInfo struct {
@@ -336,19 +473,21 @@ func getDefaultValuesFromCRIStatus(conn *grpc.ClientConn) (snapshotter string, h
 		Registry struct {
 			Proxy string `json:"proxy"`
 		} `json:"registry"`
+		SandboxImage string `json:"sandboxImage"`
 	}
 
 	config, ok := resp.Info["config"]
 	if !ok {
-		return "", "", errors.Wrap(err, "failed to get config info from containerd")
+		return "", "", "", errors.Wrap(err, "failed to get config info from containerd")
 	}
 
 	if err := json.Unmarshal([]byte(config), &partInfo); err != nil {
-		return "", "", errors.Wrapf(err, "failed to unmarshal config(%v)", config)
+		return "", "", "", errors.Wrapf(err, "failed to unmarshal config(%v)", config)
 	}
 
 	snapshotter = partInfo.ContainerdConfig.Snapshotter
 	httpproxy = partInfo.Registry.Proxy
+	sandboxImage = partInfo.SandboxImage
 	return
 }
 
@@ -0,0 +1,99 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imageruntime
+
+import (
+	"context"
+	"flag"
+	"io"
+	"net/http"
+
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// minBandwidthBurst is the minimum bytes read from registry at once when the bandwidth is limited.
+	minBandwidthBurst = 32 * 1024
+)
+
+var (
+	// maxConcurrentDownloadsForPullImage limits the layers downloaded concurrently for each image by containerd.
+	maxConcurrentDownloadsForPullImage = 0
+	// maxBandwidthForPullImage limits the bytes downloaded per second from registries by containerd on the node.
+	maxBandwidthForPullImage = ""
+)
+
+func init() {
+	flag.IntVar(&maxConcurrentDownloadsForPullImage, "max-concurrent-downloads-for-pull-image", maxConcurrentDownloadsForPullImage,
+		"The max number of layers downloaded concurrently for each image by containerd, 0 means no limit.")
+	flag.StringVar(&maxBandwidthForPullImage, "max-bandwidth-for-pull-image", maxBandwidthForPullImage,
+		"The max bytes per second downloaded from registries by containerd on the node, such as 100Mi, empty means no limit.")
+}
+
+// newBandwidthLimiter returns the limiter of the bandwidth shared by all the images pulled, or nil if no limit.
+func newBandwidthLimiter() *rate.Limiter {
+	if maxBandwidthForPullImage == "" {
+		return nil
+	}
+	q, err := resource.ParseQuantity(maxBandwidthForPullImage)
+	if err != nil || q.Value() <= 0 {
+		klog.Warningf("Ignore invalid max-bandwidth-for-pull-image %s: %v", maxBandwidthForPullImage, err)
+		return nil
+	}
+	burst := int(q.Value())
+	if burst < minBandwidthBurst {
+		burst = minBandwidthBurst
+	}
+	klog.Infof("Limit the bandwidth for pulling images to %s per second", q.String())
+	return rate.NewLimiter(rate.Limit(q.Value()), burst)
+}
+
+// rateLimitedTransport limits the bytes read from the response bodies.
+type rateLimitedTransport struct {
+	http.RoundTripper
+	limiter *rate.Limiter
+}
+
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.RoundTripper.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = &rateLimitedReader{ReadCloser: resp.Body, ctx: req.Context(), limiter: t.limiter}
+	return resp, nil
+}
+
+type rateLimitedReader struct {
+	io.ReadCloser
+	ctx     context.Context
+	limiter *rate.Limiter
+}
+
+func (r *rateLimitedReader) Read(p []byte) (int, error) {
+	if len(p) > r.limiter.Burst() {
+		p = p[:r.limiter.Burst()]
+	}
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		if waitErr := r.limiter.WaitN(r.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
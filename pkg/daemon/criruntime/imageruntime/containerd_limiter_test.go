@@ -0,0 +1,79 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imageruntime
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestNewBandwidthLimiter(t *testing.T) {
+	defer func(old string) { maxBandwidthForPullImage = old }(maxBandwidthForPullImage)
+
+	cases := []struct {
+		bandwidth     string
+		expectedLimit float64
+		expectedBurst int
+	}{
+		{bandwidth: ""},
+		{bandwidth: "invalid"},
+		{bandwidth: "1Mi", expectedLimit: 1024 * 1024, expectedBurst: 1024 * 1024},
+		{bandwidth: "1Ki", expectedLimit: 1024, expectedBurst: minBandwidthBurst},
+	}
+	for _, cs := range cases {
+		maxBandwidthForPullImage = cs.bandwidth
+		limiter := newBandwidthLimiter()
+		if cs.expectedLimit == 0 {
+			if limiter != nil {
+				t.Fatalf("expected no limiter for %q, but got %v", cs.bandwidth, limiter.Limit())
+			}
+			continue
+		}
+		if limiter == nil || float64(limiter.Limit()) != cs.expectedLimit || limiter.Burst() != cs.expectedBurst {
+			t.Fatalf("unexpected limiter for %q: %v", cs.bandwidth, limiter)
+		}
+	}
+}
+
+func TestRateLimitedReader(t *testing.T) {
+	defer func(old string) { maxBandwidthForPullImage = old }(maxBandwidthForPullImage)
+	maxBandwidthForPullImage = "64Ki"
+
+	data := bytes.Repeat([]byte("a"), 128*1024)
+	reader := &rateLimitedReader{
+		ReadCloser: ioutil.NopCloser(bytes.NewReader(data)),
+		ctx:        context.TODO(),
+		limiter:    newBandwidthLimiter(),
+	}
+
+	start := time.Now()
+	got, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatalf("expected %d bytes read, but got %d", len(data), len(got))
+	}
+	// the first 64Ki bytes are read in burst, and the left ones should wait for about one second
+	if cost := time.Since(start); cost < 900*time.Millisecond {
+		t.Fatalf("expected reading limited by bandwidth, but cost %v", cost)
+	}
+}
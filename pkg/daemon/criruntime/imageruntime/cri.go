@@ -68,7 +68,7 @@ type commonCRIImageService struct {
 // PullImage implements ImageService.PullImage.
 func (c *commonCRIImageService) PullImage(ctx context.Context, imageName, tag string, pullSecrets []v1.Secret) (ImagePullStatusReader, error) {
 	registry := daemonutil.ParseRegistry(imageName)
-	fullImageName := imageName + ":" + tag
+	fullImageName := daemonutil.JoinImageNameTag(imageName, tag)
 	// Reader
 	pipeR, pipeW := io.Pipe()
 	defer pipeW.Close()
@@ -164,3 +164,9 @@ func (c *commonCRIImageService) ListImages(ctx context.Context) ([]ImageInfo, er
 	}
 	return collection, nil
 }
+
+// RemoveImage implements ImageService.RemoveImage.
+func (c *commonCRIImageService) RemoveImage(ctx context.Context, image string) error {
+	_, err := c.criImageClient.RemoveImage(ctx, &runtimeapi.RemoveImageRequest{Image: &runtimeapi.ImageSpec{Image: image}})
+	return err
+}
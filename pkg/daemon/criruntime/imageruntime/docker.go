@@ -74,7 +74,7 @@ func (d *dockerImageService) PullImage(ctx context.Context, imageName, tag strin
 	}
 
 	registry := daemonutil.ParseRegistry(imageName)
-	fullName := imageName + ":" + tag
+	fullName := daemonutil.JoinImageNameTag(imageName, tag)
 	var ioReader io.ReadCloser
 
 	if len(pullSecrets) > 0 {
@@ -144,6 +144,36 @@ func (d *dockerImageService) ListImages(ctx context.Context) ([]ImageInfo, error
 	return newImageCollectionDocker(infos), nil
 }
 
+// UntagImage implements ImageUntagger.UntagImage, docker only removes the tag if the image has other tags.
+func (d *dockerImageService) UntagImage(ctx context.Context, name string) error {
+	if err := d.createRuntimeClientIfNecessary(); err != nil {
+		return err
+	}
+	if _, err := d.client.ImageRemove(ctx, name, dockertypes.ImageRemoveOptions{}); err != nil {
+		if dockerapi.IsErrNotFound(err) {
+			return nil
+		}
+		d.handleRuntimeError(err)
+		return err
+	}
+	return nil
+}
+
+func (d *dockerImageService) RemoveImage(ctx context.Context, image string) error {
+	if err := d.createRuntimeClientIfNecessary(); err != nil {
+		return err
+	}
+	_, err := d.client.ImageRemove(ctx, image, dockertypes.ImageRemoveOptions{PruneChildren: true})
+	if err != nil {
+		if dockerapi.IsErrNotFound(err) {
+			return nil
+		}
+		d.handleRuntimeError(err)
+		return err
+	}
+	return nil
+}
+
 func newImageCollectionDocker(infos []dockertypes.ImageSummary) []ImageInfo {
 	collection := make([]ImageInfo, 0, len(infos))
 	for _, info := range infos {
@@ -119,6 +119,8 @@ type layerProgress struct {
 type pullingProgress struct {
 	Layers        map[string]layerProgress `json:"layers,omitempty"`
 	TotalStatuses []string                 `json:"totalStatuses,omitempty"`
+	// CurrentLayer is the layer which has reported downloading lately
+	CurrentLayer string `json:"currentLayer,omitempty"`
 }
 
 func newPullingProgress() *pullingProgress {
@@ -127,22 +129,41 @@ func newPullingProgress() *pullingProgress {
 	}
 }
 
-func (pp *pullingProgress) getProgressPercent() int32 {
-	current := int64(0)
-	total := int64(0)
+func (pp *pullingProgress) getProgressBytes() (current, total int64) {
 	for _, layerProgress := range pp.Layers {
 		if layerProgress.JSONProgress != nil {
 			current = current + layerProgress.Current
 			total = total + layerProgress.Total
 		}
 	}
+	return current, total
+}
 
+func (pp *pullingProgress) getProgressPercent() int32 {
+	current, total := pp.getProgressBytes()
 	if total == int64(0) {
 		return 0
 	}
 	return int32(current * 100 / total)
 }
 
+func (pp *pullingProgress) update(jm *dockermessage.JSONMessage) {
+	if jm.ID != "" {
+		pp.Layers[jm.ID] = layerProgress{
+			JSONProgress: jm.Progress,
+			Status:       jm.Status,
+		}
+		// docker reports "Downloading" and containerd reports "downloading"
+		if strings.EqualFold(jm.Status, "downloading") {
+			pp.CurrentLayer = jm.ID
+		} else if pp.CurrentLayer == jm.ID {
+			pp.CurrentLayer = ""
+		}
+	} else if jm.Status != "" {
+		pp.TotalStatuses = append(pp.TotalStatuses, jm.Status)
+	}
+}
+
 type imagePullStatusReader struct {
 	ch     chan ImagePullStatus
 	done   chan struct{}
@@ -212,16 +233,16 @@ func (r *imagePullStatusReader) mainloop() {
 			}
 
 			klog.V(5).Infof("runtime read progress %v", util.DumpJSON(jm))
-			if jm.ID != "" {
-				progress.Layers[jm.ID] = layerProgress{
-					JSONProgress: jm.Progress,
-					Status:       jm.Status,
-				}
-			} else if jm.Status != "" {
-				progress.TotalStatuses = append(progress.TotalStatuses, jm.Status)
-			}
+			progress.update(&jm)
 			currentProgress := progress.getProgressPercent()
-			r.seedPullStatus(ImagePullStatus{Process: int(currentProgress), DetailInfo: util.DumpJSON(progress)})
+			downloadedBytes, totalBytes := progress.getProgressBytes()
+			r.seedPullStatus(ImagePullStatus{
+				Process:         int(currentProgress),
+				DetailInfo:      util.DumpJSON(progress),
+				DownloadedBytes: downloadedBytes,
+				TotalBytes:      totalBytes,
+				CurrentLayer:    progress.CurrentLayer,
+			})
 		}
 	}
 }
@@ -233,6 +254,13 @@ func (c ImageInfo) ContainsImage(name string, tag string) bool {
 			return true
 		}
 	}
+	// the image pulled by digest
+	for _, repoDigest := range c.RepoDigests {
+		imageRepo, imageDigest := parseRepositoryTag(repoDigest)
+		if imageRepo == name && imageDigest == tag {
+			return true
+		}
+	}
 	return false
 }
 
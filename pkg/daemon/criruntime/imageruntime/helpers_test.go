@@ -0,0 +1,87 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imageruntime
+
+import (
+	"encoding/json"
+	"testing"
+
+	dockermessage "github.com/docker/docker/pkg/jsonmessage"
+)
+
+func TestPullingProgress(t *testing.T) {
+	cases := []struct {
+		name                    string
+		messages                []string
+		expectedCurrent         int64
+		expectedTotal           int64
+		expectedPercent         int32
+		expectedCurrentLayer    string
+		expectedTotalStatusSize int
+	}{
+		{
+			name: "docker layers downloading",
+			messages: []string{
+				`{"status":"Pulling from library/nginx","id":"latest"}`,
+				`{"status":"Pulling fs layer","id":"layer1"}`,
+				`{"status":"Downloading","progressDetail":{"current":50,"total":100},"id":"layer1"}`,
+				`{"status":"Downloading","progressDetail":{"current":100,"total":300},"id":"layer2"}`,
+			},
+			expectedCurrent:      150,
+			expectedTotal:        400,
+			expectedPercent:      37,
+			expectedCurrentLayer: "layer2",
+		},
+		{
+			name: "containerd layer done",
+			messages: []string{
+				`{"status":"downloading","progressDetail":{"current":1,"total":10},"id":"layer1"}`,
+				`{"status":"done","progressDetail":{"current":10,"total":10},"id":"layer1"}`,
+				`{"status":"Digest: sha256:xxx"}`,
+			},
+			expectedCurrent:         10,
+			expectedTotal:           10,
+			expectedPercent:         100,
+			expectedTotalStatusSize: 1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pp := newPullingProgress()
+			for _, msg := range tc.messages {
+				var jm dockermessage.JSONMessage
+				if err := json.Unmarshal([]byte(msg), &jm); err != nil {
+					t.Fatal(err)
+				}
+				pp.update(&jm)
+			}
+			if current, total := pp.getProgressBytes(); current != tc.expectedCurrent || total != tc.expectedTotal {
+				t.Fatalf("expected bytes %d/%d, got %d/%d", tc.expectedCurrent, tc.expectedTotal, current, total)
+			}
+			if percent := pp.getProgressPercent(); percent != tc.expectedPercent {
+				t.Fatalf("expected percent %d, got %d", tc.expectedPercent, percent)
+			}
+			if pp.CurrentLayer != tc.expectedCurrentLayer {
+				t.Fatalf("expected current layer %q, got %q", tc.expectedCurrentLayer, pp.CurrentLayer)
+			}
+			if len(pp.TotalStatuses) != tc.expectedTotalStatusSize {
+				t.Fatalf("expected %d total statuses, got %v", tc.expectedTotalStatusSize, pp.TotalStatuses)
+			}
+		})
+	}
+}
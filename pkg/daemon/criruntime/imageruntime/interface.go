@@ -31,6 +31,8 @@ type ImageInfo struct {
 	RepoTags []string `json:"RepoTags"`
 	// size of image's taking disk space.
 	Size int64 `json:"Size,omitempty"`
+	// Pinned images should never be removed, such as the sandbox image of the runtime.
+	Pinned bool `json:"Pinned,omitempty"`
 }
 
 type ImagePullStatus struct {
@@ -38,6 +40,12 @@ type ImagePullStatus struct {
 	Process    int
 	DetailInfo string
 	Finish     bool
+
+	// DownloadedBytes and TotalBytes are the sum of the layers known so far
+	DownloadedBytes int64
+	TotalBytes      int64
+	// CurrentLayer is the layer being downloaded
+	CurrentLayer string
 }
 
 type ImagePullStatusReader interface {
@@ -48,4 +56,13 @@ type ImagePullStatusReader interface {
 type ImageService interface {
 	PullImage(ctx context.Context, imageName, tag string, pullSecrets []v1.Secret) (ImagePullStatusReader, error)
 	ListImages(ctx context.Context) ([]ImageInfo, error)
+	// RemoveImage removes the image by its name or ID, and it returns nil if the image does not exist.
+	RemoveImage(ctx context.Context, image string) error
+}
+
+// ImageUntagger is implemented by the image services which can remove one name of an image, and keep the image
+// if it still has other names. Note that CRI RemoveImage removes all the names of the image.
+type ImageUntagger interface {
+	// UntagImage removes the name of image, and it returns nil if the name does not exist.
+	UntagImage(ctx context.Context, name string) error
 }
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/http"
 	"sync"
 
 	pouchfilters "github.com/alibaba/pouch/apis/filters"
@@ -146,6 +147,20 @@ func (d *pouchImageService) ListImages(ctx context.Context) ([]ImageInfo, error)
 	return newImageCollectionPouch(infos), nil
 }
 
+func (d *pouchImageService) RemoveImage(ctx context.Context, image string) error {
+	if err := d.createRuntimeClientIfNecessary(); err != nil {
+		return err
+	}
+	if err := d.client.ImageRemove(ctx, image, false); err != nil {
+		if respErr, ok := err.(pouchapi.RespError); ok && respErr.Code() == http.StatusNotFound {
+			return nil
+		}
+		d.handleRuntimeError(err)
+		return err
+	}
+	return nil
+}
+
 func newImageCollectionPouch(infos []pouchtypes.ImageInfo) []ImageInfo {
 	collection := make([]ImageInfo, 0, len(infos))
 	for _, info := range infos {
@@ -22,11 +22,14 @@ import (
 	"net"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apimachinery/pkg/watch"
 	clientset "k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -38,9 +41,11 @@ import (
 
 	kruiseapis "github.com/openkruise/kruise/apis"
 	"github.com/openkruise/kruise/pkg/client"
+	kruiseclientset "github.com/openkruise/kruise/pkg/client/clientset/versioned"
 	"github.com/openkruise/kruise/pkg/daemon/containermeta"
 	"github.com/openkruise/kruise/pkg/daemon/containerrecreate"
 	daemonruntime "github.com/openkruise/kruise/pkg/daemon/criruntime"
+	"github.com/openkruise/kruise/pkg/daemon/imagedeleter"
 	"github.com/openkruise/kruise/pkg/daemon/imagepuller"
 	daemonoptions "github.com/openkruise/kruise/pkg/daemon/options"
 	daemonutil "github.com/openkruise/kruise/pkg/daemon/util"
@@ -120,8 +125,11 @@ func NewDaemon(cfg *rest.Config, bindAddress string) (Daemon, error) {
 		podInformer = newPodInformer(genericClient.KubeClient, nodeName)
 	}
 
-	accountManager := daemonutil.NewImagePullAccountManager(genericClient.KubeClient)
-	runtimeFactory, err := daemonruntime.NewFactory(varRunMountPath, accountManager)
+	accountManager, err := daemonutil.NewImagePullAccountManager(genericClient.KubeClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to new image pull account manager: %v", err)
+	}
+	runtimeFactory, err := daemonruntime.NewFactory(varRunMountPath, getRuntimeAnnotations(genericClient.KruiseClient, nodeName), accountManager)
 	if err != nil {
 		return nil, fmt.Errorf("failed to new runtime factory: %v", err)
 	}
@@ -147,9 +155,15 @@ func NewDaemon(cfg *rest.Config, bindAddress string) (Daemon, error) {
 		return nil, fmt.Errorf("failed to new crr daemon controller: %v", err)
 	}
 
+	imageDeleter, err := imagedeleter.NewController(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to new image deleter controller: %v", err)
+	}
+
 	var runnables = []Runnable{
 		puller,
 		crrController,
+		imageDeleter,
 	}
 
 	if utilfeature.DefaultFeatureGate.Enabled(features.DaemonWatchingPod) {
@@ -170,6 +184,28 @@ func NewDaemon(cfg *rest.Config, bindAddress string) (Daemon, error) {
 	}, nil
 }
 
+// getRuntimeAnnotations returns the annotations of the node to find the runtime, which are synced into the NodeImage
+// of the node by kruise-manager, so that kruise-daemon needs no permission to get nodes.
+// It waits a while for the NodeImage of a new node, and the runtime is detected by default if the NodeImage is not found.
+func getRuntimeAnnotations(kruiseClient kruiseclientset.Interface, nodeName string) map[string]string {
+	var annotations map[string]string
+	err := wait.PollImmediate(5*time.Second, time.Minute, func() (bool, error) {
+		nodeImage, err := kruiseClient.AppsV1alpha1().NodeImages().Get(context.TODO(), nodeName, metav1.GetOptions{})
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		annotations = nodeImage.Annotations
+		return true, nil
+	})
+	if err != nil {
+		klog.Warningf("Failed to get NodeImage %s for runtime detection: %v", nodeName, err)
+	}
+	return annotations
+}
+
 func newPodInformer(client clientset.Interface, nodeName string) cache.SharedIndexInformer {
 	tweakListOptionsFunc := func(opt *metav1.ListOptions) {
 		opt.FieldSelector = "spec.nodeName=" + nodeName
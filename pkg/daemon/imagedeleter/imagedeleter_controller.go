@@ -0,0 +1,349 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagedeleter
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/client"
+	kruiseclient "github.com/openkruise/kruise/pkg/client/clientset/versioned"
+	listersalpha1 "github.com/openkruise/kruise/pkg/client/listers/apps/v1alpha1"
+	daemonruntime "github.com/openkruise/kruise/pkg/daemon/criruntime"
+	runtimeimage "github.com/openkruise/kruise/pkg/daemon/criruntime/imageruntime"
+	daemonoptions "github.com/openkruise/kruise/pkg/daemon/options"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+)
+
+func init() {
+	flag.StringVar(&protectedImages, "image-deleter-protected-images", protectedImages,
+		"The comma-separated repositories of images which will never be deleted by ImageDeleteJob, such as the sandbox image configured for the runtime. "+
+			"The sandbox image and the images pinned by containerd are always protected.")
+}
+
+var (
+	protectedImages = "registry.k8s.io/pause,k8s.gcr.io/pause"
+)
+
+const (
+	workers = 1
+
+	// trackImageUsageInterval is the interval to record the images used by containers on the node
+	trackImageUsageInterval = time.Minute
+)
+
+type Controller struct {
+	nodeName       string
+	queue          workqueue.RateLimitingInterface
+	kruiseClient   kruiseclient.Interface
+	jobInformer    cache.SharedIndexInformer
+	jobLister      listersalpha1.ImageDeleteJobLister
+	eventRecorder  record.EventRecorder
+	runtimeFactory daemonruntime.Factory
+	usageTracker   *imageUsageTracker
+	// protectedRepositories are the normalized repositories of the images which will never be deleted
+	protectedRepositories sets.String
+}
+
+// NewController returns the controller for image deleting
+func NewController(opts daemonoptions.Options) (*Controller, error) {
+	genericClient := client.GetGenericClientWithName("kruise-daemon-imagedeleter")
+	informer := newImageDeleteJobInformer(genericClient.KruiseClient)
+
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartRecordingToSink(&v1core.EventSinkImpl{Interface: genericClient.KubeClient.CoreV1().Events("")})
+	recorder := eventBroadcaster.NewRecorder(opts.Scheme, v1.EventSource{Component: "kruise-daemon-imagedeleter", Host: opts.NodeName})
+
+	queue := workqueue.NewNamedRateLimitingQueue(
+		// Backoff duration from 500ms to 50~55s
+		workqueue.NewItemExponentialFailureRateLimiter(500*time.Millisecond, 50*time.Second+time.Millisecond*time.Duration(rand.Intn(5000))),
+		"imagedeleter",
+	)
+
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			job, ok := obj.(*appsv1alpha1.ImageDeleteJob)
+			if ok {
+				enqueue(queue, job, opts.NodeName)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			job, ok := newObj.(*appsv1alpha1.ImageDeleteJob)
+			if ok {
+				enqueue(queue, job, opts.NodeName)
+			}
+		},
+	})
+
+	opts.Healthz.RegisterFunc("imageDeleteJobInformerSynced", func(_ *http.Request) error {
+		if !informer.HasSynced() {
+			return fmt.Errorf("not synced")
+		}
+		return nil
+	})
+
+	protectedRepositories := sets.NewString()
+	for _, image := range strings.Split(protectedImages, ",") {
+		if image = strings.TrimSpace(image); image != "" {
+			protectedRepositories.Insert(getRepository(image))
+		}
+	}
+
+	return &Controller{
+		nodeName:              opts.NodeName,
+		queue:                 queue,
+		kruiseClient:          genericClient.KruiseClient,
+		jobInformer:           informer,
+		jobLister:             listersalpha1.NewImageDeleteJobLister(informer.GetIndexer()),
+		eventRecorder:         recorder,
+		runtimeFactory:        opts.RuntimeFactory,
+		usageTracker:          newImageUsageTracker(),
+		protectedRepositories: protectedRepositories,
+	}, nil
+}
+
+func newImageDeleteJobInformer(client kruiseclient.Interface) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return client.AppsV1alpha1().ImageDeleteJobs().List(context.TODO(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return client.AppsV1alpha1().ImageDeleteJobs().Watch(context.TODO(), options)
+			},
+		},
+		&appsv1alpha1.ImageDeleteJob{},
+		0, // do not resync
+		cache.Indexers{},
+	)
+}
+
+func enqueue(queue workqueue.Interface, job *appsv1alpha1.ImageDeleteJob, nodeName string) {
+	if job.DeletionTimestamp != nil || job.Status.CompletionTime != nil {
+		return
+	}
+	if getRunningNodeStatus(job, nodeName) == nil {
+		return
+	}
+	queue.Add(job.Name)
+}
+
+// getRunningNodeStatus returns the status of the node if the manager has assigned the job to it.
+func getRunningNodeStatus(job *appsv1alpha1.ImageDeleteJob, nodeName string) *appsv1alpha1.ImageDeleteJobNodeStatus {
+	for i := range job.Status.NodeStatuses {
+		nodeStatus := &job.Status.NodeStatuses[i]
+		if nodeStatus.NodeName == nodeName {
+			if nodeStatus.Phase == appsv1alpha1.ImageDeletePhaseRunning {
+				return nodeStatus
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+func (c *Controller) Run(stop <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	klog.Info("Starting informer for ImageDeleteJob")
+	go c.jobInformer.Run(stop)
+	if !cache.WaitForCacheSync(stop, c.jobInformer.HasSynced) {
+		return
+	}
+
+	go wait.Until(func() {
+		if _, _, err := c.trackImageUsage(); err != nil {
+			klog.Warningf("Failed to track the usage of images: %v", err)
+		}
+	}, trackImageUsageInterval, stop)
+
+	klog.Infof("Starting imagedeleter controller")
+	for i := 0; i < workers; i++ {
+		go wait.Until(func() {
+			for c.processNextWorkItem() {
+			}
+		}, time.Second, stop)
+	}
+
+	klog.Info("Started imagedeleter controller successfully")
+	<-stop
+}
+
+// processNextWorkItem will read a single work item off the workqueue and
+// attempt to process it, by calling the syncHandler.
+func (c *Controller) processNextWorkItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	err := c.sync(key.(string))
+	if err == nil {
+		// No error, tell the queue to stop tracking history
+		c.queue.Forget(key)
+	} else {
+		// requeue the item to work on later
+		c.queue.AddRateLimited(key)
+	}
+
+	return true
+}
+
+func (c *Controller) sync(name string) (retErr error) {
+	job, err := c.jobLister.Get(name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if job.DeletionTimestamp != nil || job.Status.CompletionTime != nil || getRunningNodeStatus(job, c.nodeName) == nil {
+		return nil
+	}
+
+	klog.V(3).Infof("Start deleting images for ImageDeleteJob %s", name)
+	defer func() {
+		if retErr != nil {
+			klog.Errorf("Failed to sync ImageDeleteJob %s: %v", name, retErr)
+		} else {
+			klog.V(3).Infof("Finished syncing ImageDeleteJob %s", name)
+		}
+	}()
+
+	// the result is reported into the NodeImage of this node, so never delete the images again once reported
+	nodeImage, err := c.kruiseClient.AppsV1alpha1().NodeImages().Get(context.TODO(), c.nodeName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			klog.Warningf("Skip ImageDeleteJob %s for NodeImage %s not found", name, c.nodeName)
+			return nil
+		}
+		return err
+	}
+	if reported, ok := nodeImage.Status.ImageDeleteStatuses[job.Name]; ok && reported.JobUID == job.UID {
+		return nil
+	}
+
+	deleted, inUse, deleteErr := c.deleteImages(job)
+	now := metav1.Now()
+	newStatus := appsv1alpha1.ImageDeleteStatus{
+		JobUID:         job.UID,
+		Phase:          appsv1alpha1.ImageDeletePhaseSucceeded,
+		DeletedImages:  deleted,
+		CompletionTime: &now,
+	}
+	var messages []string
+	if len(inUse) > 0 {
+		messages = append(messages, fmt.Sprintf("skipped images in use or pinned: %s", strings.Join(inUse, ", ")))
+	}
+	if deleteErr != nil {
+		newStatus.Phase = appsv1alpha1.ImageDeletePhaseFailed
+		messages = append(messages, deleteErr.Error())
+		c.eventRecorder.Eventf(job, v1.EventTypeWarning, "DeleteImagesFailed", "Failed to delete images on node %s: %v", c.nodeName, deleteErr)
+	}
+	newStatus.Message = strings.Join(messages, "; ")
+	return c.reportStatus(job, &newStatus)
+}
+
+// trackImageUsage records the usage of the images on the node and returns the images and the ones used by containers.
+func (c *Controller) trackImageUsage() ([]runtimeimage.ImageInfo, sets.String, error) {
+	images, err := c.runtimeFactory.GetImageService().ListImages(context.TODO())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list images: %v", err)
+	}
+	containers, err := c.runtimeFactory.GetRuntimeService().ListContainers(nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list containers: %v", err)
+	}
+	markProtectedImages(images, c.protectedRepositories)
+	usedImages := getUsedImages(containers)
+	c.usageTracker.update(images, usedImages, time.Now())
+	return images, usedImages, nil
+}
+
+func (c *Controller) deleteImages(job *appsv1alpha1.ImageDeleteJob) (deleted, inUse []string, err error) {
+	images, usedImages, err := c.trackImageUsage()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	toDelete, inUse := getImagesToDelete(job, images, usedImages, c.usageTracker, time.Now())
+	imageService := c.runtimeFactory.GetImageService()
+	var errs []error
+	for _, image := range toDelete {
+		var removeErr error
+		for _, ref := range image.refs {
+			if removeErr = imageService.RemoveImage(context.TODO(), ref); removeErr != nil {
+				break
+			}
+		}
+		if removeErr != nil {
+			klog.Warningf("Failed to delete image %s for ImageDeleteJob %s: %v", image.name, job.Name, removeErr)
+			errs = append(errs, fmt.Errorf("failed to delete image %s: %v", image.name, removeErr))
+			continue
+		}
+		klog.Infof("Deleted image %s for ImageDeleteJob %s", image.name, job.Name)
+		deleted = append(deleted, image.name)
+	}
+	return deleted, inUse, utilerrors.NewAggregate(errs)
+}
+
+// reportStatus reports the status of the job into the NodeImage of this node, which will be aggregated by kruise-manager,
+// so that the nodes never update the same ImageDeleteJob status.
+func (c *Controller) reportStatus(job *appsv1alpha1.ImageDeleteJob, newStatus *appsv1alpha1.ImageDeleteStatus) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		nodeImage, err := c.kruiseClient.AppsV1alpha1().NodeImages().Get(context.TODO(), c.nodeName, metav1.GetOptions{})
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		statuses := make(map[string]appsv1alpha1.ImageDeleteStatus, len(nodeImage.Status.ImageDeleteStatuses)+1)
+		for jobName, status := range nodeImage.Status.ImageDeleteStatuses {
+			// clean up the statuses of the jobs deleted
+			if existing, err := c.jobLister.Get(jobName); err == nil && existing.UID == status.JobUID {
+				statuses[jobName] = status
+			}
+		}
+		statuses[job.Name] = *newStatus
+		nodeImage.Status.ImageDeleteStatuses = statuses
+		_, err = c.kruiseClient.AppsV1alpha1().NodeImages().UpdateStatus(context.TODO(), nodeImage, metav1.UpdateOptions{})
+		return err
+	})
+}
@@ -0,0 +1,225 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagedeleter
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/distribution/reference"
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	runtimeimage "github.com/openkruise/kruise/pkg/daemon/criruntime/imageruntime"
+	daemonutil "github.com/openkruise/kruise/pkg/daemon/util"
+	"k8s.io/apimachinery/pkg/util/sets"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1alpha2"
+)
+
+// imageUsageTracker records the last time each image was used by containers on the node,
+// so that the images unused for a long time can be found.
+type imageUsageTracker struct {
+	sync.Mutex
+	// records is keyed by the image ID
+	records map[string]*imageUsageRecord
+}
+
+type imageUsageRecord struct {
+	firstDetected time.Time
+	lastUsed      time.Time
+}
+
+func newImageUsageTracker() *imageUsageTracker {
+	return &imageUsageTracker{records: make(map[string]*imageUsageRecord)}
+}
+
+func (t *imageUsageTracker) update(images []runtimeimage.ImageInfo, usedImages sets.String, now time.Time) {
+	t.Lock()
+	defer t.Unlock()
+
+	existing := sets.NewString()
+	for i := range images {
+		info := &images[i]
+		existing.Insert(info.ID)
+		record, ok := t.records[info.ID]
+		if !ok {
+			record = &imageUsageRecord{firstDetected: now}
+			t.records[info.ID] = record
+		}
+		if isImageUsed(info, usedImages) {
+			record.lastUsed = now
+		}
+	}
+	for id := range t.records {
+		if !existing.Has(id) {
+			delete(t.records, id)
+		}
+	}
+}
+
+// unusedSince returns the time since when the image has not been used, or zero if it has not been detected.
+func (t *imageUsageTracker) unusedSince(id string) time.Time {
+	t.Lock()
+	defer t.Unlock()
+
+	record, ok := t.records[id]
+	if !ok {
+		return time.Time{}
+	}
+	if !record.lastUsed.IsZero() {
+		return record.lastUsed
+	}
+	return record.firstDetected
+}
+
+// getUsedImages returns the IDs and normalized references of the images used by the containers.
+func getUsedImages(containers []*runtimeapi.Container) sets.String {
+	usedImages := sets.NewString()
+	for _, c := range containers {
+		if c.ImageRef != "" {
+			imageRef := c.ImageRef
+			for _, prefix := range []string{"docker-pullable://", "docker://"} {
+				imageRef = strings.TrimPrefix(imageRef, prefix)
+			}
+			usedImages.Insert(imageRef)
+			usedImages.Insert(normalizeImage(imageRef))
+		}
+		if c.Image != nil && c.Image.Image != "" {
+			usedImages.Insert(c.Image.Image)
+			usedImages.Insert(normalizeImage(c.Image.Image))
+		}
+	}
+	return usedImages
+}
+
+func isImageUsed(info *runtimeimage.ImageInfo, usedImages sets.String) bool {
+	if info.Pinned || usedImages.Has(info.ID) {
+		return true
+	}
+	for _, name := range getImageNames(info) {
+		if usedImages.Has(normalizeImage(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+func getImageNames(info *runtimeimage.ImageInfo) []string {
+	names := make([]string, 0, len(info.RepoTags)+len(info.RepoDigests))
+	names = append(names, info.RepoTags...)
+	names = append(names, info.RepoDigests...)
+	return names
+}
+
+// markProtectedImages marks the images in the protected repositories as pinned, such as the sandbox image.
+func markProtectedImages(images []runtimeimage.ImageInfo, protectedRepositories sets.String) {
+	for i := range images {
+		for _, name := range getImageNames(&images[i]) {
+			if protectedRepositories.Has(getRepository(name)) {
+				images[i].Pinned = true
+				break
+			}
+		}
+	}
+}
+
+// getRepository returns the normalized repository of the image, or itself if it is not a valid reference.
+func getRepository(image string) string {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return image
+	}
+	return named.Name()
+}
+
+// normalizeImage returns the normalized reference of the image, or itself if it is not a valid reference.
+func normalizeImage(image string) string {
+	named, err := daemonutil.NormalizeImageRef(image)
+	if err != nil {
+		return image
+	}
+	return named.String()
+}
+
+type imageToDelete struct {
+	// name is the image reported in the job status
+	name string
+	// refs are the references to be removed by the runtime
+	refs []string
+}
+
+// getImagesToDelete returns the images to be deleted for the job, and the ones skipped for still in use.
+func getImagesToDelete(job *appsv1alpha1.ImageDeleteJob, images []runtimeimage.ImageInfo, usedImages sets.String,
+	tracker *imageUsageTracker, now time.Time) (toDelete []imageToDelete, inUse []string) {
+
+	isUnusedLongEnough := func(info *runtimeimage.ImageInfo) bool {
+		if job.Spec.UnusedDays == nil {
+			return true
+		}
+		since := tracker.unusedSince(info.ID)
+		return !since.IsZero() && now.Sub(since) >= time.Duration(*job.Spec.UnusedDays)*24*time.Hour
+	}
+
+	if len(job.Spec.Images) == 0 {
+		for i := range images {
+			info := &images[i]
+			if isImageUsed(info, usedImages) {
+				continue
+			}
+			if !isUnusedLongEnough(info) {
+				continue
+			}
+			// remove the image by all its tags, which also works for runtimes refusing to remove an image referenced by multiple tags
+			target := imageToDelete{name: info.ID, refs: info.RepoTags}
+			if len(info.RepoTags) > 0 {
+				target.name = info.RepoTags[0]
+			} else {
+				target.refs = []string{info.ID}
+			}
+			toDelete = append(toDelete, target)
+		}
+		return toDelete, nil
+	}
+
+	for _, image := range job.Spec.Images {
+		normalized := normalizeImage(image)
+		for i := range images {
+			info := &images[i]
+			var refs []string
+			if image == info.ID {
+				refs = append(refs, info.ID)
+			}
+			for _, name := range getImageNames(info) {
+				if normalizeImage(name) == normalized {
+					refs = append(refs, name)
+				}
+			}
+			if len(refs) == 0 {
+				continue
+			}
+			if isImageUsed(info, usedImages) {
+				inUse = append(inUse, image)
+				break
+			}
+			if !isUnusedLongEnough(info) {
+				break
+			}
+			toDelete = append(toDelete, imageToDelete{name: image, refs: refs})
+			break
+		}
+	}
+	return toDelete, inUse
+}
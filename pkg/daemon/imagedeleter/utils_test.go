@@ -0,0 +1,94 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagedeleter
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	runtimeimage "github.com/openkruise/kruise/pkg/daemon/criruntime/imageruntime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1alpha2"
+	utilpointer "k8s.io/utils/pointer"
+)
+
+func TestGetImagesToDelete(t *testing.T) {
+	images := []runtimeimage.ImageInfo{
+		{ID: "sha256:nginx", RepoTags: []string{"docker.io/library/nginx:1.21", "docker.io/library/nginx:latest"}},
+		{ID: "sha256:redis", RepoTags: []string{"docker.io/library/redis:6"}},
+		{ID: "sha256:busybox", RepoTags: []string{"docker.io/library/busybox:1.35"}},
+		{ID: "sha256:dangling"},
+		{ID: "sha256:pause", RepoTags: []string{"registry.k8s.io/pause:3.6"}},
+		{ID: "sha256:pinned", RepoTags: []string{"docker.io/library/sandbox:1.0"}, Pinned: true},
+	}
+	markProtectedImages(images, sets.NewString(getRepository("registry.k8s.io/pause")))
+	if !images[4].Pinned {
+		t.Fatalf("expected pause image protected")
+	}
+	containers := []*runtimeapi.Container{
+		{Image: &runtimeapi.ImageSpec{Image: "redis:6"}, ImageRef: "sha256:redis"},
+	}
+	usedImages := getUsedImages(containers)
+
+	now := time.Now()
+	tracker := newImageUsageTracker()
+	tracker.update(images, usedImages, now.Add(-72*time.Hour))
+	tracker.update(images, usedImages, now)
+	// busybox was used until yesterday
+	tracker.records["sha256:busybox"].lastUsed = now.Add(-24 * time.Hour)
+
+	cases := []struct {
+		name             string
+		spec             appsv1alpha1.ImageDeleteJobSpec
+		expectedToDelete []imageToDelete
+		expectedInUse    []string
+	}{
+		{
+			name: "specified images",
+			spec: appsv1alpha1.ImageDeleteJobSpec{Images: []string{"nginx", "redis:6", "alpine:3.15", "registry.k8s.io/pause:3.6", "sandbox:1.0"}},
+			expectedToDelete: []imageToDelete{
+				{name: "nginx", refs: []string{"docker.io/library/nginx:latest"}},
+			},
+			expectedInUse: []string{"redis:6", "registry.k8s.io/pause:3.6", "sandbox:1.0"},
+		},
+		{
+			name: "images unused for 2 days",
+			spec: appsv1alpha1.ImageDeleteJobSpec{UnusedDays: utilpointer.Int32(2)},
+			expectedToDelete: []imageToDelete{
+				{name: "docker.io/library/nginx:1.21", refs: []string{"docker.io/library/nginx:1.21", "docker.io/library/nginx:latest"}},
+				{name: "sha256:dangling", refs: []string{"sha256:dangling"}},
+			},
+		},
+		{
+			name:             "specified images unused for 2 days",
+			spec:             appsv1alpha1.ImageDeleteJobSpec{Images: []string{"busybox:1.35", "sha256:dangling"}, UnusedDays: utilpointer.Int32(2)},
+			expectedToDelete: []imageToDelete{{name: "sha256:dangling", refs: []string{"sha256:dangling"}}},
+		},
+	}
+	for _, cs := range cases {
+		job := &appsv1alpha1.ImageDeleteJob{Spec: cs.spec}
+		toDelete, inUse := getImagesToDelete(job, images, usedImages, tracker, now)
+		if !reflect.DeepEqual(toDelete, cs.expectedToDelete) {
+			t.Fatalf("%s: expected to delete %+v, but got %+v", cs.name, cs.expectedToDelete, toDelete)
+		}
+		if !reflect.DeepEqual(inUse, cs.expectedInUse) {
+			t.Fatalf("%s: expected in use %v, but got %v", cs.name, cs.expectedInUse, inUse)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagepuller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	runtimeimage "github.com/openkruise/kruise/pkg/daemon/criruntime/imageruntime"
+	daemonutil "github.com/openkruise/kruise/pkg/daemon/util"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// distributor delegates pulling images to a P2P distribution system, such as Dragonfly and Kraken, instead of
+// pulling them from registries directly, which avoids registry hotspots when pulling on thousands of nodes.
+type distributor interface {
+	PullImage(ctx context.Context, imageName, tag string, pullSecrets []v1.Secret) (runtimeimage.ImagePullStatusReader, error)
+}
+
+// untagTimeout is the timeout to remove the name of image in the registry mirror after pulling.
+const untagTimeout = 30 * time.Second
+
+type distributorFactory func(runtime runtimeimage.ImageService, spec *appsv1alpha1.ImagePullDistributor) distributor
+
+// distributorFactories builds distributors by their types, the other P2P systems can be supported by registering here.
+var distributorFactories = map[appsv1alpha1.ImagePullDistributorType]distributorFactory{
+	appsv1alpha1.ImagePullDistributorDragonfly: newRegistryMirrorDistributor,
+	appsv1alpha1.ImagePullDistributorKraken:    newRegistryMirrorDistributor,
+}
+
+func newDistributor(runtime runtimeimage.ImageService, spec *appsv1alpha1.ImagePullDistributor) (distributor, error) {
+	factory, ok := distributorFactories[spec.Type]
+	if !ok {
+		return nil, fmt.Errorf("unknown type of distributor %s", spec.Type)
+	}
+	return factory(runtime, spec), nil
+}
+
+// registryMirrorDistributor pulls the image from the registry mirror served by the distributor agent on the node,
+// which downloads the layers from the other peers. Then it pulls the image by its original name, which only fetches
+// the manifest from registry for the layers have existed, so that pods can use the image directly.
+// At last, the name of image in the mirror is removed if the runtime supports, to not leave it on the node.
+type registryMirrorDistributor struct {
+	runtime  runtimeimage.ImageService
+	endpoint string
+}
+
+func newRegistryMirrorDistributor(runtime runtimeimage.ImageService, spec *appsv1alpha1.ImagePullDistributor) distributor {
+	return &registryMirrorDistributor{runtime: runtime, endpoint: spec.Endpoint}
+}
+
+func (d *registryMirrorDistributor) PullImage(ctx context.Context, imageName, tag string, pullSecrets []v1.Secret) (runtimeimage.ImagePullStatusReader, error) {
+	mirrorName, err := daemonutil.ReplaceImageDomain(imageName, d.endpoint)
+	if err != nil {
+		return nil, err
+	}
+	klog.V(3).Infof("Pulling image %s:%s through distributor as %s", imageName, tag, mirrorName)
+	// the agent of distributor is responsible for the credentials of registries
+	mirrorReader, err := d.runtime.PullImage(ctx, mirrorName, tag, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull image %s:%s from distributor: %v", mirrorName, tag, err)
+	}
+
+	mirrorRef := mirrorName + ":latest"
+	if tag != "" {
+		mirrorRef = daemonutil.JoinImageNameTag(mirrorName, tag)
+	}
+	reader := newChainedPullStatusReader()
+	go reader.run(mirrorReader, func() (runtimeimage.ImagePullStatusReader, error) {
+		return d.runtime.PullImage(ctx, imageName, tag, pullSecrets)
+	}, func() {
+		d.untagMirrorImage(mirrorRef)
+	})
+	return reader, nil
+}
+
+func (d *registryMirrorDistributor) untagMirrorImage(mirrorRef string) {
+	untagger, ok := d.runtime.(runtimeimage.ImageUntagger)
+	if !ok {
+		klog.V(4).Infof("Runtime does not support untagging, leave the image %s", mirrorRef)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), untagTimeout)
+	defer cancel()
+	if err := untagger.UntagImage(ctx, mirrorRef); err != nil {
+		klog.Warningf("Failed to untag image %s pulled through distributor: %v", mirrorRef, err)
+	}
+}
+
+// chainedPullStatusReader reports the statuses of the first pulling, and then the ones of the next pulling
+// if the first succeeded.
+type chainedPullStatusReader struct {
+	ch        chan runtimeimage.ImagePullStatus
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newChainedPullStatusReader() *chainedPullStatusReader {
+	return &chainedPullStatusReader{
+		ch:   make(chan runtimeimage.ImagePullStatus, 10),
+		done: make(chan struct{}),
+	}
+}
+
+func (r *chainedPullStatusReader) C() <-chan runtimeimage.ImagePullStatus {
+	return r.ch
+}
+
+func (r *chainedPullStatusReader) Close() {
+	r.closeOnce.Do(func() {
+		close(r.done)
+	})
+}
+
+// run forwards the statuses of the first pulling and then the next pulling, and calls cleanup after the next pulling
+// ends if the first succeeded.
+func (r *chainedPullStatusReader) run(first runtimeimage.ImagePullStatusReader, next func() (runtimeimage.ImagePullStatusReader, error), cleanup func()) {
+	if !r.forward(first, false) {
+		return
+	}
+	defer cleanup()
+	nextReader, err := next()
+	if err != nil {
+		r.send(runtimeimage.ImagePullStatus{Err: err, Finish: true})
+		return
+	}
+	r.forward(nextReader, true)
+}
+
+// forward sends the statuses of the reader, and returns whether the pulling has succeeded.
+// The success of the pulling is not sent unless it is the last one.
+func (r *chainedPullStatusReader) forward(reader runtimeimage.ImagePullStatusReader, last bool) bool {
+	defer reader.Close()
+	for {
+		select {
+		case <-r.done:
+			return false
+		case status, ok := <-reader.C():
+			if !ok {
+				r.send(runtimeimage.ImagePullStatus{Err: fmt.Errorf("pulling status closed unexpectedly"), Finish: true})
+				return false
+			}
+			if status.Finish && status.Err == nil && !last {
+				return true
+			}
+			if !r.send(status) {
+				return false
+			}
+			if status.Finish {
+				return status.Err == nil
+			}
+		}
+	}
+}
+
+func (r *chainedPullStatusReader) send(status runtimeimage.ImagePullStatus) bool {
+	select {
+	case r.ch <- status:
+		return true
+	case <-r.done:
+		return false
+	}
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagepuller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	runtimeimage "github.com/openkruise/kruise/pkg/daemon/criruntime/imageruntime"
+	v1 "k8s.io/api/core/v1"
+)
+
+type fakePullStatusReader struct {
+	ch chan runtimeimage.ImagePullStatus
+}
+
+func (r *fakePullStatusReader) C() <-chan runtimeimage.ImagePullStatus {
+	return r.ch
+}
+
+func (r *fakePullStatusReader) Close() {}
+
+type fakeImageService struct {
+	pulled   []string
+	results  map[string]error
+	untagged chan string
+}
+
+func (s *fakeImageService) PullImage(ctx context.Context, imageName, tag string, pullSecrets []v1.Secret) (runtimeimage.ImagePullStatusReader, error) {
+	ref := fmt.Sprintf("%s:%s", imageName, tag)
+	s.pulled = append(s.pulled, ref)
+	reader := &fakePullStatusReader{ch: make(chan runtimeimage.ImagePullStatus, 2)}
+	reader.ch <- runtimeimage.ImagePullStatus{Process: 50}
+	reader.ch <- runtimeimage.ImagePullStatus{Process: 100, Finish: true, Err: s.results[ref]}
+	return reader, nil
+}
+
+func (s *fakeImageService) ListImages(ctx context.Context) ([]runtimeimage.ImageInfo, error) {
+	return nil, nil
+}
+
+func (s *fakeImageService) RemoveImage(ctx context.Context, image string) error {
+	return nil
+}
+
+func (s *fakeImageService) UntagImage(ctx context.Context, name string) error {
+	s.untagged <- name
+	return nil
+}
+
+func readUntilFinished(t *testing.T, reader runtimeimage.ImagePullStatusReader) []runtimeimage.ImagePullStatus {
+	defer reader.Close()
+	var statuses []runtimeimage.ImagePullStatus
+	for {
+		select {
+		case status := <-reader.C():
+			statuses = append(statuses, status)
+			if status.Finish {
+				return statuses
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for pulling finished, got %v", statuses)
+		}
+	}
+}
+
+func TestRegistryMirrorDistributor(t *testing.T) {
+	spec := &appsv1alpha1.ImagePullDistributor{Type: appsv1alpha1.ImagePullDistributorDragonfly, Endpoint: "127.0.0.1:65001"}
+
+	runtime := &fakeImageService{untagged: make(chan string, 1)}
+	d, err := newDistributor(runtime, spec)
+	if err != nil {
+		t.Fatalf("failed to new distributor: %v", err)
+	}
+	reader, err := d.PullImage(context.TODO(), "nginx", "1.21", nil)
+	if err != nil {
+		t.Fatalf("failed to pull: %v", err)
+	}
+	statuses := readUntilFinished(t, reader)
+	expectedStatuses := []runtimeimage.ImagePullStatus{{Process: 50}, {Process: 50}, {Process: 100, Finish: true}}
+	if !reflect.DeepEqual(statuses, expectedStatuses) {
+		t.Fatalf("expected statuses %v, got %v", expectedStatuses, statuses)
+	}
+	expectedPulled := []string{"127.0.0.1:65001/docker.io/library/nginx:1.21", "nginx:1.21"}
+	if !reflect.DeepEqual(runtime.pulled, expectedPulled) {
+		t.Fatalf("expected pulled %v, got %v", expectedPulled, runtime.pulled)
+	}
+	select {
+	case name := <-runtime.untagged:
+		if name != expectedPulled[0] {
+			t.Fatalf("expected untagged %s, got %s", expectedPulled[0], name)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for the mirror image untagged")
+	}
+
+	// it should keep the source registry of image
+	runtime = &fakeImageService{untagged: make(chan string, 1)}
+	d, _ = newDistributor(runtime, spec)
+	reader, err = d.PullImage(context.TODO(), "ghcr.io/foo/bar", "v1", nil)
+	if err != nil {
+		t.Fatalf("failed to pull: %v", err)
+	}
+	readUntilFinished(t, reader)
+	if expected := "127.0.0.1:65001/ghcr.io/foo/bar:v1"; runtime.pulled[0] != expected {
+		t.Fatalf("expected pulled %s from distributor, got %v", expected, runtime.pulled)
+	}
+	if _, err = d.PullImage(context.TODO(), "registry.local:5000/foo/bar", "v1", nil); err == nil {
+		t.Fatalf("expected error for registry with port")
+	}
+
+	// it should not pull from registry if failed to pull from distributor
+	mirrorErr := fmt.Errorf("not found")
+	runtime = &fakeImageService{results: map[string]error{"127.0.0.1:65001/docker.io/library/nginx:1.21": mirrorErr}}
+	d, _ = newDistributor(runtime, spec)
+	reader, err = d.PullImage(context.TODO(), "nginx", "1.21", nil)
+	if err != nil {
+		t.Fatalf("failed to pull: %v", err)
+	}
+	statuses = readUntilFinished(t, reader)
+	if last := statuses[len(statuses)-1]; last.Err != mirrorErr {
+		t.Fatalf("expected error %v, got %v", mirrorErr, last.Err)
+	}
+	if len(runtime.pulled) != 1 {
+		t.Fatalf("expected only pulled from distributor, got %v", runtime.pulled)
+	}
+
+	if _, err = newDistributor(runtime, &appsv1alpha1.ImagePullDistributor{Type: "Unknown"}); err == nil {
+		t.Fatalf("expected error for unknown distributor")
+	}
+}
@@ -27,10 +27,12 @@ import (
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
 	"github.com/openkruise/kruise/pkg/client"
 	kruiseclient "github.com/openkruise/kruise/pkg/client/clientset/versioned"
+	clientalpha1 "github.com/openkruise/kruise/pkg/client/clientset/versioned/typed/apps/v1alpha1"
 	listersalpha1 "github.com/openkruise/kruise/pkg/client/listers/apps/v1alpha1"
 	daemonoptions "github.com/openkruise/kruise/pkg/daemon/options"
 	daemonutil "github.com/openkruise/kruise/pkg/daemon/util"
 	utilimagejob "github.com/openkruise/kruise/pkg/util/imagejob"
+	"golang.org/x/time/rate"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -48,17 +50,32 @@ import (
 
 type Controller struct {
 	scheme                *runtime.Scheme
+	nodeName              string
 	queue                 workqueue.RateLimitingInterface
 	puller                puller
 	imagePullNodeInformer cache.SharedIndexInformer
 	imagePullNodeLister   listersalpha1.NodeImageLister
-	statusUpdater         *statusUpdater
+	// shardInformer watches the extra shards of NodeImage for this node
+	shardInformer cache.SharedIndexInformer
+	shardLister   listersalpha1.NodeImageLister
+
+	nodeImageClient     clientalpha1.NodeImageInterface
+	statusUpdateLimiter *rate.Limiter
+	// statusUpdaters is keyed by the name of NodeImage
+	statusUpdaters map[string]*statusUpdater
 }
 
 // NewController returns the controller for image pulling
 func NewController(opts daemonoptions.Options, secretManager daemonutil.SecretManager) (*Controller, error) {
 	genericClient := client.GetGenericClientWithName("kruise-daemon-imagepuller")
-	informer := newNodeImageInformer(genericClient.KruiseClient, opts.NodeName)
+	informer := newNodeImageInformer(genericClient.KruiseClient, func(opt *metav1.ListOptions) {
+		opt.FieldSelector = "metadata.name=" + opts.NodeName
+		// the shards of other nodes may be named as this node
+		opt.LabelSelector = "!" + appsv1alpha1.NodeImageShardOfLabelKey
+	})
+	shardInformer := newNodeImageInformer(genericClient.KruiseClient, func(opt *metav1.ListOptions) {
+		opt.LabelSelector = appsv1alpha1.NodeImageShardOfLabelKey + "=" + opts.NodeName
+	})
 
 	eventBroadcaster := record.NewBroadcaster()
 	eventBroadcaster.StartRecordingToSink(&v1core.EventSinkImpl{Interface: genericClient.KubeClient.CoreV1().Events("")})
@@ -71,7 +88,7 @@ func NewController(opts daemonoptions.Options, secretManager daemonutil.SecretMa
 		"imagepuller",
 	)
 
-	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+	eventHandler := cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
 			nodeImage, ok := obj.(*appsv1alpha1.NodeImage)
 			if ok {
@@ -91,7 +108,15 @@ func NewController(opts daemonoptions.Options, secretManager daemonutil.SecretMa
 			logNewImages(oldNodeImage, newNodeImage)
 			enqueue(queue, newNodeImage)
 		},
-	})
+		DeleteFunc: func(obj interface{}) {
+			// stop pulling the images in the deleted NodeImage
+			if key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj); err == nil {
+				queue.Add(key)
+			}
+		},
+	}
+	informer.AddEventHandler(eventHandler)
+	shardInformer.AddEventHandler(eventHandler)
 
 	puller, err := newRealPuller(opts.RuntimeFactory.GetImageService(), secretManager, recorder)
 	if err != nil {
@@ -99,7 +124,7 @@ func NewController(opts daemonoptions.Options, secretManager daemonutil.SecretMa
 	}
 
 	opts.Healthz.RegisterFunc("nodeImageInformerSynced", func(_ *http.Request) error {
-		if !informer.HasSynced() {
+		if !informer.HasSynced() || !shardInformer.HasSynced() {
 			return fmt.Errorf("not synced")
 		}
 		return nil
@@ -107,19 +132,20 @@ func NewController(opts daemonoptions.Options, secretManager daemonutil.SecretMa
 
 	return &Controller{
 		scheme:                opts.Scheme,
+		nodeName:              opts.NodeName,
 		queue:                 queue,
 		puller:                puller,
 		imagePullNodeInformer: informer,
 		imagePullNodeLister:   listersalpha1.NewNodeImageLister(informer.GetIndexer()),
-		statusUpdater:         newStatusUpdater(genericClient.KruiseClient.AppsV1alpha1().NodeImages()),
+		shardInformer:         shardInformer,
+		shardLister:           listersalpha1.NewNodeImageLister(shardInformer.GetIndexer()),
+		nodeImageClient:       genericClient.KruiseClient.AppsV1alpha1().NodeImages(),
+		statusUpdateLimiter:   newStatusUpdateRateLimiter(),
+		statusUpdaters:        make(map[string]*statusUpdater),
 	}, nil
 }
 
-func newNodeImageInformer(client kruiseclient.Interface, nodeName string) cache.SharedIndexInformer {
-	tweakListOptionsFunc := func(opt *metav1.ListOptions) {
-		opt.FieldSelector = "metadata.name=" + nodeName
-	}
-
+func newNodeImageInformer(client kruiseclient.Interface, tweakListOptionsFunc func(*metav1.ListOptions)) cache.SharedIndexInformer {
 	return cache.NewSharedIndexInformer(
 		&cache.ListWatch{
 			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
@@ -151,12 +177,13 @@ func (c *Controller) Run(stop <-chan struct{}) {
 
 	klog.Info("Starting informer for NodeImage")
 	go c.imagePullNodeInformer.Run(stop)
-	if !cache.WaitForCacheSync(stop, c.imagePullNodeInformer.HasSynced) {
+	go c.shardInformer.Run(stop)
+	if !cache.WaitForCacheSync(stop, c.imagePullNodeInformer.HasSynced, c.shardInformer.HasSynced) {
 		return
 	}
 
 	klog.Infof("Starting puller controller")
-	// Launch one workers to process resources, for there is only one NodeImage per Node
+	// Launch one workers to process resources, for there are only a few shards of NodeImage per Node
 	go wait.Until(func() {
 		for c.processNextWorkItem() {
 		}
@@ -197,8 +224,11 @@ func (c *Controller) sync(key string) (retErr error) {
 		return nil
 	}
 
-	nodeImage, err := c.imagePullNodeLister.Get(name)
+	nodeImage, err := c.getNodeImage(name)
 	if errors.IsNotFound(err) {
+		// stop all the workers for the deleted NodeImage
+		_ = c.puller.Sync(&appsv1alpha1.NodeImage{ObjectMeta: metav1.ObjectMeta{Name: name}}, nil)
+		delete(c.statusUpdaters, name)
 		return nil
 	} else if err != nil {
 		klog.Errorf("Failed to get NodeImage %s: %v", name, err)
@@ -220,19 +250,23 @@ func (c *Controller) sync(key string) (retErr error) {
 		return
 	}
 
+	now := time.Now()
 	newStatus := appsv1alpha1.NodeImageStatus{
 		ImageStatuses: make(map[string]appsv1alpha1.ImageStatus),
+		// reported by imagedeleter
+		ImageDeleteStatuses: nodeImage.Status.ImageDeleteStatuses,
 	}
 	for imageName, imageSpec := range nodeImage.Spec.Images {
 		newStatus.Desired += int32(len(imageSpec.Tags))
 
-		imageStatus := c.puller.GetStatus(imageName)
+		imageStatus := c.puller.GetStatus(name, imageName)
 		if klog.V(9).Enabled() {
 			klog.V(9).Infof("get image %v status %#v", imageName, imageStatus)
 		}
 		if imageStatus == nil {
 			continue
 		}
+		compactImageStatus(imageStatus, nodeImageStatusCompactionTTL, now)
 		utilimagejob.SortStatusImageTags(imageStatus)
 		newStatus.ImageStatuses[imageName] = *imageStatus
 		for _, tagStatus := range imageStatus.Tags {
@@ -251,7 +285,12 @@ func (c *Controller) sync(key string) (retErr error) {
 	}
 
 	var limited bool
-	limited, retErr = c.statusUpdater.updateStatus(nodeImage, &newStatus)
+	updater, ok := c.statusUpdaters[name]
+	if !ok {
+		updater = newStatusUpdater(c.nodeImageClient, c.statusUpdateLimiter)
+		c.statusUpdaters[name] = updater
+	}
+	limited, retErr = updater.updateStatus(nodeImage, &newStatus)
 	if retErr != nil {
 		return retErr
 	}
@@ -265,3 +304,10 @@ func (c *Controller) sync(key string) (retErr error) {
 	}
 	return nil
 }
+
+func (c *Controller) getNodeImage(name string) (*appsv1alpha1.NodeImage, error) {
+	if name == c.nodeName {
+		return c.imagePullNodeLister.Get(name)
+	}
+	return c.shardLister.Get(name)
+}
@@ -18,6 +18,7 @@ package imagepuller
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"sync"
 	"time"
@@ -46,9 +47,24 @@ const (
 	PullImageFailed  = "PullImageFailed"
 )
 
+var (
+	// maxWorkersForPullImage limits the number of images pulled in parallel on the node.
+	maxWorkersForPullImage = -1
+	// imagePullProgressReportInterval throttles reporting the pulling progress into status.
+	imagePullProgressReportInterval = 5 * time.Second
+	// nodeImageStatusCompactionTTL is the duration after pulling tasks completed to compact their statuses.
+	nodeImageStatusCompactionTTL = 24 * time.Hour
+)
+
+func init() {
+	flag.IntVar(&maxWorkersForPullImage, "max-workers-for-pull-image", maxWorkersForPullImage, "The max number of images pulled in parallel on the node, -1 means no limit.")
+	flag.DurationVar(&imagePullProgressReportInterval, "image-pull-progress-report-interval", imagePullProgressReportInterval, "The min interval to report the pulling progress of images into NodeImage status.")
+	flag.DurationVar(&nodeImageStatusCompactionTTL, "nodeimage-status-compaction-ttl", nodeImageStatusCompactionTTL, "The duration after pulling tasks completed to compact their statuses in NodeImage, 0 means never compact.")
+}
+
 type puller interface {
 	Sync(obj *appsv1alpha1.NodeImage, ref *v1.ObjectReference) error
-	GetStatus(imageName string) *appsv1alpha1.ImageStatus
+	GetStatus(nodeImageName, imageName string) *appsv1alpha1.ImageStatus
 }
 
 type realPuller struct {
@@ -57,7 +73,11 @@ type realPuller struct {
 	secretManager daemonutil.SecretManager
 	eventRecorder record.EventRecorder
 
-	workerPools map[string]workerPool
+	// workerPools is keyed by the name of NodeImage and then the image name,
+	// for there may be multiple shards of NodeImage for a node
+	workerPools map[string]map[string]workerPool
+	// workerLimitedPool is the tokens of the workers allowed to pull images in parallel, nil means no limit.
+	workerLimitedPool chan struct{}
 }
 
 var _ puller = &realPuller{}
@@ -67,7 +87,10 @@ func newRealPuller(runtime runtimeimage.ImageService, secretManager daemonutil.S
 		runtime:       runtime,
 		secretManager: secretManager,
 		eventRecorder: eventRecorder,
-		workerPools:   make(map[string]workerPool),
+		workerPools:   make(map[string]map[string]workerPool),
+	}
+	if maxWorkersForPullImage > 0 {
+		p.workerLimitedPool = make(chan struct{}, maxWorkersForPullImage)
 	}
 	return p, nil
 }
@@ -78,22 +101,26 @@ func (p *realPuller) Sync(obj *appsv1alpha1.NodeImage, ref *v1.ObjectReference)
 
 	p.Lock()
 	defer p.Unlock()
+	workerPools := p.workerPools[obj.Name]
+	if workerPools == nil {
+		workerPools = make(map[string]workerPool)
+	}
 	// stop all workers not in the spec
-	for imageName := range p.workerPools {
+	for imageName := range workerPools {
 		if _, ok := obj.Spec.Images[imageName]; !ok {
 			klog.V(3).Infof("stop workerpool for %v", imageName)
-			pool := p.workerPools[imageName]
-			delete(p.workerPools, imageName)
+			pool := workerPools[imageName]
+			delete(workerPools, imageName)
 			pool.Stop()
 		}
 	}
 	var ret error
 	for imageName, imageSpec := range obj.Spec.Images {
-		pool, ok := p.workerPools[imageName]
+		pool, ok := workerPools[imageName]
 		if !ok {
 			klog.V(3).Infof("starting new workerpool for %v", imageName)
-			pool = newRealWorkerPool(imageName, p.runtime, p.secretManager, p.eventRecorder)
-			p.workerPools[imageName] = pool
+			pool = newRealWorkerPool(imageName, p.runtime, p.secretManager, p.eventRecorder, p.workerLimitedPool)
+			workerPools[imageName] = pool
 		}
 		var imageStatus *appsv1alpha1.ImageStatus
 		if s, ok := obj.Status.ImageStatuses[imageName]; ok {
@@ -103,13 +130,18 @@ func (p *realPuller) Sync(obj *appsv1alpha1.NodeImage, ref *v1.ObjectReference)
 			ret = err
 		}
 	}
+	if len(workerPools) > 0 {
+		p.workerPools[obj.Name] = workerPools
+	} else {
+		delete(p.workerPools, obj.Name)
+	}
 	return ret
 }
 
-func (p *realPuller) GetStatus(imageName string) *appsv1alpha1.ImageStatus {
+func (p *realPuller) GetStatus(nodeImageName, imageName string) *appsv1alpha1.ImageStatus {
 	p.Lock()
 	defer p.Unlock()
-	pool, ok := p.workerPools[imageName]
+	pool, ok := p.workerPools[nodeImageName][imageName]
 	if !ok {
 		return nil
 	}
@@ -137,18 +169,21 @@ type realWorkerPool struct {
 	tagStatuses   map[string]*appsv1alpha1.ImageTagStatus
 	active        bool
 
+	workerLimitedPool chan struct{}
+
 	lastSyncSpec *appsv1alpha1.ImageSpec
 }
 
-func newRealWorkerPool(name string, runtime runtimeimage.ImageService, secretManager daemonutil.SecretManager, eventRecorder record.EventRecorder) *realWorkerPool {
+func newRealWorkerPool(name string, runtime runtimeimage.ImageService, secretManager daemonutil.SecretManager, eventRecorder record.EventRecorder, workerLimitedPool chan struct{}) *realWorkerPool {
 	w := &realWorkerPool{
-		name:          name,
-		runtime:       runtime,
-		secretManager: secretManager,
-		eventRecorder: eventRecorder,
-		pullWorkers:   make(map[string]*pullWorker),
-		tagStatuses:   make(map[string]*appsv1alpha1.ImageTagStatus),
-		active:        true,
+		name:              name,
+		runtime:           runtime,
+		secretManager:     secretManager,
+		eventRecorder:     eventRecorder,
+		pullWorkers:       make(map[string]*pullWorker),
+		tagStatuses:       make(map[string]*appsv1alpha1.ImageTagStatus),
+		active:            true,
+		workerLimitedPool: workerLimitedPool,
 	}
 	return w
 }
@@ -214,7 +249,7 @@ func (w *realWorkerPool) Sync(spec *appsv1alpha1.ImageSpec, status *appsv1alpha1
 		_, ok := w.pullWorkers[tagSpec.Tag]
 
 		if !ok {
-			worker := newPullWorker(w.name, tagSpec, secrets, w.runtime, w, ref, w.eventRecorder)
+			worker := newPullWorker(w.name, tagSpec, secrets, w.runtime, w, ref, w.eventRecorder, w.workerLimitedPool)
 			w.pullWorkers[tagSpec.Tag] = worker
 		}
 	}
@@ -262,7 +297,7 @@ func (w *realWorkerPool) UpdateStatus(status *appsv1alpha1.ImageTagStatus) {
 	w.tagStatuses[status.Tag] = status
 }
 
-func newPullWorker(name string, tagSpec appsv1alpha1.ImageTagSpec, secrets []v1.Secret, runtime runtimeimage.ImageService, statusUpdater imageStatusUpdater, ref *v1.ObjectReference, eventRecorder record.EventRecorder) *pullWorker {
+func newPullWorker(name string, tagSpec appsv1alpha1.ImageTagSpec, secrets []v1.Secret, runtime runtimeimage.ImageService, statusUpdater imageStatusUpdater, ref *v1.ObjectReference, eventRecorder record.EventRecorder, limitedPool chan struct{}) *pullWorker {
 	o := &pullWorker{
 		name:          name,
 		tagSpec:       tagSpec,
@@ -271,6 +306,7 @@ func newPullWorker(name string, tagSpec appsv1alpha1.ImageTagSpec, secrets []v1.
 		statusUpdater: statusUpdater,
 		ref:           ref,
 		eventRecorder: eventRecorder,
+		limitedPool:   limitedPool,
 		active:        true,
 		stopCh:        make(chan struct{}),
 	}
@@ -288,6 +324,7 @@ type pullWorker struct {
 	statusUpdater imageStatusUpdater
 	ref           *v1.ObjectReference
 	eventRecorder record.EventRecorder
+	limitedPool   chan struct{}
 
 	active bool
 	stopCh chan struct{}
@@ -322,6 +359,11 @@ func (w *pullWorker) Run() {
 		StartTime: &startTime,
 		Version:   w.tagSpec.Version,
 	}
+	if !w.acquire(newStatus) {
+		klog.V(3).Infof("Worker %v is stopped before pulling", w.ImageRef())
+		return
+	}
+	defer w.release()
 	defer func() {
 		cost := time.Since(startTime.Time)
 		if newStatus.Phase == appsv1alpha1.ImagePhaseFailed {
@@ -404,6 +446,41 @@ func (w *pullWorker) Run() {
 	}
 }
 
+// acquire waits for a token of the limited pool before pulling, in which the status is Waiting.
+// It returns false if the worker is stopped during waiting.
+func (w *pullWorker) acquire(newStatus *appsv1alpha1.ImageTagStatus) bool {
+	if w.limitedPool == nil {
+		return true
+	}
+	select {
+	case w.limitedPool <- struct{}{}:
+		return true
+	default:
+	}
+
+	klog.V(3).Infof("Worker %v is waiting for the other images pulling, max workers %d", w.ImageRef(), cap(w.limitedPool))
+	waitingStatus := newStatus.DeepCopy()
+	waitingStatus.Phase = appsv1alpha1.ImagePhaseWaiting
+	if w.IsActive() {
+		w.statusUpdater.UpdateStatus(waitingStatus)
+	}
+	select {
+	case w.limitedPool <- struct{}{}:
+		if w.IsActive() {
+			w.statusUpdater.UpdateStatus(newStatus.DeepCopy())
+		}
+		return true
+	case <-w.stopCh:
+		return false
+	}
+}
+
+func (w *pullWorker) release() {
+	if w.limitedPool != nil {
+		<-w.limitedPool
+	}
+}
+
 func (w *pullWorker) getImageInfo(ctx context.Context) (*runtimeimage.ImageInfo, error) {
 	imageInfos, err := w.runtime.ListImages(ctx)
 	if err != nil {
@@ -431,11 +508,18 @@ func (w *pullWorker) doPullImage(ctx context.Context, newStatus *appsv1alpha1.Im
 		return nil
 	}
 
+	var imagePuller distributor = w.runtime
+	if w.tagSpec.PullPolicy != nil && w.tagSpec.PullPolicy.Distributor != nil {
+		if imagePuller, err = newDistributor(w.runtime, w.tagSpec.PullPolicy.Distributor); err != nil {
+			return err
+		}
+	}
+
 	// make it asynchronous for CRI runtime will block in pulling image
 	var statusReader runtimeimage.ImagePullStatusReader
 	pullChan := make(chan struct{})
 	go func() {
-		statusReader, err = w.runtime.PullImage(ctx, w.name, tag, w.secrets)
+		statusReader, err = imagePuller.PullImage(ctx, w.name, tag, w.secrets)
 		close(pullChan)
 	}()
 
@@ -464,8 +548,11 @@ func (w *pullWorker) doPullImage(ctx context.Context, newStatus *appsv1alpha1.Im
 	}
 	defer statusReader.Close()
 
+	// reset the detail left by the previous attempt
+	newStatus.ProgressDetail = nil
 	progress := 0
 	var progressInfo string
+	var lastReportTime time.Time
 	logTicker := time.NewTicker(defaultImagePullingProgressLogInterval)
 	defer logTicker.Stop()
 
@@ -486,6 +573,7 @@ func (w *pullWorker) doPullImage(ctx context.Context, newStatus *appsv1alpha1.Im
 			progress = progressStatus.Process
 			progressInfo = progressStatus.DetailInfo
 			newStatus.Progress = int32(progressStatus.Process)
+			updateProgressDetail(newStatus, &progressStatus, time.Now())
 			klog.V(5).Infof("Pulling image %s:%s, cost: %v, progress: %v%%, detail: %v", w.name, tag, time.Since(startTime.Time), progress, progressInfo)
 			if progressStatus.Finish {
 				if progressStatus.Err == nil {
@@ -493,11 +581,33 @@ func (w *pullWorker) doPullImage(ctx context.Context, newStatus *appsv1alpha1.Im
 				}
 				return fmt.Errorf("pulling image %s:%s error %v", w.name, tag, progressStatus.Err)
 			}
-			w.statusUpdater.UpdateStatus(newStatus)
+			if time.Since(lastReportTime) >= imagePullProgressReportInterval {
+				lastReportTime = time.Now()
+				w.statusUpdater.UpdateStatus(newStatus.DeepCopy())
+			}
 		}
 	}
 }
 
+// updateProgressDetail updates the detail of pulling progress in status, and records the time if the downloaded bytes increased.
+func updateProgressDetail(newStatus *appsv1alpha1.ImageTagStatus, progressStatus *runtimeimage.ImagePullStatus, now time.Time) {
+	if progressStatus.TotalBytes == 0 && progressStatus.CurrentLayer == "" {
+		return
+	}
+	detail := newStatus.ProgressDetail
+	if detail == nil {
+		detail = &appsv1alpha1.ImagePullProgressDetail{}
+		newStatus.ProgressDetail = detail
+	}
+	if detail.LastProgressTime == nil || progressStatus.DownloadedBytes > detail.DownloadedBytes {
+		t := metav1.NewTime(now)
+		detail.LastProgressTime = &t
+	}
+	detail.DownloadedBytes = progressStatus.DownloadedBytes
+	detail.TotalBytes = progressStatus.TotalBytes
+	detail.CurrentLayer = progressStatus.CurrentLayer
+}
+
 func (w *pullWorker) finishPulling(newStatus *appsv1alpha1.ImageTagStatus, phase appsv1alpha1.ImagePullPhase, message string) {
 	newStatus.Phase = phase
 	now := metav1.Now()
@@ -89,12 +89,17 @@ const (
 	statusUpdateBurst = 5
 )
 
-func newStatusUpdater(imagePullNodeClient clientalpha1.NodeImageInterface) *statusUpdater {
+func newStatusUpdateRateLimiter() *rate.Limiter {
+	return rate.NewLimiter(statusUpdateQPS, statusUpdateBurst)
+}
+
+// newStatusUpdater returns the updater for a NodeImage, the updaters of shards for a node should share the rate limiter.
+func newStatusUpdater(imagePullNodeClient clientalpha1.NodeImageInterface, rateLimiter *rate.Limiter) *statusUpdater {
 	return &statusUpdater{
 		imagePullNodeClient: imagePullNodeClient,
 		previousStatus:      &appsv1alpha1.NodeImageStatus{},
 		previousTimestamp:   time.Now().Add(-time.Hour * 24),
-		rateLimiter:         rate.NewLimiter(statusUpdateQPS, statusUpdateBurst),
+		rateLimiter:         rateLimiter,
 	}
 }
 
@@ -130,3 +135,28 @@ func (su *statusUpdater) statusChanged(newStatus *appsv1alpha1.NodeImageStatus)
 	// Can not use imagePullNode.Status to compare because of time accuracy
 	return !reflect.DeepEqual(su.previousStatus, newStatus)
 }
+
+// compactImageStatus compacts the statuses of tags completed for longer than the ttl, which only keep the fields
+// that controllers depend on, so that the NodeImage will not grow unbounded with the finished pulling tasks.
+func compactImageStatus(imageStatus *appsv1alpha1.ImageStatus, ttl time.Duration, now time.Time) {
+	if ttl <= 0 {
+		return
+	}
+	for i := range imageStatus.Tags {
+		tagStatus := &imageStatus.Tags[i]
+		if tagStatus.CompletionTime == nil || now.Sub(tagStatus.CompletionTime.Time) < ttl {
+			continue
+		}
+		compacted := appsv1alpha1.ImageTagStatus{
+			Tag:            tagStatus.Tag,
+			Phase:          tagStatus.Phase,
+			CompletionTime: tagStatus.CompletionTime,
+			Version:        tagStatus.Version,
+		}
+		// keep the reason of failure
+		if tagStatus.Phase == appsv1alpha1.ImagePhaseFailed {
+			compacted.Message = tagStatus.Message
+		}
+		*tagStatus = compacted
+	}
+}
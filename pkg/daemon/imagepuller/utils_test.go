@@ -0,0 +1,56 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagepuller
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCompactImageStatus(t *testing.T) {
+	now := time.Now()
+	longAgo := metav1.NewTime(now.Add(-48 * time.Hour))
+	recently := metav1.NewTime(now.Add(-time.Minute))
+
+	imageStatus := &appsv1alpha1.ImageStatus{Tags: []appsv1alpha1.ImageTagStatus{
+		{Tag: "v1", Phase: appsv1alpha1.ImagePhaseSucceeded, Progress: 100, StartTime: &longAgo, CompletionTime: &longAgo, Version: 1, ImageID: "nginx@sha256:1", Message: "image pulled"},
+		{Tag: "v2", Phase: appsv1alpha1.ImagePhaseFailed, StartTime: &longAgo, CompletionTime: &longAgo, Version: 2, Message: "not found"},
+		{Tag: "v3", Phase: appsv1alpha1.ImagePhaseSucceeded, Progress: 100, StartTime: &recently, CompletionTime: &recently, Version: 1, ImageID: "nginx@sha256:3"},
+		{Tag: "v4", Phase: appsv1alpha1.ImagePhasePulling, Progress: 50, StartTime: &longAgo, Version: 1},
+	}}
+	expected := &appsv1alpha1.ImageStatus{Tags: []appsv1alpha1.ImageTagStatus{
+		{Tag: "v1", Phase: appsv1alpha1.ImagePhaseSucceeded, CompletionTime: &longAgo, Version: 1},
+		{Tag: "v2", Phase: appsv1alpha1.ImagePhaseFailed, CompletionTime: &longAgo, Version: 2, Message: "not found"},
+		{Tag: "v3", Phase: appsv1alpha1.ImagePhaseSucceeded, Progress: 100, StartTime: &recently, CompletionTime: &recently, Version: 1, ImageID: "nginx@sha256:3"},
+		{Tag: "v4", Phase: appsv1alpha1.ImagePhasePulling, Progress: 50, StartTime: &longAgo, Version: 1},
+	}}
+
+	disabled := imageStatus.DeepCopy()
+	compactImageStatus(disabled, 0, now)
+	if !reflect.DeepEqual(disabled, imageStatus) {
+		t.Fatalf("expected not compacted with zero ttl, got %v", disabled)
+	}
+
+	compactImageStatus(imageStatus, 24*time.Hour, now)
+	if !reflect.DeepEqual(imageStatus, expected) {
+		t.Fatalf("expected %v, got %v", expected, imageStatus)
+	}
+}
@@ -127,17 +127,29 @@ func (m *genericRuntimeManager) RunInContainer(id kubeletcontainer.ContainerID,
 // * Run the pre-stop lifecycle hooks (if applicable).
 // * Stop the container.
 func (m *genericRuntimeManager) KillContainer(pod *v1.Pod, containerID kubeletcontainer.ContainerID, containerName string, message string, gracePeriodOverride *int64) error {
+	_, err := m.killContainer(pod, containerID, containerName, message, gracePeriodOverride, "")
+	return err
+}
+
+// KillContainerWithSignal kills a container like KillContainer, but sends the signal to the main process of
+// the container instead of its default stop signal, and stops it after the grace period if it has not exited.
+// It returns false if the signal failed to be sent, and the container is stopped by its default stop signal instead.
+func (m *genericRuntimeManager) KillContainerWithSignal(pod *v1.Pod, containerID kubeletcontainer.ContainerID, containerName string, message string, signal string) (bool, error) {
+	return m.killContainer(pod, containerID, containerName, message, nil, signal)
+}
+
+func (m *genericRuntimeManager) killContainer(pod *v1.Pod, containerID kubeletcontainer.ContainerID, containerName string, message string, gracePeriodOverride *int64, signal string) (bool, error) {
 	var containerSpec *v1.Container
 	if pod != nil {
 		if containerSpec = kubeletcontainer.GetContainerSpec(pod, containerName); containerSpec == nil {
-			return fmt.Errorf("failed to get containerSpec %q(id=%q) in pod %q when killing container for reason %q",
+			return false, fmt.Errorf("failed to get containerSpec %q(id=%q) in pod %q when killing container for reason %q",
 				containerName, containerID.String(), format.Pod(pod), message)
 		}
 	} else {
 		// Restore necessary information if one of the specs is nil.
 		restoredPod, restoredContainer, err := m.restoreSpecsFromContainerLabels(containerID)
 		if err != nil {
-			return err
+			return false, err
 		}
 		pod, containerSpec = restoredPod, restoredContainer
 	}
@@ -170,6 +182,16 @@ func (m *genericRuntimeManager) KillContainer(pod *v1.Pod, containerID kubeletco
 		klog.V(3).Infof("Killing container %q, but using %d second grace period override", containerID, gracePeriod)
 	}
 
+	signaled := signal != ""
+	if signaled {
+		var signalErr error
+		if gracePeriod, signalErr = m.signalContainer(containerID, signal, gracePeriod); signalErr != nil {
+			signaled = false
+			m.recordContainerEvent(pod, containerSpec, containerID.ID, v1.EventTypeWarning, "FailedSignal",
+				"Failed to send %s to container, stopping it with the default stop signal: %v", signal, signalErr)
+		}
+	}
+
 	klog.V(2).Infof("Killing container %q with %d second grace period", containerID.String(), gracePeriod)
 
 	err := m.runtimeService.StopContainer(containerID.ID, gracePeriod)
@@ -179,7 +201,40 @@ func (m *genericRuntimeManager) KillContainer(pod *v1.Pod, containerID kubeletco
 		klog.V(3).Infof("Container %q exited normally", containerID.String())
 	}
 
-	return err
+	return signaled, err
+}
+
+// signalContainer sends the signal to the main process of the container and waits for it to exit in the grace period,
+// then returns the grace period left for stopping the container.
+// The signal is sent by the kill command in the container, for CRI has no API to signal containers.
+// It returns an error if the signal failed to be sent, e.g., there is no kill command in the container image.
+func (m *genericRuntimeManager) signalContainer(containerID kubeletcontainer.ContainerID, signal string, gracePeriod int64) (int64, error) {
+	start := time.Now()
+	cmd := []string{"kill", "-s", strings.TrimPrefix(signal, "SIG"), "1"}
+	if output, err := m.RunInContainer(containerID, cmd, time.Duration(gracePeriod)*time.Second); err != nil {
+		klog.Warningf("Failed to send %s to container %q: %v, output: %s", signal, containerID.String(), err, string(output))
+		return gracePeriod, err
+	}
+	klog.V(3).Infof("Sent %s to container %q, waiting %d seconds for it to exit", signal, containerID.String(), gracePeriod)
+
+	deadline := start.Add(time.Duration(gracePeriod) * time.Second)
+	for time.Now().Before(deadline) {
+		status, err := m.runtimeService.ContainerStatus(containerID.ID)
+		if err != nil {
+			klog.Warningf("Failed to get status of container %q after signal: %v", containerID.String(), err)
+			break
+		}
+		if status.State != runtimeapi.ContainerState_CONTAINER_RUNNING {
+			break
+		}
+		time.Sleep(signalPollInterval)
+	}
+
+	left := int64(time.Until(deadline) / time.Second)
+	if left < minimumGracePeriodInSeconds {
+		left = minimumGracePeriodInSeconds
+	}
+	return left, nil
 }
 
 // restoreSpecsFromContainerLabels restores all information needed for killing a container. In some
@@ -18,6 +18,8 @@ limitations under the License.
 package kuberuntime
 
 import (
+	"time"
+
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
@@ -29,6 +31,9 @@ import (
 
 const (
 	minimumGracePeriodInSeconds = 2
+
+	// signalPollInterval is the interval to check whether the container has exited after signaled
+	signalPollInterval = 500 * time.Millisecond
 )
 
 type Runtime interface {
@@ -39,6 +44,10 @@ type Runtime interface {
 	// * Run the pre-stop lifecycle hooks (if applicable).
 	// * Stop the container.
 	KillContainer(pod *v1.Pod, containerID kubeletcontainer.ContainerID, containerName string, message string, gracePeriodOverride *int64) error
+	// KillContainerWithSignal kills a container like KillContainer, but the signal will be sent to the main process
+	// of the container after pre-stop hooks, and the container will be stopped if not exited in the grace period.
+	// It returns false if the signal failed to be sent, and the container is stopped by its default stop signal instead.
+	KillContainerWithSignal(pod *v1.Pod, containerID kubeletcontainer.ContainerID, containerName string, message string, signal string) (bool, error)
 }
 
 func NewGenericRuntime(
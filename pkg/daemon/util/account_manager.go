@@ -42,7 +42,15 @@ type ImagePullAccountManager interface {
 	GetAccountInfo(repo string) (*AuthInfo, error)
 }
 
-// NewImagePullAccountManager returns an ImagePullAccountManager, defaults to be nil
-func NewImagePullAccountManager(kubeClient clientset.Interface) ImagePullAccountManager {
-	return nil
+// NewImagePullAccountManager returns an ImagePullAccountManager, which gets accounts by the credential provider
+// plugins if configured, defaults to be nil
+func NewImagePullAccountManager(kubeClient clientset.Interface) (ImagePullAccountManager, error) {
+	if imageCredentialProviderConfigFile == "" {
+		return nil, nil
+	}
+	m, err := newPluginAccountManager(imageCredentialProviderConfigFile, imageCredentialProviderBinDir)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
 }
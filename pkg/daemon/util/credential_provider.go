@@ -0,0 +1,277 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/credentialprovider"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	credentialProviderRequestKind  = "CredentialProviderRequest"
+	credentialProviderResponseKind = "CredentialProviderResponse"
+
+	cacheKeyTypeGlobal = "Global"
+
+	credentialProviderExecTimeout = time.Minute
+)
+
+var (
+	// imageCredentialProviderConfigFile is the path to the credential provider plugin config file,
+	// which is the same as the one of kubelet.
+	imageCredentialProviderConfigFile = ""
+	// imageCredentialProviderBinDir is the path to the directory where credential provider plugin binaries are located.
+	imageCredentialProviderBinDir = ""
+)
+
+func init() {
+	flag.StringVar(&imageCredentialProviderConfigFile, "image-credential-provider-config", imageCredentialProviderConfigFile,
+		"The path to the credential provider plugin config file, which is compatible with the one of kubelet, such as the config for ecr-credential-provider.")
+	flag.StringVar(&imageCredentialProviderBinDir, "image-credential-provider-bin-dir", imageCredentialProviderBinDir,
+		"The path to the directory where credential provider plugin binaries are located, which should be mounted into kruise-daemon.")
+}
+
+// credentialProviderConfig is the configuration of the exec credential provider plugins,
+// compatible with kubelet.config.k8s.io CredentialProviderConfig.
+type credentialProviderConfig struct {
+	metav1.TypeMeta `json:",inline"`
+
+	Providers []credentialProviderSpec `json:"providers"`
+}
+
+type credentialProviderSpec struct {
+	Name                 string           `json:"name"`
+	MatchImages          []string         `json:"matchImages"`
+	DefaultCacheDuration *metav1.Duration `json:"defaultCacheDuration,omitempty"`
+	APIVersion           string           `json:"apiVersion"`
+	Args                 []string         `json:"args,omitempty"`
+	Env                  []execEnvVar     `json:"env,omitempty"`
+}
+
+type execEnvVar struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// credentialProviderRequest is compatible with credentialprovider.kubelet.k8s.io CredentialProviderRequest.
+type credentialProviderRequest struct {
+	metav1.TypeMeta `json:",inline"`
+
+	Image string `json:"image"`
+}
+
+// credentialProviderResponse is compatible with credentialprovider.kubelet.k8s.io CredentialProviderResponse.
+type credentialProviderResponse struct {
+	metav1.TypeMeta `json:",inline"`
+
+	CacheKeyType  string                    `json:"cacheKeyType"`
+	CacheDuration *metav1.Duration          `json:"cacheDuration,omitempty"`
+	Auth          map[string]execAuthConfig `json:"auth,omitempty"`
+}
+
+type execAuthConfig struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+func readCredentialProviderConfig(configFile string) (*credentialProviderConfig, error) {
+	data, err := ioutil.ReadFile(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credential provider config %s: %v", configFile, err)
+	}
+	config := &credentialProviderConfig{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to decode credential provider config %s: %v", configFile, err)
+	}
+	if len(config.Providers) == 0 {
+		return nil, fmt.Errorf("no providers found in credential provider config %s", configFile)
+	}
+	for _, provider := range config.Providers {
+		if provider.Name == "" {
+			return nil, fmt.Errorf("name is required for credential provider")
+		}
+		if filepath.Base(provider.Name) != provider.Name {
+			return nil, fmt.Errorf("invalid name %s for credential provider, it should be the name of the binary", provider.Name)
+		}
+		if len(provider.MatchImages) == 0 {
+			return nil, fmt.Errorf("matchImages is required for credential provider %s", provider.Name)
+		}
+		if provider.APIVersion == "" {
+			return nil, fmt.Errorf("apiVersion is required for credential provider %s", provider.Name)
+		}
+	}
+	return config, nil
+}
+
+// newPluginAccountManager returns an ImagePullAccountManager which gets the accounts by exec credential provider plugins,
+// such as ecr-credential-provider, so that the images can be pulled with the identity of the node.
+func newPluginAccountManager(configFile, binDir string) (*pluginAccountManager, error) {
+	config, err := readCredentialProviderConfig(configFile)
+	if err != nil {
+		return nil, err
+	}
+	m := &pluginAccountManager{}
+	for _, provider := range config.Providers {
+		binPath := filepath.Join(binDir, provider.Name)
+		if _, err := os.Stat(binPath); err != nil {
+			return nil, fmt.Errorf("failed to find binary of credential provider %s: %v", provider.Name, err)
+		}
+		m.plugins = append(m.plugins, &credentialProviderPlugin{
+			provider: provider,
+			binPath:  binPath,
+			cache:    make(map[string]cacheAccountItem),
+		})
+	}
+	return m, nil
+}
+
+type pluginAccountManager struct {
+	plugins []*credentialProviderPlugin
+}
+
+func (m *pluginAccountManager) GetAccountInfo(registry string) (*AuthInfo, error) {
+	for _, plugin := range m.plugins {
+		if !plugin.isImageAllowed(registry) {
+			continue
+		}
+		return plugin.getAccountInfo(registry)
+	}
+	return nil, nil
+}
+
+type credentialProviderPlugin struct {
+	provider credentialProviderSpec
+	binPath  string
+
+	lock  sync.Mutex
+	cache map[string]cacheAccountItem
+}
+
+type cacheAccountItem struct {
+	deadline time.Time
+	authInfo *AuthInfo
+}
+
+func (c cacheAccountItem) isExpired() bool {
+	return time.Now().After(c.deadline)
+}
+
+func (p *credentialProviderPlugin) isImageAllowed(image string) bool {
+	for _, matchImage := range p.provider.MatchImages {
+		if matched, _ := credentialprovider.URLsMatchStr(matchImage, image); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *credentialProviderPlugin) getAccountInfo(registry string) (*AuthInfo, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for _, key := range []string{registry, cacheKeyTypeGlobal} {
+		if item, ok := p.cache[key]; ok && !item.isExpired() {
+			return item.authInfo, nil
+		}
+	}
+
+	resp, err := p.exec(registry)
+	if err != nil {
+		return nil, err
+	}
+
+	var authInfo *AuthInfo
+	for matchImage, auth := range resp.Auth {
+		if matched, _ := credentialprovider.URLsMatchStr(matchImage, registry); matched {
+			authInfo = &AuthInfo{Username: auth.Username, Password: auth.Password}
+			break
+		}
+	}
+
+	var cacheDuration time.Duration
+	if resp.CacheDuration != nil {
+		cacheDuration = resp.CacheDuration.Duration
+	} else if p.provider.DefaultCacheDuration != nil {
+		cacheDuration = p.provider.DefaultCacheDuration.Duration
+	}
+	if cacheDuration > 0 {
+		// the account got for the registry is also valid for the images in it, so cache it by registry unless global
+		key := registry
+		if resp.CacheKeyType == cacheKeyTypeGlobal {
+			key = cacheKeyTypeGlobal
+		}
+		p.cache[key] = cacheAccountItem{deadline: time.Now().Add(cacheDuration), authInfo: authInfo}
+	}
+	return authInfo, nil
+}
+
+func (p *credentialProviderPlugin) exec(image string) (*credentialProviderResponse, error) {
+	req := credentialProviderRequest{
+		TypeMeta: metav1.TypeMeta{APIVersion: p.provider.APIVersion, Kind: credentialProviderRequestKind},
+		Image:    image,
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), credentialProviderExecTimeout)
+	defer cancel()
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cmd := exec.CommandContext(ctx, p.binPath, p.provider.Args...)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.Env = os.Environ()
+	for _, env := range p.provider.Env {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", env.Name, env.Value))
+	}
+
+	klog.V(5).Infof("Exec credential provider %s for image %s", p.provider.Name, image)
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to exec credential provider %s: %v, stderr: %s", p.provider.Name, err, stderr.String())
+	}
+
+	resp := &credentialProviderResponse{}
+	if err := json.Unmarshal(stdout.Bytes(), resp); err != nil {
+		return nil, fmt.Errorf("failed to decode response from credential provider %s: %v", p.provider.Name, err)
+	}
+	if resp.Kind != credentialProviderResponseKind {
+		return nil, fmt.Errorf("unexpected kind %q in response from credential provider %s", resp.Kind, p.provider.Name)
+	}
+	if resp.APIVersion != p.provider.APIVersion {
+		return nil, fmt.Errorf("unexpected apiVersion %q in response from credential provider %s, expected %q",
+			resp.APIVersion, p.provider.Name, p.provider.APIVersion)
+	}
+	return resp, nil
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const fakeCredentialProviderConfig = `
+apiVersion: kubelet.config.k8s.io/v1alpha1
+kind: CredentialProviderConfig
+providers:
+  - name: fake-credential-provider
+    matchImages:
+      - "*.dkr.ecr.*.amazonaws.com"
+    defaultCacheDuration: "12h"
+    apiVersion: credentialprovider.kubelet.k8s.io/v1alpha1
+    env:
+      - name: FAKE_PASSWORD
+        value: secret
+`
+
+// fakeCredentialProviderScript counts the times it is executed and returns the account for the requested image.
+const fakeCredentialProviderScript = `#!/bin/sh
+echo x >> "$(dirname "$0")/count"
+image=$(cat | sed -e 's/.*"image":"\([^"]*\)".*/\1/')
+echo "{\"apiVersion\":\"credentialprovider.kubelet.k8s.io/v1alpha1\",\"kind\":\"CredentialProviderResponse\",\"cacheKeyType\":\"Registry\",\"auth\":{\"${image}\":{\"username\":\"AWS\",\"password\":\"${FAKE_PASSWORD}\"}}}"
+`
+
+func TestPluginAccountManager(t *testing.T) {
+	dir, err := ioutil.TempDir("", "credential-provider")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	configFile := filepath.Join(dir, "config.yaml")
+	if err := ioutil.WriteFile(configFile, []byte(fakeCredentialProviderConfig), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "fake-credential-provider"), []byte(fakeCredentialProviderScript), 0755); err != nil {
+		t.Fatalf("failed to write plugin: %v", err)
+	}
+
+	m, err := newPluginAccountManager(configFile, dir)
+	if err != nil {
+		t.Fatalf("failed to new account manager: %v", err)
+	}
+
+	authInfo, err := m.GetAccountInfo("docker.io")
+	if err != nil || authInfo != nil {
+		t.Fatalf("expected no account for unmatched registry, but got %v, %v", authInfo, err)
+	}
+
+	registry := "123456789012.dkr.ecr.us-west-2.amazonaws.com"
+	for i := 0; i < 2; i++ {
+		authInfo, err = m.GetAccountInfo(registry)
+		if err != nil {
+			t.Fatalf("failed to get account: %v", err)
+		}
+		if authInfo == nil || authInfo.Username != "AWS" || authInfo.Password != "secret" {
+			t.Fatalf("unexpected account: %v", authInfo)
+		}
+	}
+
+	count, err := ioutil.ReadFile(filepath.Join(dir, "count"))
+	if err != nil {
+		t.Fatalf("failed to read count: %v", err)
+	}
+	if n := strings.Count(string(count), "x"); n != 1 {
+		t.Fatalf("expected plugin executed once with cache, but got %d", n)
+	}
+}
+
+func TestReadCredentialProviderConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "credential-provider")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cases := map[string]string{
+		"no providers":     "providers: []",
+		"no matchImages":   "providers:\n  - name: foo\n    apiVersion: credentialprovider.kubelet.k8s.io/v1alpha1",
+		"no apiVersion":    "providers:\n  - name: foo\n    matchImages: [\"*.gcr.io\"]",
+		"name is not base": "providers:\n  - name: ../foo\n    matchImages: [\"*.gcr.io\"]\n    apiVersion: credentialprovider.kubelet.k8s.io/v1alpha1",
+	}
+	for name, config := range cases {
+		configFile := filepath.Join(dir, "config.yaml")
+		if err := ioutil.WriteFile(configFile, []byte(config), 0644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		if _, err := readCredentialProviderConfig(configFile); err == nil {
+			t.Fatalf("%s: expected error, but got nil", name)
+		}
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"strings"
 
 	"github.com/docker/distribution/reference"
+	"github.com/opencontainers/go-digest"
 )
 
 // NodeName returns the node name of this daemon
@@ -62,6 +63,14 @@ func ParseRepositoryTag(repos string) (string, string) {
 	return repos, ""
 }
 
+// JoinImageNameTag returns the image reference of name and tag, and the tag can also be a digest like sha256:xxx.
+func JoinImageNameTag(name, tag string) string {
+	if _, err := digest.Parse(tag); err == nil {
+		return name + "@" + tag
+	}
+	return name + ":" + tag
+}
+
 // NormalizeImageRefToNameTag normalizes the image reference to name and tag.
 func NormalizeImageRefToNameTag(ref string) (string, string, error) {
 	namedRef, err := NormalizeImageRef(ref)
@@ -108,3 +117,25 @@ func NormalizeImageRef(ref string) (reference.Named, error) {
 	}
 	return reference.TagNameOnly(named), nil
 }
+
+// ReplaceImageDomain returns the image name to pull from the mirror endpoint, which keeps the registry domain of
+// the image as the first path component, such as ghcr.io/foo/bar to 127.0.0.1:65001/ghcr.io/foo/bar, so that
+// the mirror can fetch it from the source registry rather than its default upstream.
+func ReplaceImageDomain(imageName, endpoint string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(imageName)
+	if err != nil {
+		return "", err
+	}
+	domain := reference.Domain(named)
+	if strings.Contains(domain, ":") {
+		return "", fmt.Errorf("registry %s with port can not be pulled through mirror", domain)
+	}
+	mirrorNamed, err := reference.ParseNormalizedNamed(endpoint + "/" + domain + "/" + reference.Path(named))
+	if err != nil {
+		return "", fmt.Errorf("invalid endpoint %s: %v", endpoint, err)
+	}
+	if reference.Domain(mirrorNamed) != endpoint {
+		return "", fmt.Errorf("invalid endpoint %s: not a registry address", endpoint)
+	}
+	return mirrorNamed.Name(), nil
+}
@@ -43,8 +43,8 @@ const (
 	// Otherwise, it will only be injected to Pods created by Kruise workloads.
 	KruisePodReadinessGate featuregate.Feature = "KruisePodReadinessGate"
 
-	// PreDownloadImageForInPlaceUpdate enables cloneset-controller to create ImagePullJobs to
-	// pre-download images for in-place update.
+	// PreDownloadImageForInPlaceUpdate enables cloneset, statefulset, daemonset and sidecarset controllers
+	// to create ImagePullJobs to pre-download images for in-place update.
 	PreDownloadImageForInPlaceUpdate featuregate.Feature = "PreDownloadImageForInPlaceUpdate"
 
 	// CloneSetPartitionRollback enables CloneSet controller to rollback Pods to currentRevision
@@ -65,6 +65,14 @@ const (
 	// PodUnavailableBudgetUpdateGate enables PUB capability to protect pod from in-place update
 	PodUnavailableBudgetUpdateGate featuregate.Feature = "PodUnavailableBudgetUpdateGate"
 
+	// PodUnavailableBudgetReservation enables PUB webhook to batch the concurrent pod operations of a PUB,
+	// and persist them to PUB status with a single update before admitting them.
+	PodUnavailableBudgetReservation featuregate.Feature = "PodUnavailableBudgetReservation"
+
+	// PodUnavailableBudgetDefaultPolicy enables creating default PUBs for the workloads without explicit PUB
+	// in the namespaces annotated with default budget.
+	PodUnavailableBudgetDefaultPolicy featuregate.Feature = "PodUnavailableBudgetDefaultPolicy"
+
 	// WorkloadSpread enable WorkloadSpread to constrain the spread of the workload.
 	WorkloadSpread featuregate.Feature = "WorkloadSpread"
 
@@ -82,6 +90,10 @@ const (
 
 	// Enables policies controlling deletion of PVCs created by a StatefulSet.
 	StatefulSetAutoDeletePVC featuregate.Feature = "StatefulSetAutoDeletePVC"
+
+	// InPlaceWorkloadVerticalScaling enables workloads to in-place resize cpu/memory of containers
+	// via pods/resize subresource, which requires InPlacePodVerticalScaling enabled in Kubernetes.
+	InPlaceWorkloadVerticalScaling featuregate.Feature = "InPlaceWorkloadVerticalScaling"
 )
 
 var defaultFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
@@ -89,17 +101,20 @@ var defaultFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
 	KruiseDaemon:      {Default: true, PreRelease: featuregate.Beta},
 	DaemonWatchingPod: {Default: true, PreRelease: featuregate.Beta},
 
-	CloneSetShortHash:                {Default: false, PreRelease: featuregate.Alpha},
-	KruisePodReadinessGate:           {Default: false, PreRelease: featuregate.Alpha},
-	PreDownloadImageForInPlaceUpdate: {Default: false, PreRelease: featuregate.Alpha},
-	CloneSetPartitionRollback:        {Default: false, PreRelease: featuregate.Alpha},
-	ResourcesDeletionProtection:      {Default: false, PreRelease: featuregate.Alpha},
-	WorkloadSpread:                   {Default: false, PreRelease: featuregate.Alpha},
-	PodUnavailableBudgetDeleteGate:   {Default: false, PreRelease: featuregate.Alpha},
-	PodUnavailableBudgetUpdateGate:   {Default: false, PreRelease: featuregate.Alpha},
-	TemplateNoDefaults:               {Default: false, PreRelease: featuregate.Alpha},
-	InPlaceUpdateEnvFromMetadata:     {Default: false, PreRelease: featuregate.Alpha},
-	StatefulSetAutoDeletePVC:         {Default: false, PreRelease: featuregate.Alpha},
+	CloneSetShortHash:                 {Default: false, PreRelease: featuregate.Alpha},
+	KruisePodReadinessGate:            {Default: false, PreRelease: featuregate.Alpha},
+	PreDownloadImageForInPlaceUpdate:  {Default: false, PreRelease: featuregate.Alpha},
+	CloneSetPartitionRollback:         {Default: false, PreRelease: featuregate.Alpha},
+	ResourcesDeletionProtection:       {Default: false, PreRelease: featuregate.Alpha},
+	WorkloadSpread:                    {Default: false, PreRelease: featuregate.Alpha},
+	PodUnavailableBudgetDeleteGate:    {Default: false, PreRelease: featuregate.Alpha},
+	PodUnavailableBudgetUpdateGate:    {Default: false, PreRelease: featuregate.Alpha},
+	PodUnavailableBudgetReservation:   {Default: false, PreRelease: featuregate.Alpha},
+	PodUnavailableBudgetDefaultPolicy: {Default: false, PreRelease: featuregate.Alpha},
+	TemplateNoDefaults:                {Default: false, PreRelease: featuregate.Alpha},
+	InPlaceUpdateEnvFromMetadata:      {Default: false, PreRelease: featuregate.Alpha},
+	StatefulSetAutoDeletePVC:          {Default: false, PreRelease: featuregate.Alpha},
+	InPlaceWorkloadVerticalScaling:    {Default: false, PreRelease: featuregate.Alpha},
 }
 
 func init() {
@@ -125,6 +140,8 @@ func SetDefaultFeatureGates() {
 		_ = utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=false", ResourcesDeletionProtection))
 		_ = utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=false", PodUnavailableBudgetDeleteGate))
 		_ = utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=false", PodUnavailableBudgetUpdateGate))
+		_ = utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=false", PodUnavailableBudgetReservation))
+		_ = utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=false", PodUnavailableBudgetDefaultPolicy))
 		_ = utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=false", WorkloadSpread))
 	}
 	if !utilfeature.DefaultFeatureGate.Enabled(KruiseDaemon) {
@@ -21,12 +21,16 @@ import (
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
 	appsv1beta1 "github.com/openkruise/kruise/apis/apps/v1beta1"
+	kubeClient "github.com/openkruise/kruise/pkg/client"
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/scale"
+	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -61,12 +65,18 @@ type PodControllerFinder func(ref ControllerReference, namespace string) (*Scale
 
 type ControllerFinder struct {
 	client.Client
+	// scaleClient is used to find the custom workloads exposing the scale subresource
+	scaleClient scale.ScalesGetter
 }
 
 func NewControllerFinder(c client.Client) *ControllerFinder {
-	return &ControllerFinder{
+	finder := &ControllerFinder{
 		Client: c,
 	}
+	if genericClient := kubeClient.GetGenericClient(); genericClient != nil {
+		finder.scaleClient = genericClient.ScaleClient
+	}
+	return finder
 }
 
 func (r *ControllerFinder) GetExpectedScaleForPods(pods []*corev1.Pod) (int32, error) {
@@ -119,6 +129,22 @@ func (r *ControllerFinder) GetScaleAndSelectorForRef(apiVersion, kind, ns, name
 	return nil, nil
 }
 
+// GetScaleAndSelectorForTargetRef returns the workload referenced by the targetRef of policies, e.g. PodUnavailableBudget.
+// Besides the workloads supported by Finders, it also supports the custom workloads exposing the scale subresource,
+// e.g. Argo Rollouts.
+func (r *ControllerFinder) GetScaleAndSelectorForTargetRef(apiVersion, kind, ns, name string, uid types.UID) (*ScaleAndSelector, error) {
+	if IsValidGroupVersionKind(apiVersion, kind) {
+		return r.GetScaleAndSelectorForRef(apiVersion, kind, ns, name, uid)
+	}
+	targetRef := ControllerReference{
+		APIVersion: apiVersion,
+		Kind:       kind,
+		Name:       name,
+		UID:        uid,
+	}
+	return r.getScaleSubresource(targetRef, ns)
+}
+
 func (r *ControllerFinder) Finders() []PodControllerFinder {
 	return []PodControllerFinder{r.getPodReplicationController, r.getPodDeployment, r.getPodReplicaSet,
 		r.getPodStatefulSet, r.getPodKruiseCloneSet, r.getPodKruiseStatefulSet}
@@ -374,6 +400,57 @@ func (r *ControllerFinder) getPodKruiseStatefulSet(ref ControllerReference, name
 	}, nil
 }
 
+// getScaleSubresource returns the custom workload referenced by the provided controllerRef via scale subresource.
+func (r *ControllerFinder) getScaleSubresource(ref ControllerReference, namespace string) (*ScaleAndSelector, error) {
+	if r.scaleClient == nil {
+		return nil, nil
+	}
+	// This error is irreversible, so there is no need to return error
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		return nil, nil
+	}
+	mapping, err := r.RESTMapper().RESTMapping(schema.GroupKind{Group: gv.Group, Kind: ref.Kind}, gv.Version)
+	if err != nil {
+		// the kind of workload is not installed, it is ok here.
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	s, err := r.scaleClient.Scales(namespace).Get(context.TODO(), mapping.Resource.GroupResource(), ref.Name, metav1.GetOptions{})
+	if err != nil {
+		// when the workload is not found or doesn't expose the scale subresource, it is ok here.
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if ref.UID != "" && s.UID != ref.UID {
+		return nil, nil
+	}
+
+	workload := &ScaleAndSelector{
+		Scale: s.Spec.Replicas,
+		ControllerReference: ControllerReference{
+			APIVersion: ref.APIVersion,
+			Kind:       ref.Kind,
+			Name:       s.Name,
+			UID:        s.UID,
+		},
+		Metadata: s.ObjectMeta,
+	}
+	// If the workload doesn't report its selector, it should match nothing, not everything.
+	if s.Status.Selector != "" {
+		workload.Selector, err = metav1.ParseToLabelSelector(s.Status.Selector)
+		if err != nil {
+			klog.Errorf("%s(%s/%s) parse scale selector(%s) failed: %s", ref.Kind, namespace, ref.Name, s.Status.Selector, err.Error())
+			return nil, nil
+		}
+	}
+	return workload, nil
+}
+
 func verifyGroupKind(apiVersion, kind string, gvk schema.GroupVersionKind) (bool, error) {
 	gv, err := schema.ParseGroupVersion(apiVersion)
 	if err != nil {
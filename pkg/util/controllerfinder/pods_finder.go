@@ -71,13 +71,22 @@ func (r *ControllerFinder) GetPodsForRef(apiVersion, kind, name, ns string, acti
 		}
 	// others, e.g. rc, cloneset, statefulset...
 	default:
-		obj, err := r.GetScaleAndSelectorForRef(apiVersion, kind, ns, name, "")
+		obj, err := r.GetScaleAndSelectorForTargetRef(apiVersion, kind, ns, name, "")
 		if err != nil {
 			return nil, -1, err
 		}
 		if obj == nil {
 			return nil, 0, nil
 		}
+		// the pods of custom workloads may be owned by the intermediate workloads, e.g. Argo Rollouts -> ReplicaSet -> Pod,
+		// so list the pods by the selector of scale subresource.
+		if !IsValidGroupVersionKind(apiVersion, kind) {
+			matchedPods, err := r.getPodsForSelector(obj.Selector, ns, active)
+			if err != nil {
+				return nil, -1, err
+			}
+			return matchedPods, obj.Scale, nil
+		}
 		workloadReplicas = obj.Scale
 		workloadUIDs = append(workloadUIDs, obj.UID)
 	}
@@ -106,6 +115,34 @@ func (r *ControllerFinder) GetPodsForRef(apiVersion, kind, name, ns string, acti
 	return matchedPods, workloadReplicas, nil
 }
 
+func (r *ControllerFinder) getPodsForSelector(selector *metav1.LabelSelector, ns string, active bool) ([]*corev1.Pod, error) {
+	if selector == nil {
+		return nil, nil
+	}
+	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		klog.Errorf("Namespace(%s) get labelSelector failed: %s", ns, err.Error())
+		return nil, nil
+	}
+	if labelSelector.Empty() {
+		return nil, nil
+	}
+	podList := &corev1.PodList{}
+	if err = r.List(context.TODO(), podList, &client.ListOptions{Namespace: ns, LabelSelector: labelSelector}, utilclient.DisableDeepCopy); err != nil {
+		return nil, err
+	}
+	matchedPods := make([]*corev1.Pod, 0, len(podList.Items))
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		// filter not active Pod if active is true.
+		if active && !kubecontroller.IsPodActive(pod) {
+			continue
+		}
+		matchedPods = append(matchedPods, pod)
+	}
+	return matchedPods, nil
+}
+
 func (r *ControllerFinder) getReplicaSetsForDeployment(apiVersion, kind, ns, name string) ([]appsv1.ReplicaSet, error) {
 	targetRef := ControllerReference{
 		APIVersion: apiVersion,
@@ -0,0 +1,130 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllerfinder
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/scale"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var rolloutGVK = schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Rollout"}
+
+// fakeScales returns the scales of custom workloads, key is resource/name
+type fakeScales map[string]*autoscalingv1.Scale
+
+func (f fakeScales) Scales(namespace string) scale.ScaleInterface {
+	return f
+}
+
+func (f fakeScales) Get(ctx context.Context, resource schema.GroupResource, name string, opts metav1.GetOptions) (*autoscalingv1.Scale, error) {
+	s, ok := f[fmt.Sprintf("%s/%s", resource.String(), name)]
+	if !ok {
+		return nil, errors.NewNotFound(resource, name)
+	}
+	return s.DeepCopy(), nil
+}
+
+func (f fakeScales) Update(ctx context.Context, resource schema.GroupResource, scale *autoscalingv1.Scale, opts metav1.UpdateOptions) (*autoscalingv1.Scale, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (f fakeScales) Patch(ctx context.Context, gvr schema.GroupVersionResource, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions) (*autoscalingv1.Scale, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func TestGetPodsForCustomRef(t *testing.T) {
+	cases := []struct {
+		name           string
+		getScales      func() fakeScales
+		expectPods     int
+		expectReplicas int32
+	}{
+		{
+			name: "custom workload with scale subresource",
+			getScales: func() fakeScales {
+				return fakeScales{"rollouts.argoproj.io/echoserver": {
+					ObjectMeta: metav1.ObjectMeta{Name: "echoserver", Namespace: "default", UID: "rollout-uid"},
+					Spec:       autoscalingv1.ScaleSpec{Replicas: 5},
+					Status:     autoscalingv1.ScaleStatus{Replicas: 3, Selector: "app=echoserver"},
+				}}
+			},
+			expectPods:     3,
+			expectReplicas: 5,
+		},
+		{
+			name: "custom workload without selector",
+			getScales: func() fakeScales {
+				return fakeScales{"rollouts.argoproj.io/echoserver": {
+					ObjectMeta: metav1.ObjectMeta{Name: "echoserver", Namespace: "default", UID: "rollout-uid"},
+					Spec:       autoscalingv1.ScaleSpec{Replicas: 5},
+				}}
+			},
+			expectPods:     0,
+			expectReplicas: 5,
+		},
+		{
+			name: "custom workload not found",
+			getScales: func() fakeScales {
+				return fakeScales{}
+			},
+			expectPods:     0,
+			expectReplicas: 0,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = corev1.AddToScheme(scheme)
+			mapper := meta.NewDefaultRESTMapper(nil)
+			mapper.Add(rolloutGVK, meta.RESTScopeNamespace)
+			builder := fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(mapper)
+			for i := 0; i < 4; i++ {
+				labels := map[string]string{"app": "echoserver"}
+				if i == 3 {
+					labels["app"] = "other"
+				}
+				builder.WithObjects(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("pod-%d", i),
+					Namespace: "default",
+					Labels:    labels,
+				}})
+			}
+			finder := &ControllerFinder{Client: builder.Build(), scaleClient: cs.getScales()}
+
+			pods, replicas, err := finder.GetPodsForRef(rolloutGVK.GroupVersion().String(), rolloutGVK.Kind, "echoserver", "default", true)
+			if err != nil {
+				t.Fatalf("GetPodsForRef failed: %s", err.Error())
+			}
+			if len(pods) != cs.expectPods || replicas != cs.expectReplicas {
+				t.Fatalf("expect pods(%d) replicas(%d), but get pods(%d) replicas(%d)", cs.expectPods, cs.expectReplicas, len(pods), replicas)
+			}
+		})
+	}
+}
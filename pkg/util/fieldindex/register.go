@@ -18,6 +18,7 @@ package fieldindex
 
 import (
 	"context"
+	"strings"
 	"sync"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -35,6 +36,14 @@ const (
 	IndexNameForOwnerRefUID = "ownerRefUID"
 	IndexNameForController  = ".metadata.controller"
 	IndexNameForIsActive    = "isActive"
+	IndexNameForRelatedPub  = "relatedPub"
+
+	IndexNameForInjectedSidecarSet = "injectedSidecarSet"
+
+	// podRelatedPubAnnotation is the same as pubcontrol.PodRelatedPubAnnotation
+	podRelatedPubAnnotation = "kruise.io/related-pub"
+	// podInjectedSidecarSetsAnnotation is the same as sidecarcontrol.SidecarSetListAnnotation
+	podInjectedSidecarSetsAnnotation = "kruise.io/sidecarset-injected-list"
 )
 
 var (
@@ -50,6 +59,15 @@ var ownerIndexFunc = func(obj client.Object) []string {
 	return owners
 }
 
+// advancedCronJobOwnerIndexFunc indexes the jobs by the name of the AdvancedCronJob controlling them.
+var advancedCronJobOwnerIndexFunc = func(obj client.Object) []string {
+	owner := metav1.GetControllerOf(obj)
+	if owner == nil || owner.APIVersion != apiGVStr || owner.Kind != appsv1alpha1.AdvancedCronJobKind {
+		return nil
+	}
+	return []string{owner.Name}
+}
+
 func RegisterFieldIndexes(c cache.Cache) error {
 	var err error
 	registerOnce.Do(func() {
@@ -66,6 +84,14 @@ func RegisterFieldIndexes(c cache.Cache) error {
 		if err = indexPodNodeName(c); err != nil {
 			return
 		}
+		// pod related-pub annotation
+		if err = indexPodRelatedPub(c); err != nil {
+			return
+		}
+		// pod sidecarset-injected-list annotation
+		if err = indexPodInjectedSidecarSet(c); err != nil {
+			return
+		}
 		// job owner
 		if err = indexJob(c); err != nil {
 			return
@@ -76,11 +102,20 @@ func RegisterFieldIndexes(c cache.Cache) error {
 				return
 			}
 		}
-		// imagepulljob active
+		// imagepulljob active and owner
 		if utildiscovery.DiscoverObject(&appsv1alpha1.ImagePullJob{}) {
 			if err = indexImagePullJobActive(c); err != nil {
 				return
 			}
+			if err = c.IndexField(context.TODO(), &appsv1alpha1.ImagePullJob{}, IndexNameForController, advancedCronJobOwnerIndexFunc); err != nil {
+				return
+			}
+		}
+		// ephemeraljob owner
+		if utildiscovery.DiscoverObject(&appsv1alpha1.EphemeralJob{}) {
+			if err = c.IndexField(context.TODO(), &appsv1alpha1.EphemeralJob{}, IndexNameForController, advancedCronJobOwnerIndexFunc); err != nil {
+				return
+			}
 		}
 	})
 	return err
@@ -99,42 +134,32 @@ func indexPodNodeName(c cache.Cache) error {
 	})
 }
 
-func indexJob(c cache.Cache) error {
-	return c.IndexField(context.TODO(), &batchv1.Job{}, IndexNameForController, func(rawObj client.Object) []string {
-		// grab the job object, extract the owner...
-		job := rawObj.(*batchv1.Job)
-		owner := metav1.GetControllerOf(job)
-		if owner == nil {
-			return nil
-		}
-
-		// ...make sure it's a AdvancedCronJob...
-		if owner.APIVersion != apiGVStr || owner.Kind != appsv1alpha1.AdvancedCronJobKind {
-			return nil
+func indexPodRelatedPub(c cache.Cache) error {
+	return c.IndexField(context.TODO(), &v1.Pod{}, IndexNameForRelatedPub, func(obj client.Object) []string {
+		pubName := obj.GetAnnotations()[podRelatedPubAnnotation]
+		if pubName == "" {
+			return []string{}
 		}
-
-		// ...and if so, return it
-		return []string{owner.Name}
+		return []string{pubName}
 	})
 }
 
-func indexBroadcastCronJob(c cache.Cache) error {
-	return c.IndexField(context.TODO(), &appsv1alpha1.BroadcastJob{}, IndexNameForController, func(rawObj client.Object) []string {
-		// grab the job object, extract the owner...
-		job := rawObj.(*appsv1alpha1.BroadcastJob)
-		owner := metav1.GetControllerOf(job)
-		if owner == nil {
-			return nil
+func indexPodInjectedSidecarSet(c cache.Cache) error {
+	return c.IndexField(context.TODO(), &v1.Pod{}, IndexNameForInjectedSidecarSet, func(obj client.Object) []string {
+		names := obj.GetAnnotations()[podInjectedSidecarSetsAnnotation]
+		if names == "" {
+			return []string{}
 		}
+		return strings.Split(names, ",")
+	})
+}
 
-		// ...make sure it's a AdvancedCronJob...
-		if owner.APIVersion != apiGVStr || owner.Kind != appsv1alpha1.AdvancedCronJobKind {
-			return nil
-		}
+func indexJob(c cache.Cache) error {
+	return c.IndexField(context.TODO(), &batchv1.Job{}, IndexNameForController, advancedCronJobOwnerIndexFunc)
+}
 
-		// ...and if so, return it
-		return []string{owner.Name}
-	})
+func indexBroadcastCronJob(c cache.Cache) error {
+	return c.IndexField(context.TODO(), &appsv1alpha1.BroadcastJob{}, IndexNameForController, advancedCronJobOwnerIndexFunc)
 }
 
 func indexImagePullJobActive(c cache.Cache) error {
@@ -64,6 +64,8 @@ func writeCache(job *appsv1alpha1.ImagePullJob, nodeImages []*appsv1alpha1.NodeI
 	cachedNodeImages[string(job.UID)] = names
 }
 
+// GetNodeImagesForJob returns the NodeImages that the image of job should be synced into.
+// If NodeImage is sharded, they are the shards that the image belongs to on the selected nodes.
 func GetNodeImagesForJob(reader client.Reader, job *appsv1alpha1.ImagePullJob) (nodeImages []*appsv1alpha1.NodeImage, err error) {
 	defer func() {
 		if err == nil {
@@ -71,6 +73,14 @@ func GetNodeImagesForJob(reader client.Reader, job *appsv1alpha1.ImagePullJob) (
 		}
 	}()
 
+	nodeImages, err = getNodeImagesOfNodesForJob(reader, job)
+	if err != nil {
+		return nil, err
+	}
+	return getNodeImageShardsForImage(reader, nodeImages, job.Spec.Image)
+}
+
+func getNodeImagesOfNodesForJob(reader client.Reader, job *appsv1alpha1.ImagePullJob) (nodeImages []*appsv1alpha1.NodeImage, err error) {
 	if job.Spec.PodSelector != nil {
 		selector, err := util.GetFastLabelSelector(&job.Spec.PodSelector.LabelSelector)
 		if err != nil {
@@ -138,6 +148,9 @@ func GetNodeImagesForJob(reader client.Reader, job *appsv1alpha1.ImagePullJob) (
 				}
 				return nil, fmt.Errorf("get specific NodeImage %s error: %v", name, err)
 			}
+			if _, isShard := GetNodeNameOfNodeImage(&nodeImage); isShard {
+				continue
+			}
 			nodeImages = append(nodeImages, &nodeImage)
 		}
 		return nodeImages, nil
@@ -156,12 +169,30 @@ func GetNodeImagesForJob(reader client.Reader, job *appsv1alpha1.ImagePullJob) (
 func convertNodeImages(nodeImageList *appsv1alpha1.NodeImageList) []*appsv1alpha1.NodeImage {
 	nodeImages := make([]*appsv1alpha1.NodeImage, 0, len(nodeImageList.Items))
 	for i := range nodeImageList.Items {
+		// the extra shards are not related to nodes directly
+		if _, isShard := GetNodeNameOfNodeImage(&nodeImageList.Items[i]); isShard {
+			continue
+		}
 		nodeImages = append(nodeImages, &nodeImageList.Items[i])
 	}
 	return nodeImages
 }
 
 func GetActiveJobsForNodeImage(reader client.Reader, nodeImage, oldNodeImage *appsv1alpha1.NodeImage) (newJobs, oldJobs []*appsv1alpha1.ImagePullJob, err error) {
+	// the jobs of a shard are matched by the NodeImage of its node
+	if nodeName, isShard := GetNodeNameOfNodeImage(nodeImage); isShard {
+		nodeImage = &appsv1alpha1.NodeImage{}
+		if err = reader.Get(context.TODO(), types.NamespacedName{Name: nodeName}, nodeImage); err != nil {
+			if errors.IsNotFound(err) {
+				return nil, nil, nil
+			}
+			return nil, nil, err
+		}
+		if oldNodeImage != nil {
+			oldNodeImage = nodeImage
+		}
+	}
+
 	var podsOnNode []*v1.Pod
 	jobList := appsv1alpha1.ImagePullJobList{}
 	if err = reader.List(context.TODO(), &jobList, client.MatchingFields{fieldindex.IndexNameForIsActive: "true"}, utilclient.DisableDeepCopy); err != nil {
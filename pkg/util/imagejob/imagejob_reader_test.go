@@ -129,25 +129,33 @@ var (
 		{
 			ObjectMeta: metav1.ObjectMeta{Name: "job2"},
 			Spec: appsv1alpha1.ImagePullJobSpec{
-				Selector: &appsv1alpha1.ImagePullJobNodeSelector{Names: []string{"node2", "node4"}},
+				ImagePullJobTemplate: appsv1alpha1.ImagePullJobTemplate{
+					Selector: &appsv1alpha1.ImagePullJobNodeSelector{Names: []string{"node2", "node4"}},
+				},
 			},
 		},
 		{
 			ObjectMeta: metav1.ObjectMeta{Name: "job3"},
 			Spec: appsv1alpha1.ImagePullJobSpec{
-				Selector: &appsv1alpha1.ImagePullJobNodeSelector{LabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"arch": "arm64"}}},
+				ImagePullJobTemplate: appsv1alpha1.ImagePullJobTemplate{
+					Selector: &appsv1alpha1.ImagePullJobNodeSelector{LabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"arch": "arm64"}}},
+				},
 			},
 		},
 		{
 			ObjectMeta: metav1.ObjectMeta{Name: "job4"},
 			Spec: appsv1alpha1.ImagePullJobSpec{
-				Selector: &appsv1alpha1.ImagePullJobNodeSelector{LabelSelector: metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "arch", Operator: metav1.LabelSelectorOpDoesNotExist}}}},
+				ImagePullJobTemplate: appsv1alpha1.ImagePullJobTemplate{
+					Selector: &appsv1alpha1.ImagePullJobNodeSelector{LabelSelector: metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "arch", Operator: metav1.LabelSelectorOpDoesNotExist}}}},
+				},
 			},
 		},
 		{
 			ObjectMeta: metav1.ObjectMeta{Name: "job5"},
 			Spec: appsv1alpha1.ImagePullJobSpec{
-				PodSelector: &appsv1alpha1.ImagePullJobPodSelector{LabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "foo"}}},
+				ImagePullJobTemplate: appsv1alpha1.ImagePullJobTemplate{
+					PodSelector: &appsv1alpha1.ImagePullJobPodSelector{LabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "foo"}}},
+				},
 			},
 		},
 		{
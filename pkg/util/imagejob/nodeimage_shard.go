@@ -0,0 +1,107 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagejob
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	daemonutil "github.com/openkruise/kruise/pkg/daemon/util"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NodeImageShards is the number of NodeImage objects for each node. Images are assigned to the shards by the hash
+// of their names, so that a node with thousands of images will not exceed the limits of a single object.
+// The first shard is named as the node, and the others are named as <node>-shard-<index> and labeled with
+// NodeImageShardOfLabelKey. A shard is never created if its name is taken by a node, and only the NodeImages
+// labeled as shards of the node are used, for the names of shards may collide with the names of nodes.
+var NodeImageShards = 1
+
+// GetNodeImageShardName returns the name of the NodeImage shard with the index for the node.
+func GetNodeImageShardName(nodeName string, index int) string {
+	if index <= 0 {
+		return nodeName
+	}
+	return fmt.Sprintf("%s-shard-%d", nodeName, index)
+}
+
+// GetNodeImageNameForImage returns the name of the NodeImage shard that the image belongs to on the node.
+func GetNodeImageNameForImage(nodeName, imageName string) string {
+	if NodeImageShards <= 1 {
+		return nodeName
+	}
+	hasher := fnv.New32a()
+	_, _ = hasher.Write([]byte(imageName))
+	return GetNodeImageShardName(nodeName, int(hasher.Sum32()%uint32(NodeImageShards)))
+}
+
+// GetNodeNameOfNodeImage returns the node name of the NodeImage, and whether it is an extra shard of the node.
+func GetNodeNameOfNodeImage(nodeImage *appsv1alpha1.NodeImage) (string, bool) {
+	if nodeName, ok := nodeImage.Labels[appsv1alpha1.NodeImageShardOfLabelKey]; ok {
+		return nodeName, true
+	}
+	return nodeImage.Name, false
+}
+
+// IsNodeImageShardExpected returns whether the NodeImage shard with the name is expected for the node.
+func IsNodeImageShardExpected(nodeName, name string) bool {
+	for i := 0; i < NodeImageShards; i++ {
+		if GetNodeImageShardName(nodeName, i) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// getNodeImageShardsForImage replaces the NodeImages of nodes with the shards that the image belongs to.
+func getNodeImageShardsForImage(reader client.Reader, nodeImages []*appsv1alpha1.NodeImage, image string) ([]*appsv1alpha1.NodeImage, error) {
+	if NodeImageShards <= 1 {
+		return nodeImages, nil
+	}
+	imageName, _, err := daemonutil.NormalizeImageRefToNameTag(image)
+	if err != nil {
+		return nil, fmt.Errorf("invalid image %s: %v", image, err)
+	}
+	shards := make([]*appsv1alpha1.NodeImage, 0, len(nodeImages))
+	for _, nodeImage := range nodeImages {
+		name := GetNodeImageNameForImage(nodeImage.Name, imageName)
+		if name == nodeImage.Name {
+			shards = append(shards, nodeImage)
+			continue
+		}
+		shard := &appsv1alpha1.NodeImage{}
+		if err := reader.Get(context.TODO(), types.NamespacedName{Name: name}, shard); err != nil {
+			if errors.IsNotFound(err) {
+				klog.V(4).Infof("NodeImage shard %s for image %s not found, wait for it created", name, imageName)
+				continue
+			}
+			return nil, fmt.Errorf("get NodeImage shard %s error: %v", name, err)
+		}
+		// the shard name may collide with the NodeImage of another node named like it
+		if shard.Labels[appsv1alpha1.NodeImageShardOfLabelKey] != nodeImage.Name {
+			klog.Warningf("NodeImage %s is not a shard of node %s, skip it for image %s", name, nodeImage.Name, imageName)
+			continue
+		}
+		shards = append(shards, shard)
+	}
+	return shards, nil
+}
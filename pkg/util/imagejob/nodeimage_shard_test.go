@@ -0,0 +1,126 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagejob
+
+import (
+	"fmt"
+	"testing"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetNodeImageNameForImage(t *testing.T) {
+	defer func() { NodeImageShards = 1 }()
+
+	NodeImageShards = 1
+	if name := GetNodeImageNameForImage("node1", "nginx"); name != "node1" {
+		t.Fatalf("expected node1 without sharding, got %s", name)
+	}
+
+	NodeImageShards = 4
+	names := map[string]struct{}{}
+	for _, image := range []string{"nginx", "busybox", "redis", "mysql"} {
+		name := GetNodeImageNameForImage("node1", image)
+		if name != GetNodeImageNameForImage("node1", image) {
+			t.Fatalf("expected stable shard for %s", image)
+		}
+		if !IsNodeImageShardExpected("node1", name) {
+			t.Fatalf("unexpected shard %s for %s", name, image)
+		}
+		names[name] = struct{}{}
+	}
+	if len(names) < 2 {
+		t.Fatalf("expected images assigned to multiple shards, got %v", names)
+	}
+	if IsNodeImageShardExpected("node1", GetNodeImageShardName("node1", 4)) {
+		t.Fatalf("expected shard 4 not expected for 4 shards")
+	}
+}
+
+func TestGetNodeImageShardsForImage(t *testing.T) {
+	defer func() { NodeImageShards = 1 }()
+	NodeImageShards = 2
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = appsv1alpha1.AddToScheme(scheme)
+
+	image := "nginx:1.21"
+	imageName := "nginx"
+	var nodeImages []*appsv1alpha1.NodeImage
+	var objs []runtime.Object
+	expected := map[string]string{}
+	for _, nodeName := range []string{"node1", "node2"} {
+		nodeImage := &appsv1alpha1.NodeImage{ObjectMeta: metav1.ObjectMeta{Name: nodeName}}
+		shard := &appsv1alpha1.NodeImage{ObjectMeta: metav1.ObjectMeta{
+			Name:   GetNodeImageShardName(nodeName, 1),
+			Labels: map[string]string{appsv1alpha1.NodeImageShardOfLabelKey: nodeName},
+		}}
+		nodeImages = append(nodeImages, nodeImage)
+		objs = append(objs, nodeImage, shard)
+		expected[nodeName] = GetNodeImageNameForImage(nodeName, imageName)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objs...).Build()
+
+	shards, err := getNodeImageShardsForImage(c, nodeImages, image)
+	if err != nil {
+		t.Fatalf("failed to get shards: %v", err)
+	}
+	if len(shards) != 2 {
+		t.Fatalf("expected 2 shards, got %d", len(shards))
+	}
+	for _, shard := range shards {
+		nodeName, _ := GetNodeNameOfNodeImage(shard)
+		if expected[nodeName] != shard.Name {
+			t.Fatalf("expected shard %s for node %s, got %s", expected[nodeName], nodeName, shard.Name)
+		}
+	}
+}
+
+func TestGetNodeImageShardsForImageWithCollision(t *testing.T) {
+	defer func() { NodeImageShards = 1 }()
+	NodeImageShards = 2
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = appsv1alpha1.AddToScheme(scheme)
+
+	// find an image in the extra shard
+	var imageName string
+	for i := 0; ; i++ {
+		imageName = fmt.Sprintf("image-%d", i)
+		if GetNodeImageNameForImage("node1", imageName) != "node1" {
+			break
+		}
+	}
+	nodeImage := &appsv1alpha1.NodeImage{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}
+	// the NodeImage of another node named like the shard of node1
+	collided := &appsv1alpha1.NodeImage{ObjectMeta: metav1.ObjectMeta{Name: GetNodeImageShardName("node1", 1)}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(nodeImage, collided).Build()
+
+	shards, err := getNodeImageShardsForImage(c, []*appsv1alpha1.NodeImage{nodeImage}, imageName+":latest")
+	if err != nil {
+		t.Fatalf("failed to get shards: %v", err)
+	}
+	if len(shards) != 0 {
+		t.Fatalf("expected no shard for the collided NodeImage, got %v", shards[0].Name)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"strconv"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -30,6 +31,29 @@ import (
 )
 
 func CreateJobForWorkload(c client.Client, owner metav1.Object, gvk schema.GroupVersionKind, name, image string, labels map[string]string, podSelector metav1.LabelSelector, pullSecrets []string) error {
+	job := newJobForWorkload(owner, gvk, name, image, labels, pullSecrets)
+	job.Spec.PodSelector = &appsv1alpha1.ImagePullJobPodSelector{LabelSelector: podSelector}
+	return c.Create(context.TODO(), job)
+}
+
+// CreateJobForWorkloadOnNodes creates an ImagePullJob for the workload, which pulls image on the nodes matching nodeSelector
+// instead of the nodes of pods.
+func CreateJobForWorkloadOnNodes(c client.Client, owner metav1.Object, gvk schema.GroupVersionKind, name, image string, labels map[string]string, nodeSelector metav1.LabelSelector, pullSecrets []string) error {
+	job := newJobForWorkload(owner, gvk, name, image, labels, pullSecrets)
+	job.Spec.Selector = &appsv1alpha1.ImagePullJobNodeSelector{LabelSelector: nodeSelector}
+	return c.Create(context.TODO(), job)
+}
+
+// CreateJobForWorkloadOnNodeNames creates an ImagePullJob in the namespace for the workload, which pulls image on the given nodes.
+// It is used for the cluster-scoped workloads, whose pods may be in different namespaces.
+func CreateJobForWorkloadOnNodeNames(c client.Client, owner metav1.Object, gvk schema.GroupVersionKind, namespace, name, image string, labels map[string]string, nodeNames []string, pullSecrets []string) error {
+	job := newJobForWorkload(owner, gvk, name, image, labels, pullSecrets)
+	job.Namespace = namespace
+	job.Spec.Selector = &appsv1alpha1.ImagePullJobNodeSelector{Names: nodeNames}
+	return c.Create(context.TODO(), job)
+}
+
+func newJobForWorkload(owner metav1.Object, gvk schema.GroupVersionKind, name, image string, labels map[string]string, pullSecrets []string) *appsv1alpha1.ImagePullJob {
 	var pullTimeoutSeconds int32 = 300
 	if str, ok := owner.GetAnnotations()[appsv1alpha1.ImagePreDownloadTimeoutSecondsKey]; ok {
 		if i, err := strconv.Atoi(str); err == nil {
@@ -44,7 +68,7 @@ func CreateJobForWorkload(c client.Client, owner metav1.Object, gvk schema.Group
 		}
 	}
 
-	job := &appsv1alpha1.ImagePullJob{
+	return &appsv1alpha1.ImagePullJob{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace:       owner.GetNamespace(),
 			Name:            name,
@@ -52,19 +76,57 @@ func CreateJobForWorkload(c client.Client, owner metav1.Object, gvk schema.Group
 			Labels:          labels,
 		},
 		Spec: appsv1alpha1.ImagePullJobSpec{
-			Image:       image,
-			PullSecrets: pullSecrets,
-			PodSelector: &appsv1alpha1.ImagePullJobPodSelector{LabelSelector: podSelector},
-			Parallelism: &parallelism,
-			PullPolicy:  &appsv1alpha1.PullPolicy{BackoffLimit: utilpointer.Int32Ptr(1), TimeoutSeconds: &pullTimeoutSeconds},
-			CompletionPolicy: appsv1alpha1.CompletionPolicy{
-				Type:                    appsv1alpha1.Always,
-				TTLSecondsAfterFinished: utilpointer.Int32Ptr(600),
+			Image: image,
+			ImagePullJobTemplate: appsv1alpha1.ImagePullJobTemplate{
+				PullSecrets: pullSecrets,
+				Parallelism: &parallelism,
+				PullPolicy:  &appsv1alpha1.PullPolicy{BackoffLimit: utilpointer.Int32Ptr(1), TimeoutSeconds: &pullTimeoutSeconds},
+				CompletionPolicy: appsv1alpha1.CompletionPolicy{
+					Type:                    appsv1alpha1.Always,
+					TTLSecondsAfterFinished: utilpointer.Int32Ptr(600),
+				},
 			},
 		},
 	}
+}
 
-	return c.Create(context.TODO(), job)
+// GetNodeSelectorFromPodSpec converts the nodeSelector and required node affinity of pod spec into a label selector over nodes.
+// It returns nil if there is no scheduling hint, or the hints can not be expressed by a label selector,
+// such as multiple nodeSelectorTerms, matchFields or Gt/Lt operators.
+func GetNodeSelectorFromPodSpec(spec *v1.PodSpec) *metav1.LabelSelector {
+	selector := &metav1.LabelSelector{}
+	for k, v := range spec.NodeSelector {
+		if selector.MatchLabels == nil {
+			selector.MatchLabels = make(map[string]string)
+		}
+		selector.MatchLabels[k] = v
+	}
+
+	if spec.Affinity != nil && spec.Affinity.NodeAffinity != nil && spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+		terms := spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+		if len(terms) > 1 || (len(terms) == 1 && len(terms[0].MatchFields) > 0) {
+			return nil
+		}
+		for _, term := range terms {
+			for _, req := range term.MatchExpressions {
+				switch req.Operator {
+				case v1.NodeSelectorOpIn, v1.NodeSelectorOpNotIn, v1.NodeSelectorOpExists, v1.NodeSelectorOpDoesNotExist:
+				default:
+					return nil
+				}
+				selector.MatchExpressions = append(selector.MatchExpressions, metav1.LabelSelectorRequirement{
+					Key:      req.Key,
+					Operator: metav1.LabelSelectorOperator(req.Operator),
+					Values:   req.Values,
+				})
+			}
+		}
+	}
+
+	if len(selector.MatchLabels) == 0 && len(selector.MatchExpressions) == 0 {
+		return nil
+	}
+	return selector
 }
 
 func DeleteJobsForWorkload(c client.Client, ownerObj metav1.Object) error {
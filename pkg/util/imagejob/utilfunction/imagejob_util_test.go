@@ -0,0 +1,84 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utilfunction
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetNodeSelectorFromPodSpec(t *testing.T) {
+	requiredAffinity := func(terms ...v1.NodeSelectorTerm) *v1.Affinity {
+		return &v1.Affinity{NodeAffinity: &v1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{NodeSelectorTerms: terms},
+		}}
+	}
+
+	cases := []struct {
+		name     string
+		spec     v1.PodSpec
+		expected *metav1.LabelSelector
+	}{
+		{
+			name: "no scheduling hints",
+		},
+		{
+			name: "node selector and affinity",
+			spec: v1.PodSpec{
+				NodeSelector: map[string]string{"pool": "web"},
+				Affinity: requiredAffinity(v1.NodeSelectorTerm{MatchExpressions: []v1.NodeSelectorRequirement{
+					{Key: "zone", Operator: v1.NodeSelectorOpIn, Values: []string{"a", "b"}},
+				}}),
+			},
+			expected: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"pool": "web"},
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "zone", Operator: metav1.LabelSelectorOpIn, Values: []string{"a", "b"}},
+				},
+			},
+		},
+		{
+			name: "multiple terms",
+			spec: v1.PodSpec{
+				NodeSelector: map[string]string{"pool": "web"},
+				Affinity: requiredAffinity(
+					v1.NodeSelectorTerm{MatchExpressions: []v1.NodeSelectorRequirement{{Key: "zone", Operator: v1.NodeSelectorOpExists}}},
+					v1.NodeSelectorTerm{MatchExpressions: []v1.NodeSelectorRequirement{{Key: "gpu", Operator: v1.NodeSelectorOpExists}}},
+				),
+			},
+		},
+		{
+			name: "unsupported operator",
+			spec: v1.PodSpec{
+				Affinity: requiredAffinity(v1.NodeSelectorTerm{MatchExpressions: []v1.NodeSelectorRequirement{
+					{Key: "cpu", Operator: v1.NodeSelectorOpGt, Values: []string{"4"}},
+				}}),
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := GetNodeSelectorFromPodSpec(&tc.spec); !reflect.DeepEqual(got, tc.expected) {
+				t.Fatalf("expected %+v, got %+v", tc.expected, got)
+			}
+		})
+	}
+}
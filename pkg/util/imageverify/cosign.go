@@ -0,0 +1,299 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imageverify
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+)
+
+const (
+	cosignSignatureAnnotation   = "dev.cosignproject.cosign/signature"
+	cosignCertificateAnnotation = "dev.sigstore.cosign/certificate"
+	cosignChainAnnotation       = "dev.sigstore.cosign/chain"
+	cosignBundleAnnotation      = "dev.sigstore.cosign/bundle"
+)
+
+var (
+	// oidFulcioIssuer is the extension of the OIDC issuer in Fulcio certificates, whose value is the raw string.
+	oidFulcioIssuer = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 1}
+	// oidFulcioIssuerV2 is the extension of the OIDC issuer in Fulcio certificates, whose value is DER encoded.
+	oidFulcioIssuerV2 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8}
+)
+
+// cosignVerifier verifies the signatures that cosign stores as the layers of the tag sha256-<digest>.sig.
+type cosignVerifier struct {
+	publicKey crypto.PublicKey
+
+	roots          *x509.CertPool
+	rekorPublicKey crypto.PublicKey
+	rekorLogID     string
+	issuer         string
+	subject        string
+}
+
+func newCosignVerifier(spec *appsv1alpha1.ImageVerification) (*cosignVerifier, error) {
+	if len(spec.TrustedCertificates) > 0 {
+		return nil, fmt.Errorf("trustedCertificates can only work with Notation signatures")
+	}
+	if (len(spec.PublicKey) > 0) == (spec.Keyless != nil) {
+		return nil, fmt.Errorf("either publicKey or keyless should be specified for Cosign signatures")
+	}
+
+	v := &cosignVerifier{}
+	var err error
+	if len(spec.PublicKey) > 0 {
+		if v.publicKey, err = parsePublicKey(spec.PublicKey); err != nil {
+			return nil, fmt.Errorf("invalid publicKey: %v", err)
+		}
+		return v, nil
+	}
+
+	keyless := spec.Keyless
+	if len(keyless.Issuer) == 0 || len(keyless.Subject) == 0 {
+		return nil, fmt.Errorf("issuer and subject of keyless can not be empty")
+	}
+	if v.roots, err = newCertPool(keyless.Roots); err != nil {
+		return nil, fmt.Errorf("invalid roots of keyless: %v", err)
+	}
+	if v.rekorPublicKey, err = parsePublicKey(keyless.RekorPublicKey); err != nil {
+		return nil, fmt.Errorf("invalid rekorPublicKey of keyless: %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(v.rekorPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rekorPublicKey of keyless: %v", err)
+	}
+	logID := sha256.Sum256(der)
+	v.rekorLogID = hex.EncodeToString(logID[:])
+	v.issuer = keyless.Issuer
+	v.subject = keyless.Subject
+	return v, nil
+}
+
+func (v *cosignVerifier) verify(ctx context.Context, c *registryClient, dgst digest.Digest) error {
+	sigTag := fmt.Sprintf("%s-%s.sig", dgst.Algorithm(), dgst.Encoded())
+	m, _, err := c.getManifest(ctx, sigTag)
+	if err == errNotFound {
+		return newVerificationError("no cosign signatures found for %s", dgst)
+	} else if err != nil {
+		return fmt.Errorf("failed to get cosign signatures %s: %v", sigTag, err)
+	}
+
+	var errs []error
+	for _, layer := range m.Layers {
+		signature, ok := layer.Annotations[cosignSignatureAnnotation]
+		if !ok {
+			continue
+		}
+		payload, err := c.getBlob(ctx, layer)
+		if err != nil {
+			return fmt.Errorf("failed to get cosign payload %s: %v", layer.Digest, err)
+		}
+		if err := v.verifySignature(dgst, payload, signature, layer.Annotations); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		return nil
+	}
+	if len(errs) == 0 {
+		return newVerificationError("no cosign signatures found for %s", dgst)
+	}
+	return newVerificationError("no valid cosign signatures for %s: %s", dgst, joinErrors(errs))
+}
+
+func (v *cosignVerifier) verifySignature(dgst digest.Digest, payload []byte, signature string, annotations map[string]string) error {
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %v", err)
+	}
+
+	publicKey := v.publicKey
+	if publicKey == nil {
+		cert, err := v.verifyCertificate(payload, sig, annotations)
+		if err != nil {
+			return err
+		}
+		publicKey = cert.PublicKey
+	}
+	if err := verifyWithPublicKey(publicKey, payload, sig); err != nil {
+		return err
+	}
+
+	simpleSigning := struct {
+		Critical struct {
+			Image struct {
+				DockerManifestDigest string `json:"docker-manifest-digest"`
+			} `json:"image"`
+		} `json:"critical"`
+	}{}
+	if err := json.Unmarshal(payload, &simpleSigning); err != nil {
+		return fmt.Errorf("invalid payload: %v", err)
+	}
+	if signed := simpleSigning.Critical.Image.DockerManifestDigest; signed != dgst.String() {
+		return fmt.Errorf("signed digest %s mismatched", signed)
+	}
+	return nil
+}
+
+// verifyCertificate verifies the signing certificate of keyless signature, which should be issued by the roots to the
+// expected identity, and be valid at the time recorded by Rekor.
+func (v *cosignVerifier) verifyCertificate(payload, sig []byte, annotations map[string]string) (*x509.Certificate, error) {
+	certs, err := parseCertificates(annotations[cosignCertificateAnnotation])
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate: %v", err)
+	}
+	cert := certs[0]
+	intermediates := x509.NewCertPool()
+	if chain, ok := annotations[cosignChainAnnotation]; ok {
+		chainCerts, err := parseCertificates(chain)
+		if err != nil {
+			return nil, fmt.Errorf("invalid certificate chain: %v", err)
+		}
+		for _, c := range chainCerts {
+			intermediates.AddCert(c)
+		}
+	}
+
+	integratedTime, err := v.verifyBundle(payload, sig, cert, annotations[cosignBundleAnnotation])
+	if err != nil {
+		return nil, err
+	}
+	if _, err := cert.Verify(x509.VerifyOptions{
+		Roots:         v.roots,
+		Intermediates: intermediates,
+		CurrentTime:   integratedTime,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}); err != nil {
+		return nil, fmt.Errorf("untrusted certificate: %v", err)
+	}
+
+	if issuer := getCertificateIssuer(cert); issuer != v.issuer {
+		return nil, fmt.Errorf("issuer %q of certificate mismatched", issuer)
+	}
+	var subjects []string
+	subjects = append(subjects, cert.EmailAddresses...)
+	for _, uri := range cert.URIs {
+		subjects = append(subjects, uri.String())
+	}
+	for _, subject := range subjects {
+		if subject == v.subject {
+			return cert, nil
+		}
+	}
+	return nil, fmt.Errorf("subjects %v of certificate mismatched", subjects)
+}
+
+// rekorPayload is the entry in Rekor log, whose fields are sorted for the canonical JSON signed by Rekor.
+type rekorPayload struct {
+	Body           string `json:"body"`
+	IntegratedTime int64  `json:"integratedTime"`
+	LogID          string `json:"logID"`
+	LogIndex       int64  `json:"logIndex"`
+}
+
+type hashedRekord struct {
+	Kind string `json:"kind"`
+	Spec struct {
+		Data struct {
+			Hash struct {
+				Algorithm string `json:"algorithm"`
+				Value     string `json:"value"`
+			} `json:"hash"`
+		} `json:"data"`
+		Signature struct {
+			Content   []byte `json:"content"`
+			PublicKey struct {
+				Content []byte `json:"content"`
+			} `json:"publicKey"`
+		} `json:"signature"`
+	} `json:"spec"`
+}
+
+// verifyBundle verifies the signed entry timestamp of Rekor in the bundle, and returns the time that the signature
+// was recorded in the transparency log.
+func (v *cosignVerifier) verifyBundle(payload, sig []byte, cert *x509.Certificate, data string) (time.Time, error) {
+	if len(data) == 0 {
+		return time.Time{}, fmt.Errorf("no Rekor bundle found for keyless signature")
+	}
+	bundle := struct {
+		SignedEntryTimestamp []byte       `json:"SignedEntryTimestamp"`
+		Payload              rekorPayload `json:"Payload"`
+	}{}
+	if err := json.Unmarshal([]byte(data), &bundle); err != nil {
+		return time.Time{}, fmt.Errorf("invalid Rekor bundle: %v", err)
+	}
+	if bundle.Payload.LogID != v.rekorLogID {
+		return time.Time{}, fmt.Errorf("logID %s of Rekor bundle mismatched", bundle.Payload.LogID)
+	}
+	canonical, err := json.Marshal(bundle.Payload)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if err := verifyWithPublicKey(v.rekorPublicKey, canonical, bundle.SignedEntryTimestamp); err != nil {
+		return time.Time{}, fmt.Errorf("invalid signed entry timestamp of Rekor bundle: %v", err)
+	}
+
+	body, err := base64.StdEncoding.DecodeString(bundle.Payload.Body)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid body of Rekor bundle: %v", err)
+	}
+	entry := hashedRekord{}
+	if err := json.Unmarshal(body, &entry); err != nil {
+		return time.Time{}, fmt.Errorf("invalid body of Rekor bundle: %v", err)
+	}
+	hashed := sha256.Sum256(payload)
+	if entry.Kind != "hashedrekord" || entry.Spec.Data.Hash.Algorithm != "sha256" || entry.Spec.Data.Hash.Value != hex.EncodeToString(hashed[:]) {
+		return time.Time{}, fmt.Errorf("payload mismatched with Rekor entry")
+	}
+	if !bytes.Equal(entry.Spec.Signature.Content, sig) {
+		return time.Time{}, fmt.Errorf("signature mismatched with Rekor entry")
+	}
+	entryCerts, err := parseCertificates(string(entry.Spec.Signature.PublicKey.Content))
+	if err != nil || !entryCerts[0].Equal(cert) {
+		return time.Time{}, fmt.Errorf("certificate mismatched with Rekor entry")
+	}
+	return time.Unix(bundle.Payload.IntegratedTime, 0), nil
+}
+
+func getCertificateIssuer(cert *x509.Certificate) string {
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(oidFulcioIssuerV2) {
+			var issuer string
+			if _, err := asn1.Unmarshal(ext.Value, &issuer); err == nil {
+				return issuer
+			}
+		}
+	}
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(oidFulcioIssuer) {
+			return string(ext.Value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,231 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imageverify
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+)
+
+const (
+	notationArtifactType       = "application/vnd.cncf.notary.signature"
+	notationMediaTypeJWS       = "application/jose+json"
+	notationPayloadContentType = "application/vnd.cncf.notary.payload.v1+json"
+	notationSigningSchemeX509  = "notary.x509"
+
+	notationSigningSchemeHeader = "io.cncf.notary.signingScheme"
+	notationExpiryHeader        = "io.cncf.notary.expiry"
+)
+
+// notationVerifier verifies the JWS signatures that notation stores as the referrers of images.
+// Only the notary.x509 signing scheme is supported, whose certificates are verified at the current time.
+type notationVerifier struct {
+	roots *x509.CertPool
+	now   func() time.Time
+}
+
+func newNotationVerifier(spec *appsv1alpha1.ImageVerification) (*notationVerifier, error) {
+	if len(spec.PublicKey) > 0 || spec.Keyless != nil {
+		return nil, fmt.Errorf("publicKey and keyless can only work with Cosign signatures")
+	}
+	roots, err := newCertPool(spec.TrustedCertificates)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trustedCertificates: %v", err)
+	}
+	return &notationVerifier{roots: roots, now: time.Now}, nil
+}
+
+func (v *notationVerifier) verify(ctx context.Context, c *registryClient, dgst digest.Digest) error {
+	referrers, err := c.getReferrers(ctx, dgst, notationArtifactType)
+	if err != nil {
+		return fmt.Errorf("failed to get notation signatures: %v", err)
+	}
+	if len(referrers) == 0 {
+		return newVerificationError("no notation signatures found for %s", dgst)
+	}
+
+	var errs []error
+	for _, referrer := range referrers {
+		m, _, err := c.getManifest(ctx, referrer.Digest.String())
+		if err != nil {
+			return fmt.Errorf("failed to get notation signature %s: %v", referrer.Digest, err)
+		}
+		if len(m.Layers) != 1 || m.Layers[0].MediaType != notationMediaTypeJWS {
+			errs = append(errs, fmt.Errorf("unsupported notation signature %s", referrer.Digest))
+			continue
+		}
+		envelope, err := c.getBlob(ctx, m.Layers[0])
+		if err != nil {
+			return fmt.Errorf("failed to get notation signature %s: %v", referrer.Digest, err)
+		}
+		if err := v.verifyEnvelope(dgst, envelope); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		return nil
+	}
+	return newVerificationError("no valid notation signatures for %s: %s", dgst, joinErrors(errs))
+}
+
+// verifyEnvelope verifies the JWS envelope in the flattened JSON serialization, whose certificate chain is
+// in the unprotected x5c header.
+func (v *notationVerifier) verifyEnvelope(dgst digest.Digest, data []byte) error {
+	envelope := struct {
+		Payload   string `json:"payload"`
+		Protected string `json:"protected"`
+		Header    struct {
+			X5c [][]byte `json:"x5c"`
+		} `json:"header"`
+		Signature string `json:"signature"`
+	}{}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return fmt.Errorf("invalid JWS envelope: %v", err)
+	}
+
+	protectedBytes, err := base64.RawURLEncoding.DecodeString(envelope.Protected)
+	if err != nil {
+		return fmt.Errorf("invalid protected header: %v", err)
+	}
+	protected := map[string]interface{}{}
+	if err := json.Unmarshal(protectedBytes, &protected); err != nil {
+		return fmt.Errorf("invalid protected header: %v", err)
+	}
+	if cty, _ := protected["cty"].(string); cty != notationPayloadContentType {
+		return fmt.Errorf("unsupported content type %q", cty)
+	}
+	if scheme, _ := protected[notationSigningSchemeHeader].(string); scheme != notationSigningSchemeX509 {
+		return fmt.Errorf("unsupported signing scheme %q", scheme)
+	}
+	crit, _ := protected["crit"].([]interface{})
+	for _, c := range crit {
+		if c != notationSigningSchemeHeader && c != notationExpiryHeader {
+			return fmt.Errorf("unsupported critical header %v", c)
+		}
+	}
+	now := v.now()
+	if expiry, ok := protected[notationExpiryHeader].(string); ok {
+		expiryTime, err := time.Parse(time.RFC3339, expiry)
+		if err != nil {
+			return fmt.Errorf("invalid expiry %q: %v", expiry, err)
+		}
+		if now.After(expiryTime) {
+			return fmt.Errorf("signature expired at %s", expiry)
+		}
+	}
+
+	if len(envelope.Header.X5c) == 0 {
+		return fmt.Errorf("no certificate chain in JWS envelope")
+	}
+	var certs []*x509.Certificate
+	for _, der := range envelope.Header.X5c {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return fmt.Errorf("invalid certificate chain: %v", err)
+		}
+		certs = append(certs, cert)
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	if _, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         v.roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}); err != nil {
+		return fmt.Errorf("untrusted certificate: %v", err)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(envelope.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %v", err)
+	}
+	alg, _ := protected["alg"].(string)
+	if err := verifyJWS(alg, certs[0].PublicKey, []byte(envelope.Protected+"."+envelope.Payload), sig); err != nil {
+		return err
+	}
+
+	payloadBytes, err := base64.RawURLEncoding.DecodeString(envelope.Payload)
+	if err != nil {
+		return fmt.Errorf("invalid payload: %v", err)
+	}
+	payload := struct {
+		TargetArtifact struct {
+			Digest digest.Digest `json:"digest"`
+		} `json:"targetArtifact"`
+	}{}
+	if err := json.Unmarshal(payloadBytes, &payload); err != nil {
+		return fmt.Errorf("invalid payload: %v", err)
+	}
+	if signed := payload.TargetArtifact.Digest; signed != dgst {
+		return fmt.Errorf("signed digest %s mismatched", signed)
+	}
+	return nil
+}
+
+// verifyJWS verifies the signature of the JWS signing input with the algorithms allowed by notation.
+func verifyJWS(alg string, publicKey crypto.PublicKey, signingInput, sig []byte) error {
+	var hash crypto.Hash
+	switch alg {
+	case "PS256", "ES256":
+		hash = crypto.SHA256
+	case "PS384", "ES384":
+		hash = crypto.SHA384
+	case "PS512", "ES512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+	hasher := hash.New()
+	hasher.Write(signingInput)
+	hashed := hasher.Sum(nil)
+
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		if alg[0] != 'P' {
+			return fmt.Errorf("algorithm %s mismatched with RSA key", alg)
+		}
+		if err := rsa.VerifyPSS(key, hash, hashed, sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}); err != nil {
+			return fmt.Errorf("invalid RSA signature: %v", err)
+		}
+	case *ecdsa.PublicKey:
+		keyBytes := (key.Curve.Params().BitSize + 7) / 8
+		if alg[0] != 'E' || len(sig) != 2*keyBytes {
+			return fmt.Errorf("algorithm %s mismatched with ECDSA key", alg)
+		}
+		r := new(big.Int).SetBytes(sig[:keyBytes])
+		s := new(big.Int).SetBytes(sig[keyBytes:])
+		if !ecdsa.Verify(key, hashed, r, s) {
+			return fmt.Errorf("invalid ECDSA signature")
+		}
+	default:
+		return fmt.Errorf("unsupported type of public key %T", publicKey)
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imageverify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/containerd/containerd/remotes/docker"
+	"github.com/docker/distribution/reference"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/credentialprovider"
+	credentialprovidersecrets "k8s.io/kubernetes/pkg/credentialprovider/secrets"
+)
+
+const (
+	// maxFetchBytes limits the size of manifests and signature blobs fetched from registries
+	maxFetchBytes = 4 << 20
+
+	mediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	mediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+)
+
+var manifestMediaTypes = []string{
+	ocispec.MediaTypeImageManifest,
+	ocispec.MediaTypeImageIndex,
+	mediaTypeDockerManifest,
+	mediaTypeDockerManifestList,
+}
+
+// errNotFound means the manifest or blob does not exist in the registry.
+var errNotFound = fmt.Errorf("not found")
+
+// manifest contains the fields of image manifests and indexes used to find signatures.
+type manifest struct {
+	MediaType    string               `json:"mediaType,omitempty"`
+	ArtifactType string               `json:"artifactType,omitempty"`
+	Config       ocispec.Descriptor   `json:"config,omitempty"`
+	Layers       []ocispec.Descriptor `json:"layers,omitempty"`
+	Manifests    []descriptor         `json:"manifests,omitempty"`
+}
+
+// descriptor is the descriptor in indexes with the artifactType, which is missing in image-spec v1.0.
+type descriptor struct {
+	ocispec.Descriptor
+	ArtifactType string `json:"artifactType,omitempty"`
+}
+
+// registryClient reads manifests and blobs of a repository from the registry.
+type registryClient struct {
+	client     *http.Client
+	authorizer docker.Authorizer
+	scheme     string
+	host       string
+	repository string
+}
+
+// httpClient is used to access registries, which can be replaced in tests.
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+func newRegistryClient(named reference.Named, pullSecrets []v1.Secret) (*registryClient, error) {
+	keyring, err := credentialprovidersecrets.MakeDockerKeyring(pullSecrets, credentialprovider.NewDockerKeyring())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pull secrets: %v", err)
+	}
+	var username, password string
+	if creds, ok := keyring.Lookup(named.Name()); ok && len(creds) > 0 {
+		username, password = creds[0].Username, creds[0].Password
+	}
+
+	host := reference.Domain(named)
+	if host == "docker.io" {
+		host = "registry-1.docker.io"
+	}
+	scheme := "https"
+	if isLocalhost, _ := docker.MatchLocalhost(host); isLocalhost {
+		scheme = "http"
+	}
+	return &registryClient{
+		client: httpClient,
+		authorizer: docker.NewDockerAuthorizer(
+			docker.WithAuthClient(httpClient),
+			docker.WithAuthCreds(func(string) (string, string, error) {
+				return username, password, nil
+			}),
+		),
+		scheme:     scheme,
+		host:       host,
+		repository: reference.Path(named),
+	}, nil
+}
+
+// resolve returns the digest of the manifest referenced by the tag or digest.
+func (c *registryClient) resolve(ctx context.Context, ref string) (digest.Digest, error) {
+	if dgst, err := digest.Parse(ref); err == nil {
+		return dgst, nil
+	}
+	_, dgst, err := c.getManifest(ctx, ref)
+	return dgst, err
+}
+
+// getManifest returns the manifest referenced by the tag or digest, and its digest.
+func (c *registryClient) getManifest(ctx context.Context, ref string) (*manifest, digest.Digest, error) {
+	body, err := c.get(ctx, fmt.Sprintf("manifests/%s", ref), manifestMediaTypes)
+	if err != nil {
+		return nil, "", err
+	}
+	dgst := digest.FromBytes(body)
+	if expected, err := digest.Parse(ref); err == nil && expected != dgst {
+		return nil, "", fmt.Errorf("digest of manifest %s mismatched, got %s", expected, dgst)
+	}
+	m := &manifest{}
+	if err := json.Unmarshal(body, m); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal manifest %s: %v", ref, err)
+	}
+	return m, dgst, nil
+}
+
+// getBlob returns the content of the blob, which is checked against the descriptor.
+func (c *registryClient) getBlob(ctx context.Context, desc ocispec.Descriptor) ([]byte, error) {
+	if desc.Size > maxFetchBytes {
+		return nil, fmt.Errorf("blob %s is too large: %d", desc.Digest, desc.Size)
+	}
+	body, err := c.get(ctx, fmt.Sprintf("blobs/%s", desc.Digest), nil)
+	if err != nil {
+		return nil, err
+	}
+	if dgst := digest.FromBytes(body); dgst != desc.Digest {
+		return nil, fmt.Errorf("digest of blob %s mismatched, got %s", desc.Digest, dgst)
+	}
+	return body, nil
+}
+
+// getReferrers returns the descriptors of the artifacts with the type referring to the manifest. It falls back
+// to the index tagged with sha256-<digest> if the registry does not support the referrers API.
+func (c *registryClient) getReferrers(ctx context.Context, dgst digest.Digest, artifactType string) ([]descriptor, error) {
+	body, err := c.get(ctx, fmt.Sprintf("referrers/%s?artifactType=%s", dgst, artifactType), []string{ocispec.MediaTypeImageIndex})
+	var index *manifest
+	if err == nil {
+		index = &manifest{}
+		if err = json.Unmarshal(body, index); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal referrers of %s: %v", dgst, err)
+		}
+	} else if err == errNotFound {
+		index, _, err = c.getManifest(ctx, fmt.Sprintf("%s-%s", dgst.Algorithm(), dgst.Encoded()))
+		if err == errNotFound {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+	} else {
+		return nil, err
+	}
+
+	var referrers []descriptor
+	for _, desc := range index.Manifests {
+		if desc.ArtifactType == artifactType {
+			referrers = append(referrers, desc)
+		}
+	}
+	return referrers, nil
+}
+
+func (c *registryClient) get(ctx context.Context, path string, accepts []string) ([]byte, error) {
+	ctx = docker.WithScope(ctx, fmt.Sprintf("repository:%s:pull", c.repository))
+	url := fmt.Sprintf("%s://%s/v2/%s/%s", c.scheme, c.host, c.repository, path)
+
+	var responses []*http.Response
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(accepts) > 0 {
+			req.Header.Set("Accept", strings.Join(accepts, ", "))
+		}
+		if err := c.authorizer.Authorize(ctx, req); err != nil {
+			return nil, fmt.Errorf("failed to authorize: %v", err)
+		}
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusUnauthorized && len(responses) == 0 {
+			// retry once with the credentials for the challenge
+			_ = resp.Body.Close()
+			responses = append(responses, resp)
+			if err := c.authorizer.AddResponses(ctx, responses); err != nil {
+				return nil, fmt.Errorf("failed to authorize for %s: %v", url, err)
+			}
+			continue
+		}
+		defer resp.Body.Close()
+
+		switch {
+		case resp.StatusCode == http.StatusNotFound:
+			return nil, errNotFound
+		case resp.StatusCode != http.StatusOK:
+			return nil, fmt.Errorf("unexpected status %s for %s", resp.Status, url)
+		}
+		body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxFetchBytes+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(body) > maxFetchBytes {
+			return nil, fmt.Errorf("response of %s is too large", url)
+		}
+		return body, nil
+	}
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imageverify
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	daemonutil "github.com/openkruise/kruise/pkg/daemon/util"
+	v1 "k8s.io/api/core/v1"
+)
+
+// VerificationError means the image has no valid signature, which will not be fixed by retrying.
+type VerificationError struct {
+	msg string
+}
+
+func (e *VerificationError) Error() string {
+	return e.msg
+}
+
+func newVerificationError(format string, args ...interface{}) error {
+	return &VerificationError{msg: fmt.Sprintf(format, args...)}
+}
+
+// IsVerificationError returns whether the error means the image has no valid signature.
+func IsVerificationError(err error) bool {
+	_, ok := err.(*VerificationError)
+	return ok
+}
+
+type verifier interface {
+	// verify returns nil if any signature of the manifest with the digest is valid.
+	verify(ctx context.Context, c *registryClient, dgst digest.Digest) error
+}
+
+func newVerifier(spec *appsv1alpha1.ImageVerification) (verifier, error) {
+	switch spec.Type {
+	case appsv1alpha1.ImageSignatureCosign:
+		return newCosignVerifier(spec)
+	case appsv1alpha1.ImageSignatureNotation:
+		return newNotationVerifier(spec)
+	default:
+		return nil, fmt.Errorf("unknown type of signatures: %s", spec.Type)
+	}
+}
+
+// Validate returns error if the verification is invalid.
+func Validate(spec *appsv1alpha1.ImageVerification) error {
+	_, err := newVerifier(spec)
+	return err
+}
+
+// Verify resolves the image from its registry and verifies its signatures, then returns the digest verified.
+// It returns VerificationError if no valid signature found, and other errors if failed to access the registry.
+func Verify(ctx context.Context, image string, spec *appsv1alpha1.ImageVerification, pullSecrets []v1.Secret) (digest.Digest, error) {
+	v, err := newVerifier(spec)
+	if err != nil {
+		return "", newVerificationError("invalid verification: %v", err)
+	}
+	named, err := daemonutil.NormalizeImageRef(image)
+	if err != nil {
+		return "", newVerificationError("invalid image %s: %v", image, err)
+	}
+	_, ref, _ := daemonutil.NormalizeImageRefToNameTag(image)
+
+	c, err := newRegistryClient(named, pullSecrets)
+	if err != nil {
+		return "", err
+	}
+	dgst, err := c.resolve(ctx, ref)
+	if err == errNotFound {
+		return "", newVerificationError("image %s not found", image)
+	} else if err != nil {
+		return "", fmt.Errorf("failed to resolve image %s: %v", image, err)
+	}
+	if err := v.verify(ctx, c, dgst); err != nil {
+		return "", err
+	}
+	return dgst, nil
+}
+
+func parsePublicKey(data string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found in public key")
+	}
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
+
+func parseCertificates(data string) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	rest := []byte(data)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no PEM certificate found")
+	}
+	return certs, nil
+}
+
+func newCertPool(data string) (*x509.CertPool, error) {
+	certs, err := parseCertificates(data)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	for _, cert := range certs {
+		pool.AddCert(cert)
+	}
+	return pool, nil
+}
+
+// verifyWithPublicKey verifies the signature of data with the key in the way of cosign and Rekor,
+// which signs the SHA256 digest of data with ECDSA and RSA PKCS1v15.
+func verifyWithPublicKey(publicKey crypto.PublicKey, data, signature []byte) error {
+	hashed := sha256.Sum256(data)
+	switch key := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(key, hashed[:], signature) {
+			return fmt.Errorf("invalid ECDSA signature")
+		}
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, hashed[:], signature); err != nil {
+			return fmt.Errorf("invalid RSA signature: %v", err)
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, data, signature) {
+			return fmt.Errorf("invalid ED25519 signature")
+		}
+	default:
+		return fmt.Errorf("unsupported type of public key %T", publicKey)
+	}
+	return nil
+}
+
+func joinErrors(errs []error) string {
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
@@ -0,0 +1,331 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imageverify
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+)
+
+// fakeRegistry serves the manifests and blobs of the repository "test".
+type fakeRegistry struct {
+	*httptest.Server
+	contents map[string][]byte
+}
+
+func newFakeRegistry() *fakeRegistry {
+	r := &fakeRegistry{contents: map[string][]byte{}}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		content, ok := r.contents[strings.TrimPrefix(req.URL.Path, "/v2/test/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(content)
+	}))
+	return r
+}
+
+func (r *fakeRegistry) image(ref string) string {
+	u, _ := url.Parse(r.URL)
+	if _, err := digest.Parse(ref); err == nil {
+		return fmt.Sprintf("%s/test@%s", u.Host, ref)
+	}
+	return fmt.Sprintf("%s/test:%s", u.Host, ref)
+}
+
+func (r *fakeRegistry) putManifest(ref string, m interface{}) ocispec.Descriptor {
+	data, _ := json.Marshal(m)
+	dgst := digest.FromBytes(data)
+	r.contents["manifests/"+ref] = data
+	r.contents["manifests/"+dgst.String()] = data
+	return ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Digest: dgst, Size: int64(len(data))}
+}
+
+func (r *fakeRegistry) putBlob(mediaType string, data []byte, annotations map[string]string) ocispec.Descriptor {
+	dgst := digest.FromBytes(data)
+	r.contents["blobs/"+dgst.String()] = data
+	return ocispec.Descriptor{MediaType: mediaType, Digest: dgst, Size: int64(len(data)), Annotations: annotations}
+}
+
+func (r *fakeRegistry) putImage(tag string) digest.Digest {
+	config := r.putBlob(ocispec.MediaTypeImageConfig, []byte(fmt.Sprintf(`{"tag":"%s"}`, tag)), nil)
+	return r.putManifest(tag, &manifest{MediaType: ocispec.MediaTypeImageManifest, Config: config}).Digest
+}
+
+func encodePublicKey(key crypto.PublicKey) string {
+	der, _ := x509.MarshalPKIXPublicKey(key)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func encodeCertificate(cert *x509.Certificate) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+}
+
+func signECDSA(t *testing.T, key *ecdsa.PrivateKey, data []byte) []byte {
+	hashed := sha256.Sum256(data)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, hashed[:])
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	return sig
+}
+
+func newCertificate(t *testing.T, template, parent *x509.Certificate, publicKey crypto.PublicKey, parentKey crypto.Signer) *x509.Certificate {
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	if parent == nil {
+		parent = template
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, publicKey, parentKey)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return cert
+}
+
+func newCA(t *testing.T) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	cert := newCertificate(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil, key.Public(), key)
+	return cert, key
+}
+
+func cosignPayload(dgst digest.Digest) []byte {
+	return []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":"test"},"image":{"docker-manifest-digest":"%s"},"type":"cosign container image signature"},"optional":null}`, dgst))
+}
+
+func expectVerified(t *testing.T, image string, spec *appsv1alpha1.ImageVerification, expected digest.Digest) {
+	dgst, err := Verify(context.TODO(), image, spec, nil)
+	if err != nil {
+		t.Fatalf("failed to verify %s: %v", image, err)
+	}
+	if dgst != expected {
+		t.Fatalf("expected digest %s, got %s", expected, dgst)
+	}
+}
+
+func expectVerificationError(t *testing.T, image string, spec *appsv1alpha1.ImageVerification) {
+	if _, err := Verify(context.TODO(), image, spec, nil); !IsVerificationError(err) {
+		t.Fatalf("expected verification error for %s, got %v", image, err)
+	}
+}
+
+func TestVerifyCosignWithPublicKey(t *testing.T) {
+	registry := newFakeRegistry()
+	defer registry.Close()
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	signed := registry.putImage("signed")
+	payload := cosignPayload(signed)
+	layer := registry.putBlob("application/vnd.dev.cosign.simplesigning.v1+json", payload, map[string]string{
+		cosignSignatureAnnotation: base64.StdEncoding.EncodeToString(signECDSA(t, key, payload)),
+	})
+	registry.putManifest(fmt.Sprintf("sha256-%s.sig", signed.Encoded()), &manifest{Layers: []ocispec.Descriptor{layer}})
+	registry.putImage("unsigned")
+
+	spec := &appsv1alpha1.ImageVerification{Type: appsv1alpha1.ImageSignatureCosign, PublicKey: encodePublicKey(key.Public())}
+	expectVerified(t, registry.image("signed"), spec, signed)
+	expectVerified(t, registry.image(signed.String()), spec, signed)
+	expectVerificationError(t, registry.image("unsigned"), spec)
+	expectVerificationError(t, registry.image("notfound"), spec)
+
+	otherSpec := &appsv1alpha1.ImageVerification{Type: appsv1alpha1.ImageSignatureCosign, PublicKey: encodePublicKey(otherKey.Public())}
+	expectVerificationError(t, registry.image("signed"), otherSpec)
+}
+
+func TestVerifyCosignKeyless(t *testing.T) {
+	registry := newFakeRegistry()
+	defer registry.Close()
+
+	ca, caKey := newCA(t)
+	rekorKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	signingKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	issuer, _ := asn1.Marshal("https://accounts.example.com")
+	cert := newCertificate(t, &x509.Certificate{
+		NotBefore:       time.Now().Add(-time.Minute),
+		NotAfter:        time.Now().Add(time.Minute),
+		EmailAddresses:  []string{"security@example.com"},
+		KeyUsage:        x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		ExtraExtensions: []pkix.Extension{{Id: oidFulcioIssuerV2, Value: issuer}},
+	}, ca, signingKey.Public(), caKey)
+
+	signed := registry.putImage("signed")
+	payload := cosignPayload(signed)
+	sig := signECDSA(t, signingKey, payload)
+	hashed := sha256.Sum256(payload)
+	certPEM := encodeCertificate(cert)
+	body, _ := json.Marshal(map[string]interface{}{
+		"apiVersion": "0.0.1",
+		"kind":       "hashedrekord",
+		"spec": map[string]interface{}{
+			"data":      map[string]interface{}{"hash": map[string]string{"algorithm": "sha256", "value": hex.EncodeToString(hashed[:])}},
+			"signature": map[string]interface{}{"content": sig, "publicKey": map[string]interface{}{"content": []byte(certPEM)}},
+		},
+	})
+	rekorDER, _ := x509.MarshalPKIXPublicKey(rekorKey.Public())
+	logID := sha256.Sum256(rekorDER)
+	entry := rekorPayload{
+		Body:           base64.StdEncoding.EncodeToString(body),
+		IntegratedTime: time.Now().Unix(),
+		LogID:          hex.EncodeToString(logID[:]),
+		LogIndex:       1,
+	}
+	canonical, _ := json.Marshal(entry)
+	bundle, _ := json.Marshal(map[string]interface{}{"SignedEntryTimestamp": signECDSA(t, rekorKey, canonical), "Payload": entry})
+
+	layer := registry.putBlob("application/vnd.dev.cosign.simplesigning.v1+json", payload, map[string]string{
+		cosignSignatureAnnotation:   base64.StdEncoding.EncodeToString(sig),
+		cosignCertificateAnnotation: certPEM,
+		cosignChainAnnotation:       encodeCertificate(ca),
+		cosignBundleAnnotation:      string(bundle),
+	})
+	registry.putManifest(fmt.Sprintf("sha256-%s.sig", signed.Encoded()), &manifest{Layers: []ocispec.Descriptor{layer}})
+
+	keyless := appsv1alpha1.ImageVerificationKeyless{
+		Roots:          encodeCertificate(ca),
+		RekorPublicKey: encodePublicKey(rekorKey.Public()),
+		Issuer:         "https://accounts.example.com",
+		Subject:        "security@example.com",
+	}
+	spec := &appsv1alpha1.ImageVerification{Type: appsv1alpha1.ImageSignatureCosign, Keyless: &keyless}
+	expectVerified(t, registry.image("signed"), spec, signed)
+
+	wrongSubject := *spec.DeepCopy()
+	wrongSubject.Keyless.Subject = "someone@example.com"
+	expectVerificationError(t, registry.image("signed"), &wrongSubject)
+
+	wrongIssuer := *spec.DeepCopy()
+	wrongIssuer.Keyless.Issuer = "https://token.actions.githubusercontent.com"
+	expectVerificationError(t, registry.image("signed"), &wrongIssuer)
+
+	otherCA, _ := newCA(t)
+	untrusted := *spec.DeepCopy()
+	untrusted.Keyless.Roots = encodeCertificate(otherCA)
+	expectVerificationError(t, registry.image("signed"), &untrusted)
+}
+
+func TestVerifyNotation(t *testing.T) {
+	registry := newFakeRegistry()
+	defer registry.Close()
+
+	ca, caKey := newCA(t)
+	signingKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	cert := newCertificate(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "signer"},
+		NotBefore:   time.Now().Add(-time.Hour),
+		NotAfter:    time.Now().Add(time.Hour),
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}, ca, signingKey.Public(), caKey)
+
+	signed := registry.putImage("signed")
+	protected, _ := json.Marshal(map[string]interface{}{
+		"alg":                       "PS256",
+		"cty":                       notationPayloadContentType,
+		"crit":                      []string{notationSigningSchemeHeader},
+		notationSigningSchemeHeader: notationSigningSchemeX509,
+	})
+	payload, _ := json.Marshal(map[string]interface{}{
+		"targetArtifact": map[string]interface{}{"mediaType": ocispec.MediaTypeImageManifest, "digest": signed},
+	})
+	signingInput := base64.RawURLEncoding.EncodeToString(protected) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	hashed := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPSS(rand.Reader, signingKey, crypto.SHA256, hashed[:], &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	envelope, _ := json.Marshal(map[string]interface{}{
+		"payload":   base64.RawURLEncoding.EncodeToString(payload),
+		"protected": base64.RawURLEncoding.EncodeToString(protected),
+		"header":    map[string]interface{}{"x5c": [][]byte{cert.Raw, ca.Raw}},
+		"signature": base64.RawURLEncoding.EncodeToString(sig),
+	})
+
+	layer := registry.putBlob(notationMediaTypeJWS, envelope, nil)
+	signature := registry.putManifest("signature", &manifest{ArtifactType: notationArtifactType, Layers: []ocispec.Descriptor{layer}})
+	// the registry does not support referrers API, so that the referrers are found by the tag
+	registry.putManifest(fmt.Sprintf("sha256-%s", signed.Encoded()), &manifest{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []descriptor{{Descriptor: signature, ArtifactType: notationArtifactType}},
+	})
+	registry.putImage("unsigned")
+
+	spec := &appsv1alpha1.ImageVerification{Type: appsv1alpha1.ImageSignatureNotation, TrustedCertificates: encodeCertificate(ca)}
+	expectVerified(t, registry.image("signed"), spec, signed)
+	expectVerificationError(t, registry.image("unsigned"), spec)
+
+	otherCA, _ := newCA(t)
+	untrusted := &appsv1alpha1.ImageVerification{Type: appsv1alpha1.ImageSignatureNotation, TrustedCertificates: encodeCertificate(otherCA)}
+	expectVerificationError(t, registry.image("signed"), untrusted)
+}
+
+func TestValidate(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ca, _ := newCA(t)
+	cases := []struct {
+		spec    appsv1alpha1.ImageVerification
+		invalid bool
+	}{
+		{spec: appsv1alpha1.ImageVerification{Type: appsv1alpha1.ImageSignatureCosign, PublicKey: encodePublicKey(key.Public())}},
+		{spec: appsv1alpha1.ImageVerification{Type: appsv1alpha1.ImageSignatureCosign}, invalid: true},
+		{spec: appsv1alpha1.ImageVerification{Type: appsv1alpha1.ImageSignatureCosign, PublicKey: "invalid"}, invalid: true},
+		{spec: appsv1alpha1.ImageVerification{Type: appsv1alpha1.ImageSignatureCosign, Keyless: &appsv1alpha1.ImageVerificationKeyless{
+			Roots: encodeCertificate(ca), RekorPublicKey: encodePublicKey(key.Public()), Issuer: "https://accounts.example.com"}}, invalid: true},
+		{spec: appsv1alpha1.ImageVerification{Type: appsv1alpha1.ImageSignatureNotation, TrustedCertificates: encodeCertificate(ca)}},
+		{spec: appsv1alpha1.ImageVerification{Type: appsv1alpha1.ImageSignatureNotation, PublicKey: encodePublicKey(key.Public())}, invalid: true},
+		{spec: appsv1alpha1.ImageVerification{Type: "Unknown"}, invalid: true},
+	}
+	for i, tc := range cases {
+		if err := Validate(&tc.spec); (err != nil) != tc.invalid {
+			t.Fatalf("case #%d expected invalid=%v, got %v", i, tc.invalid, err)
+		}
+	}
+}
@@ -29,8 +29,10 @@ import (
 	"github.com/openkruise/kruise/pkg/util/revisionadapter"
 	apps "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/sets"
 	coreinformers "k8s.io/client-go/informers/core/v1"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/util/retry"
@@ -39,12 +41,22 @@ import (
 )
 
 var (
-	containerImagePatchRexp = regexp.MustCompile("^/spec/containers/([0-9]+)/image$")
-	rfc6901Decoder          = strings.NewReplacer("~1", "/", "~0", "~")
+	containerImagePatchRexp     = regexp.MustCompile("^/spec/containers/([0-9]+)/image$")
+	containerResourcesPatchRexp = regexp.MustCompile("^/spec/containers/([0-9]+)/resources(/.*)?$")
+	rfc6901Decoder              = strings.NewReplacer("~1", "/", "~0", "~")
 
 	Clock clock.Clock = clock.RealClock{}
 )
 
+const (
+	// PodResizePending and PodResizeInProgress are the pod conditions reported by kubelet for in-place resize.
+	PodResizePending    v1.PodConditionType = "PodResizePending"
+	PodResizeInProgress v1.PodConditionType = "PodResizeInProgress"
+
+	// PodReasonInfeasible is the reason of PodResizePending condition when the node can not satisfy the resize.
+	PodReasonInfeasible = "Infeasible"
+)
+
 type RefreshResult struct {
 	RefreshErr    error
 	DelayDuration time.Duration
@@ -79,11 +91,12 @@ type Interface interface {
 type UpdateSpec struct {
 	Revision string `json:"revision"`
 
-	ContainerImages       map[string]string            `json:"containerImages,omitempty"`
-	ContainerRefMetadata  map[string]metav1.ObjectMeta `json:"containerRefMetadata,omitempty"`
-	MetaDataPatch         []byte                       `json:"metaDataPatch,omitempty"`
-	UpdateEnvFromMetadata bool                         `json:"updateEnvFromMetadata,omitempty"`
-	GraceSeconds          int32                        `json:"graceSeconds,omitempty"`
+	ContainerImages       map[string]string                  `json:"containerImages,omitempty"`
+	ContainerResources    map[string]v1.ResourceRequirements `json:"containerResources,omitempty"`
+	ContainerRefMetadata  map[string]metav1.ObjectMeta       `json:"containerRefMetadata,omitempty"`
+	MetaDataPatch         []byte                             `json:"metaDataPatch,omitempty"`
+	UpdateEnvFromMetadata bool                               `json:"updateEnvFromMetadata,omitempty"`
+	GraceSeconds          int32                              `json:"graceSeconds,omitempty"`
 
 	OldTemplate *v1.PodTemplateSpec `json:"oldTemplate,omitempty"`
 	NewTemplate *v1.PodTemplateSpec `json:"newTemplate,omitempty"`
@@ -213,6 +226,9 @@ func (c *realControl) finishGracePeriod(pod *v1.Pod, opts *UpdateOptions) (time.
 				return nil
 			}
 
+			if clone, err = c.resizePodIfNeeded(clone, &spec); err != nil {
+				return err
+			}
 			if clone, err = opts.PatchSpecToPod(clone, &spec, &updateState); err != nil {
 				return err
 			}
@@ -311,6 +327,12 @@ func (c *realControl) updatePodInPlace(pod *v1.Pod, spec *UpdateSpec, opts *Upda
 		if err != nil {
 			return err
 		}
+		// resize containers before the other fields updated, for resize subresource only changes resources
+		if spec.GraceSeconds <= 0 {
+			if clone, err = c.resizePodIfNeeded(clone, spec); err != nil {
+				return err
+			}
+		}
 
 		// update new revision
 		c.revisionAdapter.WriteRevisionHash(clone, spec.Revision)
@@ -349,6 +371,39 @@ func (c *realControl) updatePodInPlace(pod *v1.Pod, spec *UpdateSpec, opts *Upda
 	return newResourceVersion, retryErr
 }
 
+// resizePodIfNeeded resizes the resources of containers in spec via pods/resize subresource, and returns the resized pod.
+// If the pod adapter doesn't support resize subresource, the resources are only changed in the returned pod,
+// so that they will be updated together with the other fields.
+func (c *realControl) resizePodIfNeeded(pod *v1.Pod, spec *UpdateSpec) (*v1.Pod, error) {
+	if len(spec.ContainerResources) == 0 {
+		return pod, nil
+	}
+	resized := pod.DeepCopy()
+	changedResources := make(map[string]v1.ResourceRequirements)
+	for i := range resized.Spec.Containers {
+		container := &resized.Spec.Containers[i]
+		if resources, ok := spec.ContainerResources[container.Name]; ok && !apiequality.Semantic.DeepEqual(container.Resources, resources) {
+			container.Resources = resources
+			changedResources[container.Name] = resources
+		}
+	}
+	if len(changedResources) == 0 {
+		return pod, nil
+	}
+	adapter, ok := c.podAdapter.(podadapter.AdapterWithResize)
+	if !ok {
+		return resized, nil
+	}
+	klog.V(3).Infof("Resize containers %v of pod %s/%s in-place", util.DumpJSON(changedResources), pod.Namespace, pod.Name)
+	return adapter.ResizePod(pod, changedResources)
+}
+
+// IsPodResizeInfeasible returns whether the resize of pod has been rejected by node, e.g. for lack of allocatable resources.
+func IsPodResizeInfeasible(pod *v1.Pod) bool {
+	condition := getCondition(pod, PodResizePending)
+	return condition != nil && condition.Status == v1.ConditionTrue && condition.Reason == PodReasonInfeasible
+}
+
 // GetTemplateFromRevision returns the pod template parsed from ControllerRevision.
 func GetTemplateFromRevision(revision *apps.ControllerRevision) (*v1.PodTemplateSpec, error) {
 	var patchObj *struct {
@@ -362,6 +417,44 @@ func GetTemplateFromRevision(revision *apps.ControllerRevision) (*v1.PodTemplate
 	return &patchObj.Spec.Template, nil
 }
 
+// IsOnlyMetadataKeysChanged returns true if there are only labels or annotations in keys changed
+// between the templates of oldRevision and newRevision.
+func IsOnlyMetadataKeysChanged(oldRevision, newRevision *apps.ControllerRevision, keys []string) bool {
+	if oldRevision == nil || newRevision == nil || len(keys) == 0 {
+		return false
+	}
+	oldTemp, err := GetTemplateFromRevision(oldRevision)
+	if err != nil {
+		return false
+	}
+	newTemp, err := GetTemplateFromRevision(newRevision)
+	if err != nil {
+		return false
+	}
+
+	allowed := sets.NewString(keys...)
+	if !isOnlyKeysChanged(oldTemp.Labels, newTemp.Labels, allowed) || !isOnlyKeysChanged(oldTemp.Annotations, newTemp.Annotations, allowed) {
+		return false
+	}
+	oldTemp.Labels, newTemp.Labels = nil, nil
+	oldTemp.Annotations, newTemp.Annotations = nil, nil
+	return apiequality.Semantic.DeepEqual(oldTemp, newTemp)
+}
+
+func isOnlyKeysChanged(oldMap, newMap map[string]string, allowed sets.String) bool {
+	for k, v := range oldMap {
+		if newV, ok := newMap[k]; (!ok || newV != v) && !allowed.Has(k) {
+			return false
+		}
+	}
+	for k := range newMap {
+		if _, ok := oldMap[k]; !ok && !allowed.Has(k) {
+			return false
+		}
+	}
+	return true
+}
+
 // InjectReadinessGate injects InPlaceUpdateReady into pod.spec.readinessGates
 func InjectReadinessGate(pod *v1.Pod) {
 	for _, r := range pod.Spec.ReadinessGates {
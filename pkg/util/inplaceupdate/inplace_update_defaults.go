@@ -263,6 +263,23 @@ func defaultCalculateInPlaceUpdateSpec(oldRevision, newRevision *apps.Controller
 			}
 			return nil
 		}
+		if containerResourcesPatchRexp.MatchString(op.Path) && utilfeature.DefaultFeatureGate.Enabled(features.InPlaceWorkloadVerticalScaling) {
+			// for example: /spec/containers/0/resources/limits/cpu
+			words := strings.Split(op.Path, "/")
+			idx, _ := strconv.Atoi(words[3])
+			if len(oldTemp.Spec.Containers) <= idx || len(newTemp.Spec.Containers) <= idx {
+				return nil
+			}
+			oldContainer, newContainer := &oldTemp.Spec.Containers[idx], &newTemp.Spec.Containers[idx]
+			if !onlyResizableResourcesChanged(&oldContainer.Resources, &newContainer.Resources) {
+				return nil
+			}
+			if updateSpec.ContainerResources == nil {
+				updateSpec.ContainerResources = make(map[string]v1.ResourceRequirements)
+			}
+			updateSpec.ContainerResources[oldContainer.Name] = newContainer.Resources
+			continue
+		}
 		if op.Operation != "replace" || !containerImagePatchRexp.MatchString(op.Path) {
 			return nil
 		}
@@ -350,9 +367,41 @@ func DefaultCheckInPlaceUpdateCompleted(pod *v1.Pod) error {
 		return fmt.Errorf("existing containers to in-place update in next batches")
 	}
 
+	if condition := getCondition(pod, PodResizePending); condition != nil && condition.Status == v1.ConditionTrue {
+		return fmt.Errorf("waiting for resize of containers, pending for %s", condition.Reason)
+	} else if condition = getCondition(pod, PodResizeInProgress); condition != nil && condition.Status == v1.ConditionTrue {
+		return fmt.Errorf("waiting for resize of containers in progress")
+	}
+
 	return defaultCheckContainersInPlaceUpdateCompleted(pod, &inPlaceUpdateState)
 }
 
+// onlyResizableResourcesChanged returns whether the resources have only been changed in cpu and memory,
+// which can be resized without recreating the container.
+func onlyResizableResourcesChanged(oldResources, newResources *v1.ResourceRequirements) bool {
+	isResizable := func(name v1.ResourceName) bool {
+		return name == v1.ResourceCPU || name == v1.ResourceMemory
+	}
+	equalExceptResizable := func(oldList, newList v1.ResourceList) bool {
+		for name, quantity := range oldList {
+			if isResizable(name) {
+				continue
+			}
+			if newQuantity, ok := newList[name]; !ok || !quantity.Equal(newQuantity) {
+				return false
+			}
+		}
+		for name := range newList {
+			if _, ok := oldList[name]; !ok && !isResizable(name) {
+				return false
+			}
+		}
+		return true
+	}
+	return equalExceptResizable(oldResources.Limits, newResources.Limits) &&
+		equalExceptResizable(oldResources.Requests, newResources.Requests)
+}
+
 func defaultCheckContainersInPlaceUpdateCompleted(pod *v1.Pod, inPlaceUpdateState *appspub.InPlaceUpdateState) error {
 	runtimeContainerMetaSet, err := appspub.GetRuntimeContainerMetaSet(pod)
 	if err != nil {
@@ -30,6 +30,8 @@ import (
 	"github.com/openkruise/kruise/pkg/util/revisionadapter"
 	apps "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -153,6 +155,78 @@ func TestCalculateInPlaceUpdateSpec(t *testing.T) {
 	}
 }
 
+func TestCalculateInPlaceUpdateSpecWithResources(t *testing.T) {
+	oldRevision := &apps.ControllerRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: "old-revision"},
+		Data:       runtime.RawExtension{Raw: []byte(`{"spec":{"template":{"$patch":"replace","spec":{"containers":[{"name":"c1","image":"foo1","resources":{"limits":{"cpu":"1","nvidia.com/gpu":"1"}}}]}}}}`)},
+	}
+	cases := []struct {
+		name         string
+		enabled      bool
+		newRevision  *apps.ControllerRevision
+		expectedSpec *UpdateSpec
+	}{
+		{
+			name:    "feature-gate disabled",
+			enabled: false,
+			newRevision: &apps.ControllerRevision{
+				ObjectMeta: metav1.ObjectMeta{Name: "new-revision"},
+				Data:       runtime.RawExtension{Raw: []byte(`{"spec":{"template":{"$patch":"replace","spec":{"containers":[{"name":"c1","image":"foo1","resources":{"limits":{"cpu":"2","nvidia.com/gpu":"1"}}}]}}}}`)},
+			},
+			expectedSpec: nil,
+		},
+		{
+			name:    "resize cpu and memory with image",
+			enabled: true,
+			newRevision: &apps.ControllerRevision{
+				ObjectMeta: metav1.ObjectMeta{Name: "new-revision"},
+				Data:       runtime.RawExtension{Raw: []byte(`{"spec":{"template":{"$patch":"replace","spec":{"containers":[{"name":"c1","image":"foo2","resources":{"limits":{"cpu":"2","nvidia.com/gpu":"1"},"requests":{"memory":"1Gi"}}}]}}}}`)},
+			},
+			expectedSpec: &UpdateSpec{
+				Revision:             "new-revision",
+				ContainerImages:      map[string]string{"c1": "foo2"},
+				ContainerRefMetadata: make(map[string]metav1.ObjectMeta),
+				ContainerResources: map[string]v1.ResourceRequirements{"c1": {
+					Limits:   v1.ResourceList{v1.ResourceCPU: resource.MustParse("2"), "nvidia.com/gpu": resource.MustParse("1")},
+					Requests: v1.ResourceList{v1.ResourceMemory: resource.MustParse("1Gi")},
+				}},
+			},
+		},
+		{
+			name:    "change other resources",
+			enabled: true,
+			newRevision: &apps.ControllerRevision{
+				ObjectMeta: metav1.ObjectMeta{Name: "new-revision"},
+				Data:       runtime.RawExtension{Raw: []byte(`{"spec":{"template":{"$patch":"replace","spec":{"containers":[{"name":"c1","image":"foo1","resources":{"limits":{"cpu":"2","nvidia.com/gpu":"2"}}}]}}}}`)},
+			},
+			expectedSpec: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer utilfeature.SetFeatureGateDuringTest(t, utilfeature.DefaultFeatureGate, features.InPlaceWorkloadVerticalScaling, tc.enabled)()
+			res := defaultCalculateInPlaceUpdateSpec(oldRevision, tc.newRevision, nil)
+			if !apiequality.Semantic.DeepEqual(res, tc.expectedSpec) {
+				t.Fatalf("expected %+v, got %+v", util.DumpJSON(tc.expectedSpec), util.DumpJSON(res))
+			}
+		})
+	}
+}
+
+func TestIsPodResizeInfeasible(t *testing.T) {
+	pod := &v1.Pod{Status: v1.PodStatus{Conditions: []v1.PodCondition{
+		{Type: PodResizePending, Status: v1.ConditionTrue, Reason: "Deferred"},
+	}}}
+	if IsPodResizeInfeasible(pod) {
+		t.Fatalf("expected deferred resize to be feasible")
+	}
+	pod.Status.Conditions[0].Reason = PodReasonInfeasible
+	if !IsPodResizeInfeasible(pod) {
+		t.Fatalf("expected infeasible resize")
+	}
+}
+
 func TestCheckInPlaceUpdateCompleted(t *testing.T) {
 	succeedPods := []*v1.Pod{
 		{
@@ -235,6 +309,22 @@ func TestCheckInPlaceUpdateCompleted(t *testing.T) {
 			},
 			Status: v1.PodStatus{},
 		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "f4",
+				Labels: map[string]string{
+					apps.StatefulSetRevisionLabel: "new-revision",
+				},
+				Annotations: map[string]string{
+					appspub.InPlaceUpdateStateKey: `{"revision":"new-revision"}`,
+				},
+			},
+			Status: v1.PodStatus{
+				Conditions: []v1.PodCondition{
+					{Type: PodResizeInProgress, Status: v1.ConditionTrue},
+				},
+			},
+		},
 	}
 
 	for _, p := range succeedPods {
@@ -639,3 +729,53 @@ func TestRefresh(t *testing.T) {
 		}
 	}
 }
+
+func TestIsOnlyMetadataKeysChanged(t *testing.T) {
+	newRevision := func(metadata, image string) *apps.ControllerRevision {
+		return &apps.ControllerRevision{Data: runtime.RawExtension{Raw: []byte(
+			fmt.Sprintf(`{"spec":{"template":{"$patch":"replace","metadata":%s,"spec":{"containers":[{"name":"c1","image":"%s"}]}}}}`, metadata, image))}}
+	}
+	oldRevision := newRevision(`{"labels":{"app":"foo","route":"blue"},"annotations":{"flag":"off"}}`, "foo1")
+
+	cases := []struct {
+		name        string
+		newRevision *apps.ControllerRevision
+		keys        []string
+		expected    bool
+	}{
+		{
+			name:        "allowed keys changed",
+			newRevision: newRevision(`{"labels":{"app":"foo","route":"green"},"annotations":{"flag":"on"}}`, "foo1"),
+			keys:        []string{"route", "flag"},
+			expected:    true,
+		},
+		{
+			name:        "allowed key removed",
+			newRevision: newRevision(`{"labels":{"app":"foo"},"annotations":{"flag":"off"}}`, "foo1"),
+			keys:        []string{"route"},
+			expected:    true,
+		},
+		{
+			name:        "other key changed",
+			newRevision: newRevision(`{"labels":{"app":"bar","route":"green"},"annotations":{"flag":"off"}}`, "foo1"),
+			keys:        []string{"route"},
+		},
+		{
+			name:        "spec changed",
+			newRevision: newRevision(`{"labels":{"app":"foo","route":"green"},"annotations":{"flag":"off"}}`, "foo2"),
+			keys:        []string{"route"},
+		},
+		{
+			name:        "no allowlist",
+			newRevision: newRevision(`{"labels":{"app":"foo","route":"green"},"annotations":{"flag":"off"}}`, "foo1"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := IsOnlyMetadataKeysChanged(oldRevision, tc.newRevision, tc.keys); got != tc.expected {
+				t.Fatalf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
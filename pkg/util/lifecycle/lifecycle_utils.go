@@ -173,3 +173,20 @@ func IsPodAllHooked(hook *appspub.LifecycleHook, pod *v1.Pod) bool {
 	}
 	return true
 }
+
+// IsHookTimeout returns whether the hook has timed out for the pod in its current lifecycle state.
+// If not, it also returns the duration left before timeout, which is 0 if the hook has no timeout.
+func IsHookTimeout(hook *appspub.LifecycleHook, pod *v1.Pod) (bool, time.Duration) {
+	if hook == nil || pod == nil || hook.TimeoutSeconds == nil || *hook.TimeoutSeconds <= 0 {
+		return false, 0
+	}
+	timestamp, err := time.Parse(time.RFC3339, pod.Annotations[appspub.LifecycleTimestampKey])
+	if err != nil {
+		return false, 0
+	}
+	left := time.Duration(*hook.TimeoutSeconds)*time.Second - time.Since(timestamp)
+	if left <= 0 {
+		return true, 0
+	}
+	return false, left
+}
@@ -0,0 +1,37 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// GetMetricValueDuringTest returns the value of the counter or gauge metric.
+// Failures to write the metric cause the test to fail.
+func GetMetricValueDuringTest(tb testing.TB, m prometheus.Metric) float64 {
+	metric := &dto.Metric{}
+	if err := m.Write(metric); err != nil {
+		tb.Fatalf("write metric failed: %s", err.Error())
+	}
+	if metric.Counter != nil {
+		return metric.GetCounter().GetValue()
+	}
+	return metric.GetGauge().GetValue()
+}
@@ -18,7 +18,10 @@ package podadapter
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 
+	kubeClient "github.com/openkruise/kruise/pkg/client"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -38,6 +41,13 @@ type AdapterWithPatch interface {
 	PatchPod(pod *v1.Pod, patch client.Patch) (*v1.Pod, error)
 }
 
+// AdapterWithResize resizes container resources of pod via pods/resize subresource
+type AdapterWithResize interface {
+	Adapter
+	// ResizePod patches only the resources of the given containers via pods/resize subresource.
+	ResizePod(pod *v1.Pod, containerResources map[string]v1.ResourceRequirements) (*v1.Pod, error)
+}
+
 type AdapterRuntimeClient struct {
 	client.Client
 }
@@ -60,6 +70,16 @@ func (c *AdapterRuntimeClient) PatchPod(pod *v1.Pod, patch client.Patch) (*v1.Po
 	return pod, c.Patch(context.TODO(), pod, patch)
 }
 
+// ResizePod uses typed client for the resize subresource, because runtime client only supports status subresource.
+// It fails if the typed client hasn't been initialized, for the resources can not be updated with pod.
+func (c *AdapterRuntimeClient) ResizePod(pod *v1.Pod, containerResources map[string]v1.ResourceRequirements) (*v1.Pod, error) {
+	genericClient := kubeClient.GetGenericClient()
+	if genericClient == nil {
+		return nil, fmt.Errorf("failed to resize pod %s/%s: no typed client for pods/resize subresource", pod.Namespace, pod.Name)
+	}
+	return resizePod(genericClient.KubeClient, pod, containerResources)
+}
+
 type AdapterTypedClient struct {
 	Client clientset.Interface
 }
@@ -77,6 +97,10 @@ func (c *AdapterTypedClient) UpdatePodStatus(pod *v1.Pod) error {
 	return err
 }
 
+func (c *AdapterTypedClient) ResizePod(pod *v1.Pod, containerResources map[string]v1.ResourceRequirements) (*v1.Pod, error) {
+	return resizePod(c.Client, pod, containerResources)
+}
+
 func (c *AdapterTypedClient) PatchPod(pod *v1.Pod, patch client.Patch) (*v1.Pod, error) {
 	patchData, err := patch.Data(pod)
 	if err != nil {
@@ -104,3 +128,50 @@ func (c *AdapterInformer) UpdatePod(pod *v1.Pod) (*v1.Pod, error) {
 func (c *AdapterInformer) UpdatePodStatus(pod *v1.Pod) error {
 	return c.PodInformer.Informer().GetIndexer().Update(pod)
 }
+
+// resizePod sends a strategic merge patch which only touches spec.containers[*].resources to pods/resize subresource,
+// so that the fields unknown to this client, such as resizePolicy, are never dropped.
+func resizePod(c clientset.Interface, pod *v1.Pod, containerResources map[string]v1.ResourceRequirements) (*v1.Pod, error) {
+	patch, err := getResizePatch(pod, containerResources)
+	if err != nil {
+		return nil, err
+	}
+	return c.CoreV1().Pods(pod.Namespace).Patch(context.TODO(), pod.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{}, "resize")
+}
+
+func getResizePatch(pod *v1.Pod, containerResources map[string]v1.ResourceRequirements) ([]byte, error) {
+	var containers []map[string]interface{}
+	for i := range pod.Spec.Containers {
+		container := &pod.Spec.Containers[i]
+		resources, ok := containerResources[container.Name]
+		if !ok {
+			continue
+		}
+		resourcesPatch := map[string]interface{}{}
+		if limits := getResourceListPatch(container.Resources.Limits, resources.Limits); len(limits) > 0 {
+			resourcesPatch["limits"] = limits
+		}
+		if requests := getResourceListPatch(container.Resources.Requests, resources.Requests); len(requests) > 0 {
+			resourcesPatch["requests"] = requests
+		}
+		containers = append(containers, map[string]interface{}{"name": container.Name, "resources": resourcesPatch})
+	}
+	if len(containers) == 0 {
+		return nil, fmt.Errorf("no container of pod %s/%s to resize", pod.Namespace, pod.Name)
+	}
+	return json.Marshal(map[string]interface{}{"spec": map[string]interface{}{"containers": containers}})
+}
+
+// getResourceListPatch returns the new quantities, and null for the ones removed.
+func getResourceListPatch(oldList, newList v1.ResourceList) map[v1.ResourceName]interface{} {
+	patch := make(map[v1.ResourceName]interface{}, len(newList))
+	for name, quantity := range newList {
+		patch[name] = quantity.String()
+	}
+	for name := range oldList {
+		if _, ok := newList[name]; !ok {
+			patch[name] = nil
+		}
+	}
+	return patch
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podadapter
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestResizePod(t *testing.T) {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod-0"},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Name:  "c1",
+					Image: "nginx",
+					Resources: v1.ResourceRequirements{
+						Limits:   v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+						Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1"), v1.ResourceMemory: resource.MustParse("1Gi")},
+					},
+				},
+				{
+					Name:      "c2",
+					Image:     "busybox",
+					Resources: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}},
+				},
+			},
+		},
+	}
+	kubeClient := fake.NewSimpleClientset(pod)
+	adapter := &AdapterTypedClient{Client: kubeClient}
+
+	newPod, err := adapter.ResizePod(pod, map[string]v1.ResourceRequirements{
+		"c1": {
+			Limits:   v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+			Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to resize pod: %v", err)
+	}
+
+	actions := kubeClient.Actions()
+	patchAction, ok := actions[len(actions)-1].(clienttesting.PatchAction)
+	if !ok || patchAction.GetSubresource() != "resize" {
+		t.Fatalf("expected patch on resize subresource, got %v", actions[len(actions)-1])
+	}
+	expectedPatch := `{"spec":{"containers":[{"name":"c1","resources":{"limits":{"cpu":"2"},"requests":{"cpu":"2","memory":null}}}]}}`
+	if string(patchAction.GetPatch()) != expectedPatch {
+		t.Fatalf("expected patch %s, got %s", expectedPatch, patchAction.GetPatch())
+	}
+
+	c1, c2 := newPod.Spec.Containers[0], newPod.Spec.Containers[1]
+	if c1.Image != "nginx" || c1.Resources.Requests.Cpu().String() != "2" || c1.Resources.Limits.Cpu().String() != "2" {
+		t.Fatalf("unexpected resized container %+v", c1)
+	}
+	if _, ok := c1.Resources.Requests[v1.ResourceMemory]; ok {
+		t.Fatalf("expected memory request removed, got %+v", c1.Resources)
+	}
+	if c2.Resources.Requests.Cpu().String() != "1" {
+		t.Fatalf("expected container c2 untouched, got %+v", c2)
+	}
+
+	if _, err := adapter.ResizePod(pod, map[string]v1.ResourceRequirements{"c3": {}}); err == nil {
+		t.Fatalf("expected error for no container to resize")
+	}
+}
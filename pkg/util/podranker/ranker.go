@@ -0,0 +1,199 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podranker
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	appspub "github.com/openkruise/kruise/apis/apps/pub"
+	"github.com/openkruise/kruise/pkg/util/fieldindex"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	kubecontroller "k8s.io/kubernetes/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Ranker ranks the pods to be deleted when scaling down, and pods with higher ranks are preferred to be deleted.
+type Ranker interface {
+	GetRank(*v1.Pod) float64
+}
+
+// New returns the Ranker for the ranking policy, or nil if no policy is specified.
+func New(policy *appspub.ScaleDownRankingPolicy, pods []*v1.Pod, reader client.Reader) Ranker {
+	if policy == nil {
+		return nil
+	}
+
+	switch policy.Type {
+	case appspub.ScaleDownRankingNodeUtilization:
+		return newNodeUtilizationRanker(pods, reader)
+	case appspub.ScaleDownRankingSpreadConstraints:
+		return newSpreadConstraintsRanker(pods, reader)
+	case appspub.ScaleDownRankingPodAge:
+		return &podAgeRanker{now: time.Now()}
+	case appspub.ScaleDownRankingAnnotation:
+		return &annotationRanker{key: policy.AnnotationKey}
+	}
+	return nil
+}
+
+type podRanks map[types.UID]float64
+
+func (r podRanks) GetRank(pod *v1.Pod) float64 {
+	return r[pod.UID]
+}
+
+// newNodeUtilizationRanker ranks pods by 1 - the requested utilization of their nodes,
+// so that the pods on the idlest nodes are deleted first.
+func newNodeUtilizationRanker(pods []*v1.Pod, reader client.Reader) Ranker {
+	ranks := podRanks{}
+	utilizations := make(map[string]float64)
+	for _, pod := range pods {
+		nodeName := pod.Spec.NodeName
+		if nodeName == "" {
+			continue
+		}
+		utilization, ok := utilizations[nodeName]
+		if !ok {
+			var err error
+			if utilization, err = getNodeUtilization(nodeName, reader); err != nil {
+				klog.Warningf("Failed to get utilization of node %s: %v", nodeName, err)
+				utilization = 1
+			}
+			utilizations[nodeName] = utilization
+		}
+		ranks[pod.UID] = 1 - utilization
+	}
+	return ranks
+}
+
+// getNodeUtilization returns the average ratio of cpu and memory requested by active pods on the node.
+func getNodeUtilization(nodeName string, reader client.Reader) (float64, error) {
+	node := &v1.Node{}
+	if err := reader.Get(context.TODO(), types.NamespacedName{Name: nodeName}, node); err != nil {
+		return 0, err
+	}
+	podList := &v1.PodList{}
+	if err := reader.List(context.TODO(), podList, client.MatchingFields{fieldindex.IndexNameForPodNodeName: nodeName}); err != nil {
+		return 0, err
+	}
+
+	var cpu, memory int64
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if pod.Spec.NodeName != nodeName || !kubecontroller.IsPodActive(pod) {
+			continue
+		}
+		for _, c := range pod.Spec.Containers {
+			cpu += c.Resources.Requests.Cpu().MilliValue()
+			memory += c.Resources.Requests.Memory().Value()
+		}
+	}
+
+	var utilization float64
+	if allocatable := node.Status.Allocatable.Cpu().MilliValue(); allocatable > 0 {
+		utilization += float64(cpu) / float64(allocatable) / 2
+	}
+	if allocatable := node.Status.Allocatable.Memory().Value(); allocatable > 0 {
+		utilization += float64(memory) / float64(allocatable) / 2
+	}
+	if utilization > 1 {
+		utilization = 1
+	}
+	return utilization, nil
+}
+
+// newSpreadConstraintsRanker ranks pods by how much their topology domains exceed the least crowded domain,
+// according to the topologySpreadConstraints of pods.
+func newSpreadConstraintsRanker(pods []*v1.Pod, reader client.Reader) Ranker {
+	ranks := podRanks{}
+	if len(pods) == 0 || len(pods[0].Spec.TopologySpreadConstraints) == 0 {
+		return ranks
+	}
+
+	nodes := make(map[string]*v1.Node)
+	for _, pod := range pods {
+		nodeName := pod.Spec.NodeName
+		if _, ok := nodes[nodeName]; ok || nodeName == "" {
+			continue
+		}
+		node := &v1.Node{}
+		if err := reader.Get(context.TODO(), types.NamespacedName{Name: nodeName}, node); err != nil {
+			klog.Warningf("Failed to get node %s: %v", nodeName, err)
+			node = nil
+		}
+		nodes[nodeName] = node
+	}
+
+	for _, constraint := range pods[0].Spec.TopologySpreadConstraints {
+		podsInDomains := make(map[string][]*v1.Pod)
+		for _, pod := range pods {
+			node := nodes[pod.Spec.NodeName]
+			if node == nil {
+				continue
+			}
+			if domain, ok := node.Labels[constraint.TopologyKey]; ok {
+				podsInDomains[domain] = append(podsInDomains[domain], pod)
+			}
+		}
+
+		minCount := -1
+		for _, podsInDomain := range podsInDomains {
+			if minCount < 0 || len(podsInDomain) < minCount {
+				minCount = len(podsInDomain)
+			}
+		}
+		for _, podsInDomain := range podsInDomains {
+			for _, pod := range podsInDomain {
+				ranks[pod.UID] += float64(len(podsInDomain) - minCount)
+			}
+		}
+	}
+	return ranks
+}
+
+type podAgeRanker struct {
+	now time.Time
+}
+
+func (r *podAgeRanker) GetRank(pod *v1.Pod) float64 {
+	if pod.CreationTimestamp.IsZero() {
+		return 0
+	}
+	return r.now.Sub(pod.CreationTimestamp.Time).Seconds()
+}
+
+type annotationRanker struct {
+	key string
+}
+
+func (r *annotationRanker) GetRank(pod *v1.Pod) float64 {
+	value, ok := pod.Annotations[r.key]
+	if !ok {
+		return math.Inf(-1)
+	}
+	rank, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(rank) {
+		klog.V(4).Infof("Pod %s/%s has invalid rank %q in annotation %s", pod.Namespace, pod.Name, value, r.key)
+		return math.Inf(-1)
+	}
+	return rank
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podranker
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	appspub "github.com/openkruise/kruise/apis/apps/pub"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newNode(name, zone, cpu string) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{v1.LabelTopologyZone: zone}},
+		Status: v1.NodeStatus{Allocatable: v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse(cpu),
+			v1.ResourceMemory: resource.MustParse("8Gi"),
+		}},
+	}
+}
+
+func newPod(name, nodeName, cpu string, age time.Duration, annotations map[string]string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "default",
+			Name:              name,
+			UID:               types.UID(name),
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+			Annotations:       annotations,
+		},
+		Spec: v1.PodSpec{
+			NodeName: nodeName,
+			Containers: []v1.Container{{
+				Name:      "main",
+				Resources: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu)}},
+			}},
+			TopologySpreadConstraints: []v1.TopologySpreadConstraint{{TopologyKey: v1.LabelTopologyZone, MaxSkew: 1}},
+		},
+		Status: v1.PodStatus{Phase: v1.PodRunning},
+	}
+}
+
+func TestRanker(t *testing.T) {
+	nodes := []client.Object{
+		newNode("node-a", "zone-a", "4"),
+		newNode("node-b", "zone-b", "4"),
+	}
+	pods := []*v1.Pod{
+		newPod("pod-1", "node-a", "1", time.Hour, map[string]string{"rank": "1"}),
+		newPod("pod-2", "node-a", "1", time.Minute, map[string]string{"rank": "x"}),
+		newPod("pod-3", "node-b", "1", time.Second, map[string]string{"rank": "3"}),
+	}
+
+	cases := []struct {
+		name     string
+		policy   *appspub.ScaleDownRankingPolicy
+		expected []string
+	}{
+		{
+			name:     "node utilization",
+			policy:   &appspub.ScaleDownRankingPolicy{Type: appspub.ScaleDownRankingNodeUtilization},
+			expected: []string{"pod-3"},
+		},
+		{
+			name:     "spread constraints",
+			policy:   &appspub.ScaleDownRankingPolicy{Type: appspub.ScaleDownRankingSpreadConstraints},
+			expected: []string{"pod-1", "pod-2"},
+		},
+		{
+			name:     "pod age",
+			policy:   &appspub.ScaleDownRankingPolicy{Type: appspub.ScaleDownRankingPodAge},
+			expected: []string{"pod-1", "pod-2", "pod-3"},
+		},
+		{
+			name:     "annotation",
+			policy:   &appspub.ScaleDownRankingPolicy{Type: appspub.ScaleDownRankingAnnotation, AnnotationKey: "rank"},
+			expected: []string{"pod-3", "pod-1", "pod-2"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder().WithObjects(nodes...)
+			for _, pod := range pods {
+				builder.WithObjects(pod.DeepCopy())
+			}
+			ranker := New(tc.policy, pods, builder.Build())
+
+			sorted := make([]*v1.Pod, len(pods))
+			copy(sorted, pods)
+			sort.SliceStable(sorted, func(i, j int) bool {
+				return ranker.GetRank(sorted[i]) > ranker.GetRank(sorted[j])
+			})
+			for i, name := range tc.expected {
+				if sorted[i].Name != name {
+					t.Fatalf("expected %v in front, got %s at %d", tc.expected, sorted[i].Name, i)
+				}
+			}
+		})
+	}
+}
+
+func TestScaleDownRankingPolicyValidation(t *testing.T) {
+	cases := []struct {
+		policy    *appspub.ScaleDownRankingPolicy
+		expectErr bool
+	}{
+		{policy: nil},
+		{policy: &appspub.ScaleDownRankingPolicy{Type: appspub.ScaleDownRankingPodAge}},
+		{policy: &appspub.ScaleDownRankingPolicy{Type: appspub.ScaleDownRankingAnnotation}, expectErr: true},
+		{policy: &appspub.ScaleDownRankingPolicy{Type: appspub.ScaleDownRankingNodeUtilization, AnnotationKey: "rank"}, expectErr: true},
+		{policy: &appspub.ScaleDownRankingPolicy{Type: "Random"}, expectErr: true},
+	}
+
+	for i, tc := range cases {
+		if err := tc.policy.FieldsValidation(); (err != nil) != tc.expectErr {
+			t.Fatalf("case #%d expected error %v, got %v", i, tc.expectErr, err)
+		}
+	}
+}
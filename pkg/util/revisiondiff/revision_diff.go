@@ -0,0 +1,118 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package revisiondiff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/appscode/jsonpatch"
+	appspub "github.com/openkruise/kruise/apis/apps/pub"
+	"github.com/openkruise/kruise/pkg/util/inplaceupdate"
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+var rfc6901Decoder = strings.NewReplacer("~1", "/", "~0", "~")
+
+// Calculate returns the semantic diff from oldRevision to newRevision, or nil if nothing changed.
+// The revisions should be created by CloneSet or Advanced StatefulSet, whose data is a patch of the workload spec.
+func Calculate(oldRevision, newRevision *apps.ControllerRevision) (*appspub.RevisionDiff, error) {
+	if oldRevision == nil || newRevision == nil || oldRevision.Name == newRevision.Name {
+		return nil, nil
+	}
+
+	patches, err := jsonpatch.CreatePatch(oldRevision.Data.Raw, newRevision.Data.Raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff revision %s and %s: %v", oldRevision.Name, newRevision.Name, err)
+	}
+	oldTemp, err := inplaceupdate.GetTemplateFromRevision(oldRevision)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template from revision %s: %v", oldRevision.Name, err)
+	}
+	newTemp, err := inplaceupdate.GetTemplateFromRevision(newRevision)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template from revision %s: %v", newRevision.Name, err)
+	}
+
+	fields := sets.NewString()
+	for _, op := range patches {
+		// the removed containers can only be found by names in the old template
+		if op.Operation == "remove" {
+			fields.Insert(formatPath(op.Path, oldTemp))
+		} else {
+			fields.Insert(formatPath(op.Path, newTemp))
+		}
+	}
+	if fields.Len() == 0 {
+		return nil, nil
+	}
+	return &appspub.RevisionDiff{ChangedFields: fields.List()}, nil
+}
+
+// formatPath converts the json pointer to a field path, such as /spec/template/spec/containers/0/image
+// to spec.template.spec.containers[main].image.
+func formatPath(path string, template *v1.PodTemplateSpec) string {
+	words := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for i := range words {
+		words[i] = rfc6901Decoder.Replace(words[i])
+	}
+
+	var b strings.Builder
+	for i, word := range words {
+		if idx, err := strconv.Atoi(word); err == nil && i > 0 {
+			if name := getContainerName(strings.Join(words[:i], "."), idx, template); name != "" {
+				fmt.Fprintf(&b, "[%s]", name)
+			} else {
+				fmt.Fprintf(&b, "[%d]", idx)
+			}
+			continue
+		}
+		if strings.ContainsAny(word, "./") {
+			fmt.Fprintf(&b, "[%q]", word)
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString(".")
+		}
+		b.WriteString(word)
+	}
+	return b.String()
+}
+
+func getContainerName(parent string, idx int, template *v1.PodTemplateSpec) string {
+	if template == nil || idx < 0 {
+		return ""
+	}
+	switch parent {
+	case "spec.template.spec.containers":
+		if idx < len(template.Spec.Containers) {
+			return template.Spec.Containers[idx].Name
+		}
+	case "spec.template.spec.initContainers":
+		if idx < len(template.Spec.InitContainers) {
+			return template.Spec.InitContainers[idx].Name
+		}
+	case "spec.template.spec.ephemeralContainers":
+		if idx < len(template.Spec.EphemeralContainers) {
+			return template.Spec.EphemeralContainers[idx].Name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package revisiondiff
+
+import (
+	"reflect"
+	"testing"
+
+	apps "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestCalculate(t *testing.T) {
+	newRevision := func(name, raw string) *apps.ControllerRevision {
+		return &apps.ControllerRevision{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Data:       runtime.RawExtension{Raw: []byte(raw)},
+		}
+	}
+	oldRevision := newRevision("old", `{"spec":{"template":{"$patch":"replace","metadata":{"labels":{"app":"nginx"}},`+
+		`"spec":{"containers":[{"name":"main","image":"nginx:1"},{"name":"sidecar","image":"sidecar:1"}]}}}}`)
+
+	cases := []struct {
+		name           string
+		newRevision    *apps.ControllerRevision
+		expectedFields []string
+	}{
+		{
+			name:        "same revision",
+			newRevision: oldRevision,
+		},
+		{
+			name: "same data",
+			newRevision: newRevision("new", `{"spec":{"template":{"$patch":"replace","metadata":{"labels":{"app":"nginx"}},`+
+				`"spec":{"containers":[{"name":"main","image":"nginx:1"},{"name":"sidecar","image":"sidecar:1"}]}}}}`),
+		},
+		{
+			name: "image and labels changed",
+			newRevision: newRevision("new", `{"spec":{"template":{"$patch":"replace","metadata":{"labels":{"app":"nginx","app.kubernetes.io/version":"v2"}},`+
+				`"spec":{"containers":[{"name":"main","image":"nginx:1"},{"name":"sidecar","image":"sidecar:2"}]}}}}`),
+			expectedFields: []string{
+				`spec.template.metadata.labels["app.kubernetes.io/version"]`,
+				"spec.template.spec.containers[sidecar].image",
+			},
+		},
+		{
+			name: "container removed and ordinal overrides added",
+			newRevision: newRevision("new", `{"spec":{"ordinalOverrides":[{"ordinals":[0],"patch":{}}],"template":{"$patch":"replace","metadata":{"labels":{"app":"nginx"}},`+
+				`"spec":{"containers":[{"name":"main","image":"nginx:1"}]}}}}`),
+			expectedFields: []string{
+				"spec.ordinalOverrides",
+				"spec.template.spec.containers[sidecar]",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			diff, err := Calculate(oldRevision, tc.newRevision)
+			if err != nil {
+				t.Fatalf("failed to calculate diff: %v", err)
+			}
+			var fields []string
+			if diff != nil {
+				fields = diff.ChangedFields
+			}
+			if !reflect.DeepEqual(fields, tc.expectedFields) {
+				t.Fatalf("expected changed fields %v, got %v", tc.expectedFields, fields)
+			}
+		})
+	}
+}
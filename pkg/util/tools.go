@@ -18,11 +18,15 @@ limitations under the License.
 package util
 
 import (
+	"fmt"
 	"math"
+	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/docker/distribution/reference"
 	intstrutil "k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/integer"
 )
@@ -151,6 +155,12 @@ func IsContainerImageEqual(image1, image2 string) bool {
 // - if partition > "0%" and replicas > 0, we will ensure at least 1 old pod is reserved.
 // - if partition < "100%" and replicas > 1, we will ensure at least 1 pod is upgraded.
 func CalculatePartitionReplicas(partition *intstrutil.IntOrString, replicasPointer *int32) (int, error) {
+	return CalculatePartitionReplicasWithRounding(partition, replicasPointer, true)
+}
+
+// CalculatePartitionReplicasWithRounding is the same as CalculatePartitionReplicas, except that percentage-type
+// partition is rounded down if roundUp is false. In that case, no old pod will be reserved for a small percentage.
+func CalculatePartitionReplicasWithRounding(partition *intstrutil.IntOrString, replicasPointer *int32, roundUp bool) (int, error) {
 	if partition == nil {
 		return 0, nil
 	}
@@ -161,7 +171,7 @@ func CalculatePartitionReplicas(partition *intstrutil.IntOrString, replicasPoint
 	}
 
 	// 'roundUp=true' will ensure at least 1 old pod is reserved if partition > "0%" and replicas > 0.
-	pValue, err := intstrutil.GetScaledValueFromIntOrPercent(partition, replicas, true)
+	pValue, err := intstrutil.GetScaledValueFromIntOrPercent(partition, replicas, roundUp)
 	if err != nil {
 		return pValue, err
 	}
@@ -174,3 +184,92 @@ func CalculatePartitionReplicas(partition *intstrutil.IntOrString, replicasPoint
 	pValue = integer.IntMax(integer.IntMin(pValue, replicas), 0)
 	return pValue, nil
 }
+
+// OrdinalRange is a range of ordinals with both ends included.
+type OrdinalRange struct {
+	Start int
+	End   int
+}
+
+// Size returns the number of ordinals in the range.
+func (r OrdinalRange) Size() int {
+	return r.End - r.Start + 1
+}
+
+// ParseOrdinalRange parses the ordinal (ex: 5) or range of ordinals (ex: "100-199", both ends included).
+func ParseOrdinalRange(item intstrutil.IntOrString) (OrdinalRange, error) {
+	var r OrdinalRange
+	var err error
+	if item.Type == intstrutil.Int {
+		r.Start, r.End = item.IntValue(), item.IntValue()
+	} else {
+		parts := strings.SplitN(strings.TrimSpace(item.StrVal), "-", 2)
+		if r.Start, err = strconv.Atoi(strings.TrimSpace(parts[0])); err != nil {
+			return r, fmt.Errorf("invalid ordinal range %q: %v", item.StrVal, err)
+		}
+		r.End = r.Start
+		if len(parts) == 2 {
+			if r.End, err = strconv.Atoi(strings.TrimSpace(parts[1])); err != nil {
+				return r, fmt.Errorf("invalid ordinal range %q: %v", item.StrVal, err)
+			}
+		}
+	}
+	if r.Start < 0 || r.End < r.Start {
+		return r, fmt.Errorf("invalid ordinal range %q: must be non-negative and in ascending order", item.String())
+	}
+	return r, nil
+}
+
+// ReservedOrdinals contains the reserved ordinals of StatefulSet, and the ranges are kept as intervals
+// rather than expanded, so that a large range costs no more than a single ordinal.
+type ReservedOrdinals struct {
+	ordinals sets.Int
+	ranges   []OrdinalRange
+}
+
+// NewReservedOrdinals returns the ReservedOrdinals of reserveOrdinals and reserveOrdinalRanges. Invalid ranges are ignored.
+func NewReservedOrdinals(reserveOrdinals []int, reserveOrdinalRanges []string) *ReservedOrdinals {
+	r := &ReservedOrdinals{ordinals: sets.NewInt(reserveOrdinals...)}
+	for _, item := range reserveOrdinalRanges {
+		ordinalRange, err := ParseOrdinalRange(intstrutil.FromString(item))
+		if err != nil {
+			klog.Warningf("Ignore reserve ordinal range %s: %v", item, err)
+			continue
+		}
+		r.ranges = append(r.ranges, ordinalRange)
+	}
+	return r
+}
+
+// Has returns whether the ordinal is reserved.
+func (r *ReservedOrdinals) Has(ordinal int) bool {
+	if r.ordinals.Has(ordinal) {
+		return true
+	}
+	for _, ordinalRange := range r.ranges {
+		if ordinalRange.Start <= ordinal && ordinal <= ordinalRange.End {
+			return true
+		}
+	}
+	return false
+}
+
+// NextUnreserved returns the smallest ordinal not reserved that is no less than the given ordinal.
+func (r *ReservedOrdinals) NextUnreserved(ordinal int) int {
+	for {
+		if r.ordinals.Has(ordinal) {
+			ordinal++
+			continue
+		}
+		skipped := false
+		for _, ordinalRange := range r.ranges {
+			if ordinalRange.Start <= ordinal && ordinal <= ordinalRange.End {
+				ordinal = ordinalRange.End + 1
+				skipped = true
+			}
+		}
+		if !skipped {
+			return ordinal
+		}
+	}
+}
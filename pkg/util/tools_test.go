@@ -299,3 +299,107 @@ func TestCalculatePartitionReplicas(t *testing.T) {
 		})
 	}
 }
+
+func TestCalculatePartitionReplicasWithRoundDown(t *testing.T) {
+	cases := []struct {
+		name          string
+		replicas      *int32
+		partition     *intstr.IntOrString
+		expectedValue int
+	}{
+		{
+			name:          `replicas=10, partition=1%, expected=0`,
+			replicas:      pointer.Int32(10),
+			partition:     &intstr.IntOrString{Type: intstr.String, StrVal: "1%"},
+			expectedValue: 0,
+		},
+		{
+			name:          `replicas=12, partition=20%, expected=2`,
+			replicas:      pointer.Int32(12),
+			partition:     &intstr.IntOrString{Type: intstr.String, StrVal: "20%"},
+			expectedValue: 2,
+		},
+		{
+			name:          `replicas=9, partition=99%, expected=8`,
+			replicas:      pointer.Int32(9),
+			partition:     &intstr.IntOrString{Type: intstr.String, StrVal: "99%"},
+			expectedValue: 8,
+		},
+		{
+			name:          `replicas=10, partition=100%, expected=10`,
+			replicas:      pointer.Int32(10),
+			partition:     &intstr.IntOrString{Type: intstr.String, StrVal: "100%"},
+			expectedValue: 10,
+		},
+		{
+			name:          `replicas=10, partition=5, expected=5`,
+			replicas:      pointer.Int32(10),
+			partition:     &intstr.IntOrString{Type: intstr.Int, IntVal: 5},
+			expectedValue: 5,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			calculated, err := CalculatePartitionReplicasWithRounding(cs.partition, cs.replicas, false)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if calculated != cs.expectedValue {
+				t.Errorf("got %#v, expect %#v", calculated, cs.expectedValue)
+			}
+		})
+	}
+}
+
+func TestReservedOrdinals(t *testing.T) {
+	cases := []struct {
+		name                 string
+		reserveOrdinals      []int
+		reserveOrdinalRanges []string
+		expectedReserved     []int
+		expectedNext         map[int]int
+	}{
+		{
+			name:             "integers",
+			reserveOrdinals:  []int{1, 3},
+			expectedReserved: []int{1, 3},
+			expectedNext:     map[int]int{0: 0, 1: 2, 3: 4},
+		},
+		{
+			name:                 "ranges",
+			reserveOrdinals:      []int{0},
+			reserveOrdinalRanges: []string{"5-7", "9", "8-8"},
+			expectedReserved:     []int{0, 5, 6, 7, 8, 9},
+			expectedNext:         map[int]int{0: 1, 4: 4, 5: 10, 7: 10},
+		},
+		{
+			name:                 "invalid ranges ignored",
+			reserveOrdinalRanges: []string{"-1", "7-5", "a-b", "2-3"},
+			expectedReserved:     []int{2, 3},
+			expectedNext:         map[int]int{2: 4, 5: 5, 7: 7},
+		},
+		{
+			name:                 "huge range is not expanded",
+			reserveOrdinalRanges: []string{"1-1000000000"},
+			expectedReserved:     []int{1, 1000000000},
+			expectedNext:         map[int]int{0: 0, 1: 1000000001},
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			reserved := NewReservedOrdinals(cs.reserveOrdinals, cs.reserveOrdinalRanges)
+			for _, ordinal := range cs.expectedReserved {
+				if !reserved.Has(ordinal) {
+					t.Fatalf("expected %d reserved", ordinal)
+				}
+			}
+			for ordinal, expected := range cs.expectedNext {
+				if got := reserved.NextUnreserved(ordinal); got != expected {
+					t.Fatalf("expected next unreserved of %d is %d, got %d", ordinal, expected, got)
+				}
+			}
+		})
+	}
+}
@@ -24,6 +24,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -49,6 +50,10 @@ const (
 
 	PodDeletionCostPositive = 100
 	PodDeletionCostNegative = -100
+
+	// maxOwnerReferenceDepth is the max depth of the ownerReference chain from Pod to the custom workload,
+	// e.g. Argo Rollout -> ReplicaSet -> Pod.
+	maxOwnerReferenceDepth = 3
 )
 
 var (
@@ -133,6 +138,20 @@ func VerifyGroupKind(ref interface{}, expectedKind string, expectedGroups []stri
 	return false, nil
 }
 
+// IsCustomWorkload returns true if the targetRef is not one of the natively supported workloads,
+// which should be a custom workload exposing the scale subresource, such as Argo Rollouts.
+func IsCustomWorkload(target *appsv1alpha1.TargetReference) bool {
+	if matched, err := VerifyGroupKind(target, controllerKindDep.Kind, []string{controllerKindDep.Group}); err != nil || matched {
+		return false
+	}
+	for _, wl := range workloads {
+		if matched, err := VerifyGroupKind(target, wl.Kind, wl.Groups); err != nil || matched {
+			return false
+		}
+	}
+	return true
+}
+
 // matchReference return true if Pod has ownerReference matched workloads.
 func matchReference(ref *metav1.OwnerReference) (bool, error) {
 	if ref == nil {
@@ -159,13 +178,17 @@ func (h *Handler) HandlePodCreation(pod *corev1.Pod) error {
 	// 1. Deletion pod
 	// 2. Pod.Status.Phase = Succeeded or Failed
 	// 3. Pod.OwnerReference is nil
-	// 4. Pod.OwnerReference is not one of workloads, such as CloneSet, Deployment, ReplicaSet.
+	// 4. Pod.OwnerReference is not one of workloads, such as CloneSet, Deployment, ReplicaSet,
+	//    unless it is owned by the custom workload referenced by WorkloadSpread.
 	if !kubecontroller.IsPodActive(pod) {
 		return nil
 	}
 	ref := metav1.GetControllerOf(pod)
+	if ref == nil {
+		return nil
+	}
 	matched, err := matchReference(ref)
-	if err != nil || !matched {
+	if err != nil {
 		return nil
 	}
 
@@ -178,6 +201,9 @@ func (h *Handler) HandlePodCreation(pod *corev1.Pod) error {
 		if ws.Spec.TargetReference == nil || !ws.DeletionTimestamp.IsZero() {
 			continue
 		}
+		if !matched && !IsCustomWorkload(ws.Spec.TargetReference) {
+			continue
+		}
 		// determine if the reference of workloadSpread and pod is equal
 		if h.isReferenceEqual(ws.Spec.TargetReference, ref, pod.Namespace) {
 			matchedWS = &ws
@@ -259,7 +285,7 @@ func (h *Handler) mutatingPod(matchedWS *appsv1alpha1.WorkloadSpread,
 	var injectErr error
 	// if create pod, inject affinity、toleration、metadata in pod object
 	if operation == CreateOperation && len(suitableSubsetName) > 0 {
-		if _, injectErr = injectWorkloadSpreadIntoPod(matchedWS, pod, suitableSubsetName, generatedUID); injectErr != nil {
+		if _, injectErr = h.injectWorkloadSpreadIntoPod(matchedWS, pod, suitableSubsetName, generatedUID); injectErr != nil {
 			klog.Errorf("failed to inject Pod(%s/%s) subset(%s) data for WorkloadSpread(%s/%s)",
 				pod.Namespace, podName, suitableSubsetName, matchedWS.Namespace, matchedWS.Name)
 			return injectErr
@@ -478,7 +504,7 @@ func isPodRecordedInSubset(ws *appsv1alpha1.WorkloadSpread, podName string) (boo
 	return false, nil
 }
 
-func injectWorkloadSpreadIntoPod(ws *appsv1alpha1.WorkloadSpread, pod *corev1.Pod, subsetName string, generatedUID string) (bool, error) {
+func (h *Handler) injectWorkloadSpreadIntoPod(ws *appsv1alpha1.WorkloadSpread, pod *corev1.Pod, subsetName string, generatedUID string) (bool, error) {
 	var subset *appsv1alpha1.WorkloadSpreadSubset
 	for _, object := range ws.Spec.Subsets {
 		if subsetName == object.Name {
@@ -494,6 +520,20 @@ func injectWorkloadSpreadIntoPod(ws *appsv1alpha1.WorkloadSpread, pod *corev1.Po
 	if len(subset.Tolerations) > 0 {
 		pod.Spec.Tolerations = append(pod.Spec.Tolerations, subset.Tolerations...)
 	}
+	// inject priorityClassName, the priority and preemptionPolicy should also be injected, because they have been
+	// resolved from the priorityClassName of the workload template by Priority admission plugin before webhook.
+	if subset.PriorityClassName != "" {
+		priorityClass := &schedulingv1.PriorityClass{}
+		if err := h.Get(context.TODO(), client.ObjectKey{Name: subset.PriorityClassName}, priorityClass); err != nil {
+			klog.Errorf("failed to get PriorityClass %s of subset (%s) for WorkloadSpread (%s/%s): %s",
+				subset.PriorityClassName, subset.Name, ws.Namespace, ws.Name, err.Error())
+			return false, err
+		}
+		priority := priorityClass.Value
+		pod.Spec.PriorityClassName = priorityClass.Name
+		pod.Spec.Priority = &priority
+		pod.Spec.PreemptionPolicy = priorityClass.PreemptionPolicy
+	}
 	if pod.Spec.Affinity == nil {
 		pod.Spec.Affinity = &corev1.Affinity{}
 	}
@@ -593,6 +633,10 @@ func (h Handler) isReferenceEqual(target *appsv1alpha1.TargetReference, owner *m
 		return false
 	}
 
+	if IsCustomWorkload(target) {
+		return h.isOwnedByCustomWorkload(target, targetGv, owner, namespace)
+	}
+
 	var ownerGv schema.GroupVersion
 	if target.Kind == controllerKindDep.Kind {
 		rs := &appsv1.ReplicaSet{}
@@ -621,3 +665,37 @@ func (h Handler) isReferenceEqual(target *appsv1alpha1.TargetReference, owner *m
 
 	return targetGv.Group == ownerGv.Group && target.Kind == owner.Kind && target.Name == owner.Name
 }
+
+// isOwnedByCustomWorkload returns true if the custom workload referenced by target is found in the ownerReference chain
+// of Pod. The intermediate owners, such as the ReplicaSet created by Argo Rollout, must be the types registered in scheme,
+// so that they can be read from cache.
+func (h Handler) isOwnedByCustomWorkload(target *appsv1alpha1.TargetReference, targetGv schema.GroupVersion,
+	owner *metav1.OwnerReference, namespace string) bool {
+	for depth := 0; owner != nil && depth < maxOwnerReferenceDepth; depth++ {
+		ownerGv, err := schema.ParseGroupVersion(owner.APIVersion)
+		if err != nil {
+			klog.Errorf("parse OwnerReference apiVersion (%s) failed: %s", owner.APIVersion, err.Error())
+			return false
+		}
+		if targetGv.Group == ownerGv.Group && target.Kind == owner.Kind && target.Name == owner.Name {
+			return true
+		}
+
+		obj, err := h.Scheme().New(ownerGv.WithKind(owner.Kind))
+		if err != nil {
+			return false
+		}
+		ownerObj, ok := obj.(client.Object)
+		if !ok {
+			return false
+		}
+		if err = h.Get(context.TODO(), client.ObjectKey{Namespace: namespace, Name: owner.Name}, ownerObj); err != nil {
+			return false
+		}
+		if ownerObj.GetUID() != owner.UID {
+			return false
+		}
+		owner = metav1.GetControllerOf(ownerObj)
+	}
+	return false
+}
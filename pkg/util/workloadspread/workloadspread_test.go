@@ -24,7 +24,9 @@ import (
 	"testing"
 	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -876,6 +878,146 @@ func TestIsReferenceEqual(t *testing.T) {
 	}
 }
 
+func TestIsReferenceEqualForCustomWorkload(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	_ = appsv1.AddToScheme(testScheme)
+	rs := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rollout-test-5d8f7c",
+			Namespace: "default",
+			UID:       types.UID("b4e5a9d2-6a3e-4e5b-9f51-0f6c2a1d7e11"),
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: "argoproj.io/v1alpha1",
+					Kind:       "Rollout",
+					Name:       "rollout-test",
+					Controller: utilpointer.BoolPtr(true),
+				},
+			},
+		},
+	}
+	h := Handler{Client: fake.NewClientBuilder().WithScheme(testScheme).WithObjects(rs).Build()}
+	rolloutRef := &appsv1alpha1.TargetReference{
+		APIVersion: "argoproj.io/v1alpha1",
+		Kind:       "Rollout",
+		Name:       "rollout-test",
+	}
+
+	cases := []struct {
+		name        string
+		targetRef   *appsv1alpha1.TargetReference
+		ownerRef    *metav1.OwnerReference
+		expectEqual bool
+	}{
+		{
+			name:      "pod owned by custom workload directly",
+			targetRef: rolloutRef,
+			ownerRef: &metav1.OwnerReference{
+				APIVersion: "argoproj.io/v1alpha1",
+				Kind:       "Rollout",
+				Name:       "rollout-test",
+			},
+			expectEqual: true,
+		},
+		{
+			name:      "pod owned by custom workload via ReplicaSet",
+			targetRef: rolloutRef,
+			ownerRef: &metav1.OwnerReference{
+				APIVersion: "apps/v1",
+				Kind:       "ReplicaSet",
+				Name:       rs.Name,
+				UID:        rs.UID,
+			},
+			expectEqual: true,
+		},
+		{
+			name:      "ReplicaSet uid not equal",
+			targetRef: rolloutRef,
+			ownerRef: &metav1.OwnerReference{
+				APIVersion: "apps/v1",
+				Kind:       "ReplicaSet",
+				Name:       rs.Name,
+				UID:        types.UID("c0ffee00-0000-0000-0000-000000000000"),
+			},
+			expectEqual: false,
+		},
+		{
+			name: "custom workload name not equal",
+			targetRef: &appsv1alpha1.TargetReference{
+				APIVersion: "argoproj.io/v1alpha1",
+				Kind:       "Rollout",
+				Name:       "rollout-other",
+			},
+			ownerRef: &metav1.OwnerReference{
+				APIVersion: "apps/v1",
+				Kind:       "ReplicaSet",
+				Name:       rs.Name,
+				UID:        rs.UID,
+			},
+			expectEqual: false,
+		},
+		{
+			name:      "owner not registered in scheme",
+			targetRef: rolloutRef,
+			ownerRef: &metav1.OwnerReference{
+				APIVersion: "example.io/v1",
+				Kind:       "Unknown",
+				Name:       "unknown-test",
+			},
+			expectEqual: false,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			if !IsCustomWorkload(cs.targetRef) {
+				t.Fatalf("expect custom workload")
+			}
+			if h.isReferenceEqual(cs.targetRef, cs.ownerRef, "default") != cs.expectEqual {
+				t.Fatalf("isReferenceEqual failed")
+			}
+		})
+	}
+}
+
+func TestInjectSubsetPriorityClass(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	_ = schedulingv1.AddToScheme(testScheme)
+	preemptNever := corev1.PreemptNever
+	priorityClass := &schedulingv1.PriorityClass{
+		ObjectMeta:       metav1.ObjectMeta{Name: "spot-low"},
+		Value:            -10,
+		PreemptionPolicy: &preemptNever,
+	}
+	h := Handler{Client: fake.NewClientBuilder().WithScheme(testScheme).WithObjects(priorityClass).Build()}
+
+	ws := workloadSpreadDemo.DeepCopy()
+	ws.Spec.Subsets[0].Tolerations = []corev1.Toleration{
+		{Key: "node.kubernetes.io/spot", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	}
+	ws.Spec.Subsets[0].PriorityClassName = priorityClass.Name
+	pod := podDemo.DeepCopy()
+	pod.Spec.PriorityClassName = "default-high"
+	pod.Spec.Priority = utilpointer.Int32Ptr(1000)
+
+	if _, err := h.injectWorkloadSpreadIntoPod(ws, pod, ws.Spec.Subsets[0].Name, ""); err != nil {
+		t.Fatalf("inject subset into pod failed: %s", err.Error())
+	}
+	if len(pod.Spec.Tolerations) != 1 || pod.Spec.Tolerations[0].Key != "node.kubernetes.io/spot" {
+		t.Fatalf("expect toleration injected, but got %v", pod.Spec.Tolerations)
+	}
+	if pod.Spec.PriorityClassName != priorityClass.Name || pod.Spec.Priority == nil || *pod.Spec.Priority != -10 ||
+		pod.Spec.PreemptionPolicy == nil || *pod.Spec.PreemptionPolicy != corev1.PreemptNever {
+		t.Fatalf("expect priority class %s injected, but got %s %v %v", priorityClass.Name,
+			pod.Spec.PriorityClassName, pod.Spec.Priority, pod.Spec.PreemptionPolicy)
+	}
+
+	ws.Spec.Subsets[0].PriorityClassName = "not-found"
+	if _, err := h.injectWorkloadSpreadIntoPod(ws, podDemo.DeepCopy(), ws.Spec.Subsets[0].Name, ""); err == nil {
+		t.Fatalf("expect error for the PriorityClass not found")
+	}
+}
+
 func TestPatchMetadata(t *testing.T) {
 	pod := &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
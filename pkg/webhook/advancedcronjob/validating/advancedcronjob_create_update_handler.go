@@ -21,8 +21,11 @@ import (
 	"fmt"
 	"net/http"
 	"regexp"
+	"strings"
+	"time"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	imagepulljobvalidating "github.com/openkruise/kruise/pkg/webhook/imagepulljob/validating"
 	webhookutil "github.com/openkruise/kruise/pkg/webhook/util"
 	"github.com/robfig/cron"
 	admissionv1 "k8s.io/api/admission/v1"
@@ -30,6 +33,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	genericvalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	validationutil "k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/kubernetes/pkg/apis/core"
@@ -90,6 +94,16 @@ func validateAdvancedCronJobSpecSchedule(spec *appsv1alpha1.AdvancedCronJobSpec,
 		allErrs = append(allErrs, field.Invalid(fldPath.Child("schedule"),
 			spec.Schedule, err.Error()))
 	}
+
+	if spec.TimeZone != nil {
+		if len(*spec.TimeZone) == 0 || strings.EqualFold(*spec.TimeZone, "Local") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("timeZone"),
+				*spec.TimeZone, "timeZone must be an explicit time zone name, such as Asia/Shanghai"))
+		} else if _, err := time.LoadLocation(*spec.TimeZone); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("timeZone"),
+				*spec.TimeZone, err.Error()))
+		}
+	}
 	return allErrs
 }
 
@@ -106,12 +120,22 @@ func validateAdvancedCronJobSpecTemplate(spec *appsv1alpha1.AdvancedCronJobSpec,
 		allErrs = append(allErrs, validateBroadcastJobTemplateSpec(spec.Template.BroadcastJobTemplate, fldPath)...)
 	}
 
+	if spec.Template.ImagePullJobTemplate != nil {
+		templateCount++
+		allErrs = append(allErrs, validateImagePullJobTemplateSpec(spec.Template.ImagePullJobTemplate, fldPath.Child("template", "imagePullJobTemplate"))...)
+	}
+
+	if spec.Template.EphemeralJobTemplate != nil {
+		templateCount++
+		allErrs = append(allErrs, validateEphemeralJobTemplateSpec(spec.Template.EphemeralJobTemplate, fldPath.Child("template", "ephemeralJobTemplate"))...)
+	}
+
 	if templateCount == 0 {
 		allErrs = append(allErrs, field.Forbidden(fldPath.Child("template"),
-			"spec must have one template, either JobTemplate or BroadcastJobTemplate should be provided"))
+			"spec must have one template, one of JobTemplate, BroadcastJobTemplate, ImagePullJobTemplate and EphemeralJobTemplate should be provided"))
 	} else if templateCount > 1 {
 		allErrs = append(allErrs, field.Forbidden(fldPath.Child("template"),
-			"spec can have only one template, either JobTemplate or BroadcastJobTemplate should be provided"))
+			"spec can have only one template, one of JobTemplate, BroadcastJobTemplate, ImagePullJobTemplate and EphemeralJobTemplate should be provided"))
 	}
 	return allErrs
 }
@@ -136,6 +160,40 @@ func validateBroadcastJobTemplateSpec(brJobSpec *appsv1alpha1.BroadcastJobTempla
 	return append(allErrs, corevalidation.ValidatePodTemplateSpec(coreTemplate, fldPath.Child("template"), webhookutil.DefaultPodValidationOptions)...)
 }
 
+func validateImagePullJobTemplateSpec(imagePullJobSpec *appsv1alpha1.ImagePullJobTemplateSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	spec := &imagePullJobSpec.Spec
+	if err := imagepulljobvalidating.ValidateImagePullJobSpec(spec); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("spec"), spec, err.Error()))
+	}
+	if spec.CompletionPolicy.Type == appsv1alpha1.Never {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("spec", "completionPolicy", "type"),
+			"imagepulljob created by advancedcronjob must complete"))
+	}
+	return allErrs
+}
+
+func validateEphemeralJobTemplateSpec(ephemeralJobSpec *appsv1alpha1.EphemeralJobTemplateSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	spec := &ephemeralJobSpec.Spec
+	if spec.Selector == nil {
+		allErrs = append(allErrs, field.Required(fldPath.Child("spec", "selector"), "selector can not be empty"))
+	} else if _, err := metav1.LabelSelectorAsSelector(spec.Selector); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("spec", "selector"), spec.Selector, err.Error()))
+	}
+	if len(spec.Template.EphemeralContainers) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("spec", "template", "ephemeralContainers"), "ephemeralContainers can not be empty"))
+	}
+	for i, c := range spec.Template.EphemeralContainers {
+		// the name will be suffixed with the scheduled time in unix seconds
+		if errs := validationutil.IsDNS1123Label(c.Name + "-1234567890"); len(errs) > 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("spec", "template", "ephemeralContainers").Index(i).Child("name"),
+				c.Name, strings.Join(errs, "; ")))
+		}
+	}
+	return allErrs
+}
+
 func convertPodTemplateSpec(template *v1.PodTemplateSpec) (*core.PodTemplateSpec, error) {
 	coreTemplate := &core.PodTemplateSpec{}
 	if err := corev1.Convert_v1_PodTemplateSpec_To_core_PodTemplateSpec(template.DeepCopy(), coreTemplate, nil); err != nil {
@@ -160,13 +218,14 @@ func (h *AdvancedCronJobCreateUpdateHandler) validateAdvancedCronJobUpdate(obj,
 
 	advanceCronJob := obj.DeepCopy()
 	advanceCronJob.Spec.Schedule = oldObj.Spec.Schedule
+	advanceCronJob.Spec.TimeZone = oldObj.Spec.TimeZone
 	advanceCronJob.Spec.ConcurrencyPolicy = oldObj.Spec.ConcurrencyPolicy
 	advanceCronJob.Spec.SuccessfulJobsHistoryLimit = oldObj.Spec.SuccessfulJobsHistoryLimit
 	advanceCronJob.Spec.FailedJobsHistoryLimit = oldObj.Spec.FailedJobsHistoryLimit
 	advanceCronJob.Spec.StartingDeadlineSeconds = oldObj.Spec.StartingDeadlineSeconds
 	advanceCronJob.Spec.Paused = oldObj.Spec.Paused
 	if !apiequality.Semantic.DeepEqual(advanceCronJob.Spec, oldObj.Spec) {
-		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec"), "updates to advancedcronjob spec for fields other than 'schedule', 'concurrencyPolicy', 'successfulJobsHistoryLimit', 'failedJobsHistoryLimit', 'startingDeadlineSeconds' and 'paused' are forbidden"))
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec"), "updates to advancedcronjob spec for fields other than 'schedule', 'timeZone', 'concurrencyPolicy', 'successfulJobsHistoryLimit', 'failedJobsHistoryLimit', 'startingDeadlineSeconds' and 'paused' are forbidden"))
 	}
 	return allErrs
 }
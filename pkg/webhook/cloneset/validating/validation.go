@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	appspub "github.com/openkruise/kruise/apis/apps/pub"
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
 	clonesetcore "github.com/openkruise/kruise/pkg/controller/cloneset/core"
 	"github.com/openkruise/kruise/pkg/util"
@@ -91,7 +92,52 @@ func (h *CloneSetCreateUpdateHandler) validateCloneSetSpec(spec, oldSpec *appsv1
 
 	allErrs = append(allErrs, h.validateScaleStrategy(&spec.ScaleStrategy, oldScaleStrategy, metadata, fldPath.Child("scaleStrategy"))...)
 	allErrs = append(allErrs, h.validateUpdateStrategy(&spec.UpdateStrategy, int(*spec.Replicas), fldPath.Child("updateStrategy"))...)
+	switch spec.PodNamingPolicy {
+	case "", appsv1alpha1.CloneSetRandomPodNaming, appsv1alpha1.CloneSetOrdinalPodNaming:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("podNamingPolicy"), spec.PodNamingPolicy,
+			[]string{string(appsv1alpha1.CloneSetRandomPodNaming), string(appsv1alpha1.CloneSetOrdinalPodNaming)}))
+	}
+	if spec.ProgressDeadlineSeconds != nil {
+		allErrs = append(allErrs, apivalidation.ValidateNonnegativeField(int64(*spec.ProgressDeadlineSeconds), fldPath.Child("progressDeadlineSeconds"))...)
+		if *spec.ProgressDeadlineSeconds <= spec.MinReadySeconds {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("progressDeadlineSeconds"), *spec.ProgressDeadlineSeconds, "must be greater than minReadySeconds"))
+		}
+	}
+	switch spec.VolumeClaimUpdateStrategy.Type {
+	case "", appsv1alpha1.OnDeleteVolumeClaimUpdateStrategyType, appsv1alpha1.ExpandVolumeClaimUpdateStrategyType:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("volumeClaimUpdateStrategy", "type"), spec.VolumeClaimUpdateStrategy.Type,
+			[]string{string(appsv1alpha1.OnDeleteVolumeClaimUpdateStrategyType), string(appsv1alpha1.ExpandVolumeClaimUpdateStrategyType)}))
+	}
+	switch spec.VolumeClaimUpdateStrategy.ReusePolicy {
+	case "", appsv1alpha1.NeverVolumeClaimReusePolicyType, appsv1alpha1.OnRecreateVolumeClaimReusePolicyType:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("volumeClaimUpdateStrategy", "reusePolicy"), spec.VolumeClaimUpdateStrategy.ReusePolicy,
+			[]string{string(appsv1alpha1.NeverVolumeClaimReusePolicyType), string(appsv1alpha1.OnRecreateVolumeClaimReusePolicyType)}))
+	}
+	if spec.Lifecycle != nil {
+		allErrs = append(allErrs, validateLifecycleHook(spec.Lifecycle.PreDelete, fldPath.Child("lifecycle", "preDelete"))...)
+		allErrs = append(allErrs, validateLifecycleHook(spec.Lifecycle.InPlaceUpdate, fldPath.Child("lifecycle", "inPlaceUpdate"))...)
+	}
+
+	return allErrs
+}
 
+func validateLifecycleHook(hook *appspub.LifecycleHook, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if hook == nil {
+		return allErrs
+	}
+	if hook.TimeoutSeconds != nil {
+		allErrs = append(allErrs, apivalidation.ValidateNonnegativeField(int64(*hook.TimeoutSeconds), fldPath.Child("timeoutSeconds"))...)
+	}
+	switch hook.FailurePolicy {
+	case "", appspub.LifecycleHookFailurePolicyContinue, appspub.LifecycleHookFailurePolicyBlock:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("failurePolicy"), hook.FailurePolicy,
+			[]string{string(appspub.LifecycleHookFailurePolicyContinue), string(appspub.LifecycleHookFailurePolicyBlock)}))
+	}
 	return allErrs
 }
 
@@ -103,6 +149,14 @@ func (h *CloneSetCreateUpdateHandler) validateScaleStrategy(strategy, oldStrateg
 		return allErrs
 	}
 
+	if strategy.PodsToDeleteSelector != nil {
+		allErrs = append(allErrs, unversionedvalidation.ValidateLabelSelector(strategy.PodsToDeleteSelector, fldPath.Child("podsToDeleteSelector"))...)
+	}
+
+	if err := strategy.RankingPolicy.FieldsValidation(); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("rankingPolicy"), strategy.RankingPolicy, err.Error()))
+	}
+
 	podsToDeleteSet := sets.NewString(strategy.PodsToDelete...)
 
 	if oldStrategy != nil && len(oldStrategy.PodsToDelete) > 0 {
@@ -144,6 +198,33 @@ func (h *CloneSetCreateUpdateHandler) validateUpdateStrategy(strategy *appsv1alp
 			fmt.Sprintf("failed getValueFromIntOrPercent for partition: %v", err)))
 	}
 	allErrs = append(allErrs, apivalidation.ValidateNonnegativeField(int64(partition), fldPath.Child("partition"))...)
+	switch strategy.PartitionRounding {
+	case "", appsv1alpha1.CloneSetPartitionRoundUp, appsv1alpha1.CloneSetPartitionRoundDown:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("partitionRounding"), strategy.PartitionRounding,
+			[]string{string(appsv1alpha1.CloneSetPartitionRoundUp), string(appsv1alpha1.CloneSetPartitionRoundDown)}))
+	}
+
+	var lastStepReplicas int
+	for i := range strategy.Steps {
+		step := &strategy.Steps[i]
+		stepPath := fldPath.Child("steps").Index(i)
+		stepReplicas, err := intstrutil.GetScaledValueFromIntOrPercent(&step.Replicas, replicas, true)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(stepPath.Child("replicas"), step.Replicas.String(),
+				fmt.Sprintf("failed getValueFromIntOrPercent for replicas: %v", err)))
+			continue
+		}
+		allErrs = append(allErrs, apivalidation.ValidateNonnegativeField(int64(stepReplicas), stepPath.Child("replicas"))...)
+		if stepReplicas < lastStepReplicas {
+			allErrs = append(allErrs, field.Invalid(stepPath.Child("replicas"), step.Replicas.String(),
+				"replicas of steps should not be decreasing"))
+		}
+		lastStepReplicas = stepReplicas
+		if step.Pause.Duration != nil {
+			allErrs = append(allErrs, apivalidation.ValidateNonnegativeField(int64(*step.Pause.Duration), stepPath.Child("pause", "duration"))...)
+		}
+	}
 
 	if err := strategy.PriorityStrategy.FieldsValidation(); err != nil {
 		allErrs = append(allErrs, field.Required(fldPath.Child("priorityStrategy"), err.Error()))
@@ -180,6 +261,55 @@ func (h *CloneSetCreateUpdateHandler) validateUpdateStrategy(strategy *appsv1alp
 			"maxUnavailable and maxSurge should not both be less than 1"))
 	}
 
+	allErrs = append(allErrs, apivalidation.ValidateNonnegativeField(int64(strategy.BatchPauseSeconds), fldPath.Child("batchPauseSeconds"))...)
+
+	for i, key := range strategy.InPlaceMetadataKeys {
+		allErrs = append(allErrs, unversionedvalidation.ValidateLabelName(key, fldPath.Child("inPlaceMetadataKeys").Index(i))...)
+	}
+
+	if rollback := strategy.RollbackTo; rollback != nil {
+		if rollback.Revision == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("rollbackTo", "revision"), ""))
+		}
+		if rollback.MaxUnavailable != nil {
+			rollbackMaxUnavailable, err := intstrutil.GetValueFromIntOrPercent(rollback.MaxUnavailable, replicas, true)
+			if err != nil {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("rollbackTo", "maxUnavailable"), rollback.MaxUnavailable.String(),
+					fmt.Sprintf("failed getValueFromIntOrPercent for maxUnavailable: %v", err)))
+			} else if replicas > 0 && rollbackMaxUnavailable < 1 && maxSurge < 1 {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("rollbackTo", "maxUnavailable"), rollback.MaxUnavailable,
+					"maxUnavailable and maxSurge should not both be less than 1"))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// validateVolumeClaimTemplatesExpansion only allows to increase the storage requests in volumeClaimTemplates.
+func validateVolumeClaimTemplatesExpansion(templates, oldTemplates []v1.PersistentVolumeClaim, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if len(templates) != len(oldTemplates) {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "volumeClaimTemplates can not be added or removed"))
+		return allErrs
+	}
+
+	for i := range templates {
+		storage := templates[i].Spec.Resources.Requests[v1.ResourceStorage]
+		oldStorage := oldTemplates[i].Spec.Resources.Requests[v1.ResourceStorage]
+		if storage.Cmp(oldStorage) < 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("spec", "resources", "requests", string(v1.ResourceStorage)), storage.String(), "storage requests can not be decreased"))
+			continue
+		}
+
+		clone := templates[i].DeepCopy()
+		if _, ok := oldTemplates[i].Spec.Resources.Requests[v1.ResourceStorage]; ok {
+			clone.Spec.Resources.Requests[v1.ResourceStorage] = oldStorage
+		}
+		if !apiequality.Semantic.DeepEqual(clone, &oldTemplates[i]) {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Index(i), "updates to volumeClaimTemplates for fields other than storage requests are forbidden"))
+		}
+	}
 	return allErrs
 }
 
@@ -194,8 +324,14 @@ func (h *CloneSetCreateUpdateHandler) validateCloneSetUpdate(cloneSet, oldCloneS
 	clone.Spec.MinReadySeconds = oldCloneSet.Spec.MinReadySeconds
 	clone.Spec.Lifecycle = oldCloneSet.Spec.Lifecycle
 	clone.Spec.RevisionHistoryLimit = oldCloneSet.Spec.RevisionHistoryLimit
+	clone.Spec.ProgressDeadlineSeconds = oldCloneSet.Spec.ProgressDeadlineSeconds
+	clone.Spec.VolumeClaimUpdateStrategy = oldCloneSet.Spec.VolumeClaimUpdateStrategy
+	if cloneSet.Spec.VolumeClaimUpdateStrategy.Type == appsv1alpha1.ExpandVolumeClaimUpdateStrategyType {
+		clone.Spec.VolumeClaimTemplates = oldCloneSet.Spec.VolumeClaimTemplates
+		allErrs = append(allErrs, validateVolumeClaimTemplatesExpansion(cloneSet.Spec.VolumeClaimTemplates, oldCloneSet.Spec.VolumeClaimTemplates, field.NewPath("spec", "volumeClaimTemplates"))...)
+	}
 	if !apiequality.Semantic.DeepEqual(clone.Spec, oldCloneSet.Spec) {
-		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec"), "updates to cloneset spec for fields other than 'replicas', 'template', 'lifecycle', 'scaleStrategy', 'updateStrategy', 'minReadySeconds' and 'revisionHistoryLimit' are forbidden"))
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec"), "updates to cloneset spec for fields other than 'replicas', 'template', 'lifecycle', 'scaleStrategy', 'updateStrategy', 'minReadySeconds', 'revisionHistoryLimit', 'volumeClaimUpdateStrategy' and 'progressDeadlineSeconds' are forbidden"))
 	}
 
 	coreControl := clonesetcore.New(cloneSet)
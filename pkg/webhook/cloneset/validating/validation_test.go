@@ -10,9 +10,11 @@ import (
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
 	"github.com/openkruise/kruise/pkg/util"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -316,6 +318,34 @@ func TestValidate(t *testing.T) {
 				},
 			},
 		},
+		"invalid-partitionRounding": {
+			spec: &appsv1alpha1.CloneSetSpec{
+				Replicas: &val1,
+				Selector: &metav1.LabelSelector{MatchLabels: validLabels},
+				Template: validPodTemplate.Template,
+				UpdateStrategy: appsv1alpha1.CloneSetUpdateStrategy{
+					Type:              appsv1alpha1.InPlaceIfPossibleCloneSetUpdateStrategyType,
+					Partition:         util.GetIntOrStrPointer(intstr.FromString("20%")),
+					PartitionRounding: "Nearest",
+					MaxUnavailable:    &intOrStr1,
+				},
+			},
+		},
+		"invalid-steps": {
+			spec: &appsv1alpha1.CloneSetSpec{
+				Replicas: &val1,
+				Selector: &metav1.LabelSelector{MatchLabels: validLabels},
+				Template: validPodTemplate.Template,
+				UpdateStrategy: appsv1alpha1.CloneSetUpdateStrategy{
+					Type:           appsv1alpha1.InPlaceIfPossibleCloneSetUpdateStrategyType,
+					MaxUnavailable: &intOrStr1,
+					Steps: []appsv1alpha1.CloneSetUpdateStep{
+						{Replicas: intstr.FromInt(5)},
+						{Replicas: intstr.FromInt(2)},
+					},
+				},
+			},
+		},
 		"invalid-maxUnavailable": {
 			spec: &appsv1alpha1.CloneSetSpec{
 				Replicas: &val1,
@@ -434,3 +464,55 @@ func TestValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateVolumeClaimTemplatesExpansion(t *testing.T) {
+	newTemplate := func(storage string, accessMode v1.PersistentVolumeAccessMode) v1.PersistentVolumeClaim {
+		return v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "data"},
+			Spec: v1.PersistentVolumeClaimSpec{
+				AccessModes: []v1.PersistentVolumeAccessMode{accessMode},
+				Resources:   v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse(storage)}},
+			},
+		}
+	}
+
+	cases := []struct {
+		name         string
+		templates    []v1.PersistentVolumeClaim
+		oldTemplates []v1.PersistentVolumeClaim
+		expectErr    bool
+	}{
+		{
+			name:         "increase storage",
+			templates:    []v1.PersistentVolumeClaim{newTemplate("20Gi", v1.ReadWriteOnce)},
+			oldTemplates: []v1.PersistentVolumeClaim{newTemplate("10Gi", v1.ReadWriteOnce)},
+		},
+		{
+			name:         "decrease storage",
+			templates:    []v1.PersistentVolumeClaim{newTemplate("5Gi", v1.ReadWriteOnce)},
+			oldTemplates: []v1.PersistentVolumeClaim{newTemplate("10Gi", v1.ReadWriteOnce)},
+			expectErr:    true,
+		},
+		{
+			name:         "update access modes",
+			templates:    []v1.PersistentVolumeClaim{newTemplate("20Gi", v1.ReadWriteMany)},
+			oldTemplates: []v1.PersistentVolumeClaim{newTemplate("10Gi", v1.ReadWriteOnce)},
+			expectErr:    true,
+		},
+		{
+			name:         "add template",
+			templates:    []v1.PersistentVolumeClaim{newTemplate("10Gi", v1.ReadWriteOnce), newTemplate("10Gi", v1.ReadWriteOnce)},
+			oldTemplates: []v1.PersistentVolumeClaim{newTemplate("10Gi", v1.ReadWriteOnce)},
+			expectErr:    true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := validateVolumeClaimTemplatesExpansion(tc.templates, tc.oldTemplates, field.NewPath("spec", "volumeClaimTemplates"))
+			if (len(errs) > 0) != tc.expectErr {
+				t.Fatalf("expected error %v, got %v", tc.expectErr, errs)
+			}
+		})
+	}
+}
@@ -104,12 +104,23 @@ func (h *ContainerRecreateRequestHandler) Handle(ctx context.Context, req admiss
 	if obj.Spec.ActiveDeadlineSeconds != nil && *obj.Spec.ActiveDeadlineSeconds < minDeadlineSeconds {
 		return admission.Errored(http.StatusBadRequest, fmt.Errorf("activeDeadlineSeconds can not be less than %ds", minDeadlineSeconds))
 	}
+	if obj.Spec.TTLSecondsAfterFinished != nil && *obj.Spec.TTLSecondsAfterFinished < 0 {
+		return admission.Errored(http.StatusBadRequest, fmt.Errorf("ttlSecondsAfterFinished must be non-negative integer"))
+	}
 	if obj.Spec.Strategy.TerminationGracePeriodSeconds != nil && *obj.Spec.Strategy.TerminationGracePeriodSeconds < 0 {
 		return admission.Errored(http.StatusBadRequest, fmt.Errorf("terminationGracePeriodSeconds must be non-negative integer"))
 	}
 	if obj.Spec.Strategy.UnreadyGracePeriodSeconds != nil && *obj.Spec.Strategy.UnreadyGracePeriodSeconds < 0 {
 		return admission.Errored(http.StatusBadRequest, fmt.Errorf("unreadyGracePeriodSeconds must be non-negative integer"))
 	}
+	if obj.Spec.Strategy.RetryBackoffSeconds != nil && *obj.Spec.Strategy.RetryBackoffSeconds < 0 {
+		return admission.Errored(http.StatusBadRequest, fmt.Errorf("retryBackoffSeconds must be non-negative integer"))
+	}
+	for i := range obj.Spec.Containers {
+		if err := ValidateContainerRecreateRequestContainer(&obj.Spec.Containers[i]); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+	}
 
 	// defaults
 	switch obj.Spec.Strategy.FailurePolicy {
@@ -138,6 +149,9 @@ func (h *ContainerRecreateRequestHandler) Handle(ctx context.Context, req admiss
 		return admission.Errored(http.StatusBadRequest, err)
 	}
 
+	// Note that PodUnavailableBudget is checked by kruise-manager after the CRR created, and kruise-daemon
+	// waits for it before recreating the containers, so the CRR will be pending rather than denied if the budget is exhausted now.
+
 	if reflect.DeepEqual(obj, copy) {
 		return admission.Allowed("")
 	}
@@ -148,6 +162,24 @@ func (h *ContainerRecreateRequestHandler) Handle(ctx context.Context, req admiss
 	return admission.PatchResponseFromRaw(req.AdmissionRequest.Object.Raw, marshalled)
 }
 
+// ValidateContainerRecreateRequestContainer validates the container to recreate, which is shared by ContainerRecreateRequest
+// and the template of ContainerRecreateRequestSet.
+func ValidateContainerRecreateRequestContainer(c *appsv1alpha1.ContainerRecreateRequestContainer) error {
+	if c.MinStartedSeconds != nil && *c.MinStartedSeconds < 0 {
+		return fmt.Errorf("minStartedSeconds of container %s must be non-negative integer", c.Name)
+	}
+	if c.ReadinessTimeoutSeconds != nil && *c.ReadinessTimeoutSeconds <= 0 {
+		return fmt.Errorf("readinessTimeoutSeconds of container %s must be positive integer", c.Name)
+	}
+	if c.MaxRetries < 0 {
+		return fmt.Errorf("maxRetries of container %s must be non-negative integer", c.Name)
+	}
+	if c.TerminationGracePeriodSeconds != nil && *c.TerminationGracePeriodSeconds < 0 {
+		return fmt.Errorf("terminationGracePeriodSeconds of container %s must be non-negative integer", c.Name)
+	}
+	return nil
+}
+
 func injectPodIntoContainerRecreateRequest(obj *appsv1alpha1.ContainerRecreateRequest, pod *v1.Pod) error {
 	obj.Labels[appsv1alpha1.ContainerRecreateRequestNodeNameKey] = pod.Spec.NodeName
 	obj.Labels[appsv1alpha1.ContainerRecreateRequestPodUIDKey] = string(pod.UID)
@@ -166,6 +198,10 @@ func injectPodIntoContainerRecreateRequest(obj *appsv1alpha1.ContainerRecreateRe
 		if c.PreStop != nil || c.Ports != nil || c.StatusContext != nil {
 			return fmt.Errorf("preStop, ports, statusContext in container are ready-only fields")
 		}
+		// the signal is sent to PID 1 of the container, which is the pause process if the process namespace is shared
+		if c.Signal != "" && pod.Spec.ShareProcessNamespace != nil && *pod.Spec.ShareProcessNamespace {
+			return fmt.Errorf("signal of container %s is not allowed for Pod with shareProcessNamespace", c.Name)
+		}
 
 		podContainer := util.GetContainer(c.Name, pod)
 		if podContainer == nil {
@@ -0,0 +1,152 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validating
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/features"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
+	crrmutating "github.com/openkruise/kruise/pkg/webhook/containerrecreaterequest/mutating"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	minDeadlineSeconds = 3
+)
+
+// ContainerRecreateRequestSetCreateUpdateHandler handles ContainerRecreateRequestSet
+type ContainerRecreateRequestSetCreateUpdateHandler struct {
+	// Decoder decodes objects
+	Decoder *admission.Decoder
+}
+
+var _ admission.Handler = &ContainerRecreateRequestSetCreateUpdateHandler{}
+
+// Handle handles admission requests.
+func (h *ContainerRecreateRequestSetCreateUpdateHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	obj := &appsv1alpha1.ContainerRecreateRequestSet{}
+
+	err := h.Decoder.Decode(req, obj)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if !utilfeature.DefaultFeatureGate.Enabled(features.KruiseDaemon) {
+		return admission.Errored(http.StatusForbidden, fmt.Errorf("feature-gate %s is not enabled", features.KruiseDaemon))
+	}
+
+	if req.AdmissionRequest.Operation == admissionv1.Update {
+		oldObj := &appsv1alpha1.ContainerRecreateRequestSet{}
+		if err := h.Decoder.DecodeRaw(req.OldObject, oldObj); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if !reflect.DeepEqual(obj.Spec.Selector, oldObj.Spec.Selector) || !reflect.DeepEqual(obj.Spec.Template, oldObj.Spec.Template) ||
+			obj.Spec.TopologyKey != oldObj.Spec.TopologyKey {
+			return admission.Errored(http.StatusForbidden, fmt.Errorf("selector, template and topologyKey of ContainerRecreateRequestSet are immutable"))
+		}
+	}
+
+	if err := validate(obj); err != nil {
+		klog.Warningf("Error validate ContainerRecreateRequestSet %s/%s: %v", obj.Namespace, obj.Name, err)
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	return admission.ValidationResponse(true, "allowed")
+}
+
+func validate(obj *appsv1alpha1.ContainerRecreateRequestSet) error {
+	if obj.Spec.Selector == nil {
+		return fmt.Errorf("selector can not be empty")
+	}
+	selector, err := metav1.LabelSelectorAsSelector(obj.Spec.Selector)
+	if err != nil {
+		return fmt.Errorf("invalid selector: %v", err)
+	}
+	if selector.Empty() {
+		return fmt.Errorf("selector can not select all pods")
+	}
+
+	template := &obj.Spec.Template
+	if len(template.Containers) == 0 {
+		return fmt.Errorf("containers list can not be null")
+	}
+	names := sets.NewString()
+	for i := range template.Containers {
+		c := &template.Containers[i]
+		if c.Name == "" {
+			return fmt.Errorf("container name can not be empty")
+		}
+		if names.Has(c.Name) {
+			return fmt.Errorf("duplicated container %s", c.Name)
+		}
+		names.Insert(c.Name)
+		if err := crrmutating.ValidateContainerRecreateRequestContainer(c); err != nil {
+			return err
+		}
+	}
+	if template.ActiveDeadlineSeconds != nil && *template.ActiveDeadlineSeconds < minDeadlineSeconds {
+		return fmt.Errorf("activeDeadlineSeconds can not be less than %ds", minDeadlineSeconds)
+	}
+	if strategy := template.Strategy; strategy != nil {
+		if strategy.TerminationGracePeriodSeconds != nil && *strategy.TerminationGracePeriodSeconds < 0 {
+			return fmt.Errorf("terminationGracePeriodSeconds must be non-negative integer")
+		}
+		if strategy.UnreadyGracePeriodSeconds != nil && *strategy.UnreadyGracePeriodSeconds < 0 {
+			return fmt.Errorf("unreadyGracePeriodSeconds must be non-negative integer")
+		}
+		if strategy.RetryBackoffSeconds != nil && *strategy.RetryBackoffSeconds < 0 {
+			return fmt.Errorf("retryBackoffSeconds must be non-negative integer")
+		}
+		switch strategy.FailurePolicy {
+		case "", appsv1alpha1.ContainerRecreateRequestFailurePolicyFail, appsv1alpha1.ContainerRecreateRequestFailurePolicyIgnore:
+		default:
+			return fmt.Errorf("unknown failurePolicy %s", strategy.FailurePolicy)
+		}
+	}
+
+	if obj.Spec.MaxUnavailable != nil {
+		maxUnavailable, err := intstr.GetScaledValueFromIntOrPercent(obj.Spec.MaxUnavailable, 100, true)
+		if err != nil {
+			return fmt.Errorf("invalid maxUnavailable: %v", err)
+		}
+		if maxUnavailable < 0 {
+			return fmt.Errorf("maxUnavailable can not be negative")
+		}
+	}
+	if obj.Spec.TTLSecondsAfterFinished != nil && *obj.Spec.TTLSecondsAfterFinished < 0 {
+		return fmt.Errorf("ttlSecondsAfterFinished can not be negative")
+	}
+
+	return nil
+}
+
+var _ admission.DecoderInjector = &ContainerRecreateRequestSetCreateUpdateHandler{}
+
+// InjectDecoder injects the decoder into the ContainerRecreateRequestSetCreateUpdateHandler
+func (h *ContainerRecreateRequestSetCreateUpdateHandler) InjectDecoder(d *admission.Decoder) error {
+	h.Decoder = d
+	return nil
+}
@@ -31,6 +31,7 @@ import (
 	metavalidation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/klog/v2"
@@ -112,6 +113,23 @@ func validateDaemonSetSpec(spec *appsv1alpha1.DaemonSetSpec, fldPath *field.Path
 		if spec.Lifecycle.InPlaceUpdate != nil {
 			allErrs = append(allErrs, field.Forbidden(fldPath.Child("lifecycle", "inPlaceUpdate"), "inPlaceUpdate hook has not supported yet"))
 		}
+		if hook := spec.Lifecycle.PreDelete; hook != nil {
+			if hook.TimeoutSeconds != nil {
+				allErrs = append(allErrs, field.Forbidden(fldPath.Child("lifecycle", "preDelete", "timeoutSeconds"), "timeoutSeconds of hook has not supported yet"))
+			}
+			if hook.FailurePolicy != "" {
+				allErrs = append(allErrs, field.Forbidden(fldPath.Child("lifecycle", "preDelete", "failurePolicy"), "failurePolicy of hook has not supported yet"))
+			}
+		}
+	}
+
+	if approval := spec.NodeApproval; approval != nil {
+		if approval.Selector == nil && approval.ConditionType == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("nodeApproval"), "selector or conditionType must be specified"))
+		}
+		if approval.Selector != nil {
+			allErrs = append(allErrs, metavalidation.ValidateLabelSelector(approval.Selector, fldPath.Child("nodeApproval", "selector"))...)
+		}
 	}
 	return allErrs
 }
@@ -173,6 +191,43 @@ func validateRollingUpdateDaemonSet(rollingUpdate *appsv1alpha1.RollingUpdateDae
 	if rollingUpdate.Partition != nil {
 		allErrs = append(allErrs, corevalidation.ValidateNonnegativeField(int64(*rollingUpdate.Partition), fldPath.Child("rollingUpdate").Child("partition"))...)
 	}
+	if rollingUpdate.PartitionPercent != nil {
+		if rollingUpdate.Partition != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("partitionPercent"), "may not be set together with partition"))
+		}
+		if percent := *rollingUpdate.PartitionPercent; percent < 0 || percent > 100 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("partitionPercent"), percent, "must be between 0 and 100"))
+		}
+	}
+	if rollingUpdate.ScatterTopologyKey != "" {
+		allErrs = append(allErrs, metavalidation.ValidateLabelName(rollingUpdate.ScatterTopologyKey, fldPath.Child("scatterTopologyKey"))...)
+	}
+
+	poolNames := sets.NewString()
+	for i, pool := range rollingUpdate.NodePools {
+		idxPath := fldPath.Child("nodePools").Index(i)
+		if pool.Name == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("name"), ""))
+		} else if poolNames.Has(pool.Name) {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("name"), pool.Name))
+		}
+		poolNames.Insert(pool.Name)
+		if pool.Selector == nil {
+			allErrs = append(allErrs, field.Required(idxPath.Child("selector"), ""))
+		} else {
+			allErrs = append(allErrs, metavalidation.ValidateLabelSelector(pool.Selector, idxPath.Child("selector"))...)
+		}
+	}
+	if ref := rollingUpdate.CoLocatedPodUnavailableBudget; ref != nil {
+		if ref.Name == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("coLocatedPodUnavailableBudget", "name"), ""))
+		}
+		if ref.Namespace != "" {
+			for _, msg := range validation.IsDNS1123Label(ref.Namespace) {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("coLocatedPodUnavailableBudget", "namespace"), ref.Namespace, msg))
+			}
+		}
+	}
 
 	return allErrs
 }
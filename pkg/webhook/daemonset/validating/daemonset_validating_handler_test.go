@@ -21,6 +21,7 @@ import (
 	"reflect"
 	"testing"
 
+	appspub "github.com/openkruise/kruise/apis/apps/pub"
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -93,6 +94,111 @@ func TestValidateDaemonSet(t *testing.T) {
 			}(),
 			true,
 		},
+		{
+			"partition more than 100 percent",
+			func() *appsv1alpha1.DaemonSet {
+				maxUnavailable := intstr.FromInt(1)
+				partition := int32(120)
+				ds := newDaemonset("ds1")
+				ds.Spec.Selector = &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"key1": "value1",
+					},
+				}
+				ds.Spec.Template = corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{
+							"key1": "value1",
+						},
+					},
+					Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "a", Image: "b"}}},
+				}
+				ds.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyAlways
+				ds.Spec.UpdateStrategy = appsv1alpha1.DaemonSetUpdateStrategy{
+					Type: appsv1alpha1.RollingUpdateDaemonSetStrategyType,
+					RollingUpdate: &appsv1alpha1.RollingUpdateDaemonSet{
+						Type:               appsv1alpha1.StandardRollingUpdateType,
+						MaxUnavailable:     &maxUnavailable,
+						PartitionPercent:   &partition,
+						ScatterTopologyKey: "topology.kubernetes.io/zone",
+					},
+				}
+				return ds
+			}(),
+			false,
+		},
+		{
+			"partition and partitionPercent both set",
+			func() *appsv1alpha1.DaemonSet {
+				maxUnavailable := intstr.FromInt(1)
+				partition := int32(1)
+				partitionPercent := int32(50)
+				ds := newDaemonset("ds1")
+				ds.Spec.Selector = &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"key1": "value1",
+					},
+				}
+				ds.Spec.Template = corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{
+							"key1": "value1",
+						},
+					},
+					Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "a", Image: "b"}}},
+				}
+				ds.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyAlways
+				ds.Spec.UpdateStrategy = appsv1alpha1.DaemonSetUpdateStrategy{
+					Type: appsv1alpha1.RollingUpdateDaemonSetStrategyType,
+					RollingUpdate: &appsv1alpha1.RollingUpdateDaemonSet{
+						Type:             appsv1alpha1.StandardRollingUpdateType,
+						MaxUnavailable:   &maxUnavailable,
+						Partition:        &partition,
+						PartitionPercent: &partitionPercent,
+					},
+				}
+				return ds
+			}(),
+			false,
+		},
+		{
+			"preDelete hook with timeout",
+			func() *appsv1alpha1.DaemonSet {
+				maxUnavailable := intstr.FromInt(1)
+				timeoutSeconds := int32(30)
+				ds := newDaemonset("ds1")
+				ds.Spec.Selector = &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"key1": "value1",
+					},
+				}
+				ds.Spec.Template = corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{
+							"key1": "value1",
+						},
+					},
+					Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "a", Image: "b"}}},
+				}
+				ds.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyAlways
+				ds.Spec.UpdateStrategy = appsv1alpha1.DaemonSetUpdateStrategy{
+					Type: appsv1alpha1.RollingUpdateDaemonSetStrategyType,
+					RollingUpdate: &appsv1alpha1.RollingUpdateDaemonSet{
+						Type:           appsv1alpha1.StandardRollingUpdateType,
+						MaxUnavailable: &maxUnavailable,
+					},
+				}
+				ds.Spec.Lifecycle = &appspub.Lifecycle{
+					PreDelete: &appspub.LifecycleHook{
+						FinalizersHandler: []string{"example.com/hook"},
+						TimeoutSeconds:    &timeoutSeconds,
+						FailurePolicy:     appspub.LifecycleHookFailurePolicyContinue,
+					},
+				}
+				return ds
+			}(),
+			false,
+		},
 	} {
 		result, _, err := handler.validatingDaemonSetFn(context.TODO(), c.Ds)
 		if !reflect.DeepEqual(c.ExpectAllowResult, result) {
@@ -0,0 +1,135 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validating
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/opencontainers/go-digest"
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	daemonutil "github.com/openkruise/kruise/pkg/daemon/util"
+	"github.com/openkruise/kruise/pkg/features"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// ImageDeleteJobCreateUpdateHandler handles ImageDeleteJob
+type ImageDeleteJobCreateUpdateHandler struct {
+	// Decoder decodes objects
+	Decoder *admission.Decoder
+}
+
+var _ admission.Handler = &ImageDeleteJobCreateUpdateHandler{}
+
+// Handle handles admission requests.
+func (h *ImageDeleteJobCreateUpdateHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	obj := &appsv1alpha1.ImageDeleteJob{}
+
+	err := h.Decoder.Decode(req, obj)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if !utilfeature.DefaultFeatureGate.Enabled(features.KruiseDaemon) {
+		return admission.Errored(http.StatusForbidden, fmt.Errorf("feature-gate %s is not enabled", features.KruiseDaemon))
+	}
+
+	if err := validate(obj); err != nil {
+		klog.Warningf("Error validate ImageDeleteJob %s: %v", obj.Name, err)
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	return admission.ValidationResponse(true, "allowed")
+}
+
+func validate(obj *appsv1alpha1.ImageDeleteJob) error {
+	if len(obj.Spec.Images) == 0 && obj.Spec.UnusedDays == nil {
+		return fmt.Errorf("at least one of images and unusedDays should be set")
+	}
+
+	images := sets.NewString()
+	for _, image := range obj.Spec.Images {
+		if len(image) == 0 {
+			return fmt.Errorf("image can not be empty")
+		}
+		// image ID is also allowed
+		key := image
+		if _, err := digest.Parse(image); err != nil {
+			normalized, err := daemonutil.NormalizeImageRef(image)
+			if err != nil {
+				return fmt.Errorf("invalid image %s: %v", image, err)
+			}
+			key = normalized.String()
+		}
+		if images.Has(key) {
+			return fmt.Errorf("duplicated image %s", image)
+		}
+		images.Insert(key)
+	}
+
+	if obj.Spec.UnusedDays != nil && *obj.Spec.UnusedDays <= 0 {
+		return fmt.Errorf("unusedDays must be positive")
+	}
+
+	if obj.Spec.Selector != nil {
+		if obj.Spec.Selector.MatchLabels != nil || obj.Spec.Selector.MatchExpressions != nil {
+			if obj.Spec.Selector.Names != nil {
+				return fmt.Errorf("can not set both names and labelSelector in this spec.selector")
+			}
+			if _, err := metav1.LabelSelectorAsSelector(&obj.Spec.Selector.LabelSelector); err != nil {
+				return fmt.Errorf("invalid selector: %v", err)
+			}
+		}
+		if obj.Spec.Selector.Names != nil {
+			names := sets.NewString(obj.Spec.Selector.Names...)
+			if names.Len() != len(obj.Spec.Selector.Names) {
+				return fmt.Errorf("duplicated name in selector names")
+			}
+		}
+	}
+
+	if obj.Spec.Parallelism != nil {
+		parallelism, err := intstr.GetScaledValueFromIntOrPercent(obj.Spec.Parallelism, 100, true)
+		if err != nil {
+			return fmt.Errorf("invalid parallelism: %v", err)
+		}
+		if parallelism < 0 {
+			return fmt.Errorf("parallelism can not be negative")
+		}
+	}
+
+	if obj.Spec.ActiveDeadlineSeconds != nil && *obj.Spec.ActiveDeadlineSeconds <= 0 {
+		return fmt.Errorf("activeDeadlineSeconds must be positive")
+	}
+	if obj.Spec.TTLSecondsAfterFinished != nil && *obj.Spec.TTLSecondsAfterFinished < 0 {
+		return fmt.Errorf("ttlSecondsAfterFinished can not be negative")
+	}
+	return nil
+}
+
+var _ admission.DecoderInjector = &ImageDeleteJobCreateUpdateHandler{}
+
+// InjectDecoder injects the decoder into the ImageDeleteJobCreateUpdateHandler
+func (h *ImageDeleteJobCreateUpdateHandler) InjectDecoder(d *admission.Decoder) error {
+	h.Decoder = d
+	return nil
+}
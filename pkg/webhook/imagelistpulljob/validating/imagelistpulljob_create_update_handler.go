@@ -0,0 +1,96 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validating
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	daemonutil "github.com/openkruise/kruise/pkg/daemon/util"
+	"github.com/openkruise/kruise/pkg/features"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
+	imagepulljobvalidating "github.com/openkruise/kruise/pkg/webhook/imagepulljob/validating"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// ImageListPullJobCreateUpdateHandler handles ImageListPullJob
+type ImageListPullJobCreateUpdateHandler struct {
+	// Decoder decodes objects
+	Decoder *admission.Decoder
+}
+
+var _ admission.Handler = &ImageListPullJobCreateUpdateHandler{}
+
+// Handle handles admission requests.
+func (h *ImageListPullJobCreateUpdateHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	obj := &appsv1alpha1.ImageListPullJob{}
+
+	err := h.Decoder.Decode(req, obj)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if !utilfeature.DefaultFeatureGate.Enabled(features.KruiseDaemon) {
+		return admission.Errored(http.StatusForbidden, fmt.Errorf("feature-gate %s is not enabled", features.KruiseDaemon))
+	}
+
+	if err := validate(obj); err != nil {
+		klog.Warningf("Error validate ImageListPullJob %s/%s: %v", obj.Namespace, obj.Name, err)
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	return admission.ValidationResponse(true, "allowed")
+}
+
+func validate(obj *appsv1alpha1.ImageListPullJob) error {
+	if err := imagepulljobvalidating.ValidateImagePullJobTemplate(&obj.Spec.ImagePullJobTemplate); err != nil {
+		return err
+	}
+
+	if len(obj.Spec.Images) == 0 {
+		return fmt.Errorf("images can not be empty")
+	}
+
+	images := sets.NewString()
+	for _, image := range obj.Spec.Images {
+		if len(image) == 0 {
+			return fmt.Errorf("image can not be empty")
+		}
+		normalized, err := daemonutil.NormalizeImageRef(image)
+		if err != nil {
+			return fmt.Errorf("invalid image %s: %v", image, err)
+		}
+		if images.Has(normalized.String()) {
+			return fmt.Errorf("duplicated image %s", image)
+		}
+		images.Insert(normalized.String())
+	}
+
+	return nil
+}
+
+var _ admission.DecoderInjector = &ImageListPullJobCreateUpdateHandler{}
+
+// InjectDecoder injects the decoder into the ImageListPullJobCreateUpdateHandler
+func (h *ImageListPullJobCreateUpdateHandler) InjectDecoder(d *admission.Decoder) error {
+	h.Decoder = d
+	return nil
+}
@@ -27,6 +27,9 @@ import (
 	daemonutil "github.com/openkruise/kruise/pkg/daemon/util"
 	"github.com/openkruise/kruise/pkg/features"
 	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
+	"github.com/openkruise/kruise/pkg/util/imageverify"
+	admissionv1 "k8s.io/api/admission/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
@@ -57,52 +60,112 @@ func (h *ImagePullJobCreateUpdateHandler) Handle(ctx context.Context, req admiss
 		return admission.Errored(http.StatusBadRequest, err)
 	}
 
+	if req.Operation == admissionv1.Update {
+		oldObj := &appsv1alpha1.ImagePullJob{}
+		if err := h.Decoder.DecodeRaw(req.OldObject, oldObj); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if err := validateUpdate(obj, oldObj); err != nil {
+			klog.Warningf("Error validate ImagePullJob %s/%s update: %v", obj.Namespace, obj.Name, err)
+			return admission.Errored(http.StatusUnprocessableEntity, err)
+		}
+	}
+
 	return admission.ValidationResponse(true, "allowed")
 }
 
 func validate(obj *appsv1alpha1.ImagePullJob) error {
-	if obj.Spec.Selector != nil {
-		if obj.Spec.Selector.MatchLabels != nil || obj.Spec.Selector.MatchExpressions != nil {
-			if obj.Spec.Selector.Names != nil {
+	return ValidateImagePullJobSpec(&obj.Spec)
+}
+
+// ValidateImagePullJobSpec validates the spec of ImagePullJob, which is also used to validate the template in AdvancedCronJob.
+func ValidateImagePullJobSpec(spec *appsv1alpha1.ImagePullJobSpec) error {
+	if err := ValidateImagePullJobTemplate(&spec.ImagePullJobTemplate); err != nil {
+		return err
+	}
+
+	if len(spec.Image) == 0 {
+		return fmt.Errorf("image can not be empty")
+	}
+
+	if _, err := daemonutil.NormalizeImageRef(spec.Image); err != nil {
+		return fmt.Errorf("invalid image %s: %v", spec.Image, err)
+	}
+
+	return nil
+}
+
+// validateUpdate forbids changing the image to verify, for the nodes pull the image by the digest verified before.
+func validateUpdate(obj, oldObj *appsv1alpha1.ImagePullJob) error {
+	if obj.Spec.Verification == nil && oldObj.Spec.Verification == nil {
+		return nil
+	}
+	if obj.Spec.Image != oldObj.Spec.Image {
+		return fmt.Errorf("spec.image is immutable for the job with verification")
+	}
+	if !apiequality.Semantic.DeepEqual(obj.Spec.Verification, oldObj.Spec.Verification) {
+		return fmt.Errorf("spec.verification is immutable")
+	}
+	return nil
+}
+
+// ValidateImagePullJobTemplate validates the pulling task shared by ImagePullJob and ImageListPullJob.
+func ValidateImagePullJobTemplate(template *appsv1alpha1.ImagePullJobTemplate) error {
+	if template.Selector != nil {
+		if template.Selector.MatchLabels != nil || template.Selector.MatchExpressions != nil {
+			if template.Selector.Names != nil {
 				return fmt.Errorf("can not set both names and labelSelector in this spec.selector")
 			}
-			if _, err := metav1.LabelSelectorAsSelector(&obj.Spec.Selector.LabelSelector); err != nil {
+			if _, err := metav1.LabelSelectorAsSelector(&template.Selector.LabelSelector); err != nil {
 				return fmt.Errorf("invalid selector: %v", err)
 			}
 		}
-		if obj.Spec.Selector.Names != nil {
-			names := sets.NewString(obj.Spec.Selector.Names...)
-			if names.Len() != len(obj.Spec.Selector.Names) {
+		if template.Selector.Names != nil {
+			names := sets.NewString(template.Selector.Names...)
+			if names.Len() != len(template.Selector.Names) {
 				return fmt.Errorf("duplicated name in selector names")
 			}
 		}
 	}
-	if obj.Spec.PodSelector != nil {
-		if obj.Spec.Selector != nil {
+	if template.PodSelector != nil {
+		if template.Selector != nil {
 			return fmt.Errorf("can not set both selector and podSelector")
 		}
-		if _, err := metav1.LabelSelectorAsSelector(&obj.Spec.PodSelector.LabelSelector); err != nil {
+		if _, err := metav1.LabelSelectorAsSelector(&template.PodSelector.LabelSelector); err != nil {
 			return fmt.Errorf("invalid podSelector: %v", err)
 		}
 	}
 
-	if len(obj.Spec.Image) == 0 {
-		return fmt.Errorf("image can not be empty")
+	if template.PullPolicy != nil && template.PullPolicy.Distributor != nil {
+		distributor := template.PullPolicy.Distributor
+		switch distributor.Type {
+		case appsv1alpha1.ImagePullDistributorDragonfly, appsv1alpha1.ImagePullDistributorKraken:
+		default:
+			return fmt.Errorf("unknown type of distributor: %s", distributor.Type)
+		}
+		if len(distributor.Endpoint) == 0 {
+			return fmt.Errorf("endpoint of distributor can not be empty")
+		}
+		if _, err := daemonutil.ReplaceImageDomain("busybox", distributor.Endpoint); err != nil {
+			return fmt.Errorf("invalid distributor: %v", err)
+		}
 	}
 
-	if _, err := daemonutil.NormalizeImageRef(obj.Spec.Image); err != nil {
-		return fmt.Errorf("invalid image %s: %v", obj.Spec.Image, err)
+	if template.Verification != nil {
+		if err := imageverify.Validate(template.Verification); err != nil {
+			return fmt.Errorf("invalid verification: %v", err)
+		}
 	}
 
-	switch obj.Spec.CompletionPolicy.Type {
+	switch template.CompletionPolicy.Type {
 	case appsv1alpha1.Always:
 
 	case appsv1alpha1.Never:
-		if obj.Spec.CompletionPolicy.ActiveDeadlineSeconds != nil || obj.Spec.CompletionPolicy.TTLSecondsAfterFinished != nil {
+		if template.CompletionPolicy.ActiveDeadlineSeconds != nil || template.CompletionPolicy.TTLSecondsAfterFinished != nil {
 			return fmt.Errorf("activeDeadlineSeconds and ttlSecondsAfterFinished can only work with Always CompletionPolicyType")
 		}
 	default:
-		return fmt.Errorf("unknown type of completionPolicy: %s", obj.Spec.CompletionPolicy.Type)
+		return fmt.Errorf("unknown type of completionPolicy: %s", template.CompletionPolicy.Type)
 	}
 
 	return nil
@@ -0,0 +1,76 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validating
+
+import (
+	"testing"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+)
+
+func TestValidateUpdate(t *testing.T) {
+	newJob := func(image, publicKey string) *appsv1alpha1.ImagePullJob {
+		job := &appsv1alpha1.ImagePullJob{}
+		job.Spec.Image = image
+		if publicKey != "" {
+			job.Spec.Verification = &appsv1alpha1.ImageVerification{Type: appsv1alpha1.ImageSignatureCosign, PublicKey: publicKey}
+		}
+		return job
+	}
+	cases := []struct {
+		name      string
+		oldJob    *appsv1alpha1.ImagePullJob
+		newJob    *appsv1alpha1.ImagePullJob
+		expectErr bool
+	}{
+		{
+			name:   "change image without verification",
+			oldJob: newJob("nginx:1.20", ""),
+			newJob: newJob("nginx:1.21", ""),
+		},
+		{
+			name:      "change image with verification",
+			oldJob:    newJob("nginx:1.20", "key"),
+			newJob:    newJob("nginx:1.21", "key"),
+			expectErr: true,
+		},
+		{
+			name:      "change verification",
+			oldJob:    newJob("nginx:1.20", "key"),
+			newJob:    newJob("nginx:1.20", "another-key"),
+			expectErr: true,
+		},
+		{
+			name:      "add verification",
+			oldJob:    newJob("nginx:1.20", ""),
+			newJob:    newJob("nginx:1.20", "key"),
+			expectErr: true,
+		},
+		{
+			name:   "nothing changed",
+			oldJob: newJob("nginx:1.20", "key"),
+			newJob: newJob("nginx:1.20", "key"),
+		},
+	}
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			if err := validateUpdate(cs.newJob, cs.oldJob); (err != nil) != cs.expectErr {
+				t.Fatalf("expected error %v, got %v", cs.expectErr, err)
+			}
+		})
+	}
+}
@@ -21,8 +21,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
+	appspub "github.com/openkruise/kruise/apis/apps/pub"
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
 	"github.com/openkruise/kruise/pkg/control/sidecarcontrol"
 	"github.com/openkruise/kruise/pkg/util"
@@ -32,6 +34,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
+	storagenames "k8s.io/apiserver/pkg/storage/names"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
@@ -71,17 +74,35 @@ func (h *PodCreateHandler) sidecarsetMutatingPod(ctx context.Context, req admiss
 
 	matchedSidecarSets := make([]sidecarcontrol.SidecarControl, 0)
 	for _, sidecarSet := range sidecarsetList.Items {
-		if sidecarSet.Spec.InjectionStrategy.Paused {
+		// the deleting sidecarSet is uninjecting the sidecars from pods,
+		// and the ephemeral sidecarSet is injected by controller on demand
+		if sidecarSet.Spec.InjectionStrategy.Paused || sidecarSet.Spec.InjectionStrategy.Ephemeral || sidecarSet.DeletionTimestamp != nil {
 			continue
 		}
-		if matched, err := sidecarcontrol.PodMatchedSidecarSet(pod, sidecarSet); err != nil {
+		if matched, err := sidecarcontrol.PodMatchedSidecarSet(h.Client, pod, sidecarSet); err != nil {
 			return err
 		} else if !matched {
 			continue
 		}
+		// only a percentage of the newly created pods will be injected, which are chosen by pod name
+		if !isUpdated && sidecarSet.Spec.InjectionStrategy.Percent != nil {
+			if len(pod.Name) == 0 && len(pod.GenerateName) > 0 {
+				pod.Name = storagenames.SimpleNameGenerator.GenerateName(pod.GenerateName)
+			}
+			if !sidecarcontrol.IsPodSelectedByInjectionPercent(pod, &sidecarSet) {
+				continue
+			}
+		}
 		// check whether sidecarSet is active
 		// when sidecarSet is not active, it will not perform injections and upgrades process.
-		control := sidecarcontrol.New(sidecarSet.DeepCopy())
+		sidecarSetCopy := sidecarSet.DeepCopy()
+		// merge the overrides in pod namespace into the sidecars at injection time
+		if !isUpdated {
+			if err := sidecarcontrol.ApplySidecarSetOverrides(h.Client, pod.Namespace, sidecarSetCopy); err != nil {
+				return err
+			}
+		}
+		control := sidecarcontrol.New(sidecarSetCopy)
 		if !control.IsActiveSidecarSet() {
 			continue
 		}
@@ -115,13 +136,8 @@ func (h *PodCreateHandler) sidecarsetMutatingPod(ctx context.Context, req admiss
 		pod.Namespace, pod.Name)
 	klog.V(4).Infof("[sidecar inject] before mutating: %v", util.DumpJSON(pod))
 	// apply sidecar set info into pod
-	// 1. inject init containers, sort by their name, after the original init containers
-	sort.SliceStable(sidecarInitContainers, func(i, j int) bool {
-		return sidecarInitContainers[i].Name < sidecarInitContainers[j].Name
-	})
-	for _, initContainer := range sidecarInitContainers {
-		pod.Spec.InitContainers = append(pod.Spec.InitContainers, initContainer.Container)
-	}
+	// 1. inject init containers, sort by their weight and name, after the original init containers by default
+	pod.Spec.InitContainers = mergeSidecarInitContainers(pod.Spec.InitContainers, sidecarInitContainers)
 	// 2. inject containers
 	pod.Spec.Containers = mergeSidecarContainers(pod.Spec.Containers, sidecarContainers)
 	// 3. inject volumes
@@ -156,12 +172,37 @@ func mergeSidecarSecrets(secretsInPod, secretsInSidecar []corev1.LocalObjectRefe
 	return allSecrets
 }
 
+func mergeSidecarInitContainers(origins []corev1.Container, injected []*appsv1alpha1.SidecarContainer) []corev1.Container {
+	sort.SliceStable(injected, func(i, j int) bool {
+		if injected[i].InjectWeight != injected[j].InjectWeight {
+			return injected[i].InjectWeight > injected[j].InjectWeight
+		}
+		return injected[i].Name < injected[j].Name
+	})
+	var beforeAppContainers []corev1.Container
+	var afterAppContainers []corev1.Container
+	for _, initContainer := range injected {
+		if initContainer.InitContainerInjectPolicy == appsv1alpha1.BeforeAppContainerType {
+			beforeAppContainers = append(beforeAppContainers, initContainer.Container)
+		} else {
+			afterAppContainers = append(afterAppContainers, initContainer.Container)
+		}
+	}
+	origins = append(beforeAppContainers, origins...)
+	origins = append(origins, afterAppContainers...)
+	return origins
+}
+
 func mergeSidecarContainers(origins []corev1.Container, injected []*appsv1alpha1.SidecarContainer) []corev1.Container {
 	//format: pod.spec.containers[index].name -> index(the index of container in pod)
 	containersInPod := make(map[string]int)
 	for index, container := range origins {
 		containersInPod[container.Name] = index
 	}
+	// the sidecar containers with higher weight are injected in front
+	sort.SliceStable(injected, func(i, j int) bool {
+		return injected[i].InjectWeight > injected[j].InjectWeight
+	})
 	var beforeAppContainers []corev1.Container
 	var afterAppContainers []corev1.Container
 	for _, sidecar := range injected {
@@ -311,6 +352,12 @@ func buildSidecars(isUpdated bool, pod *corev1.Pod, oldPod *corev1.Pod, matchedS
 			sidecarContainer.VolumeMounts = util.MergeVolumeMounts(sidecarContainer.VolumeMounts, injectedMounts)
 			// add the "Injected" env to the sidecar container
 			sidecarContainer.Env = append(sidecarContainer.Env, corev1.EnvVar{Name: sidecarcontrol.SidecarEnvKey, Value: "true"})
+			// add the launch priority env to the sidecar container, unless it has been defined
+			if sidecarContainer.LaunchPriority != nil {
+				sidecarContainer.Env = util.MergeEnvVar(sidecarContainer.Env, []corev1.EnvVar{{
+					Name: appspub.ContainerLaunchPriorityEnvName, Value: strconv.Itoa(int(*sidecarContainer.LaunchPriority)),
+				}})
+			}
 			// merged Env from sidecar.Env and transfer envs
 			sidecarContainer.Env = util.MergeEnvVar(sidecarContainer.Env, transferEnvs)
 
@@ -19,11 +19,14 @@ package mutating
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/openkruise/kruise/apis"
+	appspub "github.com/openkruise/kruise/apis/apps/pub"
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
 	"github.com/openkruise/kruise/pkg/control/sidecarcontrol"
 	"github.com/openkruise/kruise/pkg/util"
@@ -33,6 +36,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
+	utilpointer "k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
@@ -463,6 +467,46 @@ func testInjectionStrategyPaused(t *testing.T, sidecarIn *appsv1alpha1.SidecarSe
 	}
 }
 
+func TestInjectionStrategyPercent(t *testing.T) {
+	sidecarSetIn := sidecarSet1.DeepCopy()
+	testInjectionStrategyPercent(t, sidecarSetIn)
+}
+
+func testInjectionStrategyPercent(t *testing.T, sidecarSetIn *appsv1alpha1.SidecarSet) {
+	sidecarSetIn.Spec.InjectionStrategy.Percent = utilpointer.Int32Ptr(30)
+	decoder, _ := admission.NewDecoder(scheme.Scheme)
+	client := fake.NewClientBuilder().WithObjects(sidecarSetIn).Build()
+	podHandler := &PodCreateHandler{Decoder: decoder, Client: client}
+	req := newAdmission(admissionv1.Create, runtime.RawExtension{}, runtime.RawExtension{}, "")
+
+	var injected int
+	for i := 0; i < 1000; i++ {
+		podIn := pod1.DeepCopy()
+		podIn.Name = fmt.Sprintf("test-pod-%d", i)
+		podOut := podIn.DeepCopy()
+		_ = podHandler.sidecarsetMutatingPod(context.Background(), req, podOut)
+		if len(podOut.Spec.Containers) != len(podIn.Spec.Containers) {
+			injected++
+		}
+		if sidecarcontrol.IsPodSelectedByInjectionPercent(podIn, sidecarSetIn) != (len(podOut.Spec.Containers) != len(podIn.Spec.Containers)) {
+			t.Fatalf("expect pod %s injected only if selected by percent", podIn.Name)
+		}
+	}
+	if injected < 250 || injected > 350 {
+		t.Fatalf("expect about 300 pods injected, but got %d", injected)
+	}
+
+	// pods with generateName get their names generated to be chosen
+	podIn := pod1.DeepCopy()
+	podIn.Name = ""
+	podIn.GenerateName = "test-pod-"
+	podOut := podIn.DeepCopy()
+	_ = podHandler.sidecarsetMutatingPod(context.Background(), req, podOut)
+	if podOut.Name == "" {
+		t.Fatalf("expect pod name generated")
+	}
+}
+
 func TestSidecarSetPodInjectPolicy(t *testing.T) {
 	sidecarSetIn := sidecarSet1.DeepCopy()
 	testSidecarSetPodInjectPolicy(t, sidecarSetIn)
@@ -904,6 +948,21 @@ func TestMergeSidecarContainers(t *testing.T) {
 			expectContainerLen: 4,
 			expectedContainers: []string{"new-sidecar-1", "sidecar-1", "app-container", "sidecar-2"},
 		},
+		{
+			name: "origins not sidecar, and inject new sidecar with weight",
+			getOrigins: func() []corev1.Container {
+				return podContainers[1:2]
+			},
+			getInjected: func() []*appsv1alpha1.SidecarContainer {
+				injected := []*appsv1alpha1.SidecarContainer{
+					sidecarContainers[0].DeepCopy(), sidecarContainers[1].DeepCopy(), sidecarContainers[2].DeepCopy(),
+				}
+				injected[1].InjectWeight = 10
+				return injected
+			},
+			expectContainerLen: 4,
+			expectedContainers: []string{"new-sidecar-1", "app-container", "sidecar-2", "sidecar-1"},
+		},
 	}
 
 	for _, cs := range cases {
@@ -923,6 +982,49 @@ func TestMergeSidecarContainers(t *testing.T) {
 	}
 }
 
+func TestMergeSidecarInitContainers(t *testing.T) {
+	origins := []corev1.Container{{Name: "app-init"}}
+	injected := []*appsv1alpha1.SidecarContainer{
+		{Container: corev1.Container{Name: "init-b"}},
+		{Container: corev1.Container{Name: "init-a"}},
+		{Container: corev1.Container{Name: "init-c"}, InjectWeight: 10},
+		{Container: corev1.Container{Name: "init-e"}, InitContainerInjectPolicy: appsv1alpha1.BeforeAppContainerType},
+		{Container: corev1.Container{Name: "init-d"}, InitContainerInjectPolicy: appsv1alpha1.BeforeAppContainerType},
+	}
+	expected := []string{"init-d", "init-e", "app-init", "init-c", "init-a", "init-b"}
+
+	finals := mergeSidecarInitContainers(origins, injected)
+	var names []string
+	for _, c := range finals {
+		names = append(names, c.Name)
+	}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("expect init containers %v, but got %v", expected, names)
+	}
+}
+
+func TestSidecarSetLaunchPriority(t *testing.T) {
+	sidecarSetIn := sidecarSet1.DeepCopy()
+	sidecarSetIn.Spec.Containers[0].LaunchPriority = utilpointer.Int32Ptr(100)
+	podIn := pod1.DeepCopy()
+	decoder, _ := admission.NewDecoder(scheme.Scheme)
+	client := fake.NewClientBuilder().WithObjects(sidecarSetIn).Build()
+	podOut := podIn.DeepCopy()
+	podHandler := &PodCreateHandler{Decoder: decoder, Client: client}
+	req := newAdmission(admissionv1.Create, runtime.RawExtension{}, runtime.RawExtension{}, "")
+	if err := podHandler.sidecarsetMutatingPod(context.Background(), req, podOut); err != nil {
+		t.Fatalf("inject sidecar into pod failed, err: %v", err)
+	}
+	sidecar := util.GetContainer(sidecarSetIn.Spec.Containers[0].Name, podOut)
+	if sidecar == nil {
+		t.Fatalf("expect sidecar container injected")
+	}
+	env := util.GetContainerEnvVar(sidecar, appspub.ContainerLaunchPriorityEnvName)
+	if env == nil || env.Value != "100" {
+		t.Fatalf("expect launch priority env 100, but got %v", env)
+	}
+}
+
 func newAdmission(op admissionv1.Operation, object, oldObject runtime.RawExtension, subResource string) admission.Request {
 	return admission.Request{
 		AdmissionRequest: newAdmissionRequest(op, object, oldObject, subResource),
@@ -28,6 +28,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
+// +kubebuilder:rbac:groups=scheduling.k8s.io,resources=priorityclasses,verbs=get;list;watch
+
 func (h *PodCreateHandler) workloadSpreadMutatingPod(ctx context.Context, req admission.Request,
 	pod *corev1.Pod) error {
 	if len(req.AdmissionRequest.SubResource) > 0 ||
@@ -20,11 +20,15 @@ import (
 	"context"
 	"net/http"
 
+	kruiseclient "github.com/openkruise/kruise/pkg/client"
 	"github.com/openkruise/kruise/pkg/control/pubcontrol"
 	"github.com/openkruise/kruise/pkg/features"
 	"github.com/openkruise/kruise/pkg/util/controllerfinder"
 	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
 	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
@@ -44,9 +48,11 @@ type PodCreateHandler struct {
 
 	finders    *controllerfinder.ControllerFinder
 	pubControl pubcontrol.PubControl
+	// eventRecorder records the operations rejected by PodUnavailableBudget
+	eventRecorder record.EventRecorder
 }
 
-func (h *PodCreateHandler) validatingPodFn(ctx context.Context, req admission.Request) (allowed bool, reason string, err error) {
+func (h *PodCreateHandler) validatingPodFn(ctx context.Context, req admission.Request) (allowed bool, reason string, auditAnnotations map[string]string, err error) {
 	allowed = true
 	if req.Operation == admissionv1.Delete && len(req.OldObject.Raw) == 0 {
 		klog.Warningf("Skip to validate pod %s/%s deletion for no old object, maybe because of Kubernetes version < 1.16", req.Namespace, req.Name)
@@ -56,7 +62,7 @@ func (h *PodCreateHandler) validatingPodFn(ctx context.Context, req admission.Re
 	switch req.Operation {
 	case admissionv1.Update:
 		if utilfeature.DefaultFeatureGate.Enabled(features.PodUnavailableBudgetUpdateGate) {
-			allowed, reason, err = h.podUnavailableBudgetValidatingPod(ctx, req)
+			allowed, reason, auditAnnotations, err = h.podUnavailableBudgetValidatingPod(ctx, req)
 		}
 	case admissionv1.Delete, admissionv1.Create:
 		if utilfeature.DefaultFeatureGate.Enabled(features.WorkloadSpread) {
@@ -67,7 +73,7 @@ func (h *PodCreateHandler) validatingPodFn(ctx context.Context, req admission.Re
 		}
 
 		if utilfeature.DefaultFeatureGate.Enabled(features.PodUnavailableBudgetDeleteGate) {
-			allowed, reason, err = h.podUnavailableBudgetValidatingPod(ctx, req)
+			allowed, reason, auditAnnotations, err = h.podUnavailableBudgetValidatingPod(ctx, req)
 		}
 	}
 
@@ -78,11 +84,13 @@ var _ admission.Handler = &PodCreateHandler{}
 
 // Handle handles admission requests.
 func (h *PodCreateHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
-	allowed, reason, err := h.validatingPodFn(ctx, req)
+	allowed, reason, auditAnnotations, err := h.validatingPodFn(ctx, req)
 	if err != nil {
 		return admission.Errored(http.StatusBadRequest, err)
 	}
-	return admission.ValidationResponse(allowed, reason)
+	resp := admission.ValidationResponse(allowed, reason)
+	resp.AuditAnnotations = auditAnnotations
+	return resp
 }
 
 var _ inject.Client = &PodCreateHandler{}
@@ -92,6 +100,11 @@ func (h *PodCreateHandler) InjectClient(c client.Client) error {
 	h.Client = c
 	h.finders = controllerfinder.NewControllerFinder(c)
 	h.pubControl = pubcontrol.NewPubControl(c)
+	if genericClient := kruiseclient.GetGenericClientWithName("pod-validating-webhook"); genericClient != nil {
+		eventBroadcaster := record.NewBroadcaster()
+		eventBroadcaster.StartRecordingToSink(&v1core.EventSinkImpl{Interface: genericClient.KubeClient.CoreV1().Events("")})
+		h.eventRecorder = eventBroadcaster.NewRecorder(c.Scheme(), corev1.EventSource{Component: "pod-validating-webhook"})
+	}
 	return nil
 }
 
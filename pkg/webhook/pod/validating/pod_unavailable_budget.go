@@ -18,12 +18,17 @@ package validating
 
 import (
 	"context"
+	"flag"
+	"fmt"
+	"strings"
 
 	"github.com/openkruise/kruise/pkg/control/pubcontrol"
+	"github.com/openkruise/kruise/pkg/util/controllerfinder"
 	admissionv1 "k8s.io/api/admission/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apiserver/pkg/util/dryrun"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/apis/policy"
@@ -33,24 +38,69 @@ import (
 // +kubebuilder:rbac:groups=policy.kruise.io,resources=podunavailablebudgets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=policy.kruise.io,resources=podunavailablebudgets/status,verbs=get;update;patch
 
+func init() {
+	flag.StringVar(&taintEvictionPolicy, "pub-taint-eviction-policy", taintEvictionPolicy,
+		"How PUB handles the pod deletion by NoExecute taint manager, Enforce rejects it if the budget is exhausted, "+
+			"Account always allows it and only records the pod in PUB status. Defaults Enforce")
+	flag.StringVar(&taintManagerUsers, "pub-taint-manager-users", taintManagerUsers,
+		"Comma-separated usernames of NoExecute taint manager, whose pod deletion is handled by pub-taint-eviction-policy.")
+	flag.StringVar(&exemptNamespaces, "pub-exempt-namespaces", exemptNamespaces,
+		"Comma-separated namespaces whose pod operations bypass PUB validation, besides kube-system and kube-public.")
+	flag.StringVar(&exemptRequesters, "pub-exempt-requesters", exemptRequesters,
+		"Comma-separated usernames or groups whose pod operations bypass PUB validation, "+
+			"e.g. system:serviceaccount:ops:break-glass for emergency operations.")
+}
+
+const (
+	// TaintEvictionPolicyEnforce rejects the pod deletion by taint manager if the budget is exhausted, the same as other deletion
+	TaintEvictionPolicyEnforce = "Enforce"
+	// TaintEvictionPolicyAccount always allows the pod deletion by taint manager, and records the pod in PUB status if the budget remains
+	TaintEvictionPolicyAccount = "Account"
+)
+
 var (
 	// IgnoredNamespaces specifies the namespaces where Pods won't get injected
 	IgnoredNamespaces = []string{"kube-system", "kube-public"}
+
+	taintEvictionPolicy = TaintEvictionPolicyEnforce
+	taintManagerUsers   = "system:serviceaccount:kube-system:node-controller,system:kube-controller-manager"
+
+	exemptNamespaces string
+	exemptRequesters string
 )
 
+// ValidateTaintEvictionPolicy checks the pub-taint-eviction-policy flag, it should be called once the flags are parsed.
+func ValidateTaintEvictionPolicy() error {
+	switch taintEvictionPolicy {
+	case TaintEvictionPolicyEnforce, TaintEvictionPolicyAccount:
+		return nil
+	}
+	return fmt.Errorf("pub-taint-eviction-policy must be %s or %s, got %q",
+		TaintEvictionPolicyEnforce, TaintEvictionPolicyAccount, taintEvictionPolicy)
+}
+
 // parameters:
 // 1. allowed(bool) whether to allow this request
 // 2. reason(string)
-// 3. err(error)
-func (p *PodCreateHandler) podUnavailableBudgetValidatingPod(ctx context.Context, req admission.Request) (bool, string, error) {
+// 3. auditAnnotations(map[string]string) the pub status recorded in audit logs if the request is denied
+// 4. err(error)
+func (p *PodCreateHandler) podUnavailableBudgetValidatingPod(ctx context.Context, req admission.Request) (bool, string, map[string]string, error) {
 	var newPod, oldPod *corev1.Pod
 	var dryRun bool
+	var operation pubcontrol.Operation
+	// whether the pod is deleted by taint manager and only needs to be accounted
+	var taintEviction bool
 	// ignore kube-system, kube-public
 	for _, namespace := range IgnoredNamespaces {
 		if req.Namespace == namespace {
-			return true, "", nil
+			return true, "", nil, nil
 		}
 	}
+	// ignore the namespaces and requesters in allowlist
+	if isPubExempted(req) {
+		klog.V(3).Infof("pod(%s/%s) operation(%s) by user(%s) is exempted from pub, then admit", req.Namespace, req.Name, req.Operation, req.UserInfo.Username)
+		return true, "", nil, nil
+	}
 
 	klog.V(6).Infof("pub validate operation(%s) pod(%s/%s)", req.Operation, req.Namespace, req.Name)
 	newPod = &corev1.Pod{}
@@ -60,52 +110,59 @@ func (p *PodCreateHandler) podUnavailableBudgetValidatingPod(ctx context.Context
 		//decode new pod
 		err := p.Decoder.Decode(req, newPod)
 		if err != nil {
-			return false, "", err
+			return false, "", nil, err
 		}
 		oldPod = &corev1.Pod{}
 		if err = p.Decoder.Decode(
 			admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{Object: req.AdmissionRequest.OldObject}},
 			oldPod); err != nil {
-			return false, "", err
+			return false, "", nil, err
 		}
 
 		options := &metav1.UpdateOptions{}
 		err = p.Decoder.DecodeRaw(req.Options, options)
 		if err != nil {
-			return false, "", err
+			return false, "", nil, err
 		}
 		// if dry run
 		dryRun = dryrun.IsDryRun(options.DryRun)
+		operation = pubcontrol.UpdateOperation
+		// in-place resize of container resources
+		if req.AdmissionRequest.SubResource == "resize" {
+			operation = pubcontrol.ResizeOperation
+		}
 
 	// filter out invalid Delete operation, only validate delete pods resources
 	case admissionv1.Delete:
 		if req.AdmissionRequest.SubResource != "" {
 			klog.V(6).Infof("pod(%s/%s) AdmissionRequest operation(DELETE) subResource(%s), then admit", req.Namespace, req.Name, req.SubResource)
-			return true, "", nil
+			return true, "", nil, nil
 		}
 		if err := p.Decoder.DecodeRaw(req.OldObject, newPod); err != nil {
-			return false, "", err
+			return false, "", nil, err
 		}
 		deletion := &metav1.DeleteOptions{}
 		err := p.Decoder.DecodeRaw(req.Options, deletion)
 		if err != nil {
-			return false, "", err
+			return false, "", nil, err
 		}
 		// if dry run
 		dryRun = dryrun.IsDryRun(deletion.DryRun)
+		operation = pubcontrol.DeleteOperation
+		taintEviction = taintEvictionPolicy == TaintEvictionPolicyAccount && p.isTaintEviction(ctx, req, newPod)
 
 	// filter out invalid Create operation, only validate create pod eviction subresource
 	case admissionv1.Create:
 		// ignore create operation other than subresource eviction
 		if req.AdmissionRequest.SubResource != "eviction" {
 			klog.V(6).Infof("pod(%s/%s) AdmissionRequest operation(CREATE) Resource(%s) subResource(%s), then admit", req.Namespace, req.Name, req.Resource, req.SubResource)
-			return true, "", nil
+			return true, "", nil, nil
 		}
 		eviction := &policy.Eviction{}
 		//decode eviction
 		err := p.Decoder.Decode(req, eviction)
 		if err != nil {
-			return false, "", err
+			return false, "", nil, err
 		}
 		// if dry run
 		if eviction.DeleteOptions != nil {
@@ -116,17 +173,25 @@ func (p *PodCreateHandler) podUnavailableBudgetValidatingPod(ctx context.Context
 			Name:      req.AdmissionRequest.Name,
 		}
 		if err = p.Client.Get(ctx, key, newPod); err != nil {
-			return false, "", err
+			return false, "", nil, err
 		}
+		operation = pubcontrol.EvictOperation
 	}
 
 	// Get the workload corresponding to the pod, if it has been deleted then it is not protected
 	if ref := metav1.GetControllerOf(newPod); ref != nil {
-		workload, err := p.finders.GetScaleAndSelectorForRef(ref.APIVersion, ref.Kind, newPod.Namespace, ref.Name, ref.UID)
+		var workload *controllerfinder.ScaleAndSelector
+		var err error
+		if controllerfinder.IsValidGroupVersionKind(ref.APIVersion, ref.Kind) {
+			workload, err = p.finders.GetScaleAndSelectorForRef(ref.APIVersion, ref.Kind, newPod.Namespace, ref.Name, ref.UID)
+		} else if newPod.Annotations[pubcontrol.PodRelatedPubAnnotation] != "" {
+			// the custom workload referenced by pub targetRef
+			workload, err = p.finders.GetScaleAndSelectorForTargetRef(ref.APIVersion, ref.Kind, newPod.Namespace, ref.Name, ref.UID)
+		}
 		if err != nil {
-			return false, "", err
+			return false, "", nil, err
 		} else if workload == nil || !workload.Metadata.DeletionTimestamp.IsZero() {
-			return true, "", nil
+			return true, "", nil, nil
 		}
 	}
 
@@ -134,31 +199,85 @@ func (p *PodCreateHandler) podUnavailableBudgetValidatingPod(ctx context.Context
 	if newPod.Status.Phase == corev1.PodSucceeded || newPod.Status.Phase == corev1.PodFailed ||
 		newPod.Status.Phase == corev1.PodPending || newPod.Status.Phase == "" || !newPod.ObjectMeta.DeletionTimestamp.IsZero() {
 		klog.V(3).Infof("pod(%s/%s) Status(%s) Deletion(%v), then admit", newPod.Namespace, newPod.Name, newPod.Status.Phase, !newPod.ObjectMeta.DeletionTimestamp.IsZero())
-		return true, "", nil
+		return true, "", nil, nil
 	}
 
 	pub, err := p.pubControl.GetPubForPod(newPod)
 	if err != nil {
-		return false, "", err
+		return false, "", nil, err
 	}
 	// if there is no matching PodUnavailableBudget, just return true
 	if pub == nil {
-		return true, "", nil
+		return true, "", nil, nil
 	}
 
 	klog.V(3).Infof("validating pod(%s/%s) operation(%s) for pub(%s/%s)", newPod.Namespace, newPod.Name, req.Operation, pub.Namespace, pub.Name)
-	// pods that contain annotations[pod.kruise.io/pub-no-protect]="true" will be ignore
+	// pods that contain annotations[pub.kruise.io/no-protect]="true" or the operation scope will be ignore
 	// and will no longer check the pub quota
-	if newPod.Annotations[pubcontrol.PodPubNoProtectionAnnotation] == "true" {
-		klog.V(3).Infof("pod(%s/%s) contains annotations[%s], then don't need check pub", newPod.Namespace, newPod.Name, pubcontrol.PodPubNoProtectionAnnotation)
-		return true, "", nil
+	if pubcontrol.IsPodNoProtected(newPod, operation) {
+		klog.V(3).Infof("pod(%s/%s) contains annotations[%s] for operation(%s), then don't need check pub", newPod.Namespace, newPod.Name, pubcontrol.PodPubNoProtectionAnnotation, operation)
+		return true, "", nil, nil
+	}
+
+	// the in-place update, including the resize of containers before it, has been budgeted by workload controller,
+	// then don't count it again
+	if (operation == pubcontrol.UpdateOperation && pubcontrol.IsPodUpdateBudgeted(oldPod, newPod, pub)) ||
+		(operation == pubcontrol.ResizeOperation && pubcontrol.IsPodResizeBudgeted(newPod, pub)) {
+		klog.V(3).Infof("pod(%s/%s) update has been budgeted by workload for pub(%s/%s), then admit", newPod.Namespace, newPod.Name, pub.Namespace, pub.Name)
+		return true, "", nil, nil
 	}
 
 	// the change will not cause pod unavailability, then pass
 	if !p.pubControl.IsPodUnavailableChanged(oldPod, newPod) {
 		klog.V(3).Infof("validate pod(%s/%s) changed cannot cause unavailability, then don't need check pub", newPod.Namespace, newPod.Name)
-		return true, "", nil
+		return true, "", nil, nil
 	}
 
-	return pubcontrol.PodUnavailableBudgetValidatePod(p.Client, p.pubControl, pub, newPod, pubcontrol.Operation(req.Operation), dryRun)
+	allowed, reason, err := pubcontrol.PodUnavailableBudgetValidatePod(p.Client, p.pubControl, pub, newPod, operation, dryRun)
+	if err != nil || allowed {
+		return allowed, reason, nil, err
+	}
+	if taintEviction {
+		klog.Warningf("pod(%s/%s) is deleted by taint manager over budget of pub(%s/%s): %s", newPod.Namespace, newPod.Name, pub.Namespace, pub.Name, reason)
+		if p.eventRecorder != nil && !dryRun {
+			p.eventRecorder.Eventf(pub, corev1.EventTypeWarning, "TaintEvictionOverBudget",
+				"Pod %s on node %s was deleted by NoExecute taint manager over budget: %s", newPod.Name, newPod.Spec.NodeName, reason)
+		}
+		return true, "", nil, nil
+	}
+	if !dryRun {
+		pubcontrol.RecordPubRejection(p.eventRecorder, pub, newPod, operation, req.UserInfo.Username, reason)
+	}
+	return false, reason, pubcontrol.GetPubAuditAnnotations(p.Client, pub), nil
+}
+
+// isTaintEviction returns whether the pod deletion is requested by taint manager,
+// because the node of pod has a NoExecute taint.
+func (p *PodCreateHandler) isTaintEviction(ctx context.Context, req admission.Request, pod *corev1.Pod) bool {
+	if pod.Spec.NodeName == "" || !sets.NewString(strings.Split(taintManagerUsers, ",")...).Has(req.UserInfo.Username) {
+		return false
+	}
+	node := &corev1.Node{}
+	if err := p.Client.Get(ctx, types.NamespacedName{Name: pod.Spec.NodeName}, node); err != nil {
+		klog.Warningf("Get node(%s) of pod(%s/%s) failed: %s", pod.Spec.NodeName, pod.Namespace, pod.Name, err.Error())
+		return false
+	}
+	for _, taint := range node.Spec.Taints {
+		if taint.Effect == corev1.TaintEffectNoExecute {
+			return true
+		}
+	}
+	return false
+}
+
+// isPubExempted returns whether the request is in the namespace or from the requester that bypasses pub validation
+func isPubExempted(req admission.Request) bool {
+	if exemptNamespaces != "" && sets.NewString(strings.Split(exemptNamespaces, ",")...).Has(req.Namespace) {
+		return true
+	}
+	if exemptRequesters == "" {
+		return false
+	}
+	requesters := sets.NewString(strings.Split(exemptRequesters, ",")...)
+	return requesters.Has(req.UserInfo.Username) || requesters.HasAny(req.UserInfo.Groups...)
 }
@@ -18,6 +18,7 @@ package validating
 
 import (
 	"context"
+	"fmt"
 	"reflect"
 	"testing"
 	"time"
@@ -30,10 +31,12 @@ import (
 	admissionv1 "k8s.io/api/admission/v1"
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
 	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
 	"k8s.io/kubernetes/pkg/apis/policy"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -157,6 +160,64 @@ func TestValidateUpdatePodForPub(t *testing.T) {
 				return pubStatus
 			},
 		},
+		{
+			name: "valid resize pod, allow",
+			oldPod: func() *corev1.Pod {
+				pod := podDemo.DeepCopy()
+				return pod
+			},
+			newPod: func() *corev1.Pod {
+				pod := podDemo.DeepCopy()
+				pod.Spec.Containers[0].Resources.Limits = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")}
+				return pod
+			},
+			subresource: "resize",
+			pub: func() *policyv1alpha1.PodUnavailableBudget {
+				pub := pubDemo.DeepCopy()
+				pub.Status.CurrentAvailable = 8
+				pub.Status.UnavailableAllowed = 1
+				return pub
+			},
+			expectAllow: true,
+			expectPubStatus: func() *policyv1alpha1.PodUnavailableBudgetStatus {
+				pubStatus := pubDemo.Status.DeepCopy()
+				pubStatus.UnavailablePods["test-pod-0"] = metav1.Now()
+				pubStatus.CurrentAvailable = 8
+				pubStatus.UnavailableAllowed = 0
+				return pubStatus
+			},
+		},
+		{
+			name: "resize pod budgeted by workload, allow",
+			oldPod: func() *corev1.Pod {
+				pod := podDemo.DeepCopy()
+				pod.Labels[apps.DefaultDeploymentUniqueLabelKey] = "v1"
+				pod.Annotations[pubcontrol.PodPubBudgetLeaseAnnotation] = "pub-uid/v2"
+				return pod
+			},
+			newPod: func() *corev1.Pod {
+				pod := podDemo.DeepCopy()
+				pod.Labels[apps.DefaultDeploymentUniqueLabelKey] = "v1"
+				pod.Annotations[pubcontrol.PodPubBudgetLeaseAnnotation] = "pub-uid/v2"
+				pod.Spec.Containers[0].Resources.Limits = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")}
+				return pod
+			},
+			subresource: "resize",
+			pub: func() *policyv1alpha1.PodUnavailableBudget {
+				pub := pubDemo.DeepCopy()
+				pub.UID = "pub-uid"
+				pub.Status.CurrentAvailable = 8
+				pub.Status.UnavailableAllowed = 1
+				return pub
+			},
+			expectAllow: true,
+			expectPubStatus: func() *policyv1alpha1.PodUnavailableBudgetStatus {
+				pubStatus := pubDemo.Status.DeepCopy()
+				pubStatus.CurrentAvailable = 8
+				pubStatus.UnavailableAllowed = 1
+				return pubStatus
+			},
+		},
 		{
 			name: "valid update pod, reject",
 			oldPod: func() *corev1.Pod {
@@ -431,6 +492,8 @@ func TestValidateUpdatePodForPub(t *testing.T) {
 				Client:     fClient,
 				Decoder:    decoder,
 				pubControl: pubcontrol.NewPubControl(fClient),
+				// records the events of pub and pod when the operation is rejected
+				eventRecorder: record.NewFakeRecorder(10),
 			}
 			oldPodRaw := runtime.RawExtension{
 				Raw: []byte(util.DumpJSON(cs.oldPod())),
@@ -442,13 +505,25 @@ func TestValidateUpdatePodForPub(t *testing.T) {
 			req.Options = runtime.RawExtension{
 				Raw: []byte(util.DumpJSON(metav1.UpdateOptions{})),
 			}
-			allow, _, err := podHandler.podUnavailableBudgetValidatingPod(context.TODO(), req)
+			allow, _, auditAnnotations, err := podHandler.podUnavailableBudgetValidatingPod(context.TODO(), req)
 			if err != nil {
 				t.Errorf("Pub validate pod failed: %s", err.Error())
 			}
 			if allow != cs.expectAllow {
 				t.Fatalf("expect allow(%v) but get(%v)", cs.expectAllow, allow)
 			}
+			if !allow && auditAnnotations[pubcontrol.AuditAnnotationPubName] != fmt.Sprintf("%s/%s", cs.pub().Namespace, cs.pub().Name) {
+				t.Fatalf("expect audit annotations of pub, but get(%v)", auditAnnotations)
+			} else if allow && auditAnnotations != nil {
+				t.Fatalf("expect no audit annotations, but get(%v)", auditAnnotations)
+			}
+			expectEvents := 0
+			if !allow {
+				expectEvents = 2
+			}
+			if events := len(podHandler.eventRecorder.(*record.FakeRecorder).Events); events != expectEvents {
+				t.Fatalf("expect events(%d) but get(%d)", expectEvents, events)
+			}
 			newPub, err := getLatestPub(fClient, cs.pub())
 			if err != nil {
 				t.Errorf("get latest pub failed: %s", err.Error())
@@ -523,6 +598,7 @@ func TestValidateEvictPodForPub(t *testing.T) {
 			expectPubStatus: func() *policyv1alpha1.PodUnavailableBudgetStatus {
 				pubStatus := pubDemo.Status.DeepCopy()
 				pubStatus.DisruptedPods["test-pod-0"] = metav1.Now()
+				pubStatus.EvictedPods = []string{"test-pod-0"}
 				pubStatus.CurrentAvailable = 8
 				pubStatus.UnavailableAllowed = 0
 				return pubStatus
@@ -594,7 +670,7 @@ func TestValidateEvictPodForPub(t *testing.T) {
 				Raw: []byte(util.DumpJSON(cs.eviction())),
 			}
 			req := newAdmission(cs.newPod().Namespace, cs.newPod().Name, admissionv1.Create, evictionRaw, runtime.RawExtension{}, cs.subresource)
-			allow, _, err := podHandler.podUnavailableBudgetValidatingPod(context.TODO(), req)
+			allow, _, _, err := podHandler.podUnavailableBudgetValidatingPod(context.TODO(), req)
 			if err != nil {
 				t.Errorf("Pub validate pod failed: %s", err.Error())
 			}
@@ -733,7 +809,7 @@ func TestValidateDeletePodForPub(t *testing.T) {
 			}
 			req := newAdmission(cs.newPod().Namespace, cs.newPod().Name, admissionv1.Delete, runtime.RawExtension{}, podRaw, cs.subresource)
 			req.AdmissionRequest.Options = deletionRaw
-			allow, _, err := podHandler.podUnavailableBudgetValidatingPod(context.TODO(), req)
+			allow, _, _, err := podHandler.podUnavailableBudgetValidatingPod(context.TODO(), req)
 			if err != nil {
 				t.Errorf("Pub validate pod failed: %s", err.Error())
 			}
@@ -793,3 +869,162 @@ func getLatestPub(client client.Client, pub *policyv1alpha1.PodUnavailableBudget
 	err := client.Get(context.TODO(), key, newPub)
 	return newPub, err
 }
+
+func TestValidateTaintEvictionPolicy(t *testing.T) {
+	defer func(policy string) { taintEvictionPolicy = policy }(taintEvictionPolicy)
+
+	cases := []struct {
+		policy    string
+		expectErr bool
+	}{
+		{policy: TaintEvictionPolicyEnforce},
+		{policy: TaintEvictionPolicyAccount},
+		{policy: "account", expectErr: true},
+		{policy: "", expectErr: true},
+	}
+	for _, cs := range cases {
+		taintEvictionPolicy = cs.policy
+		if err := ValidateTaintEvictionPolicy(); (err != nil) != cs.expectErr {
+			t.Fatalf("policy(%q) expect error(%v), but get(%v)", cs.policy, cs.expectErr, err)
+		}
+	}
+}
+
+func TestTaintEvictionForPub(t *testing.T) {
+	cases := []struct {
+		name        string
+		policy      string
+		username    string
+		taints      []corev1.Taint
+		expectAllow bool
+	}{
+		{
+			name:        "enforce policy, reject",
+			policy:      TaintEvictionPolicyEnforce,
+			username:    "system:serviceaccount:kube-system:node-controller",
+			taints:      []corev1.Taint{{Key: corev1.TaintNodeNotReady, Effect: corev1.TaintEffectNoExecute}},
+			expectAllow: false,
+		},
+		{
+			name:        "account policy, deleted by taint manager, allow",
+			policy:      TaintEvictionPolicyAccount,
+			username:    "system:serviceaccount:kube-system:node-controller",
+			taints:      []corev1.Taint{{Key: corev1.TaintNodeNotReady, Effect: corev1.TaintEffectNoExecute}},
+			expectAllow: true,
+		},
+		{
+			name:        "account policy, node without NoExecute taint, reject",
+			policy:      TaintEvictionPolicyAccount,
+			username:    "system:serviceaccount:kube-system:node-controller",
+			taints:      []corev1.Taint{{Key: corev1.TaintNodeUnschedulable, Effect: corev1.TaintEffectNoSchedule}},
+			expectAllow: false,
+		},
+		{
+			name:        "account policy, deleted by other user, reject",
+			policy:      TaintEvictionPolicyAccount,
+			username:    "admin",
+			taints:      []corev1.Taint{{Key: corev1.TaintNodeNotReady, Effect: corev1.TaintEffectNoExecute}},
+			expectAllow: false,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			defer func(policy string) { taintEvictionPolicy = policy }(taintEvictionPolicy)
+			taintEvictionPolicy = cs.policy
+			pod := podDemo.DeepCopy()
+			pod.Spec.NodeName = "node-1"
+			node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}, Spec: corev1.NodeSpec{Taints: cs.taints}}
+			decoder, _ := admission.NewDecoder(scheme)
+			fClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pubDemo.DeepCopy(), pod, node).Build()
+			podHandler := PodCreateHandler{
+				Client:     fClient,
+				Decoder:    decoder,
+				pubControl: pubcontrol.NewPubControl(fClient),
+			}
+			req := newAdmission(pod.Namespace, pod.Name, admissionv1.Delete, runtime.RawExtension{}, runtime.RawExtension{Raw: []byte(util.DumpJSON(pod))}, "")
+			req.AdmissionRequest.Options = runtime.RawExtension{Raw: []byte(util.DumpJSON(metav1.DeleteOptions{}))}
+			req.AdmissionRequest.UserInfo.Username = cs.username
+			allow, _, _, err := podHandler.podUnavailableBudgetValidatingPod(context.TODO(), req)
+			if err != nil {
+				t.Fatalf("Pub validate pod failed: %s", err.Error())
+			}
+			if allow != cs.expectAllow {
+				t.Fatalf("expect allow(%v) but get(%v)", cs.expectAllow, allow)
+			}
+			_ = util.GlobalCache.Delete(pubDemo)
+		})
+	}
+}
+
+func TestPubExemption(t *testing.T) {
+	cases := []struct {
+		name             string
+		exemptNamespaces string
+		exemptRequesters string
+		username         string
+		groups           []string
+		expectAllow      bool
+	}{
+		{
+			name:        "no exemption, reject",
+			username:    "admin",
+			expectAllow: false,
+		},
+		{
+			name:             "exempted namespace, allow",
+			exemptNamespaces: "ops,default",
+			username:         "admin",
+			expectAllow:      true,
+		},
+		{
+			name:             "exempted service account, allow",
+			exemptRequesters: "system:serviceaccount:ops:break-glass",
+			username:         "system:serviceaccount:ops:break-glass",
+			expectAllow:      true,
+		},
+		{
+			name:             "exempted group, allow",
+			exemptRequesters: "system:serviceaccounts:ops",
+			username:         "system:serviceaccount:ops:operator",
+			groups:           []string{"system:serviceaccounts", "system:serviceaccounts:ops"},
+			expectAllow:      true,
+		},
+		{
+			name:             "other requester, reject",
+			exemptNamespaces: "ops",
+			exemptRequesters: "system:serviceaccount:ops:break-glass",
+			username:         "admin",
+			expectAllow:      false,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			defer func(namespaces, requesters string) {
+				exemptNamespaces, exemptRequesters = namespaces, requesters
+			}(exemptNamespaces, exemptRequesters)
+			exemptNamespaces, exemptRequesters = cs.exemptNamespaces, cs.exemptRequesters
+			pod := podDemo.DeepCopy()
+			decoder, _ := admission.NewDecoder(scheme)
+			fClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pubDemo.DeepCopy(), pod).Build()
+			podHandler := PodCreateHandler{
+				Client:     fClient,
+				Decoder:    decoder,
+				pubControl: pubcontrol.NewPubControl(fClient),
+			}
+			req := newAdmission(pod.Namespace, pod.Name, admissionv1.Delete, runtime.RawExtension{}, runtime.RawExtension{Raw: []byte(util.DumpJSON(pod))}, "")
+			req.AdmissionRequest.Options = runtime.RawExtension{Raw: []byte(util.DumpJSON(metav1.DeleteOptions{}))}
+			req.AdmissionRequest.UserInfo.Username = cs.username
+			req.AdmissionRequest.UserInfo.Groups = cs.groups
+			allow, _, _, err := podHandler.podUnavailableBudgetValidatingPod(context.TODO(), req)
+			if err != nil {
+				t.Fatalf("Pub validate pod failed: %s", err.Error())
+			}
+			if allow != cs.expectAllow {
+				t.Fatalf("expect allow(%v) but get(%v)", cs.expectAllow, allow)
+			}
+			_ = util.GlobalCache.Delete(pubDemo)
+		})
+	}
+}
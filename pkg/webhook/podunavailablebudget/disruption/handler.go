@@ -0,0 +1,181 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/openkruise/kruise/pkg/control/pubcontrol"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kubeclientset "k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+)
+
+// Path is the path for node-drain tools to query whether a pod can be disrupted right now, e.g.
+// /pub-disruption-check?namespace=default&name=pod-0&operation=EVICT
+// operation is optional and defaults to EVICT.
+// The request must carry a bearer token, and the user of the token must be authorized to do the operation
+// for the pod, e.g. create pods/eviction for EVICT.
+const Path = "/pub-disruption-check"
+
+// Response is the result of disruption query
+type Response struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// Handler answers whether the operation for pod would be allowed by PodUnavailableBudget,
+// it never mutates PodUnavailableBudget status.
+type Handler struct {
+	Client client.Client
+	// KubeClient is used to authenticate and authorize the requests by TokenReview and SubjectAccessReview
+	KubeClient kubeclientset.Interface
+
+	pubControl pubcontrol.PubControl
+}
+
+var _ http.Handler = &Handler{}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, fmt.Sprintf("method %s is not allowed", r.Method), http.StatusMethodNotAllowed)
+		return
+	}
+	query := r.URL.Query()
+	namespace, name := query.Get("namespace"), query.Get("name")
+	if namespace == "" || name == "" {
+		http.Error(w, "namespace and name of pod are required", http.StatusBadRequest)
+		return
+	}
+	var operation pubcontrol.Operation = pubcontrol.EvictOperation
+	if op := strings.ToUpper(query.Get("operation")); op != "" {
+		switch op {
+		case pubcontrol.EvictOperation, pubcontrol.DeleteOperation, pubcontrol.UpdateOperation,
+			pubcontrol.RecreateOperation, pubcontrol.ResizeOperation:
+			operation = pubcontrol.Operation(op)
+		default:
+			http.Error(w, fmt.Sprintf("unknown operation %s", op), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if code, err := h.authorize(r, namespace, name, operation); err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	pod := &corev1.Pod{}
+	if err := h.Client.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: name}, pod); err != nil {
+		if errors.IsNotFound(err) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	allowed, reason, err := pubcontrol.IsPodDisruptionAllowed(h.Client, h.pubControl, pod, operation)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	klog.V(5).Infof("query pod(%s/%s) operation(%s) disruption, allowed(%v) reason(%s)", namespace, name, operation, allowed, reason)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(&Response{Allowed: allowed, Reason: reason}); err != nil {
+		klog.Errorf("Failed to write disruption query response: %v", err)
+	}
+}
+
+// +kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+// authorize authenticates the bearer token of request by TokenReview, and checks whether the user is allowed to
+// do the operation for the pod by SubjectAccessReview. It returns the http status code if not authorized.
+func (h *Handler) authorize(r *http.Request, namespace, name string, operation pubcontrol.Operation) (int, error) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") || strings.TrimSpace(strings.TrimPrefix(auth, "Bearer ")) == "" {
+		return http.StatusUnauthorized, fmt.Errorf("bearer token is required")
+	}
+	token := strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+	tokenReview, err := h.KubeClient.AuthenticationV1().TokenReviews().Create(context.TODO(),
+		&authenticationv1.TokenReview{Spec: authenticationv1.TokenReviewSpec{Token: token}}, metav1.CreateOptions{})
+	if err != nil {
+		klog.Errorf("Failed to review token for disruption query: %v", err)
+		return http.StatusInternalServerError, err
+	}
+	if !tokenReview.Status.Authenticated {
+		return http.StatusUnauthorized, fmt.Errorf("unauthenticated: %s", tokenReview.Status.Error)
+	}
+
+	user := tokenReview.Status.User
+	extra := make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+	for k, v := range user.Extra {
+		extra[k] = authorizationv1.ExtraValue(v)
+	}
+	accessReview, err := h.KubeClient.AuthorizationV1().SubjectAccessReviews().Create(context.TODO(), &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			ResourceAttributes: getResourceAttributes(namespace, name, operation),
+			User:               user.Username,
+			Groups:             user.Groups,
+			UID:                user.UID,
+			Extra:              extra,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		klog.Errorf("Failed to review access for disruption query: %v", err)
+		return http.StatusInternalServerError, err
+	}
+	if !accessReview.Status.Allowed {
+		return http.StatusForbidden, fmt.Errorf("user %s is not allowed to %s pod %s/%s", user.Username, operation, namespace, name)
+	}
+	return http.StatusOK, nil
+}
+
+// getResourceAttributes returns the attributes that the requester must be authorized for the operation.
+func getResourceAttributes(namespace, name string, operation pubcontrol.Operation) *authorizationv1.ResourceAttributes {
+	attributes := &authorizationv1.ResourceAttributes{Namespace: namespace, Name: name, Resource: "pods"}
+	switch operation {
+	case pubcontrol.EvictOperation:
+		attributes.Verb = "create"
+		attributes.Subresource = "eviction"
+	case pubcontrol.DeleteOperation:
+		attributes.Verb = "delete"
+	default:
+		attributes.Verb = "update"
+	}
+	return attributes
+}
+
+var _ inject.Client = &Handler{}
+
+// InjectClient injects the client into the Handler
+func (h *Handler) InjectClient(c client.Client) error {
+	h.Client = c
+	h.pubControl = pubcontrol.NewPubControl(c)
+	return nil
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2022 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
+	"github.com/openkruise/kruise/pkg/control/pubcontrol"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var (
+	scheme *runtime.Scheme
+
+	pubDemo = &policyv1alpha1.PodUnavailableBudget{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pub-test"},
+		Spec: policyv1alpha1.PodUnavailableBudgetSpec{
+			Selector:       &metav1.LabelSelector{MatchLabels: map[string]string{"app": "nginx"}},
+			MaxUnavailable: &intstr.IntOrString{Type: intstr.Int, IntVal: 1},
+		},
+	}
+
+	podDemo = &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "test-pod",
+			Labels:      map[string]string{"app": "nginx"},
+			Annotations: map[string]string{pubcontrol.PodRelatedPubAnnotation: "pub-test"},
+		},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+)
+
+func init() {
+	scheme = runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = policyv1alpha1.AddToScheme(scheme)
+}
+
+func TestDisruptionQuery(t *testing.T) {
+	cases := []struct {
+		name               string
+		query              string
+		unavailableAllowed int32
+		token              string
+		expectCode         int
+		expectAllowed      bool
+	}{
+		{
+			name:       "token missing",
+			query:      "namespace=default&name=test-pod",
+			token:      "-",
+			expectCode: http.StatusUnauthorized,
+		},
+		{
+			name:       "token unauthenticated",
+			query:      "namespace=default&name=test-pod",
+			token:      "invalid-token",
+			expectCode: http.StatusUnauthorized,
+		},
+		{
+			name:       "user not allowed to delete pod",
+			query:      "namespace=default&name=test-pod&operation=delete",
+			token:      "viewer-token",
+			expectCode: http.StatusForbidden,
+		},
+		{
+			name:               "eviction allowed",
+			query:              "namespace=default&name=test-pod",
+			unavailableAllowed: 1,
+			expectCode:         http.StatusOK,
+			expectAllowed:      true,
+		},
+		{
+			name:               "deletion rejected",
+			query:              "namespace=default&name=test-pod&operation=delete",
+			unavailableAllowed: 0,
+			expectCode:         http.StatusOK,
+			expectAllowed:      false,
+		},
+		{
+			name:       "pod not found",
+			query:      "namespace=default&name=not-found",
+			expectCode: http.StatusNotFound,
+		},
+		{
+			name:       "unknown operation",
+			query:      "namespace=default&name=test-pod&operation=patch",
+			expectCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			pub := pubDemo.DeepCopy()
+			pub.Status.UnavailableAllowed = cs.unavailableAllowed
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pub, podDemo.DeepCopy()).Build()
+			handler := &Handler{KubeClient: newFakeKubeClient()}
+			_ = handler.InjectClient(fakeClient)
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, Path+"?"+cs.query, nil)
+			switch cs.token {
+			case "":
+				request.Header.Set("Authorization", "Bearer admin-token")
+			case "-":
+			default:
+				request.Header.Set("Authorization", "Bearer "+cs.token)
+			}
+			handler.ServeHTTP(recorder, request)
+			if recorder.Code != cs.expectCode {
+				t.Fatalf("expect code(%d), but get(%d): %s", cs.expectCode, recorder.Code, recorder.Body.String())
+			}
+			if recorder.Code != http.StatusOK {
+				return
+			}
+			resp := &Response{}
+			if err := json.Unmarshal(recorder.Body.Bytes(), resp); err != nil {
+				t.Fatalf("unmarshal response failed: %s", err.Error())
+			}
+			if resp.Allowed != cs.expectAllowed {
+				t.Fatalf("expect allowed(%v), but get(%v)", cs.expectAllowed, resp.Allowed)
+			}
+			// dry run never mutates pub status
+			newPub := &policyv1alpha1.PodUnavailableBudget{}
+			_ = fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: pub.Namespace, Name: pub.Name}, newPub)
+			if newPub.Status.UnavailableAllowed != cs.unavailableAllowed || len(newPub.Status.DisruptedPods) != 0 {
+				t.Fatalf("expect pub status not changed, but get(%v)", newPub.Status)
+			}
+		})
+	}
+}
+
+// newFakeKubeClient returns a fake clientset that authenticates admin-token and viewer-token,
+// and only the admin is allowed to do any operation.
+func newFakeKubeClient() *kubefake.Clientset {
+	kubeClient := kubefake.NewSimpleClientset()
+	kubeClient.PrependReactor("create", "tokenreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		review := action.(clienttesting.CreateAction).GetObject().(*authenticationv1.TokenReview).DeepCopy()
+		switch review.Spec.Token {
+		case "admin-token":
+			review.Status = authenticationv1.TokenReviewStatus{Authenticated: true, User: authenticationv1.UserInfo{Username: "admin"}}
+		case "viewer-token":
+			review.Status = authenticationv1.TokenReviewStatus{Authenticated: true, User: authenticationv1.UserInfo{Username: "viewer"}}
+		}
+		return true, review, nil
+	})
+	kubeClient.PrependReactor("create", "subjectaccessreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		review := action.(clienttesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview).DeepCopy()
+		review.Status.Allowed = review.Spec.User == "admin" ||
+			(review.Spec.ResourceAttributes.Verb == "get" && review.Spec.ResourceAttributes.Resource == "pods")
+		return true, review, nil
+	})
+	return kubeClient
+}
@@ -145,6 +145,18 @@ func validatePodUnavailableBudgetSpec(obj *policyv1alpha1.PodUnavailableBudget,
 		allErrs = append(allErrs, appsvalidation.ValidatePositiveIntOrPercent(*spec.MinAvailable, fldPath.Child("minAvailable"))...)
 		allErrs = append(allErrs, appsvalidation.IsNotMoreThan100Percent(*spec.MinAvailable, fldPath.Child("minAvailable"))...)
 	}
+	if spec.StaleTimeoutSeconds != nil && *spec.StaleTimeoutSeconds <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("staleTimeoutSeconds"), *spec.StaleTimeoutSeconds, "must be greater than 0"))
+	}
+	if policy := spec.FailurePolicy; policy != nil {
+		if policy.Type != "" && policy.Type != policyv1alpha1.PubFailurePolicyFail && policy.Type != policyv1alpha1.PubFailurePolicyIgnore {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("failurePolicy", "type"), policy.Type,
+				[]string{string(policyv1alpha1.PubFailurePolicyFail), string(policyv1alpha1.PubFailurePolicyIgnore)}))
+		}
+		if policy.TimeoutMilliseconds != nil && *policy.TimeoutMilliseconds <= 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("failurePolicy", "timeoutMilliseconds"), *policy.TimeoutMilliseconds, "must be greater than 0"))
+		}
+	}
 	return allErrs
 }
 
@@ -22,6 +22,10 @@ import (
 	"net/http"
 	"time"
 
+	kubeClient "github.com/openkruise/kruise/pkg/client"
+	"github.com/openkruise/kruise/pkg/features"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
+	"github.com/openkruise/kruise/pkg/webhook/podunavailablebudget/disruption"
 	webhookutil "github.com/openkruise/kruise/pkg/webhook/util"
 	webhookcontroller "github.com/openkruise/kruise/pkg/webhook/util/controller"
 	"github.com/openkruise/kruise/pkg/webhook/util/health"
@@ -99,6 +103,11 @@ func SetupWithManager(mgr manager.Manager) error {
 	// register health handler
 	server.Register("/healthz", &health.Handler{})
 
+	// register pub disruption query handler
+	if utilfeature.DefaultFeatureGate.Enabled(features.PodUnavailableBudgetDeleteGate) {
+		server.Register(disruption.Path, &disruption.Handler{KubeClient: kubeClient.GetGenericClient().KubeClient})
+	}
+
 	return nil
 }
 
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"path"
 	"reflect"
 	"regexp"
 	"strings"
@@ -97,6 +98,10 @@ func (h *SidecarSetCreateUpdateHandler) validateSidecarSet(obj *appsv1alpha1.Sid
 		allErrs = append(allErrs, field.InternalError(field.NewPath(""), fmt.Errorf("query other sidecarsets failed, err: %v", err)))
 	}
 	allErrs = append(allErrs, validateSidecarConflict(sidecarSets, obj, field.NewPath("spec"))...)
+	// the overrides in namespaces must be still valid for the new sidecars
+	if err := sidecarcontrol.ValidateSidecarSetOverrides(h.Client, obj); err != nil {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec"), obj.Name, err.Error()))
+	}
 	return allErrs
 }
 
@@ -120,6 +125,25 @@ func validateSidecarSetSpec(obj *appsv1alpha1.SidecarSet, fldPath *field.Path) f
 	} else {
 		allErrs = append(allErrs, validateSelector(spec.Selector, fldPath.Child("selector"))...)
 	}
+	//validate spec namespaceSelector
+	if spec.NamespaceSelector != nil {
+		if spec.Namespace != "" {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("namespaceSelector"), "namespace and namespaceSelector are mutually exclusive"))
+		} else {
+			allErrs = append(allErrs, validateSelector(spec.NamespaceSelector, fldPath.Child("namespaceSelector"))...)
+		}
+	}
+	//validating SidecarSetInjectionStrategy
+	if percent := spec.InjectionStrategy.Percent; percent != nil && (*percent < 0 || *percent > 100) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("injectionStrategy", "percent"), *percent, "percent must be in the range of [0, 100]"))
+	}
+	if spec.InjectionStrategy.Ephemeral {
+		allErrs = append(allErrs, validateEphemeralSidecarSet(spec, fldPath)...)
+	}
+	//validating SidecarSetUninjectionStrategy
+	if spec.UninjectionStrategy != nil && spec.UninjectionStrategy.EmptyImage == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("uninjectionStrategy", "emptyImage"), "emptyImage is required for uninjection"))
+	}
 	//validating SidecarSetUpdateStrategy
 	allErrs = append(allErrs, validateSidecarSetUpdateStrategy(&spec.UpdateStrategy, fldPath.Child("strategy"))...)
 	//validating volumes
@@ -136,6 +160,52 @@ func validateSidecarSetSpec(obj *appsv1alpha1.SidecarSet, fldPath *field.Path) f
 	return allErrs
 }
 
+// validateEphemeralSidecarSet validates the sidecarSet whose containers are injected as ephemeral containers,
+// which can not have initContainers, hot upgrade, ports, probes, lifecycle or resources.
+func validateEphemeralSidecarSet(spec *appsv1alpha1.SidecarSetSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if len(spec.InitContainers) > 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("initContainers"), "not allowed for ephemeral sidecarSet"))
+	}
+	if spec.UninjectionStrategy != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("uninjectionStrategy"), "not allowed for ephemeral sidecarSet"))
+	}
+	// ephemeral containers can not add volumes into pod
+	sidecarSetVolumes := sets.NewString()
+	for _, volume := range spec.Volumes {
+		sidecarSetVolumes.Insert(volume.Name)
+	}
+	if len(spec.Volumes) > 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("volumes"), "not allowed for ephemeral sidecarSet"))
+	}
+	for i := range spec.Containers {
+		container := &spec.Containers[i]
+		idxPath := fldPath.Child("containers").Index(i)
+		for j, mount := range container.VolumeMounts {
+			if sidecarSetVolumes.Has(mount.Name) {
+				allErrs = append(allErrs, field.Forbidden(idxPath.Child("volumeMounts").Index(j),
+					fmt.Sprintf("volume %s of sidecarSet is not allowed for ephemeral containers", mount.Name)))
+			}
+		}
+		if container.UpgradeStrategy.UpgradeType == appsv1alpha1.SidecarContainerHotUpgrade {
+			allErrs = append(allErrs, field.Forbidden(idxPath.Child("upgradeStrategy"), "hot upgrade is not allowed for ephemeral sidecarSet"))
+		}
+		if len(container.Ports) > 0 {
+			allErrs = append(allErrs, field.Forbidden(idxPath.Child("ports"), "not allowed for ephemeral containers"))
+		}
+		if container.LivenessProbe != nil || container.ReadinessProbe != nil || container.StartupProbe != nil {
+			allErrs = append(allErrs, field.Forbidden(idxPath, "probes are not allowed for ephemeral containers"))
+		}
+		if container.Lifecycle != nil {
+			allErrs = append(allErrs, field.Forbidden(idxPath.Child("lifecycle"), "not allowed for ephemeral containers"))
+		}
+		if len(container.Resources.Limits) > 0 || len(container.Resources.Requests) > 0 {
+			allErrs = append(allErrs, field.Forbidden(idxPath.Child("resources"), "not allowed for ephemeral containers"))
+		}
+	}
+	return allErrs
+}
+
 func validateSelector(selector *metav1.LabelSelector, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 	allErrs = append(allErrs, metavalidation.ValidateLabelSelector(selector, fldPath)...)
@@ -156,6 +226,9 @@ func validateSidecarSetUpdateStrategy(strategy *appsv1alpha1.SidecarSetUpdateStr
 		if strategy.Selector != nil {
 			allErrs = append(allErrs, validateSelector(strategy.Selector, fldPath.Child("selector"))...)
 		}
+		if strategy.PausedSelector != nil {
+			allErrs = append(allErrs, validateSelector(strategy.PausedSelector, fldPath.Child("pausedSelector"))...)
+		}
 		if strategy.Partition != nil {
 			allErrs = append(allErrs, appsvalidation.ValidatePositiveIntOrPercent(*(strategy.Partition), fldPath.Child("partition"))...)
 		}
@@ -167,6 +240,12 @@ func validateSidecarSetUpdateStrategy(strategy *appsv1alpha1.SidecarSetUpdateStr
 				allErrs = append(allErrs, field.Required(fldPath.Child("scatterStrategy"), err.Error()))
 			}
 		}
+		for i, key := range strategy.TopologyScatterKeys {
+			allErrs = append(allErrs, metavalidation.ValidateLabelName(key, fldPath.Child("topologyScatterKeys").Index(i))...)
+		}
+	}
+	if strategy.RollbackTo != nil && strategy.RollbackTo.Revision < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("rollbackTo", "revision"), strategy.RollbackTo.Revision, "must be non-negative"))
 	}
 	return allErrs
 }
@@ -178,7 +257,16 @@ func validateContainersForSidecarSet(
 	allErrs := field.ErrorList{}
 	//validating initContainer
 	var coreInitContainers []core.Container
-	for _, container := range initContainers {
+	for i, container := range initContainers {
+		idxPath := fldPath.Child("initContainers").Index(i)
+		switch container.InitContainerInjectPolicy {
+		case "", appsv1alpha1.BeforeAppContainerType, appsv1alpha1.AfterAppContainerType:
+		default:
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("initContainerInjectPolicy"), container.InitContainerInjectPolicy, "unsupported init container inject policy"))
+		}
+		if container.LaunchPriority != nil {
+			allErrs = append(allErrs, field.Forbidden(idxPath.Child("launchPriority"), "not allowed in initContainers"))
+		}
 		coreContainer := core.Container{}
 		if err := corev1.Convert_v1_Container_To_core_Container(&container.Container, &coreContainer, nil); err != nil {
 			allErrs = append(allErrs, field.Invalid(fldPath.Child("initContainer"), container.Container, fmt.Sprintf("Convert_v1_Container_To_core_Container failed: %v", err)))
@@ -197,7 +285,9 @@ func validateContainersForSidecarSet(
 		if container.ShareVolumePolicy.Type != appsv1alpha1.ShareVolumePolicyEnabled && container.ShareVolumePolicy.Type != appsv1alpha1.ShareVolumePolicyDisabled {
 			allErrs = append(allErrs, field.Invalid(fldPath.Child("container").Child("shareVolumePolicy"), container.ShareVolumePolicy, "unsupported share volume policy"))
 		}
+		allErrs = append(allErrs, validateShareVolumePolicy(&container.ShareVolumePolicy, idxPath.Child("shareVolumePolicy"))...)
 		allErrs = append(allErrs, validateDownwardAPI(container.TransferEnv, idxPath.Child("transferEnv"))...)
+		allErrs = append(allErrs, validateHotUpgradeStrategy(&container.UpgradeStrategy, idxPath.Child("upgradeStrategy"))...)
 		coreContainer := core.Container{}
 		if err := corev1.Convert_v1_Container_To_core_Container(&container.Container, &coreContainer, nil); err != nil {
 			allErrs = append(allErrs, field.Invalid(fldPath.Child("container"), container.Container, fmt.Sprintf("Convert_v1_Container_To_core_Container failed: %v", err)))
@@ -236,6 +326,67 @@ func validateContainersForSidecarSet(
 	return allErrs
 }
 
+func validateShareVolumePolicy(policy *appsv1alpha1.ShareVolumePolicy, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if policy.Type != appsv1alpha1.ShareVolumePolicyEnabled &&
+		(len(policy.IncludeVolumes) > 0 || len(policy.ExcludeVolumes) > 0 || len(policy.MountOverrides) > 0) {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "includeVolumes, excludeVolumes and mountOverrides are only allowed when type is enabled"))
+	}
+	for i, name := range policy.IncludeVolumes {
+		if name == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("includeVolumes").Index(i), ""))
+		}
+	}
+	for i, name := range policy.ExcludeVolumes {
+		if name == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("excludeVolumes").Index(i), ""))
+		}
+	}
+	volumeNames := sets.NewString()
+	for i, override := range policy.MountOverrides {
+		idxPath := fldPath.Child("mountOverrides").Index(i)
+		if override.VolumeName == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("volumeName"), ""))
+		} else if volumeNames.Has(override.VolumeName) {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("volumeName"), override.VolumeName))
+		}
+		volumeNames.Insert(override.VolumeName)
+		if override.SubPath != nil && (path.IsAbs(*override.SubPath) || sets.NewString(strings.Split(*override.SubPath, "/")...).Has("..")) {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("subPath"), *override.SubPath, "must be a relative path without '..'"))
+		}
+	}
+	return allErrs
+}
+
+func validateHotUpgradeStrategy(strategy *appsv1alpha1.SidecarContainerUpgradeStrategy, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if strategy.UpgradeType != appsv1alpha1.SidecarContainerHotUpgrade {
+		if strategy.HotUpgradeMigrationHook != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("hotUpgradeMigrationHook"), "only allowed for HotUpgrade"))
+		}
+		if strategy.HotUpgradeMinReadySeconds != 0 {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("hotUpgradeMinReadySeconds"), "only allowed for HotUpgrade"))
+		}
+		return allErrs
+	}
+	if strategy.HotUpgradeMinReadySeconds < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("hotUpgradeMinReadySeconds"), strategy.HotUpgradeMinReadySeconds, "must be non-negative"))
+	}
+	if hook := strategy.HotUpgradeMigrationHook; hook != nil {
+		hookPath := fldPath.Child("hotUpgradeMigrationHook")
+		if hook.TCPSocket != nil {
+			allErrs = append(allErrs, field.Forbidden(hookPath.Child("tcpSocket"), "only exec or httpGet is supported"))
+		} else if (hook.Exec == nil) == (hook.HTTPGet == nil) {
+			allErrs = append(allErrs, field.Invalid(hookPath, hook, "must specify exactly one of exec and httpGet"))
+		} else if hook.Exec != nil && len(hook.Exec.Command) == 0 {
+			allErrs = append(allErrs, field.Required(hookPath.Child("exec", "command"), ""))
+		} else if hook.HTTPGet != nil && hook.HTTPGet.Host != "" {
+			allErrs = append(allErrs, field.Forbidden(hookPath.Child("httpGet", "host"), "httpGet is always sent to the pod ip"))
+		}
+	}
+	return allErrs
+}
+
 func validateSidecarContainerConflict(newContainers, oldContainers []appsv1alpha1.SidecarContainer, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 
@@ -2,6 +2,7 @@ package validating
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
@@ -9,7 +10,9 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	utilpointer "k8s.io/utils/pointer"
 )
 
 func TestValidateSidecarSet(t *testing.T) {
@@ -109,6 +112,134 @@ func TestValidateSidecarSet(t *testing.T) {
 				},
 			},
 		},
+		"namespace-with-namespaceSelector": {
+			ObjectMeta: metav1.ObjectMeta{Name: "test-sidecarset"},
+			Spec: appsv1alpha1.SidecarSetSpec{
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"a": "b"},
+				},
+				Namespace: "test-ns",
+				NamespaceSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"a": "b"},
+				},
+				UpdateStrategy: appsv1alpha1.SidecarSetUpdateStrategy{
+					Type: appsv1alpha1.NotUpdateSidecarSetStrategyType,
+				},
+				Containers: []appsv1alpha1.SidecarContainer{
+					{
+						PodInjectPolicy: appsv1alpha1.BeforeAppContainerType,
+						ShareVolumePolicy: appsv1alpha1.ShareVolumePolicy{
+							Type: appsv1alpha1.ShareVolumePolicyDisabled,
+						},
+						UpgradeStrategy: appsv1alpha1.SidecarContainerUpgradeStrategy{
+							UpgradeType: appsv1alpha1.SidecarContainerColdUpgrade,
+						},
+						Container: corev1.Container{
+							Name:                     "test-sidecar",
+							Image:                    "test-image",
+							ImagePullPolicy:          corev1.PullIfNotPresent,
+							TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+						},
+					},
+				},
+			},
+		},
+		"hot-upgrade-migration-hook-with-tcpSocket": {
+			ObjectMeta: metav1.ObjectMeta{Name: "test-sidecarset"},
+			Spec: appsv1alpha1.SidecarSetSpec{
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"a": "b"},
+				},
+				UpdateStrategy: appsv1alpha1.SidecarSetUpdateStrategy{
+					Type: appsv1alpha1.NotUpdateSidecarSetStrategyType,
+				},
+				Containers: []appsv1alpha1.SidecarContainer{
+					{
+						PodInjectPolicy: appsv1alpha1.BeforeAppContainerType,
+						ShareVolumePolicy: appsv1alpha1.ShareVolumePolicy{
+							Type: appsv1alpha1.ShareVolumePolicyDisabled,
+						},
+						UpgradeStrategy: appsv1alpha1.SidecarContainerUpgradeStrategy{
+							UpgradeType:          appsv1alpha1.SidecarContainerHotUpgrade,
+							HotUpgradeEmptyImage: "empty:v1",
+							HotUpgradeMigrationHook: &appsv1alpha1.ProbeHandler{
+								TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(80)},
+							},
+						},
+						Container: corev1.Container{
+							Name:                     "test-sidecar",
+							Image:                    "test-image",
+							ImagePullPolicy:          corev1.PullIfNotPresent,
+							TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+						},
+					},
+				},
+			},
+		},
+		"hot-upgrade-migration-hook-with-httpGet-host": {
+			ObjectMeta: metav1.ObjectMeta{Name: "test-sidecarset"},
+			Spec: appsv1alpha1.SidecarSetSpec{
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"a": "b"},
+				},
+				UpdateStrategy: appsv1alpha1.SidecarSetUpdateStrategy{
+					Type: appsv1alpha1.NotUpdateSidecarSetStrategyType,
+				},
+				Containers: []appsv1alpha1.SidecarContainer{
+					{
+						PodInjectPolicy: appsv1alpha1.BeforeAppContainerType,
+						ShareVolumePolicy: appsv1alpha1.ShareVolumePolicy{
+							Type: appsv1alpha1.ShareVolumePolicyDisabled,
+						},
+						UpgradeStrategy: appsv1alpha1.SidecarContainerUpgradeStrategy{
+							UpgradeType:          appsv1alpha1.SidecarContainerHotUpgrade,
+							HotUpgradeEmptyImage: "empty:v1",
+							HotUpgradeMigrationHook: &appsv1alpha1.ProbeHandler{
+								HTTPGet: &corev1.HTTPGetAction{Host: "10.0.0.1", Port: intstr.FromInt(80)},
+							},
+						},
+						Container: corev1.Container{
+							Name:                     "test-sidecar",
+							Image:                    "test-image",
+							ImagePullPolicy:          corev1.PullIfNotPresent,
+							TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+						},
+					},
+				},
+			},
+		},
+		"duplicate-share-volume-mount-overrides": {
+			ObjectMeta: metav1.ObjectMeta{Name: "test-sidecarset"},
+			Spec: appsv1alpha1.SidecarSetSpec{
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"a": "b"},
+				},
+				UpdateStrategy: appsv1alpha1.SidecarSetUpdateStrategy{
+					Type: appsv1alpha1.NotUpdateSidecarSetStrategyType,
+				},
+				Containers: []appsv1alpha1.SidecarContainer{
+					{
+						PodInjectPolicy: appsv1alpha1.BeforeAppContainerType,
+						ShareVolumePolicy: appsv1alpha1.ShareVolumePolicy{
+							Type: appsv1alpha1.ShareVolumePolicyEnabled,
+							MountOverrides: []appsv1alpha1.ShareVolumeMountOverride{
+								{VolumeName: "logs", ReadOnly: utilpointer.BoolPtr(true)},
+								{VolumeName: "logs", ReadOnly: utilpointer.BoolPtr(false)},
+							},
+						},
+						UpgradeStrategy: appsv1alpha1.SidecarContainerUpgradeStrategy{
+							UpgradeType: appsv1alpha1.SidecarContainerColdUpgrade,
+						},
+						Container: corev1.Container{
+							Name:                     "test-sidecar",
+							Image:                    "test-image",
+							ImagePullPolicy:          corev1.PullIfNotPresent,
+							TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+						},
+					},
+				},
+			},
+		},
 		"wrong-initContainer": {
 			ObjectMeta: metav1.ObjectMeta{Name: "test-sidecarset"},
 			Spec: appsv1alpha1.SidecarSetSpec{
@@ -567,3 +698,48 @@ func TestSidecarSetVolumeConflict(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateEphemeralSidecarSetVolumes(t *testing.T) {
+	newSpec := func(volumes []corev1.Volume, mounts []corev1.VolumeMount) *appsv1alpha1.SidecarSetSpec {
+		return &appsv1alpha1.SidecarSetSpec{
+			InjectionStrategy: appsv1alpha1.SidecarSetInjectionStrategy{Ephemeral: true},
+			Containers: []appsv1alpha1.SidecarContainer{
+				{Container: corev1.Container{Name: "test-sidecar", Image: "test-image", VolumeMounts: mounts}},
+			},
+			Volumes: volumes,
+		}
+	}
+	sidecarSetVolume := corev1.Volume{Name: "sidecar-volume", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}
+	cases := []struct {
+		name         string
+		spec         *appsv1alpha1.SidecarSetSpec
+		expectFields []string
+	}{
+		{
+			name: "mount volume of pod",
+			spec: newSpec(nil, []corev1.VolumeMount{{Name: "pod-volume", MountPath: "/data"}}),
+		},
+		{
+			name:         "volumes of sidecarSet",
+			spec:         newSpec([]corev1.Volume{sidecarSetVolume}, nil),
+			expectFields: []string{"spec.volumes"},
+		},
+		{
+			name:         "mount volume of sidecarSet",
+			spec:         newSpec([]corev1.Volume{sidecarSetVolume}, []corev1.VolumeMount{{Name: "sidecar-volume", MountPath: "/data"}}),
+			expectFields: []string{"spec.volumes", "spec.containers[0].volumeMounts[0]"},
+		},
+	}
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			allErrs := validateEphemeralSidecarSet(cs.spec, field.NewPath("spec"))
+			var fields []string
+			for _, err := range allErrs {
+				fields = append(fields, err.Field)
+			}
+			if !reflect.DeepEqual(fields, cs.expectFields) {
+				t.Fatalf("expect errors of %v, but got %v", cs.expectFields, allErrs)
+			}
+		})
+	}
+}
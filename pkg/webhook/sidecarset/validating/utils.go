@@ -20,6 +20,7 @@ import (
 	"fmt"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/util"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
@@ -43,8 +44,15 @@ func getCoreVolumes(volumes []v1.Volume, fldPath *field.Path) ([]core.Volume, fi
 	return coreVolumes, allErrs
 }
 
+// isSidecarSetNamespaceDiff returns true only if the namespaces of the two sidecarSets can not overlap.
+// A namespace and a namespaceSelector are considered overlapping, for the labels of namespace may change.
 func isSidecarSetNamespaceDiff(origin *appsv1alpha1.SidecarSet, other *appsv1alpha1.SidecarSet) bool {
 	originNamespace := origin.Spec.Namespace
 	otherNamespace := other.Spec.Namespace
-	return originNamespace != "" && otherNamespace != "" && originNamespace != otherNamespace
+	if originNamespace != "" && otherNamespace != "" {
+		return originNamespace != otherNamespace
+	}
+	originSelector := origin.Spec.NamespaceSelector
+	otherSelector := other.Spec.NamespaceSelector
+	return originSelector != nil && otherSelector != nil && !util.IsSelectorOverlapping(originSelector, otherSelector)
 }
@@ -4,10 +4,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"sort"
 
 	"github.com/appscode/jsonpatch"
 	appspub "github.com/openkruise/kruise/apis/apps/pub"
 	appsv1beta1 "github.com/openkruise/kruise/apis/apps/v1beta1"
+	"github.com/openkruise/kruise/pkg/util"
 	webhookutil "github.com/openkruise/kruise/pkg/webhook/util"
 	apps "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
@@ -17,6 +19,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 	intstrutil "k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	appsvalidation "k8s.io/kubernetes/pkg/apis/apps/validation"
 	apivalidation "k8s.io/kubernetes/pkg/apis/core/validation"
@@ -39,6 +42,10 @@ func validatePodManagementPolicy(spec *appsv1beta1.StatefulSetSpec, fldPath *fie
 	return allErrs
 }
 
+// maxReservedOrdinals is the max number of ordinals reserved by reserveOrdinals and reserveOrdinalRanges,
+// for the controller indexes the Pods by ordinal up to the last one in use.
+const maxReservedOrdinals = 10000
+
 func validateReserveOrdinals(spec *appsv1beta1.StatefulSetSpec, fldPath *field.Path) field.ErrorList {
 	var allErrs field.ErrorList
 
@@ -53,6 +60,65 @@ func validateReserveOrdinals(spec *appsv1beta1.StatefulSetSpec, fldPath *field.P
 			}
 		}
 	}
+
+	// the ranges are validated as intervals without being expanded
+	var ranges []util.OrdinalRange
+	count := len(spec.ReserveOrdinals)
+	for i, item := range spec.ReserveOrdinalRanges {
+		r, err := util.ParseOrdinalRange(intstrutil.FromString(item))
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("reserveOrdinalRanges").Index(i), item, err.Error()))
+			continue
+		}
+		for _, ordinal := range spec.ReserveOrdinals {
+			if r.Start <= ordinal && ordinal <= r.End {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("reserveOrdinalRanges").Index(i), item, fmt.Sprintf("overlaps with reserveOrdinals %d", ordinal)))
+				break
+			}
+		}
+		ranges = append(ranges, r)
+		if count += r.Size(); count > maxReservedOrdinals {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("reserveOrdinalRanges"), spec.ReserveOrdinalRanges, fmt.Sprintf("reserves more than %d ordinals", maxReservedOrdinals)))
+			return allErrs
+		}
+	}
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].Start < ranges[j].Start })
+	for i := 1; i < len(ranges); i++ {
+		if ranges[i].Start <= ranges[i-1].End {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("reserveOrdinalRanges"), spec.ReserveOrdinalRanges, "reserveOrdinalRanges contains overlapped ranges"))
+			break
+		}
+	}
+	return allErrs
+}
+
+func validateOrdinalOverrides(spec *appsv1beta1.StatefulSetSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	for i := range spec.OrdinalOverrides {
+		override := &spec.OrdinalOverrides[i]
+		overridePath := fldPath.Child("ordinalOverrides").Index(i)
+		if len(override.Ordinals) == 0 {
+			allErrs = append(allErrs, field.Required(overridePath.Child("ordinals"), ""))
+		}
+		for j, item := range override.Ordinals {
+			if _, err := util.ParseOrdinalRange(item); err != nil {
+				allErrs = append(allErrs, field.Invalid(overridePath.Child("ordinals").Index(j), item.String(), err.Error()))
+			}
+		}
+		if len(override.Patch.Raw) == 0 {
+			allErrs = append(allErrs, field.Required(overridePath.Child("patch"), ""))
+			continue
+		}
+		podBytes, _ := json.Marshal(&v1.Pod{Spec: spec.Template.Spec})
+		patched, err := strategicpatch.StrategicMergePatch(podBytes, override.Patch.Raw, &v1.Pod{})
+		if err == nil {
+			err = json.Unmarshal(patched, &v1.Pod{})
+		}
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(overridePath.Child("patch"), string(override.Patch.Raw), err.Error()))
+		}
+	}
 	return allErrs
 }
 
@@ -63,6 +129,11 @@ func validateScaleStrategy(spec *appsv1beta1.StatefulSetSpec, fldPath *field.Pat
 		if spec.ScaleStrategy.MaxUnavailable != nil {
 			allErrs = append(allErrs, validateMaxUnavailableField(spec.ScaleStrategy.MaxUnavailable, spec, fldPath.Child("scaleStrategy").Child("maxUnavailable"))...)
 		}
+		if rateLimit := spec.ScaleStrategy.PodOperationRateLimit; rateLimit != nil {
+			rateLimitPath := fldPath.Child("scaleStrategy").Child("podOperationRateLimit")
+			allErrs = append(allErrs, apivalidation.ValidateNonnegativeField(int64(rateLimit.QPS), rateLimitPath.Child("qps"))...)
+			allErrs = append(allErrs, apivalidation.ValidateNonnegativeField(int64(rateLimit.Burst), rateLimitPath.Child("burst"))...)
+		}
 	}
 	return allErrs
 }
@@ -87,6 +158,46 @@ func ValidatePersistentVolumeClaimRetentionPolicy(policy *appsv1beta1.StatefulSe
 	return allErrs
 }
 
+func validateVolumeClaimUpdateStrategy(spec *appsv1beta1.StatefulSetSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if spec.VolumeClaimUpdateStrategy != nil {
+		switch spec.VolumeClaimUpdateStrategy.Type {
+		case "", appsv1beta1.OnDeleteVolumeClaimUpdateStrategyType, appsv1beta1.ExpandVolumeClaimUpdateStrategyType:
+		default:
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("volumeClaimUpdateStrategy", "type"), spec.VolumeClaimUpdateStrategy.Type,
+				[]string{string(appsv1beta1.OnDeleteVolumeClaimUpdateStrategyType), string(appsv1beta1.ExpandVolumeClaimUpdateStrategyType)}))
+		}
+	}
+	return allErrs
+}
+
+// validateVolumeClaimTemplatesExpansion only allows to increase the storage requests in volumeClaimTemplates.
+func validateVolumeClaimTemplatesExpansion(templates, oldTemplates []v1.PersistentVolumeClaim, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if len(templates) != len(oldTemplates) {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "volumeClaimTemplates can not be added or removed"))
+		return allErrs
+	}
+
+	for i := range templates {
+		storage := templates[i].Spec.Resources.Requests[v1.ResourceStorage]
+		oldStorage := oldTemplates[i].Spec.Resources.Requests[v1.ResourceStorage]
+		if storage.Cmp(oldStorage) < 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("spec", "resources", "requests", string(v1.ResourceStorage)), storage.String(), "storage requests can not be decreased"))
+			continue
+		}
+
+		clone := templates[i].DeepCopy()
+		if _, ok := oldTemplates[i].Spec.Resources.Requests[v1.ResourceStorage]; ok {
+			clone.Spec.Resources.Requests[v1.ResourceStorage] = oldStorage
+		}
+		if !apiequality.Semantic.DeepEqual(clone, &oldTemplates[i]) {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Index(i), "updates to volumeClaimTemplates for fields other than storage requests are forbidden"))
+		}
+	}
+	return allErrs
+}
+
 func validateOnDeleteStatefulSetStrategyType(spec *appsv1beta1.StatefulSetSpec, fldPath *field.Path) field.ErrorList {
 	var allErrs field.ErrorList
 
@@ -226,11 +337,20 @@ func validateStatefulSetSpec(spec *appsv1beta1.StatefulSetSpec, fldPath *field.P
 
 	allErrs = append(allErrs, validatePodManagementPolicy(spec, fldPath)...)
 	allErrs = append(allErrs, validateReserveOrdinals(spec, fldPath)...)
+	allErrs = append(allErrs, validateOrdinalOverrides(spec, fldPath)...)
 	allErrs = append(allErrs, validateScaleStrategy(spec, fldPath)...)
 	allErrs = append(allErrs, validateUpdateStrategyType(spec, fldPath)...)
 	allErrs = append(allErrs, ValidatePersistentVolumeClaimRetentionPolicy(spec.PersistentVolumeClaimRetentionPolicy, fldPath.Child("persistentVolumeClaimRetentionPolicy"))...)
+	allErrs = append(allErrs, validateVolumeClaimUpdateStrategy(spec, fldPath)...)
+	if spec.Lifecycle != nil {
+		allErrs = append(allErrs, validateLifecycleHook(spec.Lifecycle.PreDelete, fldPath.Child("lifecycle", "preDelete"))...)
+		allErrs = append(allErrs, validateLifecycleHook(spec.Lifecycle.InPlaceUpdate, fldPath.Child("lifecycle", "inPlaceUpdate"))...)
+	}
 
 	allErrs = append(allErrs, apivalidation.ValidateNonnegativeField(int64(*spec.Replicas), fldPath.Child("replicas"))...)
+	if spec.Ordinals != nil {
+		allErrs = append(allErrs, apivalidation.ValidateNonnegativeField(int64(spec.Ordinals.Start), fldPath.Child("ordinals", "start"))...)
+	}
 
 	// validate `spec.Selector`
 	allErrs = append(allErrs, validateSpecSelector(spec, fldPath)...)
@@ -244,6 +364,21 @@ func validateStatefulSetSpec(spec *appsv1beta1.StatefulSetSpec, fldPath *field.P
 	return allErrs
 }
 
+// validateLifecycleHook forbids the timeoutSeconds and failurePolicy of hook, which are only supported by CloneSet.
+func validateLifecycleHook(hook *appspub.LifecycleHook, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if hook == nil {
+		return allErrs
+	}
+	if hook.TimeoutSeconds != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("timeoutSeconds"), "timeoutSeconds of hook has not supported yet"))
+	}
+	if hook.FailurePolicy != "" {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("failurePolicy"), "failurePolicy of hook has not supported yet"))
+	}
+	return allErrs
+}
+
 func validatePodUpdatePolicy(spec *appsv1beta1.StatefulSetSpec, fldPath *field.Path) field.ErrorList {
 	var allErrs field.ErrorList
 	switch spec.UpdateStrategy.RollingUpdate.PodUpdatePolicy {
@@ -320,18 +455,41 @@ func ValidateStatefulSetUpdate(statefulSet, oldStatefulSet *appsv1beta1.Stateful
 
 	restoreReserveOrdinals := statefulSet.Spec.ReserveOrdinals
 	statefulSet.Spec.ReserveOrdinals = oldStatefulSet.Spec.ReserveOrdinals
+
+	restoreReserveOrdinalRanges := statefulSet.Spec.ReserveOrdinalRanges
+	statefulSet.Spec.ReserveOrdinalRanges = oldStatefulSet.Spec.ReserveOrdinalRanges
+
+	restoreOrdinals := statefulSet.Spec.Ordinals
+	statefulSet.Spec.Ordinals = oldStatefulSet.Spec.Ordinals
+
+	restoreOrdinalOverrides := statefulSet.Spec.OrdinalOverrides
+	statefulSet.Spec.OrdinalOverrides = oldStatefulSet.Spec.OrdinalOverrides
 	statefulSet.Spec.Lifecycle = oldStatefulSet.Spec.Lifecycle
 	statefulSet.Spec.RevisionHistoryLimit = oldStatefulSet.Spec.RevisionHistoryLimit
 
+	restoreVolumeClaimUpdateStrategy := statefulSet.Spec.VolumeClaimUpdateStrategy
+	statefulSet.Spec.VolumeClaimUpdateStrategy = oldStatefulSet.Spec.VolumeClaimUpdateStrategy
+
+	restoreVolumeClaimTemplates := statefulSet.Spec.VolumeClaimTemplates
+	if restoreVolumeClaimUpdateStrategy != nil && restoreVolumeClaimUpdateStrategy.Type == appsv1beta1.ExpandVolumeClaimUpdateStrategyType {
+		statefulSet.Spec.VolumeClaimTemplates = oldStatefulSet.Spec.VolumeClaimTemplates
+		allErrs = append(allErrs, validateVolumeClaimTemplatesExpansion(restoreVolumeClaimTemplates, oldStatefulSet.Spec.VolumeClaimTemplates, field.NewPath("spec", "volumeClaimTemplates"))...)
+	}
+
 	if !apiequality.Semantic.DeepEqual(statefulSet.Spec, oldStatefulSet.Spec) {
-		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec"), "updates to statefulset spec for fields other than 'replicas', 'template', 'reserveOrdinals', 'lifecycle', 'revisionHistoryLimit', 'persistentVolumeClaimRetentionPolicy' and 'updateStrategy' are forbidden"))
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec"), "updates to statefulset spec for fields other than 'replicas', 'template', 'reserveOrdinals', 'reserveOrdinalRanges', 'ordinals', 'ordinalOverrides', 'lifecycle', 'revisionHistoryLimit', 'persistentVolumeClaimRetentionPolicy', 'volumeClaimUpdateStrategy' and 'updateStrategy' are forbidden"))
 	}
 	statefulSet.Spec.Replicas = restoreReplicas
 	statefulSet.Spec.Template = restoreTemplate
 	statefulSet.Spec.UpdateStrategy = restoreStrategy
 	statefulSet.Spec.ScaleStrategy = restoreScaleStrategy
 	statefulSet.Spec.ReserveOrdinals = restoreReserveOrdinals
+	statefulSet.Spec.ReserveOrdinalRanges = restoreReserveOrdinalRanges
+	statefulSet.Spec.Ordinals = restoreOrdinals
+	statefulSet.Spec.OrdinalOverrides = restoreOrdinalOverrides
 	statefulSet.Spec.PersistentVolumeClaimRetentionPolicy = restorePersistentVolumeClaimRetentionPolicy
+	statefulSet.Spec.VolumeClaimUpdateStrategy = restoreVolumeClaimUpdateStrategy
+	statefulSet.Spec.VolumeClaimTemplates = restoreVolumeClaimTemplates
 
 	allErrs = append(allErrs, apivalidation.ValidateNonnegativeField(int64(*statefulSet.Spec.Replicas), field.NewPath("spec", "replicas"))...)
 	allErrs = append(allErrs, ValidatePersistentVolumeClaimRetentionPolicy(statefulSet.Spec.PersistentVolumeClaimRetentionPolicy, field.NewPath("spec", "persistentVolumeClaimRetentionPolicy"))...)
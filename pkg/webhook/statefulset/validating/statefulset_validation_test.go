@@ -21,10 +21,13 @@ import (
 	"strings"
 	"testing"
 
+	appspub "github.com/openkruise/kruise/apis/apps/pub"
 	appsv1beta1 "github.com/openkruise/kruise/apis/apps/v1beta1"
 	apps "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	utilpointer "k8s.io/utils/pointer"
 )
@@ -428,6 +431,70 @@ func TestValidateStatefulSet(t *testing.T) {
 				UpdateStrategy:      appsv1beta1.StatefulSetUpdateStrategy{Type: apps.RollingUpdateStatefulSetStrategyType},
 			},
 		},
+		"invalid ordinal overrides": {
+			ObjectMeta: metav1.ObjectMeta{Name: "abc-123", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1beta1.StatefulSetSpec{
+				PodManagementPolicy: apps.OrderedReadyPodManagement,
+				Selector:            &metav1.LabelSelector{MatchLabels: validLabels},
+				Template:            validPodTemplate.Template,
+				Replicas:            &val3,
+				UpdateStrategy:      appsv1beta1.StatefulSetUpdateStrategy{Type: apps.RollingUpdateStatefulSetStrategyType},
+				OrdinalOverrides: []appsv1beta1.StatefulSetOrdinalOverride{
+					{Ordinals: []intstr.IntOrString{intstr.FromString("2-1")}, Patch: runtime.RawExtension{Raw: []byte(`{"spec":{"nodeSelector":{"a":"b"}}}`)}},
+					{Ordinals: []intstr.IntOrString{intstr.FromInt(0)}, Patch: runtime.RawExtension{Raw: []byte(`{"spec":"foo"}`)}},
+				},
+			},
+		},
+		"invalid reserve ordinal range": {
+			ObjectMeta: metav1.ObjectMeta{Name: "abc-123", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1beta1.StatefulSetSpec{
+				PodManagementPolicy:  apps.OrderedReadyPodManagement,
+				Selector:             &metav1.LabelSelector{MatchLabels: validLabels},
+				Template:             validPodTemplate.Template,
+				UpdateStrategy:       appsv1beta1.StatefulSetUpdateStrategy{Type: apps.RollingUpdateStatefulSetStrategyType},
+				ReserveOrdinals:      nil,
+				ReserveOrdinalRanges: []string{"3-1"},
+			},
+		},
+		"overlapped reserve ordinal ranges": {
+			ObjectMeta: metav1.ObjectMeta{Name: "abc-123", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1beta1.StatefulSetSpec{
+				PodManagementPolicy:  apps.OrderedReadyPodManagement,
+				Selector:             &metav1.LabelSelector{MatchLabels: validLabels},
+				Template:             validPodTemplate.Template,
+				UpdateStrategy:       appsv1beta1.StatefulSetUpdateStrategy{Type: apps.RollingUpdateStatefulSetStrategyType},
+				ReserveOrdinals:      []int{5},
+				ReserveOrdinalRanges: []string{"1-3", "3-4", "5-6"},
+			},
+		},
+		"too many reserved ordinals": {
+			ObjectMeta: metav1.ObjectMeta{Name: "abc-123", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1beta1.StatefulSetSpec{
+				PodManagementPolicy:  apps.OrderedReadyPodManagement,
+				Selector:             &metav1.LabelSelector{MatchLabels: validLabels},
+				Template:             validPodTemplate.Template,
+				UpdateStrategy:       appsv1beta1.StatefulSetUpdateStrategy{Type: apps.RollingUpdateStatefulSetStrategyType},
+				ReserveOrdinals:      nil,
+				ReserveOrdinalRanges: []string{"0-10000"},
+			},
+		},
+		"lifecycle hook with timeout": {
+			ObjectMeta: metav1.ObjectMeta{Name: "abc-123", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1beta1.StatefulSetSpec{
+				PodManagementPolicy: apps.OrderedReadyPodManagement,
+				Selector:            &metav1.LabelSelector{MatchLabels: validLabels},
+				Template:            validPodTemplate.Template,
+				Replicas:            &val3,
+				UpdateStrategy:      appsv1beta1.StatefulSetUpdateStrategy{Type: apps.RollingUpdateStatefulSetStrategyType},
+				Lifecycle: &appspub.Lifecycle{
+					PreDelete: &appspub.LifecycleHook{
+						LabelsHandler:  map[string]string{"a": "b"},
+						TimeoutSeconds: utilpointer.Int32Ptr(30),
+						FailurePolicy:  appspub.LifecycleHookFailurePolicyContinue,
+					},
+				},
+			},
+		},
 		"set active deadline seconds": {
 			ObjectMeta: metav1.ObjectMeta{Name: "abc-123", Namespace: metav1.NamespaceDefault},
 			Spec: appsv1beta1.StatefulSetSpec{
@@ -469,7 +536,10 @@ func TestValidateStatefulSet(t *testing.T) {
 					field != "spec.updateStrategy.rollingUpdate.podUpdatePolicy" &&
 					field != "spec.template.spec.readinessGates" &&
 					field != "spec.podManagementPolicy" &&
-					field != "spec.template.spec.activeDeadlineSeconds" {
+					field != "spec.template.spec.activeDeadlineSeconds" &&
+					!strings.HasPrefix(field, "spec.ordinalOverrides") &&
+					!strings.HasPrefix(field, "spec.lifecycle") &&
+					!strings.HasPrefix(field, "spec.reserveOrdinalRanges") {
 					t.Errorf("%s: missing prefix for: %v", k, errs[i])
 				}
 			}
@@ -487,3 +557,61 @@ func setTestDefault(obj *appsv1beta1.StatefulSet) {
 		*obj.Spec.RevisionHistoryLimit = 0
 	}
 }
+
+func TestValidateStatefulSetUpdateVolumeClaimTemplates(t *testing.T) {
+	newStatefulSet := func(storage string, strategyType appsv1beta1.StatefulSetVolumeClaimUpdateStrategyType) *appsv1beta1.StatefulSet {
+		return &appsv1beta1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: metav1.NamespaceDefault, ResourceVersion: "1"},
+			Spec: appsv1beta1.StatefulSetSpec{
+				Replicas: utilpointer.Int32Ptr(1),
+				VolumeClaimTemplates: []v1.PersistentVolumeClaim{{
+					ObjectMeta: metav1.ObjectMeta{Name: "data"},
+					Spec: v1.PersistentVolumeClaimSpec{
+						Resources: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse(storage)}},
+					},
+				}},
+				VolumeClaimUpdateStrategy: &appsv1beta1.StatefulSetVolumeClaimUpdateStrategy{Type: strategyType},
+			},
+		}
+	}
+
+	cases := []struct {
+		name        string
+		obj         *appsv1beta1.StatefulSet
+		expectedErr bool
+	}{
+		{
+			name: "increase storage with Expand",
+			obj:  newStatefulSet("20Gi", appsv1beta1.ExpandVolumeClaimUpdateStrategyType),
+		},
+		{
+			name:        "decrease storage with Expand",
+			obj:         newStatefulSet("5Gi", appsv1beta1.ExpandVolumeClaimUpdateStrategyType),
+			expectedErr: true,
+		},
+		{
+			name: "change access modes with Expand",
+			obj: func() *appsv1beta1.StatefulSet {
+				obj := newStatefulSet("20Gi", appsv1beta1.ExpandVolumeClaimUpdateStrategyType)
+				obj.Spec.VolumeClaimTemplates[0].Spec.AccessModes = []v1.PersistentVolumeAccessMode{v1.ReadWriteMany}
+				return obj
+			}(),
+			expectedErr: true,
+		},
+		{
+			name:        "increase storage with OnDelete",
+			obj:         newStatefulSet("20Gi", appsv1beta1.OnDeleteVolumeClaimUpdateStrategyType),
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			oldObj := newStatefulSet("10Gi", appsv1beta1.OnDeleteVolumeClaimUpdateStrategyType)
+			errs := ValidateStatefulSetUpdate(tc.obj, oldObj)
+			if tc.expectedErr != (len(errs) > 0) {
+				t.Fatalf("expected error %v, got %v", tc.expectedErr, errs)
+			}
+		})
+	}
+}
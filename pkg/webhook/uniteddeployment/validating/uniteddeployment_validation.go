@@ -21,6 +21,7 @@ import (
 	"strings"
 
 	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	apimachineryvalidation "k8s.io/apimachinery/pkg/api/validation"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -60,13 +61,18 @@ func validateUnitedDeploymentSpec(spec *appsv1alpha1.UnitedDeploymentSpec, fldPa
 		allErrs = append(allErrs, validateSubsetTemplate(&spec.Template, selector, fldPath.Child("template"))...)
 	}
 
-	var sumReplicas int32
+	var sumReplicas, sumMinReplicas int32
 	var expectedReplicas int32 = 1
 	if spec.Replicas != nil {
 		expectedReplicas = *spec.Replicas
 	}
+	autoscaledSubsetNames := sets.String{}
+	for _, autoscaling := range spec.SubsetAutoscaling {
+		autoscaledSubsetNames.Insert(autoscaling.Name)
+	}
 	subSetNames := sets.String{}
 	count := 0
+	allocatedCount := 0
 	for i, subset := range spec.Topology.Subsets {
 		if len(subset.Name) == 0 {
 			allErrs = append(allErrs, field.Required(fldPath.Child("topology", "subsets").Index(i).Child("name"), ""))
@@ -102,9 +108,36 @@ func validateUnitedDeploymentSpec(spec *appsv1alpha1.UnitedDeploymentSpec, fldPa
 			allErrs = append(allErrs, apivalidation.ValidateTolerations(coreTolerations, fldPath.Child("topology", "subsets").Index(i).Child("tolerations"))...)
 		}
 
+		if autoscaledSubsetNames.Has(subset.Name) {
+			if subset.Replicas != nil {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("topology", "subsets").Index(i).Child("replicas"), subset.Replicas, "replicas of subset with autoscaling should not be specified"))
+			}
+			if subset.MinReplicas != nil {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("topology", "subsets").Index(i).Child("minReplicas"), *subset.MinReplicas, "minReplicas of subset with autoscaling should not be specified"))
+			}
+			continue
+		}
+		allocatedCount++
+
+		if subset.MinReplicas != nil {
+			if *subset.MinReplicas < 0 {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("topology", "subsets").Index(i).Child("minReplicas"), *subset.MinReplicas, "minReplicas of subset should not be less than 0"))
+			} else if subset.Replicas != nil {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("topology", "subsets").Index(i).Child("minReplicas"), *subset.MinReplicas, "minReplicas of subset should not be specified with replicas"))
+			} else if spec.Template.AdvancedDaemonSetTemplate != nil {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("topology", "subsets").Index(i).Child("minReplicas"), *subset.MinReplicas, "minReplicas of subset should not be specified for advancedDaemonSetTemplate"))
+			} else {
+				sumMinReplicas += *subset.MinReplicas
+			}
+		}
+
 		if subset.Replicas == nil {
 			continue
 		}
+		if spec.Template.AdvancedDaemonSetTemplate != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("topology", "subsets").Index(i).Child("replicas"), subset.Replicas, "replicas of subset should not be specified for advancedDaemonSetTemplate"))
+			continue
+		}
 
 		replicas, err := udctrl.ParseSubsetReplicas(expectedReplicas, *subset.Replicas)
 		if err != nil {
@@ -120,7 +153,11 @@ func validateUnitedDeploymentSpec(spec *appsv1alpha1.UnitedDeploymentSpec, fldPa
 		allErrs = append(allErrs, field.Invalid(fldPath.Child("topology", "subsets"), sumReplicas, fmt.Sprintf("sum of indicated subset replicas %d should not be greater than UnitedDeployment replicas %d", sumReplicas, expectedReplicas)))
 	}
 
-	if count > 0 && count == len(spec.Topology.Subsets) && sumReplicas != expectedReplicas {
+	if sumReplicas <= expectedReplicas && sumReplicas+sumMinReplicas > expectedReplicas {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("topology", "subsets"), sumMinReplicas, fmt.Sprintf("sum of indicated subset replicas %d and minReplicas %d should not be greater than UnitedDeployment replicas %d", sumReplicas, sumMinReplicas, expectedReplicas)))
+	}
+
+	if count > 0 && count == allocatedCount && sumReplicas != expectedReplicas {
 		allErrs = append(allErrs, field.Invalid(fldPath.Child("topology", "subsets"), sumReplicas, fmt.Sprintf("if replicas of all subsets are provided, the sum of indicated subset replicas %d should equal UnitedDeployment replicas %d", sumReplicas, expectedReplicas)))
 	}
 
@@ -132,6 +169,105 @@ func validateUnitedDeploymentSpec(spec *appsv1alpha1.UnitedDeploymentSpec, fldPa
 		}
 	}
 
+	allErrs = append(allErrs, validateOrderedUpdate(spec, subSetNames, fldPath.Child("updateStrategy"))...)
+
+	if adaptive := spec.Topology.ScheduleStrategy.Adaptive; adaptive != nil {
+		if spec.Topology.ScheduleStrategy.Type != appsv1alpha1.AdaptiveUnitedDeploymentScheduleStrategyType {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("topology", "scheduleStrategy", "adaptive"), adaptive, "the scheduleStrategy's type must be adaptive when using adaptive scheduleStrategy"))
+		}
+		if adaptive.RescheduleCriticalSeconds != nil {
+			allErrs = append(allErrs, apivalidation.ValidateNonnegativeField(int64(*adaptive.RescheduleCriticalSeconds), fldPath.Child("topology", "scheduleStrategy", "adaptive", "rescheduleCriticalSeconds"))...)
+		}
+		if adaptive.UnschedulableLastSeconds != nil {
+			allErrs = append(allErrs, apivalidation.ValidateNonnegativeField(int64(*adaptive.UnschedulableLastSeconds), fldPath.Child("topology", "scheduleStrategy", "adaptive", "unschedulableLastSeconds"))...)
+		}
+	}
+
+	if spec.Template.AdvancedDaemonSetTemplate != nil && len(spec.SubsetAutoscaling) > 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("subsetAutoscaling"), spec.SubsetAutoscaling, "subsetAutoscaling is not supported by advancedDaemonSetTemplate"))
+	}
+	allErrs = append(allErrs, validateSubsetAutoscaling(spec.SubsetAutoscaling, subSetNames, fldPath.Child("subsetAutoscaling"))...)
+
+	return allErrs
+}
+
+func validateOrderedUpdate(spec *appsv1alpha1.UnitedDeploymentSpec, subSetNames sets.String, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.UpdateStrategy.Type != appsv1alpha1.OrderedUpdateStrategyType {
+		if spec.UpdateStrategy.OrderedUpdate != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("orderedUpdate"), spec.UpdateStrategy.OrderedUpdate, "the updateStrategy's type must be Ordered when using orderedUpdate"))
+		}
+		return allErrs
+	}
+	if spec.Template.DeploymentTemplate != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("type"), spec.UpdateStrategy.Type, "Ordered update strategy is not supported by deploymentTemplate"))
+	}
+	if spec.UpdateStrategy.OrderedUpdate == nil {
+		return allErrs
+	}
+
+	steps := sets.String{}
+	for i, step := range spec.UpdateStrategy.OrderedUpdate.Steps {
+		if !subSetNames.Has(step.Subset) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("orderedUpdate", "steps").Index(i).Child("subset"), step.Subset, fmt.Sprintf("subset %s does not exist", step.Subset)))
+		} else if steps.Has(step.Subset) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("orderedUpdate", "steps").Index(i).Child("subset"), step.Subset, fmt.Sprintf("duplicated subset name %s", step.Subset)))
+		}
+		steps.Insert(step.Subset)
+		if step.Partition != nil {
+			allErrs = append(allErrs, apivalidation.ValidateNonnegativeField(int64(*step.Partition), fldPath.Child("orderedUpdate", "steps").Index(i).Child("partition"))...)
+		}
+	}
+	return allErrs
+}
+
+func validateSubsetAutoscaling(subsetAutoscaling []appsv1alpha1.SubsetAutoscaling, subSetNames sets.String, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	names := sets.String{}
+	for i, autoscaling := range subsetAutoscaling {
+		if !subSetNames.Has(autoscaling.Name) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("name"), autoscaling.Name, fmt.Sprintf("subset %s does not exist", autoscaling.Name)))
+		} else if names.Has(autoscaling.Name) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("name"), autoscaling.Name, fmt.Sprintf("duplicated subset name %s", autoscaling.Name)))
+		}
+		names.Insert(autoscaling.Name)
+
+		minReplicas := int32(1)
+		if autoscaling.MinReplicas != nil {
+			minReplicas = *autoscaling.MinReplicas
+			if minReplicas < 1 {
+				allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("minReplicas"), minReplicas, "must be greater than or equal to 1"))
+			}
+		}
+		if autoscaling.MaxReplicas < minReplicas {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("maxReplicas"), autoscaling.MaxReplicas, "must be greater than or equal to minReplicas"))
+		}
+		for j := range autoscaling.Metrics {
+			allErrs = append(allErrs, validateSubsetAutoscalingMetric(&autoscaling.Metrics[j], fldPath.Index(i).Child("metrics").Index(j))...)
+		}
+	}
+	return allErrs
+}
+
+func validateSubsetAutoscalingMetric(metric *appsv1alpha1.SubsetAutoscalingMetric, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if metric.Resource != v1.ResourceCPU && metric.Resource != v1.ResourceMemory {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("resource"), metric.Resource, []string{string(v1.ResourceCPU), string(v1.ResourceMemory)}))
+	}
+	switch {
+	case metric.TargetAverageUtilization != nil && metric.TargetAverageValue != nil:
+		allErrs = append(allErrs, field.Invalid(fldPath, metric, "targetAverageUtilization and targetAverageValue can not be set both"))
+	case metric.TargetAverageUtilization != nil:
+		if *metric.TargetAverageUtilization < 1 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("targetAverageUtilization"), *metric.TargetAverageUtilization, "must be greater than 0"))
+		}
+	case metric.TargetAverageValue != nil:
+		if metric.TargetAverageValue.Sign() <= 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("targetAverageValue"), metric.TargetAverageValue.String(), "must be positive"))
+		}
+	default:
+		allErrs = append(allErrs, field.Required(fldPath, "one of targetAverageUtilization and targetAverageValue must be set"))
+	}
 	return allErrs
 }
 
@@ -220,10 +356,13 @@ func validateSubsetTemplate(template *appsv1alpha1.SubsetTemplate, selector labe
 	if template.DeploymentTemplate != nil {
 		templateCount++
 	}
+	if template.AdvancedDaemonSetTemplate != nil {
+		templateCount++
+	}
 	if templateCount < 1 {
-		allErrs = append(allErrs, field.Required(fldPath, "should provide one of statefulSetTemplate, advancedStatefulSetTemplate, cloneSetTemplate, or deploymentTemplate"))
+		allErrs = append(allErrs, field.Required(fldPath, "should provide one of statefulSetTemplate, advancedStatefulSetTemplate, cloneSetTemplate, deploymentTemplate, or advancedDaemonSetTemplate"))
 	} else if templateCount > 1 {
-		allErrs = append(allErrs, field.Invalid(fldPath, template, "should provide only one of statefulSetTemplate, advancedStatefulSetTemplate, cloneSetTemplate, or deploymentTemplate"))
+		allErrs = append(allErrs, field.Invalid(fldPath, template, "should provide only one of statefulSetTemplate, advancedStatefulSetTemplate, cloneSetTemplate, deploymentTemplate, or advancedDaemonSetTemplate"))
 	}
 
 	if template.StatefulSetTemplate != nil {
@@ -265,6 +404,18 @@ func validateSubsetTemplate(template *appsv1alpha1.SubsetTemplate, selector labe
 			return allErrs
 		}
 		allErrs = append(allErrs, appsvalidation.ValidatePodTemplateSpecForReplicaSet(coreTemplate, selector, 0, fldPath.Child("deploymentTemplate", "spec", "template"), webhookutil.DefaultPodValidationOptions)...)
+	} else if template.AdvancedDaemonSetTemplate != nil {
+		labels := labels.Set(template.AdvancedDaemonSetTemplate.Labels)
+		if !selector.Matches(labels) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("advancedDaemonSetTemplate", "metadata", "labels"), template.AdvancedDaemonSetTemplate.Labels, "`selector` does not match template `labels`"))
+		}
+		template := template.AdvancedDaemonSetTemplate.Spec.Template
+		coreTemplate, err := convertor.ConvertPodTemplateSpec(&template)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Root(), template, fmt.Sprintf("Convert_v1_PodTemplateSpec_To_core_PodTemplateSpec failed: %v", err)))
+			return allErrs
+		}
+		allErrs = append(allErrs, appsvalidation.ValidatePodTemplateSpecForReplicaSet(coreTemplate, selector, 0, fldPath.Child("advancedDaemonSetTemplate", "spec", "template"), webhookutil.DefaultPodValidationOptions)...)
 	}
 
 	return allErrs
@@ -51,6 +51,7 @@ func TestValidateUnitedDeployment(t *testing.T) {
 	replicas2 := intstr.FromString("90%")
 	replicas3 := intstr.FromString("71%")
 	replicas4 := intstr.FromString("29%")
+	var minReplicas int32 = 2
 	successCases := []appsv1alpha1.UnitedDeployment{
 		{
 			ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: metav1.NamespaceDefault},
@@ -196,6 +197,79 @@ func TestValidateUnitedDeployment(t *testing.T) {
 				},
 			},
 		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1alpha1.UnitedDeploymentSpec{
+				Replicas: &val,
+				Selector: &metav1.LabelSelector{MatchLabels: validLabels},
+				Template: appsv1alpha1.SubsetTemplate{
+					StatefulSetTemplate: &appsv1alpha1.StatefulSetTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: validLabels,
+						},
+						Spec: apps.StatefulSetSpec{
+							Template: validPodTemplate.Template,
+						},
+					},
+				},
+				Topology: appsv1alpha1.Topology{
+					Subsets: []appsv1alpha1.Subset{
+						{
+							Name: "subset1",
+						},
+						{
+							Name: "subset2",
+						},
+					},
+				},
+				SubsetAutoscaling: []appsv1alpha1.SubsetAutoscaling{
+					{
+						Name:        "subset2",
+						MinReplicas: &minReplicas,
+						MaxReplicas: 5,
+						Metrics: []appsv1alpha1.SubsetAutoscalingMetric{
+							{
+								Resource:                 v1.ResourceMemory,
+								TargetAverageUtilization: &minReplicas,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1alpha1.UnitedDeploymentSpec{
+				Replicas: &val,
+				Selector: &metav1.LabelSelector{MatchLabels: validLabels},
+				Template: appsv1alpha1.SubsetTemplate{
+					StatefulSetTemplate: &appsv1alpha1.StatefulSetTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: validLabels,
+						},
+						Spec: apps.StatefulSetSpec{
+							Template: validPodTemplate.Template,
+						},
+					},
+				},
+				Topology: appsv1alpha1.Topology{
+					Subsets: []appsv1alpha1.Subset{
+						{
+							Name:     "subset1",
+							Replicas: &replicas1,
+						},
+						{
+							Name:        "subset2",
+							MinReplicas: &minReplicas,
+							Priority:    1,
+						},
+						{
+							Name: "subset3",
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for i, successCase := range successCases {
@@ -567,6 +641,265 @@ func TestValidateUnitedDeployment(t *testing.T) {
 				},
 			},
 		},
+		"subset autoscaling with replicas specified": {
+			ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1alpha1.UnitedDeploymentSpec{
+				Replicas: &val,
+				Selector: &metav1.LabelSelector{MatchLabels: validLabels},
+				Template: appsv1alpha1.SubsetTemplate{
+					StatefulSetTemplate: &appsv1alpha1.StatefulSetTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: validLabels,
+						},
+						Spec: apps.StatefulSetSpec{
+							Template: validPodTemplate.Template,
+						},
+					},
+				},
+				Topology: appsv1alpha1.Topology{
+					Subsets: []appsv1alpha1.Subset{
+						{
+							Name:     "subset1",
+							Replicas: &replicas1,
+						},
+					},
+				},
+				SubsetAutoscaling: []appsv1alpha1.SubsetAutoscaling{
+					{
+						Name:        "subset1",
+						MinReplicas: &minReplicas,
+						MaxReplicas: 5,
+					},
+				},
+			},
+		},
+		"subset autoscaling for nonexistent subset": {
+			ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1alpha1.UnitedDeploymentSpec{
+				Replicas: &val,
+				Selector: &metav1.LabelSelector{MatchLabels: validLabels},
+				Template: appsv1alpha1.SubsetTemplate{
+					StatefulSetTemplate: &appsv1alpha1.StatefulSetTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: validLabels,
+						},
+						Spec: apps.StatefulSetSpec{
+							Template: validPodTemplate.Template,
+						},
+					},
+				},
+				Topology: appsv1alpha1.Topology{
+					Subsets: []appsv1alpha1.Subset{
+						{
+							Name: "subset1",
+						},
+					},
+				},
+				SubsetAutoscaling: []appsv1alpha1.SubsetAutoscaling{
+					{
+						Name:        "subset2",
+						MinReplicas: &minReplicas,
+						MaxReplicas: 5,
+					},
+				},
+			},
+		},
+		"subset autoscaling maxReplicas less than minReplicas": {
+			ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1alpha1.UnitedDeploymentSpec{
+				Replicas: &val,
+				Selector: &metav1.LabelSelector{MatchLabels: validLabels},
+				Template: appsv1alpha1.SubsetTemplate{
+					StatefulSetTemplate: &appsv1alpha1.StatefulSetTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: validLabels,
+						},
+						Spec: apps.StatefulSetSpec{
+							Template: validPodTemplate.Template,
+						},
+					},
+				},
+				Topology: appsv1alpha1.Topology{
+					Subsets: []appsv1alpha1.Subset{
+						{
+							Name: "subset1",
+						},
+					},
+				},
+				SubsetAutoscaling: []appsv1alpha1.SubsetAutoscaling{
+					{
+						Name:        "subset1",
+						MinReplicas: &minReplicas,
+						MaxReplicas: 1,
+					},
+				},
+			},
+		},
+		"subset autoscaling metric without target": {
+			ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1alpha1.UnitedDeploymentSpec{
+				Replicas: &val,
+				Selector: &metav1.LabelSelector{MatchLabels: validLabels},
+				Template: appsv1alpha1.SubsetTemplate{
+					StatefulSetTemplate: &appsv1alpha1.StatefulSetTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: validLabels,
+						},
+						Spec: apps.StatefulSetSpec{
+							Template: validPodTemplate.Template,
+						},
+					},
+				},
+				Topology: appsv1alpha1.Topology{
+					Subsets: []appsv1alpha1.Subset{
+						{
+							Name: "subset1",
+						},
+					},
+				},
+				SubsetAutoscaling: []appsv1alpha1.SubsetAutoscaling{
+					{
+						Name:        "subset1",
+						MinReplicas: &minReplicas,
+						MaxReplicas: 5,
+						Metrics: []appsv1alpha1.SubsetAutoscalingMetric{
+							{
+								Resource: v1.ResourceCPU,
+							},
+						},
+					},
+				},
+			},
+		},
+		"subset autoscaling metric of unsupported resource": {
+			ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1alpha1.UnitedDeploymentSpec{
+				Replicas: &val,
+				Selector: &metav1.LabelSelector{MatchLabels: validLabels},
+				Template: appsv1alpha1.SubsetTemplate{
+					StatefulSetTemplate: &appsv1alpha1.StatefulSetTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: validLabels,
+						},
+						Spec: apps.StatefulSetSpec{
+							Template: validPodTemplate.Template,
+						},
+					},
+				},
+				Topology: appsv1alpha1.Topology{
+					Subsets: []appsv1alpha1.Subset{
+						{
+							Name: "subset1",
+						},
+					},
+				},
+				SubsetAutoscaling: []appsv1alpha1.SubsetAutoscaling{
+					{
+						Name:        "subset1",
+						MinReplicas: &minReplicas,
+						MaxReplicas: 5,
+						Metrics: []appsv1alpha1.SubsetAutoscalingMetric{
+							{
+								Resource:                 v1.ResourceEphemeralStorage,
+								TargetAverageUtilization: &minReplicas,
+							},
+						},
+					},
+				},
+			},
+		},
+		"ordered update with nonexistent subset": {
+			ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1alpha1.UnitedDeploymentSpec{
+				Replicas: &val,
+				Selector: &metav1.LabelSelector{MatchLabels: validLabels},
+				Template: appsv1alpha1.SubsetTemplate{
+					StatefulSetTemplate: &appsv1alpha1.StatefulSetTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: validLabels,
+						},
+						Spec: apps.StatefulSetSpec{
+							Template: validPodTemplate.Template,
+						},
+					},
+				},
+				Topology: appsv1alpha1.Topology{
+					Subsets: []appsv1alpha1.Subset{
+						{
+							Name: "subset1",
+						},
+					},
+				},
+				UpdateStrategy: appsv1alpha1.UnitedDeploymentUpdateStrategy{
+					Type: appsv1alpha1.OrderedUpdateStrategyType,
+					OrderedUpdate: &appsv1alpha1.OrderedUpdate{
+						Steps: []appsv1alpha1.OrderedUpdateStep{
+							{
+								Subset: "subset2",
+							},
+						},
+					},
+				},
+			},
+		},
+		"subset minReplicas greater than replicas": {
+			ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1alpha1.UnitedDeploymentSpec{
+				Replicas: &val,
+				Selector: &metav1.LabelSelector{MatchLabels: validLabels},
+				Template: appsv1alpha1.SubsetTemplate{
+					StatefulSetTemplate: &appsv1alpha1.StatefulSetTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: validLabels,
+						},
+						Spec: apps.StatefulSetSpec{
+							Template: validPodTemplate.Template,
+						},
+					},
+				},
+				Topology: appsv1alpha1.Topology{
+					Subsets: []appsv1alpha1.Subset{
+						{
+							Name:     "subset1",
+							Replicas: &replicas1,
+						},
+						{
+							Name:        "subset2",
+							MinReplicas: &val,
+						},
+					},
+				},
+			},
+		},
+		"subset minReplicas with replicas": {
+			ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1alpha1.UnitedDeploymentSpec{
+				Replicas: &val,
+				Selector: &metav1.LabelSelector{MatchLabels: validLabels},
+				Template: appsv1alpha1.SubsetTemplate{
+					StatefulSetTemplate: &appsv1alpha1.StatefulSetTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: validLabels,
+						},
+						Spec: apps.StatefulSetSpec{
+							Template: validPodTemplate.Template,
+						},
+					},
+				},
+				Topology: appsv1alpha1.Topology{
+					Subsets: []appsv1alpha1.Subset{
+						{
+							Name:        "subset1",
+							Replicas:    &replicas1,
+							MinReplicas: &minReplicas,
+						},
+						{
+							Name: "subset2",
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for k, v := range errorCases {
@@ -585,6 +918,10 @@ func TestValidateUnitedDeployment(t *testing.T) {
 					field != "spec.topology.subsets[0]" &&
 					field != "spec.topology.subsets[0].name" &&
 					field != "spec.updateStrategy.partitions" &&
+					field != "spec.topology.subsets[0].replicas" &&
+					field != "spec.topology.subsets[0].minReplicas" &&
+					!strings.HasPrefix(field, "spec.subsetAutoscaling") &&
+					!strings.HasPrefix(field, "spec.updateStrategy.orderedUpdate") &&
 					field != "spec.topology.subsets[0].nodeSelectorTerm.matchExpressions[0].values" {
 					t.Errorf("%s: missing prefix for: %v", k, errs[i])
 				}
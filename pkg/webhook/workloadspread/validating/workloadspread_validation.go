@@ -27,6 +27,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/apis/core"
@@ -116,7 +117,11 @@ func validateWorkloadSpreadSpec(obj *appsv1alpha1.WorkloadSpread, fldPath *field
 					allErrs = append(allErrs, field.Invalid(fldPath.Child("targetRef"), spec.TargetReference, "TargetReference is not valid for Job."))
 				}
 			default:
-				allErrs = append(allErrs, field.Invalid(fldPath.Child("targetRef"), spec.TargetReference, "TargetReference's GroupKind is not permitted."))
+				// custom workloads exposing the scale subresource are permitted, such as Argo Rollouts.
+				gv, err := schema.ParseGroupVersion(spec.TargetReference.APIVersion)
+				if err != nil || gv.Group == "" {
+					allErrs = append(allErrs, field.Invalid(fldPath.Child("targetRef"), spec.TargetReference, "TargetReference's GroupKind is not permitted."))
+				}
 			}
 		}
 	}
@@ -132,6 +137,14 @@ func validateWorkloadSpreadSpec(obj *appsv1alpha1.WorkloadSpread, fldPath *field
 			spec.ScheduleStrategy.Type, "ScheduleStrategy's type is not valid"))
 	}
 
+	// validate deletionCostPolicy
+	if spec.DeletionCostPolicy != "" &&
+		spec.DeletionCostPolicy != appsv1alpha1.SubsetWorkloadSpreadDeletionCostPolicyType &&
+		spec.DeletionCostPolicy != appsv1alpha1.TieredWorkloadSpreadDeletionCostPolicyType {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("deletionCostPolicy"),
+			spec.DeletionCostPolicy, "DeletionCostPolicy is not valid"))
+	}
+
 	if spec.ScheduleStrategy.Adaptive != nil {
 		if spec.ScheduleStrategy.Type != appsv1alpha1.AdaptiveWorkloadSpreadScheduleStrategyType {
 			allErrs = append(allErrs, field.Invalid(fldPath.Child("scheduleStrategy").Child("type"),
@@ -155,6 +168,30 @@ func validateWorkloadSpreadSpec(obj *appsv1alpha1.WorkloadSpread, fldPath *field
 			allErrs = append(allErrs, field.Invalid(fldPath.Child("scheduleStrategy").Child("adaptive").Child("rescheduleCriticalSeconds"),
 				spec.ScheduleStrategy.Adaptive.RescheduleCriticalSeconds, fmt.Sprintf("rescheduleCriticalSeconds < 0 or rescheduleCriticalSeconds > %d is not permitted", allowedMaxSeconds)))
 		}
+
+		if failover := spec.ScheduleStrategy.Adaptive.Failover; failover != nil {
+			failoverPath := fldPath.Child("scheduleStrategy").Child("adaptive").Child("failover")
+			if failover.MaxPendingPodPercent != nil && (*failover.MaxPendingPodPercent < 0 || *failover.MaxPendingPodPercent > 100) {
+				allErrs = append(allErrs, field.Invalid(failoverPath.Child("maxPendingPodPercent"),
+					*failover.MaxPendingPodPercent, "maxPendingPodPercent must be between 0 and 100"))
+			}
+			if failover.MaxNotReadyNodePercent != nil && (*failover.MaxNotReadyNodePercent < 0 || *failover.MaxNotReadyNodePercent > 100) {
+				allErrs = append(allErrs, field.Invalid(failoverPath.Child("maxNotReadyNodePercent"),
+					*failover.MaxNotReadyNodePercent, "maxNotReadyNodePercent must be between 0 and 100"))
+			}
+			if failover.RecoverySeconds != nil && *failover.RecoverySeconds < 0 {
+				allErrs = append(allErrs, field.Invalid(failoverPath.Child("recoverySeconds"),
+					*failover.RecoverySeconds, "recoverySeconds must be non-negative"))
+			}
+		}
+
+		if rebalance := spec.ScheduleStrategy.Adaptive.Rebalance; rebalance != nil && rebalance.MaxUnavailable != nil {
+			maxUnavailable, err := intstr.GetValueFromIntOrPercent(rebalance.MaxUnavailable, 100, true)
+			if err != nil || maxUnavailable <= 0 {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("scheduleStrategy").Child("adaptive").Child("rebalance").Child("maxUnavailable"),
+					rebalance.MaxUnavailable, "maxUnavailable must be a positive integer or percentage"))
+			}
+		}
 	}
 
 	return allErrs
@@ -230,6 +267,12 @@ func validateWorkloadSpreadSubsets(subsets []appsv1alpha1.WorkloadSpreadSubset,
 			allErrs = append(allErrs, corevalidation.ValidateTolerations(coreTolerations, fldPath.Index(i).Child("tolerations"))...)
 		}
 
+		if subset.PriorityClassName != "" {
+			for _, msg := range validation.IsDNS1123Subdomain(subset.PriorityClassName) {
+				allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("priorityClassName"), subset.PriorityClassName, msg))
+			}
+		}
+
 		//TODO validate patch
 
 		//1. All subset maxReplicas must be the same type: int or percent.